@@ -13,12 +13,21 @@ const (
 	cliUser = "appuser" // we'll create this user in the container
 )
 
+// cliLdflags stamps the version of the binary as the Makefile does, so the
+// meta table of the databases it builds says which commit did it. The source
+// has no .git to take it from.
+const cliLdflags = `-X 'main.Version=${GIT_SHA:-development}' -X 'main.Commit=${GIT_SHA}' ` +
+	`-X 'main.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)'`
+
 // Builds the CLI binary
 func (c *Chapauy) BuildCliBase(
 	ctx context.Context,
 	// +defaultPath="/"
 	// +ignore=["web", "db" ]
 	src *dagger.Directory,
+	// the commit being built
+	// +optional
+	gitSha string,
 ) *dagger.Container {
 	//dictates where Go stores its build cacheDir data, which includes compiled
 	// packages and other build artifacts.
@@ -60,7 +69,9 @@ func (c *Chapauy) BuildCliBase(
 		WithDirectory("/src", src.WithoutDirectory("web")).
 		WithExec([]string{"chown", "-R", cliUser + ":" + cliUser, "/src"}).
 		WithUser(cliUser).
-		WithExec([]string{"go", "build", "-o", "build/chapa", "main.go"})
+		WithEnvVariable("GIT_SHA", gitSha).
+		// distroless has no timezone database: impo/tzdata.go embeds one
+		WithExec([]string{"sh", "-c", `go build -ldflags="` + cliLdflags + `" -o build/chapa .`})
 }
 
 // Runs validation on CLI code
@@ -70,7 +81,7 @@ func (c *Chapauy) BuildCliValidate(
 	// +ignore=["web", "db" ]
 	src *dagger.Directory,
 ) *dagger.Container {
-	return c.BuildCliBase(ctx, src).
+	return c.BuildCliBase(ctx, src, "").
 		// make deps
 		WithExec([]string{"go", "install", "-v", "github.com/golangci/golangci-lint/cmd/golangci-lint@latest"}).
 		WithExec([]string{"go", "install", "-v", "github.com/securego/gosec/v2/cmd/gosec@latest"}).
@@ -111,9 +122,12 @@ func (c *Chapauy) BuildCli(
 	// +defaultPath="/"
 	// +ignore=["web", "db" ]
 	src *dagger.Directory,
+	// the commit being built
+	// +optional
+	gitSha string,
 ) *dagger.Container {
 	// Stage 1: Build the binary
-	builder := c.BuildCliBase(ctx, src)
+	builder := c.BuildCliBase(ctx, src, gitSha)
 
 	// Stage 2: Create the runtime container
 	return dag.Container().
@@ -8,17 +8,59 @@ import (
 	"dagger/chapauy/infra"
 	"dagger/chapauy/internal/dagger"
 	"fmt"
+	"slices"
 )
 
+// dbFiles are the files of the data image the web needs: the DuckDB file and
+// the artifacts written by `impo update`. The rest of the state, the raw HTML
+// documents, is only needed to update them.
+var dbFiles = []string{
+	"chapauy.duckdb",
+	"scoreboard.json",
+	"repeat_offenders.json",
+	"schema.json",
+	"qa_sample.html",
+	"run_report.json",
+}
+
+// privateFiles are never published, should they end up in the state
+// directory: the payment status of the plates looked up by `chapa payments`
+// is personal data, and the snapshots of `chapa db snapshot` are whole copies
+// of the database that would only grow the archive.
+var privateFiles = []string{
+	"payments.duckdb",
+	"payments.duckdb.wal",
+	"snapshots",
+}
+
+// splitState splits a state directory as `impo update` lays it out by default
+// into the database and the archive of documents.
+func splitState(stateDir *dagger.Directory) (db, archive *dagger.Directory) {
+	db = dag.Directory().WithDirectory(".", stateDir, dagger.DirectoryWithDirectoryOpts{Include: dbFiles})
+	archive = dag.Directory().WithDirectory(".", stateDir, dagger.DirectoryWithDirectoryOpts{
+		Exclude: append(slices.Clone(dbFiles), privateFiles...),
+	})
+
+	return db, archive
+}
+
+// dataContainer builds the data image. The archive, by far the largest, goes
+// in its own layer under /app/archive, before the database under /app/db, so
+// the images that only need the database copy just that.
+func dataContainer(db, archive *dagger.Directory) *dagger.Container {
+	return dag.Container().
+		WithWorkdir("/app").
+		WithDirectory("archive", archive).
+		WithDirectory("db", db)
+}
+
 // Creates the initial state image from a local directory
 func (c *Chapauy) DataBootstrap(
 	ctx context.Context,
 	// +defaultPath="db"
 	stateDir *dagger.Directory,
 ) *dagger.Container {
-	return dag.Container().
-		WithWorkdir("/app").
-		WithDirectory("db", stateDir)
+	return dataContainer(splitState(stateDir))
 }
 
 func (c *Chapauy) DataBootstrapAndPublish(
@@ -7,8 +7,10 @@ import (
 	"context"
 	"dagger/chapauy/infra"
 	"dagger/chapauy/internal/dagger"
+	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 )
 
 type Chapauy struct{}
@@ -58,6 +60,7 @@ func (c *Chapauy) BuildAndPublish(
 	cli := c.BuildCli(ctx, src.
 		WithoutDirectory("web").
 		WithoutDirectory("db"),
+		gitSha,
 	)
 	web := c.BuildFrontend(ctx, src.
 		Directory("web").
@@ -67,6 +70,15 @@ func (c *Chapauy) BuildAndPublish(
 		gitSha,
 	)
 
+	// Don't publish broken images, e.g. without the timezone data
+	if err := c.SmokeTestCli(ctx, cli); err != nil {
+		return err
+	}
+
+	if err := c.SmokeTestWeb(ctx, web); err != nil {
+		return err
+	}
+
 	accessToken, err := extractToken(ctx, token)
 	if err != nil {
 		return err
@@ -85,6 +97,9 @@ func (c *Chapauy) BuildAndPublish(
 }
 
 // Performs the daily synchronization of data and redeploy of the web service.
+// Returns the manifest of the run: the images published with their digests,
+// the rows of the database, the metrics of the update, its duration and the
+// version of the CLI.
 func (c *Chapauy) DataRefresh(
 	ctx context.Context,
 	// Access Token (optional, used for registry operations)
@@ -93,12 +108,14 @@ func (c *Chapauy) DataRefresh(
 	// Dry run mode (builds but does not publish)
 	// +optional
 	dryRun bool,
-) error {
+) (*dagger.File, error) {
+	manifest := &RunManifest{StartedAt: time.Now().UTC(), DryRun: dryRun, Images: []PublishedImage{}}
+
 	log.Printf("Starting Data Update...\n CLI: %s\n Data: %s\n Web: %s\n", infra.Images.CLI, infra.Images.Data, infra.Images.Web)
 
 	accessToken, err := extractToken(ctx, token)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tokenSecret := dag.SetSecret("gcp-token", accessToken)
@@ -108,6 +125,17 @@ func (c *Chapauy) DataRefresh(
 		WithRegistryAuth(infra.Images.RegistryAddr, "oauth2accesstoken", tokenSecret).
 		From(infra.Images.Data)
 
+	dbDir := dataCtr.Directory("/app/db")
+	archiveDir := dataCtr.Directory("/app/archive")
+
+	// Images published before the archive had its own layer carry the
+	// documents inside /app/db
+	if _, err := archiveDir.Entries(ctx); err != nil {
+		log.Println("Data image without /app/archive, splitting /app/db")
+
+		dbDir, archiveDir = splitState(dbDir)
+	}
+
 	// We use the CLI image to run the update
 	// Note: CLI runs as user 1000 (appuser) or 65532 (distroless) usually.
 	// We run as root to ensure we can write to the mounted volume and avoid permission issues.
@@ -117,34 +145,59 @@ func (c *Chapauy) DataRefresh(
 		WithRegistryAuth(infra.Images.RegistryAddr, "oauth2accesstoken", tokenSecret).
 		From(infra.Images.CLI).
 		WithUser("root").
-		WithDirectory("/app/db", dataCtr.Directory("/app/db")).
-		WithExec([]string{"/app/chapa", "impo", "update"})
-
-	// Force execution to verify the update command runs successfully
+		WithDirectory("/app/db", dbDir).
+		WithDirectory("/app/archive", archiveDir).
+		// Only the databases likely to have new documents, all of them on Sundays
+		WithExec([]string{"/app/chapa", "impo", "update", "--archive-path", "/app/archive", "--schedule"}).
+		// Distances to the nearest fixed radar of every geocoded offense; the
+		// bundled school zone layer is empty, nearest_school_m stays NULL
+		WithExec([]string{"/app/chapa", "spatial", "proximity"}).
+		// Leaves the published file as small as possible
+		WithExec([]string{"/app/chapa", "db", "optimize", "--analyze"})
+
+	// Force execution to verify the update commands run successfully
 	if _, err := cliCtr.Sync(ctx); err != nil {
-		return fmt.Errorf("failed to execute update command: %w", err)
+		return nil, fmt.Errorf("failed to execute update command: %w", err)
 	}
 
 	// 4. Capture Updated Data
+	// Besides the DB, /app/db carries scoreboard.json, repeat_offenders.json,
+	// schema.json, run_report.json and qa_sample.html, the sheet to review a
+	// sample of the offenses extracted by this run. The downloaded documents are in
+	// /app/archive.
 	updatedDb := cliCtr.Directory("/app/db")
+	updatedArchive := cliCtr.Directory("/app/archive")
+
+	// The manifest takes the rows and metrics of the run from the report of
+	// `impo update` and the version from the CLI that ran it
+	if err := manifest.readRunReport(ctx, updatedDb.File(runReportFile)); err != nil {
+		return nil, err
+	}
+
+	version, err := cliCtr.WithExec([]string{"/app/chapa", "version", "--json"}).Stdout(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the CLI version: %w", err)
+	}
+	manifest.Version = json.RawMessage(version)
 
 	// 5. Publish Updated Data Image
-	// Reconstruct the data image structure (Filesystem + DB)
-	// DataBootstrap created it as: WithWorkdir("/app").WithDirectory("db", stateDir)
-	newDataCtr := dag.Container().
-		WithWorkdir("/app").
-		WithDirectory("db", updatedDb)
+	// Same structure as DataBootstrap: archive and DB in separate layers
+	newDataCtr := dataContainer(updatedDb, updatedArchive)
 
 	if dryRun {
 		log.Printf("dry-run: Skipping publish for %s", newDataCtr)
 	} else {
-		if _, err := publish(ctx, tokenSecret, newDataCtr, infra.DataImageName); err != nil {
-			return fmt.Errorf("failed to publish updated data: %w", err)
+		ref, err := publish(ctx, tokenSecret, newDataCtr, infra.DataImageName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish updated data: %w", err)
 		}
+		manifest.addImage(infra.DataImageName, ref)
 		log.Println("✅ Published updated data image")
 	}
 
-	return nil
+	manifest.finish(time.Now().UTC())
+
+	return manifest.file()
 }
 
 // Builds the Web+Data image by injecting the latest data into the web image
@@ -171,17 +224,18 @@ func (c *Chapauy) BuildWebData(
 		From(infra.Images.Web)
 
 	// 3. Inject Data into Web
-	// We assume the data is at /app/db in the data image
+	// The data is at /app/db in the data image, apart from the documents
+	// archive at /app/archive, which the web doesn't need.
 	// And needs to be at /app/chapauy.duckdb in the web image
-	// Note: DataRefresh logic used:
-	// updatedDb := cliCtr.Directory("/app/db")
-	// webCtr.WithFile("/app/chapauy.duckdb", updatedDb.File("chapauy.duckdb"))
 
 	dbFile := dataCtr.Directory("/app/db").File("chapauy.duckdb")
+	// Precomputed by `impo update`, lets the landing page render without DuckDB
+	scoreboardFile := dataCtr.Directory("/app/db").File("scoreboard.json")
 
 	webDataCtr := webCtr.
 		WithUser("root"). // Switch to root to write file
 		WithFile("/app/chapauy.duckdb", dbFile).
+		WithFile("/app/scoreboard.json", scoreboardFile).
 		WithUser(distrolessUser) // Switch back to nonroot for runtime
 
 	if _, err := publish(ctx, tokenSecret, webDataCtr, infra.WebDataImageName); err != nil {
@@ -0,0 +1,104 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"dagger/chapauy/internal/dagger"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// manifestFile is the name of the manifest returned by DataRefresh.
+const manifestFile = "manifest.json"
+
+// runReportFile is the report `impo update` leaves next to the database
+// (impo.RunReportFile).
+const runReportFile = "run_report.json"
+
+// PublishedImage is an image pushed by a run.
+type PublishedImage struct {
+	Name   string `json:"name"`
+	Ref    string `json:"ref"`    // as returned by the registry, with the digest
+	Digest string `json:"digest"` // sha256:...
+}
+
+// RunManifest describes a run of the pipeline, for the automation that
+// follows it (release notes, the badge of the website).
+type RunManifest struct {
+	// Version is the output of `chapa version --json` of the CLI that ran.
+	Version         json.RawMessage  `json:"version,omitempty"`
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	DryRun          bool             `json:"dry_run"`
+	Images          []PublishedImage `json:"images"`
+	// Rows are the rows of each table of the published database.
+	Rows map[string]int64 `json:"rows"`
+	// Metrics are the totals of the phases of `impo update`.
+	Metrics json.RawMessage `json:"metrics,omitempty"`
+}
+
+// addImage records an image published under ref.
+func (m *RunManifest) addImage(name, ref string) {
+	img := PublishedImage{Name: name, Ref: ref}
+	if _, digest, ok := strings.Cut(ref, "@"); ok {
+		img.Digest = digest
+	}
+
+	m.Images = append(m.Images, img)
+}
+
+// readRunReport takes the rows and the metrics from the report of `impo
+// update`.
+func (m *RunManifest) readRunReport(ctx context.Context, f *dagger.File) error {
+	contents, err := f.Contents(ctx)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", runReportFile, err)
+	}
+
+	var report struct {
+		Metrics json.RawMessage  `json:"metrics"`
+		Rows    map[string]int64 `json:"rows"`
+	}
+
+	if err := json.Unmarshal([]byte(contents), &report); err != nil {
+		return fmt.Errorf("decoding %s: %w", runReportFile, err)
+	}
+
+	m.Metrics, m.Rows = report.Metrics, report.Rows
+
+	return nil
+}
+
+// finish stamps the end of the run.
+func (m *RunManifest) finish(now time.Time) {
+	m.FinishedAt = now
+	m.DurationSeconds = now.Sub(m.StartedAt).Seconds()
+}
+
+// Write writes the manifest as indented JSON.
+func (m *RunManifest) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(m); err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	return nil
+}
+
+// file returns the manifest as a file to export.
+func (m *RunManifest) file() (*dagger.File, error) {
+	var b strings.Builder
+	if err := m.Write(&b); err != nil {
+		return nil, err
+	}
+
+	return dag.Directory().WithNewFile(manifestFile, b.String()).File(manifestFile), nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Smoke tests of the built images
+package main
+
+import (
+	"context"
+	"dagger/chapauy/internal/dagger"
+	"fmt"
+)
+
+// smokeDocument is a tiny IMPO document, enough to exercise the extraction.
+const smokeDocument = `<html>
+<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Maldonado N° 1/025</title>
+<h5>Fecha de Publicación: 01/02/2025</h5>
+<table class="tabla_en_texto">
+<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD><TD><pre>Interseccion</pre></TD><TD><pre>Intervenido</pre></TD><TD><pre>Articulo</pre></TD><TD><pre>Valor en UR</pre></TD></TR>
+<TR><TD><pre>AAB1234</pre></TD><TD><pre>15/01/2025 10:30</pre></TD><TD><pre>Av. Roosevelt y Parada 5</pre></TD><TD><pre>IDM 0000000001</pre></TD><TD><pre>Exceso de velocidad hasta 20 km/h</pre></TD><TD><pre>2,5</pre></TD></TR>
+</table></html>`
+
+// Runs the CLI image: it must start, have the timezone data and extract a
+// document
+func (c *Chapauy) SmokeTestCli(
+	ctx context.Context,
+	cli *dagger.Container,
+) error {
+	_, err := cli.
+		WithNewFile("/tmp/smoke.html", smokeDocument).
+		WithExec([]string{"/app/chapa", "version"}).
+		WithExec([]string{"/app/chapa", "selfcheck"}).
+		WithExec([]string{"/app/chapa", "debug", "document", "/tmp/smoke.html"}).
+		Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("cli smoke test: %w", err)
+	}
+
+	return nil
+}
+
+// Runs the web image as a service: it must answer /healthz
+func (c *Chapauy) SmokeTestWeb(
+	ctx context.Context,
+	web *dagger.Container,
+) error {
+	_, err := dag.Container().
+		From("curlimages/curl:8.11.1").
+		WithServiceBinding("web", web.AsService()).
+		WithExec([]string{
+			"curl",
+			"--fail",
+			"--silent",
+			"--show-error",
+			"--retry", "10",
+			"--retry-connrefused",
+			"--retry-delay", "1",
+			"http://web:3000/healthz",
+		}).
+		Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("web smoke test: %w", err)
+	}
+
+	return nil
+}
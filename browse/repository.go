@@ -0,0 +1,373 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package browse serves the public read API and a plain HTML browser of the
+// dataset from the DuckDB file, to explore it locally without the Node
+// toolchain of the web.
+package browse
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/articles"
+	"github.com/jcodagnone/chapauy/sucive"
+)
+
+// The dimensions a Filter restricts, named as the query parameters of the
+// public API (see Dimension in web/lib/types.ts).
+const (
+	DimDatabase    = "database"
+	DimYear        = "year"
+	DimCountry     = "country"
+	DimVehicleType = "vehicle_type"
+	DimVehicle     = "vehicle"
+	DimDocSource   = "doc_source"
+	DimLocation    = "location"
+	DimDescription = "description"
+	DimArticleID   = "article_id"
+	DimArticleCode = "article_code"
+	DimFeatures    = "features"
+	DimDate        = "date"
+)
+
+// Dimensions are the dimensions a Filter accepts, in the order the browser
+// shows them.
+var Dimensions = []string{
+	DimDatabase, DimYear, DimDate, DimCountry, DimVehicleType, DimVehicle,
+	DimDocSource, DimLocation, DimDescription, DimArticleID, DimArticleCode, DimFeatures,
+}
+
+// ErrUnknownDimension is returned for filters on a dimension the API doesn't
+// have.
+var ErrUnknownDimension = errors.New("unknown dimension")
+
+// columns are the expressions of the dimensions compared by equality.
+var columns = map[string]string{
+	DimDatabase:  "db_id",
+	DimYear:      "time_year",
+	DimCountry:   "vehicle_country",
+	DimVehicle:   "vehicle",
+	DimDocSource: "doc_source",
+	DimLocation:  "location",
+	DimDate:      "CAST(time AS DATE)",
+}
+
+// features are the conditions of the values of DimFeatures.
+var features = map[string]string{
+	"with_error": "error IS NOT NULL",
+	"no_error":   "error IS NULL",
+	"with_ur":    "(ur IS NOT NULL AND ur != 0)",
+	"no_ur":      "(ur IS NULL OR ur = 0)",
+}
+
+// Filter restricts the offenses to the ones with any of the values of each of
+// its dimensions.
+type Filter map[string][]string
+
+// byDocument tells whether the offenses are listed in the order of their
+// document, to compare them side by side with it.
+func (f Filter) byDocument() bool {
+	return len(f) == 1 && len(f[DimDocSource]) > 0
+}
+
+// where returns the condition of the filter, "true" when empty.
+func (f Filter) where() (string, []any, error) {
+	var (
+		clauses []string
+		args    []any
+	)
+
+	// sorted, so the same filter is the same query
+	dims := make([]string, 0, len(f))
+	for dim := range f {
+		dims = append(dims, dim)
+	}
+
+	slices.Sort(dims)
+
+	for _, dim := range dims {
+		values := f[dim]
+		if len(values) == 0 {
+			continue
+		}
+
+		var or []string
+
+		switch dim {
+		case DimDatabase, DimYear, DimCountry, DimVehicle, DimDocSource, DimLocation, DimDate:
+			or = append(or, columns[dim]+" IN (?"+strings.Repeat(", ?", len(values)-1)+")")
+			for _, v := range values {
+				args = append(args, v)
+			}
+		case DimDescription:
+			for _, v := range values {
+				or = append(or, "description ILIKE ?")
+				args = append(args, "%"+v+"%")
+			}
+		case DimVehicleType:
+			for _, v := range values {
+				if v == "" {
+					or = append(or, "vehicle_type IS NULL")
+
+					continue
+				}
+
+				or = append(or, "vehicle_type = ?")
+				args = append(args, v)
+			}
+		case DimArticleID, DimArticleCode:
+			column := "article_ids"
+			if dim == DimArticleCode {
+				column = "article_codes"
+			}
+
+			for _, v := range values {
+				or = append(or, "list_contains("+column+", ?)")
+				args = append(args, v)
+			}
+		case DimFeatures:
+			for _, v := range values {
+				cond, ok := features[v]
+				if !ok {
+					return "", nil, fmt.Errorf("%w: %s=%s", ErrUnknownDimension, dim, v)
+				}
+
+				or = append(or, cond)
+			}
+		default:
+			return "", nil, fmt.Errorf("%w: %s", ErrUnknownDimension, dim)
+		}
+
+		clauses = append(clauses, "("+strings.Join(or, " OR ")+")")
+	}
+
+	if len(clauses) == 0 {
+		return "true", nil, nil
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// Offense is an offense as the public API returns it (Offense in
+// web/lib/types.ts), without its point, which needs the spatial extension.
+type Offense struct {
+	RepoID          int        `json:"repo_id"`
+	DocSource       string     `json:"doc_source"`
+	DocID           string     `json:"doc_id"`
+	DocDate         *time.Time `json:"doc_date"`
+	RecordID        int        `json:"record_id"`
+	ID              string     `json:"id"`
+	Time            *time.Time `json:"time"`
+	Location        string     `json:"location"`
+	DisplayLocation string     `json:"display_location,omitempty"`
+	Description     string     `json:"description"`
+	Vehicle         string     `json:"vehicle"`
+	VehicleType     string     `json:"vehicle_type"`
+	Country         string     `json:"country"`
+	UR              int        `json:"ur"`
+	Error           string     `json:"error,omitempty"`
+	ArticleIDs      []string   `json:"article_id,omitempty"`
+	// PaymentStatus is the sucive.Status of the offense when its plate was
+	// looked up, only served locally (see serve --payments)
+	PaymentStatus string `json:"payment_status,omitempty"`
+}
+
+// Summary are the totals of the offenses of a filter.
+type Summary struct {
+	RecordCount int     `json:"record_count"`
+	TotalUR     int64   `json:"total_ur"`
+	AvgUR       float64 `json:"avg_ur"`
+}
+
+// Article is an article of the traffic regulations.
+type Article struct {
+	ID    string `json:"id"`
+	Code  int    `json:"code"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// Repository reads the published database.
+type Repository interface {
+	// ListOffenses lists a page, from 1, of the offenses of the filter.
+	ListOffenses(f Filter, page, perPage int) ([]Offense, error)
+	Summarize(f Filter) (Summary, error)
+	// ListArticles lists the articles, empty when the database has none.
+	ListArticles() ([]Article, error)
+}
+
+type sqlRepository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a repository of the database.
+func NewRepository(db *sql.DB) Repository {
+	return &sqlRepository{db: db}
+}
+
+func (r *sqlRepository) ListOffenses(f Filter, page, perPage int) ([]Offense, error) {
+	where, args, err := f.where()
+	if err != nil {
+		return nil, err
+	}
+
+	order := "time DESC, doc_id, record_id"
+	if f.byDocument() {
+		order = "doc_id, record_id"
+	}
+
+	args = append(args, perPage, (max(page, 1)-1)*perPage)
+
+	payments, err := r.paymentsTable()
+	if err != nil {
+		return nil, err
+	}
+
+	from, status := "active_offenses", "''"
+	if payments != "" {
+		from = "active_offenses LEFT JOIN " + payments + " USING (db_id, doc_source, record_id)"
+		status = "COALESCE(status, '')"
+	}
+
+	// #nosec G202 - the filter is built from placeholders
+	rows, err := r.db.Query(`
+		SELECT
+			db_id, doc_source, COALESCE(doc_id, ''), doc_date, record_id, COALESCE(offense_id, ''),
+			time, COALESCE(location, ''), COALESCE(display_location, ''), COALESCE(description, ''),
+			COALESCE(vehicle, ''), COALESCE(vehicle_type, ''), COALESCE(vehicle_country, ''),
+			COALESCE(ur, 0), COALESCE(error, ''), article_ids, `+status+`
+		FROM `+from+`
+		WHERE `+where+`
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses: %w", err)
+	}
+	defer rows.Close()
+
+	ret := []Offense{}
+
+	for rows.Next() {
+		var (
+			o              Offense
+			docDate, when  sql.NullTime
+			articleIDsList any
+		)
+
+		if err := rows.Scan(
+			&o.RepoID, &o.DocSource, &o.DocID, &docDate, &o.RecordID, &o.ID,
+			&when, &o.Location, &o.DisplayLocation, &o.Description,
+			&o.Vehicle, &o.VehicleType, &o.Country,
+			&o.UR, &o.Error, &articleIDsList, &o.PaymentStatus,
+		); err != nil {
+			return nil, fmt.Errorf("scanning offense: %w", err)
+		}
+
+		if docDate.Valid {
+			o.DocDate = &docDate.Time
+		}
+
+		if when.Valid {
+			o.Time = &when.Time
+		}
+
+		ids, _ := articleIDsList.([]any)
+		for _, id := range ids {
+			if s, ok := id.(string); ok {
+				o.ArticleIDs = append(o.ArticleIDs, s)
+			}
+		}
+
+		ret = append(ret, o)
+	}
+
+	return ret, rows.Err()
+}
+
+// paymentsTable returns the table with the payment status of the plates
+// looked up in SUCIVE, empty when the database has none attached.
+func (r *sqlRepository) paymentsTable() (string, error) {
+	var database string
+
+	err := r.db.QueryRow(
+		"SELECT database_name FROM duckdb_tables() WHERE table_name = ? AND NOT temporary LIMIT 1",
+		sucive.PaymentsTable,
+	).Scan(&database)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("looking up payments: %w", err)
+	}
+
+	return fmt.Sprintf(`"%s".%s`, database, sucive.PaymentsTable), nil
+}
+
+func (r *sqlRepository) Summarize(f Filter) (Summary, error) {
+	var s Summary
+
+	where, args, err := f.where()
+	if err != nil {
+		return s, err
+	}
+
+	// #nosec G202 - the filter is built from placeholders
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(ur), 0), COALESCE(AVG(ur), 0)
+		FROM active_offenses
+		WHERE `+where, args...).Scan(&s.RecordCount, &s.TotalUR, &s.AvgUR); err != nil {
+		return s, fmt.Errorf("summarizing offenses: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *sqlRepository) ListArticles() ([]Article, error) {
+	ret := []Article{}
+
+	var exists bool
+	if err := r.db.QueryRow(
+		"SELECT COUNT(*) > 0 FROM duckdb_tables() WHERE table_name = 'articles' AND NOT temporary",
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("looking up articles: %w", err)
+	} else if !exists {
+		return ret, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(code, 0), COALESCE(title, ''), COALESCE(text, '')
+		FROM articles
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying articles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Article
+		if err := rows.Scan(&a.ID, &a.Code, &a.Title, &a.Text); err != nil {
+			return nil, fmt.Errorf("scanning article: %w", err)
+		}
+
+		ret = append(ret, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating articles: %w", err)
+	}
+
+	// SQL would sort "15.10" before "15.4"
+	slices.SortFunc(ret, func(a, b Article) int {
+		return cmp.Or(cmp.Compare(a.Code, b.Code), articles.CompareIDs(a.ID, b.ID))
+	})
+
+	return ret, nil
+}
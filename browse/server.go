@@ -0,0 +1,277 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package browse
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/i18n"
+)
+
+// The page sizes of the offenses, as in the public API.
+const (
+	DefaultPerPage  = 20
+	DocumentPerPage = 1500 // when listing the offenses of a document
+	MaxPerPage      = 1500
+	browserPerPage  = 50
+)
+
+// The query parameters of the page.
+const (
+	paramPage    = "page"
+	paramPerPage = "per_page"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+// Server serves the public read API and the browser of the dataset.
+type Server struct {
+	repo  Repository
+	dbMap map[int]string
+}
+
+// NewServer creates the server of a database, dbMap naming its databases.
+func NewServer(repo Repository, dbMap map[int]string) *Server {
+	return &Server{repo: repo, dbMap: dbMap}
+}
+
+// Handler returns the handler of the routes of the server.
+func (s *Server) Handler() http.Handler {
+	r := gin.Default()
+	r.SetHTMLTemplate(template.Must(template.New("").Funcs(templateFuncs).ParseFS(templates, "templates/*.html")))
+
+	r.GET("/", s.browserView)
+	r.GET("/healthz", func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
+	r.GET("/api/v1/offenses", s.listOffenses)
+	r.GET("/api/v1/articles", s.listArticles)
+
+	return r
+}
+
+// templateFuncs are the functions of the templates of the browser.
+var templateFuncs = template.FuncMap{
+	// the fines are stored in thousandths of UR, see impo.UR
+	"ur": func(v any) string {
+		switch v := v.(type) {
+		case int:
+			return impo.UR(v).String()
+		case int64:
+			return impo.UR(v).String()
+		default:
+			return fmt.Sprint(v)
+		}
+	},
+}
+
+// Run serves on addr until it fails.
+func (s *Server) Run(addr string) error {
+	return http.ListenAndServe(addr, s.Handler()) // #nosec G114 - local only
+}
+
+func (s *Server) dbName(id int) string {
+	if name, ok := s.dbMap[id]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("DB %d", id)
+}
+
+// errBadParameter is returned for query parameters the API doesn't have or
+// can't read.
+var errBadParameter = errors.New("bad parameter")
+
+// parseQuery reads the filter and the page of the query parameters.
+func parseQuery(q url.Values) (f Filter, page, perPage int, err error) {
+	f = make(Filter)
+	page = 1
+
+	for key, values := range q {
+		switch {
+		case key == paramPage:
+			if page, err = strconv.Atoi(values[0]); err != nil || page < 1 {
+				return nil, 0, 0, fmt.Errorf("%w: %s=%s", errBadParameter, key, values[0])
+			}
+		case key == paramPerPage:
+			if perPage, err = strconv.Atoi(values[0]); err != nil || perPage < 1 || perPage > MaxPerPage {
+				return nil, 0, 0, fmt.Errorf("%w: %s=%s", errBadParameter, key, values[0])
+			}
+		case slices.Contains(Dimensions, key):
+			for _, v := range values {
+				if key == DimVehicle {
+					v = impo.NormalizeVehicleID(v)
+				}
+
+				f[key] = append(f[key], v)
+			}
+		default:
+			return nil, 0, 0, fmt.Errorf("%w: %s", errBadParameter, key)
+		}
+	}
+
+	if perPage == 0 {
+		perPage = DefaultPerPage
+		if f.byDocument() {
+			perPage = DocumentPerPage
+		}
+	}
+
+	return f, page, perPage, nil
+}
+
+type repo struct {
+	Name string `json:"name"`
+}
+
+type pagination struct {
+	CurrentPage int `json:"current_page"`
+	TotalPages  int `json:"total_pages"`
+}
+
+// OffensesResponse is the response of /api/v1/offenses, a subset of the one
+// of the web (OffensesResponse in web/lib/types.ts).
+type OffensesResponse struct {
+	Offenses   []Offense       `json:"offenses"`
+	Pagination pagination      `json:"pagination"`
+	Repos      map[string]repo `json:"repos"`
+	Summary    Summary         `json:"summary"`
+}
+
+// offenses reads a page of the offenses of the filter.
+func (s *Server) offenses(f Filter, page, perPage int) (*OffensesResponse, error) {
+	offenses, err := s.repo.ListOffenses(f, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.Summarize(f)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make(map[string]repo)
+	for _, o := range offenses {
+		repos[strconv.Itoa(o.RepoID)] = repo{Name: s.dbName(o.RepoID)}
+	}
+
+	return &OffensesResponse{
+		Offenses: offenses,
+		Pagination: pagination{
+			CurrentPage: page,
+			TotalPages:  (summary.RecordCount + perPage - 1) / perPage,
+		},
+		Repos:   repos,
+		Summary: summary,
+	}, nil
+}
+
+func (s *Server) listOffenses(ctx *gin.Context) {
+	f, page, perPage, err := parseQuery(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid query parameter") + ": " + err.Error()})
+
+		return
+	}
+
+	resp, err := s.offenses(f, page, perPage)
+	if errors.Is(err, ErrUnknownDimension) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid query parameter") + ": " + err.Error()})
+
+		return
+	} else if err != nil {
+		log.Printf("listing offenses: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("failed to list offenses")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) listArticles(ctx *gin.Context) {
+	articles, err := s.repo.ListArticles()
+	if err != nil {
+		log.Printf("listing articles: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("failed to list articles")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"articles": articles})
+}
+
+type option struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+func (s *Server) browserView(ctx *gin.Context) {
+	data := gin.H{}
+
+	query := ctx.Request.URL.Query()
+	for key, values := range query {
+		// the empty fields of the form filter nothing
+		if len(values) == 1 && values[0] == "" {
+			query.Del(key)
+		}
+	}
+
+	f, page, _, err := parseQuery(query)
+	if err == nil {
+		var resp *OffensesResponse
+		if resp, err = s.offenses(f, page, browserPerPage); err == nil {
+			data["Response"] = resp
+		}
+	}
+
+	if err != nil {
+		data["Error"] = err.Error()
+		page = 1
+	}
+
+	var databases []option
+	for _, id := range slices.Sorted(maps.Keys(s.dbMap)) {
+		v := strconv.Itoa(id)
+		databases = append(databases, option{Value: v, Label: s.dbName(id), Selected: slices.Contains(f[DimDatabase], v)})
+	}
+
+	data["Databases"] = databases
+	data["Query"] = query
+	data["Page"] = page
+	data["PrevURL"] = pageURL(query, page-1)
+	data["NextURL"] = pageURL(query, page+1)
+	data["APIURL"] = apiURL(query, page)
+
+	ctx.HTML(http.StatusOK, "index.html", data)
+}
+
+// pageURL returns the URL of another page of the browser.
+func pageURL(q url.Values, page int) string {
+	q = maps.Clone(q)
+	q.Set(paramPage, strconv.Itoa(page))
+
+	return "/?" + q.Encode()
+}
+
+// apiURL returns the URL of the offenses of a page of the browser in the API,
+// with its filter and page size.
+func apiURL(q url.Values, page int) string {
+	q = maps.Clone(q)
+	q.Set(paramPage, strconv.Itoa(page))
+	q.Set(paramPerPage, strconv.Itoa(browserPerPage))
+
+	return "/api/v1/offenses?" + q.Encode()
+}
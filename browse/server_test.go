@@ -0,0 +1,193 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package browse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	return NewServer(NewRepository(setupDB(t)), map[int]string{45: "Montevideo", 46: "Canelones"}).Handler()
+}
+
+func setupDB(t *testing.T) *sql.DB {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// the columns of offenses the API reads
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_date DATE, doc_source VARCHAR, record_id INTEGER,
+			offense_id VARCHAR, vehicle VARCHAR, vehicle_country CHAR(2), vehicle_type VARCHAR,
+			"time" TIMESTAMPTZ, time_year USMALLINT, location VARCHAR, display_location VARCHAR,
+			description VARCHAR, ur INTEGER, error VARCHAR, article_ids VARCHAR[], article_codes TINYINT[],
+			superseded_by VARCHAR
+		);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO offenses VALUES
+			(45, 'R/1', '2024-01-10', 'doc1', 1, 'A1', 'SBA1234', 'UY', 'Auto', '2024-01-02 10:00:00-03', 2024,
+				'AV ITALIA Y GARIBALDI', NULL, 'EXCESO DE VELOCIDAD', 8, NULL, ['18.7.1'], [18], NULL),
+			(45, 'R/1', '2024-01-10', 'doc1', 2, 'A2', 'SBB1234', 'UY', 'Moto', '2024-01-03 10:00:00-03', 2024,
+				'RAMBLA', NULL, 'SEMAFORO ROJO', 10, NULL, ['13.3'], [13], NULL),
+			(46, 'R/2', '2023-05-10', 'doc2', 1, 'B1', 'ABC1234', 'AR', NULL, '2023-05-01 10:00:00-03', 2023,
+				'RUTA 1', NULL, 'EXCESO DE VELOCIDAD', NULL, 'UR inválida', NULL, NULL, NULL),
+			-- doc1 re-publishes doc0, so A1 must be counted once
+			(45, 'R/0', '2024-01-05', 'doc0', 1, 'A1', 'SBA1234', 'UY', 'Auto', '2024-01-02 10:00:00-03', 2024,
+				'AV ITALIA Y GARIBALDI', NULL, 'EXCESO DE VELOCIDAD', 8, NULL, ['18.7.1'], [18], 'doc1');
+		CREATE TABLE articles (id VARCHAR, text VARCHAR, code TINYINT, title VARCHAR);
+		INSERT INTO articles VALUES ('18.7.1', 'Exceso de velocidad', 18, 'Velocidad');
+	`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func getOffenses(t *testing.T, h http.Handler, query string) (*httptest.ResponseRecorder, OffensesResponse) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/offenses?"+query, nil))
+
+	var resp OffensesResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+
+	return w, resp
+}
+
+func TestServer_Offenses(t *testing.T) {
+	h := setupServer(t)
+
+	w, resp := getOffenses(t, h, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, resp.Summary.RecordCount)
+	assert.Equal(t, int64(18), resp.Summary.TotalUR)
+	// the most recent first
+	assert.Equal(t, "B1", resp.Offenses[2].ID)
+	assert.Equal(t, "Montevideo", resp.Repos["45"].Name)
+
+	_, resp = getOffenses(t, h, "description=velocidad&year=2024")
+	require.Len(t, resp.Offenses, 1)
+	assert.Equal(t, []string{"18.7.1"}, resp.Offenses[0].ArticleIDs)
+
+	_, resp = getOffenses(t, h, "vehicle=sba-1234")
+	require.Len(t, resp.Offenses, 1)
+	assert.Equal(t, "A1", resp.Offenses[0].ID)
+
+	_, resp = getOffenses(t, h, "features=with_error")
+	require.Len(t, resp.Offenses, 1)
+	assert.Equal(t, "UR inválida", resp.Offenses[0].Error)
+
+	_, resp = getOffenses(t, h, "article_code=13&database=45&database=46")
+	require.Len(t, resp.Offenses, 1)
+	assert.Equal(t, "A2", resp.Offenses[0].ID)
+
+	// the offenses of a document follow its order
+	_, resp = getOffenses(t, h, "doc_source=doc1")
+	require.Len(t, resp.Offenses, 2)
+	assert.Equal(t, "A1", resp.Offenses[0].ID)
+
+	// superseded by doc1
+	_, resp = getOffenses(t, h, "doc_source=doc0")
+	assert.Empty(t, resp.Offenses)
+	assert.Equal(t, 0, resp.Summary.RecordCount)
+
+	_, resp = getOffenses(t, h, "per_page=1&page=2")
+	assert.Equal(t, pagination{CurrentPage: 2, TotalPages: 3}, resp.Pagination)
+	require.Len(t, resp.Offenses, 1)
+
+	for _, query := range []string{"color=red", "page=0", "per_page=5000", "features=with_bugs"} {
+		w, _ = getOffenses(t, h, query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestServer_Payments(t *testing.T) {
+	db := setupDB(t)
+	h := NewServer(NewRepository(db), map[int]string{45: "Montevideo", 46: "Canelones"}).Handler()
+
+	_, resp := getOffenses(t, h, "vehicle=SBA1234")
+	require.Len(t, resp.Offenses, 1)
+	assert.Empty(t, resp.Offenses[0].PaymentStatus)
+
+	// as attached by serve --payments
+	_, err := db.Exec(`
+		ATTACH ':memory:' AS payments;
+		CREATE TABLE payments.payment_status (db_id INTEGER, doc_source VARCHAR, record_id INTEGER, status VARCHAR);
+		INSERT INTO payments.payment_status VALUES (45, 'doc1', 1, 'pending');
+	`)
+	require.NoError(t, err)
+
+	_, resp = getOffenses(t, h, "vehicle=SBA1234")
+	require.Len(t, resp.Offenses, 1)
+	assert.Equal(t, "pending", resp.Offenses[0].PaymentStatus)
+
+	_, resp = getOffenses(t, h, "database=45")
+	require.Len(t, resp.Offenses, 2)
+	assert.Empty(t, resp.Offenses[0].PaymentStatus)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?vehicle=SBA1234", nil))
+	assert.Contains(t, w.Body.String(), "<td>pendiente</td>")
+}
+
+func TestServer_Articles(t *testing.T) {
+	h := setupServer(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/articles", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Articles []Article `json:"articles"`
+	}
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []Article{{ID: "18.7.1", Code: 18, Title: "Velocidad", Text: "Exceso de velocidad"}}, resp.Articles)
+}
+
+func TestServer_Browser(t *testing.T) {
+	h := setupServer(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?database=46&year=", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "ABC1234")
+	assert.NotContains(t, body, "SBA1234")
+	assert.Contains(t, body, `<option value="46" selected>Canelones</option>`)
+	// the link to the API keeps the filter
+	assert.Contains(t, body, `href="/api/v1/offenses?database=46&amp;page=1&amp;per_page=50"`)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?database=45", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// in UR, not in thousandths
+	body = w.Body.String()
+	assert.Contains(t, body, "0.018 UR en total")
+	assert.Contains(t, body, `<td class="number">0.008</td>`)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?color=red", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "bad parameter: color")
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/spf13/cobra"
+)
+
+// The shell completions come from the completion command cobra adds (chapa
+// completion bash|zsh|fish|powershell); these complete the names of the
+// databases, which cobra can't know.
+
+// completeDBNames completes the name of a database, as accepted by impo.Find,
+// described by its id.
+func completeDBNames(toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ret []string
+
+	_ = impo.Each(func(db impo.DbReference) error {
+		if len(db.Name) >= len(toComplete) && strings.EqualFold(db.Name[:len(toComplete)], toComplete) {
+			ret = append(ret, fmt.Sprintf("%s\t%d", db.Name, db.ID))
+		}
+
+		return nil
+	})
+
+	return ret, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDBArg completes the optional database argument of dbArg.
+func completeDBArg(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeDBNames(toComplete)
+}
+
+// completeDBFlag completes a flag taking a database.
+func completeDBFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeDBNames(toComplete)
+}
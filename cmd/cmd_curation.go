@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,25 +12,62 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
-	_ "github.com/duckdb/duckdb-go/v2" // register duckdb driver
 	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/curation/credentials"
 	"github.com/jcodagnone/chapauy/curation/utils"
 	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
 	"github.com/spf13/cobra"
 )
 
 const judgmentsFile = "judgments.json"
 
 type CurationData struct {
-	Articles     []curation.Article      `json:"articles"`
-	Descriptions []*curation.Description `json:"descriptions"`
-	Locations    []*curation.Location    `json:"locations"`
+	Articles           []curation.Article            `json:"articles"`
+	Descriptions       []*curation.Description       `json:"descriptions"`
+	Locations          []*curation.Location          `json:"locations"`
+	CanonicalLocations []*curation.CanonicalLocation `json:"canonical_locations,omitempty"`
 }
 
 var curationCmd = &cobra.Command{
-	Use:   "curation",
-	Short: "Manage the interactive curation workflow",
+	Use:     "curation",
+	Aliases: []string{"cur"},
+	Short:   "Manage the interactive curation workflow",
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		return loadLocationRules()
+	},
+}
+
+var (
+	serveReadOnly      bool
+	serveRequireTokens bool
+	mapsKeySource      string
+	mapsKeyConfig      credentials.Config
+)
+
+// mapsAPIKey reads the Google Maps API key from --maps-key-source. When every
+// source of the auto chain fails the server runs without geocoding, logging
+// why; a source chosen explicitly must work.
+func mapsAPIKey(ctx context.Context) (string, error) {
+	provider, err := credentials.New(mapsKeySource, mapsKeyConfig)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := provider.APIKey(ctx)
+	if err == nil {
+		return key, nil
+	}
+
+	if mapsKeySource != credentials.SourceAuto {
+		return "", fmt.Errorf("reading the Google Maps API key from %s: %w", provider.Name(), err)
+	}
+
+	log.Printf("⚠️ No Google Maps API key, geocoding suggestions are disabled:\n%v", err)
+
+	return "", nil
 }
 
 var curationServeCmd = &cobra.Command{
@@ -46,9 +84,14 @@ var curationServeCmd = &cobra.Command{
 			return fmt.Errorf("database not found at %s - run 'seed' or 'impo update' first", dbpath)
 		}
 
-		db, err := sql.Open("duckdb", dbpath)
+		mode := dbutils.ReadWrite
+		if serveReadOnly {
+			mode = dbutils.ReadOnly
+		}
+
+		db, err := openDB(mode)
 		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+			return err
 		}
 		defer db.Close()
 
@@ -63,8 +106,10 @@ var curationServeCmd = &cobra.Command{
 		}
 
 		locRepo := curation.NewLocationRepository(db, dbMap)
-		if err := locRepo.CreateSchema(); err != nil {
-			return fmt.Errorf("creating geocoding schema: %w", err)
+		if !serveReadOnly {
+			if err := locRepo.CreateSchema(); err != nil {
+				return fmt.Errorf("creating geocoding schema: %w", err)
+			}
 		}
 
 		// Load radar index
@@ -74,8 +119,60 @@ var curationServeCmd = &cobra.Command{
 		}
 
 		descrRepo := curation.NewDescriptionRepository(db)
-		if err := descrRepo.CreateSchema(); err != nil {
-			return fmt.Errorf("creating description schema: %w", err)
+		if !serveReadOnly {
+			if err := descrRepo.CreateSchema(); err != nil {
+				return fmt.Errorf("creating description schema: %w", err)
+			}
+		}
+
+		if !serveReadOnly {
+			if err := curation.NewOutlierRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating outlier schema: %w", err)
+			}
+
+			if err := curation.NewAuditRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating audit schema: %w", err)
+			}
+
+			if err := curation.NewHeaderRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating headers schema: %w", err)
+			}
+
+			if err := curation.NewValueRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating values schema: %w", err)
+			}
+
+			if err := curation.NewVehicleOverrideRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating vehicle overrides schema: %w", err)
+			}
+
+			if err := curation.NewStreetAliasRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating street aliases schema: %w", err)
+			}
+
+			if err := curation.NewCuratorRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating curator schema: %w", err)
+			}
+
+			if err := curation.NewFailureRepository(db).CreateSchema(); err != nil {
+				return fmt.Errorf("creating failures schema: %w", err)
+			}
+
+			geocodeCache := curation.NewGeocodeCacheRepository(db)
+			if err := geocodeCache.CreateSchema(); err != nil {
+				return fmt.Errorf("creating geocode cache schema: %w", err)
+			}
+
+			if n, err := geocodeCache.PurgeExpiredGeocodes(time.Now()); err != nil {
+				return err
+			} else if n > 0 {
+				log.Printf("🧹 Purged %d expired geocoding results", n)
+			}
+		}
+
+		apiKey, err := mapsAPIKey(context.Background())
+		if err != nil {
+			return err
 		}
 
 		server := curation.NewServer(
@@ -83,7 +180,11 @@ var curationServeCmd = &cobra.Command{
 			db, // Pass db directly
 			radarIndex,
 			dbMap,
+			apiKey,
 		)
+		server.SetReadOnly(serveReadOnly)
+		server.SetRequireTokens(serveRequireTokens)
+		server.SetDocumentsPath(impoOptions.DocumentsPath())
 
 		fmt.Println("🗺️  Geocoding workflow server starting...")
 		fmt.Println("📍 Open http://localhost:8080 in your browser")
@@ -99,10 +200,9 @@ var curationStoreCmd = &cobra.Command{
 	Long:  `Exports all location judgments from the database to a local JSON file. The file is sorted to minimize diffs when checking into version control.`,
 	Args:  cobra.NoArgs,
 	RunE: func(_ *cobra.Command, _ []string) error {
-		dbpath := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
-		db, err := sql.Open("duckdb", dbpath)
+		db, err := openDB(dbutils.ReadOnly)
 		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+			return err
 		}
 		defer db.Close()
 
@@ -112,6 +212,15 @@ var curationStoreCmd = &cobra.Command{
 			return fmt.Errorf("getting location judgments: %w", err)
 		}
 
+		canonicalLocations, err := repo.ListCanonicalLocations()
+		if err != nil {
+			return fmt.Errorf("getting canonical locations: %w", err)
+		}
+
+		for _, c := range canonicalLocations {
+			c.References = 0 // derived, keep it out of the file
+		}
+
 		descrRepo := curation.NewDescriptionRepository(db)
 		descriptions, err := descrRepo.GetAllDescriptionJudgmentsSorted()
 		if err != nil {
@@ -125,9 +234,10 @@ var curationStoreCmd = &cobra.Command{
 
 		data, err := json.MarshalIndent(
 			CurationData{
-				Articles:     articles,
-				Descriptions: descriptions,
-				Locations:    locations,
+				Articles:           articles,
+				Descriptions:       descriptions,
+				Locations:          locations,
+				CanonicalLocations: canonicalLocations,
 			},
 			"",
 			"  ",
@@ -157,10 +267,9 @@ var curationLoadCmd = &cobra.Command{
 After importing, it updates the offenses table with the geocoding information.`,
 	Args: cobra.NoArgs,
 	RunE: func(_ *cobra.Command, _ []string) error {
-		dbpath := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
-		db, err := sql.Open("duckdb", dbpath)
+		db, err := openDB(dbutils.ReadWrite)
 		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+			return err
 		}
 		defer db.Close()
 
@@ -178,6 +287,13 @@ func ensureCurationDataLoaded(db *sql.DB) error {
 		return fmt.Errorf("creating geocoding schema: %w", err)
 	}
 
+	// judgments saved before the nearest place was computed
+	if n, err := locRepo.BackfillNearestPlaces(); err != nil {
+		return fmt.Errorf("backfilling nearest places: %w", err)
+	} else if n > 0 {
+		log.Printf("✅ Named %s judgments after their nearest place\n", utils.FormatInt(n))
+	}
+
 	descrRepo := curation.NewDescriptionRepository(db)
 	if err := descrRepo.CreateSchema(); err != nil {
 		return fmt.Errorf("creating description schema: %w", err)
@@ -277,38 +393,40 @@ func ensureCurationDataLoaded(db *sql.DB) error {
 
 	log.Println("♻️  Reloading curation data...")
 
-	// Clear tables
-	if _, err := db.Exec("DELETE FROM locations"); err != nil {
-		return fmt.Errorf("clearing locations: %w", err)
-	}
+	// the reload is staged and swapped atomically: a malformed file leaves
+	// the current curation data as it was
+	if err := curation.Reload(db, func(staging *sql.DB) error {
+		stagingLocs, stagingDescrs := curation.NewLocationRepository(staging, nil), curation.NewDescriptionRepository(staging)
 
-	if _, err := db.Exec("DELETE FROM descriptions"); err != nil {
-		return fmt.Errorf("clearing descriptions: %w", err)
-	}
+		// Load the shared locations before the judgments referencing them
+		for _, c := range curationData.CanonicalLocations {
+			if err := stagingLocs.SaveCanonicalLocation(c); err != nil {
+				return fmt.Errorf("inserting canonical locations: %w", err)
+			}
+		}
 
-	if _, err := db.Exec("DELETE FROM articles"); err != nil {
-		return fmt.Errorf("clearing articles: %w", err)
-	}
+		// Load Location Judgments
+		if err := stagingLocs.BulkInsertJudgments(curationData.Locations); err != nil {
+			return fmt.Errorf("inserting location judgments: %w", err)
+		}
 
-	// Load Location Judgments
-	if err := locRepo.BulkInsertJudgments(curationData.Locations); err != nil {
-		return fmt.Errorf("inserting location judgments: %w", err)
-	}
+		// Load Articles
+		if err := stagingDescrs.SeedArticles(curationData.Articles); err != nil {
+			return fmt.Errorf("seeding articles: %w", err)
+		}
 
-	log.Printf("✅ Imported %s location judgments from %s\n", utils.FormatInt(int64(len(curationData.Locations))), judgmentsFile)
+		// Load Description Judgments
+		if err := stagingDescrs.BulkInsertDescriptionJudgments(curationData.Descriptions); err != nil {
+			return fmt.Errorf("inserting description judgments: %w", err)
+		}
 
-	// Load Articles
-	if err := descrRepo.SeedArticles(curationData.Articles); err != nil {
-		return fmt.Errorf("seeding articles: %w", err)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("reloading curation data from %s: %w", judgmentsFile, err)
 	}
 
+	log.Printf("✅ Imported %s location judgments from %s\n", utils.FormatInt(int64(len(curationData.Locations))), judgmentsFile)
 	log.Printf("✅ Imported %s articles from %s\n", utils.FormatInt(int64(len(curationData.Articles))), judgmentsFile)
-
-	// Load Description Judgments
-	if err := descrRepo.BulkInsertDescriptionJudgments(curationData.Descriptions); err != nil {
-		return fmt.Errorf("inserting description judgments: %w", err)
-	}
-
 	log.Printf("✅ Imported %s description judgments from %s\n", utils.FormatInt(int64(len(curationData.Descriptions))), judgmentsFile)
 
 	return nil
@@ -320,7 +438,27 @@ func backfillCurationData(db *sql.DB) error {
 		return fmt.Errorf("initializing repository: %w", err)
 	}
 
-	affected, err := repo.BackfillGeocodingData()
+	// judgments made before the intersections were compared regardless of
+	// the order of their streets
+	if n, err := curation.NewLocationRepository(db, nil).MergeMirroredIntersections(); err != nil {
+		return fmt.Errorf("merging mirrored intersections: %w", err)
+	} else if n > 0 {
+		log.Printf("✅ Merged %s judgments of intersections with their streets in another order\n", utils.FormatInt(int64(n)))
+	}
+
+	// the renamed streets first, so they get the judgments of the new names
+	if err := curation.NewStreetAliasRepository(db).CreateSchema(); err != nil {
+		return fmt.Errorf("creating street aliases schema: %w", err)
+	}
+
+	affected, err := repo.BackfillStreetAliases()
+	if err != nil {
+		return fmt.Errorf("backfilling street aliases: %w", err)
+	}
+
+	log.Printf("✅ Renamed the streets of %s offenses\n", utils.FormatInt(affected))
+
+	affected, err = repo.BackfillGeocodingData()
 	if err != nil {
 		return fmt.Errorf("backfilling geocoding data: %w", err)
 	}
@@ -347,6 +485,17 @@ func backfillCurationData(db *sql.DB) error {
 		utils.FormatInt(int64(pendingGeocodingOffenses)),
 		utils.FormatInt(int64(pendingGeocodingLocations)))
 
+	if err := curation.NewVehicleOverrideRepository(db).CreateSchema(); err != nil {
+		return fmt.Errorf("creating vehicle overrides schema: %w", err)
+	}
+
+	affected, err = repo.BackfillVehicleOverrides()
+	if err != nil {
+		return fmt.Errorf("backfilling vehicle overrides: %w", err)
+	}
+
+	log.Printf("✅ Backfilled %s offenses with the vehicle overrides\n", utils.FormatInt(affected))
+
 	affected, err = repo.BackportDescriptionArticles()
 	if err != nil {
 		return fmt.Errorf("backporting curation data: %w", err)
@@ -382,4 +531,52 @@ func init() {
 	curationCmd.AddCommand(curationServeCmd)
 	curationCmd.AddCommand(curationStoreCmd)
 	curationCmd.AddCommand(curationLoadCmd)
+	curationServeCmd.Flags().BoolVar(
+		&serveReadOnly,
+		"read-only",
+		false,
+		"Open the database read-only: browse without saving judgments",
+	)
+	curationServeCmd.Flags().BoolVar(
+		&serveRequireTokens,
+		"require-tokens",
+		false,
+		"Reject the API requests without a curator token (see 'curation token issue')",
+	)
+	curationServeCmd.Flags().StringVar(
+		&impoOptions.ArchivePath,
+		"archive-path",
+		"",
+		"Directory of the downloaded documents, to preview their extraction. Defaults to the directory of the database",
+	)
+	curationServeCmd.Flags().StringVar(
+		&mapsKeySource,
+		"maps-key-source",
+		credentials.SourceAuto,
+		"Where to read the Google Maps API key from: auto, env, file, secret-manager or api-keys",
+	)
+	curationServeCmd.Flags().StringVar(
+		&mapsKeyConfig.File,
+		"maps-key-file",
+		"",
+		"File with the Google Maps API key (file source)",
+	)
+	curationServeCmd.Flags().StringVar(
+		&mapsKeyConfig.Secret,
+		"maps-key-secret",
+		"",
+		"Secret Manager secret with the Google Maps API key, a name or projects/P/secrets/S[/versions/V] (secret-manager source)",
+	)
+	curationServeCmd.Flags().StringVar(
+		&mapsKeyConfig.Project,
+		"maps-key-project",
+		"",
+		"Google Cloud project of the secret-manager and api-keys sources. Defaults to the one of the credentials",
+	)
+	curationCmd.PersistentFlags().StringVar(
+		&locationRulesPath,
+		"location-rules",
+		"",
+		"JSON file with additional location cleanup rules per database, in the format of impo/location_rules.json",
+	)
 }
@@ -0,0 +1,102 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var evalOptions struct {
+	testFraction float64
+	seed         uint64
+	thresholds   []float64
+	confusions   int
+	perArticle   bool
+}
+
+var curationClassifyCmd = &cobra.Command{
+	Use:   "classify",
+	Short: "Description classifier tooling",
+}
+
+var curationClassifyEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Measure the description classifier against the curated descriptions",
+	Long: `Splits the classified descriptions into train and test sets, classifies the
+test set with a classifier that only knows the train set, and reports the
+precision and recall per article and the most frequent confusions.
+
+Pass several --threshold values to compare them on the same split.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		descrRepo := curation.NewDescriptionRepository(db)
+
+		articles, err := descrRepo.ListArticles()
+		if err != nil {
+			return fmt.Errorf("listing articles: %w", err)
+		}
+
+		descriptions, err := descrRepo.GetAllDescriptionJudgmentsSorted()
+		if err != nil {
+			return fmt.Errorf("loading classified descriptions: %w", err)
+		}
+
+		for _, threshold := range evalOptions.thresholds {
+			report := curation.EvaluateClassifier(articles, descriptions, curation.EvalOptions{
+				TestFraction: evalOptions.testFraction,
+				Seed:         evalOptions.seed,
+				Threshold:    threshold,
+			})
+
+			fmt.Printf("# threshold %.2f: train %d, test %d, precision %.3f, recall %.3f\n",
+				threshold, report.Train, report.Test, report.Overall.Precision(), report.Overall.Recall())
+
+			if evalOptions.perArticle {
+				fmt.Printf("%-12s %6s %6s %6s %9s %6s\n", "article", "tp", "fp", "fn", "precision", "recall")
+
+				for _, m := range report.Articles {
+					fmt.Printf("%-12s %6d %6d %6d %9.3f %6.3f\n",
+						m.ArticleID, m.TruePositives, m.FalsePositives, m.FalseNegatives, m.Precision(), m.Recall())
+				}
+			}
+
+			for i, c := range report.Confusions {
+				if i == evalOptions.confusions {
+					break
+				}
+
+				predicted := c.Predicted
+				if predicted == "" {
+					predicted = "(none)"
+				}
+
+				fmt.Printf("%6d  %s -> %s\n", c.Count, c.Expected, predicted)
+			}
+
+			fmt.Println()
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	curationCmd.AddCommand(curationClassifyCmd)
+	curationClassifyCmd.AddCommand(curationClassifyEvalCmd)
+	curationClassifyEvalCmd.Flags().Float64Var(&evalOptions.testFraction, "test-fraction", 0.2, "Share of the classified descriptions held out for testing")
+	curationClassifyEvalCmd.Flags().Uint64Var(&evalOptions.seed, "seed", 1, "Seed of the train/test split")
+	curationClassifyEvalCmd.Flags().Float64SliceVar(&evalOptions.thresholds, "threshold", []float64{0.5}, "Minimum similarity score of a prediction (repeatable)")
+	curationClassifyEvalCmd.Flags().IntVar(&evalOptions.confusions, "confusions", 20, "Number of confusions to report")
+	curationClassifyEvalCmd.Flags().BoolVar(&evalOptions.perArticle, "per-article", true, "Report precision and recall per article")
+}
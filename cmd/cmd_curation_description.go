@@ -16,13 +16,12 @@ package cmd
 
 import (
 	"bufio"
-	"database/sql"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
 	"github.com/spf13/cobra"
 )
 
@@ -36,10 +35,15 @@ var curationDescriptionCmd = &cobra.Command{
 	Use:   "description",
 	Short: "Interactive batch curation for descriptions",
 	RunE: func(_ *cobra.Command, _ []string) error {
-		dbpath := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
-		db, err := sql.Open("duckdb", dbpath)
+		// only the ingestion mode writes
+		mode := dbutils.ReadOnly
+		if !interactive && !isTerminal(os.Stdin) {
+			mode = dbutils.ReadWrite
+		}
+
+		db, err := openDB(mode)
 		if err != nil {
-			return fmt.Errorf("opening database: %w", err)
+			return err
 		}
 		defer db.Close()
 
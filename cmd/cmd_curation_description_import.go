@@ -0,0 +1,107 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var importOptions struct {
+	file      string
+	source    string
+	dryRun    bool
+	conflicts string
+}
+
+var curationDescriptionImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import descriptions classified by other projects",
+	Long: `Imports a JSON array of classified descriptions, like
+[{"description": "EXCESO DE VELOCIDAD", "article_ids": ["13.3.A"]}], recording
+--source as their provenance.
+
+The article IDs are validated against our catalog, and only the descriptions
+we haven't classified are imported: the classifications that disagree with
+ours are reported as conflicts for review (and written to --conflicts as JSON).`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		data, err := os.ReadFile(importOptions.file) // #nosec G304 - path is from the command line
+		if err != nil {
+			return fmt.Errorf("reading classifications: %w", err)
+		}
+
+		var items []curation.ImportedClassification
+		if err := json.Unmarshal(data, &items); err != nil {
+			return fmt.Errorf("parsing classifications: %w", err)
+		}
+
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		descrRepo := curation.NewDescriptionRepository(db)
+		if err := descrRepo.CreateSchema(); err != nil {
+			return fmt.Errorf("creating description schema: %w", err)
+		}
+
+		report, err := descrRepo.ImportClassifications(items, importOptions.source, importOptions.dryRun)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range report.Conflicts {
+			ours := "ours"
+			if c.Source != "" {
+				ours = c.Source
+			}
+
+			fmt.Printf("⚠️  %s | %s: %s | %s: %s\n", c.Description,
+				ours, strings.Join(c.Existing, ","), importOptions.source, strings.Join(c.Imported, ","))
+		}
+
+		for _, i := range report.Invalid {
+			fmt.Printf("❌ %s | %s\n", i.Description, i.Reason)
+		}
+
+		if importOptions.conflicts != "" {
+			output, err := json.MarshalIndent(report.Conflicts, "", "  ")
+			if err != nil {
+				return fmt.Errorf("encoding conflicts: %w", err)
+			}
+
+			if err := os.WriteFile(importOptions.conflicts, output, 0o600); err != nil {
+				return fmt.Errorf("writing conflicts: %w", err)
+			}
+		}
+
+		verb := "Imported"
+		if importOptions.dryRun {
+			verb = "Would import"
+		}
+
+		fmt.Printf("%s %d of %d descriptions from %s: %d already classified the same way, %d conflicts, %d invalid\n",
+			verb, report.Imported, len(items), importOptions.source, report.Agreed, len(report.Conflicts), len(report.Invalid))
+
+		return nil
+	},
+}
+
+func init() {
+	curationDescriptionImportCmd.Flags().StringVar(&importOptions.file, "file", "", "JSON file with the classified descriptions")
+	curationDescriptionImportCmd.Flags().StringVar(&importOptions.source, "source", "", "Name of the project the classifications come from")
+	curationDescriptionImportCmd.Flags().BoolVar(&importOptions.dryRun, "dry-run", false, "Report what would be imported without saving it")
+	curationDescriptionImportCmd.Flags().StringVar(&importOptions.conflicts, "conflicts", "", "File where to write the conflicts as JSON")
+	_ = curationDescriptionImportCmd.MarkFlagRequired("file")
+	_ = curationDescriptionImportCmd.MarkFlagRequired("source")
+	curationDescriptionCmd.AddCommand(curationDescriptionImportCmd)
+}
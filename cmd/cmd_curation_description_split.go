@@ -0,0 +1,116 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var splitOptions struct {
+	minParts int
+	limit    int
+	accept   bool
+}
+
+var curationDescriptionSplitCmd = &cobra.Command{
+	Use:   "split [description...]",
+	Short: "Split composite descriptions into their parts and classify them at once",
+	Long: `Shows the comma separated parts of composite descriptions with the
+suggestions for each part. Without arguments it goes through the unclassified
+descriptions with at least --min-parts parts.
+
+With --accept, the best suggestion of every part is accepted: the parts and the
+composite description (with the union of their articles) are classified in a
+single transaction. Descriptions with a part without suggestions are left
+untouched.`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		mode := dbutils.ReadOnly
+		if splitOptions.accept {
+			mode = dbutils.ReadWrite
+		}
+
+		db, err := openDB(mode)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		descrRepo := curation.NewDescriptionRepository(db)
+
+		articles, err := descrRepo.ListArticles()
+		if err != nil {
+			return fmt.Errorf("listing articles: %w", err)
+		}
+
+		descriptions, err := descrRepo.GetAllDescriptionJudgmentsSorted()
+		if err != nil {
+			return fmt.Errorf("loading classified descriptions: %w", err)
+		}
+
+		classifier := curation.NewDescriptionClassifierWithDescriptions(articles, descriptions)
+
+		if len(args) == 0 {
+			unclassified, err := descrRepo.GetUnclassifiedDescriptions(splitOptions.limit)
+			if err != nil {
+				return fmt.Errorf("getting unclassified descriptions: %w", err)
+			}
+
+			for _, item := range unclassified {
+				if len(utils.DefaultTokenizer.Split(item.Description)) >= splitOptions.minParts {
+					args = append(args, item.Description)
+				}
+			}
+		}
+
+		accepted := 0
+
+		for _, description := range args {
+			breakdown := classifier.SuggestWithBreakdown(description, threshold)
+
+			fmt.Printf("# MULTI | %s\n", description)
+
+			for _, bd := range breakdown {
+				fmt.Printf("## %s\n", bd.Part)
+
+				for _, suggestion := range bd.Suggestions {
+					fmt.Printf("%.2f | %s | %s\n", suggestion.Score, suggestion.ArticleID, suggestion.Text)
+				}
+			}
+
+			if splitOptions.accept {
+				parts, err := curation.BestParts(breakdown)
+				if err != nil {
+					fmt.Printf("⚠️  Skipping: %v\n", err)
+				} else if err := descrRepo.SaveCompositeClassification(description, parts); err != nil {
+					fmt.Printf("Error saving classification for '%s': %v\n", description, err)
+				} else {
+					fmt.Printf("✅ Saved %d parts for '%s'\n", len(parts), description)
+
+					accepted++
+				}
+			}
+
+			fmt.Println()
+		}
+
+		if splitOptions.accept {
+			fmt.Printf("Accepted %d of %d composite descriptions\n", accepted, len(args))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	curationDescriptionSplitCmd.Flags().Float64Var(&threshold, "threshold", 0.5, "Minimum similarity score to consider a suggestion valid")
+	curationDescriptionSplitCmd.Flags().IntVar(&splitOptions.minParts, "min-parts", 3, "Minimum number of parts of the unclassified descriptions to show")
+	curationDescriptionSplitCmd.Flags().IntVar(&splitOptions.limit, "limit", 10000, "Maximum number of unclassified descriptions to look at")
+	curationDescriptionSplitCmd.Flags().BoolVar(&splitOptions.accept, "accept", false, "Accept the best suggestion of every part")
+	curationDescriptionCmd.AddCommand(curationDescriptionSplitCmd)
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var curationImportOSMCmd = &cobra.Command{
+	Use:   "import-osm <extract.osm>",
+	Short: "Seed low-confidence judgments from OpenStreetMap intersections",
+	Long: `Reads an OpenStreetMap XML extract of Uruguay (.osm or .osm.gz) with the
+named highways and the department boundaries, indexes the coordinates of every
+street intersection per department, and creates low-confidence judgments for
+the pending locations of the form "CALLE A Y CALLE B" that match one.
+
+A suitable extract can be produced from the Geofabrik dump with:
+
+  osmium tags-filter uruguay-latest.osm.pbf w/highway r/boundary=administrative -o uruguay.osm`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		idx, err := curation.LoadOSMIntersections(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("⏳ Indexed %d intersections from %s\n", idx.Len(), args[0])
+
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		dbMap := make(map[int]string)
+		if err := impo.Each(func(ref impo.DbReference) error {
+			dbMap[ref.ID] = ref.Name
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("building db map: %w", err)
+		}
+
+		repo := curation.NewLocationRepository(db, dbMap)
+		if err := repo.CreateSchema(); err != nil {
+			return fmt.Errorf("creating geocoding schema: %w", err)
+		}
+
+		n, err := curation.SeedOSMJudgments(db, repo, idx, dbMap)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Seeded %d low-confidence judgments from OpenStreetMap\n", n)
+
+		return nil
+	},
+}
+
+func init() {
+	curationCmd.AddCommand(curationImportOSMCmd)
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var outlierOptions struct {
+	minCount int
+	factor   float64
+}
+
+var curationOutliersCmd = &cobra.Command{
+	Use:   "ur-outliers",
+	Short: "Queue offenses whose UR deviates from their article for review",
+	Long: `Computes the UR distribution of each article from the offenses classified
+under a single article, and queues for review the offenses whose UR is more
+than --factor times above or below the median (e.g. "50" extracted instead of
+"5.0"). The queue is reviewed in the curation server (/api/ur-outliers).`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		repo := curation.NewOutlierRepository(db)
+		if err := repo.CreateSchema(); err != nil {
+			return fmt.Errorf("creating outlier schema: %w", err)
+		}
+
+		stats, err := repo.ComputeURStats(outlierOptions.minCount)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-12s %8s %8s %8s %8s\n", "article", "count", "p05", "median", "p95")
+		for _, s := range stats {
+			fmt.Printf("%-12s %8d %8.0f %8.0f %8.0f\n", s.ArticleID, s.Count, s.P05, s.Median, s.P95)
+		}
+
+		n, err := repo.DetectUROutliers(outlierOptions.minCount, outlierOptions.factor)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Queued %d new UR outliers for review\n", n)
+
+		return nil
+	},
+}
+
+func init() {
+	curationCmd.AddCommand(curationOutliersCmd)
+	curationOutliersCmd.Flags().IntVar(
+		&outlierOptions.minCount,
+		"min-count",
+		curation.URStatsMinCount,
+		"Minimum number of offenses of an article to trust its UR distribution",
+	)
+	curationOutliersCmd.Flags().Float64Var(
+		&outlierOptions.factor,
+		"factor",
+		5,
+		"How many times above or below the median a UR is an outlier",
+	)
+}
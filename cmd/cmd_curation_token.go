@@ -0,0 +1,130 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var curationTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the API tokens of the curators",
+}
+
+// openCuratorRepository opens the database read-write with the tokens schema
+// in place.
+func openCuratorRepository() (*sql.DB, curation.CuratorRepository, error) {
+	db, err := openDB(dbutils.ReadWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo := curation.NewCuratorRepository(db)
+	if err := repo.CreateSchema(); err != nil {
+		db.Close()
+
+		return nil, nil, fmt.Errorf("creating curator schema: %w", err)
+	}
+
+	return db, repo, nil
+}
+
+var curationTokenIssueCmd = &cobra.Command{
+	Use:   "issue <curator>",
+	Short: "Issue a new token for a curator",
+	Long: `Issues a token for the curator. It's printed only once: the database keeps
+its hash. Send it as "Authorization: Bearer <token>"; the pages of the curation
+server ask for it the first time the API requires it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		db, repo, err := openCuratorRepository()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		token, t, err := repo.IssueToken(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Issued token %d for %s:\n%s\n", t.ID, t.Curator, token)
+
+		return nil
+	},
+}
+
+var curationTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tokens of the curators",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, repo, err := openCuratorRepository()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		tokens, err := repo.ListTokens()
+		if err != nil {
+			return err
+		}
+
+		formatTime := func(t *time.Time) string {
+			if t == nil {
+				return "-"
+			}
+
+			return t.Format(time.DateTime)
+		}
+
+		fmt.Printf("%-5s %-20s %-19s %-19s %-19s\n", "id", "curator", "created", "last used", "revoked")
+		for _, t := range tokens {
+			fmt.Printf("%-5d %-20s %-19s %-19s %-19s\n",
+				t.ID, t.Curator, t.CreatedAt.Format(time.DateTime), formatTime(t.LastUsedAt), formatTime(t.RevokedAt))
+		}
+
+		return nil
+	},
+}
+
+var curationTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a token of a curator",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing token id: %w", err)
+		}
+
+		db, repo, err := openCuratorRepository()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := repo.RevokeToken(id); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Revoked token %d\n", id)
+
+		return nil
+	},
+}
+
+func init() {
+	curationCmd.AddCommand(curationTokenCmd)
+	curationTokenCmd.AddCommand(curationTokenIssueCmd)
+	curationTokenCmd.AddCommand(curationTokenListCmd)
+	curationTokenCmd.AddCommand(curationTokenRevokeCmd)
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbOptimizeAnalyze bool
+	dbRestoreBackup   bool
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Mantenimiento de la base DuckDB",
+}
+
+var dbOptimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "Compacta la base antes de publicarla",
+	Long: `Elimina las tablas temporales y de trabajo (prefijo tmp_), opcionalmente
+actualiza las estadísticas con ANALYZE y fuerza un CHECKPOINT para integrar el
+write-ahead log, informando el tamaño final del archivo.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		path := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
+
+		report, err := dbutils.Optimize(path, dbutils.OptimizeOptions{Analyze: dbOptimizeAnalyze})
+		if err != nil {
+			return err
+		}
+
+		for _, table := range report.DroppedTables {
+			log.Printf("🗑️ Dropped %s", table)
+		}
+
+		log.Printf("✅ Optimized %s: %.1f MiB (was %.1f MiB)",
+			path, float64(report.SizeAfter)/(1<<20), float64(report.SizeBefore)/(1<<20))
+
+		return nil
+	},
+}
+
+var dbSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Copias de la base para volver atrás una operación",
+	Long: `Guarda copias de chapauy.duckdb en el directorio snapshots de --db-path,
+con una etiqueta y la fecha en que se tomaron, para probar operaciones masivas
+(clasificación automática, fusión de duplicados) y volver atrás si el resultado
+no convence.`,
+}
+
+var dbSnapshotCreateCmd = &cobra.Command{
+	Use:   "create <etiqueta>",
+	Short: "Guarda una copia de la base",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		s, err := dbutils.CreateSnapshot(filepath.Join(impoOptions.DbPath, "chapauy.duckdb"), args[0])
+		if err != nil {
+			return err
+		}
+
+		log.Printf("📸 Created snapshot %s (%.1f MiB)", s.Name, float64(s.Size)/(1<<20))
+
+		return nil
+	},
+}
+
+var dbSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <nombre|etiqueta>",
+	Short: "Reemplaza la base por una copia",
+	Long: `Reemplaza chapauy.duckdb por la copia indicada por su nombre o por su
+etiqueta (la más reciente con ella). Ningún otro proceso debe tener la base
+abierta. Salvo con --backup=false, antes guarda la base actual con la etiqueta
+before_restore.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
+
+		if dbRestoreBackup {
+			s, err := dbutils.CreateSnapshot(path, "before_restore")
+			if err != nil {
+				return err
+			}
+
+			log.Printf("📸 Created snapshot %s", s.Name)
+		}
+
+		s, err := dbutils.RestoreSnapshot(path, args[0])
+		if err != nil {
+			return err
+		}
+
+		log.Printf("✅ Restored snapshot %s", s.Name)
+
+		return nil
+	},
+}
+
+var dbSnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lista las copias de la base",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		snapshots, err := dbutils.ListSnapshots(filepath.Join(impoOptions.DbPath, "chapauy.duckdb"))
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NOMBRE\tETIQUETA\tFECHA\tTAMAÑO")
+
+		for _, s := range snapshots {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.1f MiB\n",
+				s.Name, s.Label, s.CreatedAt.Format("2006-01-02 15:04:05"), float64(s.Size)/(1<<20))
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbOptimizeCmd)
+	dbCmd.AddCommand(dbSnapshotCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotCreateCmd, dbSnapshotRestoreCmd, dbSnapshotListCmd)
+	dbCmd.PersistentFlags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	dbOptimizeCmd.Flags().BoolVar(
+		&dbOptimizeAnalyze,
+		"analyze",
+		false,
+		"Actualiza las estadísticas del planificador (ANALYZE)",
+	)
+	dbSnapshotRestoreCmd.Flags().BoolVar(
+		&dbRestoreBackup,
+		"backup",
+		true,
+		"Guarda la base actual antes de reemplazarla",
+	)
+}
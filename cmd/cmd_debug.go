@@ -43,6 +43,11 @@ BTX1234		{Country:UY AdmDivision:B … Category:Taxi MercosurFormat:true}
 		if isTerminal(input) {
 			fmt.Fprintln(os.Stderr, "Ingrese mátriculas a analizar, una por línea…")
 		}
+		reviewed, err := impo.DefaultVehicleOverrides()
+		if err != nil {
+			log.Fatal(err)
+		}
+		overrides := impo.NewVehicleOverrides(reviewed)
 		scanner := bufio.NewScanner(input)
 		for scanner.Scan() {
 			plate := scanner.Text()
@@ -50,6 +55,7 @@ BTX1234		{Country:UY AdmDivision:B … Category:Taxi MercosurFormat:true}
 			if err != nil {
 				fmt.Printf("%s\t%q\n", plate, err)
 			} else {
+				overrides.Apply(impo.NormalizeVehicleID(plate), info)
 				if s, err := json.Marshal(info); err == nil {
 					fmt.Printf("%s\t\t%s\n", plate, s)
 				} else {
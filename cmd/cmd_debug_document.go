@@ -42,6 +42,12 @@ Ejemplos:
 			}
 		}
 
+		// saved from the browser, it may well be in ISO-8859-1
+		r, err = htmlutils.NewReader(r, "")
+		if err != nil {
+			log.Fatalf("error reading html: %v", err)
+		}
+
 		node, err := htmlutils.AsNode(r)
 		if err != nil {
 			log.Fatalf("error parsing html: %v", err)
@@ -0,0 +1,98 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/jcodagnone/chapauy/export"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var exportOptions struct {
+	format string
+	output string
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exporta la base de datos para consumidores sin DuckDB",
+	Long: `Materializa las tablas principales (offenses, locations y articles) en un
+único archivo. Con --format=sqlite se genera una base SQLite con índices por
+vehículo y por departamento y fecha.
+
+Con --format=years la base DuckDB se divide en el directorio --output (por
+defecto, years en --db-path): un archivo con las infracciones de cada año,
+common.duckdb con el resto de las tablas y manifest.json con la lista de
+archivos, para que la web descargue solo los años que muestra.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		defaultOutput, ok := map[string]string{
+			"sqlite": "chapauy.sqlite",
+			"years":  "years",
+		}[exportOptions.format]
+		if !ok {
+			return fmt.Errorf("unsupported export format %q", exportOptions.format)
+		}
+
+		output := exportOptions.output
+		if output == "" {
+			output = filepath.Join(impoOptions.DbPath, defaultOutput)
+		}
+
+		// attached read-only to an in-memory database, which can attach the
+		// output files to write them
+		db, err := dbutils.OpenAttached(filepath.Join(impoOptions.DbPath, "chapauy.duckdb"))
+		if err != nil {
+			return fmt.Errorf("opening database: %w", err)
+		}
+		defer db.Close()
+
+		if exportOptions.format == "years" {
+			m, err := export.Shards(db, output, time.Now())
+			if err != nil {
+				return err
+			}
+
+			log.Printf("✅ Split the offenses of %d years in %s", len(m.Years), output)
+
+			return nil
+		}
+
+		if err := export.SQLite(db, output); err != nil {
+			return err
+		}
+
+		log.Printf("✅ Exported database to %s", output)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	exportCmd.Flags().StringVar(
+		&exportOptions.format,
+		"format",
+		"sqlite",
+		"Formato de salida (sqlite, years)",
+	)
+	exportCmd.Flags().StringVarP(
+		&exportOptions.output,
+		"output",
+		"o",
+		"",
+		"Archivo de salida, o directorio con --format=years. Por defecto, chapauy.sqlite o years en --db-path",
+	)
+}
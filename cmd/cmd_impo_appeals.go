@@ -0,0 +1,94 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	appealsAsOf   string
+	appealsOutput string
+)
+
+var impoAppealsCmd = &cobra.Command{
+	Use:   "appeals",
+	Short: "Exporta las infracciones con plazo de descargos abierto",
+	Long: `Exporta como JSONL las infracciones vigentes cuyo plazo para presentar
+descargos (10 días hábiles desde la publicación) aún no venció.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		asOf := time.Now()
+		if appealsAsOf != "" {
+			var err error
+			if asOf, err = time.Parse(time.DateOnly, appealsAsOf); err != nil {
+				return fmt.Errorf("parsing --as-of: %w", err)
+			}
+		}
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		appeals, err := impo.ListOpenAppeals(db, asOf)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if appealsOutput != "" {
+			f, err := os.Create(filepath.Clean(appealsOutput))
+			if err != nil {
+				return fmt.Errorf("creating %s: %w", appealsOutput, err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		w := bufio.NewWriter(out)
+		enc := json.NewEncoder(w)
+		for i := range appeals {
+			if err := enc.Encode(&appeals[i]); err != nil {
+				return fmt.Errorf("encoding appeal: %w", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("writing appeals: %w", err)
+		}
+
+		log.Printf("✅ Exported %d offenses with an open appeal window", len(appeals))
+
+		return nil
+	},
+}
+
+func init() {
+	impoCmd.AddCommand(impoAppealsCmd)
+	impoAppealsCmd.Flags().StringVar(
+		&appealsAsOf,
+		"as-of",
+		"",
+		"Fecha (AAAA-MM-DD) contra la que se evalúa el plazo. Por defecto, hoy",
+	)
+	impoAppealsCmd.Flags().StringVarP(
+		&appealsOutput,
+		"output",
+		"o",
+		"",
+		"Archivo de salida. Por defecto, la salida estándar",
+	)
+}
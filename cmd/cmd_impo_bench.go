@@ -0,0 +1,46 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/spf13/cobra"
+)
+
+var benchRows int
+
+var impoBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Mide el rendimiento de la extracción sobre un documento sintético",
+	Long: `Extrae un documento sintético y falla si tarda más que el presupuesto de la
+extracción (2s cada 10.000 filas, escalado a --rows), para detectar en CI un
+cambio que haga más lentas las corridas nocturnas sin depender de los documentos
+descargados. Los benchmarks detallados se ejecutan con go test -bench . ./impo.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if benchRows <= 0 {
+			return fmt.Errorf("--rows must be positive, got %d", benchRows)
+		}
+
+		elapsed, budget, err := impo.MeasureExtraction(benchRows)
+		if elapsed > 0 {
+			fmt.Printf("⏱️ Extracted %d rows in %v (budget %v)\n", benchRows, elapsed.Round(time.Millisecond), budget)
+		}
+
+		return err
+	},
+}
+
+func init() {
+	impoCmd.AddCommand(impoBenchCmd)
+	impoBenchCmd.Flags().IntVar(
+		&benchRows,
+		"rows",
+		impo.BenchRows,
+		"Cantidad de filas del documento sintético",
+	)
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	docsStatus string
+	docsDB     string
+	docsYear   int
+	docsJSON   bool
+	docsMonths bool
+)
+
+var impoDocsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Inventario de los documentos de cada base",
+}
+
+var impoDocsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lista los documentos según su estado en el proceso",
+	Long: `Lista los documentos encontrados por la búsqueda, descargados o extraídos,
+con su estado: missing (sin descargar), downloaded (sin extraer), extracted o
+failed (falló la última extracción), para reprocesar solo los necesarios.
+
+  chapa impo docs list --status=failed --db=Maldonado --year=2024`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		var filter impo.DocumentFilter
+
+		if docsStatus != "" {
+			status, err := impo.ParseDocumentStatus(docsStatus)
+			if err != nil {
+				return err
+			}
+
+			filter.Status = status
+		}
+
+		filter.Year = docsYear
+
+		var args []string
+		if docsDB != "" {
+			args = []string{docsDB}
+		}
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		repo, err := impo.NewSQLOffenseRepository(db)
+		if err != nil {
+			return err
+		}
+
+		var docs []impo.DocumentInfo
+
+		err = forEachDB(args, func(dbRef *impo.DbReference) error {
+			ret, err := impo.ListDocuments(impo.NewFileStore(impoOptions.DocumentsPath(), dbRef), repo, filter)
+			docs = append(docs, ret...)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if docsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			for i := range docs {
+				if err := enc.Encode(&docs[i]); err != nil {
+					return fmt.Errorf("encoding document: %w", err)
+				}
+			}
+
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DB\tSTATUS\tYEAR\tOFFENSES\tERRORS\tDOCUMENT\tFAILURE")
+
+		for _, d := range docs {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\t%s\n",
+				d.DbID, d.Status, d.Year, d.Offenses, d.Errors, d.DocSource, d.Failure)
+		}
+
+		return w.Flush()
+	},
+}
+
+var impoDocsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Documentos publicados y extraídos de cada base por mes",
+	Long: `Cuenta por base los documentos encontrados en IMPO y los extraídos, y por
+mes, según la fecha de publicación, los publicados y los extraídos. Un mes sin
+documentos luego de meses con documentos es una brecha: la base pudo no
+publicar nada, o la búsqueda dejó de encontrar sus documentos sin fallar.
+
+La fecha de publicación se lee del documento descargado, se haya extraído o
+no; los que todavía no se descargaron se cuentan aparte como sin fecha. El mes
+actual no cuenta como brecha, todavía puede tener documentos.
+
+  chapa impo docs stats --db=Maldonado --months`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		var args []string
+		if docsDB != "" {
+			args = []string{docsDB}
+		}
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		repo, err := impo.NewSQLOffenseRepository(db)
+		if err != nil {
+			return err
+		}
+
+		var stats []*impo.DocumentStats
+
+		now := time.Now()
+
+		err = forEachDB(args, func(dbRef *impo.DbReference) error {
+			s, err := impo.ListDocumentStats(impo.NewFileStore(impoOptions.DocumentsPath(), dbRef), repo, now)
+			if err == nil {
+				stats = append(stats, s)
+			}
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if docsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			for _, s := range stats {
+				if err := enc.Encode(s); err != nil {
+					return fmt.Errorf("encoding stats: %w", err)
+				}
+			}
+
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		if docsMonths {
+			fmt.Fprintln(w, "DB\tNAME\tMONTH\tPUBLISHED\tEXTRACTED\tGAP")
+
+			for _, s := range stats {
+				for _, m := range s.Months {
+					gap := ""
+					if m.Gap {
+						gap = "⚠️"
+					}
+
+					fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", s.DbID, s.Name, m.Month, m.Published, m.Extracted, gap)
+				}
+			}
+
+			return w.Flush()
+		}
+
+		fmt.Fprintln(w, "DB\tNAME\tFOUND\tEXTRACTED\tUNDATED\tLAST PUBLISHED\tGAPS")
+
+		for _, s := range stats {
+			last := ""
+			if s.LastPublished != nil {
+				last = s.LastPublished.Format(time.DateOnly)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\t%d\n", s.DbID, s.Name, s.Found, s.Extracted, s.Undated, last, s.Gaps)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	impoCmd.AddCommand(impoDocsCmd)
+	impoDocsCmd.AddCommand(impoDocsListCmd, impoDocsStatsCmd)
+	impoDocsListCmd.Flags().StringVar(
+		&docsStatus,
+		"status",
+		"",
+		"Estado de los documentos a listar: missing, downloaded, extracted o failed",
+	)
+	impoDocsListCmd.Flags().StringVar(
+		&docsDB,
+		"db",
+		"",
+		"Base de datos (id o nombre). Por defecto, todas",
+	)
+	_ = impoDocsListCmd.RegisterFlagCompletionFunc("db", completeDBFlag)
+	impoDocsListCmd.Flags().IntVar(
+		&docsYear,
+		"year",
+		0,
+		"Año de los documentos, según su URL",
+	)
+	impoDocsListCmd.Flags().BoolVar(
+		&docsJSON,
+		"json",
+		false,
+		"Escribe los documentos como JSONL",
+	)
+	impoDocsStatsCmd.Flags().StringVar(
+		&docsDB,
+		"db",
+		"",
+		"Base de datos (id o nombre). Por defecto, todas",
+	)
+	_ = impoDocsStatsCmd.RegisterFlagCompletionFunc("db", completeDBFlag)
+	impoDocsStatsCmd.Flags().BoolVar(
+		&docsMonths,
+		"months",
+		false,
+		"Lista los documentos de cada mes en lugar del resumen de cada base",
+	)
+	impoDocsStatsCmd.Flags().BoolVar(
+		&docsJSON,
+		"json",
+		false,
+		"Escribe las estadísticas de cada base como JSONL",
+	)
+}
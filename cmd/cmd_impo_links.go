@@ -0,0 +1,129 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var impoVerifyLinksCmd = &cobra.Command{
+	Use:   "verify-links [db]",
+	Short: "Verifica que los documentos sigan publicados en IMPO",
+	Long: `Consulta con HEAD la URL de cada documento descubierto por la búsqueda y
+registra el resultado en la tabla doc_status: ok, moved (IMPO redirige a otra
+URL), gone (404 o 410) o error (la consulta falló). Lista los documentos que
+ya no están donde fueron publicados y desde cuándo faltan, como evidencia de
+las notificaciones retiradas sin aviso.
+
+Un error en la consulta no cambia el estado conocido del documento.`,
+	Args:              dbArg,
+	ValidArgsFunction: completeDBArg,
+	RunE: func(_ *cobra.Command, args []string) error {
+		var err error
+		if impoOptions.CrawlWindow, err = impo.ParseCrawlWindow(crawlWindow); err != nil {
+			return err
+		}
+
+		if err := configureProxy(); err != nil {
+			return err
+		}
+
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		repo, err := impo.NewSQLOffenseRepository(db)
+		if err != nil {
+			return fmt.Errorf("initializing repository: %w", err)
+		}
+
+		if err := repo.CreateSchema(); err != nil {
+			return fmt.Errorf("creating table: %w", err)
+		}
+
+		if impoOptions.UserAgent == "" {
+			impoOptions.UserAgent = fmt.Sprintf("chapauy/%s (+https://github.com/jcodagnone/chapauy)", Version)
+		}
+
+		var (
+			metrics impo.ClientMetrics
+			missing []impo.LinkCheck
+		)
+
+		err = forEachDB(args, func(dbRef *impo.DbReference) error {
+			c := impo.NewImpoClient(impoOptions, dbRef, repo)
+			checks, err := c.VerifyLinks()
+			metrics.Merge(&c.Metrics)
+			missing = append(missing, checks...)
+
+			return err
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DB\tSTATUS\tHTTP\tMISSING SINCE\tDOCUMENT\tLOCATION")
+
+		for _, c := range missing {
+			since := ""
+			if c.MissingSince != nil {
+				since = c.MissingSince.Format(time.DateOnly)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\n", c.DbID, c.Status, c.HTTPStatus, since, c.DocSource, c.Location)
+		}
+
+		if werr := w.Flush(); werr != nil && err == nil {
+			err = werr
+		}
+
+		log.Printf(
+			"Verified %d links - %d ok, %d moved, %d gone, %d failed",
+			metrics.LinksOK+metrics.LinksMoved+metrics.LinksGone+metrics.LinksErr,
+			metrics.LinksOK,
+			metrics.LinksMoved,
+			metrics.LinksGone,
+			metrics.LinksErr,
+		)
+
+		return err
+	},
+}
+
+func init() {
+	impoCmd.AddCommand(impoVerifyLinksCmd)
+	impoVerifyLinksCmd.Flags().StringVar(
+		&impoOptions.UserAgent,
+		"user-agent",
+		"",
+		"User-Agent a enviar a IMPO. Por defecto identifica al proyecto y su URL de contacto",
+	)
+	impoVerifyLinksCmd.Flags().DurationVar(
+		&impoOptions.RequestDelay,
+		"request-delay",
+		impo.DefaultRequestDelay,
+		"Tiempo mínimo entre dos pedidos a IMPO",
+	)
+	addProxyFlags(impoVerifyLinksCmd.Flags())
+	impoVerifyLinksCmd.Flags().StringVar(
+		&crawlWindow,
+		"crawl-window",
+		"",
+		"Franja horaria (HH:MM-HH:MM) en la que se permite consultar IMPO",
+	)
+	impoVerifyLinksCmd.Flags().BoolVar(
+		&impoOptions.DryRun,
+		"dry-run",
+		false,
+		"No registra el resultado en doc_status",
+	)
+}
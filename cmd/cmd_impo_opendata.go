@@ -0,0 +1,103 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jcodagnone/chapauy/opendata"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var opendataOptions struct {
+	baseURL     string
+	ckanURL     string
+	ckanDataset string
+	profile     string
+}
+
+var impoOpendataCmd = &cobra.Command{
+	Use:   "opendata <dir>",
+	Short: "Exporta el dataset en el formato de datos.gub.uy",
+	Long: `Exporta las infracciones vigentes como un CSV por año junto a la metadata
+DCAT (dcat.json), la estructura esperada por el Catálogo Nacional de Datos
+Abiertos. Con --ckan-url y --ckan-dataset además se publican los CSV en el
+catálogo CKAN, usando la clave de la variable de entorno CKAN_API_KEY.
+
+Con --profile=anonymized se enmascaran las matrículas (conservando la letra del
+departamento de las uruguayas, el país y el tipo de vehículo), se omiten los
+identificadores de intervención y las referencias al documento publicado
+(doc_id, doc_date, doc_source, record_id y offense_id), y la hora se trunca a la
+hora en punto.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		opts := opendata.DefaultOptions()
+		opts.BaseURL = opendataOptions.baseURL
+		opts.Profile = opendataOptions.profile
+
+		resources, err := opendata.Export(db, args[0], opts)
+		if err != nil {
+			return fmt.Errorf("exporting dataset: %w", err)
+		}
+
+		for _, r := range resources {
+			log.Printf("✅ %s: %d offenses", r.Path, r.Records)
+		}
+
+		if opendataOptions.ckanURL == "" {
+			return nil
+		}
+
+		if opendataOptions.ckanDataset == "" {
+			return errors.New("--ckan-dataset is required to publish")
+		}
+
+		ckan := &opendata.CKAN{BaseURL: opendataOptions.ckanURL, APIKey: os.Getenv("CKAN_API_KEY")}
+		if err := ckan.Publish(opendataOptions.ckanDataset, resources); err != nil {
+			return fmt.Errorf("publishing to CKAN: %w", err)
+		}
+
+		log.Printf("✅ Published %d resources to %s", len(resources), opendataOptions.ckanURL)
+
+		return nil
+	},
+}
+
+func init() {
+	impoCmd.AddCommand(impoOpendataCmd)
+	impoOpendataCmd.Flags().StringVar(
+		&opendataOptions.baseURL,
+		"base-url",
+		"",
+		"URL desde donde se descargarán los CSV, para la metadata DCAT",
+	)
+	impoOpendataCmd.Flags().StringVar(
+		&opendataOptions.profile,
+		"profile",
+		opendata.ProfileFull,
+		"Perfil de exportación: full o anonymized",
+	)
+	impoOpendataCmd.Flags().StringVar(
+		&opendataOptions.ckanURL,
+		"ckan-url",
+		"",
+		"URL del catálogo CKAN donde publicar los recursos",
+	)
+	impoOpendataCmd.Flags().StringVar(
+		&opendataOptions.ckanDataset,
+		"ckan-dataset",
+		"",
+		"Identificador del dataset en el catálogo CKAN",
+	)
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchJitter   float64
+)
+
+var impoWatchCmd = &cobra.Command{
+	Use:   "watch [db]",
+	Short: "Actualiza el contenido local periódicamente, como un servicio",
+	Long: `Ejecuta la búsqueda, descarga y extracción de update, esperando
+--interval entre una actualización y la siguiente, para correr en un servidor
+propio en lugar del scheduler de Google Cloud. Cada espera varía al azar,
+hacia arriba o hacia abajo, hasta la fracción --jitter de --interval, para no
+consultar IMPO siempre a la misma hora. Acepta los mismos flags que update.
+
+Solo puede haber un watch por --db-path: el proceso toma el archivo
+chapauy.lock del directorio mientras corre, y update, extract y reextract lo
+toman durante la suya, así que fallan mientras watch corre. Si el proceso
+muere, el sistema libera el lock. La base de datos se abre únicamente durante
+cada actualización, de modo que entre una y otra puede leerse desde otros
+comandos. Una actualización fallida se informa y se reintenta en la
+siguiente. Ctrl-C (o SIGTERM) termina el proceso al finalizar la
+actualización en curso.`,
+	Args:              dbArg,
+	ValidArgsFunction: completeDBArg,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if watchInterval < time.Minute {
+			return errors.New("--interval debe ser de al menos un minuto")
+		}
+
+		if watchJitter < 0 || watchJitter >= 1 {
+			return errors.New("--jitter debe estar entre 0 y 1")
+		}
+
+		unlock, err := lockDbPath()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		for ctx.Err() == nil {
+			if err := runUpdate(args); err != nil {
+				log.Printf("⚠️ Update failed, retrying in the next one: %v", err)
+			}
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			delay := watchDelay(watchInterval, watchJitter)
+			log.Printf("💤 Next update at %s", time.Now().Add(delay).Format(time.DateTime))
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+		}
+
+		log.Printf("Stopping")
+
+		return nil
+	},
+}
+
+// watchDelay returns the wait before the next update: the interval plus or
+// minus up to the fraction jitter of it, so the updates don't hit IMPO always
+// at the same time.
+func watchDelay(interval time.Duration, jitter float64) time.Duration {
+	d := time.Duration(float64(interval) * jitter)
+	if d <= 0 {
+		return interval
+	}
+
+	return interval - d + rand.N(2*d) // #nosec G404 - scheduling, not security
+}
+
+func init() {
+	impoCmd.AddCommand(impoWatchCmd)
+	addUpdateFlags(impoWatchCmd.Flags())
+	impoWatchCmd.Flags().DurationVar(
+		&watchInterval,
+		"interval",
+		6*time.Hour,
+		"Espera entre el fin de una actualización y el comienzo de la siguiente",
+	)
+	impoWatchCmd.Flags().Float64Var(
+		&watchJitter,
+		"jitter",
+		0.1,
+		"Variación al azar, hacia arriba o hacia abajo, de cada espera, como fracción de --interval",
+	)
+}
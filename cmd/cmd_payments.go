@@ -0,0 +1,155 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/sucive"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/jcodagnone/chapauy/utils/i18n"
+	"github.com/spf13/cobra"
+)
+
+var paymentsOptions struct {
+	url     string
+	consent bool
+	delay   time.Duration
+	path    string
+}
+
+var paymentsCmd = &cobra.Command{
+	Use:   "payments <matrícula>",
+	Short: "Consulta en SUCIVE qué infracciones de una matrícula están pendientes o pagas",
+	Long: `Consulta las multas de una matrícula en la consulta pública de SUCIVE y
+marca cada una de nuestras infracciones de la matrícula según la multa del
+mismo día: pendiente, paga, en SUCIVE sin estado legible, o no figura.
+
+El estado de pago es un dato personal: solo se consulta con --consent, que
+confirma que el titular de la matrícula autorizó la consulta, y se guarda en
+--payments-db, fuera del directorio de estado de --db-path, que se publica
+entero. 'chapa serve --payments' lo muestra junto a las infracciones de la
+matrícula.
+
+--url es la dirección de la consulta, con %s en el lugar de la matrícula, como
+en 'chapa stats sucive'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !paymentsOptions.consent {
+			return i18n.Errorf("looking up the payment status requires --consent of the owner of the plate")
+		}
+
+		if !strings.Contains(paymentsOptions.url, "%s") {
+			return i18n.Errorf("--url must include %%s in place of the plate")
+		}
+
+		if err := sucive.CheckPaymentsPath(paymentsOptions.path, impoOptions.DbPath); err != nil {
+			return err
+		}
+
+		plate := impo.NormalizeVehicleID(args[0])
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		offenses, err := sucive.PlateOffenses(db, plate)
+		if err != nil {
+			return err
+		}
+
+		if len(offenses) == 0 {
+			log.Printf("No offenses of %s to look up", plate)
+
+			return nil
+		}
+
+		lookup := newSuciveLookup(paymentsOptions.url, paymentsOptions.delay)
+
+		payments, err := sucive.LinkPayments(cmd.Context(), lookup, plate, offenses)
+		if err != nil {
+			return err
+		}
+
+		pdb, err := sucive.OpenPayments(paymentsOptions.path)
+		if err != nil {
+			return err
+		}
+		defer pdb.Close()
+
+		if err := sucive.SavePayments(pdb, plate, payments, time.Now()); err != nil {
+			return err
+		}
+
+		return printPayments(os.Stdout, payments)
+	},
+}
+
+// paymentLabels are the sucive.Status as shown to the owner of the plate.
+var paymentLabels = map[sucive.Status]string{
+	sucive.StatusPending:   "pendiente",
+	sucive.StatusPaid:      "paga",
+	sucive.StatusListed:    "en SUCIVE",
+	sucive.StatusNotListed: "no figura",
+}
+
+func printPayments(out io.Writer, payments []sucive.Payment) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FECHA\tDB\tESTADO\tMULTA\tDOCUMENTO")
+
+	for _, p := range payments {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			p.Time.In(impo.UruguayTimezone).Format("2006-01-02 15:04"), p.DbID, paymentLabels[p.Status], p.Fine, p.DocSource)
+	}
+
+	return w.Flush()
+}
+
+// addPaymentsDBFlag adds --payments-db, shared by payments and serve.
+func addPaymentsDBFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&paymentsOptions.path,
+		"payments-db",
+		sucive.DefaultPaymentsPath,
+		"Archivo con el estado de pago de las matrículas consultadas, fuera del directorio de estado",
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(paymentsCmd)
+	paymentsCmd.Flags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	addPaymentsDBFlag(paymentsCmd)
+	paymentsCmd.Flags().StringVar(
+		&paymentsOptions.url,
+		"url",
+		"",
+		"URL de la consulta pública de multas, con %s en el lugar de la matrícula",
+	)
+	paymentsCmd.Flags().BoolVar(
+		&paymentsOptions.consent,
+		"consent",
+		false,
+		"Confirma que el titular de la matrícula autorizó la consulta",
+	)
+	paymentsCmd.Flags().DurationVar(
+		&paymentsOptions.delay,
+		"request-delay",
+		2*time.Second,
+		"Tiempo mínimo entre dos consultas",
+	)
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/stats"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var reportOptions struct {
+	month  string
+	format string
+	top    int
+	output string
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Reportes para difusión",
+}
+
+var reportMonthlyCmd = &cobra.Command{
+	Use:   "monthly",
+	Short: "Genera el resumen mensual de las infracciones publicadas",
+	Long: `Genera un resumen en Markdown o HTML de las infracciones publicadas en un
+mes, listo para pegar en un newsletter: el ranking de departamentos, los
+radares y puntos con más infracciones, las multas más altas y los artículos que
+más cambiaron respecto al mes anterior. Las infracciones se cuentan por la
+fecha de publicación del documento, y no incluye matrículas.
+
+Por defecto resume el mes anterior al actual.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		now := time.Now().In(impo.UruguayTimezone)
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, impo.UruguayTimezone).AddDate(0, -1, 0)
+
+		if reportOptions.month != "" {
+			var err error
+			if month, err = stats.ParseMonth(reportOptions.month); err != nil {
+				return err
+			}
+		}
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		report, err := stats.ComputeMonthlyReport(db, month, reportOptions.top)
+		if err != nil {
+			return err
+		}
+
+		report.GeneratedAt = now
+
+		if reportOptions.output == "" {
+			return stats.RenderMonthlyReport(os.Stdout, report, reportOptions.format)
+		}
+
+		f, err := os.Create(reportOptions.output) // #nosec G304 - path is from the command line
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		defer f.Close()
+
+		w := bufio.NewWriter(f)
+		if err := stats.RenderMonthlyReport(w, report, reportOptions.format); err != nil {
+			return err
+		}
+
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportMonthlyCmd)
+	reportCmd.PersistentFlags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	reportMonthlyCmd.Flags().StringVar(&reportOptions.month, "month", "", "Mes a resumir, como YYYY-MM")
+	reportMonthlyCmd.Flags().StringVar(&reportOptions.format, "format", stats.FormatMarkdown, "Formato del resumen (md|html)")
+	reportMonthlyCmd.Flags().IntVar(&reportOptions.top, "top", 10, "Cantidad de filas de cada ranking")
+	reportMonthlyCmd.Flags().StringVarP(&reportOptions.output, "output", "o", "", "Archivo donde escribir el resumen (por defecto la salida estándar)")
+}
@@ -4,13 +4,19 @@
 package cmd
 
 import (
+	"database/sql"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/jcodagnone/chapauy/utils/i18n"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type logWriter struct {
@@ -38,11 +44,78 @@ Información Oficial.
 
 var Version = "dev"
 
-func Execute(version string) {
-	Version = version
+// lang is only declared so cobra accepts --lang; its value is resolved before
+// parsing (see langFromArgs) because help texts must be localized beforehand.
+var lang string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", string(i18n.DefaultLang), "Idioma de los mensajes (es|en)")
+}
+
+// langFromArgs returns the value of --lang, falling back to $CHAPA_LANG.
+func langFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if v, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return v
+		}
+
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	if v := os.Getenv("CHAPA_LANG"); v != "" {
+		return v
+	}
+
+	return string(i18n.DefaultLang)
+}
+
+// localize translates the help texts of the command tree.
+func localize(cmd *cobra.Command) {
+	cmd.Short = i18n.T(cmd.Short)
+	cmd.Long = i18n.T(cmd.Long)
+
+	translate := func(f *pflag.Flag) { f.Usage = i18n.T(f.Usage) }
+	cmd.LocalNonPersistentFlags().VisitAll(translate)
+	cmd.PersistentFlags().VisitAll(translate)
+
+	for _, c := range cmd.Commands() {
+		localize(c)
+	}
+}
+
+// Build describes the binary being executed. It is completed in Execute.
+var Build BuildInfo
+
+func Execute(info BuildInfo) {
+	Build = info.complete()
+	Version = Build.Version
+
+	if err := i18n.SetLang(langFromArgs(os.Args[1:])); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	localize(rootCmd)
 
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
 	}
 }
+
+// openDB opens chapauy.duckdb inside --db-path. Commands that only read must use
+// dbutils.ReadOnly so they don't block (nor get blocked by) each other.
+func openDB(mode dbutils.AccessMode) (*sql.DB, error) {
+	db, err := dbutils.Open(filepath.Join(impoOptions.DbPath, "chapauy.duckdb"), mode)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/html/charset"
+)
+
+// errSelfCheck is returned when any of the checks fails.
+var errSelfCheck = errors.New("self-check failed")
+
+// selfCheck is one of the checks of the environment the binary runs in.
+type selfCheck struct {
+	name string
+	run  func() (string, error)
+}
+
+var selfChecks = []selfCheck{
+	{"timezone", checkTimezone},
+	{"charset", checkCharset},
+}
+
+// checkTimezone verifies the dates of the documents are read in Uruguay's
+// time: before 2015 Uruguay had daylight saving time, which an outdated
+// timezone database could miss.
+func checkTimezone() (string, error) {
+	summer := time.Date(2014, time.January, 15, 12, 0, 0, 0, impo.UruguayTimezone)
+	if _, offset := summer.Zone(); offset != -2*60*60 {
+		return "", fmt.Errorf("expected UTC-2 in January 2014, got an offset of %ds", offset)
+	}
+
+	return impo.UruguayTimezone.String(), nil
+}
+
+// checkCharset verifies an ISO-8859-1 document is decoded into UTF-8 and its
+// accents folded, as the property matching of the headers needs.
+func checkCharset() (string, error) {
+	const latin1 = "Intersecci\xf3n, Matr\xedcula"
+
+	r, err := charset.NewReader(strings.NewReader(latin1), "text/html; charset=iso-8859-1")
+	if err != nil {
+		return "", fmt.Errorf("creating ISO-8859-1 reader: %w", err)
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("decoding ISO-8859-1: %w", err)
+	}
+
+	if got := string(b); got != "Intersección, Matrícula" {
+		return "", fmt.Errorf("decoded ISO-8859-1 as %q", got)
+	}
+
+	if got := utils.LowerASCIIFolding(string(b)); got != "interseccion, matricula" {
+		return "", fmt.Errorf("folded %q as %q", string(b), got)
+	}
+
+	return "ISO-8859-1 → UTF-8", nil
+}
+
+var selfCheckCmd = &cobra.Command{
+	Use:   "selfcheck",
+	Short: "Verifica que el entorno tenga la zona horaria y el manejo de caracteres necesarios",
+	Long: `Verifica que la zona horaria America/Montevideo incluida en el binario tenga el
+horario de verano anterior a 2015 y que los documentos en ISO-8859-1 se
+decodifiquen a UTF-8. Las construcciones de las imágenes lo ejecutan para no
+publicar imágenes rotas.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		failed := false
+
+		for _, check := range selfChecks {
+			detail, err := check.run()
+			if err != nil {
+				failed = true
+
+				fmt.Fprintf(cmd.OutOrStdout(), "❌ %s: %v\n", check.name, err)
+
+				continue
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ %s: %s\n", check.name, detail)
+		}
+
+		if failed {
+			return errSelfCheck
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfCheckCmd)
+}
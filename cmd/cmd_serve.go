@@ -0,0 +1,128 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jcodagnone/chapauy/browse"
+	"github.com/jcodagnone/chapauy/export"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/sucive"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr     string
+	serveShards   string
+	serveYears    []int
+	servePayments bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Sirve la API pública de lectura y un navegador de los datos",
+	Long: `Abre la base DuckDB en modo lectura y sirve en la máquina local la API
+pública de lectura (/api/v1/offenses, /api/v1/articles) y un navegador de los
+datos con tablas HTML y filtros, sin necesidad de Node ni de la web.
+
+Con --shards se sirve en cambio la base dividida por año por
+'chapa export --format=years', limitada a los años de --years si se indican.
+
+Con --payments se muestra además el estado de pago de las infracciones de las
+matrículas consultadas con 'chapa payments', guardado en --payments-db.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openServeDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if servePayments {
+			if err := sucive.AttachPayments(db, paymentsOptions.path); err != nil {
+				return err
+			}
+		}
+
+		dbMap := make(map[int]string)
+		if err := impo.Each(func(ref impo.DbReference) error {
+			dbMap[ref.ID] = ref.Name
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("building db map: %w", err)
+		}
+
+		fmt.Printf("📊 Open http://%s in your browser\n", serveAddr)
+
+		return browse.NewServer(browse.NewRepository(db), dbMap).Run(serveAddr)
+	},
+}
+
+// openServeDB opens the database to serve: the one of --db-path, or the
+// shards of --shards attached to an in-memory one.
+func openServeDB() (*sql.DB, error) {
+	if serveShards == "" {
+		dbpath := filepath.Join(impoOptions.DbPath, "chapauy.duckdb")
+		if _, err := os.Stat(dbpath); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("database not found at %s - run 'seed' or 'impo update' first", dbpath)
+		}
+
+		return openDB(dbutils.ReadOnly)
+	}
+
+	db, err := dbutils.Open("", dbutils.ReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("opening in-memory database: %w", err)
+	}
+
+	if err := export.AttachShards(db, serveShards, serveYears); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	serveCmd.Flags().StringVar(
+		&serveAddr,
+		"addr",
+		"localhost:8080",
+		"Dirección donde escuchar; por defecto solo la máquina local",
+	)
+	serveCmd.Flags().StringVar(
+		&serveShards,
+		"shards",
+		"",
+		"Directorio con la base dividida por año por export --format=years, a servir en lugar de la de --db-path",
+	)
+	serveCmd.Flags().IntSliceVar(
+		&serveYears,
+		"years",
+		nil,
+		"Con --shards, los años a servir. Por defecto, todos",
+	)
+	serveCmd.Flags().BoolVar(
+		&servePayments,
+		"payments",
+		false,
+		"Muestra el estado de pago de las matrículas consultadas con 'chapa payments'",
+	)
+	addPaymentsDBFlag(serveCmd)
+}
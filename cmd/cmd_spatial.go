@@ -0,0 +1,228 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/mattn/go-isatty"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	spatialResolutions string
+	spatialChunkSize   int
+	spatialSchoolZones string
+	spatialRadars      string
+)
+
+var spatialCmd = &cobra.Command{
+	Use:   "spatial",
+	Short: "Mantenimiento de los datos espaciales",
+}
+
+var spatialReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Recalcula las celdas H3 de ubicaciones e infracciones",
+	Long: `Recalcula las columnas h3_resN de canonical_locations, locations y offenses a
+partir de sus puntos para las resoluciones de --resolutions, agregando las
+columnas que falten. Las filas se actualizan por lotes de --chunk-size.
+
+Las ubicaciones curadas y las infracciones nuevas ya guardan las resoluciones
+1 a 10: hace falta para completar las filas guardadas antes, o para agregar
+otras resoluciones, que sí hay que volver a calcular después de cada
+actualización.`,
+	Example: `  chapa spatial reindex --resolutions=9-10`,
+	Args:    cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		lo, hi, err := curation.ParseResolutions(spatialResolutions)
+		if err != nil {
+			return err
+		}
+
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		// the points are POINT_2D
+		if _, err := db.Exec(`INSTALL spatial; LOAD spatial;`); err != nil {
+			return fmt.Errorf("loading spatial extension: %w", err)
+		}
+
+		var (
+			bar     *progressbar.ProgressBar
+			current string
+		)
+
+		opts := curation.ReindexOptions{
+			MinResolution: lo,
+			MaxResolution: hi,
+			ChunkSize:     spatialChunkSize,
+		}
+
+		if isatty.IsTerminal(os.Stderr.Fd()) {
+			opts.Progress = func(table string, done, total int64) {
+				if table != current {
+					current = table
+					bar = progressbar.NewOptions64(total,
+						progressbar.OptionSetDescription("Reindexing "+table),
+						progressbar.OptionSetWriter(os.Stderr),
+						progressbar.OptionShowCount(),
+						progressbar.OptionClearOnFinish(),
+					)
+				}
+
+				_ = bar.Set64(done)
+			}
+		}
+
+		report, err := curation.ReindexCells(db, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, column := range report.AddedColumns {
+			log.Printf("➕ Added %s", column)
+		}
+
+		for _, table := range curation.CellTables {
+			if n, ok := report.Rows[table]; ok {
+				log.Printf("✅ Reindexed %s rows of %s at resolutions %d-%d", utils.FormatInt(n), table, lo, hi)
+			}
+		}
+
+		return nil
+	},
+}
+
+var spatialProximityCmd = &cobra.Command{
+	Use:   "proximity",
+	Short: "Calcula la distancia de las infracciones a la zona escolar y al radar fijo más cercanos",
+	Long: `Guarda en nearest_school_m y nearest_radar_m de cada infracción geocodificada la
+distancia en metros a la zona escolar y al radar fijo más cercanos. Por defecto
+usa las capas GeoJSON incluidas en el binario (curation/zonas_escolares.json y
+curation/radares.json); --school-zones y --radars permiten usar otras. Una capa
+vacía deja su columna en NULL: la de zonas escolares incluida todavía no tiene
+datos, así que nearest_school_m queda en NULL hasta pasar --school-zones.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		schoolZones, err := loadPointLayer(spatialSchoolZones, "zonas_escolares", curation.DefaultSchoolZoneLayer)
+		if err != nil {
+			return err
+		}
+
+		radars, err := loadPointLayer(spatialRadars, "radares", curation.DefaultRadarLayer)
+		if err != nil {
+			return err
+		}
+
+		for _, layer := range []*curation.PointLayer{schoolZones, radars} {
+			if layer.Len() == 0 {
+				log.Printf("⚠️ The %s layer is empty, its distances are left NULL", layer.Name)
+			}
+		}
+
+		db, err := openDB(dbutils.ReadWrite)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		// the points are POINT_2D
+		if _, err := db.Exec(`INSTALL spatial; LOAD spatial;`); err != nil {
+			return fmt.Errorf("loading spatial extension: %w", err)
+		}
+
+		opts := curation.ProximityOptions{
+			SchoolZones: schoolZones,
+			Radars:      radars,
+			ChunkSize:   spatialChunkSize,
+		}
+
+		if isatty.IsTerminal(os.Stderr.Fd()) {
+			var bar *progressbar.ProgressBar
+
+			opts.Progress = func(done, total int64) {
+				if bar == nil {
+					bar = progressbar.NewOptions64(total,
+						progressbar.OptionSetDescription("Measuring distances"),
+						progressbar.OptionSetWriter(os.Stderr),
+						progressbar.OptionShowCount(),
+						progressbar.OptionClearOnFinish(),
+					)
+				}
+
+				_ = bar.Set64(done)
+			}
+		}
+
+		n, err := curation.EnrichProximity(db, opts)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("✅ Measured the distances of %s offenses", utils.FormatInt(n))
+
+		return nil
+	},
+}
+
+// loadPointLayer reads the layer from path, or the bundled one when empty.
+func loadPointLayer(path, name string, bundled func() (*curation.PointLayer, error)) (*curation.PointLayer, error) {
+	if path == "" {
+		return bundled()
+	}
+
+	f, err := os.Open(path) // #nosec G304 - path is provided by admin
+	if err != nil {
+		return nil, fmt.Errorf("opening %s layer: %w", name, err)
+	}
+	defer f.Close()
+
+	return curation.LoadPointLayer(name, f)
+}
+
+func init() {
+	rootCmd.AddCommand(spatialCmd)
+	spatialCmd.AddCommand(spatialReindexCmd)
+	spatialCmd.AddCommand(spatialProximityCmd)
+	spatialCmd.PersistentFlags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	spatialReindexCmd.Flags().StringVar(
+		&spatialResolutions,
+		"resolutions",
+		fmt.Sprintf("%d-%d", curation.MinCellResolution, curation.MaxCellResolution),
+		"Resoluciones H3 a recalcular, como 1-10 o 9",
+	)
+	spatialCmd.PersistentFlags().IntVar(
+		&spatialChunkSize,
+		"chunk-size",
+		curation.DefaultReindexChunkSize,
+		"Cantidad de filas actualizadas por lote",
+	)
+	spatialProximityCmd.Flags().StringVar(
+		&spatialSchoolZones,
+		"school-zones",
+		"",
+		"Capa GeoJSON de zonas escolares. Por defecto, la incluida",
+	)
+	spatialProximityCmd.Flags().StringVar(
+		&spatialRadars,
+		"radars",
+		"",
+		"Capa GeoJSON de radares fijos. Por defecto, la incluida",
+	)
+}
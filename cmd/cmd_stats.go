@@ -0,0 +1,271 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/stats"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/spf13/cobra"
+)
+
+var statsOptions struct {
+	top  int
+	json bool
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Estadísticas de la base de datos",
+}
+
+var statsSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Resume la base de datos en la terminal",
+	Long: `Muestra las infracciones por departamento y año (con un sparkline de la
+evolución), los artículos más frecuentes, el total de UR y qué proporción de
+las infracciones está geolocalizada y clasificada. Sirve para revisar
+rápidamente una base recién construida sin abrir la interfaz web.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		summary, err := stats.ComputeSummary(db, statsOptions.top)
+		if err != nil {
+			return err
+		}
+
+		names := make(map[int]string)
+		if err := impo.Each(func(ref impo.DbReference) error {
+			names[ref.ID] = ref.Name
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("building db map: %w", err)
+		}
+
+		printSummary(os.Stdout, summary, names)
+
+		return nil
+	},
+}
+
+func printSummary(w io.Writer, s *stats.Summary, names map[int]string) {
+	fmt.Fprintf(w, "Infracciones: %d  UR: %s  Geolocalizadas: %.1f%%  Clasificadas: %.1f%%\n\n",
+		s.Offenses, s.TotalUR, s.GeocodedPct(), s.ClassifiedPct())
+
+	var header strings.Builder
+
+	fmt.Fprintf(&header, "%-26s", "departamento")
+
+	for _, year := range s.Years {
+		fmt.Fprintf(&header, " %8d", year)
+	}
+
+	fmt.Fprintf(&header, " %9s  %s", "total", "evolución")
+	fmt.Fprintln(w, header.String())
+
+	for _, d := range s.Departments {
+		name, ok := names[d.DbID]
+		if !ok {
+			name = fmt.Sprintf("DB %d", d.DbID)
+		}
+
+		fmt.Fprintf(w, "%-26s", name)
+
+		values := make([]int, len(s.Years))
+
+		for i, year := range s.Years {
+			values[i] = d.ByYear[year]
+			fmt.Fprintf(w, " %8d", values[i])
+		}
+
+		fmt.Fprintf(w, " %9d  %s\n", d.Total, stats.Sparkline(values))
+	}
+
+	if len(s.TopArticles) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%-12s %9s %7s\n", "artículo", "infracc.", "%")
+
+	for _, a := range s.TopArticles {
+		fmt.Fprintf(w, "%-12s %9d %6.1f%%\n", a.ArticleID, a.Count, float64(a.Count)*100/float64(max(s.Offenses, 1)))
+	}
+}
+
+var statsPlatesCmd = &cobra.Command{
+	Use:   "matriculas",
+	Short: "Cruza la primera letra de las matrículas uruguayas con la base",
+	Long: `Muestra, para cada base, cuántas matrículas uruguayas empiezan con cada
+letra. Como la primera letra identifica al departamento, sirve para validar el
+mapeo de letras a departamentos; las letras que no corresponden a ningún
+departamento (por ejemplo una serie Mercosur nueva) se listan aparte para
+incorporarlas a las tablas de matrículas.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		report, err := stats.ComputePlateReport(db)
+		if err != nil {
+			return err
+		}
+
+		names := make(map[int]string)
+		if err := impo.Each(func(ref impo.DbReference) error {
+			names[ref.ID] = ref.Name
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("building db map: %w", err)
+		}
+
+		printPlateReport(os.Stdout, report, names)
+
+		return nil
+	},
+}
+
+func printPlateReport(w io.Writer, r *stats.PlateReport, names map[int]string) {
+	var header strings.Builder
+
+	fmt.Fprintf(&header, "%-16s", "base")
+
+	for _, letter := range r.Letters {
+		mark := " "
+		if !impo.IsUruguayDepartment(letter) {
+			mark = "?"
+		}
+
+		fmt.Fprintf(&header, " %6s%s", letter, mark)
+	}
+
+	fmt.Fprintf(&header, " %8s", "total")
+	fmt.Fprintln(w, header.String())
+
+	for _, d := range r.Databases {
+		name, ok := names[d.DbID]
+		if !ok {
+			name = fmt.Sprintf("DB %d", d.DbID)
+		}
+
+		fmt.Fprintf(w, "%-16s", name)
+
+		for _, letter := range r.Letters {
+			fmt.Fprintf(w, " %6.1f%%", float64(d.ByLetter[letter])*100/float64(max(d.Total, 1)))
+		}
+
+		fmt.Fprintf(w, " %8d\n", d.Total)
+	}
+
+	if len(r.Unknown) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\n⚠️  Letras sin departamento asignado:")
+
+	for _, p := range r.Unknown {
+		bases := make([]string, 0, len(p.DbIDs))
+		for _, id := range p.DbIDs {
+			bases = append(bases, names[id])
+		}
+
+		fmt.Fprintf(w, "  %s: %d matrículas (%d Mercosur) en %s\n",
+			p.Letter, p.Count, p.Mercosur, strings.Join(bases, ", "))
+	}
+}
+
+var statsRepeatCmd = &cobra.Command{
+	Use:   "reincidencia",
+	Short: "Distribución anónima de infracciones por matrícula",
+	Long: `Muestra, para cada base y año, cuántas matrículas tienen 1, 2 a 5, 6 a 10
+o más de 10 infracciones y cuántas infracciones suman, junto con la proporción
+de las infracciones cometidas por matrículas reincidentes. Responde qué tan
+concentradas están las multas sin exponer las matrículas. Con --json escribe
+lo mismo que repeat_offenders.json.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		report, err := stats.ComputeRepeatOffenders(db, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if statsOptions.json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(report)
+		}
+
+		printRepeatOffenders(os.Stdout, report)
+
+		return nil
+	},
+}
+
+func printRepeatOffenders(w io.Writer, r *stats.RepeatOffendersReport) {
+	fmt.Fprintf(w, "%-20s %6s %9s", "base", "año", "matríc.")
+
+	for _, label := range stats.RepeatBuckets {
+		fmt.Fprintf(w, " %9s", label)
+	}
+
+	fmt.Fprintf(w, " %11s\n", "reincid. %")
+
+	for _, row := range r.Rows {
+		fmt.Fprintf(w, "%-20s %6d %9d", row.Name, row.Year, row.Plates)
+
+		for _, b := range row.Buckets {
+			fmt.Fprintf(w, " %9d", b.Plates)
+		}
+
+		fmt.Fprintf(w, " %10.1f%%\n", row.RepeatShare())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsSummaryCmd)
+	statsCmd.AddCommand(statsPlatesCmd)
+	statsCmd.AddCommand(statsRepeatCmd)
+	statsCmd.PersistentFlags().StringVar(
+		&impoOptions.DbPath,
+		"db-path",
+		"db",
+		"Directorio base donde almacenar el estado",
+	)
+	statsSummaryCmd.Flags().IntVar(
+		&statsOptions.top,
+		"top",
+		10,
+		"Cantidad de artículos más frecuentes a mostrar",
+	)
+	statsRepeatCmd.Flags().BoolVar(
+		&statsOptions.json,
+		"json",
+		false,
+		"Escribe la distribución en JSON",
+	)
+}
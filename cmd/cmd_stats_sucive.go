@@ -0,0 +1,141 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/sucive"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/jcodagnone/chapauy/utils/httputils"
+	"github.com/spf13/cobra"
+)
+
+var suciveOptions struct {
+	url    string
+	sample int
+	delay  time.Duration
+}
+
+var statsSuciveCmd = &cobra.Command{
+	Use:   "sucive",
+	Short: "Compara una muestra de matrículas con la consulta pública de SUCIVE",
+	Long: `Elige al azar --sample matrículas uruguayas, consulta sus multas en la
+consulta pública de SUCIVE y cuenta cuántas de nuestras infracciones aparecen
+allí con la misma fecha. El porcentaje resultante, total y por base, estima qué
+tan completo es el conjunto de datos.
+
+--url es la dirección de la consulta, con %s en el lugar de la matrícula. Se
+toma como multa cada fila de las tablas de la respuesta que tenga una fecha.
+Los pedidos se espacian según --request-delay para no sobrecargar el servicio.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if !strings.Contains(suciveOptions.url, "%s") {
+			return errors.New("--url debe incluir %s en el lugar de la matrícula")
+		}
+
+		db, err := openDB(dbutils.ReadOnly)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		sample, err := sucive.SampleOffenses(db, suciveOptions.sample)
+		if err != nil {
+			return err
+		}
+
+		report := sucive.Reconcile(cmd.Context(), newSuciveLookup(suciveOptions.url, suciveOptions.delay), sample)
+
+		names := make(map[int]string)
+		if err := impo.Each(func(ref impo.DbReference) error {
+			names[ref.ID] = ref.Name
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("building db map: %w", err)
+		}
+
+		printSuciveReport(os.Stdout, report, names)
+
+		return nil
+	},
+}
+
+// newSuciveLookup returns the lookup of the public page at url, spacing the
+// requests by delay.
+func newSuciveLookup(url string, delay time.Duration) *sucive.HTTPLookup {
+	return &sucive.HTTPLookup{
+		URL: url,
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &httputils.AppendRequestHeadersRoundTripper{
+				Headers: map[string]string{
+					"User-Agent": fmt.Sprintf("chapauy/%s (+https://github.com/jcodagnone/chapauy)", Version),
+				},
+				Transport: &httputils.PoliteRoundTripper{
+					Delay:         delay,
+					MaxRetries:    3,
+					MaxRetryAfter: time.Minute,
+					Transport:     http.DefaultTransport,
+				},
+			},
+		},
+	}
+}
+
+func printSuciveReport(w io.Writer, r *sucive.Report, names map[int]string) {
+	fmt.Fprintf(w, "Matrículas consultadas: %d (%d consultas fallidas)\n", r.Plates, r.LookupErrors)
+	fmt.Fprintf(w, "Infracciones encontradas: %d de %d (%.1f%%)\n\n", r.Matched, r.Offenses, r.Rate())
+
+	ids := make([]int, 0, len(r.ByDb))
+	for id := range r.ByDb {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	fmt.Fprintf(w, "%-16s %9s %11s %7s\n", "base", "infracc.", "encontradas", "%")
+
+	for _, id := range ids {
+		m := r.ByDb[id]
+
+		name, ok := names[id]
+		if !ok {
+			name = fmt.Sprintf("DB %d", id)
+		}
+
+		fmt.Fprintf(w, "%-16s %9d %11d %6.1f%%\n", name, m.Offenses, m.Matched, m.Rate())
+	}
+}
+
+func init() {
+	statsCmd.AddCommand(statsSuciveCmd)
+	statsSuciveCmd.Flags().StringVar(
+		&suciveOptions.url,
+		"url",
+		"",
+		"URL de la consulta pública de multas, con %s en el lugar de la matrícula",
+	)
+	statsSuciveCmd.Flags().IntVar(
+		&suciveOptions.sample,
+		"sample",
+		100,
+		"Cantidad de matrículas a consultar",
+	)
+	statsSuciveCmd.Flags().DurationVar(
+		&suciveOptions.delay,
+		"request-delay",
+		2*time.Second,
+		"Tiempo mínimo entre dos consultas",
+	)
+}
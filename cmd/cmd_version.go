@@ -0,0 +1,96 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildInfo identifies the build that produced the binary (and the databases
+// it writes).
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// complete fills the fields that were not provided through -ldflags using the
+// information the go toolchain embeds in the binary.
+func (b BuildInfo) complete() BuildInfo {
+	b.GoVersion = runtime.Version()
+	b.Platform = runtime.GOOS + "/" + runtime.GOARCH
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if b.Commit == "" {
+					b.Commit = s.Value
+				}
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			}
+		}
+	}
+
+	if b.Commit == "" {
+		b.Commit = "unknown"
+	}
+
+	if b.Date == "" {
+		b.Date = "unknown"
+	}
+
+	return b
+}
+
+// Meta returns the build information as key/values suitable for the `meta`
+// table.
+func (b BuildInfo) Meta() map[string]string {
+	return map[string]string{
+		"build_version":    b.Version,
+		"build_commit":     b.Commit,
+		"build_date":       b.Date,
+		"build_go_version": b.GoVersion,
+		"build_platform":   b.Platform,
+	}
+}
+
+var versionJSON bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Muestra la versión y los datos de compilación",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if versionJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			return enc.Encode(Build)
+		}
+
+		fmt.Printf("chapa %s\n", Build.Version)
+		fmt.Printf("  commit:     %s\n", Build.Commit)
+		fmt.Printf("  fecha:      %s\n", Build.Date)
+		fmt.Printf("  go:         %s\n", Build.GoVersion)
+		fmt.Printf("  plataforma: %s\n", Build.Platform)
+
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Emite la información en formato JSON")
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package articles handles the identifiers of the articles of the traffic
+// regulations: the codes, numbered in roman numerals by the regulations, and
+// the IDs, dotted numbers like "18.3.1" that must be sorted as numbers.
+package articles
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidRoman is returned when a string isn't a roman numeral.
+var ErrInvalidRoman = errors.New("invalid roman numeral")
+
+var (
+	romanValues  = []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	romanSymbols = []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+)
+
+// ToRoman converts a positive integer to a roman numeral, empty when it isn't
+// positive.
+func ToRoman(num int) string {
+	if num <= 0 {
+		return ""
+	}
+
+	var roman strings.Builder
+
+	for i := 0; num > 0; i++ {
+		for num >= romanValues[i] {
+			roman.WriteString(romanSymbols[i])
+			num -= romanValues[i]
+		}
+	}
+
+	return roman.String()
+}
+
+// ParseRoman converts a roman numeral, in any case, to an integer. Only the
+// canonical numerals are accepted, as ToRoman writes them: "IV", not "IIII".
+func ParseRoman(s string) (int, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	rest := upper
+	ret := 0
+
+	for i, symbol := range romanSymbols {
+		for strings.HasPrefix(rest, symbol) {
+			ret += romanValues[i]
+			rest = rest[len(symbol):]
+		}
+	}
+
+	if ret == 0 || rest != "" || ToRoman(ret) != upper {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRoman, s)
+	}
+
+	return ret, nil
+}
+
+// CompareIDs compares two article IDs in their natural order, comparing the
+// runs of digits as numbers: "15.4" goes before "15.10", and "18.3" before
+// "18.3.1". It returns -1, 0 or 1, as cmp.Compare.
+func CompareIDs(a, b string) int {
+	for a != "" && b != "" {
+		ca, ra := chunk(a)
+		cb, rb := chunk(b)
+
+		if c := compareChunks(ca, cb); c != 0 {
+			return c
+		}
+
+		a, b = ra, rb
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// SortIDs sorts article IDs in their natural order, see CompareIDs.
+func SortIDs(ids []string) {
+	slices.SortFunc(ids, CompareIDs)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// chunk splits s after its first run of digits, or of other characters.
+func chunk(s string) (string, string) {
+	digits := isDigit(s[0])
+
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+func compareChunks(a, b string) int {
+	if !isDigit(a[0]) || !isDigit(b[0]) {
+		return strings.Compare(a, b)
+	}
+
+	// numbers of different length compare by length, without the leading
+	// zeros; the zeros break the tie, so "01" and "1" aren't equal
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+
+	return cmp.Or(cmp.Compare(len(ta), len(tb)), strings.Compare(ta, tb), cmp.Compare(len(a), len(b)))
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package articles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoman(t *testing.T) {
+	for n, roman := range map[int]string{
+		1: "I", 4: "IV", 9: "IX", 14: "XIV", 18: "XVIII", 40: "XL", 1994: "MCMXCIV",
+	} {
+		assert.Equal(t, roman, ToRoman(n))
+
+		got, err := ParseRoman(roman)
+		require.NoError(t, err)
+		assert.Equal(t, n, got)
+	}
+
+	assert.Empty(t, ToRoman(0))
+
+	got, err := ParseRoman(" xviii ")
+	require.NoError(t, err)
+	assert.Equal(t, 18, got)
+
+	for _, s := range []string{"", "IIII", "VX", "XIIV", "18", "ABC"} {
+		_, err := ParseRoman(s)
+		assert.ErrorIs(t, err, ErrInvalidRoman, s)
+	}
+}
+
+func TestCompareIDs(t *testing.T) {
+	ids := []string{"15.10", "2", "18.3.1", "15.4", "18.3", "15.4b", "15.4a", "10", "18.10.2", "18.9.1"}
+	SortIDs(ids)
+
+	assert.Equal(t, []string{
+		"2", "10", "15.4", "15.4a", "15.4b", "15.10", "18.3", "18.3.1", "18.9.1", "18.10.2",
+	}, ids)
+
+	assert.Equal(t, 0, CompareIDs("18.1", "18.1"))
+	assert.Equal(t, -1, CompareIDs("1", "01"))
+	assert.Equal(t, 1, CompareIDs("18.1", "18"))
+}
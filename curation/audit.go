@@ -0,0 +1,262 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Audited curator actions.
+const (
+	AuditAcceptJudgment = "accept_judgment"
+	AuditMergeLocations = "merge_locations"
+	AuditMergeCluster   = "merge_cluster"
+	AuditAcceptCluster  = "accept_cluster"
+	AuditLinkCanonical  = "link_canonical_location"
+	AuditClassify       = "classify_description"
+	AuditSplit          = "split_description"
+)
+
+// Kinds of judgments an action can change.
+const (
+	AuditKindLocation    = "location"
+	AuditKindDescription = "description"
+)
+
+// AuditAction is a curator action, recorded with the state of every judgment
+// it changed so that it can be undone.
+type AuditAction struct {
+	ID        int64         `json:"id"`
+	Session   string        `json:"session"`
+	Action    string        `json:"action"`
+	Changes   []AuditChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AuditChange is a judgment as it was before the action. Before is empty when
+// the action created it.
+type AuditChange struct {
+	Kind   string          `json:"kind"`
+	DbID   int             `json:"db_id,omitempty"`
+	Target string          `json:"target"`
+	Before json.RawMessage `json:"before,omitempty"`
+}
+
+// ErrNothingToUndo is returned when a session has no actions left to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// AuditRepository handles persistence of the audit trail of the curators.
+type AuditRepository interface {
+	CreateSchema() error
+	// RecordAction appends an action to the trail, filling its ID.
+	RecordAction(a *AuditAction) error
+	// LastActions returns the last n actions of the session not undone yet,
+	// newest first.
+	LastActions(session string, n int) ([]*AuditAction, error)
+	// MarkUndone flags an action as undone so it isn't undone twice.
+	MarkUndone(id int64) error
+}
+
+type sqlAuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository.
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &sqlAuditRepository{db: db}
+}
+
+func (r *sqlAuditRepository) CreateSchema() error {
+	_, err := r.db.Exec(`
+		CREATE SEQUENCE IF NOT EXISTS curation_audit_seq START 1;
+
+		CREATE TABLE IF NOT EXISTS curation_audit (
+			id BIGINT PRIMARY KEY DEFAULT nextval('curation_audit_seq'),
+			session VARCHAR NOT NULL,
+			action VARCHAR NOT NULL,
+			changes JSON NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			undone_at TIMESTAMP
+		);
+	`)
+
+	return err
+}
+
+func (r *sqlAuditRepository) RecordAction(a *AuditAction) error {
+	changes, err := json.Marshal(a.Changes)
+	if err != nil {
+		return fmt.Errorf("encoding changes: %w", err)
+	}
+
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO curation_audit (session, action, changes, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`, a.Session, a.Action, string(changes), a.CreatedAt).Scan(&a.ID)
+	if err != nil {
+		return fmt.Errorf("recording %s: %w", a.Action, err)
+	}
+
+	return nil
+}
+
+func (r *sqlAuditRepository) LastActions(session string, n int) ([]*AuditAction, error) {
+	rows, err := r.db.Query(`
+		SELECT id, session, action, CAST(changes AS VARCHAR), created_at
+		FROM curation_audit
+		WHERE session = ? AND undone_at IS NULL
+		ORDER BY id DESC
+		LIMIT ?
+	`, session, n)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit trail: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []*AuditAction
+
+	for rows.Next() {
+		var (
+			a       AuditAction
+			changes string
+		)
+
+		if err := rows.Scan(&a.ID, &a.Session, &a.Action, &changes, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning audit trail: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(changes), &a.Changes); err != nil {
+			return nil, fmt.Errorf("decoding changes of action %d: %w", a.ID, err)
+		}
+
+		ret = append(ret, &a)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlAuditRepository) MarkUndone(id int64) error {
+	if _, err := r.db.Exec("UPDATE curation_audit SET undone_at = ? WHERE id = ?", time.Now(), id); err != nil {
+		return fmt.Errorf("marking action %d as undone: %w", id, err)
+	}
+
+	return nil
+}
+
+// snapshotLocations returns the current judgments of the locations, before an
+// action changes them.
+func snapshotLocations(repo LocationRepository, dbID int, locations ...string) ([]AuditChange, error) {
+	changes := make([]AuditChange, 0, len(locations))
+
+	for _, location := range locations {
+		change := AuditChange{Kind: AuditKindLocation, DbID: dbID, Target: location}
+
+		judgments, err := repo.ListJudgments(&dbID, &location, 1, 0)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("looking up judgment of %s: %w", location, err)
+		}
+
+		if len(judgments) > 0 {
+			if change.Before, err = json.Marshal(judgments[0]); err != nil {
+				return nil, fmt.Errorf("encoding judgment of %s: %w", location, err)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// snapshotDescription returns the current classification of a description,
+// before an action changes it.
+func snapshotDescription(repo DescriptionRepository, description string) ([]AuditChange, error) {
+	change := AuditChange{Kind: AuditKindDescription, Target: description}
+
+	d, err := repo.GetDescriptionWithArticles(description)
+	if err != nil {
+		return nil, fmt.Errorf("looking up classification of %s: %w", description, err)
+	}
+
+	if d != nil {
+		if change.Before, err = json.Marshal(d); err != nil {
+			return nil, fmt.Errorf("encoding classification of %s: %w", description, err)
+		}
+	}
+
+	return []AuditChange{change}, nil
+}
+
+// revertChange puts a judgment back in the state recorded by the change,
+// deleting it if the action had created it.
+func revertChange(locRepo LocationRepository, descrRepo DescriptionRepository, c AuditChange) error {
+	switch c.Kind {
+	case AuditKindLocation:
+		if len(c.Before) == 0 {
+			return locRepo.DeleteJudgment(c.DbID, c.Target)
+		}
+
+		var judgment Location
+		if err := json.Unmarshal(c.Before, &judgment); err != nil {
+			return fmt.Errorf("decoding judgment of %s: %w", c.Target, err)
+		}
+
+		return locRepo.SaveJudgment(&judgment)
+	case AuditKindDescription:
+		if len(c.Before) == 0 {
+			return descrRepo.DeleteDescriptionClassification(c.Target)
+		}
+
+		var d Description
+		if err := json.Unmarshal(c.Before, &d); err != nil {
+			return fmt.Errorf("decoding classification of %s: %w", c.Target, err)
+		}
+
+		return descrRepo.SaveDescriptionClassification(d.Description, d.ArticleIDs)
+	default:
+		return fmt.Errorf("unknown audit change kind %q", c.Kind)
+	}
+}
+
+// Undo reverts the last n actions of the session, newest first, returning
+// the actions undone.
+func Undo(
+	auditRepo AuditRepository, locRepo LocationRepository, descrRepo DescriptionRepository, session string, n int,
+) ([]*AuditAction, error) {
+	actions, err := auditRepo.LastActions(session, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actions) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	undone := make([]*AuditAction, 0, len(actions))
+
+	for _, a := range actions {
+		for i := len(a.Changes) - 1; i >= 0; i-- {
+			if err := revertChange(locRepo, descrRepo, a.Changes[i]); err != nil {
+				return undone, fmt.Errorf("undoing %s %d: %w", a.Action, a.ID, err)
+			}
+		}
+
+		if err := auditRepo.MarkUndone(a.ID); err != nil {
+			return undone, err
+		}
+
+		undone = append(undone, a)
+	}
+
+	return undone, nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+// CanonicalLocation is a place curated once and shared by the judgments of
+// every database that references it, so that the same physical point (e.g. a
+// radar on Ruta Interbalnearia) isn't geocoded once per department.
+type CanonicalLocation struct {
+	Name            string         `json:"name"`
+	Point           *spatial.Point `json:"point"`
+	GeocodingMethod string         `json:"geocoding_method"`
+	Confidence      string         `json:"confidence"`
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	// References is the number of judgments pointing to the location. It is
+	// only filled by ListCanonicalLocations.
+	References int `json:"references,omitempty"`
+}
+
+// ErrCanonicalLocationNotFound is returned when linking a judgment to a
+// canonical location that does not exist.
+var ErrCanonicalLocationNotFound = errors.New("canonical location not found")
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// SaveCanonicalLocation creates or updates a canonical location. The point of
+// every judgment referencing it is updated as well.
+func (r *sqlJudgmentRepository) SaveCanonicalLocation(c *CanonicalLocation) error {
+	if c.Name == "" {
+		return errors.New("name can't be empty")
+	}
+
+	if c.Point == nil {
+		return errors.New("point can't be null")
+	}
+
+	// computeH3 lives on Location, borrow it.
+	cells := &Location{Point: c.Point}
+	if err := cells.computeH3(); err != nil {
+		return err
+	}
+
+	cells.computeNearestPlace()
+
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	if _, err := tx.Exec(`
+		INSERT INTO canonical_locations(
+			name, point, geocoding_method, confidence, notes, created_at, updated_at,
+			h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8, h3_res9, h3_res10
+		)
+		VALUES (?, ST_Point(?, ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			point = excluded.point,
+			geocoding_method = excluded.geocoding_method,
+			confidence = excluded.confidence,
+			notes = excluded.notes,
+			updated_at = excluded.updated_at,
+			h3_res1 = excluded.h3_res1, h3_res2 = excluded.h3_res2,
+			h3_res3 = excluded.h3_res3, h3_res4 = excluded.h3_res4,
+			h3_res5 = excluded.h3_res5, h3_res6 = excluded.h3_res6,
+			h3_res7 = excluded.h3_res7, h3_res8 = excluded.h3_res8,
+			h3_res9 = excluded.h3_res9, h3_res10 = excluded.h3_res10
+	`,
+		c.Name, c.Point.Lng, c.Point.Lat, c.GeocodingMethod, c.Confidence, c.Notes, c.CreatedAt, c.UpdatedAt,
+		cells.H3Res1, cells.H3Res2, cells.H3Res3, cells.H3Res4,
+		cells.H3Res5, cells.H3Res6, cells.H3Res7, cells.H3Res8,
+		cells.H3Res9, cells.H3Res10,
+	); err != nil {
+		return fmt.Errorf("saving canonical location %s: %w", c.Name, err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE locations AS l
+		SET point = c.point, updated_at = c.updated_at, nearest_place = ?, nearest_place_m = ?,
+			h3_res1 = c.h3_res1, h3_res2 = c.h3_res2, h3_res3 = c.h3_res3, h3_res4 = c.h3_res4,
+			h3_res5 = c.h3_res5, h3_res6 = c.h3_res6, h3_res7 = c.h3_res7, h3_res8 = c.h3_res8,
+			h3_res9 = c.h3_res9, h3_res10 = c.h3_res10
+		FROM canonical_locations AS c
+		WHERE c.name = ? AND l.global_location = c.name
+	`, nullIfEmpty(cells.NearestPlace), cells.NearestPlaceM, c.Name); err != nil {
+		return fmt.Errorf("propagating canonical location %s: %w", c.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing canonical location: %w", err)
+	}
+
+	return nil
+}
+
+// ListCanonicalLocations returns every canonical location, sorted by name.
+func (r *sqlJudgmentRepository) ListCanonicalLocations() ([]*CanonicalLocation, error) {
+	rows, err := r.db.Query(`
+		SELECT c.name, c.point, c.geocoding_method, c.confidence, c.notes,
+		       c.created_at, c.updated_at, COUNT(l.id)
+		FROM canonical_locations c
+		LEFT JOIN locations l ON l.global_location = c.name
+		GROUP BY ALL
+		ORDER BY c.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying canonical locations: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []*CanonicalLocation
+
+	for rows.Next() {
+		c := &CanonicalLocation{Point: &spatial.Point{}}
+		if err := rows.Scan(
+			&c.Name, c.Point, &c.GeocodingMethod, &c.Confidence, &c.Notes,
+			&c.CreatedAt, &c.UpdatedAt, &c.References,
+		); err != nil {
+			return nil, fmt.Errorf("scanning canonical location: %w", err)
+		}
+
+		ret = append(ret, c)
+	}
+
+	return ret, rows.Err()
+}
+
+// LinkCanonicalLocation makes the judgment of a location reference a
+// canonical location: it takes its name and point. The judgment is created if
+// the location hadn't been curated yet.
+func (r *sqlJudgmentRepository) LinkCanonicalLocation(dbID int, location, name string) error {
+	var c CanonicalLocation
+
+	c.Point = &spatial.Point{}
+
+	err := r.db.QueryRow(`
+		SELECT name, point, geocoding_method, confidence
+		FROM canonical_locations WHERE name = ?
+	`, name).Scan(&c.Name, c.Point, &c.GeocodingMethod, &c.Confidence)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrCanonicalLocationNotFound, name)
+	} else if err != nil {
+		return fmt.Errorf("looking up canonical location %s: %w", name, err)
+	}
+
+	judgment := &Location{
+		DbID:            dbID,
+		Location:        location,
+		GeocodingMethod: c.GeocodingMethod,
+		Confidence:      c.Confidence,
+	}
+
+	judgments, err := r.ListJudgments(&dbID, &location, 1, 0)
+	if err != nil {
+		return fmt.Errorf("looking up judgment: %w", err)
+	}
+
+	if len(judgments) > 0 {
+		judgment = judgments[0]
+	}
+
+	judgment.Point = c.Point
+	judgment.GlobalLocation = c.Name
+	// offenses of every database are renamed to the shared name
+	if location != c.Name {
+		judgment.CanonicalLocation = c.Name
+	}
+
+	return r.SaveJudgment(judgment)
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/uber/h3-go/v4"
+)
+
+// Resolutions of the H3 cells the statistics aggregate (h3_res1 to h3_res8).
+const (
+	MinCellResolution = 1
+	MaxCellResolution = 8
+)
+
+// Errors of the cell statistics.
+var (
+	ErrInvalidCell       = errors.New("invalid h3 cell")
+	ErrInvalidResolution = errors.New("invalid h3 resolution")
+)
+
+// CellCount is the number of offenses in a child cell.
+type CellCount struct {
+	Cell     string  `json:"cell"`
+	Offenses int     `json:"offenses"`
+	Lat      float64 `json:"lat"` // center of the cell
+	Lng      float64 `json:"lng"`
+}
+
+// LocationCount is the number of offenses at a location.
+type LocationCount struct {
+	Location string `json:"location"`
+	Offenses int    `json:"offenses"`
+}
+
+// ArticleCount is the number of offenses classified under an article.
+type ArticleCount struct {
+	ArticleID string `json:"article_id"`
+	Offenses  int    `json:"offenses"`
+}
+
+// CellStats summarizes the active offenses inside an H3 cell, with the
+// breakdown by its children at a finer resolution to drill down the map.
+type CellStats struct {
+	Cell         string          `json:"cell"`
+	Resolution   int             `json:"resolution"` // of the children
+	Offenses     int             `json:"offenses"`
+	TotalUR      impo.UR         `json:"total_ur"`
+	Children     []CellCount     `json:"children"`
+	TopLocations []LocationCount `json:"top_locations"`
+	TopArticles  []ArticleCount  `json:"top_articles"`
+}
+
+// CellStatsRepository computes the statistics of the H3 cells.
+type CellStatsRepository interface {
+	// CellStats returns the statistics of the cell, with its children at
+	// the given resolution and the topN locations and articles. A zero
+	// resolution means the one right below the cell.
+	CellStats(cell string, resolution, topN int) (*CellStats, error)
+}
+
+type sqlCellStatsRepository struct {
+	db *sql.DB
+}
+
+// NewCellStatsRepository creates a new cell statistics repository.
+func NewCellStatsRepository(db *sql.DB) CellStatsRepository {
+	return &sqlCellStatsRepository{db: db}
+}
+
+// parseCell parses an H3 index as the web writes them (hexadecimal) and
+// validates that the offenses store its resolution.
+func parseCell(s string) (h3.Cell, error) {
+	cell := h3.CellFromString(s)
+	if !cell.IsValid() {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidCell, s)
+	}
+
+	if res := cell.Resolution(); res < MinCellResolution || res > MaxCellResolution {
+		return 0, fmt.Errorf("%w: %q has resolution %d", ErrInvalidCell, s, res)
+	}
+
+	return cell, nil
+}
+
+func (r *sqlCellStatsRepository) CellStats(s string, resolution, topN int) (*CellStats, error) {
+	cell, err := parseCell(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cellRes := cell.Resolution()
+	if resolution == 0 {
+		resolution = min(cellRes+1, MaxCellResolution)
+	}
+
+	if resolution < cellRes || resolution > MaxCellResolution {
+		return nil, fmt.Errorf("%w: %d for a cell of resolution %d", ErrInvalidResolution, resolution, cellRes)
+	}
+
+	// both columns come from validated resolutions
+	cellCol := fmt.Sprintf("h3_res%d", cellRes)
+	childCol := fmt.Sprintf("h3_res%d", resolution)
+
+	ret := &CellStats{
+		Cell:         cell.String(),
+		Resolution:   resolution,
+		Children:     []CellCount{},
+		TopLocations: []LocationCount{},
+		TopArticles:  []ArticleCount{},
+	}
+
+	// #nosec G201 - the column names are built from integers
+	if err := r.db.QueryRow(fmt.Sprintf(`
+		SELECT COUNT(*), CAST(COALESCE(SUM(ur), 0) AS BIGINT)
+		FROM active_offenses
+		WHERE %s = ?
+	`, cellCol), uint64(cell)).Scan(&ret.Offenses, &ret.TotalUR); err != nil {
+		return nil, fmt.Errorf("querying offenses of %s: %w", cell, err)
+	}
+
+	if ret.Offenses == 0 {
+		return ret, nil
+	}
+
+	if resolution > cellRes {
+		// #nosec G201 - the column names are built from integers
+		rows, err := r.db.Query(fmt.Sprintf(`
+			SELECT %s, COUNT(*)
+			FROM active_offenses
+			WHERE %s = ? AND %s IS NOT NULL
+			GROUP BY 1
+			ORDER BY 2 DESC, 1
+		`, childCol, cellCol, childCol), uint64(cell))
+		if err != nil {
+			return nil, fmt.Errorf("querying children of %s: %w", cell, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				child uint64
+				c     CellCount
+			)
+
+			if err := rows.Scan(&child, &c.Offenses); err != nil {
+				return nil, fmt.Errorf("scanning child of %s: %w", cell, err)
+			}
+
+			center, err := h3.Cell(child).LatLng()
+			if err != nil {
+				return nil, fmt.Errorf("center of child %x: %w", child, err)
+			}
+
+			c.Cell, c.Lat, c.Lng = h3.Cell(child).String(), center.Lat, center.Lng
+			ret.Children = append(ret.Children, c)
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// #nosec G201 - the column name is built from an integer
+	rows, err := r.db.Query(fmt.Sprintf(`
+		SELECT COALESCE(display_location, location) AS l, COUNT(*)
+		FROM active_offenses
+		WHERE %s = ? AND l IS NOT NULL
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT ?
+	`, cellCol), uint64(cell), topN)
+	if err != nil {
+		return nil, fmt.Errorf("querying locations of %s: %w", cell, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l LocationCount
+		if err := rows.Scan(&l.Location, &l.Offenses); err != nil {
+			return nil, fmt.Errorf("scanning location of %s: %w", cell, err)
+		}
+
+		ret.TopLocations = append(ret.TopLocations, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// #nosec G201 - the column name is built from an integer
+	articles, err := r.db.Query(fmt.Sprintf(`
+		SELECT article_id, COUNT(*)
+		FROM (SELECT unnest(article_ids) AS article_id FROM active_offenses WHERE %s = ?)
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT ?
+	`, cellCol), uint64(cell), topN)
+	if err != nil {
+		return nil, fmt.Errorf("querying articles of %s: %w", cell, err)
+	}
+	defer articles.Close()
+
+	for articles.Next() {
+		var a ArticleCount
+		if err := articles.Scan(&a.ArticleID, &a.Offenses); err != nil {
+			return nil, fmt.Errorf("scanning article of %s: %w", cell, err)
+		}
+
+		ret.TopArticles = append(ret.TopArticles, a)
+	}
+
+	return ret, articles.Err()
+}
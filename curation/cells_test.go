@@ -0,0 +1,146 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/h3-go/v4"
+)
+
+func setupCellsDB(t *testing.T) (*sql.DB, map[string]h3.LatLng) {
+	t.Helper()
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (
+			location VARCHAR, display_location VARCHAR, ur INTEGER, article_ids VARCHAR[],
+			h3_res1 UBIGINT, h3_res2 UBIGINT, h3_res3 UBIGINT, h3_res4 UBIGINT,
+			h3_res5 UBIGINT, h3_res6 UBIGINT, h3_res7 UBIGINT, h3_res8 UBIGINT
+		)
+	`)
+	require.NoError(t, err)
+
+	points := map[string]h3.LatLng{
+		"18 de Julio y Ejido":   h3.NewLatLng(-34.9055, -56.1851),
+		"Rambla y Bvar Artigas": h3.NewLatLng(-34.9209, -56.1590),
+		"Punta del Este":        h3.NewLatLng(-34.9626, -54.9440),
+	}
+
+	insert := func(location string, ur int, articles []string) {
+		cells := make([]any, 0, 8)
+		for res := 1; res <= 8; res++ {
+			cell, err := h3.LatLngToCell(points[location], res)
+			require.NoError(t, err)
+
+			cells = append(cells, uint64(cell))
+		}
+
+		_, err := db.Exec(`INSERT INTO active_offenses VALUES (?, NULL, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			append([]any{location, ur, articles}, cells...)...)
+		require.NoError(t, err)
+	}
+
+	insert("18 de Julio y Ejido", 5, []string{"13.3.A"})
+	insert("18 de Julio y Ejido", 5, []string{"13.3.A", "18.1"})
+	insert("Rambla y Bvar Artigas", 10, []string{"13.3.A"})
+	insert("Punta del Este", 2, []string{"18.1"})
+
+	return db, points
+}
+
+func TestCellStats(t *testing.T) {
+	db, points := setupCellsDB(t)
+	defer db.Close()
+
+	repo := NewCellStatsRepository(db)
+
+	montevideo, err := h3.LatLngToCell(points["18 de Julio y Ejido"], 5)
+	require.NoError(t, err)
+
+	stats, err := repo.CellStats(montevideo.String(), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Offenses)
+	assert.EqualValues(t, 20, stats.TotalUR)
+	assert.Equal(t, 6, stats.Resolution)
+	assert.Equal(t, []LocationCount{
+		{Location: "18 de Julio y Ejido", Offenses: 2},
+		{Location: "Rambla y Bvar Artigas", Offenses: 1},
+	}, stats.TopLocations)
+	assert.Equal(t, []ArticleCount{{ArticleID: "13.3.A", Offenses: 3}, {ArticleID: "18.1", Offenses: 1}}, stats.TopArticles)
+
+	total := 0
+	for _, c := range stats.Children {
+		child := h3.CellFromString(c.Cell)
+		assert.Equal(t, 6, child.Resolution())
+
+		parent, err := child.Parent(5)
+		require.NoError(t, err)
+		assert.Equal(t, montevideo, parent)
+
+		total += c.Offenses
+	}
+
+	assert.Equal(t, 3, total)
+
+	// top limits both rankings
+	stats, err = repo.CellStats(montevideo.String(), 8, 1)
+	require.NoError(t, err)
+	assert.Len(t, stats.TopLocations, 1)
+	assert.Len(t, stats.TopArticles, 1)
+	assert.Equal(t, 8, h3.CellFromString(stats.Children[0].Cell).Resolution())
+
+	// a cell with no offenses
+	empty, err := h3.LatLngToCell(h3.NewLatLng(-30.9, -55.5), 5)
+	require.NoError(t, err)
+
+	stats, err = repo.CellStats(empty.String(), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Offenses)
+	assert.Empty(t, stats.Children)
+
+	_, err = repo.CellStats("not a cell", 0, 10)
+	require.ErrorIs(t, err, ErrInvalidCell)
+
+	_, err = repo.CellStats(montevideo.String(), 4, 10)
+	require.ErrorIs(t, err, ErrInvalidResolution)
+
+	_, err = repo.CellStats(montevideo.String(), 9, 10)
+	require.ErrorIs(t, err, ErrInvalidResolution)
+}
+
+func TestCellStatsAPI(t *testing.T) {
+	db, points := setupCellsDB(t)
+	defer db.Close()
+
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{cellRepo: NewCellStatsRepository(db)}
+	router := gin.New()
+	router.GET("/api/cells/:cell", server.getCellStats)
+
+	cell, err := h3.LatLngToCell(points["Punta del Este"], 3)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/cells/"+cell.String()+"?resolution=5", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"offenses":1`)
+
+	for _, path := range []string{"/api/cells/zzz", "/api/cells/" + cell.String() + "?resolution=x", "/api/cells/" + cell.String() + "?top=0"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// EvalOptions configures a classifier evaluation.
+type EvalOptions struct {
+	TestFraction float64 // share of the classified descriptions held out for testing
+	Seed         uint64  // seed of the train/test split, so runs are comparable
+	Threshold    float64 // minimum score of a suggestion to count as a prediction
+}
+
+// ArticleMetrics are the evaluation counts of a single article.
+type ArticleMetrics struct {
+	ArticleID      string
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+}
+
+// Precision is the share of the predictions of the article that were right.
+func (m ArticleMetrics) Precision() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+}
+
+// Recall is the share of the descriptions of the article that were predicted.
+func (m ArticleMetrics) Recall() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+}
+
+// Confusion counts how many times an article was missed in favour of another
+// one. Predicted is empty when nothing reached the threshold.
+type Confusion struct {
+	Expected  string
+	Predicted string
+	Count     int
+}
+
+// EvalReport is the result of evaluating the classifier.
+type EvalReport struct {
+	Threshold  float64
+	Train      int
+	Test       int
+	Overall    ArticleMetrics // micro-averaged over every article
+	Articles   []ArticleMetrics
+	Confusions []Confusion // most frequent first
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+
+	return float64(a) / float64(b)
+}
+
+// EvaluateClassifier splits the classified descriptions into train and test
+// sets, builds a classifier with the train set and measures how well its
+// suggestions above the threshold match the curated articles of the test set.
+func EvaluateClassifier(articles []Article, descriptions []*Description, opts EvalOptions) *EvalReport {
+	shuffled := make([]*Description, 0, len(descriptions))
+	for _, d := range descriptions {
+		if len(d.ArticleIDs) > 0 {
+			shuffled = append(shuffled, d)
+		}
+	}
+
+	// sort first so the split only depends on the seed
+	sort.Slice(shuffled, func(i, j int) bool { return shuffled[i].Description < shuffled[j].Description })
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed)) // #nosec G404 - reproducible split, not security
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	nTest := int(float64(len(shuffled)) * opts.TestFraction)
+	test, train := shuffled[:nTest], shuffled[nTest:]
+
+	classifier := NewDescriptionClassifierWithDescriptions(articles, train)
+
+	report := &EvalReport{Threshold: opts.Threshold, Train: len(train), Test: len(test)}
+	metrics := make(map[string]*ArticleMetrics)
+	confusions := make(map[[2]string]int)
+
+	metricsOf := func(id string) *ArticleMetrics {
+		if metrics[id] == nil {
+			metrics[id] = &ArticleMetrics{ArticleID: id}
+		}
+
+		return metrics[id]
+	}
+
+	for _, d := range test {
+		expected := make(map[string]bool, len(d.ArticleIDs))
+		for _, id := range d.ArticleIDs {
+			expected[id] = true
+		}
+
+		predicted := make(map[string]bool)
+		for _, s := range classifier.Suggest(d.Description, opts.Threshold) {
+			predicted[s.ArticleID] = true
+		}
+
+		var missed, wrong []string
+
+		for id := range expected {
+			if predicted[id] {
+				metricsOf(id).TruePositives++
+			} else {
+				metricsOf(id).FalseNegatives++
+				missed = append(missed, id)
+			}
+		}
+
+		for id := range predicted {
+			if !expected[id] {
+				metricsOf(id).FalsePositives++
+				wrong = append(wrong, id)
+			}
+		}
+
+		for _, e := range missed {
+			if len(wrong) == 0 {
+				confusions[[2]string{e, ""}]++
+			}
+
+			for _, p := range wrong {
+				confusions[[2]string{e, p}]++
+			}
+		}
+	}
+
+	report.Overall.ArticleID = "*"
+
+	for _, m := range metrics {
+		report.Articles = append(report.Articles, *m)
+		report.Overall.TruePositives += m.TruePositives
+		report.Overall.FalsePositives += m.FalsePositives
+		report.Overall.FalseNegatives += m.FalseNegatives
+	}
+
+	sort.Slice(report.Articles, func(i, j int) bool {
+		return report.Articles[i].ArticleID < report.Articles[j].ArticleID
+	})
+
+	for k, n := range confusions {
+		report.Confusions = append(report.Confusions, Confusion{Expected: k[0], Predicted: k[1], Count: n})
+	}
+
+	sort.Slice(report.Confusions, func(i, j int) bool {
+		a, b := report.Confusions[i], report.Confusions[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+
+		if a.Expected != b.Expected {
+			return a.Expected < b.Expected
+		}
+
+		return a.Predicted < b.Predicted
+	})
+
+	return report
+}
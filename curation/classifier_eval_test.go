@@ -0,0 +1,49 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateClassifier(t *testing.T) {
+	articles := []Article{
+		{ID: "18.9.2", Text: "Estacionar en lugar tarifado sin abonar la tarifa correspondiente."},
+		{ID: "21.3.1", Text: "Conductor o acompañante sin casco protector."},
+	}
+
+	descriptions := []*Description{
+		{Description: "ESTACIONADO SIN ABONAR TARIFA", ArticleIDs: []string{"18.9.2"}},
+		{Description: "CONDUCTOR SIN CASCO", ArticleIDs: []string{"21.3.1"}},
+		{Description: "NO PAGO ESTACIONAMIENTO", ArticleIDs: []string{"18.9.2"}},
+		{Description: "ACOMPAÑANTE SIN CASCO", ArticleIDs: []string{"21.3.1"}},
+		{Description: "SIN CLASIFICAR"},
+	}
+
+	// everything held out, so the classifier only knows the articles
+	report := EvaluateClassifier(articles, descriptions, EvalOptions{TestFraction: 1, Seed: 1, Threshold: 0.5})
+
+	assert.Equal(t, 0, report.Train)
+	assert.Equal(t, 4, report.Test)
+
+	byID := make(map[string]ArticleMetrics)
+	for _, m := range report.Articles {
+		byID[m.ArticleID] = m
+	}
+
+	assert.Equal(t, ArticleMetrics{ArticleID: "18.9.2", TruePositives: 1, FalseNegatives: 1}, byID["18.9.2"])
+	assert.Equal(t, ArticleMetrics{ArticleID: "21.3.1", TruePositives: 2}, byID["21.3.1"])
+	assert.InDelta(t, 1.0, report.Overall.Precision(), 1e-9)
+	assert.InDelta(t, 0.75, report.Overall.Recall(), 1e-9)
+	assert.Equal(t, []Confusion{{Expected: "18.9.2", Predicted: "", Count: 1}}, report.Confusions)
+
+	// the split is reproducible
+	a := EvaluateClassifier(articles, descriptions, EvalOptions{TestFraction: 0.5, Seed: 7, Threshold: 0.5})
+	b := EvaluateClassifier(articles, descriptions, EvalOptions{TestFraction: 0.5, Seed: 7, Threshold: 0.5})
+	assert.Equal(t, a, b)
+	assert.Equal(t, 2, a.Train)
+	assert.Equal(t, 2, a.Test)
+}
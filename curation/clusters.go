@@ -0,0 +1,136 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrClusterNotFound is returned when a cluster no longer exists: it was
+// resolved, or its locations changed since it was listed.
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// ErrPrincipalNotInCluster is returned when the principal chosen to accept a
+// cluster isn't one of its locations.
+var ErrPrincipalNotInCluster = errors.New("principal is not a location of the cluster")
+
+// ClusterID identifies a cluster by its members, regardless of their order.
+func ClusterID(locations []*ClusterLocation) string {
+	members := make([]string, len(locations))
+	for i, l := range locations {
+		members[i] = fmt.Sprintf("%d/%s", l.DbID, l.Description)
+	}
+
+	sort.Strings(members)
+	sum := sha256.Sum256([]byte(strings.Join(members, "\n")))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (r *sqlJudgmentRepository) resolvedClusters() (map[string]bool, error) {
+	rows, err := r.db.Query("SELECT cluster_id FROM resolved_clusters")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make(map[string]bool)
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ret[id] = true
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlJudgmentRepository) FindLocationCluster(dbID *int, id string) (*LocationCluster, error) {
+	clusters, err := r.GetLocationClusters(dbID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range clusters {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, id)
+}
+
+func (r *sqlJudgmentRepository) AcceptCluster(cluster *LocationCluster, principal string) ([]MergeResult, error) {
+	dbID := -1
+
+	for _, l := range cluster.Locations {
+		if l.Description == principal {
+			dbID = l.DbID
+		}
+	}
+
+	if dbID < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPrincipalNotInCluster, principal)
+	}
+
+	var (
+		locations []string
+		skipped   []MergeResult
+	)
+
+	for _, l := range cluster.Locations {
+		switch {
+		case l.Description == principal && l.DbID == dbID:
+		case l.DbID != dbID:
+			// a judgment only references another of its own database: the
+			// clusters are by distance, so a radar shared by two databases
+			// ends up in one
+			skipped = append(skipped, MergeResult{Location: l.Description, Skipped: "location of another database"})
+		default:
+			locations = append(locations, l.Description)
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	results, err := mergeInto(tx, dbID, principal, locations)
+	results = append(results, skipped...)
+
+	if err != nil {
+		return results, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO resolved_clusters (cluster_id, db_id, principal, resolved_at)
+		VALUES (?, ?, ?, ?)
+	`, cluster.ID, dbID, principal, time.Now()); err != nil {
+		return nil, fmt.Errorf("resolving cluster %s: %w", cluster.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing cluster %s: %w", cluster.ID, err)
+	}
+
+	return results, nil
+}
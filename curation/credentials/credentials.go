@@ -0,0 +1,307 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package credentials finds the Google Maps API key of the curation server
+// from a chain of sources, reporting why every source failed instead of
+// falling back silently.
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	apikeys "cloud.google.com/go/apikeys/apiv2"
+	"cloud.google.com/go/apikeys/apiv2/apikeyspb"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/secretmanager/v1"
+)
+
+// Sources of the API key, for the --maps-key-source flag.
+const (
+	SourceAuto          = "auto" // every configured source, in this order
+	SourceEnv           = "env"
+	SourceFile          = "file"
+	SourceSecretManager = "secret-manager"
+	SourceAPIKeys       = "api-keys"
+)
+
+// Sources are the sources a key can be read from, in the order of the auto
+// chain.
+var Sources = []string{SourceEnv, SourceFile, SourceSecretManager, SourceAPIKeys}
+
+// EnvVar is the environment variable of the env source.
+const EnvVar = "GOOGLE_MAPS_API_KEY"
+
+// KeyDisplayName is the display name of the key looked up by the api-keys
+// source. It matches the one created in .dagger/gcp/resources.go.
+const KeyDisplayName = "ChapaUY Geocoding Key"
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// Errors of the providers.
+var (
+	// ErrNotConfigured is returned by the providers that lack their settings,
+	// e.g. the file source without a path. The auto chain skips them.
+	ErrNotConfigured = errors.New("not configured")
+	ErrNoProject     = errors.New("no Google Cloud project: set --maps-key-project or a quota project in the credentials")
+	ErrEmptyKey      = errors.New("empty API key")
+	ErrUnknownSource = errors.New("unknown API key source")
+)
+
+// Provider reads the API key from one source.
+type Provider interface {
+	// Name identifies the source in the logs and errors.
+	Name() string
+	APIKey(ctx context.Context) (string, error)
+}
+
+// Config are the settings of the sources.
+type Config struct {
+	File    string // file with the key of the file source
+	Secret  string // secret of the secret-manager source, a name or a full version resource
+	Project string // project of the Google Cloud sources, by default the one of the credentials
+}
+
+// New returns the provider of a source, or the chain of all of them for
+// SourceAuto.
+func New(source string, cfg Config) (Provider, error) {
+	switch source {
+	case SourceAuto, "":
+		return Chain{
+			EnvProvider{Var: EnvVar},
+			FileProvider{Path: cfg.File},
+			SecretManagerProvider{Secret: cfg.Secret, Project: cfg.Project},
+			APIKeysProvider{DisplayName: KeyDisplayName, Project: cfg.Project},
+		}, nil
+	case SourceEnv:
+		return EnvProvider{Var: EnvVar}, nil
+	case SourceFile:
+		return FileProvider{Path: cfg.File}, nil
+	case SourceSecretManager:
+		return SecretManagerProvider{Secret: cfg.Secret, Project: cfg.Project}, nil
+	case SourceAPIKeys:
+		return APIKeysProvider{DisplayName: KeyDisplayName, Project: cfg.Project}, nil
+	}
+
+	return nil, fmt.Errorf("%w %q, expected %s or one of %v", ErrUnknownSource, source, SourceAuto, Sources)
+}
+
+// Chain tries the providers in order and returns the first key found. The
+// error lists why every provider failed.
+type Chain []Provider
+
+func (c Chain) Name() string {
+	names := make([]string, 0, len(c))
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+func (c Chain) APIKey(ctx context.Context) (string, error) {
+	errs := make([]error, 0, len(c))
+
+	for _, p := range c {
+		key, err := p.APIKey(ctx)
+		if err == nil {
+			log.Printf("🔑 Google Maps API key read from %s", p.Name())
+
+			return key, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// EnvProvider reads the key from an environment variable.
+type EnvProvider struct {
+	Var string
+}
+
+func (p EnvProvider) Name() string {
+	return SourceEnv
+}
+
+func (p EnvProvider) APIKey(_ context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(p.Var))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrNotConfigured, p.Var)
+	}
+
+	return key, nil
+}
+
+// FileProvider reads the key from a file, ignoring the surrounding
+// whitespace.
+type FileProvider struct {
+	Path string
+}
+
+func (p FileProvider) Name() string {
+	return SourceFile
+}
+
+func (p FileProvider) APIKey(_ context.Context) (string, error) {
+	if p.Path == "" {
+		return "", fmt.Errorf("%w: no --maps-key-file", ErrNotConfigured)
+	}
+
+	b, err := os.ReadFile(p.Path) // #nosec G304 - path is from the command line
+	if err != nil {
+		return "", fmt.Errorf("reading key: %w", err)
+	}
+
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("%w in %s", ErrEmptyKey, p.Path)
+	}
+
+	return key, nil
+}
+
+// SecretManagerProvider reads the key from a Secret Manager secret. A bare
+// secret name reads its latest version in the project.
+type SecretManagerProvider struct {
+	Secret  string
+	Project string
+}
+
+func (p SecretManagerProvider) Name() string {
+	return SourceSecretManager
+}
+
+// resource returns the resource name of the version to read.
+func (p SecretManagerProvider) resource(ctx context.Context) (string, error) {
+	if strings.HasPrefix(p.Secret, "projects/") {
+		if strings.Contains(p.Secret, "/versions/") {
+			return p.Secret, nil
+		}
+
+		return p.Secret + "/versions/latest", nil
+	}
+
+	project, err := projectID(ctx, p.Project)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/latest", project, p.Secret), nil
+}
+
+func (p SecretManagerProvider) APIKey(ctx context.Context) (string, error) {
+	if p.Secret == "" {
+		return "", fmt.Errorf("%w: no --maps-key-secret", ErrNotConfigured)
+	}
+
+	name, err := p.resource(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	svc, err := secretmanager.NewService(ctx)
+	if err != nil {
+		return "", fmt.Errorf("creating secret manager client: %w", err)
+	}
+
+	resp, err := svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("accessing %s: %w", name, err)
+	}
+
+	b, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decoding %s: %w", name, err)
+	}
+
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("%w in %s", ErrEmptyKey, name)
+	}
+
+	return key, nil
+}
+
+// APIKeysProvider looks up the key by its display name with the API Keys
+// API, using the application default credentials.
+type APIKeysProvider struct {
+	DisplayName string
+	Project     string
+}
+
+func (p APIKeysProvider) Name() string {
+	return SourceAPIKeys
+}
+
+func (p APIKeysProvider) APIKey(ctx context.Context) (string, error) {
+	project, err := projectID(ctx, p.Project)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := apikeys.NewClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("creating apikeys client: %w", err)
+	}
+	defer client.Close()
+
+	it := client.ListKeys(ctx, &apikeyspb.ListKeysRequest{
+		Parent: fmt.Sprintf("projects/%s/locations/global", project),
+	})
+
+	for {
+		key, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("listing keys: %w", err)
+		}
+
+		if key.DisplayName != p.DisplayName {
+			continue
+		}
+
+		// ListKeys and GetKey redact the key, it takes GetKeyString
+		resp, err := client.GetKeyString(ctx, &apikeyspb.GetKeyStringRequest{Name: key.Name})
+		if err != nil {
+			return "", fmt.Errorf("getting key string of %s: %w", key.Name, err)
+		}
+
+		if resp.KeyString == "" {
+			return "", fmt.Errorf("%w: %s", ErrEmptyKey, key.Name)
+		}
+
+		return resp.KeyString, nil
+	}
+
+	return "", fmt.Errorf("key with display name %q not found in project %s", p.DisplayName, project)
+}
+
+// projectID returns the explicit project or the one of the application
+// default credentials.
+func projectID(ctx context.Context, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+
+	creds, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	if err != nil {
+		return "", fmt.Errorf("finding default credentials: %w", err)
+	}
+
+	if creds.ProjectID == "" {
+		return "", ErrNoProject
+	}
+
+	return creds.ProjectID, nil
+}
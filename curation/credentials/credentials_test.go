@@ -0,0 +1,84 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	p, err := New(SourceAuto, Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "env,file,secret-manager,api-keys", p.Name())
+
+	for _, source := range Sources {
+		p, err := New(source, Config{})
+		require.NoError(t, err)
+		assert.Equal(t, source, p.Name())
+	}
+
+	_, err = New("adc", Config{})
+	require.ErrorIs(t, err, ErrUnknownSource)
+}
+
+func TestProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("CHAPA_TEST_KEY", "")
+
+	_, err := EnvProvider{Var: "CHAPA_TEST_KEY"}.APIKey(ctx)
+	require.ErrorIs(t, err, ErrNotConfigured)
+
+	_, err = FileProvider{}.APIKey(ctx)
+	require.ErrorIs(t, err, ErrNotConfigured)
+
+	_, err = SecretManagerProvider{}.APIKey(ctx)
+	require.ErrorIs(t, err, ErrNotConfigured)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("  \n"), 0o600))
+
+	_, err = FileProvider{Path: path}.APIKey(ctx)
+	require.ErrorIs(t, err, ErrEmptyKey)
+
+	// every failure is reported
+	chain := Chain{EnvProvider{Var: "CHAPA_TEST_KEY"}, FileProvider{Path: path}}
+	_, err = chain.APIKey(ctx)
+	require.ErrorIs(t, err, ErrNotConfigured)
+	require.ErrorIs(t, err, ErrEmptyKey)
+	assert.Contains(t, err.Error(), "env: not configured: CHAPA_TEST_KEY is not set")
+
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+
+	key, err := chain.APIKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", key)
+
+	// the first source that has a key wins
+	t.Setenv("CHAPA_TEST_KEY", "from-env")
+
+	key, err = chain.APIKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", key)
+}
+
+func TestSecretManagerResource(t *testing.T) {
+	ctx := context.Background()
+
+	for secret, want := range map[string]string{
+		"maps-key":                               "projects/p/secrets/maps-key/versions/latest",
+		"projects/q/secrets/maps-key":            "projects/q/secrets/maps-key/versions/latest",
+		"projects/q/secrets/maps-key/versions/3": "projects/q/secrets/maps-key/versions/3",
+	} {
+		name, err := SecretManagerProvider{Secret: secret, Project: "p"}.resource(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, name)
+	}
+}
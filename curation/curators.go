@@ -0,0 +1,209 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// TokenPrefix starts every curator token, to tell them apart from other
+// secrets.
+const TokenPrefix = "chapa_"
+
+// Errors of the curator tokens.
+var (
+	ErrInvalidToken   = errors.New("invalid or revoked curator token")
+	ErrTokenNotFound  = errors.New("curator token not found")
+	ErrCuratorMissing = errors.New("curator name is required")
+)
+
+// CuratorToken identifies a curator in the judgment endpoints. Only the hash
+// of the token is stored: it's shown once, when issued.
+type CuratorToken struct {
+	ID         int64      `json:"id"`
+	Curator    string     `json:"curator"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CuratorRepository handles the tokens of the curators and who changed each
+// judgment. It's a stopgap until the OIDC integration, to deploy the curation
+// server beyond localhost.
+type CuratorRepository interface {
+	CreateSchema() error
+	// IssueToken creates a token for the curator, returning it in clear.
+	IssueToken(curator string) (string, *CuratorToken, error)
+	// Authenticate returns the curator of a token that wasn't revoked.
+	Authenticate(token string) (*CuratorToken, error)
+	ListTokens() ([]*CuratorToken, error)
+	RevokeToken(id int64) error
+	// StampJudgments records the curator as the last one that changed the
+	// judgments of an action.
+	StampJudgments(curator string, changes []AuditChange) error
+}
+
+type sqlCuratorRepository struct {
+	db *sql.DB
+}
+
+// NewCuratorRepository creates a new curator repository.
+func NewCuratorRepository(db *sql.DB) CuratorRepository {
+	return &sqlCuratorRepository{db: db}
+}
+
+func (r *sqlCuratorRepository) CreateSchema() error {
+	_, err := r.db.Exec(`
+		CREATE SEQUENCE IF NOT EXISTS curator_tokens_seq START 1;
+
+		CREATE TABLE IF NOT EXISTS curator_tokens (
+			id BIGINT PRIMARY KEY DEFAULT nextval('curator_tokens_seq'),
+			curator VARCHAR NOT NULL,
+			token_hash VARCHAR NOT NULL UNIQUE,
+			created_at TIMESTAMP NOT NULL,
+			last_used_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		);
+	`)
+
+	return err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *sqlCuratorRepository) IssueToken(curator string) (string, *CuratorToken, error) {
+	if curator = strings.TrimSpace(curator); curator == "" {
+		return "", nil, ErrCuratorMissing
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("generating token: %w", err)
+	}
+
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	t := &CuratorToken{Curator: curator, CreatedAt: time.Now()}
+
+	if err := r.db.QueryRow(`
+		INSERT INTO curator_tokens (curator, token_hash, created_at)
+		VALUES (?, ?, ?)
+		RETURNING id
+	`, t.Curator, hashToken(token), t.CreatedAt).Scan(&t.ID); err != nil {
+		return "", nil, fmt.Errorf("saving token of %s: %w", curator, err)
+	}
+
+	return token, t, nil
+}
+
+func (r *sqlCuratorRepository) Authenticate(token string) (*CuratorToken, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	// a read-only database may not have the table: no token was issued
+	var exists bool
+	if err := r.db.QueryRow(
+		"SELECT COUNT(*) > 0 FROM duckdb_tables() WHERE table_name = 'curator_tokens' AND NOT temporary",
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("looking up token: %w", err)
+	} else if !exists {
+		return nil, ErrInvalidToken
+	}
+
+	t := &CuratorToken{}
+
+	err := r.db.QueryRow(`
+		SELECT id, curator, created_at, last_used_at
+		FROM curator_tokens
+		WHERE token_hash = ? AND revoked_at IS NULL
+	`, hashToken(token)).Scan(&t.ID, &t.Curator, &t.CreatedAt, &t.LastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	} else if err != nil {
+		return nil, fmt.Errorf("looking up token: %w", err)
+	}
+
+	// informative only, e.g. the database may be read-only
+	if _, err := r.db.Exec("UPDATE curator_tokens SET last_used_at = ? WHERE id = ?", time.Now(), t.ID); err != nil {
+		log.Printf("Error updating the last use of token %d: %v", t.ID, err)
+	}
+
+	return t, nil
+}
+
+func (r *sqlCuratorRepository) ListTokens() ([]*CuratorToken, error) {
+	rows, err := r.db.Query(`
+		SELECT id, curator, created_at, last_used_at, revoked_at
+		FROM curator_tokens
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []*CuratorToken
+
+	for rows.Next() {
+		t := &CuratorToken{}
+		if err := rows.Scan(&t.ID, &t.Curator, &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scanning token: %w", err)
+		}
+
+		ret = append(ret, t)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlCuratorRepository) RevokeToken(id int64) error {
+	result, err := r.db.Exec(
+		"UPDATE curator_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("revoking token %d: %w", id, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %d", ErrTokenNotFound, id)
+	}
+
+	return nil
+}
+
+func (r *sqlCuratorRepository) StampJudgments(curator string, changes []AuditChange) error {
+	for _, c := range changes {
+		var err error
+
+		switch c.Kind {
+		case AuditKindLocation:
+			_, err = r.db.Exec("UPDATE locations SET curator = ? WHERE db_id = ? AND location = ?", curator, c.DbID, c.Target)
+		case AuditKindDescription:
+			_, err = r.db.Exec("UPDATE descriptions SET curator = ? WHERE description = ?", curator, c.Target)
+		default:
+			err = fmt.Errorf("unknown audit change kind %q", c.Kind)
+		}
+
+		if err != nil {
+			return fmt.Errorf("stamping %s: %w", c.Target, err)
+		}
+	}
+
+	return nil
+}
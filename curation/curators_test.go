@@ -0,0 +1,182 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCuratorRepository(t *testing.T) (*sql.DB, CuratorRepository) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo := NewCuratorRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	return db, repo
+}
+
+func TestCuratorTokens(t *testing.T) {
+	db, repo := newTestCuratorRepository(t)
+
+	_, _, err := repo.IssueToken("  ")
+	require.ErrorIs(t, err, ErrCuratorMissing)
+
+	token, issued, err := repo.IssueToken("ana")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+
+	// only the hash is stored
+	var stored string
+	require.NoError(t, db.QueryRow("SELECT token_hash FROM curator_tokens").Scan(&stored))
+	assert.NotContains(t, stored, strings.TrimPrefix(token, TokenPrefix))
+
+	got, err := repo.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "ana", got.Curator)
+
+	_, err = repo.Authenticate(token + "x")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	require.NoError(t, repo.RevokeToken(issued.ID))
+	require.ErrorIs(t, repo.RevokeToken(issued.ID), ErrTokenNotFound)
+
+	_, err = repo.Authenticate(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	tokens, err := repo.ListTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+	assert.NotNil(t, tokens[0].RevokedAt)
+}
+
+func TestStampJudgments(t *testing.T) {
+	db, repo := newTestCuratorRepository(t)
+
+	descrRepo := NewDescriptionRepository(db)
+	require.NoError(t, descrRepo.CreateSchema())
+	require.NoError(t, descrRepo.SeedArticles([]Article{{ID: "18.3.3", Text: "Velocidad", Code: 18, Title: "Velocidad"}}))
+	require.NoError(t, descrRepo.SaveDescriptionClassification("EXCESO DE VELOCIDAD", []string{"18.3.3"}))
+
+	require.NoError(t, repo.StampJudgments("ana", []AuditChange{{Kind: AuditKindDescription, Target: "EXCESO DE VELOCIDAD"}}))
+
+	d, err := descrRepo.GetDescriptionWithArticles("EXCESO DE VELOCIDAD")
+	require.NoError(t, err)
+	assert.Equal(t, "ana", d.Curator)
+
+	judgments, err := descrRepo.GetAllDescriptionJudgmentsSorted()
+	require.NoError(t, err)
+	require.Len(t, judgments, 1)
+	assert.Equal(t, "ana", judgments[0].Curator)
+}
+
+func TestAuthenticateRequireTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, repo := newTestCuratorRepository(t)
+	token, _, err := repo.IssueToken("ana")
+	require.NoError(t, err)
+
+	server := &Server{curatorRepo: repo}
+	server.SetRequireTokens(true)
+
+	router := gin.New()
+	router.Use(server.authenticate)
+	router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, curatorOf(c)) })
+	router.GET("/api/test", func(c *gin.Context) { c.String(http.StatusOK, curatorOf(c)) })
+	router.POST("/api/test", func(c *gin.Context) { c.String(http.StatusOK, curatorOf(c)) })
+	router.POST("/api/query", func(c *gin.Context) { c.String(http.StatusOK, curatorOf(c)) })
+
+	tests := []struct {
+		method  string
+		path    string
+		auth    string
+		code    int
+		curator string
+	}{
+		{http.MethodGet, "/", "", http.StatusOK, ""}, // the pages ask for the token
+		{http.MethodGet, "/api/test", "", http.StatusUnauthorized, ""},
+		{http.MethodPost, "/api/test", "", http.StatusUnauthorized, ""},
+		{http.MethodPost, "/api/query", "", http.StatusUnauthorized, ""},
+		{http.MethodGet, "/api/test", "Bearer " + token, http.StatusOK, "ana"},
+		{http.MethodPost, "/api/test", "Bearer " + token, http.StatusOK, "ana"},
+		{http.MethodPost, "/api/query", "Bearer " + token, http.StatusOK, "ana"},
+		{http.MethodPost, "/api/test", "Bearer chapa_nope", http.StatusUnauthorized, ""},
+		{http.MethodGet, "/api/test?token=" + token, "", http.StatusUnauthorized, ""}, // kept out of the logs
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.code, w.Code, "%s %s %s", tt.method, tt.path, tt.auth)
+
+		if tt.code == http.StatusOK {
+			assert.Equal(t, tt.curator, w.Body.String(), "%s %s", tt.method, tt.path)
+		}
+	}
+}
+
+func TestAuthenticateWithoutTable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// e.g. a read-only database where no token was ever issued
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = NewCuratorRepository(db).Authenticate(TokenPrefix + "x")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestCuratorSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, repo := newTestCuratorRepository(t)
+	token, _, err := repo.IssueToken("ana")
+	require.NoError(t, err)
+
+	server := &Server{curatorRepo: repo}
+
+	router := gin.New()
+	router.Use(server.authenticate)
+	router.POST("/api/test", func(c *gin.Context) { c.String(http.StatusOK, curatorSession(c)) })
+
+	session := func(auth string, header string) string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Curation-Session", header)
+
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		return w.Body.String()
+	}
+
+	// a header can't pick another curator's session
+	assert.Equal(t, "ana", session("Bearer "+token, "bob"))
+	assert.Equal(t, "192.0.2.1", session("", "bob"))
+}
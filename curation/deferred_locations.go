@@ -0,0 +1,73 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeferReasonGeocoderQuota defers a location whose suggestion couldn't be
+// computed because the geocoder ran out of quota.
+const DeferReasonGeocoderQuota = "geocoder_quota"
+
+// DeferredLocation is a pending location taken out of the curation queue
+// until a given time, so curators keep working on the rest.
+type DeferredLocation struct {
+	DbID       int       `json:"db_id"`
+	Location   string    `json:"location"`
+	Reason     string    `json:"reason"`
+	Until      time.Time `json:"until"`
+	DeferredAt time.Time `json:"deferred_at"`
+}
+
+// DeferLocation takes a location out of the queue until d.Until. Deferring it
+// again replaces the previous deferral.
+func (r *sqlJudgmentRepository) DeferLocation(d *DeferredLocation) error {
+	if d.DeferredAt.IsZero() {
+		d.DeferredAt = time.Now()
+	}
+
+	if _, err := r.db.Exec(`
+		INSERT INTO deferred_locations(db_id, location, reason, deferred_until, deferred_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (db_id, location) DO UPDATE SET
+			reason = excluded.reason,
+			deferred_until = excluded.deferred_until,
+			deferred_at = excluded.deferred_at
+	`, d.DbID, d.Location, d.Reason, d.Until, d.DeferredAt); err != nil {
+		return fmt.Errorf("deferring location %s: %w", d.Location, err)
+	}
+
+	return nil
+}
+
+// ListDeferredLocations returns the locations that are still deferred and
+// haven't been judged, the ones coming back first.
+func (r *sqlJudgmentRepository) ListDeferredLocations() ([]*DeferredLocation, error) {
+	rows, err := r.db.Query(`
+		SELECT d.db_id, d.location, d.reason, d.deferred_until, d.deferred_at
+		FROM deferred_locations d
+		LEFT JOIN locations l ON l.db_id = d.db_id AND l.location = d.location
+		WHERE d.deferred_until > ? AND l.id IS NULL
+		ORDER BY d.deferred_until, d.db_id, d.location
+	`, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("querying deferred locations: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []*DeferredLocation
+
+	for rows.Next() {
+		d := &DeferredLocation{}
+		if err := rows.Scan(&d.DbID, &d.Location, &d.Reason, &d.Until, &d.DeferredAt); err != nil {
+			return nil, fmt.Errorf("scanning deferred location: %w", err)
+		}
+
+		ret = append(ret, d)
+	}
+
+	return ret, rows.Err()
+}
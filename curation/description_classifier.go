@@ -19,6 +19,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jcodagnone/chapauy/curation/utils"
 )
@@ -39,6 +40,7 @@ type DescriptionClassifier struct {
 	vectors               map[string]map[string]int // Pre-computed word vectors for each article, keyed by ArticleID
 	classifiedByDesc      map[string][]string       // Cache of classified descriptions: description -> article_ids
 	classifiedByDescLower map[string]string         // Lowercase version for case-insensitive lookup: lowercase -> original
+	versions              *utils.ArticleVersions    // Vigency of the renumbered articles
 }
 
 // NewDescriptionClassifier creates a new DescriptionClassifier.
@@ -55,6 +57,7 @@ func NewDescriptionClassifierWithDescriptions(articles []Article, classifiedDesc
 		vectors:               make(map[string]map[string]int),
 		classifiedByDesc:      make(map[string][]string),
 		classifiedByDescLower: make(map[string]string),
+		versions:              ArticleVersionsOf(articles),
 	}
 
 	// Pre-vectorize all articles for faster lookups
@@ -73,7 +76,7 @@ func NewDescriptionClassifierWithDescriptions(articles []Article, classifiedDesc
 }
 
 // Suggest returns a list of suggested articles for a given description.
-// It handles composite descriptions by analyzing the full string and then each comma-separated part.
+// It handles composite descriptions by analyzing the full string and then each of its parts.
 // The suggestions are de-duplicated, keeping the highest score for each article, and then sorted by score.
 func (dc *DescriptionClassifier) Suggest(description string, threshold float64) []Suggestion {
 	allSuggestions := make(map[string]Suggestion) // Use a map to store unique suggestions by ArticleID
@@ -83,13 +86,12 @@ func (dc *DescriptionClassifier) Suggest(description string, threshold float64)
 		allSuggestions[s.ArticleID] = s
 	}
 
-	// 2. If the description contains a comma, analyze each part separately
+	// 2. If the description has separators, analyze each part separately
 	// This helps in cases where multiple distinct offenses are listed in one description.
-	parts := strings.Split(description, ",")
+	parts := utils.DefaultTokenizer.Split(description)
 	if len(parts) > 1 {
 		for _, part := range parts {
-			// Trim spaces around the comma-separated part
-			for _, s := range dc.suggest(strings.TrimSpace(part), threshold) {
+			for _, s := range dc.suggest(part, threshold) {
 				// If a suggestion for this ArticleID already exists, we keep the one with the higher score.
 				if existing, ok := allSuggestions[s.ArticleID]; !ok || s.Score > existing.Score {
 					allSuggestions[s.ArticleID] = s
@@ -112,6 +114,39 @@ func (dc *DescriptionClassifier) Suggest(description string, threshold float64)
 	return result
 }
 
+// SuggestAt is Suggest for an offense that happened at t: every suggested
+// article is replaced by its version in force at that date, keeping the
+// highest score when two suggestions end up being the same article.
+// Articles without a version in force are kept as they are.
+func (dc *DescriptionClassifier) SuggestAt(description string, threshold float64, t time.Time) []Suggestion {
+	suggestions := dc.Suggest(description, threshold)
+	if dc.versions.Empty() {
+		return suggestions
+	}
+
+	texts := make(map[string]string, len(dc.articles))
+	for _, a := range dc.articles {
+		texts[a.ID] = a.Text
+	}
+
+	seen := make(map[string]bool, len(suggestions))
+	result := make([]Suggestion, 0, len(suggestions))
+
+	// suggestions come sorted by score, so the first one of each article wins
+	for _, s := range suggestions {
+		if id, ok := dc.versions.Resolve(s.ArticleID, t); ok && id != s.ArticleID {
+			s.ArticleID, s.Text = id, texts[id]
+		}
+
+		if !seen[s.ArticleID] {
+			seen[s.ArticleID] = true
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
 // suggest performs the core similarity analysis on a single string (either the full description or a part of it).
 // It converts the description into a word vector and then calculates its cosine similarity against
 // all pre-vectorized articles, returning suggestions that meet the specified threshold.
@@ -233,16 +268,10 @@ func cosineSimilarity(v1, v2 map[string]int) float64 {
 }
 
 // DetectMultiArticle returns true if the description appears to have multiple distinct articles.
-// It compares the article suggestions from each comma-separated part. If parts suggest different
+// It compares the article suggestions from each part. If parts suggest different
 // high-confidence articles, it's a multi-article description.
 func (dc *DescriptionClassifier) DetectMultiArticle(description string, threshold float64) bool {
-	// No commas means single-article
-	if !strings.Contains(description, ",") {
-		return false
-	}
-
-	// Analyze each comma-separated part
-	parts := strings.Split(description, ",")
+	parts := utils.DefaultTokenizer.Split(description)
 	if len(parts) <= 1 {
 		return false
 	}
@@ -250,13 +279,8 @@ func (dc *DescriptionClassifier) DetectMultiArticle(description string, threshol
 	var partArticleIDSets []map[string]bool
 
 	for _, part := range parts {
-		trimmedPart := strings.TrimSpace(part)
-		if trimmedPart == "" {
-			continue
-		}
-
 		articleIDs := make(map[string]bool)
-		for _, s := range dc.suggest(trimmedPart, threshold) {
+		for _, s := range dc.suggest(part, threshold) {
 			articleIDs[s.ArticleID] = true
 		}
 
@@ -299,17 +323,17 @@ func articlesMatch(set1, set2 map[string]bool) bool {
 	return true
 }
 
-// SuggestionBreakdown represents suggestions grouped by comma-separated parts.
+// SuggestionBreakdown represents suggestions grouped by description parts.
 type SuggestionBreakdown struct {
 	Part        string       `json:"part"`
 	Suggestions []Suggestion `json:"suggestions"`
 }
 
-// SuggestWithBreakdown returns suggestions grouped by comma-separated parts.
+// SuggestWithBreakdown returns suggestions grouped by description parts.
 // This is useful for display to show which part maps to which articles.
 func (dc *DescriptionClassifier) SuggestWithBreakdown(description string, threshold float64) []SuggestionBreakdown {
-	// If no commas, return single breakdown for the whole description
-	if !strings.Contains(description, ",") {
+	// If there are no separators, return single breakdown for the whole description
+	if !utils.DefaultTokenizer.HasSeparator(description) {
 		return []SuggestionBreakdown{
 			{
 				Part:        description,
@@ -318,20 +342,15 @@ func (dc *DescriptionClassifier) SuggestWithBreakdown(description string, thresh
 		}
 	}
 
-	// Analyze each comma-separated part
-	parts := strings.Split(description, ",")
+	// Analyze each part
+	parts := utils.DefaultTokenizer.Split(description)
 
 	breakdown := make([]SuggestionBreakdown, 0, len(parts))
 
 	for _, part := range parts {
-		trimmedPart := strings.TrimSpace(part)
-		if trimmedPart == "" {
-			continue
-		}
-
 		breakdown = append(breakdown, SuggestionBreakdown{
-			Part:        trimmedPart,
-			Suggestions: dc.suggest(trimmedPart, threshold),
+			Part:        part,
+			Suggestions: dc.suggest(part, threshold),
 		})
 	}
 
@@ -16,6 +16,7 @@ package curation
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -226,3 +227,30 @@ func TestSuggestWithExactMatch(t *testing.T) {
 	assert.NotEmpty(t, suggestionsUnknown)
 	assert.Less(t, suggestionsUnknown[0].Score, 1.0) // Similarity match, not exact
 }
+
+func TestSuggestAt(t *testing.T) {
+	renumbered := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	articles := []Article{
+		{ID: "21.3", Text: "Conductor sin casco protector.", EffectiveTo: &renumbered, ReplacedBy: "21.3.1"},
+		{ID: "21.3.1", Text: "Conductor o acompañante sin casco protector.", EffectiveFrom: &renumbered},
+	}
+
+	dc := NewDescriptionClassifier(articles)
+
+	ids := func(suggestions []Suggestion) []string {
+		ret := make([]string, 0, len(suggestions))
+		for _, s := range suggestions {
+			ret = append(ret, s.ArticleID)
+		}
+
+		return ret
+	}
+
+	// both versions match, but only one applies at each date
+	assert.ElementsMatch(t, []string{"21.3", "21.3.1"}, ids(dc.Suggest("CONDUCTOR SIN CASCO", 0.5)))
+	assert.Equal(t, []string{"21.3"}, ids(dc.SuggestAt("CONDUCTOR SIN CASCO", 0.5, renumbered.AddDate(0, -1, 0))))
+
+	after := dc.SuggestAt("CONDUCTOR SIN CASCO", 0.5, renumbered)
+	assert.Equal(t, []string{"21.3.1"}, ids(after))
+	assert.Equal(t, "Conductor o acompañante sin casco protector.", after[0].Text)
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+)
+
+// ImportedClassification is a description classified by another project.
+type ImportedClassification struct {
+	Description string   `json:"description"`
+	ArticleIDs  []string `json:"article_ids"`
+}
+
+// ImportConflict is an imported classification that disagrees with the one
+// we already have, left for the curators to review.
+type ImportConflict struct {
+	Description string   `json:"description"`
+	Existing    []string `json:"existing"`
+	Imported    []string `json:"imported"`
+	Source      string   `json:"source,omitempty"` // of the existing one, empty if ours
+}
+
+// ImportInvalid is an imported classification that can't be imported.
+type ImportInvalid struct {
+	Description string `json:"description"`
+	Reason      string `json:"reason"`
+}
+
+// ImportReport is the outcome of an import.
+type ImportReport struct {
+	Imported  int              `json:"imported"`
+	Agreed    int              `json:"agreed"` // already classified with the same articles
+	Conflicts []ImportConflict `json:"conflicts"`
+	Invalid   []ImportInvalid  `json:"invalid"`
+}
+
+// ImportClassifications imports the classifications of an external dataset,
+// recording source as their provenance. Only the descriptions we haven't
+// classified are imported: the ones we have are never overwritten, and
+// disagreements are reported as conflicts. The article IDs are validated
+// against our catalog. With dryRun nothing is saved.
+func (r *sqlDescriptionRepository) ImportClassifications(
+	items []ImportedClassification, source string, dryRun bool,
+) (*ImportReport, error) {
+	if source = strings.TrimSpace(source); source == "" {
+		return nil, errors.New("the source of the classifications is required")
+	}
+
+	articles, err := r.ListArticles()
+	if err != nil {
+		return nil, fmt.Errorf("listing articles: %w", err)
+	}
+
+	known := make(map[string]bool, len(articles))
+	for _, a := range articles {
+		known[a.ID] = true
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("failed to rollback transaction importing descriptions from %s: %v", source, err)
+		}
+	}()
+
+	report := &ImportReport{}
+
+	for _, item := range items {
+		if reason := invalidImport(item, known); reason != "" {
+			report.Invalid = append(report.Invalid, ImportInvalid{Description: item.Description, Reason: reason})
+
+			continue
+		}
+
+		var (
+			existing         string
+			idsVal           any
+			existingSource   sql.NullString
+			existingArticles []string
+		)
+
+		err := tx.QueryRow(
+			"SELECT description, article_ids, source FROM descriptions WHERE description_key = "+descriptionKey,
+			item.Description,
+		).Scan(&existing, &idsVal, &existingSource)
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if err := saveDescriptionClassification(tx, item.Description, item.ArticleIDs); err != nil {
+				return nil, fmt.Errorf("importing %q: %w", item.Description, err)
+			}
+
+			if _, err := tx.Exec("UPDATE descriptions SET source = ? WHERE description = ?", source, item.Description); err != nil {
+				return nil, fmt.Errorf("recording source of %q: %w", item.Description, err)
+			}
+
+			report.Imported++
+		case err != nil:
+			return nil, fmt.Errorf("looking up %q: %w", item.Description, err)
+		default:
+			existingArticles, _ = utils.AnyToStringSlice(idsVal)
+			if sameArticles(existingArticles, item.ArticleIDs) {
+				report.Agreed++
+			} else {
+				report.Conflicts = append(report.Conflicts, ImportConflict{
+					Description: existing,
+					Existing:    existingArticles,
+					Imported:    item.ArticleIDs,
+					Source:      existingSource.String,
+				})
+			}
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// invalidImport returns why an imported classification can't be imported,
+// or "" if it can.
+func invalidImport(item ImportedClassification, known map[string]bool) string {
+	if strings.TrimSpace(item.Description) == "" {
+		return "empty description"
+	}
+
+	if len(item.ArticleIDs) == 0 {
+		return "no articles"
+	}
+
+	var unknown []string
+
+	for _, id := range item.ArticleIDs {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return "unknown articles: " + strings.Join(unknown, ", ")
+	}
+
+	return ""
+}
+
+// sameArticles compares two sets of article IDs, regardless of their order.
+func sameArticles(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
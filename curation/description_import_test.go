@@ -0,0 +1,68 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportClassifications(t *testing.T) {
+	db, repo := setupDescriptionDB(t)
+	defer db.Close()
+
+	require.NoError(t, repo.SaveDescriptionClassification("EXCESO DE VELOCIDAD", []string{"G.1"}))
+	require.NoError(t, repo.SaveDescriptionClassification("SIN CASCO", []string{"G.2"}))
+
+	items := []ImportedClassification{
+		{Description: "Exceso de velocidad", ArticleIDs: []string{"G.1"}},
+		{Description: "SIN CASCO", ArticleIDs: []string{"G.3"}},
+		{Description: "SIN LUCES", ArticleIDs: []string{"G.3", "G.1"}},
+		{Description: "SIN LIBRETA", ArticleIDs: []string{"99.9"}},
+		{Description: "SIN CINTURON"},
+	}
+
+	report, err := repo.ImportClassifications(items, "otro-proyecto", true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+
+	saved, err := repo.GetDescriptionWithArticles("SIN LUCES")
+	require.NoError(t, err)
+	assert.Nil(t, saved, "dry run")
+
+	report, err = repo.ImportClassifications(items, "otro-proyecto", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 1, report.Agreed)
+	assert.Equal(t, []ImportConflict{
+		{Description: "SIN CASCO", Existing: []string{"G.2"}, Imported: []string{"G.3"}},
+	}, report.Conflicts)
+	assert.Equal(t, []ImportInvalid{
+		{Description: "SIN LIBRETA", Reason: "unknown articles: 99.9"},
+		{Description: "SIN CINTURON", Reason: "no articles"},
+	}, report.Invalid)
+
+	saved, err = repo.GetDescriptionWithArticles("SIN LUCES")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, []string{"G.3", "G.1"}, saved.ArticleIDs)
+	assert.Equal(t, "otro-proyecto", saved.Source)
+
+	// ours are never overwritten
+	saved, err = repo.GetDescriptionWithArticles("SIN CASCO")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"G.2"}, saved.ArticleIDs)
+	assert.Empty(t, saved.Source)
+
+	// a curator reviewing an imported classification makes it ours
+	require.NoError(t, repo.SaveDescriptionClassification("SIN LUCES", []string{"G.3"}))
+	saved, err = repo.GetDescriptionWithArticles("SIN LUCES")
+	require.NoError(t, err)
+	assert.Empty(t, saved.Source)
+
+	_, err = repo.ImportClassifications(items, " ", false)
+	assert.Error(t, err)
+}
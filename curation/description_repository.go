@@ -8,9 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/jcodagnone/chapauy/curation/articles"
 	"github.com/jcodagnone/chapauy/curation/utils"
 )
 
@@ -26,6 +28,46 @@ type Article struct {
 	Text  string `json:"text"`
 	Code  int8   `json:"code"`
 	Title string `json:"title"`
+	// EffectiveFrom and EffectiveTo bound the period the ID was in force,
+	// for the articles that were renumbered in later digests.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	// ReplacedBy is the ID the article was renumbered to after EffectiveTo.
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+// Version returns the vigency of the article.
+func (a *Article) Version() utils.ArticleVersion {
+	v := utils.ArticleVersion{ID: a.ID, ReplacedBy: a.ReplacedBy}
+	if a.EffectiveFrom != nil {
+		v.From = *a.EffectiveFrom
+	}
+
+	if a.EffectiveTo != nil {
+		v.To = *a.EffectiveTo
+	}
+
+	return v
+}
+
+// ArticleVersionsOf indexes the vigency of the articles.
+func ArticleVersionsOf(articles []Article) *utils.ArticleVersions {
+	versions := make([]utils.ArticleVersion, 0, len(articles))
+	for i := range articles {
+		versions = append(versions, articles[i].Version())
+	}
+
+	return utils.NewArticleVersions(versions)
+}
+
+// ArticleConflict is a classified description whose offenses happened when
+// none of the versions of one of its articles was in force.
+type ArticleConflict struct {
+	Description string    `json:"description"`
+	ArticleID   string    `json:"article_id"`
+	Offenses    int       `json:"offenses"`
+	FirstTime   time.Time `json:"first_time"`
+	LastTime    time.Time `json:"last_time"`
 }
 
 // Description represents a raw offense description and its classification.
@@ -35,6 +77,10 @@ type Description struct {
 	ArticleIDs   []string  `json:"article_ids"`
 	ArticleCodes []int8    `json:"article_codes,omitempty"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	Curator      string    `json:"curator,omitempty"` // see Location.Curator
+	// Source is the external dataset the classification was imported from,
+	// empty for the ones of our curators.
+	Source string `json:"source,omitempty"`
 }
 
 // ReviewDescription represents a description to be reviewed.
@@ -65,6 +111,8 @@ type DescriptionRepository interface {
 	ListArticles() ([]Article, error)
 	ListArticleSections() ([]ValueCount, error)
 	SaveDescriptionClassification(description string, articleIDs []string) error
+	SaveCompositeClassification(description string, parts []DescriptionPart) error
+	DeleteDescriptionClassification(description string) error
 	GetDescriptionProgress() (totalDescriptions, classifiedDescriptions, totalOffenses, classifiedOffenses int, err error)
 	// New methods for bulk operations
 	GetAllDescriptionJudgmentsSorted() ([]*Description, error)
@@ -77,6 +125,8 @@ type DescriptionRepository interface {
 	AreMultiArticlePartsClassified(description string) (bool, error)
 	GetDescriptionWithArticles(description string) (*Description, error)
 	GetReviewAssignments() ([]ReviewCode, error)
+	ListArticleConflicts() ([]ArticleConflict, error)
+	ImportClassifications(items []ImportedClassification, source string, dryRun bool) (*ImportReport, error)
 }
 
 type sqlDescriptionRepository struct {
@@ -97,6 +147,10 @@ func (r *sqlDescriptionRepository) CreateSchema() error {
 			title VARCHAR NOT NULL
 		);
 
+		ALTER TABLE articles ADD COLUMN IF NOT EXISTS effective_from DATE;
+		ALTER TABLE articles ADD COLUMN IF NOT EXISTS effective_to DATE;
+		ALTER TABLE articles ADD COLUMN IF NOT EXISTS replaced_by VARCHAR;
+
 		CREATE SEQUENCE IF NOT EXISTS descriptions_seq;
 		CREATE TABLE IF NOT EXISTS descriptions (
 			id INTEGER PRIMARY KEY DEFAULT nextval('descriptions_seq'),
@@ -105,9 +159,85 @@ func (r *sqlDescriptionRepository) CreateSchema() error {
 			article_codes TINYINT[],
 			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
+
+		ALTER TABLE descriptions ADD COLUMN IF NOT EXISTS curator VARCHAR;
+		-- see utils.DescriptionKeyExpr
+		ALTER TABLE descriptions ADD COLUMN IF NOT EXISTS description_key VARCHAR;
+		ALTER TABLE descriptions ADD COLUMN IF NOT EXISTS source VARCHAR;
+
+		-- filled by the backport with the offenses that have no version of
+		-- an article in force at their date
+		CREATE TABLE IF NOT EXISTS article_conflicts (
+			description VARCHAR NOT NULL,
+			article_id VARCHAR NOT NULL,
+			offenses INTEGER NOT NULL,
+			first_time TIMESTAMPTZ NOT NULL,
+			last_time TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (description, article_id)
+		);
 	`)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// descriptions saved before they were compared by their key
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("failed to rollback transaction merging duplicate descriptions: %v", err)
+		}
+	}()
+
+	n, err := mergeDuplicateDescriptions(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if n > 0 {
+		log.Printf("✅ Merged %d duplicate descriptions", n)
+	}
+
+	return nil
+}
+
+// descriptionKey is the key of the description passed as parameter.
+var descriptionKey = utils.DescriptionKeyExpr("?")
+
+// mergeDuplicateDescriptions fills the missing keys of the descriptions and,
+// of the ones sharing a key, keeps the most recent classification.
+func mergeDuplicateDescriptions(tx *sql.Tx) (int64, error) {
+	key := utils.DescriptionKeyExpr("description")
+
+	// #nosec G202 - the key is a constant expression
+	if _, err := tx.Exec(`
+		UPDATE descriptions SET description_key = ` + key + `
+		WHERE description_key IS DISTINCT FROM ` + key); err != nil {
+		return 0, fmt.Errorf("computing description keys: %w", err)
+	}
+
+	res, err := tx.Exec(`
+		DELETE FROM descriptions
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, row_number() OVER (PARTITION BY description_key ORDER BY updated_at DESC, id DESC) AS n
+				FROM descriptions
+			)
+			WHERE n > 1
+		)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("merging duplicate descriptions: %w", err)
+	}
+
+	return res.RowsAffected()
 }
 
 func (r *sqlDescriptionRepository) SeedArticles(articles []Article) error {
@@ -116,7 +246,10 @@ func (r *sqlDescriptionRepository) SeedArticles(articles []Article) error {
 		return err
 	}
 
-	stmt, err := tx.Prepare("INSERT OR IGNORE INTO articles (id, text, code, title) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO articles (id, text, code, title, effective_from, effective_to, replaced_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
 			return err
@@ -127,7 +260,8 @@ func (r *sqlDescriptionRepository) SeedArticles(articles []Article) error {
 	defer stmt.Close()
 
 	for _, article := range articles {
-		_, err := stmt.Exec(article.ID, article.Text, article.Code, article.Title)
+		_, err := stmt.Exec(article.ID, article.Text, article.Code, article.Title,
+			article.EffectiveFrom, article.EffectiveTo, sql.NullString{String: article.ReplacedBy, Valid: article.ReplacedBy != ""})
 		if err != nil {
 			if err := tx.Rollback(); err != nil {
 				return err
@@ -146,7 +280,7 @@ func (r *sqlDescriptionRepository) GetUnclassifiedDescriptions(limit int) ([]Des
 			o.description,
 			COUNT(*) as count
 		FROM offenses o
-		LEFT JOIN descriptions d ON o.description = d.description
+		LEFT JOIN descriptions d ON ` + utils.DescriptionKeyExpr("o.description") + ` = d.description_key
 		WHERE o.description IS NOT NULL AND d.description IS NULL
 		GROUP BY o.description
 		ORDER BY count DESC, o.description ASC
@@ -173,25 +307,67 @@ func (r *sqlDescriptionRepository) GetUnclassifiedDescriptions(limit int) ([]Des
 	return descriptions, nil
 }
 
+// ListArticles returns the articles in the natural order of their IDs, see
+// articles.CompareIDs.
 func (r *sqlDescriptionRepository) ListArticles() ([]Article, error) {
-	rows, err := r.db.Query("SELECT id, text, code, title FROM articles ORDER BY id")
+	rows, err := r.db.Query("SELECT " + articleColumns + " FROM articles")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	ret, err := scanArticles(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(ret, func(a, b Article) int { return articles.CompareIDs(a.ID, b.ID) })
+
+	return ret, nil
+}
+
+const articleColumns = "id, text, code, title, effective_from, effective_to, COALESCE(replaced_by, '')"
+
+func scanArticles(rows *sql.Rows) ([]Article, error) {
 	var articles []Article
 
 	for rows.Next() {
 		var a Article
-		if err := rows.Scan(&a.ID, &a.Text, &a.Code, &a.Title); err != nil {
+		if err := rows.Scan(&a.ID, &a.Text, &a.Code, &a.Title, &a.EffectiveFrom, &a.EffectiveTo, &a.ReplacedBy); err != nil {
 			return nil, err
 		}
 
 		articles = append(articles, a)
 	}
 
-	return articles, nil
+	return articles, rows.Err()
+}
+
+// ListArticleConflicts returns the conflicts found by the last backport, the
+// ones with more offenses first.
+func (r *sqlDescriptionRepository) ListArticleConflicts() ([]ArticleConflict, error) {
+	rows, err := r.db.Query(`
+		SELECT description, article_id, offenses, first_time, last_time
+		FROM article_conflicts
+		ORDER BY offenses DESC, description, article_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying article conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []ArticleConflict
+
+	for rows.Next() {
+		var c ArticleConflict
+		if err := rows.Scan(&c.Description, &c.ArticleID, &c.Offenses, &c.FirstTime, &c.LastTime); err != nil {
+			return nil, fmt.Errorf("scanning article conflict: %w", err)
+		}
+
+		ret = append(ret, c)
+	}
+
+	return ret, rows.Err()
 }
 
 func (r *sqlDescriptionRepository) SaveDescriptionClassification(description string, articleIDs []string) error {
@@ -206,6 +382,59 @@ func (r *sqlDescriptionRepository) SaveDescriptionClassification(description str
 		}
 	}()
 
+	if err := saveDescriptionClassification(tx, description, articleIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveCompositeClassification classifies every part of a composite
+// description and the description itself, with the union of the articles of
+// its parts, in a single transaction: either all of them are saved or none.
+func (r *sqlDescriptionRepository) SaveCompositeClassification(description string, parts []DescriptionPart) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("failed to rollback transaction saving composite classification for %s: %v", description, err)
+		}
+	}()
+
+	var all []string
+
+	seen := make(map[string]bool)
+
+	for _, p := range parts {
+		if len(p.ArticleIDs) == 0 {
+			return fmt.Errorf("part %q has no articles", p.Part)
+		}
+
+		if err := saveDescriptionClassification(tx, p.Part, p.ArticleIDs); err != nil {
+			return fmt.Errorf("classifying part %q: %w", p.Part, err)
+		}
+
+		for _, id := range p.ArticleIDs {
+			if !seen[id] {
+				seen[id] = true
+				all = append(all, id)
+			}
+		}
+	}
+
+	if err := saveDescriptionClassification(tx, description, all); err != nil {
+		return fmt.Errorf("classifying %q: %w", description, err)
+	}
+
+	return tx.Commit()
+}
+
+// saveDescriptionClassification upserts the classification of a description
+// within the transaction.
+func saveDescriptionClassification(tx *sql.Tx, description string, articleIDs []string) error {
 	// 1. Fetch article codes for the given article IDs
 	var articleCodes []int8
 
@@ -254,24 +483,57 @@ func (r *sqlDescriptionRepository) SaveDescriptionClassification(description str
 	// 2. Save to descriptions table
 	now := time.Now()
 
+	// the same description written differently: the new classification
+	// replaces it, text included
+	var existing string
+
+	err := tx.QueryRow("SELECT description FROM descriptions WHERE description_key = "+descriptionKey, description).Scan(&existing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil && existing != description {
+		_, err = tx.Exec(`
+			UPDATE descriptions SET
+				description = ?,
+				article_ids = ?,
+				article_codes = ?,
+				updated_at = ?,
+				curator = NULL,
+				source = NULL
+			WHERE description = ?
+		`, description, articleIDs, articleCodes, now, existing)
+
+		return err
+	}
+
 	_, err = tx.Exec(`
-		INSERT INTO descriptions (description, article_ids, article_codes, updated_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO descriptions (description, description_key, article_ids, article_codes, updated_at)
+		VALUES (?, `+descriptionKey+`, ?, ?, ?)
 		ON CONFLICT(description) DO UPDATE SET
 			article_ids = excluded.article_ids,
 			article_codes = excluded.article_codes,
-			updated_at = excluded.updated_at;
-	`, description, articleIDs, articleCodes, now)
-	if err != nil {
-		return err
+			updated_at = excluded.updated_at,
+			source = NULL,
+			curator = NULL; -- stamped afterwards when the request has a token
+	`, description, description, articleIDs, articleCodes, now)
+
+	return err
+}
+
+// DeleteDescriptionClassification removes the classification of a description,
+// putting it back in the queue.
+func (r *sqlDescriptionRepository) DeleteDescriptionClassification(description string) error {
+	if _, err := r.db.Exec("DELETE FROM descriptions WHERE description_key = "+descriptionKey, description); err != nil {
+		return fmt.Errorf("deleting classification of %s: %w", description, err)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 // GetAllDescriptionJudgmentsSorted retrieves all description judgments from the database.
 func (r *sqlDescriptionRepository) GetAllDescriptionJudgmentsSorted() ([]*Description, error) {
-	rows, err := r.db.Query("SELECT description, article_ids, article_codes, updated_at FROM descriptions ORDER BY description")
+	rows, err := r.db.Query("SELECT description, article_ids, article_codes, updated_at, COALESCE(curator, ''), COALESCE(source, '') FROM descriptions ORDER BY description")
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +545,7 @@ func (r *sqlDescriptionRepository) GetAllDescriptionJudgmentsSorted() ([]*Descri
 		var j Description
 
 		var articleIDs, articleCodes any
-		if err := rows.Scan(&j.Description, &articleIDs, &articleCodes, &j.UpdatedAt); err != nil {
+		if err := rows.Scan(&j.Description, &articleIDs, &articleCodes, &j.UpdatedAt, &j.Curator, &j.Source); err != nil {
 			return nil, err
 		}
 
@@ -348,12 +610,14 @@ func (r *sqlDescriptionRepository) BulkInsertDescriptionJudgments(judgments []*D
 	}
 
 	stmt, err := tx.Prepare(`
-		INSERT INTO descriptions (description, article_ids, article_codes, updated_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO descriptions (description, description_key, article_ids, article_codes, updated_at, curator, source)
+		VALUES (?, ` + descriptionKey + `, ?, ?, ?, ?, ?)
 		ON CONFLICT(description) DO UPDATE SET
 			article_ids = excluded.article_ids,
 			article_codes = excluded.article_codes,
-			updated_at = excluded.updated_at;
+			updated_at = excluded.updated_at,
+			curator = excluded.curator,
+			source = excluded.source;
 	`)
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
@@ -365,7 +629,7 @@ func (r *sqlDescriptionRepository) BulkInsertDescriptionJudgments(judgments []*D
 	defer stmt.Close()
 
 	for _, j := range judgments {
-		if _, err := stmt.Exec(j.Description, j.ArticleIDs, j.ArticleCodes, j.UpdatedAt); err != nil {
+		if _, err := stmt.Exec(j.Description, j.Description, j.ArticleIDs, j.ArticleCodes, j.UpdatedAt, nullIfEmpty(j.Curator), nullIfEmpty(j.Source)); err != nil {
 			if err := tx.Rollback(); err != nil {
 				return err
 			}
@@ -374,6 +638,20 @@ func (r *sqlDescriptionRepository) BulkInsertDescriptionJudgments(judgments []*D
 		}
 	}
 
+	// the file may have the same description written differently
+	n, err := mergeDuplicateDescriptions(tx)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+
+		return err
+	}
+
+	if n > 0 {
+		log.Printf("✅ Merged %d duplicate description judgments", n)
+	}
+
 	return tx.Commit()
 }
 
@@ -423,7 +701,7 @@ func (r *sqlDescriptionRepository) GetDescriptionProgress() (totalDescriptions,
 	queryClassifiedOffenses := `
 		SELECT COUNT(*)
 		FROM offenses o
-		INNER JOIN descriptions d ON o.description = d.description`
+		INNER JOIN descriptions d ON ` + utils.DescriptionKeyExpr("o.description") + ` = d.description_key`
 
 	err = r.db.QueryRow(queryClassifiedOffenses).Scan(&classifiedOffenses)
 	if err != nil {
@@ -471,7 +749,7 @@ func (r *sqlDescriptionRepository) SearchArticles(query string) ([]Article, erro
 	}
 
 	sqlQuery := fmt.Sprintf(`
-		SELECT id, text, code, title
+		SELECT `+articleColumns+`
 		FROM articles
 		WHERE %s
 		ORDER BY (%s) DESC, id
@@ -486,18 +764,7 @@ func (r *sqlDescriptionRepository) SearchArticles(query string) ([]Article, erro
 	}
 	defer rows.Close()
 
-	var articles []Article
-
-	for rows.Next() {
-		var a Article
-		if err := rows.Scan(&a.ID, &a.Text, &a.Code, &a.Title); err != nil {
-			return nil, err
-		}
-
-		articles = append(articles, a)
-	}
-
-	return articles, nil
+	return scanArticles(rows)
 }
 
 // CountArticles counts the number of articles in the articles table.
@@ -515,7 +782,7 @@ func (r *sqlDescriptionRepository) CountArticles() (int, error) {
 func (r *sqlDescriptionRepository) IsDescriptionClassified(description string) (bool, error) {
 	var count int
 
-	err := r.db.QueryRow("SELECT COUNT(*) FROM descriptions WHERE description = ?", description).Scan(&count)
+	err := r.db.QueryRow("SELECT COUNT(*) FROM descriptions WHERE description_key = "+descriptionKey, description).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -523,10 +790,10 @@ func (r *sqlDescriptionRepository) IsDescriptionClassified(description string) (
 	return count > 0, nil
 }
 
-// AreMultiArticlePartsClassified checks if all comma-separated parts of a multi-article description
+// AreMultiArticlePartsClassified checks if all parts of a multi-article description
 // are already classified in the database. Returns true if all parts are classified, false if at least one part is not.
 func (r *sqlDescriptionRepository) AreMultiArticlePartsClassified(description string) (bool, error) {
-	if !strings.Contains(description, ",") {
+	if !utils.DefaultTokenizer.HasSeparator(description) {
 		// Not a multi-article description, check the whole thing
 		return r.IsDescriptionClassified(description)
 	}
@@ -553,7 +820,10 @@ func (r *sqlDescriptionRepository) GetDescriptionWithArticles(description string
 
 	var articleIDs, articleCodes any
 
-	err := r.db.QueryRow("SELECT description, article_ids, article_codes, updated_at FROM descriptions WHERE description = ?", description).Scan(&d.Description, &articleIDs, &articleCodes, &d.UpdatedAt)
+	err := r.db.QueryRow(
+		"SELECT description, article_ids, article_codes, updated_at, COALESCE(curator, ''), COALESCE(source, '') FROM descriptions WHERE description_key = "+descriptionKey,
+		description,
+	).Scan(&d.Description, &articleIDs, &articleCodes, &d.UpdatedAt, &d.Curator, &d.Source)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -633,53 +903,48 @@ func (r *sqlDescriptionRepository) GetReviewAssignments() ([]ReviewCode, error)
 
 	var reviewCodes []ReviewCode
 
-	codeMap := make(map[int]*ReviewCode)
+	// indexes, not pointers: the slices move as they grow
+	codeIndex := make(map[int]int)
+	articleIndex := make(map[string]int)
 
 	for rows.Next() {
-		var code int
-
-		var articleID string
-
-		var articleText string
-
-		var description sql.NullString
-
-		var offenseCount sql.NullInt64
+		var (
+			code         int
+			articleID    string
+			articleText  string
+			description  sql.NullString
+			offenseCount sql.NullInt64
+		)
 
 		if err := rows.Scan(&code, &articleID, &articleText, &description, &offenseCount); err != nil {
 			return nil, err
 		}
 
-		if _, ok := codeMap[code]; !ok {
+		ci, ok := codeIndex[code]
+		if !ok {
+			ci = len(reviewCodes)
+			codeIndex[code] = ci
 			reviewCodes = append(reviewCodes, ReviewCode{
 				Code:  code,
-				Roman: utils.ToRoman(code),
+				Roman: articles.ToRoman(code),
 			})
-			codeMap[code] = &reviewCodes[len(reviewCodes)-1]
 		}
 
-		currentCode := codeMap[code]
-
-		var currentArticle *ReviewArticle
-
-		for i := range currentCode.Articles {
-			if currentCode.Articles[i].ID == articleID {
-				currentArticle = &currentCode.Articles[i]
+		currentCode := &reviewCodes[ci]
 
-				break
-			}
-		}
-
-		if currentArticle == nil {
+		ai, ok := articleIndex[articleID]
+		if !ok {
+			ai = len(currentCode.Articles)
+			articleIndex[articleID] = ai
 			currentCode.Articles = append(currentCode.Articles, ReviewArticle{
 				ID:   articleID,
 				Text: articleText,
 			})
-			currentArticle = &currentCode.Articles[len(currentCode.Articles)-1]
 		}
 
 		// Only add description if it's not NULL
 		if description.Valid {
+			currentArticle := &currentCode.Articles[ai]
 			currentArticle.Descriptions = append(currentArticle.Descriptions, ReviewDescription{
 				Description:  description.String,
 				OffenseCount: int(offenseCount.Int64),
@@ -687,6 +952,17 @@ func (r *sqlDescriptionRepository) GetReviewAssignments() ([]ReviewCode, error)
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// SQL sorts the IDs as strings, "15.10" before "15.4"
+	for i := range reviewCodes {
+		slices.SortStableFunc(reviewCodes[i].Articles, func(a, b ReviewArticle) int {
+			return articles.CompareIDs(a.ID, b.ID)
+		})
+	}
+
 	return reviewCodes, nil
 }
 
@@ -52,6 +52,15 @@ func setupDescriptionDB(t *testing.T) (*sql.DB, DescriptionRepository) {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
+
+		CREATE TABLE deferred_locations (
+			db_id INTEGER NOT NULL,
+			location VARCHAR NOT NULL,
+			reason VARCHAR NOT NULL,
+			deferred_until TIMESTAMP NOT NULL,
+			deferred_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (db_id, location)
+		);
 	`)
 	require.NoError(t, err)
 
@@ -311,6 +320,41 @@ func TestGetReviewAssignments(t *testing.T) {
 	require.Empty(t, articleG3.Descriptions) // "MULTI ASSIGNMENT DESC" is filtered out
 }
 
+func TestArticlesNaturalOrder(t *testing.T) {
+	db, repo := setupDescriptionDB(t)
+	defer db.Close()
+
+	for _, id := range []string{"15.10", "15.4", "15.2.1"} {
+		require.NoError(t, repo.AddArticle(id, "Art "+id, 3, "Title 3"))
+	}
+
+	list, err := repo.ListArticles()
+	require.NoError(t, err)
+
+	var ids []string
+	for _, a := range list {
+		ids = append(ids, a.ID)
+	}
+
+	assert.Equal(t, []string{"15.2.1", "15.4", "15.10", "G.1", "G.2", "G.3", "G.4"}, ids)
+
+	reviewAssignments, err := repo.GetReviewAssignments()
+	require.NoError(t, err)
+
+	for _, code := range reviewAssignments {
+		if code.Code != 3 {
+			continue
+		}
+
+		ids = nil
+		for _, a := range code.Articles {
+			ids = append(ids, a.ID)
+		}
+
+		assert.Equal(t, []string{"15.2.1", "15.4", "15.10", "G.3"}, ids)
+	}
+}
+
 func TestDescriptionUpdatedAt(t *testing.T) {
 	_, repo := setupDescriptionDB(t)
 
@@ -340,3 +384,103 @@ func TestDescriptionUpdatedAt(t *testing.T) {
 	assert.False(t, updated.UpdatedAt.Before(updateStart))
 	assert.True(t, updated.UpdatedAt.After(saved.UpdatedAt))
 }
+
+func TestSaveCompositeClassification(t *testing.T) {
+	_, repo := setupDescriptionDB(t)
+
+	composite := "SIN CASCO, SIN LUCES, SIN LIBRETA"
+	parts := []DescriptionPart{
+		{Part: "SIN CASCO", ArticleIDs: []string{"G.1"}},
+		{Part: "SIN LUCES", ArticleIDs: []string{"G.2", "G.1"}},
+		{Part: "SIN LIBRETA", ArticleIDs: []string{"G.3"}},
+	}
+	require.NoError(t, repo.SaveCompositeClassification(composite, parts))
+
+	for _, p := range parts {
+		saved, err := repo.GetDescriptionWithArticles(p.Part)
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Equal(t, p.ArticleIDs, saved.ArticleIDs)
+	}
+
+	saved, err := repo.GetDescriptionWithArticles(composite)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, []string{"G.1", "G.2", "G.3"}, saved.ArticleIDs)
+	assert.ElementsMatch(t, []int8{1, 2, 3}, saved.ArticleCodes)
+
+	// an unknown article in any part leaves everything untouched
+	err = repo.SaveCompositeClassification("SIN CINTURON, SIN SEGURO", []DescriptionPart{
+		{Part: "SIN CINTURON", ArticleIDs: []string{"G.1"}},
+		{Part: "SIN SEGURO", ArticleIDs: []string{"X.9"}},
+	})
+	require.Error(t, err)
+
+	for _, d := range []string{"SIN CINTURON", "SIN SEGURO", "SIN CINTURON, SIN SEGURO"} {
+		saved, err := repo.GetDescriptionWithArticles(d)
+		require.NoError(t, err)
+		assert.Nil(t, saved, d)
+	}
+}
+
+func TestDescriptionKey(t *testing.T) {
+	db, repo := setupDescriptionDB(t)
+	defer db.Close()
+
+	require.NoError(t, repo.SaveDescriptionClassification("Exceso de Velocidad", []string{"G.1"}))
+
+	// the same description written differently replaces the classification
+	require.NoError(t, repo.SaveDescriptionClassification("EXCESO DE  VELOCIDAD ", []string{"G.2"}))
+
+	n, err := repo.CountDescriptionJudgments()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	saved, err := repo.GetDescriptionWithArticles("exceso de velocidád")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, "EXCESO DE  VELOCIDAD ", saved.Description)
+	assert.Equal(t, []string{"G.2"}, saved.ArticleIDs)
+
+	_, err = db.Exec("INSERT INTO offenses (description) VALUES ('EXCESO DE VELOCIDAD'), ('Otra')")
+	require.NoError(t, err)
+
+	unclassified, err := repo.GetUnclassifiedDescriptions(10)
+	require.NoError(t, err)
+	assert.Equal(t, []DescriptionQueueItem{{Description: "Otra", Count: 1}}, unclassified)
+}
+
+func TestMergeDuplicateDescriptions(t *testing.T) {
+	db, repo := setupDescriptionDB(t)
+	defer db.Close()
+
+	// saved before the descriptions had a key
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := db.Exec(`
+		INSERT INTO descriptions (description, article_ids, article_codes, updated_at) VALUES
+			('Exceso de Velocidad', ['G.1'], [1], ?),
+			('EXCESO DE VELOCIDAD ', ['G.2'], [2], ?),
+			('Otra', ['G.3'], [3], ?)
+	`, old, old.Add(time.Hour), old)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CreateSchema())
+
+	judgments, err := repo.GetAllDescriptionJudgmentsSorted()
+	require.NoError(t, err)
+	require.Len(t, judgments, 2)
+	assert.Equal(t, "EXCESO DE VELOCIDAD ", judgments[0].Description)
+	assert.Equal(t, []string{"G.2"}, judgments[0].ArticleIDs)
+
+	// and the files with duplicates keep the most recent one
+	require.NoError(t, repo.BulkInsertDescriptionJudgments([]*Description{
+		{Description: "otra", ArticleIDs: []string{"G.1"}, UpdatedAt: old.Add(2 * time.Hour)},
+		{Description: "OTRA", ArticleIDs: []string{"G.2"}, UpdatedAt: old.Add(time.Hour)},
+	}))
+
+	saved, err := repo.GetDescriptionWithArticles("Otra")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, "otra", saved.Description)
+	assert.Equal(t, []string{"G.1"}, saved.ArticleIDs)
+}
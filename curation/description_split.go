@@ -0,0 +1,70 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DescriptionPart is one of the comma separated infractions of a composite
+// description, with the articles it's classified under.
+type DescriptionPart struct {
+	Part       string   `json:"part"`
+	ArticleIDs []string `json:"article_ids"`
+}
+
+// ErrPartWithoutSuggestion is returned when a part of a composite description
+// has no suggestion to accept.
+var ErrPartWithoutSuggestion = errors.New("part without suggestion")
+
+// BestParts picks, for every part of the breakdown, the articles with the
+// highest score: the ones a curator would accept by default. Ties are kept, so
+// a part already classified under several articles keeps all of them.
+func BestParts(breakdown []SuggestionBreakdown) ([]DescriptionPart, error) {
+	parts := make([]DescriptionPart, 0, len(breakdown))
+
+	for _, bd := range breakdown {
+		if len(bd.Suggestions) == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrPartWithoutSuggestion, bd.Part)
+		}
+
+		best := bd.Suggestions[0].Score
+		for _, s := range bd.Suggestions[1:] {
+			best = max(best, s.Score)
+		}
+
+		p := DescriptionPart{Part: bd.Part}
+
+		for _, s := range bd.Suggestions {
+			if s.Score == best {
+				p.ArticleIDs = append(p.ArticleIDs, s.ArticleID)
+			}
+		}
+
+		parts = append(parts, p)
+	}
+
+	return parts, nil
+}
+
+// snapshotSplit returns the current classification of the composite
+// description and of each of its parts, before a split changes them.
+func snapshotSplit(repo DescriptionRepository, description string, parts []DescriptionPart) ([]AuditChange, error) {
+	changes, err := snapshotDescription(repo, description)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range parts {
+		c, err := snapshotDescription(repo, p.Part)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, c...)
+	}
+
+	return changes, nil
+}
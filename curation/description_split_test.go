@@ -0,0 +1,35 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBestParts(t *testing.T) {
+	parts, err := BestParts([]SuggestionBreakdown{
+		{Part: "SIN CASCO", Suggestions: []Suggestion{
+			{ArticleID: "G.2", Score: 0.6},
+			{ArticleID: "G.1", Score: 0.9},
+		}},
+		{Part: "SIN LUCES", Suggestions: []Suggestion{
+			{ArticleID: "G.3", Score: 1},
+			{ArticleID: "G.4", Score: 1},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []DescriptionPart{
+		{Part: "SIN CASCO", ArticleIDs: []string{"G.1"}},
+		{Part: "SIN LUCES", ArticleIDs: []string{"G.3", "G.4"}},
+	}, parts)
+
+	_, err = BestParts([]SuggestionBreakdown{
+		{Part: "SIN CASCO", Suggestions: []Suggestion{{ArticleID: "G.1", Score: 0.9}}},
+		{Part: "VARIOS"},
+	})
+	require.ErrorIs(t, err, ErrPartWithoutSuggestion)
+}
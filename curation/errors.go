@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // GeocodingError representa errores específicos de geocodificación.
@@ -15,6 +17,8 @@ type GeocodingError struct {
 	Type    ErrorType
 	Message string
 	Err     error
+	// RetryAfter es el tiempo que el proveedor pidió esperar, si lo indicó.
+	RetryAfter time.Duration
 }
 
 // ErrorType define tipos de errores de geocodificación.
@@ -126,3 +130,48 @@ func ClassifyHTTPError(statusCode int, _ string) *GeocodingError {
 		}
 	}
 }
+
+// ClassifyGoogleStatus clasifica el campo status de una respuesta de Google
+// Maps. Google responde HTTP 200 aún cuando se excede la cuota.
+func ClassifyGoogleStatus(status string) *GeocodingError {
+	switch status {
+	case "OVER_QUERY_LIMIT", "OVER_DAILY_LIMIT":
+		return &GeocodingError{
+			Type:    ErrorTypeQuotaExceeded,
+			Message: "cuota excedida (" + status + ")",
+		}
+	case "ZERO_RESULTS":
+		return &GeocodingError{
+			Type:    ErrorTypeNotFound,
+			Message: "ubicación no encontrada",
+		}
+	case "REQUEST_DENIED", "INVALID_REQUEST":
+		return &GeocodingError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: "request inválido (" + status + ")",
+		}
+	default:
+		return &GeocodingError{
+			Type:    ErrorTypeUnknown,
+			Message: "google maps status: " + status,
+		}
+	}
+}
+
+// ParseRetryAfter interpreta el header Retry-After, en segundos o como fecha
+// HTTP. Devuelve cero si no está o no se entiende.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
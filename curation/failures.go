@@ -0,0 +1,105 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// Actions on the failed extractions under review.
+const (
+	FailureRetry   = "retry"   // the next update extracts the document again
+	FailureDismiss = "dismiss" // the document is left out of the extraction
+)
+
+var (
+	ErrInvalidFailureAction = errors.New("invalid failure action")
+	ErrFailureNotFound      = errors.New("extraction failure not found")
+)
+
+// FailureRepository is the review queue of the documents whose extraction
+// failed and that `impo update` doesn't retry on its own: the parse failures
+// and the transient ones that ran out of attempts.
+type FailureRepository interface {
+	CreateSchema() error
+	ListExtractionFailures(status string, limit int) ([]impo.ExtractionFailure, error)
+	ResolveExtractionFailure(docSource, action string) error
+}
+
+type sqlFailureRepository struct {
+	db *sql.DB
+}
+
+// NewFailureRepository creates a new failure repository.
+func NewFailureRepository(db *sql.DB) FailureRepository {
+	return &sqlFailureRepository{db: db}
+}
+
+func (r *sqlFailureRepository) CreateSchema() error {
+	_, err := r.db.Exec(impo.FailuresSchema)
+
+	return err
+}
+
+func (r *sqlFailureRepository) ListExtractionFailures(status string, limit int) ([]impo.ExtractionFailure, error) {
+	rows, err := r.db.Query(`
+		SELECT db_id, doc_source, error, COALESCE(error_code, ''), kind, attempts, status, failed_at
+		FROM document_failures
+		WHERE status = ?
+		ORDER BY failed_at DESC, doc_source
+		LIMIT ?
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying extraction failures: %w", err)
+	}
+	defer rows.Close()
+
+	ret := []impo.ExtractionFailure{}
+
+	for rows.Next() {
+		var f impo.ExtractionFailure
+		if err := rows.Scan(
+			&f.DbID, &f.DocSource, &f.Error, &f.ErrorCode, &f.Kind, &f.Attempts, &f.Status, &f.FailedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning extraction failure: %w", err)
+		}
+
+		ret = append(ret, f)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlFailureRepository) ResolveExtractionFailure(docSource, action string) error {
+	var (
+		res sql.Result
+		err error
+	)
+
+	switch action {
+	case FailureRetry:
+		// without the failure the document is pending extraction again
+		res, err = r.db.Exec("DELETE FROM document_failures WHERE doc_source = ?", docSource)
+	case FailureDismiss:
+		res, err = r.db.Exec(
+			"UPDATE document_failures SET status = ? WHERE doc_source = ?", impo.FailureDismissed, docSource,
+		)
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFailureAction, action)
+	}
+
+	if err != nil {
+		return fmt.Errorf("resolving extraction failure: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrFailureNotFound, docSource)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractionFailures(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	repo := NewFailureRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO document_failures (doc_source, db_id, error, kind, attempts, status, failed_at) VALUES
+		('a.html', 45, 'parsing document: boom', 'parse', 1, 'pending', ?),
+		('b.html', 45, 'extraction timed out: after 1m', 'transient', 5, 'pending', ?),
+		('c.html', 47, 'extraction timed out: after 1m', 'transient', 2, 'retrying', ?)
+	`, now, now.Add(time.Minute), now)
+	require.NoError(t, err)
+
+	failures, err := repo.ListExtractionFailures(impo.FailurePending, 10)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "b.html", failures[0].DocSource)
+	assert.Equal(t, impo.FailureTransient, failures[0].Kind)
+	assert.Equal(t, 5, failures[0].Attempts)
+
+	require.ErrorIs(t, repo.ResolveExtractionFailure("a.html", "ignore"), ErrInvalidFailureAction)
+	require.ErrorIs(t, repo.ResolveExtractionFailure("z.html", FailureRetry), ErrFailureNotFound)
+
+	require.NoError(t, repo.ResolveExtractionFailure("a.html", FailureDismiss))
+	require.NoError(t, repo.ResolveExtractionFailure("b.html", FailureRetry))
+
+	failures, err = repo.ListExtractionFailures(impo.FailurePending, 10)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+
+	failures, err = repo.ListExtractionFailures(impo.FailureDismissed, 10)
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "a.html", failures[0].DocSource)
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM document_failures WHERE doc_source = 'b.html'").Scan(&n))
+	assert.Equal(t, 0, n)
+}
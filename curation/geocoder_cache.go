@@ -0,0 +1,167 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+)
+
+// DefaultGeocodeCacheTTL is how long a geocoding result is reused. The
+// Google Maps terms allow caching the coordinates for up to 30 days.
+const DefaultGeocodeCacheTTL = 30 * 24 * time.Hour
+
+// GeocodeCacheRepository stores the results of the geocoders, so asking again
+// for the same location doesn't bill the provider again. It lives apart from
+// the judgments: reloading the curation data keeps it.
+type GeocodeCacheRepository interface {
+	CreateSchema() error
+	// GetGeocode returns the cached result of the query, or nil if there's
+	// none or it expired.
+	GetGeocode(provider, query, department string, now time.Time) (*GeocodingResult, error)
+	// SaveGeocode caches the result of the query until now plus ttl.
+	SaveGeocode(provider, query, department string, result *GeocodingResult, now time.Time, ttl time.Duration) error
+	// PurgeExpiredGeocodes deletes the expired results, returning how many.
+	PurgeExpiredGeocodes(now time.Time) (int64, error)
+}
+
+type sqlGeocodeCacheRepository struct {
+	db *sql.DB
+}
+
+// NewGeocodeCacheRepository creates a new geocoding cache repository.
+func NewGeocodeCacheRepository(db *sql.DB) GeocodeCacheRepository {
+	return &sqlGeocodeCacheRepository{db: db}
+}
+
+func (r *sqlGeocodeCacheRepository) CreateSchema() error {
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS geocode_cache (
+			provider VARCHAR NOT NULL,
+			query VARCHAR NOT NULL, -- see geocodeCacheKey
+			department VARCHAR NOT NULL,
+			response JSON NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (provider, query, department)
+		);
+	`)
+
+	return err
+}
+
+// geocodeCacheKey normalizes a query so that the same location written with
+// other case, accents or spacing hits the same entry.
+func geocodeCacheKey(s string) string {
+	return strings.Join(strings.Fields(utils.LowerASCIIFolding(s)), " ")
+}
+
+func (r *sqlGeocodeCacheRepository) GetGeocode(provider, query, department string, now time.Time) (*GeocodingResult, error) {
+	var response string
+
+	err := r.db.QueryRow(`
+		SELECT CAST(response AS VARCHAR) FROM geocode_cache
+		WHERE provider = ? AND query = ? AND department = ? AND expires_at > ?
+	`, provider, geocodeCacheKey(query), geocodeCacheKey(department), now).Scan(&response)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("querying geocode cache: %w", err)
+	}
+
+	var result GeocodingResult
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("decoding cached geocode of %q: %w", query, err)
+	}
+
+	return &result, nil
+}
+
+func (r *sqlGeocodeCacheRepository) SaveGeocode(
+	provider, query, department string,
+	result *GeocodingResult,
+	now time.Time,
+	ttl time.Duration,
+) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encoding geocode of %q: %w", query, err)
+	}
+
+	if _, err := r.db.Exec(`
+		INSERT INTO geocode_cache (provider, query, department, response, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider, query, department) DO UPDATE SET
+			response = excluded.response,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+	`, provider, geocodeCacheKey(query), geocodeCacheKey(department), string(response), now, now.Add(ttl)); err != nil {
+		return fmt.Errorf("saving geocode of %q: %w", query, err)
+	}
+
+	return nil
+}
+
+func (r *sqlGeocodeCacheRepository) PurgeExpiredGeocodes(now time.Time) (int64, error) {
+	res, err := r.db.Exec("DELETE FROM geocode_cache WHERE expires_at <= ?", now)
+	if err != nil {
+		return 0, fmt.Errorf("purging geocode cache: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// CachingGeocoder wraps a Geocoder, answering the queries seen in the last
+// TTL from the cache. Only the results are cached: the failures are asked
+// again. A cache that fails is logged and bypassed.
+type CachingGeocoder struct {
+	next     Geocoder
+	cache    GeocodeCacheRepository
+	provider string
+
+	// TTL is how long a result is reused.
+	TTL time.Duration
+
+	now func() time.Time
+}
+
+// NewCachingGeocoder wraps the geocoder of the provider with the cache.
+func NewCachingGeocoder(next Geocoder, cache GeocodeCacheRepository, provider string) *CachingGeocoder {
+	return &CachingGeocoder{
+		next:     next,
+		cache:    cache,
+		provider: provider,
+		TTL:      DefaultGeocodeCacheTTL,
+		now:      time.Now,
+	}
+}
+
+func (g *CachingGeocoder) Geocode(location string, department string) (*GeocodingResult, error) {
+	now := g.now()
+
+	cached, err := g.cache.GetGeocode(g.provider, location, department, now)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+	} else if cached != nil {
+		return cached, nil
+	}
+
+	result, err := g.next.Geocode(location, department)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.cache.SaveGeocode(g.provider, location, department, result, now, g.TTL); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
+	return result, nil
+}
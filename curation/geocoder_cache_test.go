@@ -0,0 +1,64 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingGeocoder(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	cache := NewGeocodeCacheRepository(db)
+	require.NoError(t, cache.CreateSchema())
+
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	next := &fakeGeocoder{}
+	g := NewCachingGeocoder(next, cache, "fake")
+	g.now = func() time.Time { return now }
+
+	result, err := g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", result.Provider)
+	assert.Equal(t, 1, next.calls)
+
+	// the same query, written differently, is answered by the cache
+	result, err = g.Geocode("18 de Julio  y Ejído ", "MONTEVIDEO")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", result.Provider)
+	assert.Equal(t, 1, next.calls)
+
+	// another department is another query
+	_, err = g.Geocode("18 DE JULIO Y EJIDO", "Canelones")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+
+	// failures are not cached
+	next.errs = []error{errors.New("boom")}
+	_, err = g.Geocode("RUTA 5 KM 30", "Canelones")
+	require.Error(t, err)
+	_, err = g.Geocode("RUTA 5 KM 30", "Canelones")
+	require.NoError(t, err)
+	assert.Equal(t, 4, next.calls)
+
+	// expired entries are asked again and purged
+	now = now.Add(DefaultGeocodeCacheTTL)
+
+	purged, err := cache.PurgeExpiredGeocodes(now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, purged)
+
+	_, err = g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+	require.NoError(t, err)
+	assert.Equal(t, 5, next.calls)
+}
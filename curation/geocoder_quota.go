@@ -0,0 +1,116 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// QuotaAwareGeocoder wraps a Geocoder, retrying rate limit and quota errors
+// that ask for a short wait and, once the provider refuses to keep answering,
+// failing fast until the quota is expected to be back instead of hammering
+// the API on every suggestion.
+type QuotaAwareGeocoder struct {
+	next Geocoder
+
+	// MaxRetries is the number of retries of a rate limited request.
+	MaxRetries int
+	// MaxWait is the longest wait between retries. Longer Retry-After values
+	// block the geocoder instead.
+	MaxWait time.Duration
+	// Cooldown is how long the geocoder stays blocked after exhausting the
+	// retries when the provider gave no Retry-After.
+	Cooldown time.Duration
+
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// NewQuotaAwareGeocoder wraps the geocoder with the default retry policy.
+func NewQuotaAwareGeocoder(next Geocoder) *QuotaAwareGeocoder {
+	return &QuotaAwareGeocoder{
+		next:       next,
+		MaxRetries: 2,
+		MaxWait:    5 * time.Second,
+		Cooldown:   15 * time.Minute,
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// BlockedUntil returns when the geocoder will accept requests again, or the
+// zero time if it isn't blocked.
+func (g *QuotaAwareGeocoder) BlockedUntil() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.now().Before(g.blockedUntil) {
+		return g.blockedUntil
+	}
+
+	return time.Time{}
+}
+
+func (g *QuotaAwareGeocoder) Geocode(location string, department string) (*GeocodingResult, error) {
+	if until := g.BlockedUntil(); !until.IsZero() {
+		return nil, &GeocodingError{
+			Type:       ErrorTypeQuotaExceeded,
+			Message:    "cuota del geocodificador agotada",
+			RetryAfter: until.Sub(g.now()),
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err := g.next.Geocode(location, department)
+		if err == nil || !IsQuotaError(err) {
+			return result, err
+		}
+
+		wait := RetryAfter(err)
+		if wait == 0 {
+			wait = time.Second << attempt
+		}
+
+		if attempt < g.MaxRetries && wait <= g.MaxWait {
+			g.sleep(wait)
+
+			continue
+		}
+
+		wait = max(wait, g.Cooldown)
+
+		g.mu.Lock()
+		g.blockedUntil = g.now().Add(wait)
+		g.mu.Unlock()
+
+		return nil, &GeocodingError{
+			Type:       ErrorTypeQuotaExceeded,
+			Message:    "cuota del geocodificador agotada",
+			Err:        err,
+			RetryAfter: wait,
+		}
+	}
+}
+
+// IsQuotaError reports whether the provider refused the request because of
+// its rate limit or quota, i.e. the request may succeed later.
+func IsQuotaError(err error) bool {
+	return IsRateLimitError(err) || IsQuotaExceededError(err)
+}
+
+// RetryAfter returns how long the provider asked to wait before retrying, or
+// zero if it didn't say.
+func RetryAfter(err error) time.Duration {
+	var geoErr *GeocodingError
+	if errors.As(err, &geoErr) {
+		return geoErr.RetryAfter
+	}
+
+	return 0
+}
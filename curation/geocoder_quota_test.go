@@ -0,0 +1,107 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGeocoder struct {
+	calls     int
+	errs      []error
+	locations []string
+}
+
+func (g *fakeGeocoder) Geocode(location, _ string) (*GeocodingResult, error) {
+	g.calls++
+	g.locations = append(g.locations, location)
+	if len(g.errs) > 0 {
+		err := g.errs[0]
+		g.errs = g.errs[1:]
+
+		return nil, err
+	}
+
+	return &GeocodingResult{Provider: "fake"}, nil
+}
+
+func newTestQuotaGeocoder(next Geocoder, now *time.Time, slept *[]time.Duration) *QuotaAwareGeocoder {
+	g := NewQuotaAwareGeocoder(next)
+	g.now = func() time.Time { return *now }
+	g.sleep = func(d time.Duration) { *slept = append(*slept, d) }
+
+	return g
+}
+
+func TestQuotaAwareGeocoder(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("retries short waits", func(t *testing.T) {
+		var slept []time.Duration
+
+		next := &fakeGeocoder{errs: []error{
+			ClassifyGoogleStatus("OVER_QUERY_LIMIT"),
+			&GeocodingError{Type: ErrorTypeRateLimit, RetryAfter: 3 * time.Second},
+		}}
+		g := newTestQuotaGeocoder(next, &now, &slept)
+
+		result, err := g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+		require.NoError(t, err)
+		assert.Equal(t, "fake", result.Provider)
+		assert.Equal(t, []time.Duration{time.Second, 3 * time.Second}, slept)
+		assert.True(t, g.BlockedUntil().IsZero())
+	})
+
+	t.Run("blocks after a long retry-after", func(t *testing.T) {
+		var slept []time.Duration
+
+		next := &fakeGeocoder{errs: []error{
+			&GeocodingError{Type: ErrorTypeQuotaExceeded, RetryAfter: time.Hour},
+		}}
+		g := newTestQuotaGeocoder(next, &now, &slept)
+
+		_, err := g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+		require.Error(t, err)
+		assert.True(t, IsQuotaExceededError(err))
+		assert.Equal(t, time.Hour, RetryAfter(err))
+		assert.Empty(t, slept)
+		assert.Equal(t, now.Add(time.Hour), g.BlockedUntil())
+
+		// fails fast without calling the provider
+		_, err = g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+		require.Error(t, err)
+		assert.Equal(t, 1, next.calls)
+
+		later := now.Add(2 * time.Hour)
+		g.now = func() time.Time { return later }
+		_, err = g.Geocode("18 DE JULIO Y EJIDO", "Montevideo")
+		require.NoError(t, err)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		var slept []time.Duration
+
+		next := &fakeGeocoder{errs: []error{ClassifyGoogleStatus("ZERO_RESULTS")}}
+		g := newTestQuotaGeocoder(next, &now, &slept)
+
+		_, err := g.Geocode("NOWHERE", "")
+		require.Error(t, err)
+		assert.False(t, IsQuotaError(err))
+		assert.Equal(t, 1, next.calls)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, ParseRetryAfter("30", now))
+	assert.Equal(t, 2*time.Minute, ParseRetryAfter("Wed, 01 Oct 2025 12:02:00 GMT", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), ParseRetryAfter("soon", now))
+}
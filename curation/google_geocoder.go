@@ -8,19 +8,26 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
 )
 
 // GoogleMapsGeocoder uses Google Maps Geocoding API.
 type GoogleMapsGeocoder struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
 }
 
+// googleGeocodeURL is the endpoint of both the geocoding and the reverse
+// geocoding.
+const googleGeocodeURL = "https://maps.googleapis.com/maps/api/geocode/json"
+
 // NewGoogleMapsGeocoder creates a new Google Maps geocoder.
 func NewGoogleMapsGeocoder(apiKey string) *GoogleMapsGeocoder {
 	return &GoogleMapsGeocoder{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: googleGeocodeURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -56,7 +63,7 @@ func (g *GoogleMapsGeocoder) Geocode(location string, department string) (*Geoco
 	params.Set("key", g.apiKey)
 	params.Set("region", "uy") // Bias to Uruguay
 
-	reqURL := "https://maps.googleapis.com/maps/api/geocode/json?" + params.Encode()
+	reqURL := g.baseURL + "?" + params.Encode()
 
 	resp, err := g.httpClient.Get(reqURL)
 	if err != nil {
@@ -66,7 +73,11 @@ func (g *GoogleMapsGeocoder) Geocode(location string, department string) (*Geoco
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("google maps returned status %d", resp.StatusCode)
+		geoErr := ClassifyHTTPError(resp.StatusCode, "")
+		geoErr.Message = fmt.Sprintf("google maps returned status %d: %s", resp.StatusCode, geoErr.Message)
+		geoErr.RetryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+		return nil, geoErr
 	}
 
 	var gmResp googleMapsResponse
@@ -75,7 +86,7 @@ func (g *GoogleMapsGeocoder) Geocode(location string, department string) (*Geoco
 	}
 
 	if gmResp.Status != "OK" {
-		return nil, fmt.Errorf("google maps status: %s", gmResp.Status)
+		return nil, ClassifyGoogleStatus(gmResp.Status)
 	}
 
 	if len(gmResp.Results) == 0 {
@@ -107,3 +118,72 @@ func (g *GoogleMapsGeocoder) Geocode(location string, department string) (*Geoco
 		DisplayName: result.FormattedAddress,
 	}, nil
 }
+
+// reverseResults is how many of the results of a reverse geocoding are looked
+// at: the first ones are the closest to the point.
+const reverseResults = 5
+
+type googleReverseResponse struct {
+	Results []struct {
+		FormattedAddress  string `json:"formatted_address"`
+		AddressComponents []struct {
+			LongName  string   `json:"long_name"`
+			ShortName string   `json:"short_name"`
+			Types     []string `json:"types"`
+		} `json:"address_components"`
+	} `json:"results"`
+	Status string `json:"status"`
+}
+
+func (g *GoogleMapsGeocoder) ReverseGeocode(lat, lng float64) (*ReverseGeocodingResult, error) {
+	params := url.Values{}
+	params.Set("latlng", fmt.Sprintf("%f,%f", lat, lng))
+	params.Set("key", g.apiKey)
+	params.Set("language", "es")
+
+	resp, err := g.httpClient.Get(g.baseURL + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("reverse geocoding request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		geoErr := ClassifyHTTPError(resp.StatusCode, "")
+		geoErr.Message = fmt.Sprintf("google maps returned status %d: %s", resp.StatusCode, geoErr.Message)
+
+		return nil, geoErr
+	}
+
+	var gmResp googleReverseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gmResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	ret := &ReverseGeocodingResult{Streets: []string{}}
+
+	switch gmResp.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return ret, nil
+	default:
+		return nil, ClassifyGoogleStatus(gmResp.Status)
+	}
+
+	ret.Address = gmResp.Results[0].FormattedAddress
+
+	seen := make(map[string]bool)
+
+	for _, result := range gmResp.Results[:min(reverseResults, len(gmResp.Results))] {
+		for _, c := range result.AddressComponents {
+			if !slices.Contains(c.Types, "route") || seen[c.LongName] {
+				continue
+			}
+
+			seen[c.LongName] = true
+			ret.Streets = append(ret.Streets, c.LongName)
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// ErrInvalidProperty is returned when a header is mapped to a property that
+// doesn't exist.
+var ErrInvalidProperty = errors.New("invalid offense property")
+
+// PendingHeader is a table header the extraction ignored because no property
+// is known for it.
+type PendingHeader struct {
+	Header    string    `json:"header"`
+	DbIDs     []int     `json:"db_ids"`
+	Documents int       `json:"documents"`
+	DocSource string    `json:"doc_source"` // the last document it was seen in
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// HeaderRepository handles the headers learned by the extraction (see
+// impo.ClientOptions.LearnHeaders).
+type HeaderRepository interface {
+	CreateSchema() error
+	ListPendingHeaders() ([]PendingHeader, error)
+	// MapHeader adds the header to the synonyms of the property and flags
+	// the documents it was ignored in for re-extraction, returning them.
+	MapHeader(header, property string) ([]string, error)
+}
+
+type sqlHeaderRepository struct {
+	db *sql.DB
+}
+
+// NewHeaderRepository creates a new header repository.
+func NewHeaderRepository(db *sql.DB) HeaderRepository {
+	return &sqlHeaderRepository{db: db}
+}
+
+func (r *sqlHeaderRepository) CreateSchema() error {
+	_, err := r.db.Exec(impo.HeadersSchema)
+
+	return err
+}
+
+func (r *sqlHeaderRepository) ListPendingHeaders() ([]PendingHeader, error) {
+	rows, err := r.db.Query(`
+		SELECT header, list(DISTINCT db_id ORDER BY db_id), COUNT(*), arg_max(doc_source, seen_at), MAX(seen_at)
+		FROM pending_headers
+		GROUP BY header
+		ORDER BY COUNT(*) DESC, header
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending headers: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []PendingHeader
+
+	for rows.Next() {
+		var (
+			h      PendingHeader
+			idsVal any
+		)
+
+		if err := rows.Scan(&h.Header, &idsVal, &h.Documents, &h.DocSource, &h.LastSeen); err != nil {
+			return nil, fmt.Errorf("scanning pending header: %w", err)
+		}
+
+		ids, _ := idsVal.([]any)
+		for _, id := range ids {
+			if v, ok := id.(int32); ok {
+				h.DbIDs = append(h.DbIDs, int(v))
+			}
+		}
+
+		ret = append(ret, h)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlHeaderRepository) MapHeader(header, property string) ([]string, error) {
+	if _, err := impo.ParseOffenseProperty(property); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProperty, property)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("failed to rollback transaction mapping header %s: %v", header, err)
+		}
+	}()
+
+	if _, err := tx.Exec(`
+		INSERT INTO header_synonyms (header, property, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (header) DO UPDATE SET property = excluded.property, created_at = excluded.created_at
+	`, header, property, time.Now()); err != nil {
+		return nil, fmt.Errorf("saving synonym of %s: %w", header, err)
+	}
+
+	rows, err := tx.Query("SELECT doc_source FROM pending_headers WHERE header = ? ORDER BY doc_source", header)
+	if err != nil {
+		return nil, fmt.Errorf("querying documents of %s: %w", header, err)
+	}
+
+	var docs []string
+
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scanning document of %s: %w", header, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// `impo reextract` picks up the documents with rows of an older version;
+	// without a hash the rows are rewritten even if they come out the same
+	for _, doc := range docs {
+		if _, err := tx.Exec(
+			"UPDATE offenses SET extractor_version = 0, row_hash = NULL WHERE doc_source = ?", doc,
+		); err != nil {
+			return nil, fmt.Errorf("flagging %s for re-extraction: %w", doc, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM pending_headers WHERE header = ?", header); err != nil {
+		return nil, fmt.Errorf("clearing pending header %s: %w", header, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapHeader(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	repo := NewHeaderRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses (doc_source VARCHAR, record_id INTEGER, extractor_version INTEGER, row_hash BIGINT);
+		INSERT INTO offenses VALUES ('a.html', 1, 3, 1), ('a.html', 2, 3, 2), ('b.html', 1, 3, 3), ('c.html', 1, 3, 4);
+	`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO pending_headers (header, db_id, doc_source, seen_at) VALUES
+		('Matrícula', 45, 'a.html', ?),
+		('Matrícula', 47, 'b.html', ?),
+		('Observaciones', 45, 'c.html', ?)
+	`, now, now.Add(time.Minute), now)
+	require.NoError(t, err)
+
+	pending, err := repo.ListPendingHeaders()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "Matrícula", pending[0].Header)
+	assert.Equal(t, []int{45, 47}, pending[0].DbIDs)
+	assert.Equal(t, 2, pending[0].Documents)
+	assert.Equal(t, "b.html", pending[0].DocSource)
+
+	_, err = repo.MapHeader("Matrícula", "plate")
+	require.ErrorIs(t, err, ErrInvalidProperty)
+
+	docs, err := repo.MapHeader("Matrícula", "vehicle")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.html", "b.html"}, docs)
+
+	var flagged int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM offenses WHERE extractor_version = 0").Scan(&flagged))
+	assert.Equal(t, 3, flagged)
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM offenses WHERE row_hash IS NULL").Scan(&flagged))
+	assert.Equal(t, 3, flagged)
+
+	var property string
+	require.NoError(t, db.QueryRow("SELECT property FROM header_synonyms WHERE header = 'Matrícula'").Scan(&property))
+	assert.Equal(t, "vehicle", property)
+
+	pending, err = repo.ListPendingHeaders()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "Observaciones", pending[0].Header)
+}
+
+func TestMapHeaderReextract(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	offenses, err := impo.NewSQLOffenseRepository(db)
+	require.NoError(t, err)
+	require.NoError(t, offenses.CreateSchema())
+
+	repo := NewHeaderRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	now := time.Now().UTC()
+	doc := []*impo.TrafficOffense{{
+		DbID:     45,
+		Document: &impo.Document{DocSource: "a.html", DocID: "1/025", DocDate: now},
+		RecordID: 1,
+		Vehicle:  "AAA1111",
+		Time:     now,
+	}}
+	require.NoError(t, offenses.SaveTrafficOffenses(doc))
+
+	_, err = db.Exec("INSERT INTO pending_headers (header, db_id, doc_source, seen_at) VALUES ('Matrícula', 45, 'a.html', ?)", now)
+	require.NoError(t, err)
+
+	_, err = repo.MapHeader("Matrícula", "vehicle")
+	require.NoError(t, err)
+
+	stale, err := offenses.GetStaleDocuments(&impo.DbReference{ID: 45}, impo.ExtractorVersion)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.html"}, stale)
+
+	// the document comes out the same, but it is no longer stale
+	require.NoError(t, offenses.SaveTrafficOffenses(doc))
+
+	stale, err = offenses.GetStaleDocuments(&impo.DbReference{ID: 45}, impo.ExtractorVersion)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
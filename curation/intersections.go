@@ -0,0 +1,107 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// mirroredGroup are the judgments of an intersection of a database with its
+// streets in different orders.
+type mirroredGroup struct {
+	dbID      int
+	locations []string // the oldest first
+}
+
+// MergeMirroredIntersections merges the judgments of the same intersection
+// written with its streets in another order ("AV ITALIA Y AV BOLIVIA" and "AV
+// BOLIVIA Y AV ITALIA") into the oldest one, along with the judgments merged
+// into them. It returns the number of judgments merged.
+func (r *sqlJudgmentRepository) MergeMirroredIntersections() (int, error) {
+	rows, err := r.db.Query(`
+		SELECT db_id, location, COALESCE(canonical_location, '')
+		FROM locations
+		ORDER BY db_id, created_at, location
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("querying judgments: %w", err)
+	}
+
+	var (
+		groups []*mirroredGroup
+		byKey  = make(map[string]*mirroredGroup)
+		// canonical location -> judgments merged into it
+		mergedInto = make(map[string][]string)
+	)
+
+	for rows.Next() {
+		var (
+			dbID                int
+			location, canonical string
+		)
+
+		if err := rows.Scan(&dbID, &location, &canonical); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning judgment: %w", err)
+		}
+
+		if canonical != "" {
+			k := fmt.Sprintf("%d/%s", dbID, canonical)
+			mergedInto[k] = append(mergedInto[k], location)
+
+			continue
+		}
+
+		key, ok := impo.IntersectionKey(impo.CleanLocation(dbID, location))
+		if !ok {
+			continue
+		}
+
+		k := fmt.Sprintf("%d/%s", dbID, key)
+
+		g, ok := byKey[k]
+		if !ok {
+			g = &mirroredGroup{dbID: dbID}
+			byKey[k] = g
+			groups = append(groups, g)
+		}
+
+		g.locations = append(g.locations, location)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating judgments: %w", err)
+	}
+
+	var n int
+
+	for _, g := range groups {
+		if len(g.locations) < 2 {
+			continue
+		}
+
+		canonical, mirrored := g.locations[0], g.locations[1:]
+
+		// the merged ones follow, so none is left merged into another
+		// merged one
+		locations := slices.Clone(mirrored)
+		for _, l := range mirrored {
+			locations = append(locations, mergedInto[fmt.Sprintf("%d/%s", g.dbID, l)]...)
+		}
+
+		if _, err := r.MergeCluster(g.dbID, canonical, locations); err != nil {
+			return n, fmt.Errorf("merging the intersection %s: %w", canonical, err)
+		}
+
+		n += len(locations)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeLocationsMirroredIntersections(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, location := range []string{
+		"AV BOLIVIA Y AV ITALIA", "AV ITALIA Y AV BOLIVIA", "AV ITALIA ESQ AV BOLIVIA", "18 DE JULIO 1234",
+	} {
+		require.NoError(t, repo.SaveJudgment(&Location{
+			DbID:            1,
+			Location:        location,
+			Point:           &spatial.Point{Lat: float64(-34 - i), Lng: -56},
+			GeocodingMethod: "manual",
+			Confidence:      "high",
+			CreatedAt:       created.AddDate(0, 0, i),
+		}))
+	}
+
+	// merged into one of the mirrored ones
+	require.NoError(t, repo.SaveJudgment(&Location{
+		DbID: 1, Location: "ITALIA Y BOLIVIA", Point: &spatial.Point{Lat: -40, Lng: -56},
+		GeocodingMethod: "manual", Confidence: "high", CreatedAt: created,
+	}))
+	require.NoError(t, repo.MergeLocations(1, "ITALIA Y BOLIVIA", "AV ITALIA Y AV BOLIVIA"))
+
+	n, err := repo.MergeMirroredIntersections()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	dbID := 1
+	for location, want := range map[string]string{
+		"AV BOLIVIA Y AV ITALIA":   "",
+		"AV ITALIA Y AV BOLIVIA":   "AV BOLIVIA Y AV ITALIA",
+		"AV ITALIA ESQ AV BOLIVIA": "AV BOLIVIA Y AV ITALIA",
+		"ITALIA Y BOLIVIA":         "AV BOLIVIA Y AV ITALIA",
+		"18 DE JULIO 1234":         "",
+	} {
+		judgments, err := repo.ListJudgments(&dbID, &location, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, judgments, 1, location)
+		assert.Equal(t, want, judgments[0].CanonicalLocation, location)
+
+		if want != "" {
+			assert.InDelta(t, -34.0, judgments[0].Point.Lat, 1e-9, location)
+		}
+	}
+
+	// nothing left to merge
+	n, err = repo.MergeMirroredIntersections()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
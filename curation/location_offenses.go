@@ -0,0 +1,133 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// Pagination of the offenses of a location.
+const (
+	LocationOffensesPerPage    = 50
+	LocationOffensesMaxPerPage = 500
+)
+
+// LocationOffense is an offense recorded at a location.
+type LocationOffense struct {
+	DocID       string     `json:"doc_id"`
+	DocSource   string     `json:"doc_source"`
+	RecordID    int        `json:"record_id"`
+	Time        *time.Time `json:"time,omitempty"`
+	Vehicle     string     `json:"vehicle,omitempty"`
+	Description string     `json:"description,omitempty"`
+	ArticleIDs  []string   `json:"article_ids,omitempty"`
+	UR          impo.UR    `json:"ur"`
+	// Location is as written in the document, it may be one of the variants
+	// merged into the canonical location.
+	Location string `json:"location"`
+}
+
+// LocationTimeline is a page of the offenses of a location, oldest first.
+type LocationTimeline struct {
+	DbID     int               `json:"db_id"`
+	Location string            `json:"location"` // the canonical one
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PerPage  int               `json:"per_page"`
+	Offenses []LocationOffense `json:"offenses"`
+}
+
+// LocationOffenseRepository lists the offenses recorded at a location.
+type LocationOffenseRepository interface {
+	// LocationTimeline returns a page (starting at 1) of the offenses of the
+	// canonical location of the given one, including the ones of the
+	// variants merged into it.
+	LocationTimeline(dbID int, location string, page, perPage int) (*LocationTimeline, error)
+}
+
+type sqlLocationOffenseRepository struct {
+	db *sql.DB
+}
+
+// NewLocationOffenseRepository creates a new location offense repository.
+func NewLocationOffenseRepository(db *sql.DB) LocationOffenseRepository {
+	return &sqlLocationOffenseRepository{db: db}
+}
+
+func (r *sqlLocationOffenseRepository) LocationTimeline(dbID int, location string, page, perPage int) (*LocationTimeline, error) {
+	canonical := location
+
+	err := r.db.QueryRow(`
+		SELECT COALESCE(canonical_location, location)
+		FROM locations
+		WHERE db_id = ? AND location = ?
+	`, dbID, location).Scan(&canonical)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("resolving canonical location of %s: %w", location, err)
+	}
+
+	ret := &LocationTimeline{
+		DbID:     dbID,
+		Location: canonical,
+		Page:     page,
+		PerPage:  perPage,
+		Offenses: []LocationOffense{},
+	}
+
+	// the variants are only merged into offenses.location by the backfill,
+	// until then they are matched through the judgments
+	const where = `
+		WHERE db_id = ? AND (
+			location = ?
+			OR location IN (SELECT location FROM locations WHERE db_id = ? AND canonical_location = ?)
+		)
+	`
+	args := []any{dbID, canonical, dbID, canonical}
+
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM active_offenses"+where, args...).Scan(&ret.Total); err != nil {
+		return nil, fmt.Errorf("counting offenses of %s: %w", canonical, err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			COALESCE(doc_id, ''), doc_source, record_id, "time", COALESCE(vehicle, ''),
+			COALESCE(description, ''), article_ids, COALESCE(ur, 0), COALESCE(display_location, location)
+		FROM active_offenses`+where+`
+		ORDER BY "time" NULLS LAST, doc_source, record_id
+		LIMIT ? OFFSET ?
+	`, append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses of %s: %w", canonical, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			o          LocationOffense
+			t          sql.NullTime
+			articleIDs any
+		)
+
+		if err := rows.Scan(
+			&o.DocID, &o.DocSource, &o.RecordID, &t, &o.Vehicle, &o.Description, &articleIDs, &o.UR, &o.Location,
+		); err != nil {
+			return nil, fmt.Errorf("scanning offense of %s: %w", canonical, err)
+		}
+
+		if t.Valid {
+			o.Time = &t.Time
+		}
+
+		o.ArticleIDs, _ = utils.AnyToStringSlice(articleIDs)
+		ret.Offenses = append(ret.Offenses, o)
+	}
+
+	return ret, rows.Err()
+}
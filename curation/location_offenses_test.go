@@ -0,0 +1,97 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationOffensesAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE locations (db_id INTEGER, location VARCHAR, canonical_location VARCHAR);
+		CREATE TABLE active_offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_source VARCHAR, record_id INTEGER, "time" TIMESTAMPTZ,
+			vehicle VARCHAR, description VARCHAR, article_ids VARCHAR[], ur INTEGER,
+			location VARCHAR, display_location VARCHAR
+		);
+		INSERT INTO locations VALUES
+			(6, '18 DE JULIO Y EJIDO', '18 DE JULIO Y EJIDO'),
+			(6, '18 JULIO Y EJIDO', '18 DE JULIO Y EJIDO');
+		INSERT INTO active_offenses VALUES
+			-- backfilled variant
+			(6, '2/024', 'b', 1, '2024-02-01 10:00:00-03', 'SBA1234', 'EXCESO DE VELOCIDAD', ['18.3.3'], 5,
+				'18 DE JULIO Y EJIDO', '18 JULIO Y EJIDO'),
+			-- variant not backfilled yet
+			(6, '3/024', 'c', 1, '2024-03-01 10:00:00-03', 'SBB1234', NULL, NULL, NULL, '18 JULIO Y EJIDO', NULL),
+			(6, '1/024', 'a', 1, '2024-01-01 10:00:00-03', 'SBC1234', NULL, NULL, 3, '18 DE JULIO Y EJIDO', NULL),
+			(6, '1/024', 'a', 2, NULL, NULL, NULL, NULL, NULL, '18 DE JULIO Y EJIDO', NULL),
+			(6, '1/024', 'a', 3, '2024-01-01 11:00:00-03', NULL, NULL, NULL, NULL, 'RAMBLA Y EJIDO', NULL),
+			(45, '1/024', 'd', 1, '2024-01-01 11:00:00-03', NULL, NULL, NULL, NULL, '18 DE JULIO Y EJIDO', NULL);
+	`)
+	require.NoError(t, err)
+
+	server := &Server{timelineRepo: NewLocationOffenseRepository(db)}
+	router := gin.New()
+	router.GET("/api/locations/:db_id/*location", server.getLocationOffenses)
+
+	get := func(path string) (int, LocationTimeline) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		var timeline LocationTimeline
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+		}
+
+		return w.Code, timeline
+	}
+
+	// a variant resolves to the whole canonical location, oldest first
+	code, timeline := get("/api/locations/6/18%20JULIO%20Y%20EJIDO/offenses?per_page=3")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "18 DE JULIO Y EJIDO", timeline.Location)
+	assert.Equal(t, 4, timeline.Total)
+	require.Len(t, timeline.Offenses, 3)
+	assert.Equal(t, "SBC1234", timeline.Offenses[0].Vehicle)
+	assert.Equal(t, "18 JULIO Y EJIDO", timeline.Offenses[1].Location)
+	assert.Equal(t, []string{"18.3.3"}, timeline.Offenses[1].ArticleIDs)
+	assert.Equal(t, "c", timeline.Offenses[2].DocSource)
+
+	code, timeline = get("/api/locations/6/18%20DE%20JULIO%20Y%20EJIDO/offenses?page=2&per_page=3")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, timeline.Offenses, 1)
+	assert.Nil(t, timeline.Offenses[0].Time) // without time, last
+
+	// locations without a judgment are matched as they are
+	code, timeline = get("/api/locations/6/RAMBLA%20Y%20EJIDO/offenses")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, timeline.Total)
+
+	for path, want := range map[string]int{
+		"/api/locations/6/RAMBLA%20Y%20EJIDO":                       http.StatusNotFound,
+		"/api/locations/x/RAMBLA%20Y%20EJIDO/offenses":              http.StatusBadRequest,
+		"/api/locations/6/RAMBLA%20Y%20EJIDO/offenses?page=0":       http.StatusBadRequest,
+		"/api/locations/6/RAMBLA%20Y%20EJIDO/offenses?page=a":       http.StatusBadRequest,
+		"/api/locations/6/RAMBLA%20Y%20EJIDO/offenses?per_page=501": http.StatusBadRequest,
+	} {
+		code, _ := get(path)
+		assert.Equal(t, want, code, path)
+	}
+}
@@ -0,0 +1,474 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+// GeocodingMethodOSM is the geocoding method of the judgments seeded from an
+// OpenStreetMap extract.
+const GeocodingMethodOSM = "osm_intersection"
+
+// Uruguayan departments are the admin_level 4 boundaries in OSM.
+const osmDepartmentAdminLevel = "4"
+
+// IntersectionIndex maps the street intersections of an OpenStreetMap extract
+// to their coordinates, per department.
+type IntersectionIndex struct {
+	points map[string]spatial.Point // key: "department|street a|street b"
+}
+
+// Len returns the number of indexed intersections.
+func (idx *IntersectionIndex) Len() int {
+	return len(idx.points)
+}
+
+func intersectionKey(department, a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+
+	return utils.LowerASCIIFolding(department) + "|" + a + "|" + b
+}
+
+// Lookup returns the coordinates of a "CALLE A Y CALLE B" location.
+func (idx *IntersectionIndex) Lookup(department, location string) (spatial.Point, bool) {
+	a, b, ok := ParseIntersection(location)
+	if !ok {
+		return spatial.Point{}, false
+	}
+
+	p, ok := idx.points[intersectionKey(department, normalizeStreetName(a), normalizeStreetName(b))]
+
+	return p, ok
+}
+
+// ParseIntersection splits a location of the form "CALLE A Y CALLE B" into its
+// two streets, see impo.ParseIntersection.
+func ParseIntersection(location string) (string, string, bool) {
+	return impo.ParseIntersection(location)
+}
+
+// Words that the notifications and OSM use inconsistently ("AV 8 DE OCTUBRE"
+// vs "Avenida 8 de Octubre"), dropped before comparing street names.
+var streetNameNoise = map[string]bool{
+	"av": true, "avda": true, "avenida": true, "calle": true, "bv": true, "bvar": true,
+	"bulevar": true, "boulevard": true, "cno": true, "camino": true, "pje": true, "pasaje": true,
+	"gral": true, "general": true, "dr": true, "doctor": true, "ing": true, "ingeniero": true,
+	"pte": true, "presidente": true, "de": true, "del": true, "la": true, "los": true, "las": true,
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+func normalizeStreetName(name string) string {
+	words := strings.Fields(nonAlphanumeric.ReplaceAllString(utils.LowerASCIIFolding(name), " "))
+
+	kept := words[:0]
+	for _, w := range words {
+		if !streetNameNoise[w] {
+			kept = append(kept, w)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+type osmTag struct {
+	K string `xml:"k,attr"`
+	V string `xml:"v,attr"`
+}
+
+type osmNode struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type osmWay struct {
+	ID   int64 `xml:"id,attr"`
+	Refs []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []osmTag `xml:"tag"`
+}
+
+type osmRelation struct {
+	Members []struct {
+		Type string `xml:"type,attr"`
+		Ref  int64  `xml:"ref,attr"`
+		Role string `xml:"role,attr"`
+	} `xml:"member"`
+	Tags []osmTag `xml:"tag"`
+}
+
+func osmTagValue(tags []osmTag, key string) string {
+	for _, t := range tags {
+		if t.K == key {
+			return t.V
+		}
+	}
+
+	return ""
+}
+
+// scanOSM streams the elements of an OSM XML file (optionally gzipped) with the
+// given name ("node", "way" or "relation") to fn.
+func scanOSM(path, element string, fn func(d *xml.Decoder, start *xml.StartElement) error) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("opening osm extract: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+
+	if strings.HasSuffix(path, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("reading osm extract: %w", err)
+		}
+		defer gr.Close()
+
+		r = gr
+	}
+
+	d := xml.NewDecoder(r)
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("parsing osm extract: %w", err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == element {
+			if err := fn(d, &start); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type department struct {
+	name  string
+	rings [][]int64 // closed rings of node ids
+}
+
+// LoadOSMIntersections builds the intersection index from an OpenStreetMap XML
+// extract (.osm or .osm.gz) containing the named highways and the department
+// boundaries, e.g. produced with
+//
+//	osmium tags-filter uruguay-latest.osm.pbf w/highway r/boundary=administrative -o uruguay.osm
+//
+// The file is read three times (relations, ways and nodes) so that only the
+// coordinates that matter are kept in memory.
+func LoadOSMIntersections(path string) (*IntersectionIndex, error) {
+	var departments []*department
+
+	boundaryWays := make(map[int64][]*department)
+
+	err := scanOSM(path, "relation", func(d *xml.Decoder, start *xml.StartElement) error {
+		var rel osmRelation
+		if err := d.DecodeElement(&rel, start); err != nil {
+			return fmt.Errorf("decoding relation: %w", err)
+		}
+
+		if osmTagValue(rel.Tags, "boundary") != "administrative" ||
+			osmTagValue(rel.Tags, "admin_level") != osmDepartmentAdminLevel {
+			return nil
+		}
+
+		dept := &department{name: osmTagValue(rel.Tags, "name")}
+		departments = append(departments, dept)
+
+		for _, m := range rel.Members {
+			if m.Type == "way" {
+				boundaryWays[m.Ref] = append(boundaryWays[m.Ref], dept)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// node id -> street names crossing it
+	streets := make(map[int64]map[string]bool)
+	boundarySegments := make(map[*department][][]int64)
+	needed := make(map[int64]bool)
+
+	err = scanOSM(path, "way", func(d *xml.Decoder, start *xml.StartElement) error {
+		var way osmWay
+		if err := d.DecodeElement(&way, start); err != nil {
+			return fmt.Errorf("decoding way: %w", err)
+		}
+
+		refs := make([]int64, len(way.Refs))
+		for i, r := range way.Refs {
+			refs[i] = r.Ref
+		}
+
+		for _, dept := range boundaryWays[way.ID] {
+			boundarySegments[dept] = append(boundarySegments[dept], refs)
+
+			for _, ref := range refs {
+				needed[ref] = true
+			}
+		}
+
+		name := normalizeStreetName(osmTagValue(way.Tags, "name"))
+		if osmTagValue(way.Tags, "highway") == "" || name == "" {
+			return nil
+		}
+
+		for _, ref := range refs {
+			if streets[ref] == nil {
+				streets[ref] = make(map[string]bool)
+			}
+
+			streets[ref][name] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for ref, names := range streets {
+		if len(names) < 2 {
+			delete(streets, ref)
+		} else {
+			needed[ref] = true
+		}
+	}
+
+	for _, dept := range departments {
+		dept.rings = assembleRings(boundarySegments[dept])
+	}
+
+	coords := make(map[int64]spatial.Point, len(needed))
+
+	err = scanOSM(path, "node", func(d *xml.Decoder, start *xml.StartElement) error {
+		var node osmNode
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "id":
+				node.ID, _ = strconv.ParseInt(attr.Value, 10, 64)
+			case "lat":
+				node.Lat, _ = strconv.ParseFloat(attr.Value, 64)
+			case "lon":
+				node.Lon, _ = strconv.ParseFloat(attr.Value, 64)
+			}
+		}
+
+		if needed[node.ID] {
+			coords[node.ID] = spatial.Point{Lat: node.Lat, Lng: node.Lon}
+		}
+
+		return d.Skip()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// divided avenues cross the other street at several nodes, average them
+	type sum struct {
+		lat, lng float64
+		n        int
+	}
+
+	sums := make(map[string]*sum)
+
+	for ref, names := range streets {
+		p, ok := coords[ref]
+		if !ok {
+			continue
+		}
+
+		dept := departmentOf(departments, coords, p)
+
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+
+		sort.Strings(sorted)
+
+		for i := range sorted {
+			for j := i + 1; j < len(sorted); j++ {
+				key := intersectionKey(dept, sorted[i], sorted[j])
+				if sums[key] == nil {
+					sums[key] = &sum{}
+				}
+
+				sums[key].lat += p.Lat
+				sums[key].lng += p.Lng
+				sums[key].n++
+			}
+		}
+	}
+
+	idx := &IntersectionIndex{points: make(map[string]spatial.Point, len(sums))}
+	for key, s := range sums {
+		idx.points[key] = spatial.Point{Lat: s.lat / float64(s.n), Lng: s.lng / float64(s.n)}
+	}
+
+	return idx, nil
+}
+
+// assembleRings joins the boundary ways of a relation, which OSM doesn't keep
+// in order nor oriented, into closed rings.
+func assembleRings(segments [][]int64) [][]int64 {
+	var rings [][]int64
+
+	pending := make([][]int64, 0, len(segments))
+
+	for _, s := range segments {
+		if len(s) > 1 {
+			pending = append(pending, s)
+		}
+	}
+
+	for len(pending) > 0 {
+		ring := append([]int64(nil), pending[0]...)
+		pending = pending[1:]
+
+		for ring[0] != ring[len(ring)-1] {
+			found := false
+
+			for i, s := range pending {
+				last := ring[len(ring)-1]
+
+				switch {
+				case s[0] == last:
+					ring = append(ring, s[1:]...)
+				case s[len(s)-1] == last:
+					for k := len(s) - 2; k >= 0; k-- {
+						ring = append(ring, s[k])
+					}
+				default:
+					continue
+				}
+
+				pending = append(pending[:i], pending[i+1:]...)
+				found = true
+
+				break
+			}
+
+			if !found {
+				break // broken boundary, the extract was clipped
+			}
+		}
+
+		if ring[0] == ring[len(ring)-1] {
+			rings = append(rings, ring)
+		}
+	}
+
+	return rings
+}
+
+func departmentOf(departments []*department, coords map[int64]spatial.Point, p spatial.Point) string {
+	for _, dept := range departments {
+		inside := false
+
+		// even-odd rule over every ring, so inner rings are holes
+		for _, ring := range dept.rings {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				a, b := coords[ring[i]], coords[ring[j]]
+				if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+					p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+					inside = !inside
+				}
+			}
+		}
+
+		if inside {
+			return dept.name
+		}
+	}
+
+	return ""
+}
+
+// SeedOSMJudgments creates low confidence judgments for the pending locations
+// that name an intersection found in the index. The department of each
+// database is its name in dbMap. It returns the number of judgments created.
+func SeedOSMJudgments(db *sql.DB, repo LocationRepository, idx *IntersectionIndex, dbMap map[int]string) (int, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT o.db_id, o.location
+		FROM offenses o
+		LEFT JOIN locations lj ON o.db_id = lj.db_id AND o.location = lj.location
+		WHERE o.location IS NOT NULL AND o.location != '' AND lj.id IS NULL
+		ORDER BY o.db_id, o.location
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("querying pending locations: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+
+	var judgments []*Location
+
+	for rows.Next() {
+		var dbID int
+
+		var location string
+		if err := rows.Scan(&dbID, &location); err != nil {
+			return 0, fmt.Errorf("scanning pending location: %w", err)
+		}
+
+		p, ok := idx.Lookup(dbMap[dbID], impo.CleanLocation(dbID, location))
+		if !ok {
+			continue
+		}
+
+		judgments = append(judgments, &Location{
+			DbID:            dbID,
+			Location:        location,
+			Point:           &p,
+			GeocodingMethod: GeocodingMethodOSM,
+			Confidence:      "low",
+			Notes:           "OpenStreetMap intersection",
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating pending locations: %w", err)
+	}
+
+	if len(judgments) == 0 {
+		return 0, nil
+	}
+
+	if err := repo.BulkInsertJudgments(judgments); err != nil {
+		return 0, fmt.Errorf("inserting osm judgments: %w", err)
+	}
+
+	return len(judgments), nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// A square department crossed by two avenues. The boundary is split in two
+// ways, the second one reversed, as OSM often has them.
+const osmExtract = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="1" lat="-34.0" lon="-57.0"/>
+  <node id="2" lat="-34.0" lon="-56.0"/>
+  <node id="3" lat="-35.0" lon="-56.0"/>
+  <node id="4" lat="-35.0" lon="-57.0"/>
+  <node id="10" lat="-34.5" lon="-56.9"/>
+  <node id="11" lat="-34.5" lon="-56.5"/>
+  <node id="12" lat="-34.5" lon="-56.1"/>
+  <node id="20" lat="-34.1" lon="-56.5"/>
+  <node id="21" lat="-34.9" lon="-56.5"/>
+  <way id="100"><nd ref="1"/><nd ref="2"/><nd ref="3"/></way>
+  <way id="101"><nd ref="1"/><nd ref="4"/><nd ref="3"/></way>
+  <way id="200">
+    <nd ref="10"/><nd ref="11"/>
+    <tag k="highway" v="primary"/><tag k="name" v="Avenida 8 de Octubre"/>
+  </way>
+  <way id="201">
+    <nd ref="11"/><nd ref="12"/>
+    <tag k="highway" v="primary"/><tag k="name" v="Avenida 8 de Octubre"/>
+  </way>
+  <way id="202">
+    <nd ref="20"/><nd ref="11"/><nd ref="21"/>
+    <tag k="highway" v="secondary"/><tag k="name" v="Avenida Centenario"/>
+  </way>
+  <relation id="1000">
+    <member type="way" ref="100" role="outer"/>
+    <member type="way" ref="101" role="outer"/>
+    <tag k="boundary" v="administrative"/><tag k="admin_level" v="4"/><tag k="name" v="Montevideo"/>
+  </relation>
+</osm>
+`
+
+func writeOSMExtract(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "extract.osm")
+	if err := os.WriteFile(path, []byte(osmExtract), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestParseIntersection(t *testing.T) {
+	tests := []struct {
+		location string
+		a, b     string
+		ok       bool
+	}{
+		{"AV 8 DE OCTUBRE Y AV CENTENARIO", "AV 8 DE OCTUBRE", "AV CENTENARIO", true},
+		{"Rivera esq. Soca", "Rivera", "Soca", true},
+		{"RUTA 1 KM 23", "", "", false},
+		{"18 DE JULIO Y", "", "", false},
+	}
+
+	for _, tc := range tests {
+		a, b, ok := ParseIntersection(tc.location)
+		if a != tc.a || b != tc.b || ok != tc.ok {
+			t.Errorf("ParseIntersection(%q) = %q, %q, %v", tc.location, a, b, ok)
+		}
+	}
+}
+
+func TestLoadOSMIntersections(t *testing.T) {
+	idx, err := LoadOSMIntersections(writeOSMExtract(t))
+	if err != nil {
+		t.Fatalf("LoadOSMIntersections failed: %v", err)
+	}
+
+	if idx.Len() != 1 {
+		t.Errorf("expected 1 intersection, got %d", idx.Len())
+	}
+
+	p, ok := idx.Lookup("Montevideo", "AV CENTENARIO Y 8 DE OCTUBRE")
+	if !ok || p.Lat != -34.5 || p.Lng != -56.5 {
+		t.Errorf("unexpected lookup result %v, %v", p, ok)
+	}
+
+	if _, ok := idx.Lookup("Canelones", "AV 8 DE OCTUBRE Y AV CENTENARIO"); ok {
+		t.Error("the intersection is not in Canelones")
+	}
+}
+
+func TestSeedOSMJudgments(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE offenses (db_id INTEGER, location VARCHAR);
+		INSERT INTO offenses VALUES
+			(6, 'AV 8 DE OCTUBRE Y AV CENTENARIO'),
+			(6, 'AV 8 DE OCTUBRE Y AV CENTENARIO'),
+			(6, 'AV ITALIA Y AV CENTENARIO'),
+			(40, 'AV 8 DE OCTUBRE Y AV CENTENARIO');
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := LoadOSMIntersections(writeOSMExtract(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := SeedOSMJudgments(db, repo, idx, map[int]string{6: "Montevideo", 40: "Canelones"})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 judgment, got %d, %v", n, err)
+	}
+
+	judgments, err := repo.GetAllJudgmentsSorted()
+	if err != nil || len(judgments) != 1 {
+		t.Fatalf("unexpected judgments %+v, %v", judgments, err)
+	}
+
+	if j := judgments[0]; j.GeocodingMethod != GeocodingMethodOSM || j.Confidence != "low" || j.Point.Lat != -34.5 {
+		t.Errorf("unexpected judgment %+v", j)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+// localidades are the populated places of the INE census (department capitals
+// and the main towns), to give a name to the curated points.
+//
+//go:embed localidades.json
+var localidades []byte
+
+// Place is a populated place of the gazetteer.
+type Place struct {
+	Name       string  `json:"name"`
+	Department string  `json:"department"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+}
+
+// Gazetteer finds the populated place nearest to a point.
+type Gazetteer struct {
+	places []Place
+}
+
+// NewGazetteer reads the places from a JSON file with the format of
+// localidades.json.
+func NewGazetteer(r io.Reader) (*Gazetteer, error) {
+	var places []Place
+	if err := json.NewDecoder(r).Decode(&places); err != nil {
+		return nil, fmt.Errorf("decoding gazetteer: %w", err)
+	}
+
+	return &Gazetteer{places: places}, nil
+}
+
+// defaultGazetteer is built from the bundled localidades.json.
+var defaultGazetteer = func() *Gazetteer {
+	g, err := NewGazetteer(bytes.NewReader(localidades))
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}()
+
+// Nearest returns the place nearest to p and its distance in meters. It
+// returns false when the gazetteer is empty.
+func (g *Gazetteer) Nearest(p spatial.Point) (Place, float64, bool) {
+	var (
+		best     Place
+		bestDist = math.Inf(1)
+	)
+
+	for _, place := range g.places {
+		if d := p.HaversineDistance(&spatial.Point{Lat: place.Lat, Lng: place.Lng}); d < bestDist {
+			best, bestDist = place, d
+		}
+	}
+
+	return best, bestDist, len(g.places) > 0
+}
+
+// computeNearestPlace names the point of the judgment after the nearest
+// populated place, like computeH3 it is derived from the point.
+func (judgment *Location) computeNearestPlace() {
+	judgment.NearestPlace, judgment.NearestPlaceM = "", 0
+
+	if judgment.Point == nil {
+		return
+	}
+
+	if place, d, ok := defaultGazetteer.Nearest(*judgment.Point); ok {
+		judgment.NearestPlace, judgment.NearestPlaceM = place.Name, math.Round(d)
+	}
+}
+
+// BackfillNearestPlaces names the judgments saved before the nearest place
+// was computed, returning how many were updated.
+func (r *sqlJudgmentRepository) BackfillNearestPlaces() (int64, error) {
+	rows, err := r.db.Query(`
+		SELECT db_id, location, point.y, point.x
+		FROM locations
+		WHERE nearest_place IS NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("querying judgments without nearest place: %w", err)
+	}
+
+	var judgments []*Location
+
+	for rows.Next() {
+		j := &Location{Point: &spatial.Point{}}
+		if err := rows.Scan(&j.DbID, &j.Location, &j.Point.Lat, &j.Point.Lng); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning judgment: %w", err)
+		}
+
+		j.computeNearestPlace()
+		judgments = append(judgments, j)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, j := range judgments {
+		if _, err := r.db.Exec(
+			"UPDATE locations SET nearest_place = ?, nearest_place_m = ? WHERE db_id = ? AND location = ?",
+			j.NearestPlace, j.NearestPlaceM, j.DbID, j.Location,
+		); err != nil {
+			return 0, fmt.Errorf("updating nearest place of %s: %w", j.Location, err)
+		}
+	}
+
+	return int64(len(judgments)), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGazetteerNearest(t *testing.T) {
+	g, err := NewGazetteer(strings.NewReader(`[
+		{"name": "Maldonado", "department": "Maldonado", "lat": -34.9000, "lng": -54.9500},
+		{"name": "Punta del Este", "department": "Maldonado", "lat": -34.9625, "lng": -54.9450}
+	]`))
+	require.NoError(t, err)
+
+	place, d, ok := g.Nearest(spatial.Point{Lat: -34.9550, Lng: -54.9400})
+	require.True(t, ok)
+	assert.Equal(t, "Punta del Este", place.Name)
+	assert.InDelta(t, 950, d, 50)
+
+	empty, err := NewGazetteer(strings.NewReader(`[]`))
+	require.NoError(t, err)
+
+	_, _, ok = empty.Nearest(spatial.Point{})
+	assert.False(t, ok)
+}
+
+func TestComputeNearestPlace(t *testing.T) {
+	// Ruta Interbalnearia y Milton Lussich
+	j := &Location{Point: &spatial.Point{Lat: -34.8831, Lng: -55.0446}}
+	j.computeNearestPlace()
+	assert.Equal(t, "Punta Ballena", j.NearestPlace)
+	assert.Positive(t, j.NearestPlaceM)
+
+	j.Point = nil
+	j.computeNearestPlace()
+	assert.Empty(t, j.NearestPlace)
+	assert.Zero(t, j.NearestPlaceM)
+}
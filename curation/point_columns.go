@@ -0,0 +1,211 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// pointColumns are columns derived from the point of a row, like the H3 cells
+// or the distances to the nearest school zone and radar.
+type pointColumns struct {
+	names   []string
+	sqlType string // of the columns, as in ALTER TABLE
+	typ     duckdb.Type
+	// compute returns the values of the columns for a point, nil for NULL.
+	compute func(lat, lng float64) ([]driver.Value, error)
+}
+
+// tableExists reports whether table is a table of the database.
+func tableExists(ctx context.Context, conn *sql.Conn, table string) (bool, error) {
+	var exists bool
+	if err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) > 0 FROM duckdb_tables() WHERE table_name = ? AND NOT temporary", table,
+	).Scan(&exists); err != nil {
+		return false, fmt.Errorf("looking up table %s: %w", table, err)
+	}
+
+	return exists, nil
+}
+
+// addColumns adds the columns table lacks, returning them as table.column.
+func addColumns(ctx context.Context, conn *sql.Conn, table string, cols pointColumns) ([]string, error) {
+	var added []string
+
+	for _, column := range cols.names {
+		var exists bool
+		if err := conn.QueryRowContext(ctx,
+			"SELECT COUNT(*) > 0 FROM duckdb_columns() WHERE table_name = ? AND column_name = ?", table, column,
+		).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("looking up %s.%s: %w", table, column, err)
+		}
+
+		if exists {
+			continue
+		}
+
+		// #nosec G201 - the table and the columns are constants or built from integers
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, cols.sqlType)); err != nil {
+			return nil, fmt.Errorf("adding %s.%s: %w", table, column, err)
+		}
+
+		added = append(added, table+"."+column)
+	}
+
+	// the view is bound to the columns offenses had when it was created
+	if len(added) > 0 && table == "offenses" {
+		if _, err := conn.ExecContext(ctx, impo.ActiveOffensesView); err != nil {
+			return nil, fmt.Errorf("recreating active_offenses: %w", err)
+		}
+	}
+
+	return added, nil
+}
+
+// updatePoints recomputes the columns of the rows of table with a point,
+// chunk by chunk in rowid order, returning how many rows were updated.
+// progress, when set, is called after each chunk.
+func updatePoints(
+	ctx context.Context, conn *sql.Conn, table string, cols pointColumns, chunkSize int,
+	progress func(done, total int64),
+) (int64, error) {
+	var total int64
+
+	// #nosec G201 - the table is a constant
+	if err := conn.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE point IS NOT NULL", table),
+	).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting points of %s: %w", table, err)
+	}
+
+	var (
+		done  int64
+		after int64 = -1
+	)
+
+	for {
+		chunk, last, err := readChunk(ctx, conn, table, cols, after, chunkSize)
+		if err != nil {
+			return done, err
+		}
+
+		if len(chunk) == 0 {
+			return done, nil
+		}
+
+		if err := writeChunk(conn, table, cols, chunk); err != nil {
+			return done, err
+		}
+
+		done += int64(len(chunk))
+		after = last
+
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+}
+
+// readChunk reads the next chunk of points of table after the rowid and
+// returns the rows to append in writeChunk, the rowid followed by the
+// computed columns, and the last rowid read.
+func readChunk(
+	ctx context.Context, conn *sql.Conn, table string, cols pointColumns, after int64, chunkSize int,
+) ([][]driver.Value, int64, error) {
+	// #nosec G201 - the table is a constant
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`
+		SELECT rowid, point.y, point.x
+		FROM %s
+		WHERE point IS NOT NULL AND rowid > ?
+		ORDER BY rowid
+		LIMIT ?
+	`, table), after, chunkSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying points of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var chunk [][]driver.Value
+
+	for rows.Next() {
+		var (
+			rowid    int64
+			lat, lng float64
+		)
+
+		if err := rows.Scan(&rowid, &lat, &lng); err != nil {
+			return nil, 0, fmt.Errorf("scanning point of %s: %w", table, err)
+		}
+
+		values, err := cols.compute(lat, lng)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		chunk = append(chunk, append([]driver.Value{rowid}, values...))
+		after = rowid
+	}
+
+	return chunk, after, rows.Err()
+}
+
+// writeChunk updates the columns of a chunk with an appender, joining the
+// appended rows on the rowid.
+func writeChunk(conn *sql.Conn, table string, cols pointColumns, chunk [][]driver.Value) error {
+	rowidType, err := duckdb.NewTypeInfo(duckdb.TYPE_BIGINT)
+	if err != nil {
+		return fmt.Errorf("creating rowid type: %w", err)
+	}
+
+	colType, err := duckdb.NewTypeInfo(cols.typ)
+	if err != nil {
+		return fmt.Errorf("creating column type: %w", err)
+	}
+
+	types := []duckdb.TypeInfo{rowidType}
+	sets := make([]string, 0, len(cols.names))
+
+	for _, column := range cols.names {
+		types = append(types, colType)
+		sets = append(sets, fmt.Sprintf("%s = appended_data.col%d", column, len(types)))
+	}
+
+	return conn.Raw(func(driverConn any) error {
+		dc, ok := driverConn.(driver.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		// #nosec G201 - the table and the columns are constants or built from integers
+		appender, err := duckdb.NewQueryAppender(dc, fmt.Sprintf(`
+			UPDATE %s SET %s
+			FROM appended_data
+			WHERE %s.rowid = appended_data.col1
+		`, table, strings.Join(sets, ", "), table), "", types, nil)
+		if err != nil {
+			return fmt.Errorf("creating appender for %s: %w", table, err)
+		}
+
+		for _, row := range chunk {
+			if err := appender.AppendRow(row...); err != nil {
+				appender.Close()
+
+				return fmt.Errorf("appending %s of %s: %w", strings.Join(cols.names, ", "), table, err)
+			}
+		}
+
+		if err := appender.Close(); err != nil {
+			return fmt.Errorf("updating %s of %s: %w", strings.Join(cols.names, ", "), table, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewDocumentAPI_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{}
+	server.SetDocumentsPath(t.TempDir())
+
+	router := gin.New()
+	router.GET("/api/documents/:id/preview", server.previewDocument)
+
+	for path, want := range map[string]int{
+		"/api/documents/3907-2025/preview":           http.StatusNotFound,
+		"/api/documents/3907-2025/preview?db_id=6":   http.StatusNotFound,
+		"/api/documents/3907-2025/preview?db_id=mvd": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, path)
+	}
+}
+
+func TestDocumentStatsAPI_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{}
+	server.SetDocumentsPath(t.TempDir())
+
+	router := gin.New()
+	router.GET("/api/documents/stats", server.getDocumentStats)
+	router.GET("/api/documents/:id/preview", server.previewDocument)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/documents/stats?db_id=atlantida", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+// Layers bundled for the proximity enrichment: the fixed radars of the
+// national routes and the school zones.
+var (
+	//go:embed radares.json
+	radaresLayer []byte
+	//go:embed zonas_escolares.json
+	schoolZonesLayer []byte
+)
+
+// PointLayer is a GeoJSON layer reduced to points, to measure the distance to
+// the nearest of them. Polygons are reduced to the center of their outer ring.
+type PointLayer struct {
+	Name   string
+	points []spatial.Point
+}
+
+// LoadPointLayer reads a GeoJSON FeatureCollection with Point, MultiPoint and
+// Polygon features. Other geometries are skipped.
+func LoadPointLayer(name string, r io.Reader) (*PointLayer, error) {
+	var geoJSON struct {
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&geoJSON); err != nil {
+		return nil, fmt.Errorf("decoding %s layer: %w", name, err)
+	}
+
+	layer := &PointLayer{Name: name}
+
+	for i, feature := range geoJSON.Features {
+		var (
+			positions [][]float64
+			err       error
+		)
+
+		switch feature.Geometry.Type {
+		case "Point":
+			var position []float64
+
+			err = json.Unmarshal(feature.Geometry.Coordinates, &position)
+			positions = [][]float64{position}
+		case "MultiPoint":
+			err = json.Unmarshal(feature.Geometry.Coordinates, &positions)
+		case "Polygon":
+			var rings [][][]float64
+			if err = json.Unmarshal(feature.Geometry.Coordinates, &rings); err == nil && len(rings) > 0 {
+				positions = [][]float64{ringCenter(rings[0])}
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("decoding feature %d of %s layer: %w", i, name, err)
+		}
+
+		for _, position := range positions {
+			if len(position) < 2 {
+				return nil, fmt.Errorf("feature %d of %s layer has a position without coordinates", i, name)
+			}
+
+			layer.points = append(layer.points, spatial.Point{Lng: position[0], Lat: position[1]})
+		}
+	}
+
+	return layer, nil
+}
+
+// ringCenter is the mean of the vertices of a ring, good enough for the small
+// polygons of the school zones. The closing vertex repeats the first one.
+func ringCenter(ring [][]float64) []float64 {
+	if len(ring) > 1 {
+		ring = ring[:len(ring)-1]
+	}
+
+	var lng, lat float64
+
+	for _, position := range ring {
+		if len(position) < 2 {
+			return nil
+		}
+
+		lng += position[0]
+		lat += position[1]
+	}
+
+	n := float64(len(ring))
+
+	return []float64{lng / n, lat / n}
+}
+
+// Len returns the number of points of the layer.
+func (l *PointLayer) Len() int {
+	return len(l.points)
+}
+
+// Nearest returns the distance in meters from p to the nearest point of the
+// layer. It returns false when the layer is empty.
+func (l *PointLayer) Nearest(p spatial.Point) (float64, bool) {
+	best := math.Inf(1)
+
+	for i := range l.points {
+		if d := p.HaversineDistance(&l.points[i]); d < best {
+			best = d
+		}
+	}
+
+	return best, len(l.points) > 0
+}
+
+// DefaultRadarLayer is built from the bundled radares.json.
+func DefaultRadarLayer() (*PointLayer, error) {
+	return LoadPointLayer("radares", bytes.NewReader(radaresLayer))
+}
+
+// DefaultSchoolZoneLayer is built from the bundled zonas_escolares.json, which
+// has no zones yet: until it is filled the distances to the school zones need
+// a layer of their own.
+func DefaultSchoolZoneLayer() (*PointLayer, error) {
+	return LoadPointLayer("zonas_escolares", bytes.NewReader(schoolZonesLayer))
+}
+
+// ProximityOptions tunes EnrichProximity.
+type ProximityOptions struct {
+	SchoolZones *PointLayer
+	Radars      *PointLayer
+	// ChunkSize is the number of rows updated at once, DefaultReindexChunkSize
+	// when zero.
+	ChunkSize int
+	// Progress, when set, is called after each chunk with the offenses done
+	// so far.
+	Progress func(done, total int64)
+}
+
+// EnrichProximity stores in nearest_school_m and nearest_radar_m of the
+// geocoded offenses the distance in meters to the nearest school zone and
+// fixed radar, adding the columns when they are missing. An empty layer leaves
+// its column NULL. It returns the number of offenses updated.
+func EnrichProximity(db *sql.DB, opts ProximityOptions) (int64, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultReindexChunkSize
+	}
+
+	layers := []*PointLayer{opts.SchoolZones, opts.Radars}
+
+	// the offenses of a location share its point
+	distances := make(map[spatial.Point][]driver.Value)
+
+	cols := pointColumns{
+		names:   []string{"nearest_school_m", "nearest_radar_m"},
+		sqlType: "DOUBLE",
+		typ:     duckdb.TYPE_DOUBLE,
+		compute: func(lat, lng float64) ([]driver.Value, error) {
+			p := spatial.Point{Lat: lat, Lng: lng}
+			if values, ok := distances[p]; ok {
+				return values, nil
+			}
+
+			values := make([]driver.Value, len(layers))
+
+			for i, layer := range layers {
+				if layer == nil {
+					continue
+				}
+
+				if d, ok := layer.Nearest(p); ok {
+					values[i] = math.Round(d)
+				}
+			}
+
+			distances[p] = values
+
+			return values, nil
+		},
+	}
+
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := addColumns(ctx, conn, "offenses", cols); err != nil {
+		return 0, err
+	}
+
+	return updatePoints(ctx, conn, "offenses", cols, opts.ChunkSize, opts.Progress)
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPointLayer(t *testing.T) {
+	layer, err := LoadPointLayer("test", strings.NewReader(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-56.1851, -34.9055]}},
+			{"type": "Feature", "geometry": {"type": "MultiPoint", "coordinates": [[-56, -34], [-55, -33]]}},
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 2], [0, 0]]]}},
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}}
+		]
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []spatial.Point{
+		{Lat: -34.9055, Lng: -56.1851},
+		{Lat: -34, Lng: -56},
+		{Lat: -33, Lng: -55},
+		{Lat: 1, Lng: 1},
+	}, layer.points)
+
+	d, ok := layer.Nearest(spatial.Point{Lat: -34.9055, Lng: -56.1851})
+	assert.True(t, ok)
+	assert.InDelta(t, 0, d, 1e-6)
+
+	_, ok = (&PointLayer{}).Nearest(spatial.Point{})
+	assert.False(t, ok, "an empty layer has no nearest point")
+
+	_, err = LoadPointLayer("test", strings.NewReader(`{"features": [{"geometry": {"type": "Point", "coordinates": [1]}}]}`))
+	assert.Error(t, err)
+}
+
+func TestDefaultLayers(t *testing.T) {
+	radars, err := DefaultRadarLayer()
+	require.NoError(t, err)
+	assert.Positive(t, radars.Len())
+
+	_, err = DefaultSchoolZoneLayer()
+	require.NoError(t, err)
+}
+
+func TestEnrichProximity(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TYPE POINT_2D AS STRUCT(x DOUBLE, y DOUBLE);
+		CREATE TABLE offenses (id INTEGER, point POINT_2D, superseded_by VARCHAR);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO offenses VALUES
+			(1, {'x': -56.1851, 'y': -34.9055}, NULL),
+			(2, {'x': -56.1851, 'y': -34.9055}, NULL),
+			(3, {'x': -56.1590, 'y': -34.9209}, NULL),
+			(4, NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	school := spatial.Point{Lat: -34.9060, Lng: -56.1851}
+	radars, err := LoadPointLayer("radares", strings.NewReader(`{"features": []}`))
+	require.NoError(t, err)
+
+	n, err := EnrichProximity(db, ProximityOptions{
+		SchoolZones: &PointLayer{Name: "zonas_escolares", points: []spatial.Point{school}},
+		Radars:      radars,
+		ChunkSize:   2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	rows, err := db.Query("SELECT id, nearest_school_m, nearest_radar_m FROM active_offenses ORDER BY id")
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	got := map[int]sql.NullFloat64{}
+
+	for rows.Next() {
+		var (
+			id             int
+			school, radars sql.NullFloat64
+		)
+
+		require.NoError(t, rows.Scan(&id, &school, &radars))
+		assert.False(t, radars.Valid, "the radar layer is empty")
+
+		got[id] = school
+	}
+
+	require.NoError(t, rows.Err())
+
+	want := math.Round(school.HaversineDistance(&spatial.Point{Lat: -34.9055, Lng: -56.1851}))
+	assert.Equal(t, sql.NullFloat64{Float64: want, Valid: true}, got[1])
+	assert.Equal(t, got[1], got[2])
+	assert.Greater(t, got[3].Float64, got[1].Float64)
+	assert.False(t, got[4].Valid, "offenses without a point have no distance")
+}
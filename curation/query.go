@@ -0,0 +1,284 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Limits of the queries run from the console.
+const (
+	QueryTimeout = 10 * time.Second
+	QueryMaxRows = 1000
+)
+
+// ErrStatementNotAllowed is returned for the statements the query console
+// refuses to run.
+var ErrStatementNotAllowed = errors.New("statement not allowed")
+
+// queryStatements are the statements the console accepts.
+var queryStatements = map[string]bool{
+	"SELECT":    true,
+	"WITH":      true,
+	"FROM":      true, // DuckDB's FROM-first syntax
+	"DESCRIBE":  true,
+	"SHOW":      true,
+	"SUMMARIZE": true,
+	"VALUES":    true,
+}
+
+// queryForbidden are keywords rejected anywhere in the query, in case one
+// sneaks in a subquery.
+var queryForbidden = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true,
+	"ATTACH": true, "DETACH": true, "COPY": true, "EXPORT": true, "IMPORT": true,
+	"INSTALL": true, "LOAD": true, "PRAGMA": true, "SET": true, "RESET": true,
+	"CALL": true, "CHECKPOINT": true, "VACUUM": true,
+}
+
+// queryTableFunctions are the table functions the console accepts: the
+// others, like read_text, read_csv or glob, read files or URLs of the host.
+var queryTableFunctions = map[string]bool{
+	"range": true, "generate_series": true, "unnest": true,
+	"duckdb_tables": true, "duckdb_views": true, "duckdb_columns": true, "duckdb_schemas": true,
+	"duckdb_indexes": true, "duckdb_constraints": true, "duckdb_types": true, "duckdb_functions": true,
+	"pragma_table_info": true,
+}
+
+// queryTableName matches the names of tables, as opposed to the paths and
+// URLs DuckDB reads in their place (like FROM 'data.csv').
+var queryTableName = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+
+// QueryResult is the outcome of a console query.
+type QueryResult struct {
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	Truncated bool     `json:"truncated"` // more than QueryMaxRows rows
+}
+
+// queryKeywords returns the upper-cased words of the query outside of
+// string literals, quoted identifiers and comments, and whether it has more
+// than a statement.
+func queryKeywords(q string) ([]string, bool) {
+	var (
+		words []string
+		word  strings.Builder
+		multi bool
+		end   bool // a ; was seen
+	)
+
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToUpper(word.String()))
+			word.Reset()
+		}
+	}
+
+	rs := []rune(q)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+
+		switch {
+		case r == '\'' || r == '"':
+			flush()
+
+			i = skipUntil(rs, i+1, string(r))
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			flush()
+
+			i = skipUntil(rs, i, "\n")
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			flush()
+
+			i = skipUntil(rs, i+2, "*/") + 1
+		case r == ';':
+			flush()
+
+			end = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if end {
+				multi = true
+			}
+
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+
+	flush()
+
+	return words, multi
+}
+
+// skipUntil returns the position of the closing delimiter, looking from i.
+func skipUntil(rs []rune, i int, closing string) int {
+	c := []rune(closing)
+
+	for ; i+len(c) <= len(rs); i++ {
+		if string(rs[i:i+len(c)]) == closing {
+			return i
+		}
+	}
+
+	return len(rs)
+}
+
+// CheckReadOnlyQuery tells whether the console may run the query: a single
+// statement, of an allowed kind and without any keyword that writes.
+func CheckReadOnlyQuery(q string) error {
+	words, multi := queryKeywords(q)
+	if len(words) == 0 {
+		return fmt.Errorf("%w: empty query", ErrStatementNotAllowed)
+	}
+
+	if multi {
+		return fmt.Errorf("%w: only one statement per query", ErrStatementNotAllowed)
+	}
+
+	if !queryStatements[words[0]] {
+		return fmt.Errorf("%w: %s", ErrStatementNotAllowed, words[0])
+	}
+
+	for _, w := range words {
+		if queryForbidden[w] {
+			return fmt.Errorf("%w: %s", ErrStatementNotAllowed, w)
+		}
+	}
+
+	return nil
+}
+
+// checkQueryTables tells whether the query, as parsed by DuckDB, only reads
+// tables and the table functions in queryTableFunctions.
+func checkQueryTables(ctx context.Context, tx *sql.Tx, q string) error {
+	var serialized string
+	if err := tx.QueryRowContext(ctx, "SELECT json_serialize_sql(?::VARCHAR)::VARCHAR", q).Scan(&serialized); err != nil {
+		return fmt.Errorf("parsing query: %w", err)
+	}
+
+	var parsed struct {
+		Error      bool   `json:"error"`
+		Message    string `json:"error_message"`
+		Statements []any  `json:"statements"`
+	}
+	if err := json.Unmarshal([]byte(serialized), &parsed); err != nil {
+		return fmt.Errorf("decoding parsed query: %w", err)
+	}
+
+	if parsed.Error {
+		return errors.New(parsed.Message)
+	}
+
+	return checkQueryNode(parsed.Statements)
+}
+
+// checkQueryNode walks a node of the parsed query, checking its table
+// references.
+func checkQueryNode(node any) error {
+	switch n := node.(type) {
+	case []any:
+		for _, child := range n {
+			if err := checkQueryNode(child); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		switch n["type"] {
+		case "TABLE_FUNCTION":
+			f, _ := n["function"].(map[string]any)
+			if name, _ := f["function_name"].(string); !queryTableFunctions[strings.ToLower(name)] {
+				return fmt.Errorf("%w: table function %s", ErrStatementNotAllowed, name)
+			}
+		case "BASE_TABLE", "SHOW_REF":
+			name, _ := n["table_name"].(string)
+			if name = strings.Trim(name, `"`); name != "" && !queryTableName.MatchString(name) {
+				return fmt.Errorf("%w: table %q", ErrStatementNotAllowed, name)
+			}
+		}
+
+		for _, child := range n {
+			if err := checkQueryNode(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunReadOnlyQuery runs a console query with a timeout, in a transaction
+// that is always rolled back, returning at most QueryMaxRows rows. Besides
+// CheckReadOnlyQuery, the query is parsed to reject the reads of files and
+// URLs.
+func RunReadOnlyQuery(ctx context.Context, db *sql.DB, q string) (*QueryResult, error) {
+	if err := CheckReadOnlyQuery(q); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := checkQueryTables(ctx, tx, q); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := &QueryResult{Rows: [][]any{}}
+	if ret.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		if len(ret.Rows) == QueryMaxRows {
+			ret.Truncated = true
+
+			break
+		}
+
+		values := make([]any, len(ret.Columns))
+
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+
+		ret.Rows = append(ret.Rows, values)
+	}
+
+	return ret, rows.Err()
+}
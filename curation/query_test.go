@@ -0,0 +1,105 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnlyQuery(t *testing.T) {
+	allowed := []string{
+		"SELECT count(*) FROM offenses",
+		"  select 1;  ",
+		"WITH x AS (SELECT 1) SELECT * FROM x",
+		"FROM offenses LIMIT 3",
+		"DESCRIBE offenses",
+		"SELECT 'DELETE FROM x; DROP TABLE y' AS s",
+		`SELECT 1 AS "update"`,
+		"SELECT 1 -- ; drop table x\n",
+		"SELECT /* ; insert */ 1",
+	}
+	for _, q := range allowed {
+		assert.NoError(t, CheckReadOnlyQuery(q), q)
+	}
+
+	rejected := []string{
+		"",
+		"-- only a comment",
+		"DELETE FROM offenses",
+		"SELECT 1; DROP TABLE offenses",
+		"SELECT * FROM (DELETE FROM offenses RETURNING *)",
+		"EXPLAIN ANALYZE DELETE FROM offenses",
+		"ATTACH 'other.db'",
+		"COPY offenses TO 'out.csv'",
+		"PRAGMA enable_profiling",
+		"SET threads = 1",
+	}
+	for _, q := range rejected {
+		assert.ErrorIs(t, CheckReadOnlyQuery(q), ErrStatementNotAllowed, q)
+	}
+}
+
+func TestRunReadOnlyQuery(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses AS
+		SELECT i AS id, CASE WHEN i % 2 = 0 THEN 'Soriano' END AS department
+		FROM range(1500) t(i)
+	`)
+	require.NoError(t, err)
+
+	result, err := RunReadOnlyQuery(context.Background(), db, "SELECT department, count(*) AS n FROM offenses GROUP BY ALL ORDER BY ALL")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"department", "n"}, result.Columns)
+	assert.Equal(t, [][]any{{"Soriano", int64(750)}, {nil, int64(750)}}, result.Rows)
+	assert.False(t, result.Truncated)
+
+	result, err = RunReadOnlyQuery(context.Background(), db, "FROM offenses")
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, QueryMaxRows)
+	assert.True(t, result.Truncated)
+
+	_, err = RunReadOnlyQuery(context.Background(), db, "DROP TABLE offenses")
+	require.ErrorIs(t, err, ErrStatementNotAllowed)
+
+	_, err = RunReadOnlyQuery(context.Background(), db, "SELECT * FROM missing")
+	require.Error(t, err)
+
+	result, err = RunReadOnlyQuery(context.Background(), db, "SELECT count(*) FROM offenses o, unnest([o.id]) WHERE id IN (FROM range(3))")
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{int64(3)}}, result.Rows)
+
+	for _, q := range []string{"DESCRIBE offenses", "SHOW TABLES", "SHOW ALL TABLES", "SUMMARIZE offenses"} {
+		_, err = RunReadOnlyQuery(context.Background(), db, q)
+		assert.NoError(t, err, q)
+	}
+
+	// the host's files and URLs
+	for _, q := range []string{
+		"SELECT * FROM read_text('/etc/passwd')",
+		"SELECT content FROM read_blob('/etc/passwd')",
+		"FROM glob('/*')",
+		"SELECT * FROM read_csv('https://example.com/data.csv')",
+		"FROM read_parquet('s3://bucket/data.parquet')",
+		"FROM 'https://example.com/data.csv'",
+		`SELECT * FROM "data.csv"`,
+		"DESCRIBE 'data.json'",
+		"SUMMARIZE SELECT * FROM glob('*')",
+		"SELECT (SELECT count(*) FROM read_json('data.json'))",
+		"WITH x AS (FROM read_text('/etc/hosts')) FROM x",
+	} {
+		_, err = RunReadOnlyQuery(context.Background(), db, q)
+		assert.ErrorIs(t, err, ErrStatementNotAllowed, q)
+	}
+}
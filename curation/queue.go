@@ -0,0 +1,98 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"sync"
+	"time"
+)
+
+// QueueLease is how long a location handed out by the queue stays reserved
+// for the curator that got it.
+const QueueLease = 10 * time.Minute
+
+// DeferReasonCurator defers a location a curator chose to come back to later.
+const DeferReasonCurator = "curator"
+
+type queueKey struct {
+	dbID     int
+	location string
+}
+
+type queueClaim struct {
+	session string
+	until   time.Time
+}
+
+// locationQueue hands out the pending locations one at a time, so that two
+// curators working at the same time never get the same one. Claims and skips
+// only live in memory: a restart gives everything back.
+type locationQueue struct {
+	mu      sync.Mutex
+	claims  map[queueKey]queueClaim
+	skipped map[string]map[queueKey]bool // by session
+	now     func() time.Time
+}
+
+func newLocationQueue() *locationQueue {
+	return &locationQueue{
+		claims:  make(map[queueKey]queueClaim),
+		skipped: make(map[string]map[queueKey]bool),
+		now:     time.Now,
+	}
+}
+
+// next claims for the session the first of the pending items that isn't
+// claimed by another session nor skipped by this one. The previous claim of
+// the session is released.
+func (q *locationQueue) next(session string, pending []LocationQueueItem) (*LocationQueueItem, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+
+	for k, c := range q.claims {
+		if c.session == session || !now.Before(c.until) {
+			delete(q.claims, k)
+		}
+	}
+
+	for i := range pending {
+		k := queueKey{pending[i].DbID, pending[i].Location}
+		if _, claimed := q.claims[k]; claimed || q.skipped[session][k] {
+			continue
+		}
+
+		until := now.Add(QueueLease)
+		q.claims[k] = queueClaim{session: session, until: until}
+
+		return &pending[i], until
+	}
+
+	return nil, time.Time{}
+}
+
+// skip releases the location and keeps it from being handed again to the
+// session. Other curators can still get it.
+func (q *locationQueue) skip(session string, dbID int, location string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	k := queueKey{dbID, location}
+	delete(q.claims, k)
+
+	if q.skipped[session] == nil {
+		q.skipped[session] = make(map[queueKey]bool)
+	}
+
+	q.skipped[session][k] = true
+}
+
+// release gives the location back, e.g. once it was judged or deferred.
+func (q *locationQueue) release(dbID int, location string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.claims, queueKey{dbID, location})
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/duckdb/duckdb-go/v2"
+	"github.com/uber/h3-go/v4"
+)
+
+// CellTables are the tables with a point and its H3 cells, in the order
+// ReindexCells recomputes them.
+var CellTables = []string{"canonical_locations", "locations", "offenses"}
+
+// DefaultReindexChunkSize is the number of rows ReindexCells updates at once.
+const DefaultReindexChunkSize = 50_000
+
+// ReindexOptions tunes ReindexCells.
+type ReindexOptions struct {
+	// MinResolution and MaxResolution are the range of h3_resN columns to
+	// recompute, created when they don't exist.
+	MinResolution int
+	MaxResolution int
+	// ChunkSize is the number of rows updated at once, DefaultReindexChunkSize
+	// when zero.
+	ChunkSize int
+	// Progress, when set, is called after each chunk with the rows of the
+	// table done so far.
+	Progress func(table string, done, total int64)
+}
+
+// ReindexReport is what ReindexCells did.
+type ReindexReport struct {
+	AddedColumns []string         // as table.column
+	Rows         map[string]int64 // updated rows by table
+}
+
+// ParseResolutions parses a range of H3 resolutions as "1-10", or a single
+// one as "9".
+func ParseResolutions(s string) (lo, hi int, err error) {
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		to = from
+	}
+
+	if lo, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidResolution, s)
+	}
+
+	if hi, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidResolution, s)
+	}
+
+	if lo < 0 || hi > h3.MaxResolution || lo > hi {
+		return 0, 0, fmt.Errorf("%w: %q must be within 0-%d", ErrInvalidResolution, s, h3.MaxResolution)
+	}
+
+	return lo, hi, nil
+}
+
+// ReindexCells recomputes the h3_resN columns of CellTables from their points
+// for the resolutions of opts, adding the columns that are missing. The rows
+// are updated in chunks so a change of resolutions doesn't need hand-written
+// SQL nor a transaction over the whole offenses table. Tables that don't
+// exist are skipped.
+func ReindexCells(db *sql.DB, opts ReindexOptions) (*ReindexReport, error) {
+	if opts.MinResolution < 0 || opts.MaxResolution > h3.MaxResolution || opts.MinResolution > opts.MaxResolution {
+		return nil, fmt.Errorf("%w: %d-%d", ErrInvalidResolution, opts.MinResolution, opts.MaxResolution)
+	}
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultReindexChunkSize
+	}
+
+	cols := cellColumns(opts.MinResolution, opts.MaxResolution)
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting connection: %w", err)
+	}
+	defer conn.Close()
+
+	report := &ReindexReport{Rows: make(map[string]int64)}
+
+	for _, table := range CellTables {
+		if exists, err := tableExists(ctx, conn, table); err != nil {
+			return nil, err
+		} else if !exists {
+			continue
+		}
+
+		added, err := addColumns(ctx, conn, table, cols)
+		if err != nil {
+			return nil, err
+		}
+
+		report.AddedColumns = append(report.AddedColumns, added...)
+
+		var progress func(done, total int64)
+		if opts.Progress != nil {
+			progress = func(done, total int64) { opts.Progress(table, done, total) }
+		}
+
+		n, err := updatePoints(ctx, conn, table, cols, opts.ChunkSize, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Rows[table] = n
+	}
+
+	return report, nil
+}
+
+// cellColumns are the h3_resN columns of the resolutions lo to hi.
+func cellColumns(lo, hi int) pointColumns {
+	cols := pointColumns{sqlType: "UBIGINT", typ: duckdb.TYPE_UBIGINT}
+	for res := lo; res <= hi; res++ {
+		cols.names = append(cols.names, fmt.Sprintf("h3_res%d", res))
+	}
+
+	cols.compute = func(lat, lng float64) ([]driver.Value, error) {
+		latLng := h3.NewLatLng(lat, lng)
+		values := make([]driver.Value, 0, hi-lo+1)
+
+		for res := lo; res <= hi; res++ {
+			cell, err := h3.LatLngToCell(latLng, res)
+			if err != nil {
+				return nil, fmt.Errorf("error converting to h3 cell at res %d: %w", res, err)
+			}
+
+			values = append(values, uint64(cell))
+		}
+
+		return values, nil
+	}
+
+	return cols
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/h3-go/v4"
+)
+
+func TestParseResolutions(t *testing.T) {
+	tests := []struct {
+		in      string
+		lo, hi  int
+		wantErr bool
+	}{
+		{in: "1-10", lo: 1, hi: 10},
+		{in: "9", lo: 9, hi: 9},
+		{in: "0-15", lo: 0, hi: 15},
+		{in: "8-1", wantErr: true},
+		{in: "1-16", wantErr: true},
+		{in: "a-3", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		lo, hi, err := ParseResolutions(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidResolution, tt.in)
+
+			continue
+		}
+
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, []int{tt.lo, tt.hi}, []int{lo, hi}, tt.in)
+	}
+}
+
+func TestReindexCells(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// POINT_2D is a struct in the spatial extension
+	_, err = db.Exec(`
+		CREATE TYPE POINT_2D AS STRUCT(x DOUBLE, y DOUBLE);
+		CREATE TABLE locations (
+			id INTEGER PRIMARY KEY, location VARCHAR UNIQUE, point POINT_2D,
+			h3_res1 UBIGINT, h3_res8 UBIGINT
+		);
+		CREATE TABLE offenses (id INTEGER, point POINT_2D, superseded_by VARCHAR, h3_res8 UBIGINT);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO locations VALUES
+			(1, '18 de Julio y Ejido', {'x': -56.1851, 'y': -34.9055}, NULL, 42),
+			(2, 'Punta del Este', {'x': -54.9440, 'y': -34.9626}, NULL, NULL),
+			(3, 'Sin punto', NULL, NULL, NULL);
+		INSERT INTO offenses SELECT range, {'x': -56.1851, 'y': -34.9055}, NULL, NULL FROM range(5);
+		INSERT INTO offenses VALUES (5, NULL, NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	var progress []int64
+
+	report, err := ReindexCells(db, ReindexOptions{
+		MinResolution: 8,
+		MaxResolution: 10,
+		ChunkSize:     2,
+		Progress: func(table string, done, total int64) {
+			if table == "offenses" {
+				assert.Equal(t, int64(5), total)
+				progress = append(progress, done)
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"locations.h3_res9", "locations.h3_res10",
+		"offenses.h3_res9", "offenses.h3_res10",
+	}, report.AddedColumns)
+	assert.Equal(t, map[string]int64{"locations": 2, "offenses": 5}, report.Rows)
+	assert.Equal(t, []int64{2, 4, 5}, progress)
+
+	cell := func(res int) uint64 {
+		c, err := h3.LatLngToCell(h3.NewLatLng(-34.9055, -56.1851), res)
+		require.NoError(t, err)
+
+		return uint64(c)
+	}
+
+	var res8, res10 uint64
+
+	require.NoError(t, db.QueryRow("SELECT h3_res8, h3_res10 FROM locations WHERE id = 1").Scan(&res8, &res10))
+	assert.Equal(t, cell(8), res8, "stale cell is recomputed")
+	assert.Equal(t, cell(10), res10)
+
+	var res1 sql.NullInt64
+
+	require.NoError(t, db.QueryRow("SELECT h3_res1 FROM locations WHERE id = 1").Scan(&res1))
+	assert.False(t, res1.Valid, "resolutions out of the range are left untouched")
+
+	var n int
+
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM active_offenses WHERE h3_res9 = ?", cell(9)).Scan(&n))
+	assert.Equal(t, 5, n, "active_offenses sees the new columns")
+
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM offenses WHERE h3_res10 IS NULL").Scan(&n))
+	assert.Equal(t, 1, n, "offenses without a point have no cells")
+
+	// running it again doesn't add columns
+	report, err = ReindexCells(db, ReindexOptions{MinResolution: 9, MaxResolution: 10})
+	require.NoError(t, err)
+	assert.Empty(t, report.AddedColumns)
+}
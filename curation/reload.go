@@ -0,0 +1,135 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+)
+
+// stagingAlias is the name the staging database is attached with.
+const stagingAlias = "curation_staging"
+
+// reloadedTables are the tables replaced by Reload, with the columns left for
+// the sequences of db to assign.
+var reloadedTables = []struct {
+	name    string
+	exclude string
+}{
+	{"canonical_locations", ""},
+	{"articles", ""},
+	{"locations", "id"},
+	{"descriptions", "id"},
+}
+
+// Reload replaces the curation tables of db (the judgments, the canonical
+// locations, the articles and the descriptions) with the ones load fills.
+// load writes to a staging database with the same schema, and the tables are
+// swapped in a single transaction only after it succeeds, so a malformed
+// judgments file can't leave db half loaded or empty.
+func Reload(db *sql.DB, load func(staging *sql.DB) error) error {
+	dir, err := os.MkdirTemp("", "chapauy-curation-")
+	if err != nil {
+		return fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "staging.duckdb")
+	if err := stage(path, load); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	// the staging database is attached to a single connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("opening connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH '%s' AS %s (READ_ONLY)",
+		strings.ReplaceAll(path, "'", "''"), stagingAlias)); err != nil {
+		return fmt.Errorf("attaching staging database: %w", err)
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "DETACH "+stagingAlias); err != nil {
+			log.Printf("Error detaching staging database: %v", err)
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	for _, t := range reloadedTables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.name); err != nil {
+			return fmt.Errorf("clearing %s: %w", t.name, err)
+		}
+	}
+
+	for _, t := range reloadedTables {
+		columns := "*"
+		if t.exclude != "" {
+			columns = fmt.Sprintf("* EXCLUDE (%s)", t.exclude)
+		}
+
+		// #nosec G202 - table and column names are constants
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO %s BY NAME SELECT %s FROM %s.%s", t.name, columns, stagingAlias, t.name,
+		)); err != nil {
+			return fmt.Errorf("copying %s: %w", t.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing reload: %w", err)
+	}
+
+	return nil
+}
+
+// stage creates the staging database at path and fills it with load.
+func stage(path string, load func(staging *sql.DB) error) error {
+	staging, err := dbutils.Open(path, dbutils.ReadWrite)
+	if err != nil {
+		return fmt.Errorf("opening staging database: %w", err)
+	}
+	defer staging.Close()
+
+	if err := NewLocationRepository(staging, nil).CreateSchema(); err != nil {
+		return fmt.Errorf("creating staging geocoding schema: %w", err)
+	}
+
+	if err := NewDescriptionRepository(staging).CreateSchema(); err != nil {
+		return fmt.Errorf("creating staging description schema: %w", err)
+	}
+
+	if err := load(staging); err != nil {
+		return err
+	}
+
+	// the file must be complete before it's attached
+	if _, err := staging.Exec("CHECKPOINT"); err != nil {
+		return fmt.Errorf("checkpointing staging database: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	descrRepo := NewDescriptionRepository(db)
+	require.NoError(t, descrRepo.CreateSchema())
+	require.NoError(t, descrRepo.SeedArticles([]Article{{ID: "13.3.A", Text: "Exceso de velocidad", Code: 13, Title: "Velocidad"}}))
+	require.NoError(t, repo.SaveJudgment(&Location{
+		DbID: 6, Location: "Loc 1", GeocodingMethod: "manual", Point: &spatial.Point{Lat: 1, Lng: 1},
+	}))
+
+	load := func(staging *sql.DB) error {
+		if err := NewDescriptionRepository(staging).SeedArticles([]Article{
+			{ID: "13.3.A", Text: "Exceso de velocidad", Code: 13, Title: "Velocidad"},
+			{ID: "18.9.1", Text: "Estacionar en lugar prohibido", Code: 18, Title: "Estacionamiento"},
+		}); err != nil {
+			return err
+		}
+
+		return NewLocationRepository(staging, nil).BulkInsertJudgments([]*Location{
+			{DbID: 6, Location: "Loc 1", GeocodingMethod: "manual", Point: &spatial.Point{Lat: 1, Lng: 1}},
+			{DbID: 6, Location: "Loc 2", GeocodingMethod: "manual", Point: &spatial.Point{Lat: 2, Lng: 2}},
+		})
+	}
+	require.NoError(t, Reload(db, load))
+
+	n, err := repo.CountJudgments()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	articles, err := descrRepo.ListArticles()
+	require.NoError(t, err)
+	assert.Len(t, articles, 2)
+
+	// the ids keep coming from the sequence of db
+	require.NoError(t, repo.SaveJudgment(&Location{
+		DbID: 6, Location: "Loc 3", GeocodingMethod: "manual", Point: &spatial.Point{Lat: 3, Lng: 3},
+	}))
+
+	// a failed load leaves everything as it was
+	boom := errors.New("malformed judgments")
+	err = Reload(db, func(staging *sql.DB) error {
+		if err := load(staging); err != nil {
+			return err
+		}
+
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	n, err = repo.CountJudgments()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
@@ -27,20 +27,32 @@ type Location struct {
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	CanonicalLocation string         `json:"canonical_location,omitempty"`
-	H3Res1            int64          `json:"-"`
-	H3Res2            int64          `json:"-"`
-	H3Res3            int64          `json:"-"`
-	H3Res4            int64          `json:"-"`
-	H3Res5            int64          `json:"-"`
-	H3Res6            int64          `json:"-"`
-	H3Res7            int64          `json:"-"`
-	H3Res8            int64          `json:"-"`
+	// GlobalLocation references a row of canonical_locations, shared by
+	// judgments of every database.
+	GlobalLocation string `json:"global_location,omitempty"`
+	// Curator is who last changed the judgment through the curation API,
+	// identified by the token (see CuratorRepository).
+	Curator string `json:"curator,omitempty"`
+	// NearestPlace is the populated place nearest to the point, and
+	// NearestPlaceM its distance in meters (see localidades.json).
+	NearestPlace  string  `json:"-"`
+	NearestPlaceM float64 `json:"-"`
+	H3Res1        int64   `json:"-"`
+	H3Res2        int64   `json:"-"`
+	H3Res3        int64   `json:"-"`
+	H3Res4        int64   `json:"-"`
+	H3Res5        int64   `json:"-"`
+	H3Res6        int64   `json:"-"`
+	H3Res7        int64   `json:"-"`
+	H3Res8        int64   `json:"-"`
+	H3Res9        int64   `json:"-"`
+	H3Res10       int64   `json:"-"`
 }
 
 func (judgment *Location) computeH3() error {
 	if judgment.Point != nil {
 		latLng := h3.NewLatLng(judgment.Point.Lat, judgment.Point.Lng)
-		for res := 1; res <= 8; res++ {
+		for res := 1; res <= 10; res++ {
 			cell, err := h3.LatLngToCell(latLng, res)
 			if err != nil {
 				return fmt.Errorf("error converting to h3 cell at res %d: %w", res, err)
@@ -63,6 +75,10 @@ func (judgment *Location) computeH3() error {
 				judgment.H3Res7 = int64(cell)
 			case 8:
 				judgment.H3Res8 = int64(cell)
+			case 9:
+				judgment.H3Res9 = int64(cell)
+			case 10:
+				judgment.H3Res10 = int64(cell)
 			}
 		}
 	} else {
@@ -74,6 +90,8 @@ func (judgment *Location) computeH3() error {
 		judgment.H3Res6 = 0
 		judgment.H3Res7 = 0
 		judgment.H3Res8 = 0
+		judgment.H3Res9 = 0
+		judgment.H3Res10 = 0
 	}
 
 	return nil
@@ -91,6 +109,7 @@ type ClusterLocation struct {
 
 // LocationCluster represents a group of similar locations.
 type LocationCluster struct {
+	ID            string             `json:"id"` // see ClusterID
 	DbID          int                `json:"db_id"`
 	Location      string             `json:"location"`
 	DbName        string             `json:"db_name"`
@@ -106,6 +125,10 @@ type LocationRepository interface {
 	// SaveJudgment saves or updates a location judgment
 	SaveJudgment(judgment *Location) error
 
+	// DeleteJudgment removes the judgment of a location, putting it back in
+	// the queue
+	DeleteJudgment(dbID int, location string) error
+
 	// ListJudgments returns all judgments, optionally filtered
 	ListJudgments(dbID *int, location *string, limit, offset int) ([]*Location, error)
 
@@ -124,6 +147,47 @@ type LocationRepository interface {
 	// MergeLocations merges a list of locations into a single location.
 	MergeLocations(dbID int, targetLocation, canonicalLocation string) error
 
+	// MergeCluster merges several locations into a canonical one in a single
+	// transaction: either all of them are merged or none is.
+	MergeCluster(dbID int, canonicalLocation string, locations []string) ([]MergeResult, error)
+
+	// FindLocationCluster returns the unresolved cluster with the given ID,
+	// or ErrClusterNotFound.
+	FindLocationCluster(dbID *int, id string) (*LocationCluster, error)
+
+	// AcceptCluster merges every location of a cluster into principal, one
+	// of them, and marks the cluster resolved, in a single transaction. The
+	// locations of other databases than principal's are skipped.
+	AcceptCluster(cluster *LocationCluster, principal string) ([]MergeResult, error)
+
+	// SaveCanonicalLocation creates or updates a location shared across
+	// databases, updating the judgments that reference it.
+	SaveCanonicalLocation(c *CanonicalLocation) error
+
+	// ListCanonicalLocations returns the locations shared across databases.
+	ListCanonicalLocations() ([]*CanonicalLocation, error)
+
+	// LinkCanonicalLocation makes a judgment reference a shared location.
+	LinkCanonicalLocation(dbID int, location, name string) error
+
+	// DeferLocation takes a pending location out of the queue for a while.
+	DeferLocation(d *DeferredLocation) error
+
+	// ListDeferredLocations returns the pending locations still deferred.
+	ListDeferredLocations() ([]*DeferredLocation, error)
+
+	// MergeMirroredIntersections merges the judgments of the intersections
+	// judged with their streets in different orders.
+	MergeMirroredIntersections() (int, error)
+
+	// SimilarJudgments returns the judged locations of a database written
+	// like location, the most similar first.
+	SimilarJudgments(dbID int, location string, limit int) ([]SimilarJudgment, error)
+
+	// BackfillNearestPlaces computes the nearest place of the judgments
+	// that lack it.
+	BackfillNearestPlaces() (int64, error)
+
 	// DB returns the underlying database connection
 	DB() *sql.DB
 }
@@ -175,6 +239,57 @@ func (r *sqlJudgmentRepository) CreateSchema() error {
 			h3_res8 UBIGINT,
 			UNIQUE(db_id, location)
 		);
+
+		-- city-block cells, see ReindexCells for the existing rows
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS h3_res9 UBIGINT;
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS h3_res10 UBIGINT;
+
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS global_location VARCHAR;
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS nearest_place VARCHAR;
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS nearest_place_m DOUBLE;
+		ALTER TABLE locations ADD COLUMN IF NOT EXISTS curator VARCHAR;
+
+		-- Places that show up in several databases (e.g. the radars on Ruta
+		-- Interbalnearia, fined by both Canelones and Maldonado) are curated once
+		-- here and referenced from locations.global_location.
+		CREATE TABLE IF NOT EXISTS canonical_locations (
+			name VARCHAR PRIMARY KEY,
+			point POINT_2D NOT NULL,
+			geocoding_method VARCHAR NOT NULL,
+			confidence VARCHAR NOT NULL,
+			notes TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			h3_res1 UBIGINT,
+			h3_res2 UBIGINT,
+			h3_res3 UBIGINT,
+			h3_res4 UBIGINT,
+			h3_res5 UBIGINT,
+			h3_res6 UBIGINT,
+			h3_res7 UBIGINT,
+			h3_res8 UBIGINT
+		);
+
+		ALTER TABLE canonical_locations ADD COLUMN IF NOT EXISTS h3_res9 UBIGINT;
+		ALTER TABLE canonical_locations ADD COLUMN IF NOT EXISTS h3_res10 UBIGINT;
+
+		-- Clusters accepted by a curator, by the hash of their members: a
+		-- cluster that gains or loses a location is a new one.
+		CREATE TABLE IF NOT EXISTS resolved_clusters (
+			cluster_id VARCHAR PRIMARY KEY,
+			db_id INTEGER NOT NULL,
+			principal VARCHAR NOT NULL,
+			resolved_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS deferred_locations (
+			db_id INTEGER NOT NULL,
+			location VARCHAR NOT NULL,
+			reason VARCHAR NOT NULL,
+			deferred_until TIMESTAMP NOT NULL,
+			deferred_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (db_id, location)
+		);
 	`)
 
 	return err
@@ -199,6 +314,8 @@ func (r *sqlJudgmentRepository) SaveJudgment(judgment *Location) error {
 		return err
 	}
 
+	judgment.computeNearestPlace()
+
 	judgment.UpdatedAt = time.Now()
 	if existing != nil {
 		// Update
@@ -206,8 +323,10 @@ func (r *sqlJudgmentRepository) SaveJudgment(judgment *Location) error {
 			UPDATE locations
 			SET point = ST_Point(?, ?), is_electronic = ?,
 			    geocoding_method = ?, confidence = ?, notes = ?,
-			    updated_at = ?, canonical_location = ?,
-				h3_res1 = ?, h3_res2 = ?, h3_res3 = ?, h3_res4 = ?, h3_res5 = ?, h3_res6 = ?, h3_res7 = ?, h3_res8 = ?
+			    updated_at = ?, canonical_location = ?, global_location = ?,
+				nearest_place = ?, nearest_place_m = ?, curator = ?,
+				h3_res1 = ?, h3_res2 = ?, h3_res3 = ?, h3_res4 = ?, h3_res5 = ?, h3_res6 = ?, h3_res7 = ?, h3_res8 = ?,
+				h3_res9 = ?, h3_res10 = ?
 			WHERE db_id = ? AND location = ?
 		`,
 			judgment.Point.Lng,
@@ -218,6 +337,10 @@ func (r *sqlJudgmentRepository) SaveJudgment(judgment *Location) error {
 			judgment.Notes,
 			judgment.UpdatedAt,
 			judgment.CanonicalLocation,
+			nullIfEmpty(judgment.GlobalLocation),
+			nullIfEmpty(judgment.NearestPlace),
+			judgment.NearestPlaceM,
+			nullIfEmpty(judgment.Curator),
 			judgment.H3Res1,
 			judgment.H3Res2,
 			judgment.H3Res3,
@@ -226,6 +349,8 @@ func (r *sqlJudgmentRepository) SaveJudgment(judgment *Location) error {
 			judgment.H3Res6,
 			judgment.H3Res7,
 			judgment.H3Res8,
+			judgment.H3Res9,
+			judgment.H3Res10,
 			judgment.DbID,
 			judgment.Location,
 		)
@@ -239,6 +364,14 @@ func (r *sqlJudgmentRepository) SaveJudgment(judgment *Location) error {
 	return r.BulkInsertJudgments([]*Location{judgment})
 }
 
+func (r *sqlJudgmentRepository) DeleteJudgment(dbID int, location string) error {
+	if _, err := r.db.Exec("DELETE FROM locations WHERE db_id = ? AND location = ?", dbID, location); err != nil {
+		return fmt.Errorf("deleting judgment of %s: %w", location, err)
+	}
+
+	return nil
+}
+
 func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -250,6 +383,7 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 			db_id,
 		    location,
 			canonical_location,
+			global_location,
 			point,
 		    is_electronic,
 			geocoding_method,
@@ -257,6 +391,9 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 		    notes,
 		    created_at,
 		    updated_at,
+			nearest_place,
+			nearest_place_m,
+			curator,
 			h3_res1,
 			h3_res2,
 			h3_res3,
@@ -264,9 +401,11 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 			h3_res5,
 			h3_res6,
 			h3_res7,
-			h3_res8
+			h3_res8,
+			h3_res9,
+			h3_res10
 		)
-		VALUES (?, ?, ?, ST_Point(?, ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ST_Point(?, ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		if rErr := tx.Rollback(); rErr != nil {
@@ -287,10 +426,13 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 			return err
 		}
 
+		j.computeNearestPlace()
+
 		result, err := stmt.Exec(
 			j.DbID,
 			j.Location,
 			cannonical,
+			nullIfEmpty(j.GlobalLocation),
 			j.Point.Lng,
 			j.Point.Lat,
 			j.IsElectronic,
@@ -299,6 +441,9 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 			j.Notes,
 			j.CreatedAt,
 			j.UpdatedAt,
+			nullIfEmpty(j.NearestPlace),
+			j.NearestPlaceM,
+			nullIfEmpty(j.Curator),
 			j.H3Res1,
 			j.H3Res2,
 			j.H3Res3,
@@ -307,6 +452,8 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 			j.H3Res6,
 			j.H3Res7,
 			j.H3Res8,
+			j.H3Res9,
+			j.H3Res10,
 		)
 		if err != nil {
 			if rErr := tx.Rollback(); rErr != nil {
@@ -337,14 +484,14 @@ func (r *sqlJudgmentRepository) BulkInsertJudgments(judgments []*Location) error
 func (r *sqlJudgmentRepository) GetJudgment(dbID int, location string) (*Location, error) {
 	judgment := &Location{Point: &spatial.Point{}}
 
-	var canonicalLocation sql.NullString
+	var canonicalLocation, globalLocation, curator sql.NullString
 
-	var h3Res1, h3Res2, h3Res3, h3Res4, h3Res5, h3Res6, h3Res7, h3Res8 sql.NullInt64
+	var h3Res1, h3Res2, h3Res3, h3Res4, h3Res5, h3Res6, h3Res7, h3Res8, h3Res9, h3Res10 sql.NullInt64
 
 	err := r.db.QueryRow(`
 		SELECT db_id, location, point, is_electronic,
-		       geocoding_method, confidence, notes, created_at, updated_at, canonical_location,
-			   h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8
+		       geocoding_method, confidence, notes, created_at, updated_at, canonical_location, global_location, curator,
+			   h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8, h3_res9, h3_res10
 		FROM locations
 		WHERE db_id = ? AND location = ?
 	`, dbID, location).Scan(
@@ -358,6 +505,8 @@ func (r *sqlJudgmentRepository) GetJudgment(dbID int, location string) (*Locatio
 		&judgment.CreatedAt,
 		&judgment.UpdatedAt,
 		&canonicalLocation,
+		&globalLocation,
+		&curator,
 		&h3Res1,
 		&h3Res2,
 		&h3Res3,
@@ -366,6 +515,8 @@ func (r *sqlJudgmentRepository) GetJudgment(dbID int, location string) (*Locatio
 		&h3Res6,
 		&h3Res7,
 		&h3Res8,
+		&h3Res9,
+		&h3Res10,
 	)
 	if err != nil {
 		return nil, err
@@ -375,6 +526,9 @@ func (r *sqlJudgmentRepository) GetJudgment(dbID int, location string) (*Locatio
 		judgment.CanonicalLocation = canonicalLocation.String
 	}
 
+	judgment.GlobalLocation = globalLocation.String
+	judgment.Curator = curator.String
+
 	if h3Res1.Valid {
 		judgment.H3Res1 = h3Res1.Int64
 	}
@@ -407,6 +561,14 @@ func (r *sqlJudgmentRepository) GetJudgment(dbID int, location string) (*Locatio
 		judgment.H3Res8 = h3Res8.Int64
 	}
 
+	if h3Res9.Valid {
+		judgment.H3Res9 = h3Res9.Int64
+	}
+
+	if h3Res10.Valid {
+		judgment.H3Res10 = h3Res10.Int64
+	}
+
 	return judgment, nil
 }
 
@@ -422,16 +584,16 @@ func (r *sqlJudgmentRepository) list(query string, args []any) ([]*Location, err
 	for rows.Next() {
 		judgment := &Location{Point: &spatial.Point{}}
 
-		var canonicalLocation sql.NullString
+		var canonicalLocation, globalLocation, curator sql.NullString
 
-		var h3Res1, h3Res2, h3Res3, h3Res4, h3Res5, h3Res6, h3Res7, h3Res8 sql.NullInt64
+		var h3Res1, h3Res2, h3Res3, h3Res4, h3Res5, h3Res6, h3Res7, h3Res8, h3Res9, h3Res10 sql.NullInt64
 
 		err := rows.Scan(
 			&judgment.DbID, &judgment.Location,
 			&judgment.Point, &judgment.IsElectronic,
 			&judgment.GeocodingMethod, &judgment.Confidence, &judgment.Notes,
-			&judgment.CreatedAt, &judgment.UpdatedAt, &canonicalLocation,
-			&h3Res1, &h3Res2, &h3Res3, &h3Res4, &h3Res5, &h3Res6, &h3Res7, &h3Res8,
+			&judgment.CreatedAt, &judgment.UpdatedAt, &canonicalLocation, &globalLocation, &curator,
+			&h3Res1, &h3Res2, &h3Res3, &h3Res4, &h3Res5, &h3Res6, &h3Res7, &h3Res8, &h3Res9, &h3Res10,
 		)
 		if err != nil {
 			return nil, err
@@ -441,6 +603,9 @@ func (r *sqlJudgmentRepository) list(query string, args []any) ([]*Location, err
 			judgment.CanonicalLocation = canonicalLocation.String
 		}
 
+		judgment.GlobalLocation = globalLocation.String
+		judgment.Curator = curator.String
+
 		if h3Res1.Valid {
 			judgment.H3Res1 = h3Res1.Int64
 		}
@@ -473,6 +638,14 @@ func (r *sqlJudgmentRepository) list(query string, args []any) ([]*Location, err
 			judgment.H3Res8 = h3Res8.Int64
 		}
 
+		if h3Res9.Valid {
+			judgment.H3Res9 = h3Res9.Int64
+		}
+
+		if h3Res10.Valid {
+			judgment.H3Res10 = h3Res10.Int64
+		}
+
 		judgments = append(judgments, judgment)
 	}
 
@@ -482,8 +655,8 @@ func (r *sqlJudgmentRepository) list(query string, args []any) ([]*Location, err
 var baseSelect = `
 	SELECT db_id, location, point, is_electronic,
 	       geocoding_method, confidence, notes,
-		   created_at, updated_at, canonical_location,
-		   h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8
+		   created_at, updated_at, canonical_location, global_location, curator,
+		   h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8, h3_res9, h3_res10
 	FROM locations
 `
 
@@ -543,6 +716,11 @@ func (r *sqlJudgmentRepository) GetLocationClusters(dbID *int) ([]*LocationClust
 		return nil, fmt.Errorf("getting offense counts: %w", err)
 	}
 
+	resolved, err := r.resolvedClusters()
+	if err != nil {
+		return nil, fmt.Errorf("getting resolved clusters: %w", err)
+	}
+
 	judgmentClusters := clusterJudgments(judgments, 10) // 10 meters
 	log.Printf("Created %d raw clusters", len(judgmentClusters))
 
@@ -637,9 +815,15 @@ func (r *sqlJudgmentRepository) GetLocationClusters(dbID *int) ([]*LocationClust
 			loc.DistanceFromPrincipal = principal.Point.HaversineDistance(&loc.Point)
 		}
 
+		id := ClusterID(locations)
+		if resolved[id] {
+			continue
+		}
+
 		dbName := r.dbMap[clusterDbID]
 
 		result = append(result, &LocationCluster{
+			ID:            id,
 			DbID:          clusterDbID,
 			Location:      principal.Description,
 			DbName:        dbName,
@@ -726,8 +910,105 @@ func (r *sqlJudgmentRepository) MergeLocations(dbID int, targetLocation, canonic
 		targetJudgment.H3Res6 = canonicalJudgment.H3Res6
 		targetJudgment.H3Res7 = canonicalJudgment.H3Res7
 		targetJudgment.H3Res8 = canonicalJudgment.H3Res8
+		targetJudgment.H3Res9 = canonicalJudgment.H3Res9
+		targetJudgment.H3Res10 = canonicalJudgment.H3Res10
 	}
 
 	// Save the updated target judgment
 	return r.SaveJudgment(targetJudgment)
 }
+
+// MergeResult is the outcome of merging one location of a cluster. When any
+// location fails nothing is applied, Merged then tells which ones would have.
+// Skipped tells why a location was left as it was without failing the merge.
+type MergeResult struct {
+	Location string `json:"location"`
+	Merged   bool   `json:"merged"`
+	Error    string `json:"error,omitempty"`
+	Skipped  string `json:"skipped,omitempty"`
+}
+
+// ErrMergeFailed is returned by MergeCluster when some location could not be
+// merged; the per-location results explain which.
+var ErrMergeFailed = errors.New("some locations could not be merged")
+
+func (r *sqlJudgmentRepository) MergeCluster(
+	dbID int, canonicalLocation string, locations []string,
+) ([]MergeResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	results, err := mergeInto(tx, dbID, canonicalLocation, locations)
+	if err != nil {
+		return results, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing merge: %w", err)
+	}
+
+	return results, nil
+}
+
+// mergeInto merges locations into canonicalLocation within tx. It returns
+// ErrMergeFailed, along with the results, when any of them fails.
+func mergeInto(tx *sql.Tx, dbID int, canonicalLocation string, locations []string) ([]MergeResult, error) {
+	var exists bool
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) > 0 FROM locations WHERE db_id = ? AND location = ?", dbID, canonicalLocation,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("looking up canonical judgment: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("canonical judgment not found for dbID %d, location %s", dbID, canonicalLocation)
+	}
+
+	results := make([]MergeResult, 0, len(locations))
+	failed := false
+	now := time.Now()
+
+	for _, location := range locations {
+		result := MergeResult{Location: location}
+
+		if location == canonicalLocation {
+			result.Error = "location is the canonical one"
+		} else {
+			res, err := tx.Exec(`
+				UPDATE locations AS l
+				SET canonical_location = c.location, point = c.point, updated_at = ?,
+					nearest_place = c.nearest_place, nearest_place_m = c.nearest_place_m,
+					h3_res1 = c.h3_res1, h3_res2 = c.h3_res2, h3_res3 = c.h3_res3, h3_res4 = c.h3_res4,
+					h3_res5 = c.h3_res5, h3_res6 = c.h3_res6, h3_res7 = c.h3_res7, h3_res8 = c.h3_res8,
+					h3_res9 = c.h3_res9, h3_res10 = c.h3_res10
+				FROM locations AS c
+				WHERE c.db_id = ? AND c.location = ? AND l.db_id = ? AND l.location = ?
+			`, now, dbID, canonicalLocation, dbID, location)
+
+			if err != nil {
+				result.Error = err.Error()
+			} else if n, _ := res.RowsAffected(); n == 0 {
+				result.Error = "judgment not found"
+			} else {
+				result.Merged = true
+			}
+		}
+
+		failed = failed || !result.Merged
+		results = append(results, result)
+	}
+
+	if failed {
+		return results, ErrMergeFailed
+	}
+
+	return results, nil
+}
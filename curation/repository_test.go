@@ -5,12 +5,14 @@ package curation
 
 import (
 	"database/sql"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
 	_ "github.com/duckdb/duckdb-go/v2"
 	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/uber/h3-go/v4"
 )
 
 func setupTestDB(t *testing.T) (*sql.DB, LocationRepository) {
@@ -183,6 +185,16 @@ func TestUpdateJudgment(t *testing.T) {
 		t.Errorf("Notes = %s, want 'Corrected after review'", retrieved.Notes)
 	}
 
+	// the city-block cell follows the point
+	cell, err := h3.LatLngToCell(h3.NewLatLng(lat2, lon2), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if retrieved.H3Res10 != int64(cell) {
+		t.Errorf("H3Res10 = %d, want %d", retrieved.H3Res10, int64(cell))
+	}
+
 	if !retrieved.UpdatedAt.After(originalUpdatedAt) {
 		t.Error("UpdatedAt should be after original")
 	}
@@ -547,3 +559,307 @@ func TestMergeLocations(t *testing.T) {
 		t.Errorf("Expected target coordinates to be (10.0, 20.0), got (%f, %f)", updatedTarget.Point.Lat, updatedTarget.Point.Lng)
 	}
 }
+
+func TestMergeLocationsCluster(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	for i, location := range []string{"Canonical", "Alias 1", "Alias 2"} {
+		if err := repo.SaveJudgment(&Location{
+			DbID:            1,
+			Location:        location,
+			Point:           &spatial.Point{Lat: float64(10 * (i + 1)), Lng: 20.0},
+			GeocodingMethod: "manual",
+			Confidence:      "high",
+		}); err != nil {
+			t.Fatalf("Failed to save judgment: %v", err)
+		}
+	}
+
+	// an unknown location aborts the whole merge
+	results, err := repo.MergeCluster(1, "Canonical", []string{"Alias 1", "Missing"})
+	if !errors.Is(err, ErrMergeFailed) {
+		t.Fatalf("expected ErrMergeFailed, got %v", err)
+	}
+
+	if len(results) != 2 || !results[0].Merged || results[1].Merged {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	location := "Alias 1"
+	dbID := 1
+
+	judgments, err := repo.ListJudgments(&dbID, &location, 1, 0)
+	if err != nil || judgments[0].CanonicalLocation != "" {
+		t.Fatalf("expected Alias 1 to be untouched: %v %+v", err, judgments)
+	}
+
+	results, err = repo.MergeCluster(1, "Canonical", []string{"Alias 1", "Alias 2"})
+	if err != nil {
+		t.Fatalf("MergeCluster failed: %v %+v", err, results)
+	}
+
+	for _, location := range []string{"Alias 1", "Alias 2"} {
+		judgments, err := repo.ListJudgments(&dbID, &location, 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if judgments[0].CanonicalLocation != "Canonical" || judgments[0].Point.Lat != 10.0 {
+			t.Errorf("%s was not merged: %+v", location, judgments[0])
+		}
+	}
+}
+
+func TestAcceptCluster(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE offenses (db_id INTEGER, location VARCHAR);
+		INSERT INTO offenses VALUES (1, 'AV ITALIA Y BOLIVIA'), (1, 'AV ITALIA Y BOLIVIA'), (1, 'AVDA ITALIA Y BOLIVIA'),
+			(1, 'ITALIA Y BOLIVIA'), (2, 'AVENIDA ITALIA Y BOLIVIA');
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	// a meter apart
+	for location, lat := range map[string]float64{
+		"AV ITALIA Y BOLIVIA": -34.89, "AVDA ITALIA Y BOLIVIA": -34.89001, "ITALIA Y BOLIVIA": -34.89002,
+	} {
+		if err := repo.SaveJudgment(&Location{
+			DbID:            1,
+			Location:        location,
+			Point:           &spatial.Point{Lat: lat, Lng: -56.10},
+			GeocodingMethod: "manual",
+			Confidence:      "high",
+		}); err != nil {
+			t.Fatalf("Failed to save judgment: %v", err)
+		}
+	}
+
+	// the same corner, fined by another database
+	if err := repo.SaveJudgment(&Location{
+		DbID:            2,
+		Location:        "AVENIDA ITALIA Y BOLIVIA",
+		Point:           &spatial.Point{Lat: -34.89003, Lng: -56.10},
+		GeocodingMethod: "manual",
+		Confidence:      "high",
+	}); err != nil {
+		t.Fatalf("Failed to save judgment: %v", err)
+	}
+
+	clusters, err := repo.GetLocationClusters(nil)
+	if err != nil || len(clusters) != 1 || len(clusters[0].Locations) != 4 {
+		t.Fatalf("expected one cluster of four locations: %v %+v", err, clusters)
+	}
+
+	id := clusters[0].ID
+	if id != ClusterID([]*ClusterLocation{clusters[0].Locations[2], clusters[0].Locations[0], clusters[0].Locations[1]}) {
+		t.Errorf("the ID of the cluster depends on the order of its locations")
+	}
+
+	if _, err := repo.FindLocationCluster(nil, "missing"); !errors.Is(err, ErrClusterNotFound) {
+		t.Errorf("expected ErrClusterNotFound, got %v", err)
+	}
+
+	cluster, err := repo.FindLocationCluster(nil, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.AcceptCluster(cluster, "BOLIVIA Y AV ITALIA"); !errors.Is(err, ErrPrincipalNotInCluster) {
+		t.Errorf("expected ErrPrincipalNotInCluster, got %v", err)
+	}
+
+	// the curator may choose a principal other than the one with more offenses
+	results, err := repo.AcceptCluster(cluster, "ITALIA Y BOLIVIA")
+	if err != nil || len(results) != 3 {
+		t.Fatalf("AcceptCluster failed: %v %+v", err, results)
+	}
+
+	for _, result := range results {
+		if skipped := result.Location == "AVENIDA ITALIA Y BOLIVIA"; skipped != (result.Skipped != "") || skipped == result.Merged {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	}
+
+	otherDbID, other := 2, "AVENIDA ITALIA Y BOLIVIA"
+
+	judgments, err := repo.ListJudgments(&otherDbID, &other, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if judgments[0].CanonicalLocation != "" {
+		t.Errorf("the location of another database was merged: %+v", judgments[0])
+	}
+
+	dbID := 1
+
+	for _, location := range []string{"AV ITALIA Y BOLIVIA", "AVDA ITALIA Y BOLIVIA"} {
+		judgments, err := repo.ListJudgments(&dbID, &location, 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if judgments[0].CanonicalLocation != "ITALIA Y BOLIVIA" || judgments[0].Point.Lat != -34.89002 {
+			t.Errorf("%s was not merged: %+v", location, judgments[0])
+		}
+	}
+
+	if _, err := repo.FindLocationCluster(&dbID, id); !errors.Is(err, ErrClusterNotFound) {
+		t.Errorf("expected the cluster to be resolved, got %v", err)
+	}
+}
+
+func TestSaveAndGetJudgment_CanonicalLocation(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	// the same radar shows up in Canelones and Maldonado
+	for dbID, location := range map[int]string{35: "RUTA IB KM 35", 45: "RUTA INTERBALNEARIA KM 35.5"} {
+		if err := repo.SaveJudgment(&Location{
+			DbID:            dbID,
+			Location:        location,
+			Point:           &spatial.Point{Lat: -34.0, Lng: -55.0},
+			GeocodingMethod: "manual",
+			Confidence:      "low",
+		}); err != nil {
+			t.Fatalf("Failed to save judgment: %v", err)
+		}
+	}
+
+	canonical := &CanonicalLocation{
+		Name:            "RUTA INTERBALNEARIA KM 35",
+		Point:           &spatial.Point{Lat: -34.78, Lng: -55.71},
+		GeocodingMethod: "radares_rutas",
+		Confidence:      "high",
+	}
+	if err := repo.SaveCanonicalLocation(canonical); err != nil {
+		t.Fatalf("SaveCanonicalLocation failed: %v", err)
+	}
+
+	if err := repo.LinkCanonicalLocation(35, "RUTA IB KM 35", canonical.Name); err != nil {
+		t.Fatalf("LinkCanonicalLocation failed: %v", err)
+	}
+
+	if err := repo.LinkCanonicalLocation(45, "RUTA INTERBALNEARIA KM 35.5", canonical.Name); err != nil {
+		t.Fatalf("LinkCanonicalLocation failed: %v", err)
+	}
+
+	if err := repo.LinkCanonicalLocation(45, "RUTA IB KM 35", "missing"); !errors.Is(err, ErrCanonicalLocationNotFound) {
+		t.Errorf("expected ErrCanonicalLocationNotFound, got %v", err)
+	}
+
+	// moving the shared location moves every judgment referencing it
+	canonical.Point = &spatial.Point{Lat: -34.79, Lng: -55.72}
+	if err := repo.SaveCanonicalLocation(canonical); err != nil {
+		t.Fatalf("SaveCanonicalLocation failed: %v", err)
+	}
+
+	judgments, err := repo.GetAllJudgmentsSorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, j := range judgments {
+		if j.GlobalLocation != canonical.Name || j.CanonicalLocation != canonical.Name || j.Point.Lat != -34.79 {
+			t.Errorf("judgment not linked: %+v", j)
+		}
+	}
+
+	canonicals, err := repo.ListCanonicalLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(canonicals) != 1 || canonicals[0].References != 2 {
+		t.Errorf("unexpected canonical locations %+v", canonicals)
+	}
+}
+
+func TestNearestPlace(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	judgment := &Location{
+		DbID:            45,
+		Location:        "GORLERO Y 20",
+		Point:           &spatial.Point{Lat: -34.9593, Lng: -54.9415},
+		GeocodingMethod: "manual",
+		Confidence:      "high",
+	}
+	if err := repo.SaveJudgment(judgment); err != nil {
+		t.Fatalf("SaveJudgment() error = %v", err)
+	}
+
+	var (
+		place    string
+		distance float64
+	)
+
+	query := "SELECT nearest_place, nearest_place_m FROM locations WHERE location = 'GORLERO Y 20'"
+	if err := db.QueryRow(query).Scan(&place, &distance); err != nil {
+		t.Fatalf("querying nearest place: %v", err)
+	}
+
+	if place != "Punta del Este" || distance <= 0 || distance > 1000 {
+		t.Errorf("nearest place = %s at %.0fm, want Punta del Este", place, distance)
+	}
+
+	// judgments saved before the column existed are backfilled
+	if _, err := db.Exec("UPDATE locations SET nearest_place = NULL, nearest_place_m = NULL"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := repo.BackfillNearestPlaces()
+	if err != nil {
+		t.Fatalf("BackfillNearestPlaces() error = %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("BackfillNearestPlaces() = %d, want 1", n)
+	}
+
+	if err := db.QueryRow(query).Scan(&place, &distance); err != nil || place != "Punta del Este" {
+		t.Errorf("nearest place after backfill = %s (%v)", place, err)
+	}
+}
+
+func TestSaveAndGetJudgment_Curator(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	curators := NewCuratorRepository(db)
+	if err := curators.CreateSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	judgment := &Location{
+		DbID:            6,
+		Location:        "AV ITALIA Y AV CENTENARIO",
+		Point:           &spatial.Point{Lat: -34.89, Lng: -56.15},
+		GeocodingMethod: "manual",
+		Confidence:      "high",
+		Curator:         "ana",
+	}
+	if err := repo.SaveJudgment(judgment); err != nil {
+		t.Fatal(err)
+	}
+
+	dbID := 6
+	if got, err := repo.ListJudgments(&dbID, &judgment.Location, 1, 0); err != nil || len(got) != 1 || got[0].Curator != "ana" {
+		t.Fatalf("expected ana as curator, got %+v, %v", got, err)
+	}
+
+	// a merge doesn't know the curator, the server stamps it after the action
+	if err := curators.StampJudgments("beto", []AuditChange{{Kind: AuditKindLocation, DbID: 6, Target: judgment.Location}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := repo.GetAllJudgmentsSorted()
+	if err != nil || len(all) != 1 || all[0].Curator != "beto" {
+		t.Fatalf("expected beto as curator, got %+v, %v", all, err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+)
+
+// ReverseGeocodingResult is the address found at a point.
+type ReverseGeocodingResult struct {
+	Address string   `json:"address"`
+	Streets []string `json:"streets"` // the routes around the point
+}
+
+// ReverseGeocoder finds the address of a point, to check the coordinates the
+// curators accept.
+type ReverseGeocoder interface {
+	ReverseGeocode(lat, lng float64) (*ReverseGeocodingResult, error)
+}
+
+// AddressCheck is the outcome of comparing the address at the accepted
+// coordinates with the location text.
+type AddressCheck struct {
+	Address string   `json:"address"`
+	Streets []string `json:"streets"`
+	Matches bool     `json:"matches"`
+	Warning string   `json:"warning,omitempty"`
+}
+
+// streetStopwords are the words of the street names that don't tell one
+// street from another.
+var streetStopwords = map[string]bool{
+	"de": true, "del": true, "la": true, "las": true, "el": true, "los": true, "y": true, "e": true,
+	"esq": true, "esquina": true, "entre": true, "frente": true, "al": true, "a": true,
+	"av": true, "avda": true, "avenida": true, "calle": true, "bv": true, "bvar": true, "bulevar": true,
+	"boulevard": true, "cno": true, "camino": true, "pje": true, "pasaje": true, "km": true,
+	"gral": true, "general": true, "dr": true, "doctor": true, "ing": true, "pres": true, "presidente": true,
+}
+
+// streetTokens splits a location or street name into the words that identify
+// it: folded to lowercase ASCII, without stopwords nor single letters.
+func streetTokens(s string) map[string]bool {
+	tokens := make(map[string]bool)
+
+	for _, t := range strings.FieldsFunc(utils.LowerASCIIFolding(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if streetStopwords[t] || (len(t) == 1 && !unicode.IsDigit(rune(t[0]))) {
+			continue
+		}
+
+		tokens[t] = true
+	}
+
+	return tokens
+}
+
+// checkAddress tells whether any street around the point shares a word with
+// the location. Without streets, e.g. in the middle of a field, there's
+// nothing to compare and it's taken as a match.
+func checkAddress(location string, rev *ReverseGeocodingResult) *AddressCheck {
+	check := &AddressCheck{Address: rev.Address, Streets: rev.Streets, Matches: len(rev.Streets) == 0}
+
+	want := streetTokens(location)
+
+	for _, street := range rev.Streets {
+		for t := range streetTokens(street) {
+			if want[t] {
+				check.Matches = true
+			}
+		}
+	}
+
+	return check
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddress(t *testing.T) {
+	for _, tc := range []struct {
+		location string
+		streets  []string
+		matches  bool
+	}{
+		{"AV 8 DE OCTUBRE Y AV CENTENARIO", []string{"Avenida 8 de Octubre", "Avenida Centenario"}, true},
+		{"18 DE JULIO Y EJIDO", []string{"Avenida 18 de Julio"}, true},
+		{"BVAR ARTIGAS Y RIVERA", []string{"Bulevar General Artigas"}, true},
+		{"Ruta 5 km 30", []string{"Ruta 5"}, true},
+		// same word, with and without accent
+		{"JOSE BATLLE Y ORDOÑEZ", []string{"José Batlle y Ordóñez"}, true},
+		// only the stopwords in common
+		{"AV DE LAS INSTRUCCIONES", []string{"Avenida de las Américas"}, false},
+		{"18 DE JULIO Y EJIDO", []string{"Calle Sarandí", "Rincón"}, false},
+		// nothing around to compare with
+		{"PARADA 5", nil, true},
+	} {
+		check := checkAddress(tc.location, &ReverseGeocodingResult{Address: "x", Streets: tc.streets})
+		assert.Equal(t, tc.matches, check.Matches, "%s vs %v", tc.location, tc.streets)
+	}
+}
+
+func TestGoogleMapsGeocoder_ReverseGeocode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "-34.905500,-56.185100", r.URL.Query().Get("latlng"))
+		assert.Equal(t, "secret", r.URL.Query().Get("key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"status": "OK",
+			"results": [
+				{
+					"formatted_address": "Av. 18 de Julio 1500, Montevideo",
+					"address_components": [
+						{"long_name": "1500", "short_name": "1500", "types": ["street_number"]},
+						{"long_name": "Avenida 18 de Julio", "short_name": "Av. 18 de Julio", "types": ["route"]},
+						{"long_name": "Montevideo", "short_name": "Montevideo", "types": ["locality", "political"]}
+					]
+				},
+				{
+					"formatted_address": "Ejido & Av. 18 de Julio, Montevideo",
+					"address_components": [
+						{"long_name": "Ejido", "short_name": "Ejido", "types": ["route"]},
+						{"long_name": "Avenida 18 de Julio", "short_name": "Av. 18 de Julio", "types": ["route"]}
+					]
+				}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	g := NewGoogleMapsGeocoder("secret")
+	g.baseURL = srv.URL
+
+	rev, err := g.ReverseGeocode(-34.9055, -56.1851)
+	require.NoError(t, err)
+	assert.Equal(t, "Av. 18 de Julio 1500, Montevideo", rev.Address)
+	assert.Equal(t, []string{"Avenida 18 de Julio", "Ejido"}, rev.Streets)
+}
@@ -4,7 +4,7 @@
 package curation
 
 import (
-	"context"
+	"cmp"
 	"database/sql" // Added import
 	"errors"
 	"fmt"
@@ -12,136 +12,208 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
-	apikeys "cloud.google.com/go/apikeys/apiv2"
-	"cloud.google.com/go/apikeys/apiv2/apikeyspb"
 	"github.com/gin-gonic/gin"
+	"github.com/jcodagnone/chapauy/impo"
 	"github.com/jcodagnone/chapauy/spatial"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/iterator"
+	"github.com/jcodagnone/chapauy/utils/i18n"
 )
 
 type Server struct {
+	db              *sql.DB
 	geocodeRepo     LocationRepository
 	descriptionRepo DescriptionRepository
+	outlierRepo     OutlierRepository
+	auditRepo       AuditRepository
+	headerRepo      HeaderRepository
+	valueRepo       ValueRepository
+	vehicleRepo     VehicleOverrideRepository
+	streetRepo      StreetAliasRepository
+	failureRepo     FailureRepository
+	cellRepo        CellStatsRepository
+	curatorRepo     CuratorRepository
+	timelineRepo    LocationOffenseRepository
+	velocityRepo    VelocityRepository
+	queue           *locationQueue
 	radarIndex      *RadarIndex
 	geocoder        Geocoder
+	reverseGeocoder ReverseGeocoder
 	dbMap           map[int]string
+	documentsPath   string
+	readOnly        bool
+	requireTokens   bool
 }
 
-func NewServer(geocodeRepo LocationRepository, db *sql.DB, radarIndex *RadarIndex, dbMap map[int]string) *Server {
-	apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
-	if apiKey == "" {
-		log.Println("GOOGLE_MAPS_API_KEY is not set. Attempting to retrieve via ADC...")
-
-		var err error
-
-		apiKey, err = getAPIKeyFromADC(context.Background())
-		if err != nil {
-			log.Printf("Failed to retrieve API key via ADC: %v", err)
-			log.Print("GOOGLE_MAPS_API_KEY is not set and ADC failed. Google Maps Geocoding is required.")
-		} else {
-			log.Println("✅ Successfully retrieved Google Maps API Key via ADC")
-		}
+// NewServer creates the curation server. The apiKey of Google Maps is read by
+// the caller, see the credentials package; without one the geocoding
+// suggestions fail.
+func NewServer(
+	geocodeRepo LocationRepository,
+	db *sql.DB,
+	radarIndex *RadarIndex,
+	dbMap map[int]string,
+	apiKey string,
+) *Server {
+	if apiKey != "" {
+		fmt.Println("📍 Geocoding: Google Maps (primary)")
 	}
 
-	fmt.Println("📍 Geocoding: Google Maps (primary)")
+	google := NewGoogleMapsGeocoder(apiKey)
+
+	// the cache goes first, a cached result doesn't count against the quota
+	geocoder := NewCachingGeocoder(
+		NewQuotaAwareGeocoder(google),
+		NewGeocodeCacheRepository(db),
+		"google_maps",
+	)
 
 	return &Server{
+		db:              db,
 		geocodeRepo:     geocodeRepo,
 		descriptionRepo: NewDescriptionRepository(db), // Create descriptionRepo here
+		outlierRepo:     NewOutlierRepository(db),
+		auditRepo:       NewAuditRepository(db),
+		headerRepo:      NewHeaderRepository(db),
+		valueRepo:       NewValueRepository(db),
+		vehicleRepo:     NewVehicleOverrideRepository(db),
+		streetRepo:      NewStreetAliasRepository(db),
+		failureRepo:     NewFailureRepository(db),
+		cellRepo:        NewCellStatsRepository(db),
+		curatorRepo:     NewCuratorRepository(db),
+		timelineRepo:    NewLocationOffenseRepository(db),
+		velocityRepo:    NewVelocityRepository(db),
+		queue:           newLocationQueue(),
 		radarIndex:      radarIndex,
-		geocoder:        NewGoogleMapsGeocoder(apiKey),
+		geocoder:        geocoder,
+		reverseGeocoder: google,
 		dbMap:           dbMap,
 	}
 }
 
-func getAPIKeyFromADC(ctx context.Context) (string, error) {
-	// 1. Get Project ID from ADC
-	creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		return "", fmt.Errorf("finding default credentials: %w", err)
-	}
+// SetReadOnly makes the server reject every request that would modify the
+// database. Use it when the database was opened read-only.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
 
-	projectID := creds.ProjectID
-	if projectID == "" {
-		// Fallback to known Project ID if not found in credentials
-		// This happens when using user credentials without a quota project
-		projectID = "chapauy-20251216"
-		log.Printf("⚠️ No Project ID found in credentials. Using fallback: %s", projectID)
-	}
+// SetDocumentsPath sets where the downloaded documents are, see
+// impo.ClientOptions.DocumentsPath. Without it the extraction previews fail.
+func (s *Server) SetDocumentsPath(path string) {
+	s.documentsPath = path
+}
 
-	// 2. Create API Keys client
-	client, err := apikeys.NewClient(ctx)
-	if err != nil {
-		return "", fmt.Errorf("creating apikeys client: %w", err)
+// SetRequireTokens makes the server reject the API requests without a curator
+// token, see CuratorRepository.
+func (s *Server) SetRequireTokens(requireTokens bool) {
+	s.requireTokens = requireTokens
+}
+
+// isWrite tells whether the request may modify the database.
+func isWrite(r *http.Request) bool {
+	// the query console only reads, it's a POST to fit the query in the body
+	if r.URL.Path == "/api/query" {
+		return false
 	}
-	defer client.Close()
 
-	// 3. List keys to find the one with the expected display name
-	// This matches the DisplayName used in .dagger/gcp/resources.go (MapsDesiredState)
-	const targetDisplayName = "ChapaUY Geocoding Key"
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+func (s *Server) rejectWrites(ctx *gin.Context) {
+	if isWrite(ctx.Request) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": i18n.T("server is in read-only mode")})
 
-	req := &apikeyspb.ListKeysRequest{
-		Parent: fmt.Sprintf("projects/%s/locations/global", projectID),
+		return
 	}
 
-	it := client.ListKeys(ctx, req)
+	ctx.Next()
+}
 
-	for {
-		key, err := it.Next()
-		if errors.Is(err, iterator.Done) {
-			break
-		}
+// curatorKey is where authenticate leaves the curator in the gin context.
+const curatorKey = "curator"
 
-		if err != nil {
-			return "", fmt.Errorf("listing keys: %w", err)
+// requestToken returns the token of the request, the Authorization bearer.
+// Neither the URL, which ends up in the access log, nor a cookie, which the
+// browser sends on its own, carry it.
+func requestToken(ctx *gin.Context) string {
+	token, _ := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+
+	return strings.TrimSpace(token)
+}
+
+// authenticate identifies the curator of the request by its token. Without a
+// token the request is anonymous, which is only allowed when the tokens
+// aren't required: then every API call needs one, the reads and the query
+// console included.
+func (s *Server) authenticate(ctx *gin.Context) {
+	token := requestToken(ctx)
+	if token == "" {
+		if s.requireTokens && strings.HasPrefix(ctx.Request.URL.Path, "/api/") {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.T("a curator token is required")})
+
+			return
 		}
 
-		if key.DisplayName == targetDisplayName {
-			// Found it!
-			// ListKeys and GetKey redact the KeyString.
-			// We must use GetKeyString method to retrieve the secret.
-			log.Printf("Found key resource '%s', retrieving secret...", key.Name)
+		ctx.Next()
 
-			getReq := &apikeyspb.GetKeyStringRequest{
-				Name: key.Name,
-			}
+		return
+	}
 
-			resp, err := client.GetKeyString(ctx, getReq)
-			if err != nil {
-				return "", fmt.Errorf("getting key string: %w", err)
-			}
+	t, err := s.curatorRepo.Authenticate(token)
+	if errors.Is(err, ErrInvalidToken) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.T("invalid curator token")})
 
-			if resp.KeyString == "" {
-				return "", fmt.Errorf("key '%s' found but KeyString is still empty after GetKeyString", targetDisplayName)
-			}
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
-			return resp.KeyString, nil
-		}
+		return
 	}
 
-	return "", fmt.Errorf("key with display name '%s' not found in project %s", targetDisplayName, projectID)
+	ctx.Set(curatorKey, t.Curator)
+	ctx.Next()
+}
+
+// curatorOf returns the curator that authenticated the request, if any.
+func curatorOf(ctx *gin.Context) string {
+	return ctx.GetString(curatorKey)
 }
 
 func (s *Server) Run() error {
 	r := gin.Default()
+	if s.readOnly {
+		r.Use(s.rejectWrites)
+	}
+	if s.curatorRepo != nil {
+		r.Use(s.authenticate)
+	}
 	r.SetHTMLTemplate(template.Must(template.New("").ParseGlob("templates/*.html")))
 	r.Static("/static", "templates/static")
 
 	r.GET("/", s.geocodeView)
 	r.GET("/descriptions", s.descriptionsView)
 	r.GET("/review", s.reviewView)
+	r.GET("/query", s.queryView)
+	r.POST("/api/query", s.runQuery)
 	r.GET("/api/databases", s.listDatabases)
 	r.GET("/api/locations/queue", s.getLocationQueue)
+	r.POST("/api/locations/queue/next", s.nextInQueue)
+	r.POST("/api/locations/queue/skip", s.skipInQueue)
+	r.POST("/api/locations/queue/defer-until", s.deferInQueue)
 	r.POST("/api/locations/merge", s.mergeLocations)
+	r.POST("/api/locations/merge-cluster", s.mergeCluster)
+	r.POST("/api/locations/clusters/accept", s.acceptCluster)
+	r.GET("/api/canonical-locations", s.listCanonicalLocations)
+	r.POST("/api/canonical-locations", s.saveCanonicalLocation)
+	r.POST("/api/canonical-locations/link", s.linkCanonicalLocation)
 	r.GET("/api/locations/suggest/:db_id/*location", s.suggestCoordinates)
+	r.GET("/api/locations/deferred", s.listDeferredLocations)
 	r.POST("/api/locations/accept/:db_id/*location", s.acceptJudgment)
 	r.GET("/api/locations/progress", s.getProgress)
 	r.GET("/api/locations/judgments", s.listJudgments)
+	r.GET("/api/locations/:db_id/*location", s.getLocationOffenses) // .../offenses
 	r.GET("/api/descriptions/unclassified", s.getUnclassifiedDescriptions)
 	r.GET("/api/descriptions/articles", s.listArticles)
 	r.POST("/api/descriptions/classify", s.classifyDescription)
@@ -149,6 +221,29 @@ func (s *Server) Run() error {
 	r.POST("/api/descriptions/articles/add", s.addArticle)        // New endpoint
 	r.GET("/api/descriptions/articles/search", s.searchArticles)  // New endpoint
 	r.GET("/api/descriptions/suggest", s.suggestClassification)
+	r.GET("/api/descriptions/split", s.splitDescription)
+	r.GET("/api/descriptions/conflicts", s.listArticleConflicts)
+	r.POST("/api/descriptions/split", s.acceptSplit)
+	r.POST("/api/undo", s.undo)
+	r.GET("/api/headers/pending", s.listPendingHeaders)
+	r.POST("/api/headers/map", s.mapHeader)
+	r.GET("/api/values/pending", s.listPendingValues) // ?kind=country
+	r.POST("/api/countries/map", s.mapCountry)
+	r.GET("/api/vehicles/overrides", s.listVehicleOverrides)
+	r.POST("/api/vehicles/overrides", s.saveVehicleOverride)
+	r.POST("/api/vehicles/overrides/delete", s.deleteVehicleOverride)
+	r.GET("/api/streets/aliases", s.listStreetAliases)
+	r.POST("/api/streets/aliases", s.saveStreetAlias)
+	r.POST("/api/streets/aliases/delete", s.deleteStreetAlias)
+	r.GET("/api/cells/:cell", s.getCellStats)
+	r.GET("/api/stats/velocity", s.getVelocity)
+	r.GET("/api/ur-outliers", s.listUROutliers)
+	r.GET("/api/ur-outliers/stats", s.getURStats)
+	r.POST("/api/ur-outliers/resolve", s.resolveUROutlier)
+	r.GET("/api/extraction-failures", s.listExtractionFailures)
+	r.POST("/api/extraction-failures/resolve", s.resolveExtractionFailure)
+	r.GET("/api/documents/stats", s.getDocumentStats)      // ?db_id=
+	r.GET("/api/documents/:id/preview", s.previewDocument) // ?db_id=
 
 	return r.Run("localhost:8080")
 }
@@ -156,25 +251,51 @@ func (s *Server) Run() error {
 func (s *Server) suggestClassification(ctx *gin.Context) {
 	description := ctx.Query("description")
 	if description == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "description query parameter is required"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("description query parameter is required")})
 
 		return
 	}
 
 	articles, err := s.descriptionRepo.ListArticles()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list articles"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("failed to list articles")})
 
 		return
 	}
 
 	autoJudger := NewDescriptionClassifier(articles)
+
+	// an optional offense date picks the version of the articles in force then
+	if date := ctx.Query("date"); date != "" {
+		t, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid date parameter")})
+
+			return
+		}
+
+		ctx.JSON(http.StatusOK, autoJudger.SuggestAt(description, 0.5, t))
+
+		return
+	}
+
 	// I'll use a fixed threshold for the UI for now. 0.5 seems reasonable from previous results.
 	suggestions := autoJudger.Suggest(description, 0.5)
 
 	ctx.JSON(http.StatusOK, suggestions)
 }
 
+func (s *Server) listArticleConflicts(ctx *gin.Context) {
+	conflicts, err := s.descriptionRepo.ListArticleConflicts()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conflicts)
+}
+
 func (s *Server) geocodeView(ctx *gin.Context) {
 	ctx.HTML(http.StatusOK, "geocode.html", nil)
 }
@@ -195,7 +316,7 @@ func (s *Server) listDatabases(ctx *gin.Context) {
 	// Get all databases that have offenses with locations
 	sqlRepo, ok := s.geocodeRepo.(*sqlJudgmentRepository)
 	if !ok {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid repository type"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("invalid repository type")})
 
 		return
 	}
@@ -257,7 +378,7 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 		if dbIDParam != "" {
 			var id int
 			if _, err := fmt.Sscanf(dbIDParam, "%d", &id); err != nil {
-				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid db_id parameter"})
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id parameter")})
 
 				return
 			}
@@ -280,10 +401,33 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 	}
 
 	// Check for database filter
-	dbIDParam := ctx.Query("db_id")
+	var dbID *int
+
+	if dbIDParam := ctx.Query("db_id"); dbIDParam != "" {
+		var id int
+		if _, err := fmt.Sscanf(dbIDParam, "%d", &id); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id parameter")})
+
+			return
+		}
+
+		dbID = &id
+	}
+
+	items, err := s.queryLocationQueue(dbID, ctx.Query("sort"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
 
+// queryLocationQueue returns the pending locations, at most 1000. Sort is one
+// of "frequency" (default), "newest", "window_7" or "window_30".
+func (s *Server) queryLocationQueue(dbID *int, sort string) ([]LocationQueueItem, error) {
 	// Sorting params: support fixed window options
-	sort := ctx.Query("sort") // "frequency" (default), "newest", "window_7", "window_30"
 	windowDays := 0
 
 	switch sort {
@@ -300,18 +444,11 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 
 	whereClause := ""
 
-	if dbIDParam != "" {
+	if dbID != nil {
 		// Filter by specific database
-		var dbID int
-		if _, err := fmt.Sscanf(dbIDParam, "%d", &dbID); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid db_id parameter"})
-
-			return
-		}
-
 		whereClause = " AND o.db_id = ?"
 
-		args = append(args, dbID)
+		args = append(args, *dbID)
 	}
 
 	// Compute cutoff using Go and pass as SQL parameter (DuckDB supports casting)
@@ -330,9 +467,12 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 		FROM offenses o
 		LEFT JOIN locations lj
 			ON o.db_id = lj.db_id AND o.location = lj.location
+		LEFT JOIN deferred_locations dl
+			ON o.db_id = dl.db_id AND o.location = dl.location
 		WHERE o.location IS NOT NULL
 			AND o.location != ''
 			AND lj.id IS NULL  -- No judgment exists yet
+			AND (dl.db_id IS NULL OR dl.deferred_until <= ?)  -- Not deferred
 	` + whereClause + `
 		GROUP BY o.db_id, o.location
 	`
@@ -350,20 +490,16 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 	// Get DB handle via type assertion
 	sqlRepo, ok := s.geocodeRepo.(*sqlJudgmentRepository)
 	if !ok {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid repository type"})
-
-		return
+		return nil, errors.New(i18n.T("invalid repository type"))
 	}
 
 	// The cutoff placeholder appears before any WHERE placeholders, so ensure args order matches:
-	// cutoff first, then any db_id arg (if present).
-	args = append([]any{cutoffStr}, args...)
+	// cutoff first, then the deferral cutoff, then any db_id arg (if present).
+	args = append([]any{cutoffStr, time.Now()}, args...)
 
 	rows, err := sqlRepo.DB().Query(query, args...)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -376,9 +512,7 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 
 		var windowCount int
 		if err := rows.Scan(&item.DbID, &item.Location, &item.OffenseCount, &newest, &windowCount); err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-
-			return
+			return nil, err
 		}
 
 		// We intentionally do not expose newest/window values in the API response
@@ -393,13 +527,102 @@ func (s *Server) getLocationQueue(ctx *gin.Context) {
 		items = append(items, item)
 	}
 
-	if err := rows.Err(); err != nil {
+	return items, rows.Err()
+}
+
+type QueueNextRequest struct {
+	DbID *int   `json:"db_id"`
+	Sort string `json:"sort"`
+}
+
+type QueueNextResponse struct {
+	LocationQueueItem
+	LeaseUntil time.Time `json:"lease_until"`
+}
+
+// nextInQueue hands the curator the next pending location, reserving it for
+// QueueLease so nobody else gets it meanwhile.
+func (s *Server) nextInQueue(ctx *gin.Context) {
+	var req QueueNextRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+			return
+		}
+	}
+
+	pending, err := s.queryLocationQueue(req.DbID, req.Sort)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, items)
+	item, until := s.queue.next(curatorSession(ctx), pending)
+	if item == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("the queue is empty")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, QueueNextResponse{LocationQueueItem: *item, LeaseUntil: until})
+}
+
+type QueueItemRequest struct {
+	DbID     int       `json:"db_id"`
+	Location string    `json:"location"`
+	Until    time.Time `json:"until"` // only for defer-until
+}
+
+func (s *Server) skipInQueue(ctx *gin.Context) {
+	var req QueueItemRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Location == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("location is required")})
+
+		return
+	}
+
+	s.queue.skip(curatorSession(ctx), req.DbID, req.Location)
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// deferInQueue takes the location out of the queue, for every curator, until
+// the given time.
+func (s *Server) deferInQueue(ctx *gin.Context) {
+	var req QueueItemRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Location == "" || !req.Until.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("location and a future until are required")})
+
+		return
+	}
+
+	deferred := &DeferredLocation{
+		DbID:     req.DbID,
+		Location: req.Location,
+		Reason:   DeferReasonCurator,
+		Until:    req.Until,
+	}
+	if err := s.geocodeRepo.DeferLocation(deferred); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	s.queue.release(req.DbID, req.Location)
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "deferred": deferred})
 }
 
 type SuggestionResponse struct {
@@ -409,6 +632,9 @@ type SuggestionResponse struct {
 	GeocodingMethod string  `json:"geocoding_method"`
 	Confidence      string  `json:"confidence"`
 	Notes           string  `json:"notes"`
+	// Candidates are the judged locations written like this one, to merge
+	// it into one of them instead of geocoding it again.
+	Candidates []SimilarJudgment `json:"candidates,omitempty"`
 }
 
 func (s *Server) suggestCoordinates(ctx *gin.Context) {
@@ -417,18 +643,22 @@ func (s *Server) suggestCoordinates(ctx *gin.Context) {
 
 	var dbID int
 	if _, err := fmt.Sscanf(dbIDStr, "%d", &dbID); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid db_id"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id")})
 
 		return
 	}
 
-	if dbID == 56 { // Tacuarembó hack
-		re := regexp.MustCompile(`(?i)\s+FRENTE\s+AL\s+N°\s+`)
-		location = re.ReplaceAllString(location, " ")
+	// the judgment is saved for the location as written, only the lookups
+	// use the cleaned one
+	cleaned := impo.CleanLocation(dbID, location)
+
+	candidates, err := s.geocodeRepo.SimilarJudgments(dbID, location, SimilarJudgmentsTopN)
+	if err != nil {
+		log.Printf("Error finding judgments similar to %s: %v", location, err)
 	}
 
 	// Try RUTA pattern matching first
-	if radar, found := s.radarIndex.MatchLocation(location); found {
+	if radar, found := s.radarIndex.MatchLocation(cleaned); found {
 		ctx.JSON(http.StatusOK, SuggestionResponse{
 			Latitude:        radar.Point.Lat,
 			Longitude:       radar.Point.Lng,
@@ -436,6 +666,7 @@ func (s *Server) suggestCoordinates(ctx *gin.Context) {
 			GeocodingMethod: "radares_rutas",
 			Confidence:      "high",
 			Notes:           radar.Descrip,
+			Candidates:      candidates,
 		})
 
 		return
@@ -444,9 +675,19 @@ func (s *Server) suggestCoordinates(ctx *gin.Context) {
 	// Fallback to standard geocoding
 	department := s.dbMap[dbID]
 
-	result, err := s.geocoder.Geocode(location, department)
+	result, err := s.geocoder.Geocode(cleaned, department)
+	if err != nil && IsQuotaError(err) {
+		s.deferForQuota(ctx, dbID, location, err)
+
+		return
+	}
+
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "no suggestion available", "details": err.Error()})
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":      i18n.T("no suggestion available"),
+			"details":    err.Error(),
+			"candidates": candidates,
+		})
 
 		return
 	}
@@ -458,9 +699,49 @@ func (s *Server) suggestCoordinates(ctx *gin.Context) {
 		GeocodingMethod: result.Provider,
 		Confidence:      result.Confidence,
 		Notes:           result.DisplayName,
+		Candidates:      candidates,
+	})
+}
+
+// deferForQuota takes the location out of the queue until the geocoder is
+// expected to answer again, and tells the client when to come back.
+func (s *Server) deferForQuota(ctx *gin.Context, dbID int, location string, err error) {
+	retryAfter := RetryAfter(err)
+	if retryAfter <= 0 {
+		retryAfter = time.Minute
+	}
+
+	deferred := &DeferredLocation{
+		DbID:     dbID,
+		Location: location,
+		Reason:   DeferReasonGeocoderQuota,
+		Until:    time.Now().Add(retryAfter),
+	}
+	if deferErr := s.geocodeRepo.DeferLocation(deferred); deferErr != nil {
+		log.Printf("Error deferring %s: %v", location, deferErr)
+	}
+
+	secs := int(retryAfter.Round(time.Second).Seconds())
+	ctx.Header("Retry-After", strconv.Itoa(secs))
+	ctx.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":       i18n.T("geocoder quota exceeded, the location was deferred"),
+		"details":     err.Error(),
+		"retry_after": secs,
+		"deferred":    deferred,
 	})
 }
 
+func (s *Server) listDeferredLocations(ctx *gin.Context) {
+	deferred, err := s.geocodeRepo.ListDeferredLocations()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deferred)
+}
+
 type AcceptJudgmentRequest struct {
 	Latitude        float64 `json:"latitude"`
 	Longitude       float64 `json:"longitude"`
@@ -468,6 +749,19 @@ type AcceptJudgmentRequest struct {
 	GeocodingMethod string  `json:"geocoding_method"`
 	Confidence      string  `json:"confidence"`
 	Notes           string  `json:"notes"`
+	// VerifyAddress reverse geocodes the coordinates and warns when their
+	// streets have nothing in common with the location.
+	VerifyAddress bool `json:"verify_address"`
+	// CanonicalLocation merges the judgment into an existing one, like a
+	// candidate of the suggestion, taking its coordinates.
+	CanonicalLocation string `json:"canonical_location,omitempty"`
+}
+
+// AcceptJudgmentResponse is the answer to an accepted judgment. The address
+// check is only there when it was asked for and could be done.
+type AcceptJudgmentResponse struct {
+	Success      bool          `json:"success"`
+	AddressCheck *AddressCheck `json:"address_check,omitempty"`
 }
 
 func (s *Server) acceptJudgment(ctx *gin.Context) {
@@ -476,7 +770,7 @@ func (s *Server) acceptJudgment(ctx *gin.Context) {
 
 	var dbID int
 	if _, err := fmt.Sscanf(dbIDStr, "%d", &dbID); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid db_id"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id")})
 
 		return
 	}
@@ -502,22 +796,83 @@ func (s *Server) acceptJudgment(ctx *gin.Context) {
 		GeocodingMethod: req.GeocodingMethod,
 		Confidence:      req.Confidence,
 		Notes:           req.Notes,
+		Curator:         curatorOf(ctx),
+	}
+
+	if req.CanonicalLocation != "" {
+		judgments, err := s.geocodeRepo.ListJudgments(&dbID, &req.CanonicalLocation, 1, 0)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+			return
+		}
+
+		if len(judgments) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("canonical judgment not found")})
+
+			return
+		}
+
+		canonical := judgments[0]
+
+		// merged into the canonical one of a merged judgment, as the
+		// clusters are
+		judgment.CanonicalLocation = cmp.Or(canonical.CanonicalLocation, canonical.Location)
+		judgment.Point = canonical.Point
+		judgment.IsElectronic = canonical.IsElectronic
 	}
 
 	// Validar judgment antes de guardar
 	if err := validateJudgment(judgment); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("validación falló: %v", err)})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.Sprintf("validación falló: %v", err)})
+
+		return
+	}
+
+	changes, err := snapshotLocations(s.geocodeRepo, dbID, location)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
 	if err := s.geocodeRepo.SaveJudgment(judgment); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("error al guardar: %v", err)})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Sprintf("error al guardar: %v", err)})
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"success": true})
+	s.recordAction(ctx, AuditAcceptJudgment, changes)
+
+	if s.queue != nil {
+		s.queue.release(dbID, location)
+	}
+
+	resp := AcceptJudgmentResponse{Success: true}
+	if req.VerifyAddress {
+		resp.AddressCheck = s.verifyAddress(dbID, location, judgment.Point)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// verifyAddress compares the streets at the point with the location, to
+// catch the obviously wrong picks, like a street with the same name in
+// another city. It only warns: the judgment is saved anyway.
+func (s *Server) verifyAddress(dbID int, location string, point *spatial.Point) *AddressCheck {
+	rev, err := s.reverseGeocoder.ReverseGeocode(point.Lat, point.Lng)
+	if err != nil {
+		log.Printf("Error reverse geocoding %s: %v", location, err)
+
+		return nil
+	}
+
+	check := checkAddress(impo.CleanLocation(dbID, location), rev)
+	if !check.Matches {
+		check.Warning = i18n.Sprintf("the streets at the coordinates (%s) have nothing in common with the location", rev.Address)
+	}
+
+	return check
 }
 
 type ProgressResponse struct {
@@ -546,7 +901,7 @@ func (s *Server) getProgress(ctx *gin.Context) {
 
 	sqlRepo, ok := s.geocodeRepo.(*sqlJudgmentRepository)
 	if !ok {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid repository type"})
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("invalid repository type")})
 
 		return
 	}
@@ -561,7 +916,7 @@ func (s *Server) getProgress(ctx *gin.Context) {
 	if dbIDParam != "" {
 		var dbID int
 		if _, err := fmt.Sscanf(dbIDParam, "%d", &dbID); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid db_id parameter"})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id parameter")})
 
 			return
 		}
@@ -800,101 +1155,835 @@ func (s *Server) mergeLocations(ctx *gin.Context) {
 		return
 	}
 
+	changes, err := snapshotLocations(s.geocodeRepo, req.DbID, req.TargetLocation)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
 	if err := s.geocodeRepo.MergeLocations(req.DbID, req.TargetLocation, req.CanonicalLocation); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
+	s.recordAction(ctx, AuditMergeLocations, changes)
 	ctx.JSON(http.StatusOK, gin.H{"success": true})
 }
 
-func (s *Server) descriptionsView(ctx *gin.Context) {
-	ctx.HTML(http.StatusOK, "descriptions.html", nil)
+type MergeClusterRequest struct {
+	DbID              int      `json:"db_id"`
+	CanonicalLocation string   `json:"canonical_location"`
+	Locations         []string `json:"locations"`
 }
 
-func (s *Server) reviewView(ctx *gin.Context) {
-	data, err := s.descriptionRepo.GetReviewAssignments()
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func (s *Server) mergeCluster(ctx *gin.Context) {
+	var req MergeClusterRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	ctx.HTML(http.StatusOK, "descriptions_review.html", gin.H{
-		"Codes": data,
-	})
-}
+	if req.CanonicalLocation == "" || len(req.Locations) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("canonical_location and locations are required")})
 
-func (s *Server) getUnclassifiedDescriptions(ctx *gin.Context) {
-	limit := 1000 // Default limit
+		return
+	}
 
-	descriptions, err := s.descriptionRepo.GetUnclassifiedDescriptions(limit)
+	changes, err := snapshotLocations(s.geocodeRepo, req.DbID, req.Locations...)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, descriptions)
-}
+	results, err := s.geocodeRepo.MergeCluster(req.DbID, req.CanonicalLocation, req.Locations)
+	if errors.Is(err, ErrMergeFailed) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "error": err.Error(), "results": results})
 
-func (s *Server) listArticles(ctx *gin.Context) {
-	articles, err := s.descriptionRepo.ListArticles()
-	if err != nil {
+		return
+	} else if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, articles)
+	s.recordAction(ctx, AuditMergeCluster, changes)
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "results": results})
 }
 
-type ClassifyRequest struct {
-	Description string   `json:"description"`
-	ArticleIDs  []string `json:"article_ids"`
+type AcceptClusterRequest struct {
+	ClusterID string `json:"cluster_id"`
+	Principal string `json:"principal"`
+	DbID      *int   `json:"db_id,omitempty"` // narrows the lookup of the cluster
 }
 
-func (s *Server) classifyDescription(ctx *gin.Context) {
-	var req ClassifyRequest
+// acceptCluster merges every location of a cluster listed by the cluster mode
+// of the queue into the principal chosen by the curator, and marks the
+// cluster resolved so that it isn't listed again.
+func (s *Server) acceptCluster(ctx *gin.Context) {
+	var req AcceptClusterRequest
 	if err := ctx.BindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	err := s.descriptionRepo.SaveDescriptionClassification(req.Description, req.ArticleIDs)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if req.ClusterID == "" || req.Principal == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("cluster_id and principal are required")})
 
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"success": true})
-}
-
-func (s *Server) addArticle(c *gin.Context) {
-	var req Article
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	cluster, err := s.geocodeRepo.FindLocationCluster(req.DbID, req.ClusterID)
+	if errors.Is(err, ErrClusterNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 
 		return
-	}
-
-	err := s.descriptionRepo.AddArticle(req.ID, req.Text, req.Code, req.Title)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true})
-}
+	var changes []AuditChange
 
-func (s *Server) searchArticles(c *gin.Context) {
-	query := c.Query("query")
-	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter is required"})
+	for _, l := range cluster.Locations {
+		if l.Description == req.Principal {
+			continue
+		}
+
+		c, err := snapshotLocations(s.geocodeRepo, l.DbID, l.Description)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+			return
+		}
+
+		changes = append(changes, c...)
+	}
+
+	results, err := s.geocodeRepo.AcceptCluster(cluster, req.Principal)
+	if errors.Is(err, ErrPrincipalNotInCluster) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if errors.Is(err, ErrMergeFailed) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "error": err.Error(), "results": results})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	s.recordAction(ctx, AuditAcceptCluster, changes)
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "results": results})
+}
+
+func (s *Server) listCanonicalLocations(ctx *gin.Context) {
+	locations, err := s.geocodeRepo.ListCanonicalLocations()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, locations)
+}
+
+func (s *Server) saveCanonicalLocation(ctx *gin.Context) {
+	var req CanonicalLocation
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Name == "" || req.Point == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("name and point are required")})
+
+		return
+	}
+
+	if err := s.geocodeRepo.SaveCanonicalLocation(&req); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+type LinkCanonicalLocationRequest struct {
+	DbID     int    `json:"db_id"`
+	Location string `json:"location"`
+	Name     string `json:"name"`
+}
+
+func (s *Server) linkCanonicalLocation(ctx *gin.Context) {
+	var req LinkCanonicalLocationRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Location == "" || req.Name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("location and name are required")})
+
+		return
+	}
+
+	changes, err := snapshotLocations(s.geocodeRepo, req.DbID, req.Location)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	err = s.geocodeRepo.LinkCanonicalLocation(req.DbID, req.Location, req.Name)
+	if errors.Is(err, ErrCanonicalLocationNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	s.recordAction(ctx, AuditLinkCanonical, changes)
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) descriptionsView(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "descriptions.html", nil)
+}
+
+func (s *Server) reviewView(ctx *gin.Context) {
+	data, err := s.descriptionRepo.GetReviewAssignments()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.HTML(http.StatusOK, "descriptions_review.html", gin.H{
+		"Codes": data,
+	})
+}
+
+func (s *Server) getUnclassifiedDescriptions(ctx *gin.Context) {
+	limit := 1000 // Default limit
+
+	descriptions, err := s.descriptionRepo.GetUnclassifiedDescriptions(limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, descriptions)
+}
+
+func (s *Server) listArticles(ctx *gin.Context) {
+	articles, err := s.descriptionRepo.ListArticles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, articles)
+}
+
+type ClassifyRequest struct {
+	Description string   `json:"description"`
+	ArticleIDs  []string `json:"article_ids"`
+}
+
+func (s *Server) classifyDescription(ctx *gin.Context) {
+	var req ClassifyRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	changes, err := snapshotDescription(s.descriptionRepo, req.Description)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	err = s.descriptionRepo.SaveDescriptionClassification(req.Description, req.ArticleIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	s.recordAction(ctx, AuditClassify, changes)
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// SplitThreshold is the minimum score of the per part suggestions offered
+// when splitting a composite description.
+const SplitThreshold = 0.5
+
+// SplitResponse is a composite description broken into its parts, with the
+// suggestions for each one.
+type SplitResponse struct {
+	Description string                `json:"description"`
+	Parts       []SuggestionBreakdown `json:"parts"`
+}
+
+func (s *Server) splitClassifier() (*DescriptionClassifier, error) {
+	articles, err := s.descriptionRepo.ListArticles()
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions, err := s.descriptionRepo.GetAllDescriptionJudgmentsSorted()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDescriptionClassifierWithDescriptions(articles, descriptions), nil
+}
+
+func (s *Server) splitDescription(ctx *gin.Context) {
+	description := ctx.Query("description")
+	if description == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("description query parameter is required")})
+
+		return
+	}
+
+	classifier, err := s.splitClassifier()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("failed to list articles")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SplitResponse{
+		Description: description,
+		Parts:       classifier.SuggestWithBreakdown(description, SplitThreshold),
+	})
+}
+
+// SplitRequest accepts the parts of a composite description. When Parts is
+// empty the best suggestion of every part is accepted.
+type SplitRequest struct {
+	Description string            `json:"description"`
+	Parts       []DescriptionPart `json:"parts"`
+}
+
+func (s *Server) acceptSplit(ctx *gin.Context) {
+	var req SplitRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Description == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("description is required")})
+
+		return
+	}
+
+	if len(req.Parts) == 0 {
+		classifier, err := s.splitClassifier()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": i18n.T("failed to list articles")})
+
+			return
+		}
+
+		req.Parts, err = BestParts(classifier.SuggestWithBreakdown(req.Description, SplitThreshold))
+		if err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+
+			return
+		}
+	}
+
+	changes, err := snapshotSplit(s.descriptionRepo, req.Description, req.Parts)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if err := s.descriptionRepo.SaveCompositeClassification(req.Description, req.Parts); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	s.recordAction(ctx, AuditSplit, changes)
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "parts": req.Parts})
+}
+
+func (s *Server) queryView(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "query.html", nil)
+}
+
+type QueryRequest struct {
+	SQL string `json:"sql"`
+}
+
+func (s *Server) runQuery(ctx *gin.Context) {
+	var req QueryRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	result, err := RunReadOnlyQuery(ctx.Request.Context(), s.db, req.SQL)
+	if errors.Is(err, ErrStatementNotAllowed) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// PendingHeadersResponse lists the headers to map and the properties they
+// can be mapped to.
+type PendingHeadersResponse struct {
+	Headers    []PendingHeader `json:"headers"`
+	Properties []string        `json:"properties"`
+}
+
+func (s *Server) listPendingHeaders(ctx *gin.Context) {
+	headers, err := s.headerRepo.ListPendingHeaders()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PendingHeadersResponse{Headers: headers, Properties: impo.OffensePropertyNames()})
+}
+
+type MapHeaderRequest struct {
+	Header   string `json:"header"`
+	Property string `json:"property"`
+}
+
+func (s *Server) mapHeader(ctx *gin.Context) {
+	var req MapHeaderRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Header == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("header and property are required")})
+
+		return
+	}
+
+	docs, err := s.headerRepo.MapHeader(req.Header, req.Property)
+	if errors.Is(err, ErrInvalidProperty) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "documents": docs})
+}
+
+func (s *Server) listPendingValues(ctx *gin.Context) {
+	values, err := s.valueRepo.ListPendingValues(ctx.Query("kind"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"values": values})
+}
+
+// MapCountryRequest maps a spelling of a country to its ISO code, "" for
+// another country.
+type MapCountryRequest struct {
+	Spelling string `json:"spelling"`
+	ISO      string `json:"iso"`
+}
+
+func (s *Server) mapCountry(ctx *gin.Context) {
+	var req MapCountryRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Spelling == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("spelling is required")})
+
+		return
+	}
+
+	docs, err := s.valueRepo.MapCountry(req.Spelling, req.ISO)
+	if errors.Is(err, ErrInvalidCountry) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "documents": docs})
+}
+
+// VehicleOverridesResponse lists the reviewed overrides of the vehicle types,
+// built in, and the ones of the curators, which win.
+type VehicleOverridesResponse struct {
+	Reviewed []impo.VehicleOverride `json:"reviewed"`
+	Curated  []impo.VehicleOverride `json:"curated"`
+}
+
+func (s *Server) listVehicleOverrides(ctx *gin.Context) {
+	reviewed, err := impo.DefaultVehicleOverrides()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	curated, err := s.vehicleRepo.ListVehicleOverrides()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, VehicleOverridesResponse{Reviewed: reviewed, Curated: curated})
+}
+
+func (s *Server) saveVehicleOverride(ctx *gin.Context) {
+	var req impo.VehicleOverride
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	override, err := s.vehicleRepo.SaveVehicleOverride(req)
+	if errors.Is(err, impo.ErrInvalidVehicleOverride) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "override": override})
+}
+
+type DeleteVehicleOverrideRequest struct {
+	Country string `json:"country"`
+	Prefix  string `json:"prefix"`
+}
+
+func (s *Server) deleteVehicleOverride(ctx *gin.Context) {
+	var req DeleteVehicleOverrideRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if req.Country == "" {
+		req.Country = impo.ISOUruguay
+	}
+
+	deleted, err := s.vehicleRepo.DeleteVehicleOverride(req.Country, req.Prefix)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if !deleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("vehicle override not found")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// StreetAliasesResponse lists the reviewed old names of the renamed streets,
+// built in, and the ones of the curators, which win.
+type StreetAliasesResponse struct {
+	Reviewed []impo.StreetAlias `json:"reviewed"`
+	Curated  []impo.StreetAlias `json:"curated"`
+}
+
+func (s *Server) listStreetAliases(ctx *gin.Context) {
+	reviewed, err := impo.DefaultStreetAliases()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	curated, err := s.streetRepo.ListStreetAliases()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, StreetAliasesResponse{Reviewed: reviewed, Curated: curated})
+}
+
+func (s *Server) saveStreetAlias(ctx *gin.Context) {
+	var req impo.StreetAlias
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	alias, err := s.streetRepo.SaveStreetAlias(req)
+	if errors.Is(err, impo.ErrInvalidStreetAlias) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "alias": alias})
+}
+
+type DeleteStreetAliasRequest struct {
+	DbID int    `json:"db_id"`
+	Old  string `json:"old"`
+}
+
+func (s *Server) deleteStreetAlias(ctx *gin.Context) {
+	var req DeleteStreetAliasRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	deleted, err := s.streetRepo.DeleteStreetAlias(req.DbID, req.Old)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	if !deleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("street alias not found")})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// CellStatsTopN is the default number of locations and articles of a cell.
+const CellStatsTopN = 10
+
+func (s *Server) getCellStats(ctx *gin.Context) {
+	resolution, resErr := strconv.Atoi(ctx.DefaultQuery("resolution", "0"))
+	topN, topErr := strconv.Atoi(ctx.DefaultQuery("top", strconv.Itoa(CellStatsTopN)))
+
+	if resErr != nil || topErr != nil || topN < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid resolution or top parameter")})
+
+		return
+	}
+
+	stats, err := s.cellRepo.CellStats(ctx.Param("cell"), resolution, topN)
+	if errors.Is(err, ErrInvalidCell) || errors.Is(err, ErrInvalidResolution) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// getVelocity reports how fast the queues are being emptied over the last
+// days (28 by default).
+func (s *Server) getVelocity(ctx *gin.Context) {
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", strconv.Itoa(DefaultVelocityDays)))
+	if err != nil || days < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid days parameter")})
+
+		return
+	}
+
+	stats, err := s.velocityRepo.Velocity(time.Now(), days)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// getLocationOffenses serves /api/locations/:db_id/<location>/offenses, the
+// location being a wildcard it can't be followed by another segment.
+func (s *Server) getLocationOffenses(ctx *gin.Context) {
+	location, ok := strings.CutSuffix(strings.TrimPrefix(ctx.Param("location"), "/"), "/offenses")
+	if !ok || location == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("not found")})
+
+		return
+	}
+
+	dbID, dbErr := strconv.Atoi(ctx.Param("db_id"))
+	if dbErr != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id")})
+
+		return
+	}
+
+	page, pageErr := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	perPage, perPageErr := strconv.Atoi(ctx.DefaultQuery("per_page", strconv.Itoa(LocationOffensesPerPage)))
+
+	if pageErr != nil || perPageErr != nil || page < 1 || perPage < 1 || perPage > LocationOffensesMaxPerPage {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid page or per_page parameter")})
+
+		return
+	}
+
+	timeline, err := s.timelineRepo.LocationTimeline(dbID, location, page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timeline)
+}
+
+// curatorSession identifies the session an action belongs to: the curator of
+// the token or, for the anonymous requests, the client IP. It's never taken
+// from the request, so that nobody acts on, or undoes, another's session.
+func curatorSession(ctx *gin.Context) string {
+	if curator := curatorOf(ctx); curator != "" {
+		return curator
+	}
+
+	return ctx.ClientIP()
+}
+
+// recordAction appends a successful action to the audit trail. A failure is
+// only logged: the action was already applied.
+func (s *Server) recordAction(ctx *gin.Context, action string, changes []AuditChange) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	a := &AuditAction{Session: curatorSession(ctx), Action: action, Changes: changes}
+	if err := s.auditRepo.RecordAction(a); err != nil {
+		log.Printf("Error recording %s in the audit trail: %v", action, err)
+	}
+
+	if curator := curatorOf(ctx); curator != "" && s.curatorRepo != nil {
+		if err := s.curatorRepo.StampJudgments(curator, changes); err != nil {
+			log.Printf("Error recording the curator of %s: %v", action, err)
+		}
+	}
+}
+
+type UndoRequest struct {
+	Count int `json:"count"`
+}
+
+func (s *Server) undo(ctx *gin.Context) {
+	req := UndoRequest{Count: 1}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+			return
+		}
+	}
+
+	if req.Count < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("count must be positive")})
+
+		return
+	}
+
+	undone, err := Undo(s.auditRepo, s.geocodeRepo, s.descriptionRepo, curatorSession(ctx), req.Count)
+	if errors.Is(err, ErrNothingToUndo) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("nothing to undo")})
+
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "undone": undone})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "undone": undone})
+}
+
+func (s *Server) addArticle(c *gin.Context) {
+	var req Article
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	err := s.descriptionRepo.AddArticle(req.ID, req.Text, req.Code, req.Title)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) searchArticles(c *gin.Context) {
+	query := c.Query("query")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("query parameter is required")})
 
 		return
 	}
@@ -908,3 +1997,206 @@ func (s *Server) searchArticles(c *gin.Context) {
 
 	c.JSON(http.StatusOK, articles)
 }
+
+// URStatsMinCount is the number of offenses an article needs for its UR
+// distribution to be trusted.
+const URStatsMinCount = 20
+
+func (s *Server) listUROutliers(ctx *gin.Context) {
+	status := ctx.DefaultQuery("status", OutlierPending)
+
+	outliers, err := s.outlierRepo.ListUROutliers(status, 500)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, outliers)
+}
+
+func (s *Server) getURStats(ctx *gin.Context) {
+	stats, err := s.outlierRepo.ComputeURStats(URStatsMinCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+type ResolveUROutlierRequest struct {
+	DocSource string `json:"doc_source"`
+	RecordID  int    `json:"record_id"`
+	Status    string `json:"status"`
+}
+
+func (s *Server) resolveUROutlier(ctx *gin.Context) {
+	var req ResolveUROutlierRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	err := s.outlierRepo.ResolveUROutlier(req.DocSource, req.RecordID, req.Status)
+	switch {
+	case errors.Is(err, ErrInvalidOutlierStatus):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrOutlierNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"success": true})
+	}
+}
+
+func (s *Server) listExtractionFailures(ctx *gin.Context) {
+	status := ctx.DefaultQuery("status", impo.FailurePending)
+
+	failures, err := s.failureRepo.ListExtractionFailures(status, 500)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	ctx.JSON(http.StatusOK, failures)
+}
+
+type ResolveExtractionFailureRequest struct {
+	DocSource string `json:"doc_source"`
+	Action    string `json:"action"`
+}
+
+func (s *Server) resolveExtractionFailure(ctx *gin.Context) {
+	var req ResolveExtractionFailureRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	err := s.failureRepo.ResolveExtractionFailure(req.DocSource, req.Action)
+	switch {
+	case errors.Is(err, ErrInvalidFailureAction):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrFailureNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"success": true})
+	}
+}
+
+// getDocumentStats counts, for each database, the documents published by IMPO
+// and extracted by month, flagging the months without documents, see
+// impo.ListDocumentStats. db_id, an id or a name, picks a single database.
+func (s *Server) getDocumentStats(ctx *gin.Context) {
+	var dbRefs []impo.DbReference
+
+	if v := ctx.Query("db_id"); v != "" {
+		dbRef, err := impo.Find(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+			return
+		}
+
+		dbRefs = append(dbRefs, *dbRef)
+	} else if err := impo.Each(func(dbRef impo.DbReference) error {
+		dbRefs = append(dbRefs, dbRef)
+
+		return nil
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	repo, err := impo.NewSQLOffenseRepository(s.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	now := time.Now()
+	stats := make([]*impo.DocumentStats, 0, len(dbRefs))
+
+	for i := range dbRefs {
+		st, err := impo.ListDocumentStats(impo.NewFileStore(s.documentsPath, &dbRefs[i]), repo, now)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+			return
+		}
+
+		stats = append(stats, st)
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// PreviewTimeout is the maximum time to extract a document for a preview, as
+// the default of impo update.
+const PreviewTimeout = 2 * time.Minute
+
+// previewDocument extracts a stored document again, e.g. after mapping its
+// headers, to check the rows it would produce now before re-extracting it.
+// The id is the end of its URL, like 2933-2024, and db_id picks the database
+// when several have it.
+func (s *Server) previewDocument(ctx *gin.Context) {
+	var dbID int
+
+	if v := ctx.Query("db_id"); v != "" {
+		var err error
+		if dbID, err = strconv.Atoi(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": i18n.T("invalid db_id")})
+
+			return
+		}
+	}
+
+	dbRef, source, err := impo.FindStoredDocument(s.documentsPath, dbID, ctx.Param("id"))
+	switch {
+	case errors.Is(err, impo.ErrDocumentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("document not found")})
+
+		return
+	case errors.Is(err, impo.ErrAmbiguousDocument):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	repo, err := impo.NewSQLOffenseRepository(s.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+		return
+	}
+
+	client := impo.NewImpoClient(&impo.ClientOptions{
+		ArchivePath:        s.documentsPath,
+		ExtractTimeout:     PreviewTimeout,
+		ExtractStreamBytes: impo.DefaultStreamBytes,
+	}, dbRef, repo)
+
+	preview, err := client.PreviewDocument(source)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": i18n.T("document not found")})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, preview)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -22,8 +24,16 @@ import (
 // MockLocationRepository is a mock implementation of LocationRepository for testing.
 type MockLocationRepository struct{}
 
-func (m *MockLocationRepository) CreateSchema() error            { return nil }
-func (m *MockLocationRepository) SaveJudgment(_ *Location) error { return nil }
+// sessionIPs are the client IPs of the anonymous sessions of the tests.
+var sessionIPs = map[string]string{"alice": "192.0.2.1", "bob": "192.0.2.2", "carol": "192.0.2.3"}
+
+func sessionAddr(session string) string {
+	return sessionIPs[session] + ":1234"
+}
+
+func (m *MockLocationRepository) CreateSchema() error                  { return nil }
+func (m *MockLocationRepository) SaveJudgment(_ *Location) error       { return nil }
+func (m *MockLocationRepository) DeleteJudgment(_ int, _ string) error { return nil }
 func (m *MockLocationRepository) GetJudgment(_ int, _ string) (*Location, error) {
 	return nil, sql.ErrNoRows
 }
@@ -35,6 +45,53 @@ func (m *MockLocationRepository) CountJudgments() (int, error) { return 0, nil }
 func (m *MockLocationRepository) MergeLocations(_ int, _, _ string) error {
 	return nil
 }
+func (m *MockLocationRepository) MergeCluster(_ int, _ string, locations []string) ([]MergeResult, error) {
+	results := make([]MergeResult, 0, len(locations))
+	for _, l := range locations {
+		results = append(results, MergeResult{Location: l, Merged: true})
+	}
+
+	return results, nil
+}
+func (m *MockLocationRepository) FindLocationCluster(_ *int, id string) (*LocationCluster, error) {
+	if id != "abc" {
+		return nil, ErrClusterNotFound
+	}
+
+	return &LocationCluster{ID: id, DbID: 1, Locations: []*ClusterLocation{
+		{DbID: 1, Description: "A"}, {DbID: 1, Description: "B"},
+	}}, nil
+}
+func (m *MockLocationRepository) AcceptCluster(_ *LocationCluster, principal string) ([]MergeResult, error) {
+	if principal != "A" {
+		return nil, ErrPrincipalNotInCluster
+	}
+
+	return []MergeResult{{Location: "B", Merged: true}}, nil
+}
+func (m *MockLocationRepository) SaveCanonicalLocation(_ *CanonicalLocation) error {
+	return nil
+}
+func (m *MockLocationRepository) ListCanonicalLocations() ([]*CanonicalLocation, error) {
+	return nil, nil
+}
+func (m *MockLocationRepository) LinkCanonicalLocation(_ int, _, name string) error {
+	if name == "missing" {
+		return ErrCanonicalLocationNotFound
+	}
+
+	return nil
+}
+func (m *MockLocationRepository) DeferLocation(_ *DeferredLocation) error  { return nil }
+func (m *MockLocationRepository) BackfillNearestPlaces() (int64, error)    { return 0, nil }
+func (m *MockLocationRepository) MergeMirroredIntersections() (int, error) { return 0, nil }
+func (m *MockLocationRepository) SimilarJudgments(_ int, _ string, _ int) ([]SimilarJudgment, error) {
+	return nil, nil
+}
+func (m *MockLocationRepository) ListDeferredLocations() ([]*DeferredLocation, error) {
+	return nil, nil
+}
+
 func (m *MockLocationRepository) GetLocationClusters(_ *int) ([]*LocationCluster, error) {
 	return nil, nil
 }
@@ -53,7 +110,7 @@ func setupServerTest(t *testing.T) (*gin.Engine, *Server, *sql.DB, DescriptionRe
 	geocodeRepo := &MockLocationRepository{}
 	radarIndex := &RadarIndex{radars: make(map[string]*Radar)} // Initialize empty RadarIndex
 
-	server := NewServer(geocodeRepo, db, radarIndex, map[int]string{}) // Pass db directly
+	server := NewServer(geocodeRepo, db, radarIndex, map[int]string{}, "") // Pass db directly
 
 	// Register API routes
 	// Note: listDatabases is removed
@@ -65,6 +122,8 @@ func setupServerTest(t *testing.T) (*gin.Engine, *Server, *sql.DB, DescriptionRe
 	router.POST("/api/descriptions/articles/add", server.addArticle)
 	router.GET("/api/descriptions/articles/search", server.searchArticles)
 	router.GET("/api/descriptions/suggest", server.suggestClassification)
+	router.GET("/api/descriptions/split", server.splitDescription)
+	router.POST("/api/descriptions/split", server.acceptSplit)
 
 	return router, server, db, descriptionRepo
 }
@@ -102,6 +161,55 @@ func TestSuggestClassificationAPI(t *testing.T) {
 	assert.Contains(t, foundIDs, "21.3.1")
 }
 
+func TestSplitDescriptionAPI(t *testing.T) {
+	router, server, db, repo := setupServerTest(t)
+	defer db.Close()
+
+	require.NoError(t, server.auditRepo.CreateSchema())
+
+	require.NoError(t, repo.AddArticle("18.9.2", "Estacionar en lugar tarifado sin abonar la tarifa correspondiente.", 18, "Estacionamiento"))
+	require.NoError(t, repo.AddArticle("21.3.1", "Conductor o acompañante sin casco protector.", 21, "Seguridad"))
+	require.NoError(t, repo.SaveDescriptionClassification("SIN LIBRETA", []string{"G.3"}))
+
+	composite := "ESTACIONADO SIN ABONAR TARIFA, CONDUCTOR SIN CASCO, SIN LIBRETA"
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/descriptions/split?description="+url.QueryEscape(composite), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var split SplitResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &split))
+	require.Len(t, split.Parts, 3)
+	assert.Equal(t, "SIN LIBRETA", split.Parts[2].Part)
+	require.Len(t, split.Parts[2].Suggestions, 1)
+	assert.InDelta(t, 1.0, split.Parts[2].Suggestions[0].Score, 0)
+
+	// accepting without parts takes the best suggestion of each one
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/descriptions/split", bytes.NewBufferString(fmt.Sprintf(`{"description": %q}`, composite)))
+	req.RemoteAddr = sessionAddr("alice")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	d, err := repo.GetDescriptionWithArticles(composite)
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, []string{"18.9.2", "21.3.1", "G.3"}, d.ArticleIDs)
+
+	d, err = repo.GetDescriptionWithArticles("CONDUCTOR SIN CASCO")
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, []string{"21.3.1"}, d.ArticleIDs)
+
+	// the whole split is a single action in the audit trail
+	actions, err := server.auditRepo.LastActions(sessionIPs["alice"], 10)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, AuditSplit, actions[0].Action)
+	assert.Len(t, actions[0].Changes, 4)
+}
+
 func TestGetUnclassifiedDescriptionsAPI(t *testing.T) {
 	router, _, db, repo := setupServerTest(t)
 	defer db.Close()
@@ -355,7 +463,7 @@ func TestGetGeocodingProgressAPI(t *testing.T) {
 	// Use real repository
 	geocodeRepo := NewLocationRepository(db, map[int]string{})
 	radarIndex := &RadarIndex{radars: make(map[string]*Radar)}
-	server := NewServer(geocodeRepo, db, radarIndex, map[int]string{})
+	server := NewServer(geocodeRepo, db, radarIndex, map[int]string{}, "")
 
 	router.GET("/api/locations/progress", server.getProgress)
 
@@ -508,4 +616,286 @@ func TestGetLocationQueueOrdering(t *testing.T) {
 		assert.Equal(t, "A", items[1].Location)
 		assert.Equal(t, "C", items[2].Location)
 	}
+
+	// 4) Deferred locations leave the queue until their deferral expires
+	require.NoError(t, server.geocodeRepo.DeferLocation(&DeferredLocation{
+		DbID: 1, Location: "B", Reason: DeferReasonGeocoderQuota, Until: now.Add(time.Hour),
+	}))
+	require.NoError(t, server.geocodeRepo.DeferLocation(&DeferredLocation{
+		DbID: 1, Location: "C", Reason: DeferReasonGeocoderQuota, Until: now.Add(-time.Hour),
+	}))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/locations/queue", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	items = []LocationQueueItem{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+
+	locations := make([]string, 0, len(items))
+	for _, item := range items {
+		locations = append(locations, item.Location)
+	}
+
+	assert.Equal(t, []string{"A", "C"}, locations)
+
+	deferred, err := server.geocodeRepo.ListDeferredLocations()
+	require.NoError(t, err)
+
+	if assert.Len(t, deferred, 1) {
+		assert.Equal(t, "B", deferred[0].Location)
+	}
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{}
+	server.SetReadOnly(true)
+
+	router := gin.New()
+	router.Use(server.rejectWrites)
+	router.GET("/api/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/test", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/test", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// the query console only reads
+	router.POST("/api/query", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/query", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMergeClusterAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	server := &Server{geocodeRepo: &MockLocationRepository{}}
+	router.POST("/api/locations/merge-cluster", server.mergeCluster)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/locations/merge-cluster", bytes.NewBufferString(
+		`{"db_id": 1, "canonical_location": "A", "locations": ["B", "C"]}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Success bool          `json:"success"`
+		Results []MergeResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Results, 2)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/locations/merge-cluster", bytes.NewBufferString(
+		`{"db_id": 1, "canonical_location": "A"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAcceptClusterAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	server := &Server{geocodeRepo: &MockLocationRepository{}}
+	router.POST("/api/locations/clusters/accept", server.acceptCluster)
+
+	for body, want := range map[string]int{
+		`{"cluster_id": "abc", "principal": "A"}`: http.StatusOK,
+		`{"cluster_id": "abc", "principal": "C"}`: http.StatusBadRequest,
+		`{"cluster_id": "xyz", "principal": "A"}`: http.StatusNotFound,
+		`{"cluster_id": "abc"}`:                   http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/locations/clusters/accept", bytes.NewBufferString(body))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, body)
+	}
+}
+
+func TestLinkCanonicalLocationAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	server := &Server{geocodeRepo: &MockLocationRepository{}}
+	router.POST("/api/canonical-locations/link", server.linkCanonicalLocation)
+
+	for body, want := range map[string]int{
+		`{"db_id": 1, "location": "RUTA IB KM 35", "name": "RUTA IB KM 35"}`: http.StatusOK,
+		`{"db_id": 1, "location": "RUTA IB KM 35", "name": "missing"}`:       http.StatusNotFound,
+		`{"db_id": 1, "location": "RUTA IB KM 35"}`:                          http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/canonical-locations/link", bytes.NewBufferString(body))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Code, body)
+	}
+}
+
+func TestSuggestCoordinates_QuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	server := &Server{
+		geocodeRepo: &MockLocationRepository{},
+		radarIndex:  &RadarIndex{radars: make(map[string]*Radar)},
+		geocoder: &fakeGeocoder{errs: []error{
+			&GeocodingError{Type: ErrorTypeQuotaExceeded, RetryAfter: 90 * time.Second},
+		}},
+		dbMap: map[int]string{},
+	}
+	router.GET("/api/locations/suggest/:db_id/*location", server.suggestCoordinates)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/locations/suggest/1/18%20DE%20JULIO%20Y%20EJIDO", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+
+	var body struct {
+		RetryAfter int              `json:"retry_after"`
+		Deferred   DeferredLocation `json:"deferred"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 90, body.RetryAfter)
+	assert.Equal(t, DeferReasonGeocoderQuota, body.Deferred.Reason)
+	assert.Equal(t, "18 DE JULIO Y EJIDO", body.Deferred.Location)
+}
+
+func TestSuggestCoordinates_LocationRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	geocoder := &fakeGeocoder{}
+	router := gin.New()
+	server := &Server{
+		geocodeRepo: &MockLocationRepository{},
+		radarIndex:  &RadarIndex{radars: make(map[string]*Radar)},
+		geocoder:    geocoder,
+		dbMap:       map[int]string{6: "Montevideo", 56: "Tacuarembó"},
+	}
+	router.GET("/api/locations/suggest/:db_id/*location", server.suggestCoordinates)
+
+	for _, path := range []string{
+		"/api/locations/suggest/56/18%20DE%20JULIO%20FRENTE%20AL%20N%C2%B0%20250",
+		"/api/locations/suggest/6/18%20DE%20JULIO%20FRENTE%20AL%20N%C2%B0%20250",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// only Tacuarembó has rules for that wording
+	assert.Equal(t, []string{"18 DE JULIO 250", "18 DE JULIO FRENTE AL N° 250"}, geocoder.locations)
+}
+
+func TestUndoAPI(t *testing.T) {
+	router, server, db, descriptionRepo := setupServerTest(t)
+	defer db.Close()
+
+	require.NoError(t, server.auditRepo.CreateSchema())
+	router.POST("/api/undo", server.undo)
+
+	do := func(session, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.RemoteAddr = sessionAddr(session)
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	classify := `{"description": "SIN CASCO", "article_ids": ["%s"]}`
+	require.Equal(t, http.StatusOK, do("alice", "/api/descriptions/classify", fmt.Sprintf(classify, "G.1")).Code)
+	require.Equal(t, http.StatusOK, do("alice", "/api/descriptions/classify", fmt.Sprintf(classify, "G.2")).Code)
+
+	// another curator's session is left alone
+	assert.Equal(t, http.StatusNotFound, do("bob", "/api/undo", "").Code)
+
+	// the misclick is reverted to the previous classification
+	w := do("alice", "/api/undo", `{"count": 1}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	d, err := descriptionRepo.GetDescriptionWithArticles("SIN CASCO")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"G.1"}, d.ArticleIDs)
+
+	// undoing the first one leaves it unclassified
+	require.Equal(t, http.StatusOK, do("alice", "/api/undo", "").Code)
+
+	d, err = descriptionRepo.GetDescriptionWithArticles("SIN CASCO")
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	assert.Equal(t, http.StatusNotFound, do("alice", "/api/undo", "").Code)
+}
+
+func TestLocationQueueNextSkipDefer(t *testing.T) {
+	router, server, db, _ := setupServerTest(t)
+	defer db.Close()
+
+	server.geocodeRepo = NewLocationRepository(db, map[int]string{1: "DB1"})
+	router.POST("/api/locations/queue/next", server.nextInQueue)
+	router.POST("/api/locations/queue/skip", server.skipInQueue)
+	router.POST("/api/locations/queue/defer-until", server.deferInQueue)
+
+	_, err := db.Exec(`INSERT INTO offenses (db_id, location, time) VALUES
+		(1, 'A', '2025-01-01 10:00:00'), (1, 'A', '2025-01-02 10:00:00'), (1, 'A', '2025-01-03 10:00:00'),
+		(1, 'B', '2025-01-01 10:00:00'), (1, 'B', '2025-01-02 10:00:00'),
+		(1, 'C', '2025-01-01 10:00:00')`)
+	require.NoError(t, err)
+
+	do := func(session, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.RemoteAddr = sessionAddr(session)
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	next := func(session string) string {
+		w := do(session, "/api/locations/queue/next", "")
+		if w.Code != http.StatusOK {
+			return ""
+		}
+
+		var item QueueNextResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+
+		return item.Location
+	}
+
+	// two curators never get the same location
+	assert.Equal(t, "A", next("alice"))
+	assert.Equal(t, "B", next("bob"))
+
+	// asking again releases the previous claim
+	assert.Equal(t, "A", next("alice"))
+
+	// a skipped location is not handed again to the same curator
+	assert.Equal(t, http.StatusOK, do("alice", "/api/locations/queue/skip", `{"db_id": 1, "location": "A"}`).Code)
+	assert.Equal(t, "C", next("alice"))
+	assert.Equal(t, "A", next("carol"))
+
+	// a deferred location leaves the queue for everyone
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w := do("bob", "/api/locations/queue/defer-until", `{"db_id": 1, "location": "B", "until": "`+until+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, next("bob"))
+
+	w = do("bob", "/api/locations/queue/defer-until", `{"db_id": 1, "location": "B", "until": "2020-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
@@ -0,0 +1,135 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// The "did you mean" candidates of the suggestions: most new locations are
+// typos or variants of a corner already judged.
+const (
+	SimilarJudgmentsMin  = 0.5 // minimum trigram similarity
+	SimilarJudgmentsTopN = 5
+)
+
+// SimilarJudgment is a judged location written like another one, with the
+// coordinates it was judged at.
+type SimilarJudgment struct {
+	Location     string  `json:"location"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	IsElectronic bool    `json:"is_electronic"`
+	Similarity   float64 `json:"similarity"`
+}
+
+// trigrams returns the trigrams of the words of s as pg_trgm does: each word
+// lower-cased and padded with two spaces before and one after.
+func trigrams(s string) map[string]bool {
+	ret := make(map[string]bool)
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, w := range words {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			ret[string(padded[i:i+3])] = true
+		}
+	}
+
+	return ret
+}
+
+// TrigramSimilarity is the number of trigrams a and b share over the number
+// of trigrams of both, from 0 to 1, as the similarity of pg_trgm.
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// SimilarJudgments returns the judged locations of the database most similar
+// to location, comparing them cleaned (see impo.CleanLocation). The judgments
+// merged into another count as the canonical one, so it's offered once.
+func (r *sqlJudgmentRepository) SimilarJudgments(dbID int, location string, limit int) ([]SimilarJudgment, error) {
+	rows, err := r.db.Query(`
+		SELECT location, COALESCE(canonical_location, ''), point.y, point.x, is_electronic
+		FROM locations
+		WHERE db_id = ? AND location != ?
+	`, dbID, location)
+	if err != nil {
+		return nil, fmt.Errorf("querying judgments: %w", err)
+	}
+	defer rows.Close()
+
+	cleaned := impo.CleanLocation(dbID, location)
+	best := make(map[string]SimilarJudgment)
+
+	for rows.Next() {
+		var (
+			judged, canonical string
+			j                 SimilarJudgment
+		)
+
+		if err := rows.Scan(&judged, &canonical, &j.Latitude, &j.Longitude, &j.IsElectronic); err != nil {
+			return nil, fmt.Errorf("scanning judgment: %w", err)
+		}
+
+		j.Similarity = TrigramSimilarity(cleaned, impo.CleanLocation(dbID, judged))
+		if j.Similarity < SimilarJudgmentsMin {
+			continue
+		}
+
+		j.Location = judged
+		if canonical != "" {
+			j.Location = canonical
+		}
+
+		if j.Location == location {
+			continue
+		}
+
+		if prev, ok := best[j.Location]; !ok || j.Similarity > prev.Similarity {
+			best[j.Location] = j
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating judgments: %w", err)
+	}
+
+	ret := make([]SimilarJudgment, 0, len(best))
+	for _, j := range best {
+		ret = append(ret, j)
+	}
+
+	slices.SortFunc(ret, func(a, b SimilarJudgment) int {
+		return cmp.Or(cmp.Compare(b.Similarity, a.Similarity), cmp.Compare(a.Location, b.Location))
+	})
+
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+
+	return ret, nil
+}
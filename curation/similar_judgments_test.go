@@ -0,0 +1,63 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+
+	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrigramSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, TrigramSimilarity("AV ITALIA Y AV BOLIVIA", "av italia y av bolivia"), 1e-9)
+	assert.InDelta(t, 0.0, TrigramSimilarity("AV ITALIA", ""), 1e-9)
+	assert.InDelta(t, 0.0, TrigramSimilarity("RAMBLA", "ITALIA"), 1e-9)
+
+	typo := TrigramSimilarity("AV ITALIA Y AV BOLIVIA", "AV ITALIA Y AV BOLIVA")
+	other := TrigramSimilarity("AV ITALIA Y AV BOLIVIA", "AV ITALIA Y PEDRO BUSTAMANTE")
+	assert.Greater(t, typo, SimilarJudgmentsMin)
+	assert.Less(t, other, SimilarJudgmentsMin)
+}
+
+func TestSimilarJudgments(t *testing.T) {
+	db, repo := setupTestDB(t)
+	defer db.Close()
+
+	for i, location := range []string{
+		"AV ITALIA Y AV BOLIVIA", "AV ITALIA Y BOLIVIA", "AV ITALIA Y PEDRO BUSTAMANTE", "RAMBLA Y BOLIVIA",
+	} {
+		require.NoError(t, repo.SaveJudgment(&Location{
+			DbID:            1,
+			Location:        location,
+			Point:           &spatial.Point{Lat: float64(-34 - i), Lng: -56},
+			GeocodingMethod: "manual",
+			Confidence:      "high",
+		}))
+	}
+
+	// the other database doesn't count
+	require.NoError(t, repo.SaveJudgment(&Location{
+		DbID: 2, Location: "AV ITALIA Y AV BOLIVIAS", Point: &spatial.Point{Lat: -30, Lng: -56},
+		GeocodingMethod: "manual", Confidence: "high",
+	}))
+
+	require.NoError(t, repo.MergeLocations(1, "AV ITALIA Y BOLIVIA", "AV ITALIA Y AV BOLIVIA"))
+
+	similar, err := repo.SimilarJudgments(1, "AV ITALIA Y AV BOLIVA", SimilarJudgmentsTopN)
+	require.NoError(t, err)
+
+	// the merged one is offered as its canonical location
+	require.Len(t, similar, 1)
+	assert.Equal(t, "AV ITALIA Y AV BOLIVIA", similar[0].Location)
+	assert.InDelta(t, -34.0, similar[0].Latitude, 1e-9)
+	assert.InDelta(t, -56.0, similar[0].Longitude, 1e-9)
+	assert.Greater(t, similar[0].Similarity, SimilarJudgmentsMin)
+
+	// a judged location isn't similar to itself
+	similar, err = repo.SimilarJudgments(1, "RAMBLA Y BOLIVIA", SimilarJudgmentsTopN)
+	require.NoError(t, err)
+	assert.Empty(t, similar)
+}
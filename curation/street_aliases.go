@@ -0,0 +1,82 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// StreetAliasRepository handles the old names of the renamed streets the
+// curators add on top of the reviewed ones of impo/street_aliases.json. They
+// are applied to the offenses by the next backfill (`chapa curation load` or
+// `chapa impo update`).
+type StreetAliasRepository interface {
+	CreateSchema() error
+	ListStreetAliases() ([]impo.StreetAlias, error)
+	// SaveStreetAlias adds the alias or replaces the one with the same
+	// database and old name.
+	SaveStreetAlias(a impo.StreetAlias) (impo.StreetAlias, error)
+	// DeleteStreetAlias deletes an alias, reporting whether it existed.
+	DeleteStreetAlias(dbID int, old string) (bool, error)
+}
+
+type sqlStreetAliasRepository struct {
+	db *sql.DB
+}
+
+// NewStreetAliasRepository creates a new street alias repository.
+func NewStreetAliasRepository(db *sql.DB) StreetAliasRepository {
+	return &sqlStreetAliasRepository{db: db}
+}
+
+func (r *sqlStreetAliasRepository) CreateSchema() error {
+	_, err := r.db.Exec(impo.StreetAliasesSchema)
+
+	return err
+}
+
+func (r *sqlStreetAliasRepository) ListStreetAliases() ([]impo.StreetAlias, error) {
+	return impo.ListStreetAliases(r.db)
+}
+
+func (r *sqlStreetAliasRepository) SaveStreetAlias(a impo.StreetAlias) (impo.StreetAlias, error) {
+	if err := a.Normalize(); err != nil {
+		return a, err
+	}
+
+	if _, err := r.db.Exec(`
+		INSERT INTO street_aliases (db_id, old_name, new_name, since, note, updated_at)
+		VALUES (?, ?, ?, CAST(? AS DATE), ?, ?)
+		ON CONFLICT (db_id, old_name) DO UPDATE SET
+			new_name = excluded.new_name,
+			since = excluded.since,
+			note = excluded.note,
+			updated_at = excluded.updated_at
+	`, a.DbID, a.Old, a.New, nullIfEmpty(a.Since), nullIfEmpty(a.Note), time.Now()); err != nil {
+		return a, fmt.Errorf("saving street alias %s: %w", a.Old, err)
+	}
+
+	return a, nil
+}
+
+func (r *sqlStreetAliasRepository) DeleteStreetAlias(dbID int, old string) (bool, error) {
+	old = strings.ToUpper(strings.Join(strings.Fields(old), " "))
+
+	res, err := r.db.Exec("DELETE FROM street_aliases WHERE db_id = ? AND old_name = ?", dbID, old)
+	if err != nil {
+		return false, fmt.Errorf("deleting street alias %s: %w", old, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	return n > 0, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreetAliasRepository(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	repo := NewStreetAliasRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	_, err = repo.SaveStreetAlias(impo.StreetAlias{DbID: 45, Old: "AGRACIADA"})
+	require.ErrorIs(t, err, impo.ErrInvalidStreetAlias)
+
+	saved, err := repo.SaveStreetAlias(impo.StreetAlias{DbID: 45, Old: "av agraciada", New: "Av del Libertador"})
+	require.NoError(t, err)
+	assert.Equal(t, "AV AGRACIADA", saved.Old)
+
+	_, err = repo.SaveStreetAlias(impo.StreetAlias{
+		DbID: 45, Old: "AV AGRACIADA", New: "AV DEL LIBERTADOR", Since: "2020-05-01", Note: "decreto",
+	})
+	require.NoError(t, err)
+
+	aliases, err := repo.ListStreetAliases()
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+	assert.Equal(t, "AV DEL LIBERTADOR", aliases[0].New)
+	assert.Equal(t, "2020-05-01", aliases[0].Since)
+	assert.Equal(t, "decreto", aliases[0].Note)
+
+	deleted, err := repo.DeleteStreetAlias(45, "av  agraciada")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.DeleteStreetAlias(45, "AV AGRACIADA")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UR outlier review statuses.
+const (
+	OutlierPending   = "pending"
+	OutlierConfirmed = "confirmed" // the UR is wrong, likely an extraction error
+	OutlierDismissed = "dismissed" // the UR is right, just unusual
+)
+
+var (
+	ErrInvalidOutlierStatus = errors.New("invalid outlier status")
+	ErrOutlierNotFound      = errors.New("UR outlier not found")
+)
+
+// URStats is the UR distribution of the offenses of an article. Only offenses
+// classified under a single article are considered, as the UR of the others
+// is the sum of several fines.
+type URStats struct {
+	ArticleID string  `json:"article_id"`
+	Count     int     `json:"count"`
+	Median    float64 `json:"median"`
+	P05       float64 `json:"p05"`
+	P95       float64 `json:"p95"`
+}
+
+// UROutlier is an offense whose UR deviates wildly from the one expected for
+// its article, queued for review.
+type UROutlier struct {
+	DbID        int       `json:"db_id"`
+	DocSource   string    `json:"doc_source"`
+	RecordID    int       `json:"record_id"`
+	ArticleID   string    `json:"article_id"`
+	Description string    `json:"description"`
+	UR          int       `json:"ur"`
+	Median      float64   `json:"median"`
+	P05         float64   `json:"p05"`
+	P95         float64   `json:"p95"`
+	Status      string    `json:"status"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// OutlierRepository computes UR statistics and keeps the review queue of the
+// offenses that don't fit them.
+type OutlierRepository interface {
+	CreateSchema() error
+	// ComputeURStats returns the UR distribution of the articles with at
+	// least minCount offenses.
+	ComputeURStats(minCount int) ([]URStats, error)
+	// DetectUROutliers queues the offenses whose UR is more than factor times
+	// above or below the median of its article, returning how many were new.
+	DetectUROutliers(minCount int, factor float64) (int, error)
+	ListUROutliers(status string, limit int) ([]UROutlier, error)
+	ResolveUROutlier(docSource string, recordID int, status string) error
+}
+
+type sqlOutlierRepository struct {
+	db *sql.DB
+}
+
+// NewOutlierRepository creates a new outlier repository.
+func NewOutlierRepository(db *sql.DB) OutlierRepository {
+	return &sqlOutlierRepository{db: db}
+}
+
+func (r *sqlOutlierRepository) CreateSchema() error {
+	_, err := r.db.Exec(`
+		CREATE TABLE IF NOT EXISTS ur_outliers (
+			db_id INTEGER NOT NULL,
+			doc_source VARCHAR NOT NULL,
+			record_id INTEGER NOT NULL,
+			article_id VARCHAR NOT NULL,
+			description VARCHAR,
+			ur INTEGER NOT NULL,
+			median DOUBLE NOT NULL,
+			p05 DOUBLE NOT NULL,
+			p95 DOUBLE NOT NULL,
+			status VARCHAR NOT NULL DEFAULT 'pending',
+			detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (doc_source, record_id)
+		);
+	`)
+
+	return err
+}
+
+// urStatsQuery computes URStats; it takes the minimum count as parameter.
+const urStatsQuery = `
+	SELECT article_ids[1] AS article_id, COUNT(*) AS n,
+		median(ur) AS median, quantile_cont(ur, 0.05) AS p05, quantile_cont(ur, 0.95) AS p95
+	FROM active_offenses
+	WHERE len(article_ids) = 1 AND ur > 0 AND error IS NULL
+	GROUP BY 1
+	HAVING COUNT(*) >= ?
+`
+
+func (r *sqlOutlierRepository) ComputeURStats(minCount int) ([]URStats, error) {
+	rows, err := r.db.Query(urStatsQuery+" ORDER BY article_id", minCount)
+	if err != nil {
+		return nil, fmt.Errorf("computing UR stats: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []URStats
+
+	for rows.Next() {
+		var s URStats
+		if err := rows.Scan(&s.ArticleID, &s.Count, &s.Median, &s.P05, &s.P95); err != nil {
+			return nil, fmt.Errorf("scanning UR stats: %w", err)
+		}
+
+		ret = append(ret, s)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlOutlierRepository) DetectUROutliers(minCount int, factor float64) (int, error) {
+	res, err := r.db.Exec(`
+		INSERT OR IGNORE INTO ur_outliers
+			(db_id, doc_source, record_id, article_id, description, ur, median, p05, p95)
+		WITH stats AS (`+urStatsQuery+`)
+		SELECT o.db_id, o.doc_source, o.record_id, s.article_id, o.description, o.ur,
+			s.median, s.p05, s.p95
+		FROM active_offenses o
+		JOIN stats s ON o.article_ids[1] = s.article_id
+		WHERE len(o.article_ids) = 1 AND o.ur > 0 AND o.error IS NULL
+			AND (o.ur > s.median * ? OR o.ur * ? < s.median)
+	`, minCount, factor, factor)
+	if err != nil {
+		return 0, fmt.Errorf("detecting UR outliers: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting UR outliers: %w", err)
+	}
+
+	return int(n), nil
+}
+
+func (r *sqlOutlierRepository) ListUROutliers(status string, limit int) ([]UROutlier, error) {
+	rows, err := r.db.Query(`
+		SELECT db_id, doc_source, record_id, article_id, COALESCE(description, ''), ur,
+			median, p05, p95, status, detected_at
+		FROM ur_outliers
+		WHERE status = ?
+		ORDER BY abs(ln(ur / median)) DESC, doc_source, record_id
+		LIMIT ?
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing UR outliers: %w", err)
+	}
+	defer rows.Close()
+
+	ret := []UROutlier{}
+
+	for rows.Next() {
+		var o UROutlier
+		if err := rows.Scan(
+			&o.DbID, &o.DocSource, &o.RecordID, &o.ArticleID, &o.Description, &o.UR,
+			&o.Median, &o.P05, &o.P95, &o.Status, &o.DetectedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning UR outlier: %w", err)
+		}
+
+		ret = append(ret, o)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlOutlierRepository) ResolveUROutlier(docSource string, recordID int, status string) error {
+	if status != OutlierConfirmed && status != OutlierDismissed {
+		return fmt.Errorf("%w: %q", ErrInvalidOutlierStatus, status)
+	}
+
+	res, err := r.db.Exec(
+		"UPDATE ur_outliers SET status = ? WHERE doc_source = ? AND record_id = ?",
+		status, docSource, recordID,
+	)
+	if err != nil {
+		return fmt.Errorf("resolving UR outlier: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s#%d", ErrOutlierNotFound, docSource, recordID)
+	}
+
+	return nil
+}
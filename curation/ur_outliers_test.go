@@ -0,0 +1,126 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOutlierDB(t *testing.T) (*sql.DB, OutlierRepository) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER,
+			doc_source VARCHAR,
+			record_id INTEGER,
+			description VARCHAR,
+			ur INTEGER,
+			error VARCHAR,
+			article_ids VARCHAR[],
+			superseded_by VARCHAR
+		);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+	`)
+	require.NoError(t, err)
+
+	values := make([]string, 0, 25)
+	for i := range 23 {
+		values = append(values, fmt.Sprintf("(45, 'doc1', %d, 'EXCESO DE VELOCIDAD', 50, NULL, ['18.1'], NULL)", i))
+	}
+
+	values = append(values,
+		"(45, 'doc1', 100, 'EXCESO DE VELOCIDAD', 500, NULL, ['18.1'], NULL)",
+		"(45, 'doc1', 101, 'EXCESO DE VELOCIDAD', 5, NULL, ['18.1'], NULL)",
+		"(45, 'doc1', 102, 'EXCESO DE VELOCIDAD Y CASCO', 500, NULL, ['18.1', '21.3'], NULL)",
+		"(45, 'doc1', 103, 'CASCO', 500, NULL, ['21.3'], NULL)",
+		// re-published as doc1: neither in the stats nor queued
+		"(45, 'doc0', 100, 'EXCESO DE VELOCIDAD', 500, NULL, ['18.1'], 'doc1')",
+	)
+	_, err = db.Exec("INSERT INTO offenses VALUES " + strings.Join(values, ","))
+	require.NoError(t, err)
+
+	repo := NewOutlierRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	return db, repo
+}
+
+func TestDetectUROutliers(t *testing.T) {
+	db, repo := setupOutlierDB(t)
+	defer db.Close()
+
+	stats, err := repo.ComputeURStats(20)
+	require.NoError(t, err)
+	require.Len(t, stats, 1, "21.3 has too few offenses")
+	assert.Equal(t, "18.1", stats[0].ArticleID)
+	assert.Equal(t, 25, stats[0].Count)
+	assert.InDelta(t, 50, stats[0].Median, 0.001)
+
+	n, err := repo.DetectUROutliers(20, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// detecting again doesn't queue them twice
+	n, err = repo.DetectUROutliers(20, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	require.NoError(t, repo.ResolveUROutlier("doc1", 100, OutlierConfirmed))
+	require.ErrorIs(t, repo.ResolveUROutlier("doc1", 100, "maybe"), ErrInvalidOutlierStatus)
+	require.ErrorIs(t, repo.ResolveUROutlier("doc1", 1, OutlierDismissed), ErrOutlierNotFound)
+
+	pending, err := repo.ListUROutliers(OutlierPending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 101, pending[0].RecordID)
+	assert.Equal(t, 5, pending[0].UR)
+}
+
+func TestResolveUROutlierAPI(t *testing.T) {
+	db, repo := setupOutlierDB(t)
+	defer db.Close()
+
+	_, err := repo.DetectUROutliers(20, 5)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	server := &Server{outlierRepo: repo}
+	router.GET("/api/ur-outliers", server.listUROutliers)
+	router.POST("/api/ur-outliers/resolve", server.resolveUROutlier)
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"doc_source": "doc1", "record_id": 100, "status": "confirmed"}`, http.StatusOK},
+		{`{"doc_source": "doc1", "record_id": 101, "status": "later"}`, http.StatusBadRequest},
+		{`{"doc_source": "doc1", "record_id": 7, "status": "dismissed"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/ur-outliers/resolve", bytes.NewBufferString(tt.body))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.code, w.Code, tt.body)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/ur-outliers?status=confirmed", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"record_id":100`)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultSeparators are the separators documents use between the offenses of
+// a multi-article description. A leading or trailing space means the
+// separator must be surrounded by whitespace: "13.3 - EXCESO" splits on " - "
+// but "S/CINTURON" or "3.1 C/L VENCIDA" ("con licencia vencida") don't.
+var DefaultSeparators = []string{",", ";", " - ", " / ", " C/ "}
+
+// Tokenizer splits multi-article descriptions into their parts.
+type Tokenizer struct {
+	separators []string
+	patterns   []*regexp.Regexp // one per separator
+	split      *regexp.Regexp   // any separator
+}
+
+// DefaultTokenizer splits on the DefaultSeparators.
+var DefaultTokenizer = NewTokenizer(DefaultSeparators...)
+
+// NewTokenizer returns a tokenizer for the given separators, matched case
+// insensitively.
+func NewTokenizer(separators ...string) *Tokenizer {
+	t := &Tokenizer{separators: separators}
+
+	alternatives := make([]string, 0, len(separators))
+
+	for _, sep := range separators {
+		expr := regexp.QuoteMeta(strings.TrimSpace(sep))
+
+		if strings.HasPrefix(sep, " ") {
+			expr = `\s+` + expr
+		} else {
+			expr = `\s*` + expr
+		}
+
+		if strings.HasSuffix(sep, " ") {
+			expr += `\s+`
+		} else {
+			expr += `\s*`
+		}
+
+		alternatives = append(alternatives, expr)
+		t.patterns = append(t.patterns, regexp.MustCompile(`(?i)`+expr))
+	}
+
+	t.split = regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+
+	return t
+}
+
+// Detect returns the separators present in the description.
+func (t *Tokenizer) Detect(description string) []string {
+	var ret []string
+
+	for i, p := range t.patterns {
+		if p.MatchString(description) {
+			ret = append(ret, t.separators[i])
+		}
+	}
+
+	return ret
+}
+
+// HasSeparator reports whether the description may list several offenses.
+func (t *Tokenizer) HasSeparator(description string) bool {
+	return t.split.MatchString(description)
+}
+
+// Split returns the trimmed, non-empty parts of the description.
+func (t *Tokenizer) Split(description string) []string {
+	var ret []string
+
+	for _, part := range t.split.Split(description, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			ret = append(ret, part)
+		}
+	}
+
+	return ret
+}
+
+// ResolveMultiArticle checks if all parts of a description are classified and
+// returns the aggregated classification.
+func (t *Tokenizer) ResolveMultiArticle(description string, classify ClassifierFunc) (Classification, bool, error) {
+	parts := t.Split(description)
+	if len(parts) == 0 {
+		return Classification{}, false, nil
+	}
+
+	var result Classification
+
+	for _, part := range parts {
+		info, found, err := classify(part)
+		if err != nil {
+			return Classification{}, false, err
+		}
+
+		if !found {
+			return Classification{}, false, nil
+		}
+
+		result.ArticleIDs = append(result.ArticleIDs, info.ArticleIDs...)
+		result.ArticleCodes = append(result.ArticleCodes, info.ArticleCodes...)
+	}
+
+	return result, true, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer_Split(t *testing.T) {
+	tests := []struct {
+		description string
+		parts       []string
+		separators  []string
+	}{
+		{
+			"EXCESO DE VELOCIDAD, SIN CINTURON",
+			[]string{"EXCESO DE VELOCIDAD", "SIN CINTURON"},
+			[]string{","},
+		},
+		{
+			"Circular en sentido opuesto / circular a contramano",
+			[]string{"Circular en sentido opuesto", "circular a contramano"},
+			[]string{" / "},
+		},
+		{
+			"15.4 - NO RESPETAR SEÑALES LUMINOSAS",
+			[]string{"15.4", "NO RESPETAR SEÑALES LUMINOSAS"},
+			[]string{" - "},
+		},
+		{
+			"SIN CASCO; SIN LIBRETA",
+			[]string{"SIN CASCO", "SIN LIBRETA"},
+			[]string{";"},
+		},
+		{
+			"MAL ESTACIONADO c/ LIBRETA VENCIDA, SIN SOAT",
+			[]string{"MAL ESTACIONADO", "LIBRETA VENCIDA", "SIN SOAT"},
+			[]string{",", " C/ "},
+		},
+		// "C/" and "S/" glued to the next word are abbreviations, not separators
+		{"3.1 C/L VENCIDA", []string{"3.1 C/L VENCIDA"}, nil},
+		{"3.1C/LICENCIA VENCIDA", []string{"3.1C/LICENCIA VENCIDA"}, nil},
+		{"CONDUCIR S/CINTURON", []string{"CONDUCIR S/CINTURON"}, nil},
+		{"CONDUCIR MANIPULANDO TELEFONO CELULAR-DECRETO 81/014", []string{"CONDUCIR MANIPULANDO TELEFONO CELULAR-DECRETO 81/014"}, nil},
+		{" , ; ", nil, []string{",", ";"}},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.parts, DefaultTokenizer.Split(tc.description), tc.description)
+		assert.Equal(t, tc.separators, DefaultTokenizer.Detect(tc.description), tc.description)
+		assert.Equal(t, len(tc.separators) > 0, DefaultTokenizer.HasSeparator(tc.description), tc.description)
+	}
+}
+
+func TestTokenizer_Configurable(t *testing.T) {
+	tokenizer := NewTokenizer(" Y ")
+
+	assert.Equal(t, []string{"SIN CASCO", "SIN LIBRETA"}, tokenizer.Split("SIN CASCO y SIN LIBRETA"))
+	assert.Equal(t, []string{"SIN CASCO, SIN LIBRETA"}, tokenizer.Split("SIN CASCO, SIN LIBRETA"))
+}
@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
@@ -27,6 +28,14 @@ func LowerASCIIFolding(s string) string {
 	return s
 }
 
+// DescriptionKeyExpr returns the SQL expression of the key the descriptions
+// of column are compared by: lowercase, without accents and with its spaces
+// squashed, so "Exceso de Velocidad" and "EXCESO DE  VELOCIDAD " are the
+// same description.
+func DescriptionKeyExpr(column string) string {
+	return fmt.Sprintf(`trim(regexp_replace(strip_accents(lower(%s)), '\s+', ' ', 'g'))`, column)
+}
+
 // AnyToInt8Slice converts an interface{} to []int8 safely.
 func AnyToInt8Slice(v any) ([]int8, bool) {
 	if v == nil {
@@ -118,43 +127,9 @@ type Classification struct {
 type ClassifierFunc func(part string) (Classification, bool, error)
 
 // ResolveMultiArticle checks if all parts of a description are classified and returns the aggregated classification.
-// It splits the description by comma and checks each part using the provided classifier function.
+// It splits the description with the DefaultTokenizer and checks each part using the provided classifier function.
 func ResolveMultiArticle(description string, classify ClassifierFunc) (Classification, bool, error) {
-	parts := strings.Split(description, ",")
-
-	var result Classification
-
-	allFound := true
-	hasParts := false
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
-		hasParts = true
-
-		info, found, err := classify(part)
-		if err != nil {
-			return Classification{}, false, err
-		}
-
-		if !found {
-			allFound = false
-
-			break
-		}
-
-		result.ArticleIDs = append(result.ArticleIDs, info.ArticleIDs...)
-		result.ArticleCodes = append(result.ArticleCodes, info.ArticleCodes...)
-	}
-
-	if !hasParts || !allFound {
-		return Classification{}, false, nil
-	}
-
-	return result, true, nil
+	return DefaultTokenizer.ResolveMultiArticle(description, classify)
 }
 
 // FormatInt formats an integer with commas for human readability.
@@ -185,28 +160,3 @@ func FormatInt(n int64) string {
 		}
 	}
 }
-
-// ToRoman converts an integer to a Roman numeral.
-func ToRoman(num int) string {
-	if num <= 0 {
-		return ""
-	}
-
-	val := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
-	syb := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
-
-	var roman strings.Builder
-
-	i := 0
-
-	for num > 0 {
-		for num >= val[i] {
-			roman.WriteString(syb[i])
-			num -= val[i]
-		}
-
-		i++
-	}
-
-	return roman.String()
-}
@@ -0,0 +1,117 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import "time"
+
+// ArticleVersion is the period an article ID was in force. A zero From or To
+// leaves that side open. ReplacedBy is the ID the article was renumbered to
+// once it stopped being in force.
+type ArticleVersion struct {
+	ID         string
+	From       time.Time
+	To         time.Time
+	ReplacedBy string
+}
+
+// InForce tells whether the version applies to something that happened at t.
+// To is exclusive: the day an article is renumbered the new ID applies.
+func (v ArticleVersion) InForce(t time.Time) bool {
+	return (v.From.IsZero() || !t.Before(v.From)) && (v.To.IsZero() || t.Before(v.To))
+}
+
+// ArticleVersions resolves an article ID to the one in force at a given
+// date, following renumberings forward and backwards.
+type ArticleVersions struct {
+	byID       map[string]ArticleVersion
+	replacedBy map[string]string // the reverse of ReplacedBy
+}
+
+// NewArticleVersions indexes the versions. Articles without dates can be left
+// out: they are always in force.
+func NewArticleVersions(versions []ArticleVersion) *ArticleVersions {
+	ret := &ArticleVersions{
+		byID:       make(map[string]ArticleVersion, len(versions)),
+		replacedBy: make(map[string]string),
+	}
+
+	for _, v := range versions {
+		ret.byID[v.ID] = v
+		if v.ReplacedBy != "" {
+			ret.replacedBy[v.ReplacedBy] = v.ID
+		}
+	}
+
+	return ret
+}
+
+// Empty tells whether no article has vigency dates, so nothing to resolve.
+func (a *ArticleVersions) Empty() bool {
+	for _, v := range a.byID {
+		if !v.From.IsZero() || !v.To.IsZero() || v.ReplacedBy != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Versioned tells whether the article ID has vigency dates or takes part of
+// a renumbering.
+func (a *ArticleVersions) Versioned(id string) bool {
+	v, ok := a.byID[id]
+
+	return ok && (!v.From.IsZero() || !v.To.IsZero() || v.ReplacedBy != "" || a.replacedBy[id] != "")
+}
+
+// Resolve returns the version of the article in force at t. It returns the
+// ID unchanged and false when no version of it was in force at t: a conflict
+// a curator has to review.
+func (a *ArticleVersions) Resolve(id string, t time.Time) (string, bool) {
+	seen := make(map[string]bool)
+
+	for cur := id; !seen[cur]; {
+		seen[cur] = true
+
+		v, ok := a.byID[cur]
+		if !ok {
+			// unknown articles have no dates to respect, unless a
+			// renumbering points to one
+			return id, cur == id
+		}
+
+		switch {
+		case v.InForce(t):
+			return cur, true
+		case !v.To.IsZero() && !t.Before(v.To) && v.ReplacedBy != "":
+			cur = v.ReplacedBy
+		case !v.From.IsZero() && t.Before(v.From) && a.replacedBy[cur] != "":
+			cur = a.replacedBy[cur]
+		default:
+			return id, false
+		}
+	}
+
+	return id, false
+}
+
+// ResolveAll resolves every ID, dropping the duplicates that renumberings can
+// produce. It returns the IDs that have no version in force at t.
+func (a *ArticleVersions) ResolveAll(ids []string, t time.Time) (resolved, conflicts []string) {
+	seen := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		r, ok := a.Resolve(id, t)
+		if !ok {
+			conflicts = append(conflicts, id)
+		}
+
+		if !seen[r] {
+			seen[r] = true
+			resolved = append(resolved, r)
+		}
+	}
+
+	return resolved, conflicts
+}
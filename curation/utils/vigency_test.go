@@ -0,0 +1,62 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticleVersions_Resolve(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	versions := NewArticleVersions([]ArticleVersion{
+		{ID: "13.3.A", To: day(2020, 1, 1), ReplacedBy: "13.4.A"},
+		{ID: "13.4.A", From: day(2020, 1, 1), To: day(2023, 1, 1), ReplacedBy: "13.5"},
+		{ID: "13.5", From: day(2023, 1, 1)},
+		{ID: "18.1", From: day(2021, 1, 1)},
+		{ID: "21.3.1"},
+	})
+
+	assert.False(t, versions.Empty())
+	assert.True(t, versions.Versioned("13.5"))
+	assert.False(t, versions.Versioned("21.3.1"))
+
+	tests := []struct {
+		id   string
+		at   time.Time
+		want string
+		ok   bool
+	}{
+		{"13.3.A", day(2019, 6, 1), "13.3.A", true},
+		{"13.3.A", day(2020, 1, 1), "13.4.A", true},
+		{"13.3.A", day(2024, 1, 1), "13.5", true},
+		{"13.5", day(2019, 6, 1), "13.3.A", true},
+		{"13.4.A", day(2021, 6, 1), "13.4.A", true},
+		{"21.3.1", day(2019, 6, 1), "21.3.1", true},
+		{"99.9", day(2019, 6, 1), "99.9", true},
+		// not in force yet and nothing it replaced
+		{"18.1", day(2020, 6, 1), "18.1", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := versions.Resolve(tt.id, tt.at)
+		assert.Equal(t, tt.want, got, "%s at %s", tt.id, tt.at)
+		assert.Equal(t, tt.ok, ok, "%s at %s", tt.id, tt.at)
+	}
+
+	resolved, conflicts := versions.ResolveAll([]string{"13.3.A", "13.5", "18.1"}, day(2021, 1, 1))
+	assert.Equal(t, []string{"13.4.A", "18.1"}, resolved)
+	assert.Empty(t, conflicts)
+
+	resolved, conflicts = versions.ResolveAll([]string{"13.4.A", "18.1"}, day(2020, 6, 1))
+	assert.Equal(t, []string{"13.4.A", "18.1"}, resolved)
+	assert.Equal(t, []string{"18.1"}, conflicts)
+
+	assert.True(t, NewArticleVersions([]ArticleVersion{{ID: "1"}}).Empty())
+}
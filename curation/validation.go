@@ -4,9 +4,9 @@
 package curation
 
 import (
-	"errors"
-	"fmt"
 	"strings"
+
+	"github.com/jcodagnone/chapauy/utils/i18n"
 )
 
 // validMethods contiene los métodos de geocodificación permitidos.
@@ -17,6 +17,7 @@ var validMethods = map[string]bool{
 	"manual_click":      true,
 	"manual_adjustment": true,
 	"manual_input":      true,
+	"similar_judgment":  true,
 }
 
 // validConfidence contiene los niveles de confianza permitidos.
@@ -31,11 +32,11 @@ var validConfidence = map[string]bool{
 func validateCoordinates(lat, lon float64) error {
 	// Límites globales
 	if lat < -90 || lat > 90 {
-		return fmt.Errorf("latitud debe estar entre -90 y 90 (recibido: %f)", lat)
+		return i18n.Errorf("latitud debe estar entre -90 y 90 (recibido: %f)", lat)
 	}
 
 	if lon < -180 || lon > 180 {
-		return fmt.Errorf("longitud debe estar entre -180 y 180 (recibido: %f)", lon)
+		return i18n.Errorf("longitud debe estar entre -180 y 180 (recibido: %f)", lon)
 	}
 
 	// Límites razonables para Uruguay (con margen)
@@ -49,11 +50,11 @@ func validateCoordinates(lat, lon float64) error {
 	)
 
 	if lat < uruguayMinLat || lat > uruguayMaxLat {
-		return fmt.Errorf("latitud fuera de los límites de Uruguay (%f a %f): %f", uruguayMinLat, uruguayMaxLat, lat)
+		return i18n.Errorf("latitud fuera de los límites de Uruguay (%f a %f): %f", uruguayMinLat, uruguayMaxLat, lat)
 	}
 
 	if lon < uruguayMinLon || lon > uruguayMaxLon {
-		return fmt.Errorf("longitud fuera de los límites de Uruguay (%f a %f): %f", uruguayMinLon, uruguayMaxLon, lon)
+		return i18n.Errorf("longitud fuera de los límites de Uruguay (%f a %f): %f", uruguayMinLon, uruguayMaxLon, lon)
 	}
 
 	return nil
@@ -62,38 +63,38 @@ func validateCoordinates(lat, lon float64) error {
 // validateJudgment verifica que un LocationJudgment tenga datos válidos.
 func validateJudgment(j *Location) error {
 	if j == nil {
-		return errors.New("judgment no puede ser nil")
+		return i18n.Errorf("judgment no puede ser nil")
 	}
 
 	// Validar ubicación
 	if strings.TrimSpace(j.Location) == "" {
-		return errors.New("location no puede estar vacío")
+		return i18n.Errorf("location no puede estar vacío")
 	}
 
 	if len(j.Location) > 500 {
-		return errors.New("location demasiado largo (máximo 500 caracteres)")
+		return i18n.Errorf("location demasiado largo (máximo 500 caracteres)")
 	}
 
 	// Validar coordenadas si están presentes
 	if j.Point != nil {
 		if err := validateCoordinates(j.Point.Lat, j.Point.Lng); err != nil {
-			return fmt.Errorf("coordenadas inválidas: %w", err)
+			return i18n.Errorf("coordenadas inválidas: %w", err)
 		}
 	}
 
 	// Validar método de geocodificación
 	if j.GeocodingMethod != "" && !validMethods[j.GeocodingMethod] {
-		return fmt.Errorf("método de geocodificación inválido: %s", j.GeocodingMethod)
+		return i18n.Errorf("método de geocodificación inválido: %s", j.GeocodingMethod)
 	}
 
 	// Validar nivel de confianza
 	if j.Confidence != "" && !validConfidence[j.Confidence] {
-		return fmt.Errorf("nivel de confianza inválido: %s", j.Confidence)
+		return i18n.Errorf("nivel de confianza inválido: %s", j.Confidence)
 	}
 
 	// Validar notas
 	if len(j.Notes) > 1000 {
-		return errors.New("notes demasiado largo (máximo 1000 caracteres)")
+		return i18n.Errorf("notes demasiado largo (máximo 1000 caracteres)")
 	}
 
 	return nil
@@ -0,0 +1,172 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// ErrInvalidCountry is returned when a spelling is mapped to something that
+// isn't an ISO 3166-1 alpha-2 code.
+var ErrInvalidCountry = errors.New("invalid ISO country code")
+
+var isoCountryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// PendingValue is a value the extraction couldn't interpret, stored as the
+// error of the rows it was found in.
+type PendingValue struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	DbIDs     []int     `json:"db_ids"`
+	Documents int       `json:"documents"`
+	DocSource string    `json:"doc_source"` // the last document it was seen in
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// ValueRepository handles the values learned by the extraction, like the
+// spellings of the countries.
+type ValueRepository interface {
+	CreateSchema() error
+	// ListPendingValues lists the values of a kind to map, every kind when
+	// it's empty.
+	ListPendingValues(kind string) ([]PendingValue, error)
+	// MapCountry adds the spelling to the synonyms of the ISO code, "" for
+	// another country, and flags the documents it was found in for
+	// re-extraction, returning them.
+	MapCountry(spelling, iso string) ([]string, error)
+}
+
+type sqlValueRepository struct {
+	db *sql.DB
+}
+
+// NewValueRepository creates a new value repository.
+func NewValueRepository(db *sql.DB) ValueRepository {
+	return &sqlValueRepository{db: db}
+}
+
+func (r *sqlValueRepository) CreateSchema() error {
+	_, err := r.db.Exec(impo.ValuesSchema)
+
+	return err
+}
+
+func (r *sqlValueRepository) ListPendingValues(kind string) ([]PendingValue, error) {
+	rows, err := r.db.Query(`
+		SELECT kind, value, list(DISTINCT db_id ORDER BY db_id), COUNT(*), arg_max(doc_source, seen_at), MAX(seen_at)
+		FROM pending_values
+		WHERE ? = '' OR kind = ?
+		GROUP BY kind, value
+		ORDER BY COUNT(*) DESC, kind, value
+	`, kind, kind)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending values: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []PendingValue
+
+	for rows.Next() {
+		var (
+			v      PendingValue
+			idsVal any
+		)
+
+		if err := rows.Scan(&v.Kind, &v.Value, &idsVal, &v.Documents, &v.DocSource, &v.LastSeen); err != nil {
+			return nil, fmt.Errorf("scanning pending value: %w", err)
+		}
+
+		ids, _ := idsVal.([]any)
+		for _, id := range ids {
+			if n, ok := id.(int32); ok {
+				v.DbIDs = append(v.DbIDs, int(n))
+			}
+		}
+
+		ret = append(ret, v)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlValueRepository) MapCountry(spelling, iso string) ([]string, error) {
+	if iso != "" && !isoCountryRe.MatchString(iso) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCountry, iso)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("failed to rollback transaction mapping country %s: %v", spelling, err)
+		}
+	}()
+
+	if _, err := tx.Exec(`
+		INSERT INTO country_synonyms (spelling, iso, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (spelling) DO UPDATE SET iso = excluded.iso, created_at = excluded.created_at
+	`, spelling, iso, time.Now()); err != nil {
+		return nil, fmt.Errorf("saving synonym of %s: %w", spelling, err)
+	}
+
+	rows, err := tx.Query(
+		"SELECT doc_source FROM pending_values WHERE kind = ? AND value = ? ORDER BY doc_source",
+		impo.ValueKindCountry, spelling,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying documents of %s: %w", spelling, err)
+	}
+
+	var docs []string
+
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scanning document of %s: %w", spelling, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// `impo reextract` picks up the documents with rows of an older version;
+	// without a hash the rows are rewritten even if they come out the same
+	for _, doc := range docs {
+		if _, err := tx.Exec(
+			"UPDATE offenses SET extractor_version = 0, row_hash = NULL WHERE doc_source = ?", doc,
+		); err != nil {
+			return nil, fmt.Errorf("flagging %s for re-extraction: %w", doc, err)
+		}
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM pending_values WHERE kind = ? AND value = ?", impo.ValueKindCountry, spelling,
+	); err != nil {
+		return nil, fmt.Errorf("clearing pending country %s: %w", spelling, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
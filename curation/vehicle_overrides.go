@@ -0,0 +1,82 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// VehicleOverrideRepository handles the overrides of the vehicle types the
+// curators add on top of the reviewed ones of impo/vehicle_overrides.json.
+// They are applied to the offenses by the next backfill (`chapa curation
+// load` or `chapa impo update`).
+type VehicleOverrideRepository interface {
+	CreateSchema() error
+	ListVehicleOverrides() ([]impo.VehicleOverride, error)
+	// SaveVehicleOverride adds the override or replaces the one with the
+	// same country and prefix.
+	SaveVehicleOverride(o impo.VehicleOverride) (impo.VehicleOverride, error)
+	// DeleteVehicleOverride deletes an override, reporting whether it
+	// existed.
+	DeleteVehicleOverride(country, prefix string) (bool, error)
+}
+
+type sqlVehicleOverrideRepository struct {
+	db *sql.DB
+}
+
+// NewVehicleOverrideRepository creates a new vehicle override repository.
+func NewVehicleOverrideRepository(db *sql.DB) VehicleOverrideRepository {
+	return &sqlVehicleOverrideRepository{db: db}
+}
+
+func (r *sqlVehicleOverrideRepository) CreateSchema() error {
+	_, err := r.db.Exec(impo.VehicleOverridesSchema)
+
+	return err
+}
+
+func (r *sqlVehicleOverrideRepository) ListVehicleOverrides() ([]impo.VehicleOverride, error) {
+	return impo.ListVehicleOverrides(r.db)
+}
+
+func (r *sqlVehicleOverrideRepository) SaveVehicleOverride(o impo.VehicleOverride) (impo.VehicleOverride, error) {
+	if err := o.Normalize(); err != nil {
+		return o, err
+	}
+
+	if _, err := r.db.Exec(`
+		INSERT INTO vehicle_overrides (country, prefix, category, vehicle_type, note, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (country, prefix) DO UPDATE SET
+			category = excluded.category,
+			vehicle_type = excluded.vehicle_type,
+			note = excluded.note,
+			updated_at = excluded.updated_at
+	`, o.Country, o.Prefix, nullIfEmpty(o.Category), nullIfEmpty(o.VehicleType), nullIfEmpty(o.Note), time.Now()); err != nil {
+		return o, fmt.Errorf("saving vehicle override %s: %w", o.Prefix, err)
+	}
+
+	return o, nil
+}
+
+func (r *sqlVehicleOverrideRepository) DeleteVehicleOverride(country, prefix string) (bool, error) {
+	res, err := r.db.Exec(
+		"DELETE FROM vehicle_overrides WHERE country = ? AND prefix = ?", country, impo.NormalizeVehicleID(prefix),
+	)
+	if err != nil {
+		return false, fmt.Errorf("deleting vehicle override %s: %w", prefix, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	return n > 0, nil
+}
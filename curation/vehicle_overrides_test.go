@@ -0,0 +1,48 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVehicleOverrideRepository(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	repo := NewVehicleOverrideRepository(db)
+	require.NoError(t, repo.CreateSchema())
+
+	_, err = repo.SaveVehicleOverride(impo.VehicleOverride{Prefix: "SBA"})
+	require.ErrorIs(t, err, impo.ErrInvalidVehicleOverride)
+
+	saved, err := repo.SaveVehicleOverride(impo.VehicleOverride{Prefix: "sba", Category: "Taxi"})
+	require.NoError(t, err)
+	assert.Equal(t, impo.VehicleOverride{Country: impo.ISOUruguay, Prefix: "SBA", Category: "Taxi"}, saved)
+
+	_, err = repo.SaveVehicleOverride(impo.VehicleOverride{Prefix: "SBA", VehicleType: impo.TypeOmnibus, Note: "CUTCSA"})
+	require.NoError(t, err)
+
+	overrides, err := repo.ListVehicleOverrides()
+	require.NoError(t, err)
+	assert.Equal(t, []impo.VehicleOverride{
+		{Country: impo.ISOUruguay, Prefix: "SBA", VehicleType: impo.TypeOmnibus, Note: "CUTCSA"},
+	}, overrides)
+
+	deleted, err := repo.DeleteVehicleOverride(impo.ISOUruguay, "sba")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.DeleteVehicleOverride(impo.ISOUruguay, "SBA")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
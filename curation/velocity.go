@@ -0,0 +1,252 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+)
+
+// DefaultVelocityDays is the window of the curation velocity when none is
+// given.
+const DefaultVelocityDays = 28
+
+// unknownCurator groups the judgments made before the curator tokens.
+const unknownCurator = "(unknown)"
+
+// DailyCount is the number of judgments of a day, split by method.
+type DailyCount struct {
+	Date     string         `json:"date"`
+	Count    int            `json:"count"`
+	ByMethod map[string]int `json:"by_method,omitempty"`
+}
+
+// WeeklyCount is the number of judgments of a curator in a week, starting on
+// Monday.
+type WeeklyCount struct {
+	Week    string `json:"week"`
+	Curator string `json:"curator"`
+	Count   int    `json:"count"`
+}
+
+// Velocity is the pace of one of the curation queues during the window, and
+// when it would be empty at that pace.
+type Velocity struct {
+	Days       int           `json:"days"`
+	Total      int           `json:"total"` // judgments in the window
+	PerDay     float64       `json:"per_day"`
+	Pending    int           `json:"pending"`
+	ByMethod   []ValueCount  `json:"by_method,omitempty"`
+	ByCurator  []ValueCount  `json:"by_curator"`
+	Daily      []DailyCount  `json:"daily"`
+	Weekly     []WeeklyCount `json:"weekly"`
+	DaysToZero *float64      `json:"days_to_zero"` // nil when nothing is judged
+	EmptyBy    *string       `json:"empty_by"`
+}
+
+// VelocityStats is the pace of the location and description queues.
+type VelocityStats struct {
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Locations    *Velocity `json:"locations"`
+	Descriptions *Velocity `json:"descriptions"`
+}
+
+// VelocityRepository computes how fast the curators empty the queues, to
+// estimate how long until the full coverage and to see the effect of the
+// tooling changes.
+type VelocityRepository interface {
+	// Velocity returns the pace over the days up to now, inclusive.
+	Velocity(now time.Time, days int) (*VelocityStats, error)
+}
+
+type sqlVelocityRepository struct {
+	db *sql.DB
+}
+
+// NewVelocityRepository creates a new curation velocity repository.
+func NewVelocityRepository(db *sql.DB) VelocityRepository {
+	return &sqlVelocityRepository{db: db}
+}
+
+// velocityQueue are the queries of a queue: the judgments with their date,
+// method and curator, and the size of what's left.
+type velocityQueue struct {
+	judgments string
+	pending   string
+}
+
+var (
+	locationVelocity = velocityQueue{
+		judgments: `
+			SELECT CAST(created_at AS DATE) AS day, COALESCE(geocoding_method, '') AS method,
+				COALESCE(curator, '') AS curator
+			FROM locations`,
+		pending: `
+			SELECT COUNT(*) FROM (
+				SELECT DISTINCT o.db_id, o.location
+				FROM offenses o
+				WHERE o.location IS NOT NULL AND o.location != ''
+			) o
+			LEFT JOIN locations l ON l.db_id = o.db_id AND l.location = o.location
+			WHERE l.location IS NULL`,
+	}
+	descriptionVelocity = velocityQueue{
+		judgments: `
+			SELECT CAST(updated_at AS DATE) AS day, COALESCE(source, 'manual') AS method,
+				COALESCE(curator, '') AS curator
+			FROM descriptions`,
+		pending: `
+			SELECT COUNT(DISTINCT ` + utils.DescriptionKeyExpr("o.description") + `)
+			FROM offenses o
+			LEFT JOIN descriptions d ON ` + utils.DescriptionKeyExpr("o.description") + ` = d.description_key
+			WHERE o.description IS NOT NULL AND o.description != '' AND d.description_key IS NULL`,
+	}
+)
+
+func (r *sqlVelocityRepository) Velocity(now time.Time, days int) (*VelocityStats, error) {
+	if days < 1 {
+		days = DefaultVelocityDays
+	}
+
+	to := now.Format(time.DateOnly)
+	from := now.AddDate(0, 0, 1-days).Format(time.DateOnly)
+
+	locations, err := r.velocity(locationVelocity, from, to, days, now)
+	if err != nil {
+		return nil, fmt.Errorf("location velocity: %w", err)
+	}
+
+	descriptions, err := r.velocity(descriptionVelocity, from, to, days, now)
+	if err != nil {
+		return nil, fmt.Errorf("description velocity: %w", err)
+	}
+
+	return &VelocityStats{From: from, To: to, Locations: locations, Descriptions: descriptions}, nil
+}
+
+func (r *sqlVelocityRepository) velocity(q velocityQueue, from, to string, days int, now time.Time) (*Velocity, error) {
+	v := &Velocity{Days: days, ByCurator: []ValueCount{}, Daily: []DailyCount{}, Weekly: []WeeklyCount{}}
+
+	if err := r.db.QueryRow(q.pending).Scan(&v.Pending); err != nil {
+		return nil, fmt.Errorf("counting pending: %w", err)
+	}
+
+	window := `
+		WITH j AS (` + q.judgments + `)
+		SELECT %s FROM j
+		WHERE day BETWEEN CAST(? AS DATE) AND CAST(? AS DATE)
+		GROUP BY ALL
+		ORDER BY ALL`
+
+	// #nosec G202 - the columns are constants
+	rows, err := r.db.Query(fmt.Sprintf(window, "strftime(day, '%Y-%m-%d'), method, COUNT(*)"), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying daily judgments: %w", err)
+	}
+
+	methods := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			day, method string
+			n           int
+		)
+
+		if err := rows.Scan(&day, &method, &n); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scanning daily judgments: %w", err)
+		}
+
+		if len(v.Daily) == 0 || v.Daily[len(v.Daily)-1].Date != day {
+			v.Daily = append(v.Daily, DailyCount{Date: day, ByMethod: make(map[string]int)})
+		}
+
+		d := &v.Daily[len(v.Daily)-1]
+		d.Count += n
+		v.Total += n
+
+		if method != "" {
+			d.ByMethod[method] += n
+			methods[method] += n
+		}
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	v.ByMethod = sortedValueCounts(methods)
+
+	// #nosec G202 - the columns are constants
+	rows, err = r.db.Query(fmt.Sprintf(window, "strftime(date_trunc('week', day), '%Y-%m-%d'), curator, COUNT(*)"), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying weekly judgments: %w", err)
+	}
+	defer rows.Close()
+
+	curators := make(map[string]int)
+
+	for rows.Next() {
+		var w WeeklyCount
+		if err := rows.Scan(&w.Week, &w.Curator, &w.Count); err != nil {
+			return nil, fmt.Errorf("scanning weekly judgments: %w", err)
+		}
+
+		if w.Curator == "" {
+			w.Curator = unknownCurator
+		}
+
+		curators[w.Curator] += w.Count
+		v.Weekly = append(v.Weekly, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	v.ByCurator = sortedValueCounts(curators)
+	v.project(now)
+
+	return v, nil
+}
+
+// project estimates when the queue would be empty at the pace of the window.
+func (v *Velocity) project(now time.Time) {
+	v.PerDay = float64(v.Total) / float64(v.Days)
+	if v.PerDay == 0 {
+		return
+	}
+
+	daysToZero := math.Round(float64(v.Pending)/v.PerDay*10) / 10
+	emptyBy := now.AddDate(0, 0, int(math.Ceil(daysToZero))).Format(time.DateOnly)
+	v.DaysToZero, v.EmptyBy = &daysToZero, &emptyBy
+}
+
+// sortedValueCounts lists the counts, largest first.
+func sortedValueCounts(m map[string]int) []ValueCount {
+	ret := make([]ValueCount, 0, len(m))
+	for value, count := range m {
+		ret = append(ret, ValueCount{Value: value, Count: count})
+	}
+
+	slices.SortFunc(ret, func(a, b ValueCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	return ret
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package curation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVelocity(t *testing.T) {
+	db, _ := setupDescriptionDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		ALTER TABLE locations ADD COLUMN curator VARCHAR;
+
+		INSERT INTO offenses (db_id, location, description) VALUES
+			(1, 'A', 'EXCESO DE VELOCIDAD'),
+			(1, 'B', 'Exceso de  velocidad'),
+			(1, 'C', 'SEMAFORO ROJO'),
+			(1, 'D', 'MAL ESTACIONADO'),
+			(2, 'A', 'MAL ESTACIONADO'),
+			(2, 'E', 'CINTURON');
+
+		INSERT INTO locations (id, db_id, location, geocoding_method, curator, created_at) VALUES
+			(1, 1, 'A', 'google_maps', 'ana', '2025-06-02 10:00:00'),
+			(2, 1, 'B', 'manual', 'ana', '2025-06-03 10:00:00'),
+			(3, 1, 'C', 'google_maps', NULL, '2025-06-10 10:00:00'),
+			(4, 2, 'A', 'google_maps', 'bruno', '2025-01-01 10:00:00');
+
+		INSERT INTO descriptions (id, description, description_key, article_ids, updated_at, curator) VALUES
+			(1, 'EXCESO DE VELOCIDAD', 'exceso de velocidad', ['G.1'], '2025-06-05 10:00:00', 'ana');
+	`)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 14, 12, 0, 0, 0, time.UTC)
+
+	stats, err := NewVelocityRepository(db).Velocity(now, 14)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-06-01", stats.From)
+	assert.Equal(t, "2025-06-14", stats.To)
+
+	loc := stats.Locations
+	assert.Equal(t, 3, loc.Total, "the judgment of january is out of the window")
+	assert.Equal(t, 2, loc.Pending) // 1|D and 2|E
+	assert.InDelta(t, 3.0/14, loc.PerDay, 1e-9)
+	assert.Equal(t, []ValueCount{{"google_maps", 2}, {"manual", 1}}, loc.ByMethod)
+	assert.Equal(t, []ValueCount{{"ana", 2}, {unknownCurator, 1}}, loc.ByCurator)
+	require.Len(t, loc.Daily, 3)
+	assert.Equal(t, DailyCount{Date: "2025-06-03", Count: 1, ByMethod: map[string]int{"manual": 1}}, loc.Daily[1])
+	assert.Equal(t, []WeeklyCount{
+		{Week: "2025-06-02", Curator: "ana", Count: 2},
+		{Week: "2025-06-09", Curator: unknownCurator, Count: 1},
+	}, loc.Weekly)
+	require.NotNil(t, loc.DaysToZero)
+	assert.InDelta(t, 9.3, *loc.DaysToZero, 1e-9)
+	assert.Equal(t, "2025-06-24", *loc.EmptyBy)
+
+	desc := stats.Descriptions
+	assert.Equal(t, 1, desc.Total)
+	assert.Equal(t, 3, desc.Pending, "both spellings of the speeding share the key")
+	assert.Equal(t, []ValueCount{{"manual", 1}}, desc.ByMethod)
+
+	// nothing judged: no projection
+	stats, err = NewVelocityRepository(db).Velocity(now.AddDate(1, 0, 0), 7)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Locations.Total)
+	assert.Nil(t, stats.Locations.DaysToZero)
+	assert.Nil(t, stats.Locations.EmptyBy)
+	assert.Empty(t, stats.Locations.Daily)
+}
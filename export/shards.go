@@ -0,0 +1,342 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// The files of the database split by year: the web only downloads the common
+// tables and the years it shows.
+const (
+	ShardsManifestFile = "manifest.json"
+	ShardsCommonFile   = "common.duckdb"
+)
+
+// shardYear is the year an offense is filed under: the year of the offense,
+// or the year of its document when the time couldn't be parsed. Offenses
+// without either go to year 0.
+const shardYear = `COALESCE(time_year, year(doc_date), 0)`
+
+// YearShard is the DuckDB file with the offenses of a year.
+type YearShard struct {
+	Year     int    `json:"year"` // 0 for the offenses without a date
+	File     string `json:"file"` // relative to the manifest
+	Offenses int    `json:"offenses"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// ShardsManifest describes the database split by year.
+type ShardsManifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	// Common is the file with every table but offenses, needed by all years.
+	Common      string      `json:"common"`
+	CommonBytes int64       `json:"common_bytes"`
+	Years       []YearShard `json:"years"`
+}
+
+// Shard returns the shard of year, if the database has offenses of it.
+func (m *ShardsManifest) Shard(year int) (YearShard, bool) {
+	i := slices.IndexFunc(m.Years, func(s YearShard) bool { return s.Year == year })
+	if i < 0 {
+		return YearShard{}, false
+	}
+
+	return m.Years[i], true
+}
+
+func shardFile(year int) string {
+	if year == 0 {
+		return "offenses-undated.duckdb"
+	}
+
+	return fmt.Sprintf("offenses-%d.duckdb", year)
+}
+
+// Shards splits the database into dir: a DuckDB file with the offenses of
+// each year, one with the rest of the tables and the manifest listing them.
+// The files of a previous split are replaced. db must be able to attach them:
+// a database opened read-only can't, see dbutils.OpenAttached.
+func Shards(db *sql.DB, dir string, now time.Time) (*ShardsManifest, error) {
+	if strings.ContainsRune(dir, '\'') {
+		return nil, fmt.Errorf("invalid shards directory %q", dir)
+	}
+
+	if _, err := db.Exec(`INSTALL spatial; LOAD spatial;`); err != nil {
+		return nil, fmt.Errorf("loading extensions: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	m := &ShardsManifest{GeneratedAt: now.UTC(), Common: ShardsCommonFile}
+
+	tables, err := commonTables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.CommonBytes, err = writeShard(db, filepath.Join(dir, m.Common), tables, nil); err != nil {
+		return nil, err
+	}
+
+	years, err := offenseYears(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for year, offenses := range years {
+		s := YearShard{Year: year, File: shardFile(year), Offenses: offenses}
+
+		if s.Bytes, err = writeShard(db, filepath.Join(dir, s.File), nil, &year); err != nil {
+			return nil, err
+		}
+
+		m.Years = append(m.Years, s)
+	}
+
+	slices.SortFunc(m.Years, func(a, b YearShard) int { return a.Year - b.Year })
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding shards manifest: %w", err)
+	}
+
+	// #nosec G306 - public data, served by the web
+	if err := os.WriteFile(filepath.Join(dir, ShardsManifestFile), b, 0o644); err != nil {
+		return nil, fmt.Errorf("writing shards manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// commonTables lists the tables of the database but offenses.
+func commonTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT table_name FROM duckdb_tables()
+		WHERE database_name = current_database() AND schema_name = 'main'
+			AND NOT temporary AND table_name != 'offenses'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []string
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning table: %w", err)
+		}
+
+		ret = append(ret, name)
+	}
+
+	return ret, rows.Err()
+}
+
+// offenseYears counts the offenses of each year.
+func offenseYears(db *sql.DB) (map[int]int, error) {
+	rows, err := db.Query(`SELECT ` + shardYear + ` AS year, COUNT(*) FROM offenses GROUP BY year`)
+	if err != nil {
+		return nil, fmt.Errorf("counting offenses by year: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[int]int)
+
+	for rows.Next() {
+		var year, n int
+		if err := rows.Scan(&year, &n); err != nil {
+			return nil, fmt.Errorf("scanning year: %w", err)
+		}
+
+		ret[year] = n
+	}
+
+	return ret, rows.Err()
+}
+
+// writeShard writes tables, and the offenses of year when not nil, to a new
+// DuckDB file at path, returning its size.
+func writeShard(db *sql.DB, path string, tables []string, year *int) (size int64, err error) {
+	for _, p := range []string{path, path + ".wal"} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("removing %s: %w", p, err)
+		}
+	}
+
+	// ATTACH doesn't accept parameters; the directory was checked by Shards
+	if _, err := db.Exec(fmt.Sprintf("ATTACH '%s' AS shard", path)); err != nil {
+		return 0, fmt.Errorf("attaching %s: %w", path, err)
+	}
+
+	detached := false
+
+	defer func() {
+		if detached {
+			return
+		}
+
+		if _, derr := db.Exec("DETACH shard"); derr != nil {
+			err = errors.Join(err, fmt.Errorf("detaching %s: %w", path, derr))
+		}
+	}()
+
+	for _, table := range tables {
+		if _, err := db.Exec(fmt.Sprintf(`CREATE TABLE shard."%s" AS SELECT * FROM main."%s"`, table, table)); err != nil {
+			return 0, fmt.Errorf("copying %s to %s: %w", table, path, err)
+		}
+	}
+
+	if year != nil {
+		if _, err := db.Exec(
+			`CREATE TABLE shard.offenses AS SELECT * FROM main.offenses WHERE `+shardYear+` = ?
+			ORDER BY db_id, doc_source, record_id`,
+			*year,
+		); err != nil {
+			return 0, fmt.Errorf("copying the offenses of %d: %w", *year, err)
+		}
+	}
+
+	// the size is only final once the file is checkpointed and closed
+	if _, err := db.Exec("DETACH shard"); err != nil {
+		return 0, fmt.Errorf("detaching %s: %w", path, err)
+	}
+
+	detached = true
+
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("reading the size of %s: %w", path, err)
+	}
+
+	return st.Size(), nil
+}
+
+// ReadShardsManifest reads the manifest of the database split in dir.
+func ReadShardsManifest(dir string) (*ShardsManifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ShardsManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading shards manifest: %w", err)
+	}
+
+	var m ShardsManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("decoding shards manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// AttachShards attaches, read only, the database split in dir to db, usually
+// an in-memory one, with views named as the tables and views of the original
+// database.
+// The offenses are the ones of years, or of every year when empty.
+func AttachShards(db *sql.DB, dir string, years []int) error {
+	if strings.ContainsRune(dir, '\'') {
+		return fmt.Errorf("invalid shards directory %q", dir)
+	}
+
+	m, err := ReadShardsManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	shards := m.Years
+	if len(years) > 0 {
+		shards = nil
+
+		for _, year := range years {
+			s, ok := m.Shard(year)
+			if !ok {
+				return fmt.Errorf("no offenses of %d in %s", year, dir)
+			}
+
+			shards = append(shards, s)
+		}
+	}
+
+	attach := func(file, alias string) error {
+		path := filepath.Join(dir, file)
+		if _, err := db.Exec(fmt.Sprintf("ATTACH '%s' AS %s (READ_ONLY)", path, alias)); err != nil {
+			return fmt.Errorf("attaching %s: %w", path, err)
+		}
+
+		return nil
+	}
+
+	if err := attach(m.Common, "common"); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT table_name FROM duckdb_tables() WHERE database_name = 'common' ORDER BY table_name`)
+	if err != nil {
+		return fmt.Errorf("listing the common tables: %w", err)
+	}
+
+	var tables []string
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+
+			return fmt.Errorf("scanning table: %w", err)
+		}
+
+		tables = append(tables, name)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("listing the common tables: %w", err)
+	}
+
+	for _, table := range tables {
+		if _, err := db.Exec(fmt.Sprintf(`CREATE VIEW main."%s" AS SELECT * FROM common."%s"`, table, table)); err != nil {
+			return fmt.Errorf("creating the view of %s: %w", table, err)
+		}
+	}
+
+	if len(shards) == 0 {
+		return nil
+	}
+
+	selects := make([]string, len(shards))
+
+	for i, s := range shards {
+		alias := fmt.Sprintf("offenses_%d", s.Year)
+		if err := attach(s.File, alias); err != nil {
+			return err
+		}
+
+		selects[i] = "SELECT * FROM " + alias + ".offenses"
+	}
+
+	if _, err := db.Exec("CREATE VIEW main.offenses AS " + strings.Join(selects, " UNION ALL BY NAME ")); err != nil {
+		return fmt.Errorf("creating the view of offenses: %w", err)
+	}
+
+	// the views aren't copied to the shards
+	if _, err := db.Exec(impo.ActiveOffensesView); err != nil {
+		return fmt.Errorf("creating the view of active offenses: %w", err)
+	}
+
+	return nil
+}
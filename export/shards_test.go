@@ -0,0 +1,91 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShards(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "chapauy.duckdb")
+
+	db, err := dbutils.Open(source, dbutils.ReadWrite)
+	require.NoError(t, err)
+
+	repo, err := impo.NewSQLOffenseRepository(db)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSchema())
+	require.NoError(t, curation.NewDescriptionRepository(db).CreateSchema())
+
+	_, err = db.Exec(`
+		INSERT INTO offenses (db_id, doc_source, doc_date, record_id, vehicle, time_year) VALUES
+			(45, 'doc1', '2024-12-30', 1, 'AAA1111', 2024),
+			(45, 'doc1', '2024-12-30', 2, 'AAA2222', 2024),
+			(45, 'doc2', '2025-01-10', 1, 'AAA3333', NULL),
+			(45, 'doc3', NULL, 1, 'AAA4444', NULL);
+		UPDATE offenses SET superseded_by = 'doc2' WHERE doc_source = 'doc1' AND record_id = 2;
+		INSERT INTO articles (id, text, code, title) VALUES ('18.1', 'Velocidad', 1, 'Exceso de velocidad');
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// as chapa export opens it
+	db, err = dbutils.OpenAttached(source)
+	require.NoError(t, err)
+	defer db.Close()
+
+	dir := filepath.Join(t.TempDir(), "years")
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	m, err := Shards(db, dir, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, now, m.GeneratedAt)
+	require.Len(t, m.Years, 3)
+	assert.Equal(t, YearShard{Year: 0, File: "offenses-undated.duckdb", Offenses: 1, Bytes: m.Years[0].Bytes}, m.Years[0])
+	assert.Equal(t, 2024, m.Years[1].Year)
+	assert.Equal(t, 2, m.Years[1].Offenses)
+	// the offense without a time goes to the year of its document
+	assert.Equal(t, 2025, m.Years[2].Year)
+	assert.Equal(t, 1, m.Years[2].Offenses)
+
+	for _, s := range m.Years {
+		st, err := os.Stat(filepath.Join(dir, s.File))
+		require.NoError(t, err)
+		assert.Equal(t, st.Size(), s.Bytes)
+	}
+
+	read, err := ReadShardsManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, m, read)
+
+	shards, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer shards.Close()
+
+	require.NoError(t, AttachShards(shards, dir, []int{2024, 2025}))
+
+	var n int
+	require.NoError(t, shards.QueryRow("SELECT COUNT(*) FROM offenses").Scan(&n))
+	assert.Equal(t, 3, n)
+	require.NoError(t, shards.QueryRow("SELECT COUNT(*) FROM active_offenses").Scan(&n))
+	assert.Equal(t, 2, n)
+
+	var title string
+	require.NoError(t, shards.QueryRow("SELECT title FROM articles").Scan(&title))
+	assert.Equal(t, "Exceso de velocidad", title)
+
+	assert.ErrorContains(t, AttachShards(db, dir, []int{1999}), "no offenses of 1999")
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package export materializes the ChapaUY database in formats for consumers
+// that can't run DuckDB.
+package export
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sqliteStatements build the SQLite file, attached as "lite". DuckDB types
+// SQLite lacks are flattened: lists become ';' separated strings, points
+// lat/lng columns and timestamps ISO 8601 text. The fines are in UR, not in
+// the thousandths offenses stores, as there's no meta table to tell so.
+var sqliteStatements = []string{
+	`CREATE TABLE lite.offenses (
+		db_id INTEGER NOT NULL,
+		doc_id TEXT,
+		doc_date TEXT,
+		doc_source TEXT NOT NULL,
+		record_id INTEGER NOT NULL,
+		offense_id TEXT,
+		vehicle TEXT,
+		vehicle_country TEXT,
+		vehicle_type TEXT,
+		time TEXT,
+		time_year INTEGER,
+		location TEXT,
+		display_location TEXT,
+		description TEXT,
+		ur REAL,
+		error TEXT,
+		lat DOUBLE,
+		lng DOUBLE,
+		article_ids TEXT,
+		article_codes TEXT,
+		superseded_by TEXT,
+		PRIMARY KEY (doc_source, record_id)
+	)`,
+	`INSERT INTO lite.offenses
+		SELECT db_id, doc_id, strftime(doc_date, '%Y-%m-%d'), doc_source, record_id, offense_id,
+			vehicle, vehicle_country, vehicle_type, strftime("time" AT TIME ZONE 'UTC', '%Y-%m-%dT%H:%M:%SZ'),
+			time_year, location, display_location, description, ur / 1000.0, error,
+			point.y, point.x, array_to_string(article_ids, ';'), array_to_string(article_codes, ';'),
+			superseded_by
+		FROM offenses`,
+	`CREATE INDEX lite.offenses_vehicle ON offenses (vehicle)`,
+	`CREATE INDEX lite.offenses_db_time ON offenses (db_id, time)`,
+	`CREATE TABLE lite.locations (
+		id INTEGER PRIMARY KEY,
+		db_id INTEGER NOT NULL,
+		location TEXT NOT NULL,
+		canonical_location TEXT,
+		lat DOUBLE NOT NULL,
+		lng DOUBLE NOT NULL,
+		is_electronic BOOLEAN,
+		geocoding_method TEXT NOT NULL,
+		confidence TEXT NOT NULL,
+		notes TEXT NOT NULL,
+		nearest_place TEXT,
+		nearest_place_m DOUBLE,
+		UNIQUE (db_id, location)
+	)`,
+	`INSERT INTO lite.locations
+		SELECT id, db_id, location, canonical_location, point.y, point.x, is_electronic,
+			geocoding_method, confidence, notes, nearest_place, nearest_place_m
+		FROM locations`,
+	`CREATE TABLE lite.articles (
+		id TEXT PRIMARY KEY,
+		text TEXT NOT NULL,
+		code INTEGER NOT NULL,
+		title TEXT NOT NULL
+	)`,
+	`INSERT INTO lite.articles SELECT id, text, code, title FROM articles`,
+}
+
+// SQLite writes the offenses, locations and articles tables to a new SQLite
+// database at path, replacing any existing file. db must be able to attach
+// it: a database opened read-only can't, see dbutils.OpenAttached.
+func SQLite(db *sql.DB, path string) (err error) {
+	if strings.ContainsRune(path, '\'') {
+		return fmt.Errorf("invalid sqlite path %q", path)
+	}
+
+	if _, err := db.Exec(`INSTALL spatial; LOAD spatial; INSTALL sqlite; LOAD sqlite;`); err != nil {
+		return fmt.Errorf("loading extensions: %w", err)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing %s: %w", path, err)
+	}
+
+	// ATTACH doesn't accept parameters; the path was checked above
+	if _, err := db.Exec(fmt.Sprintf("ATTACH '%s' AS lite (TYPE SQLITE)", path)); err != nil {
+		return fmt.Errorf("attaching %s: %w", path, err)
+	}
+
+	defer func() {
+		if _, derr := db.Exec("DETACH lite"); derr != nil {
+			err = errors.Join(err, fmt.Errorf("detaching %s: %w", path, derr))
+		}
+	}()
+
+	for _, stmt := range sqliteStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("exporting to sqlite: %s: %w", strings.Join(strings.Fields(stmt)[:3], " "), err)
+		}
+	}
+
+	return nil
+}
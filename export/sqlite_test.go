@@ -0,0 +1,64 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"path/filepath"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/curation"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "chapauy.duckdb")
+
+	db, err := dbutils.Open(source, dbutils.ReadWrite)
+	require.NoError(t, err)
+
+	repo, err := impo.NewSQLOffenseRepository(db)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSchema())
+	require.NoError(t, curation.NewDescriptionRepository(db).CreateSchema())
+
+	require.NoError(t, curation.NewLocationRepository(db, nil).CreateSchema())
+
+	_, err = db.Exec(`
+		INSERT INTO offenses (db_id, doc_source, record_id, vehicle, ur, article_ids, point)
+		VALUES (45, 'doc1', 1, 'AAA1111', 7500, ['18.1', '18.2'], ST_Point(-54.9, -34.9)::POINT_2D);
+		INSERT INTO articles VALUES ('18.1', 'Velocidad', 1, 'Exceso de velocidad');
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// as chapa export opens it
+	db, err = dbutils.OpenAttached(source)
+	require.NoError(t, err)
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "chapauy.sqlite")
+	require.NoError(t, SQLite(db, path))
+
+	_, err = db.Exec("ATTACH '" + path + "' AS check_lite (TYPE SQLITE, READ_ONLY)")
+	require.NoError(t, err)
+
+	var (
+		articles string
+		lat, ur  float64
+	)
+
+	require.NoError(t, db.QueryRow("SELECT article_ids, lat, ur FROM check_lite.offenses").Scan(&articles, &lat, &ur))
+	assert.Equal(t, "18.1;18.2", articles)
+	assert.InDelta(t, -34.9, lat, 1e-9)
+	// in UR, not in thousandths
+	assert.InDelta(t, 7.5, ur, 1e-9)
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM check_lite.articles").Scan(&n))
+	assert.Equal(t, 1, n)
+}
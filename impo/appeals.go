@@ -0,0 +1,143 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jcodagnone/chapauy/utils/calendar"
+)
+
+// AppealBusinessDays is the number of días hábiles, counted from the
+// publication of the notification, that owners have to present descargos.
+const AppealBusinessDays = 10
+
+// AppealDeadline returns the last day to present descargos for an offense
+// notified in a document published on docDate.
+func AppealDeadline(docDate time.Time) time.Time {
+	return calendar.AddBusinessDays(docDate, AppealBusinessDays)
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// setAppealDeadline stores the appeal deadline of all the offenses of a
+// document.
+func setAppealDeadline(tx execer, docSource string, docDate time.Time) error {
+	if docDate.IsZero() {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE offenses SET appeal_deadline = ? WHERE doc_source = ? AND appeal_deadline IS DISTINCT FROM ?",
+		AppealDeadline(docDate), docSource, AppealDeadline(docDate),
+	); err != nil {
+		return fmt.Errorf("setting appeal deadline for %s: %w", docSource, err)
+	}
+
+	return nil
+}
+
+func (r *sqlOffenseRepository) BackfillAppealDeadlines() (int64, error) {
+	rows, err := r.db.Query(
+		"SELECT DISTINCT doc_date FROM offenses WHERE appeal_deadline IS NULL AND doc_date IS NOT NULL",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("querying documents without appeal deadline: %w", err)
+	}
+
+	var dates []time.Time
+
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning document date: %w", err)
+		}
+
+		dates = append(dates, d)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating document dates: %w", err)
+	}
+
+	var total int64
+
+	for _, d := range dates {
+		res, err := r.db.Exec(
+			"UPDATE offenses SET appeal_deadline = ? WHERE doc_date = ? AND appeal_deadline IS NULL",
+			AppealDeadline(d), d,
+		)
+		if err != nil {
+			return total, fmt.Errorf("backfilling appeal deadline for %s: %w", d.Format(time.DateOnly), err)
+		}
+
+		n, _ := res.RowsAffected()
+		total += n
+	}
+
+	return total, nil
+}
+
+// OpenAppeal is an offense whose appeal window has not expired yet.
+type OpenAppeal struct {
+	DbID           int       `json:"repo_id"`
+	DocSource      string    `json:"doc_source"`
+	DocDate        time.Time `json:"doc_date"`
+	RecordID       int       `json:"record_id"`
+	Vehicle        string    `json:"vehicle"`
+	Time           time.Time `json:"time,omitzero"`
+	Location       string    `json:"location,omitempty"`
+	NearestPlace   string    `json:"nearest_place,omitempty"` // populated place nearest to the location
+	NearestPlaceM  float64   `json:"nearest_place_m,omitempty"`
+	Description    string    `json:"description"`
+	UR             int       `json:"ur"`
+	AppealDeadline time.Time `json:"appeal_deadline"`
+}
+
+// ListOpenAppeals returns the active offenses that can still be appealed on
+// asOf, ordered by deadline.
+func ListOpenAppeals(db *sql.DB, asOf time.Time) ([]OpenAppeal, error) {
+	rows, err := db.Query(`
+		SELECT o.db_id, o.doc_source, o.doc_date, o.record_id, COALESCE(o.vehicle, ''), o."time",
+			COALESCE(o.display_location, o.location, ''), COALESCE(l.nearest_place, ''),
+			COALESCE(l.nearest_place_m, 0), COALESCE(o.description, ''), COALESCE(o.ur, 0), o.appeal_deadline
+		FROM active_offenses o
+		LEFT JOIN locations l ON l.db_id = o.db_id AND l.location = o.location
+		WHERE o.error IS NULL AND o.appeal_deadline >= ?::DATE
+		ORDER BY o.appeal_deadline, o.db_id, o.doc_source, o.record_id
+	`, asOf.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("querying open appeals: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []OpenAppeal
+
+	for rows.Next() {
+		var (
+			a    OpenAppeal
+			when sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&a.DbID, &a.DocSource, &a.DocDate, &a.RecordID, &a.Vehicle, &when,
+			&a.Location, &a.NearestPlace, &a.NearestPlaceM, &a.Description, &a.UR, &a.AppealDeadline,
+		); err != nil {
+			return nil, fmt.Errorf("scanning open appeal: %w", err)
+		}
+
+		a.Time = when.Time
+		ret = append(ret, a)
+	}
+
+	return ret, rows.Err()
+}
@@ -0,0 +1,17 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppealDeadline(t *testing.T) {
+	// published on Thursday 2025-08-21, Monday 25 is Independencia
+	got := AppealDeadline(time.Date(2025, time.August, 21, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2025-09-05", got.Format(time.DateOnly))
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// BenchRows is the number of rows of the synthetic documents of the
+// benchmarks, larger than any document published so far.
+const BenchRows = 10_000
+
+// ExtractBudget is the time the extraction of a synthetic document of
+// BenchRows rows may take, parsing included. It takes a fraction of it on a
+// current machine; going over means a change will slow the nightly runs down.
+const ExtractBudget = 2 * time.Second
+
+// extractRuns is the number of times MeasureExtraction extracts the document,
+// keeping the fastest so a busy CI runner doesn't fail it.
+const extractRuns = 3
+
+// ErrOverBudget is returned when the extraction takes longer than its budget.
+var ErrOverBudget = errors.New("extraction over budget")
+
+// benchHeaders are the headers of the synthetic documents, as Maldonado
+// writes them.
+var benchHeaders = []string{"Matricula", "Fecha y Hora", "Interseccion", "Intervenido", "Articulo", "Valor en UR"}
+
+// SyntheticDocument returns an IMPO document with a table of rows offenses,
+// with the markup of the real ones.
+func SyntheticDocument(rows int) string {
+	const cell = `<TD style="text-align:left;vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>%s</pre></TD>`
+
+	var sb strings.Builder
+
+	sb.WriteString(`<html>
+	<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Maldonado N° 1/025</title>
+	<h5>Fecha de Publicación: 01/02/2025</h5>
+	<table class="tabla_en_texto">
+	<TR>`)
+
+	for _, h := range benchHeaders {
+		fmt.Fprintf(&sb, cell, h)
+	}
+
+	sb.WriteString("</TR>\n")
+
+	for i := range rows {
+		sb.WriteString("<TR>")
+		fmt.Fprintf(&sb, cell, fmt.Sprintf("AAB%04d", i%10_000))
+		fmt.Fprintf(&sb, cell, fmt.Sprintf("%02d/01/2025 %02d:%02d", 1+i%28, i%24, i%60))
+		fmt.Fprintf(&sb, cell, fmt.Sprintf("Av. Roosevelt y Parada %d", i%40))
+		fmt.Fprintf(&sb, cell, fmt.Sprintf("IDM %010d", i))
+		fmt.Fprintf(&sb, cell, "Exceso de velocidad hasta 20 km/h")
+		fmt.Fprintf(&sb, cell, fmt.Sprintf("%d,%d", 2+i%8, i%10))
+		sb.WriteString("</TR>\n")
+	}
+
+	sb.WriteString("</table></html>")
+
+	return sb.String()
+}
+
+// MeasureExtraction parses and extracts a synthetic document of rows
+// offenses, returning the time it took and the budget for it, ExtractBudget
+// scaled to rows. It fails with ErrOverBudget when it takes longer.
+func MeasureExtraction(rows int) (time.Duration, time.Duration, error) {
+	budget := ExtractBudget * time.Duration(rows) / BenchRows
+	src := SyntheticDocument(rows)
+
+	var best time.Duration
+
+	for i := range extractRuns {
+		start := time.Now()
+
+		n, err := html.Parse(strings.NewReader(src))
+		if err != nil {
+			return 0, budget, fmt.Errorf("parsing synthetic document: %w", err)
+		}
+
+		offenses, err := ExtractDocument(nil, "", n)
+		if err != nil {
+			return 0, budget, fmt.Errorf("extracting synthetic document: %w", err)
+		}
+
+		if len(offenses) != rows {
+			return 0, budget, fmt.Errorf("extracting synthetic document: %d offenses, expected %d", len(offenses), rows)
+		}
+
+		if elapsed := time.Since(start); i == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+
+	if best > budget {
+		return best, budget, fmt.Errorf("%w: %d rows took %v, the budget is %v", ErrOverBudget, rows, best, budget)
+	}
+
+	return best, budget, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestSyntheticDocument(t *testing.T) {
+	n, err := html.Parse(strings.NewReader(SyntheticDocument(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offenses, err := ExtractDocument(nil, "", n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(offenses) != 100 {
+		t.Fatalf("expected 100 offenses, got %d", len(offenses))
+	}
+
+	for _, o := range offenses {
+		if o.Error != "" {
+			t.Fatalf("record %d: unexpected error %q", o.RecordID, o.Error)
+		}
+	}
+}
+
+func TestExtractBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures the extraction of a large document")
+	}
+
+	elapsed, budget, err := MeasureExtraction(BenchRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("%d rows in %v, budget %v", BenchRows, elapsed, budget)
+}
+
+func runBenchmark(b *testing.B, name string) {
+	b.Helper()
+
+	benches, err := benchmarks(BenchRows)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, bench := range benches {
+		if bench.Name == name {
+			bench.Run(b)
+
+			return
+		}
+	}
+
+	b.Fatalf("unknown benchmark %q", name)
+}
+
+func BenchmarkExtractDocument(b *testing.B) {
+	runBenchmark(b, "ExtractDocument")
+}
+
+func BenchmarkStreamOffenses(b *testing.B) {
+	runBenchmark(b, "streamOffenses")
+}
+
+func BenchmarkVisitOffensesTable(b *testing.B) {
+	runBenchmark(b, "visitOffensesTable")
+}
+
+func BenchmarkDocumentPropertyFromString(b *testing.B) {
+	runBenchmark(b, "documentPropertyFromString")
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	runBenchmark(b, "normalize")
+}
+
+// benchmark is one of the benchmarks of the extraction.
+type benchmark struct {
+	Name string
+	Run  func(b *testing.B)
+}
+
+// benchmarks returns the benchmarks of the hot paths of the extraction over a
+// synthetic document of rows offenses.
+func benchmarks(rows int) ([]benchmark, error) {
+	src := SyntheticDocument(rows)
+
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("parsing synthetic document: %w", err)
+	}
+
+	table := findOffensesTable(doc)
+	if table == nil {
+		return nil, errors.New("synthetic document without table")
+	}
+
+	pubDate := time.Date(2025, 2, 1, 0, 0, 0, 0, UruguayTimezone)
+
+	return []benchmark{
+		{
+			Name: "ExtractDocument",
+			Run: func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(src)))
+
+				for b.Loop() {
+					n, err := html.Parse(strings.NewReader(src))
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					if _, err := ExtractDocument(nil, "", n); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		},
+		{
+			Name: "streamOffenses",
+			Run: func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(src)))
+
+				for b.Loop() {
+					if _, err := streamOffenses(nil, "", strings.NewReader(src), false, nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		},
+		{
+			Name: "visitOffensesTable",
+			Run: func(b *testing.B) {
+				b.ReportAllocs()
+
+				for b.Loop() {
+					offenses := make([]*TrafficOffense, 0, rows)
+					if err := visitOffensesTable(table, &offenses, &pubDate, "", nil, false, nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			},
+		},
+		{
+			Name: "documentPropertyFromString",
+			Run: func(b *testing.B) {
+				b.ReportAllocs()
+
+				for b.Loop() {
+					for i := range rows {
+						if _, err := documentPropertyFromString(benchHeaders[i%len(benchHeaders)]); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			},
+		},
+		{
+			Name: "normalize",
+			Run: func(b *testing.B) {
+				b.ReportAllocs()
+
+				for b.Loop() {
+					for i := range rows {
+						normalize(benchHeaders[i%len(benchHeaders)])
+					}
+				}
+			},
+		},
+	}, nil
+}
+
+// findOffensesTable returns the body of the first table of offenses.
+func findOffensesTable(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && strings.EqualFold(n.Data, "tbody") &&
+		n.Parent != nil && strings.EqualFold(n.Parent.Data, "table") {
+		return n
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if ret := findOffensesTable(child); ret != nil {
+			return ret
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// Defaults of the databases without an entry in budgets.json.
+const (
+	DefaultMaxErrorPct = 5.0
+	DefaultMaxJumpPct  = 2.0
+	DefaultTrendRuns   = 10
+)
+
+// defaultErrorBudgets are the error budgets of the databases whose documents
+// are known to be dirtier (or cleaner) than the rest.
+//
+//go:embed budgets.json
+var defaultErrorBudgets []byte
+
+// ErrorBudget is the share of records with errors the extraction of a
+// database tolerates. Lavalleja routinely has ~8% of unparseable rows, while
+// in Montevideo any error is suspicious.
+type ErrorBudget struct {
+	DbID int    `json:"db_id"`
+	Name string `json:"name,omitempty"` // only for humans reading the file
+	// MaxErrorPct is the percentage of errors (over the valid records) above
+	// which a document isn't saved: a failsafe to catch extraction errors.
+	MaxErrorPct float64 `json:"max_error_pct,omitempty"`
+	// MaxJumpPct is how many points the error rate of a run may exceed the
+	// one of the previous TrendRuns runs before raising an alarm.
+	MaxJumpPct float64 `json:"max_jump_pct,omitempty"`
+	TrendRuns  int     `json:"trend_runs,omitempty"`
+	// Reviewed are the documents above MaxErrorPct that were reviewed as ok,
+	// usually because they have few records.
+	Reviewed []string `json:"reviewed,omitempty"`
+}
+
+// withDefaults fills the zero values with the defaults.
+func (b ErrorBudget) withDefaults() ErrorBudget {
+	if b.MaxErrorPct == 0 {
+		b.MaxErrorPct = DefaultMaxErrorPct
+	}
+
+	if b.MaxJumpPct == 0 {
+		b.MaxJumpPct = DefaultMaxJumpPct
+	}
+
+	if b.TrendRuns == 0 {
+		b.TrendRuns = DefaultTrendRuns
+	}
+
+	return b
+}
+
+// Allows tells whether a document with pct percent of errors can be saved.
+func (b ErrorBudget) Allows(docSource string, pct float64) bool {
+	return pct <= b.MaxErrorPct || slices.Contains(b.Reviewed, docSource)
+}
+
+func addErrorBudgets(dbs []DbReference, r io.Reader) error {
+	var entries []ErrorBudget
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("decoding error budgets: %w", err)
+	}
+
+	for _, e := range entries {
+		i := dbIndex(dbs, e.DbID)
+		if i < 0 {
+			return fmt.Errorf("error budgets: %w: %d", errDatabaseNotFound, e.DbID)
+		}
+
+		// the reviewed documents add up, the limits replace the previous ones
+		reviewed := slices.Concat(dbs[i].Budget.Reviewed, e.Reviewed)
+
+		if e.MaxErrorPct == 0 {
+			e.MaxErrorPct = dbs[i].Budget.MaxErrorPct
+		}
+
+		if e.MaxJumpPct == 0 {
+			e.MaxJumpPct = dbs[i].Budget.MaxJumpPct
+		}
+
+		if e.TrendRuns == 0 {
+			e.TrendRuns = dbs[i].Budget.TrendRuns
+		}
+
+		e.DbID, e.Name, e.Reviewed = dbs[i].ID, dbs[i].Name, reviewed
+		dbs[i].Budget = e.withDefaults()
+	}
+
+	return nil
+}
+
+// LoadErrorBudgets applies the budgets of a JSON file, with the format of
+// budgets.json, on top of the ones built in. It must be called before
+// creating the clients.
+func LoadErrorBudgets(path string) error {
+	f, err := os.Open(path) // #nosec G304 - path comes from the command line
+	if err != nil {
+		return fmt.Errorf("opening error budgets: %w", err)
+	}
+	defer f.Close()
+
+	return addErrorBudgets(databases, f)
+}
+
+// ExtractionRun summarizes the extraction phase of a database, to follow the
+// trend of its error rate.
+type ExtractionRun struct {
+	DbID      int
+	StartedAt time.Time
+	Documents int
+	Records   int
+	Errors    int
+}
+
+// ErrorPct is the percentage of errors over the valid records, as the budgets
+// measure it.
+func (r ExtractionRun) ErrorPct() float64 {
+	if r.Records == 0 {
+		return 0
+	}
+
+	return float64(r.Errors) / float64(r.Records) * 100.0
+}
+
+// ErrorRateAlarm reports a run whose error rate jumped above the trend of the
+// previous ones, or above the budget.
+type ErrorRateAlarm struct {
+	DbName   string
+	ErrorPct float64
+	TrendPct float64 // error rate of the previous runs
+	Runs     int     // number of previous runs in the trend
+}
+
+// checkErrorTrend compares the run with the previous ones, the most recent
+// first, returning an alarm if its error rate is over budget.
+func checkErrorTrend(budget ErrorBudget, dbName string, run ExtractionRun, previous []ExtractionRun) *ErrorRateAlarm {
+	if run.Records == 0 {
+		return nil
+	}
+
+	if len(previous) > budget.TrendRuns {
+		previous = previous[:budget.TrendRuns]
+	}
+
+	trend := ExtractionRun{}
+	for _, p := range previous {
+		trend.Records += p.Records
+		trend.Errors += p.Errors
+	}
+
+	alarm := &ErrorRateAlarm{
+		DbName:   dbName,
+		ErrorPct: run.ErrorPct(),
+		TrendPct: trend.ErrorPct(),
+		Runs:     len(previous),
+	}
+
+	switch {
+	case alarm.ErrorPct > budget.MaxErrorPct:
+		return alarm
+	case trend.Records > 0 && alarm.ErrorPct-alarm.TrendPct > budget.MaxJumpPct:
+		return alarm
+	default:
+		return nil
+	}
+}
+
+func (r *sqlOffenseRepository) SaveExtractionRun(run ExtractionRun) error {
+	if _, err := r.db.Exec(`
+		INSERT INTO extraction_runs (db_id, started_at, documents, records, errors)
+		VALUES (?, ?, ?, ?, ?)
+	`, run.DbID, run.StartedAt, run.Documents, run.Records, run.Errors); err != nil {
+		return fmt.Errorf("saving extraction run: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqlOffenseRepository) ListExtractionRuns(dbID int, limit int) ([]ExtractionRun, error) {
+	rows, err := r.db.Query(`
+		SELECT db_id, started_at, documents, records, errors
+		FROM extraction_runs
+		WHERE db_id = ?
+		ORDER BY started_at DESC
+		LIMIT ?
+	`, dbID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying extraction runs: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []ExtractionRun
+
+	for rows.Next() {
+		var run ExtractionRun
+		if err := rows.Scan(&run.DbID, &run.StartedAt, &run.Documents, &run.Records, &run.Errors); err != nil {
+			return nil, fmt.Errorf("scanning extraction run: %w", err)
+		}
+
+		ret = append(ret, run)
+	}
+
+	return ret, rows.Err()
+}
+
+// trackErrorRate records the run and raises an alarm if its error rate jumped
+// over the budget of the database.
+func (c *Client) trackErrorRate(run ExtractionRun) error {
+	if run.Documents == 0 {
+		return nil
+	}
+
+	previous, err := c.repo.ListExtractionRuns(c.dbRef.ID, c.dbRef.Budget.TrendRuns)
+	if err != nil {
+		return err
+	}
+
+	if alarm := checkErrorTrend(c.dbRef.Budget, c.dbRef.Name, run, previous); alarm != nil {
+		log.Printf("⚠️  %s error rate is %.2f%%, %.2f%% over the last %d runs (budget %.1f%%, max jump %.1f)",
+			alarm.DbName, alarm.ErrorPct, alarm.TrendPct, alarm.Runs, c.dbRef.Budget.MaxErrorPct, c.dbRef.Budget.MaxJumpPct)
+		c.Metrics.ErrorRateAlarms = append(c.Metrics.ErrorRateAlarms, *alarm)
+	}
+
+	if c.options.DryRun {
+		return nil
+	}
+
+	return c.repo.SaveExtractionRun(run)
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddErrorBudgets(t *testing.T) {
+	dbs := []DbReference{{ID: 26, Name: "Lavalleja"}}
+	dbs[0].Budget = ErrorBudget{DbID: 26}.withDefaults()
+
+	err := addErrorBudgets(dbs, strings.NewReader(`[{"db_id": 26, "max_error_pct": 10, "reviewed": ["a"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = addErrorBudgets(dbs, strings.NewReader(`[{"db_id": 26, "max_jump_pct": 4, "reviewed": ["b"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := dbs[0].Budget
+	if b.MaxErrorPct != 10 || b.MaxJumpPct != 4 || b.TrendRuns != DefaultTrendRuns {
+		t.Errorf("unexpected budget %+v", b)
+	}
+
+	if strings.Join(b.Reviewed, "|") != "a|b" {
+		t.Errorf("expected the reviewed documents to add up, got %q", b.Reviewed)
+	}
+
+	if !b.Allows("x", 8) || b.Allows("x", 11) || !b.Allows("a", 50) {
+		t.Error("unexpected Allows")
+	}
+
+	err = addErrorBudgets(dbs, strings.NewReader(`[{"db_id": 1}]`))
+	if !errors.Is(err, errDatabaseNotFound) {
+		t.Errorf("expected errDatabaseNotFound, got %v", err)
+	}
+}
+
+func TestDefaultErrorBudgets(t *testing.T) {
+	lavalleja, err := Find("Lavalleja")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	montevideo, err := Find("Montevideo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canelones, err := Find("Canelones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !lavalleja.Budget.Allows("", 8) || montevideo.Budget.Allows("", 2) {
+		t.Errorf("unexpected budgets %+v %+v", lavalleja.Budget, montevideo.Budget)
+	}
+
+	if canelones.Budget.MaxErrorPct != DefaultMaxErrorPct {
+		t.Errorf("expected the default budget, got %+v", canelones.Budget)
+	}
+}
+
+func TestCheckErrorTrend(t *testing.T) {
+	budget := ErrorBudget{MaxErrorPct: 10, MaxJumpPct: 2, TrendRuns: 2}
+	previous := []ExtractionRun{
+		{Records: 100, Errors: 8},
+		{Records: 100, Errors: 7},
+		{Records: 100, Errors: 0}, // out of the trend
+	}
+
+	tests := []struct {
+		name  string
+		run   ExtractionRun
+		alarm bool
+	}{
+		{"within the trend", ExtractionRun{Records: 100, Errors: 9}, false},
+		{"jump", ExtractionRun{Records: 100, Errors: 10}, true},
+		{"over budget", ExtractionRun{Records: 10, Errors: 2}, true},
+		{"no records", ExtractionRun{}, false},
+	}
+
+	for _, tt := range tests {
+		alarm := checkErrorTrend(budget, "Lavalleja", tt.run, previous)
+		if (alarm != nil) != tt.alarm {
+			t.Errorf("%s: expected alarm %v, got %+v", tt.name, tt.alarm, alarm)
+		}
+	}
+
+	if alarm := checkErrorTrend(budget, "Lavalleja", ExtractionRun{Records: 100, Errors: 9}, nil); alarm != nil {
+		t.Errorf("expected no alarm without history under budget, got %+v", alarm)
+	}
+
+	alarm := checkErrorTrend(budget, "Lavalleja", ExtractionRun{Records: 100, Errors: 10}, previous)
+	if alarm == nil || alarm.Runs != 2 || alarm.TrendPct != 7.5 {
+		t.Errorf("unexpected alarm %+v", alarm)
+	}
+}
@@ -4,18 +4,25 @@
 package impo
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/jcodagnone/chapauy/utils/concurrency"
 	"github.com/jcodagnone/chapauy/utils/htmlutils"
 	"github.com/jcodagnone/chapauy/utils/httputils"
+	"github.com/jcodagnone/chapauy/utils/progress"
 )
 
 // Common errors returned by the client.
@@ -31,14 +38,38 @@ const (
 	allowRedirectKey contextKey = "allowRedirect"
 )
 
-// ClientOptions configuration for ImpoClient.
+// ClientOptions configures a Client. The zero value searches, downloads and
+// extracts every document, see NewClient for setting it with options.
 type ClientOptions struct {
 	// DbPath is the root path for the database
 	DbPath string
 
+	// ArchivePath is the root path of the downloaded documents, see
+	// DocumentsPath
+	ArchivePath string
+
 	// UserAgent is the User-Agent header to use in HTTP requests
 	UserAgent string
 
+	// Proxy is the proxy of the requests to IMPO. Nil uses the one of the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	Proxy *url.URL
+
+	// RootCAs are the certificates trusted for IMPO, like the ones of a
+	// corporate proxy inspecting TLS. Nil trusts the system ones
+	RootCAs *x509.CertPool
+
+	// RequestDelay is the minimum time between two requests to IMPO
+	RequestDelay time.Duration
+
+	// CrawlWindow restricts the search and download phases to a time of the
+	// day. The zero value allows crawling at any time
+	CrawlWindow CrawlWindow
+
+	// Schedule skips the search phase of the databases unlikely to have new
+	// documents. Nil searches every database on every run
+	Schedule *Schedule
+
 	// Enables light tracing of HTTP requests and responses
 	EnableHTTPTrace bool
 
@@ -51,9 +82,17 @@ type ClientOptions struct {
 	// Overrides incremental search and traverses all pages
 	SearchFull bool
 
+	// Only search documents published on or after this date. When zero, the
+	// search starts a week before the last search (unless SearchFull is set)
+	Since time.Time
+
 	// Skips the download phase (downloading known missing documents)
 	SkipDownload bool
 
+	// Also re-downloads existing documents using conditional requests, to
+	// detect documents edited after publication
+	DownloadRefresh bool
+
 	// Skips the extraction phase (extracting information from available documents)
 	SkipExtract bool
 
@@ -69,8 +108,57 @@ type ClientOptions struct {
 	// Dry run, don't persist any change
 	DryRun bool
 
+	// Max number of concurrent downloads. Zero means one: RequestDelay is
+	// shared by all of them anyway.
+	DownloadMaxProcs int
+
 	// Max number of processes to use in the extraction phase.
 	ExtractMaxProcs int
+
+	// Maximum time to extract a single document. Zero means no limit.
+	ExtractTimeout time.Duration
+
+	// Documents larger than this many bytes are not extracted. Zero means no limit.
+	ExtractMaxBytes int64
+
+	// Documents larger than this many bytes are extracted with the tokenizer,
+	// without building the DOM of the whole document. Zero means never.
+	ExtractStreamBytes int64
+
+	// When set, the extraction only processes the documents extracted by
+	// an ExtractorVersion older than this one.
+	ReextractBelow int
+
+	// Restricts the re-extraction to the documents of a year, according to
+	// their URL. Zero re-extracts the ones of every year.
+	ReextractYear int
+
+	// On a dry run, receives the documents whose records the re-extraction
+	// would change, see reextractDiff.
+	ReextractDiff io.Writer
+
+	// Keep the original cells of every row, to find out later what the
+	// document said before normalization.
+	KeepRaw bool
+
+	// Ignore the table headers no property is known for instead of failing
+	// the document, recording them in pending_headers for the curators.
+	LearnHeaders bool
+
+	// Progress reports the progress of the search, download and extraction
+	// phases. Nil logs every page and document, as before the bars.
+	Progress *progress.Reporter
+}
+
+// DocumentsPath returns where the downloaded documents are stored:
+// ArchivePath, or DbPath when it is not set. Keeping them apart from the
+// DuckDB file lets the images that only need the database skip them.
+func (o *ClientOptions) DocumentsPath() string {
+	if o.ArchivePath != "" {
+		return o.ArchivePath
+	}
+
+	return o.DbPath
 }
 
 // ClientMetrics tracks various metrics collected during client operations.
@@ -78,6 +166,7 @@ type ClientMetrics struct {
 	SearchMetrics
 	DownloadMetrics
 	ExtractMetrics
+	LinkMetrics
 }
 
 // Merge combines the metrics from another ClientMetrics instance into this one.
@@ -89,10 +178,12 @@ func (m *ClientMetrics) Merge(other *ClientMetrics) *ClientMetrics {
 	m.SearchMetrics.Merge(&other.SearchMetrics)
 	m.DownloadMetrics.Merge(&other.DownloadMetrics)
 	m.ExtractMetrics.Merge(&other.ExtractMetrics)
+	m.LinkMetrics.Merge(&other.LinkMetrics)
 
 	return m
 }
 
+// Client scrapes a database of IMPO, one of the ones listed in
 // "Consultar bases de infracciones y multas de tránsito publicadas en el Diario Oficial".
 type Client struct {
 	dbRef   *DbReference
@@ -100,10 +191,16 @@ type Client struct {
 	options *ClientOptions
 	store   *FileStore
 	repo    OffenseRepository
-	Metrics ClientMetrics
+	changed []string // documents whose content changed in this run
+	// headers mapped by the curators, loaded when the extraction starts
+	headerSynonyms map[string]OffenseProperty
+	// spellings of the countries mapped by the curators, same
+	countrySynonyms map[string]string
+	Metrics         ClientMetrics
 }
 
-// NewImpoClient creates a new client with the provided options and database reference.
+// NewImpoClient creates a new client with the provided options and database
+// reference. The client keeps options, so changing them later changes it.
 func NewImpoClient(options *ClientOptions, dbRef *DbReference, repo OffenseRepository) *Client {
 	if options == nil {
 		options = &ClientOptions{}
@@ -135,11 +232,12 @@ func NewImpoClient(options *ClientOptions, dbRef *DbReference, repo OffenseRepos
 		DisableKeepAlives:     false,
 		DisableCompression:    false,
 	}
+	httputils.ConfigureProxy(transport, options.Proxy, options.RootCAs)
 
 	loggingTransport := &httputils.LoggingRoundTripper{
 		Writer:    httpLogWriter,
 		DumpBody:  options.EnableHTTPBodyTrace,
-		Transport: transport,
+		Transport: &httputils.ProxyErrorRoundTripper{Transport: transport},
 	}
 
 	userAgent := "chapauy/unknown"
@@ -147,12 +245,19 @@ func NewImpoClient(options *ClientOptions, dbRef *DbReference, repo OffenseRepos
 		userAgent = options.UserAgent
 	}
 
+	politeTransport := &httputils.PoliteRoundTripper{
+		Delay:         options.RequestDelay,
+		MaxRetries:    3,
+		MaxRetryAfter: 5 * time.Minute,
+		Transport:     loggingTransport,
+	}
+
 	headerTransport := &httputils.AppendRequestHeadersRoundTripper{
 		Headers: map[string]string{
 			"User-Agent": userAgent,
 			"Accept":     "*/*",
 		},
-		Transport: loggingTransport,
+		Transport: politeTransport,
 	}
 
 	client := &http.Client{
@@ -175,97 +280,187 @@ func NewImpoClient(options *ClientOptions, dbRef *DbReference, repo OffenseRepos
 	return &Client{
 		dbRef:   dbRef,
 		client:  client,
-		store:   NewFileStore(options.DbPath, dbRef),
+		store:   NewFileStore(options.DocumentsPath(), dbRef),
 		repo:    repo,
 		options: options,
 	}
 }
 
+// CrawlWindow is a daily time range of Uruguay, e.g. 01:00-06:00, in which
+// requests to IMPO are allowed. Ranges may wrap around midnight (22:00-06:00).
+type CrawlWindow struct {
+	Start time.Duration // offset from midnight
+	End   time.Duration // offset from midnight
+}
+
+// ParseCrawlWindow parses a window in HH:MM-HH:MM format. An empty string is
+// the zero window, which allows any time.
+func ParseCrawlWindow(s string) (CrawlWindow, error) {
+	if s == "" {
+		return CrawlWindow{}, nil
+	}
+
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return CrawlWindow{}, fmt.Errorf("crawl window %q: expected HH:MM-HH:MM", s)
+	}
+
+	var w CrawlWindow
+
+	for _, p := range []struct {
+		value string
+		dst   *time.Duration
+	}{{start, &w.Start}, {end, &w.End}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(p.value))
+		if err != nil {
+			return CrawlWindow{}, fmt.Errorf("crawl window %q: %w", s, err)
+		}
+
+		*p.dst = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	return w, nil
+}
+
+// Contains reports whether the time of the day of t in Uruguay falls in the
+// window. The hosts usually run in UTC.
+func (w CrawlWindow) Contains(t time.Time) bool {
+	if w.Start == w.End {
+		return true
+	}
+
+	t = t.In(UruguayTimezone)
+
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+
+	return offset >= w.Start || offset < w.End
+}
+
+func (w CrawlWindow) String() string {
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+
+	return format(w.Start) + "-" + format(w.End)
+}
+
 // DownloadMetrics tracks statistics about the download process.
 type DownloadMetrics struct {
-	DownloadsOk  int
-	DownloadsErr int
+	DownloadsOk          int
+	DownloadsErr         int
+	DownloadsNotModified int           // documents the server (or a byte comparison) reported unchanged
+	DownloadsChanged     int           // documents whose content differs from the local copy
+	DownloadTime         time.Duration // spent in the successful downloads, adding the workers
 }
 
 // Merge combines two DownloadMetrics.
 func (f *DownloadMetrics) Merge(o *DownloadMetrics) *DownloadMetrics {
 	f.DownloadsOk += o.DownloadsOk
 	f.DownloadsErr += o.DownloadsErr
+	f.DownloadsNotModified += o.DownloadsNotModified
+	f.DownloadsChanged += o.DownloadsChanged
+	f.DownloadTime += o.DownloadTime
 
 	return f
 }
 
-// Downloads missing HTML documents.
+// Downloads missing HTML documents and, with DownloadRefresh, revalidates the
+// existing ones with conditional requests.
 func (c *Client) downloadMissing() error {
-	missing, err := c.store.MissingDocuments()
+	ids, err := c.store.MissingDocuments()
 	if err != nil {
 		return fmt.Errorf("getting missing documents: %w", err)
 	}
 
-	if len(missing) == 0 {
+	if c.options.DownloadRefresh {
+		existing, err := c.store.ExistingDocuments()
+		if err != nil {
+			return fmt.Errorf("getting existing documents: %w", err)
+		}
+
+		ids = append(ids, existing...)
+	}
+
+	if len(ids) == 0 {
 		log.Println("Nothing to download")
 	}
 
-	slices.Sort(missing)
-	n := len(missing)
+	validators, err := c.store.LoadValidators()
+	if err != nil {
+		return err
+	}
 
-	var errs []error
+	slices.Sort(ids)
+	n := len(ids)
 
-	for i, id := range missing {
-		log.Printf("[%d/%d] Downloading %s", i+1, n, id)
+	// the workers only read the validators, the new ones are stored as the
+	// downloads complete
+	tasks := make([]downloadTask, len(ids))
+	for i, id := range ids {
+		tasks[i].id = id
+		tasks[i].previous, tasks[i].known = validators[id]
+	}
 
-		resp, err := c.client.Get(id)
-		if err != nil {
-			c.Metrics.DownloadsErr++
+	var errs []error
 
-			errs = append(errs, err)
-			log.Printf("[%d/%d] Download failed: %s", i+1, n, err)
+	phase := c.options.Progress.Start("Downloads "+c.dbRef.Name, n)
+	defer phase.Finish()
 
-			continue
-		}
+	_, err = concurrency.Run(context.Background(), max(c.options.DownloadMaxProcs, 1), tasks,
+		func(_ context.Context, t downloadTask) (downloadResult, error) {
+			return c.download(t)
+		},
+		func(r concurrency.Result[downloadTask, downloadResult]) {
+			i, id := r.Index, r.Task.id
 
-		r, err := htmlutils.AsReader(resp)
-		if err != nil {
-			errs = append(
-				errs,
-				errors.Join(
-					resp.Body.Close(),
-					fmt.Errorf("reading response body: %w", err),
-				),
-			)
+			if r.Err != nil {
+				errs = append(errs, r.Err)
+				phase.Add(1)
+				phase.Logf("[%d/%d] Download of %s failed after %s: %s", i+1, n, id, r.Duration, r.Err)
 
-			log.Printf("[%d/%d] Parsing: %s", i+1, n, err)
+				return
+			}
 
-			continue
-		}
+			phase.Step("[%d/%d] Downloaded %s in %s", i+1, n, id, r.Duration.Round(time.Millisecond))
 
-		if !c.options.DryRun {
-			if err := c.store.SaveDocument(id, r); err != nil {
-				errs = append(
-					errs,
-					errors.Join(
-						resp.Body.Close(),
-						fmt.Errorf("saving document: %q %w", id, err),
-					),
-				)
-
-				log.Printf("[%d/%d] Saving document: %s", i+1, n, err)
+			if r.Value.validators != (Validators{}) {
+				validators[id] = r.Value.validators
 			}
-		}
 
-		if err := resp.Body.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("closing request: %q %w", id, err))
-			log.Printf("[%d/%d] Closing response: %s", i+1, n, err)
-		}
+			switch r.Value.status {
+			case downloadNotModified:
+				c.Metrics.DownloadsNotModified++
+			case downloadChanged:
+				phase.Logf("[%d/%d] %s changed since it was downloaded", i+1, n, id)
+				c.Metrics.DownloadsChanged++
+				c.changed = append(c.changed, id)
+			}
+
+			c.Metrics.DownloadsOk++
+			c.Metrics.DownloadTime += r.Duration
+		},
+	)
+	if err != nil {
+		errs = append(errs, err)
+	}
 
-		c.Metrics.DownloadsOk++
+	if !c.options.DryRun {
+		if err := c.store.SaveValidators(validators); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	c.Metrics.DownloadsErr += len(errs)
 	if c.Metrics.DownloadsOk != 0 || c.Metrics.DownloadsErr != 0 {
 		log.Printf(
-			"Download phase completed - %d successful, %d failed",
+			"Download phase completed - %d successful (%d not modified, %d changed), %d failed",
 			c.Metrics.DownloadsOk,
+			c.Metrics.DownloadsNotModified,
+			c.Metrics.DownloadsChanged,
 			c.Metrics.DownloadsErr,
 		)
 	}
@@ -277,11 +472,122 @@ func (c *Client) downloadMissing() error {
 	return nil
 }
 
+type downloadStatus int
+
+const (
+	downloadNew downloadStatus = iota
+	downloadNotModified
+	downloadChanged
+)
+
+// downloadTask is a document to download, with the validators of its previous
+// download if known.
+type downloadTask struct {
+	id       string
+	previous Validators
+	known    bool
+}
+
+// downloadResult is the outcome of a download, with the validators to send
+// the next time.
+type downloadResult struct {
+	status     downloadStatus
+	validators Validators
+}
+
+// download fetches a document, sending the validators of a previous download
+// so an unchanged document answers 304 and is not written again. It is called
+// concurrently, see DownloadMaxProcs.
+func (c *Client) download(t downloadTask) (result downloadResult, err error) {
+	id := t.id
+
+	req, err := http.NewRequest(http.MethodGet, id, nil)
+	if err != nil {
+		return result, fmt.Errorf("creating request for %s: %w", id, err)
+	}
+
+	if t.known {
+		t.previous.apply(req)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return result, err
+	}
+
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("closing request: %q %w", id, cerr))
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return downloadResult{status: downloadNotModified}, nil
+	}
+
+	r, err := htmlutils.AsReader(resp)
+	if err != nil {
+		return result, fmt.Errorf("reading response body: %w", err)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return result, fmt.Errorf("reading response body: %w", err)
+	}
+
+	status := downloadNew
+
+	if exists, _ := c.store.exists(id); exists {
+		same, err := c.store.sameDocument(id, content)
+		if err != nil {
+			return result, err
+		}
+
+		if same {
+			status = downloadNotModified
+		} else {
+			status = downloadChanged
+		}
+	}
+
+	result = downloadResult{status: status, validators: validatorsFrom(resp)}
+
+	if status == downloadNotModified || c.options.DryRun {
+		return result, nil
+	}
+
+	if err := c.store.SaveDocument(id, bytes.NewReader(content)); err != nil {
+		return downloadResult{}, fmt.Errorf("saving document: %q %w", id, err)
+	}
+
+	return result, nil
+}
+
+// Update runs the phases of the scraper on the database of the client:
+//
+// 1. Search: Find the documents published since the last search.
+// 2. Download: Download the documents not yet stored.
 // 3. Extract: Parse downloaded documents to extract relevant information.
 func (c *Client) Update() error {
 	log.Printf("Updating database %d - %s", c.dbRef.ID, c.dbRef.Name)
 
-	if !c.options.SkipSearch {
+	skipSearch, skipDownload := c.options.SkipSearch, c.options.SkipDownload
+	if (!skipSearch || !skipDownload) && !c.options.CrawlWindow.Contains(time.Now()) {
+		log.Printf("Outside the crawl window %s, skipping search and download phases", c.options.CrawlWindow)
+
+		skipSearch, skipDownload = true, true
+	}
+
+	if !skipSearch && c.options.Schedule != nil {
+		due, err := c.searchDue(time.Now())
+		if err != nil {
+			return fmt.Errorf("checking the schedule: %w", err)
+		}
+
+		skipSearch = !due
+	}
+
+	if !skipSearch {
 		if err := c.searchForNewDocuments(); err != nil {
 			return fmt.Errorf("searching for new documents: %w", err)
 		}
@@ -294,7 +600,7 @@ func (c *Client) Update() error {
 		)
 	}
 
-	if c.options.SkipDownload {
+	if skipDownload {
 		log.Println("Skipping download phase")
 	} else {
 		if err := c.downloadMissing(); err != nil {
@@ -0,0 +1,262 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawlWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, time.March, 10, hour, minute, 0, 0, UruguayTimezone)
+	}
+
+	tests := []struct {
+		window   string
+		at       time.Time
+		expected bool
+	}{
+		{"", at(12, 0), true},
+		{"01:00-06:00", at(3, 0), true},
+		{"01:00-06:00", at(6, 0), false},
+		{"01:00-06:00", at(0, 59), false},
+		{"22:00-06:00", at(23, 30), true},
+		{"22:00-06:00", at(5, 59), true},
+		{"22:00-06:00", at(12, 0), false},
+		// in Uruguay's time, whatever the time zone of the host
+		{"01:00-06:00", time.Date(2025, time.March, 10, 6, 0, 0, 0, time.UTC), true},
+		{"01:00-06:00", time.Date(2025, time.March, 10, 3, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		w, err := ParseCrawlWindow(tt.window)
+		if err != nil {
+			t.Fatalf("ParseCrawlWindow(%q): %v", tt.window, err)
+		}
+
+		if got := w.Contains(tt.at); got != tt.expected {
+			t.Errorf("%s.Contains(%s) = %v, expected %v", tt.window, tt.at.Format("15:04"), got, tt.expected)
+		}
+	}
+
+	for _, invalid := range []string{"01:00", "1am-6am", "25:00-06:00"} {
+		if _, err := ParseCrawlWindow(invalid); err == nil {
+			t.Errorf("ParseCrawlWindow(%q) expected an error", invalid)
+		}
+	}
+}
+
+func TestDownloadMissing_Refresh(t *testing.T) {
+	content := "<html><body>v1</body></html>"
+
+	var conditional int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && content == "<html><body>v1</body></html>" {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	dbRef, err := Find("canelones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := srv.URL + "/bases/notificaciones-transito-canelones/1-2025"
+	options := &ClientOptions{DbPath: t.TempDir(), DownloadRefresh: true}
+	c := NewImpoClient(options, dbRef, nil)
+
+	if _, err := c.store.Upsert([]SearchResultEntry{{Href: id, Title: "1/025"}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// first download stores the document and its validators
+	if err := c.downloadMissing(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second one is a conditional request
+	c.Metrics = ClientMetrics{}
+	if err := c.downloadMissing(); err != nil {
+		t.Fatal(err)
+	}
+
+	if conditional != 1 || c.Metrics.DownloadsNotModified != 1 {
+		t.Errorf("expected a 304, got %d conditional requests and %d not modified",
+			conditional, c.Metrics.DownloadsNotModified)
+	}
+
+	// a silent edit is detected and the document rewritten
+	content = "<html><body>v2</body></html>"
+	c.Metrics = ClientMetrics{}
+
+	if err := c.downloadMissing(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Metrics.DownloadsChanged != 1 || len(c.changed) != 1 || c.changed[0] != id {
+		t.Errorf("expected %s to be reported as changed, got %v", id, c.changed)
+	}
+
+	same, err := c.store.sameDocument(id, []byte(content))
+	if err != nil || !same {
+		t.Errorf("expected the stored document to be updated: %v", err)
+	}
+}
+
+func TestDownloadMissing_Concurrent(t *testing.T) {
+	var running, peak atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peak.Store(max(peak.Load(), running.Add(1)))
+		defer running.Add(-1)
+
+		time.Sleep(10 * time.Millisecond)
+
+		if strings.HasSuffix(r.URL.Path, "/3-2025") {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		_, _ = w.Write([]byte("<html><body>" + r.URL.Path + "</body></html>"))
+	}))
+	defer srv.Close()
+
+	dbRef, err := Find("canelones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &ClientOptions{DbPath: t.TempDir(), DownloadMaxProcs: 4}
+	c := NewImpoClient(options, dbRef, nil)
+
+	var entries []SearchResultEntry
+	for i := 1; i <= 8; i++ {
+		entries = append(entries, SearchResultEntry{
+			Href:  fmt.Sprintf("%s/bases/notificaciones-transito-canelones/%d-2025", srv.URL, i),
+			Title: fmt.Sprintf("%d/025", i),
+		})
+	}
+
+	if _, err := c.store.Upsert(entries, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.downloadMissing(); err == nil {
+		t.Error("expected the error of 3/025")
+	}
+
+	if c.Metrics.DownloadsOk != 7 || c.Metrics.DownloadsErr != 1 || c.Metrics.DownloadTime == 0 {
+		t.Errorf("unexpected metrics %+v", c.Metrics.DownloadMetrics)
+	}
+
+	if p := peak.Load(); p < 2 || p > 4 {
+		t.Errorf("expected between 2 and 4 concurrent downloads, got %d", p)
+	}
+
+	validators, err := c.store.LoadValidators()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(validators) != 7 {
+		t.Errorf("expected the validators of the 7 downloaded documents, got %d", len(validators))
+	}
+}
+
+func TestClientOptions_DocumentsPath(t *testing.T) {
+	dbRef, err := Find("canelones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &ClientOptions{DbPath: "db"}
+	if c := NewImpoClient(options, dbRef, nil); !strings.HasPrefix(c.store.root, "db/") {
+		t.Errorf("expected the documents under db, got %s", c.store.root)
+	}
+
+	// the archive can live apart from the database
+	options.ArchivePath = "archive"
+	if c := NewImpoClient(options, dbRef, nil); !strings.HasPrefix(c.store.root, "archive/") {
+		t.Errorf("expected the documents under archive, got %s", c.store.root)
+	}
+}
+
+func TestExtractDocument_Limits(t *testing.T) {
+	dbRef, err := Find("canelones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "https://www.impo.com.uy/bases/notificaciones-transito-canelones/1-2025"
+	options := &ClientOptions{DbPath: t.TempDir(), ExtractMaxBytes: 16}
+	c := NewImpoClient(options, dbRef, nil)
+
+	if err := c.store.SaveDocument(id, strings.NewReader("<html><body><pre>huge</pre></body></html>")); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := c.extractDocument(id)
+	if !errors.Is(err, ErrDocumentTooLarge) {
+		t.Errorf("expected ErrDocumentTooLarge, got %v", err)
+	}
+
+	if metrics.FailedDocs != 1 || metrics.OversizedDocs != 1 {
+		t.Errorf("expected an oversized failure, got %+v", metrics)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	stopped := make(chan struct{})
+
+	_, err := withTimeout(time.Millisecond, func(ctx context.Context) (int, error) {
+		defer close(stopped)
+
+		<-ctx.Done()
+
+		return 1, nil
+	})
+	if !errors.Is(err, ErrExtractionTimeout) {
+		t.Errorf("expected ErrExtractionTimeout, got %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("the extraction kept running after the timeout")
+	}
+
+	r := &doneReader{r: strings.NewReader("<html>"), done: stopped}
+	if _, err := r.Read(make([]byte, 8)); !errors.Is(err, ErrExtractionTimeout) {
+		t.Errorf("expected the reader to stop, got %v", err)
+	}
+
+	v, err := withTimeout(time.Minute, func(context.Context) (int, error) { return 1, nil })
+	if err != nil || v != 1 {
+		t.Errorf("expected 1, got %d %v", v, err)
+	}
+
+	v, err = withTimeout(0, func(context.Context) (int, error) { return 2, nil })
+	if err != nil || v != 2 {
+		t.Errorf("expected 2, got %d %v", v, err)
+	}
+}
@@ -4,6 +4,7 @@
 package impo
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	neturl "net/url"
@@ -20,14 +21,18 @@ var (
 // DbReference represents a reference to an IMPO database. See:
 // https://www.impo.com.uy/directorio-bases-institucionales/
 type DbReference struct {
-	Name     string                           // Name of the database
-	ID       int                              // ID of the database
-	TodosID  int                              // ID of the document type to search
-	SeedURL  string                           // Initial URL from where we get the anonymous credentials
-	QueryURL string                           // URL used for querying the database
-	BaseURL  string                           // Base URL for each documents, it isn't always the same domain as the query
-	Issuers  []string                         // List of issuing organizations
-	id2file  []func(string) ([]string, error) // Functions that transform the URL to a filesystem path for storage
+	Name          string                           // Name of the database
+	ID            int                              // ID of the database
+	TodosID       int                              // ID of the document type to search
+	SeedURL       string                           // Initial URL from where we get the anonymous credentials
+	QueryURL      string                           // URL used for querying the database
+	BaseURL       string                           // Base URL for each documents, it isn't always the same domain as the query
+	Issuers       []string                         // Normalized aliases of the issuing organizations, see issuers.json
+	Budget        ErrorBudget                      // Errors tolerated by the extraction, see budgets.json
+	LocationRules []LocationRule                   // Cleanups of the locations before geocoding them, see location_rules.json
+	URRules       URRules                          // What the UR of the rows with several articles is, see ur_rules.json
+	TimeRules     TimeRules                        // What the time of the rows without the date of the offense is, see time_rules.json
+	id2file       []func(string) ([]string, error) // Functions that transform the URL to a filesystem path for storage
 }
 
 // Validate checks if the DbReference has all required fields.
@@ -65,9 +70,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=65",
 			BaseURL:  "https://impo.com.uy/",
 			TodosID:  799,
-			Issuers: []string{
-				"Policía Caminera",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-policia-caminera/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -82,9 +84,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=40",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  709,
-			Issuers: []string{
-				"Dirección General de Tránsito y Transporte Intendencia de Canelones",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-canelones/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -99,9 +98,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=48",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  876,
-			Issuers: []string{
-				"Dirección de Tránsito y Transporte Intendencia de Colonia",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-colonia/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -116,9 +112,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=26",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  600,
-			Issuers: []string{
-				"Dirección de Tránsito Intendencia de Lavalleja",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-lavalleja/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -133,10 +126,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=45",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  802,
-			Issuers: []string{
-				"Dirección General de Tránsito y Transporte Intendencia de Maldonado",
-				"Departamento de Movilidad Intendencia de Maldonado",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-maldonado/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -155,9 +144,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=6",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  383,
-			Issuers: []string{
-				"Centro de Gestión de Movilidad",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-cgm/([\dA-Za-z]+)-(\d+)(?:_([A-Z]))?$`),
@@ -172,9 +158,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=43",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  777,
-			Issuers: []string{
-				"Dirección de Tránsito Intendencia de Paysandú",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-paysandu/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -189,9 +172,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=55",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  815,
-			Issuers: []string{
-				"Dirección de Tránsito Intendencia de Río Negro",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-rionegro/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -199,6 +179,37 @@ var databases = func() []DbReference {
 				),
 			},
 		},
+		// Rocha and Salto were added without access to IMPO: their IDs,
+		// TodosID and document paths follow the other databases and are yet to
+		// be checked against a first crawl.
+		{
+			ID:       71,
+			Name:     "Rocha",
+			SeedURL:  "https://www.impo.com.uy/base-institucional/multasrocha",
+			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=71",
+			BaseURL:  "https://www.impo.com.uy/",
+			TodosID:  905,
+			id2file: []func(string) ([]string, error){
+				makeID2PathFunc(
+					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-rocha/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
+					typeNumberYearOptional,
+				),
+			},
+		},
+		{
+			ID:       73,
+			Name:     "Salto",
+			SeedURL:  "https://www.impo.com.uy/base-institucional/multassalto",
+			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=73",
+			BaseURL:  "https://www.impo.com.uy/",
+			TodosID:  910,
+			id2file: []func(string) ([]string, error){
+				makeID2PathFunc(
+					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-salto/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
+					typeNumberYearOptional,
+				),
+			},
+		},
 		{
 			ID:       49,
 			Name:     "Soriano",
@@ -206,9 +217,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=49",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  879,
-			Issuers: []string{
-				"Departamento de Tránsito y Transporte Intendencia de Soriano",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-soriano/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -223,9 +231,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=56",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  891,
-			Issuers: []string{
-				"Dirección General de Tránsito Intendencia de Tacuarembó",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(notificaciones)-transito-tacuarembo/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -240,9 +245,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=52",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  818,
-			Issuers: []string{
-				"Dirección de Tránsito Intendencia de Treinta y Tres",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(notificaciones)-transito-treintaytres/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -257,9 +259,6 @@ var databases = func() []DbReference {
 			QueryURL: "https://www.impo.com.uy/cgi-bin/bases/consultaBasesBS.cgi?tipoServicio=68",
 			BaseURL:  "https://www.impo.com.uy/",
 			TodosID:  867,
-			Issuers: []string{
-				"Tránsito MTOP",
-			},
 			id2file: []func(string) ([]string, error){
 				makeID2PathFunc(
 					regexp.MustCompile(`^/bases/(resoluciones|notificaciones)-transito-mtop/([\dA-Za-z]+)\-(\d+)(?:_([A-Z]))?$`),
@@ -274,10 +273,30 @@ var databases = func() []DbReference {
 		if err := ret[i].Validate(); err != nil {
 			panic(err)
 		}
-		// Convert issuers to lowercase to ease later matching
-		for j := range ret[i].Issuers {
-			ret[i].Issuers[j] = strings.ToLower(ret[i].Issuers[j])
-		}
+	}
+
+	if err := addIssuerAliases(ret, bytes.NewReader(defaultIssuerAliases)); err != nil {
+		panic(err)
+	}
+
+	for i := range ret {
+		ret[i].Budget = ErrorBudget{DbID: ret[i].ID, Name: ret[i].Name}.withDefaults()
+	}
+
+	if err := addErrorBudgets(ret, bytes.NewReader(defaultErrorBudgets)); err != nil {
+		panic(err)
+	}
+
+	if err := addLocationRules(ret, bytes.NewReader(defaultLocationRules)); err != nil {
+		panic(err)
+	}
+
+	if err := addURRules(ret, bytes.NewReader(defaultURRules)); err != nil {
+		panic(err)
+	}
+
+	if err := addTimeRules(ret, bytes.NewReader(defaultTimeRules)); err != nil {
+		panic(err)
 	}
 
 	return ret
@@ -300,6 +319,36 @@ func typeNumberYearOptional(matches []string) []string {
 	}
 }
 
+// docIDFromURL derives the ID of a document, as the titles write it (e.g.
+// "2933/024" for /bases/notificaciones-cgm/2933-2024), and its year from the
+// URL. It is the fallback for titles the issuer detection can't parse.
+func (d *DbReference) docIDFromURL(id string) (string, int, error) {
+	err := fmt.Errorf("database %s doesn't support id2file conversion", d.Name)
+
+	for _, extractFunc := range d.id2file {
+		var path []string
+		if path, err = extractFunc(id); err != nil {
+			continue
+		}
+
+		if len(path) != 3 {
+			return "", 0, fmt.Errorf("unexpected path for %q: %q", id, path)
+		}
+
+		year, err := strconv.Atoi(path[1])
+		if err != nil {
+			return "", 0, fmt.Errorf("parsing year of %q: %w", id, err)
+		}
+
+		// drop the suffix of re-published documents (37-2025_A)
+		number, _, _ := strings.Cut(path[2], "_")
+
+		return fmt.Sprintf("%s/%03d", strings.ToLower(number), year%1000), year, nil
+	}
+
+	return "", 0, err
+}
+
 // Creates a function that transforms a URL path into
 // filesystem path components using the provided regex and transformer.
 func makeID2PathFunc(
@@ -210,6 +210,21 @@ func TestEachURI2file(t *testing.T) {
 			id:       "https://www.impo.com.uy/bases/resoluciones-transito-rionegro/1-2023",
 			expected: []string{"resoluciones", "2023", "1"},
 		},
+		{
+			db:       "Rocha",
+			id:       "https://www.impo.com.uy/bases/notificaciones-transito-rocha/12-2025",
+			expected: []string{"notificaciones", "2025", "12"},
+		},
+		{
+			db:       "Rocha",
+			id:       "https://www.impo.com.uy/bases/resoluciones-transito-rocha/3-2025_A",
+			expected: []string{"resoluciones", "2025", "3_A"},
+		},
+		{
+			db:       "Salto",
+			id:       "https://www.impo.com.uy/bases/notificaciones-transito-salto/7-2025",
+			expected: []string{"notificaciones", "2025", "7"},
+		},
 		{
 			db:       "Soriano",
 			id:       "https://www.impo.com.uy/bases/notificaciones-transito-soriano/1-2024",
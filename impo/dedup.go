@@ -0,0 +1,128 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// CanonicalURL normalizes the URL of a document so the same document is
+// recognized no matter how the search linked it: IMPO serves it both with and
+// without www, over http and https, and sometimes with a trailing slash. The
+// values that aren't absolute URLs are only trimmed.
+func CanonicalURL(href string) string {
+	href = strings.TrimSpace(href)
+
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return href
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	p := path.Clean("/" + u.Path)
+	if p == "/" {
+		p = ""
+	}
+
+	ret := "https://" + host + p
+	if u.RawQuery != "" {
+		ret += "?" + u.RawQuery
+	}
+
+	return ret
+}
+
+// Collision is a document stored by more than one database, or more than once
+// by the same database under different URLs.
+type Collision struct {
+	URL    string   `json:"url"`    // canonical
+	DbIDs  []int    `json:"db_ids"` // one per stored copy
+	Stored []string `json:"stored"` // the URLs as stored, in the order of DbIDs
+}
+
+// otherDatabases returns the database that stored every canonical URL, for
+// the databases other than the one of the store.
+func (s *FileStore) otherDatabases() (map[string]int, error) {
+	ret := make(map[string]int)
+
+	for _, db := range databases {
+		if db.ID == s.dbRef.ID {
+			continue
+		}
+
+		other := NewFileStore(s.base, &db)
+
+		entries, err := other.load(other.dbpath())
+		if err != nil {
+			return nil, fmt.Errorf("loading documents of %s: %w", db.Name, err)
+		}
+
+		for href := range entries {
+			ret[CanonicalURL(href)] = db.ID
+		}
+	}
+
+	return ret, nil
+}
+
+// FindCollisions reports the documents stored more than once across the
+// databases under root.
+func FindCollisions(root string) ([]Collision, error) {
+	type copyOf struct {
+		dbID int
+		href string
+	}
+
+	seen := make(map[string][]copyOf)
+
+	for _, db := range databases {
+		s := NewFileStore(root, &db)
+
+		entries, err := s.load(s.dbpath())
+		if err != nil {
+			return nil, fmt.Errorf("loading documents of %s: %w", db.Name, err)
+		}
+
+		for href := range entries {
+			c := CanonicalURL(href)
+			seen[c] = append(seen[c], copyOf{db.ID, href})
+		}
+	}
+
+	var ret []Collision
+
+	for canonical, copies := range seen {
+		if len(copies) < 2 {
+			continue
+		}
+
+		slices.SortFunc(copies, func(a, b copyOf) int {
+			if a.dbID != b.dbID {
+				return a.dbID - b.dbID
+			}
+
+			return strings.Compare(a.href, b.href)
+		})
+
+		c := Collision{URL: canonical}
+		for _, cp := range copies {
+			c.DbIDs = append(c.DbIDs, cp.dbID)
+			c.Stored = append(c.Stored, cp.href)
+		}
+
+		ret = append(ret, c)
+	}
+
+	slices.SortFunc(ret, func(a, b Collision) int { return strings.Compare(a.URL, b.URL) })
+
+	return ret, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	want := "https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025"
+
+	for _, href := range []string{
+		"https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025",
+		"https://www.impo.com.uy/bases/notificaciones-transito-colonia/12-2025",
+		"http://WWW.IMPO.COM.UY/bases/notificaciones-transito-colonia/12-2025/",
+		"https://impo.com.uy:443//bases/notificaciones-transito-colonia/12-2025#top",
+		" https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025 ",
+	} {
+		assert.Equal(t, want, CanonicalURL(href), href)
+	}
+
+	assert.Equal(t, "01_2025", CanonicalURL("01_2025"))
+	assert.NotEqual(t, want, CanonicalURL(want+"_A"))
+}
+
+func TestFileStore_UpsertDeduplicates(t *testing.T) {
+	root := t.TempDir()
+	colonia, caminera := mustFind(t, "48"), mustFind(t, "65")
+
+	const href = "https://www.impo.com.uy/bases/notificaciones-transito-colonia/12-2025"
+
+	n, err := NewFileStore(root, colonia).Upsert([]SearchResultEntry{{Href: href}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// the same document, linked without www
+	n, err = NewFileStore(root, colonia).Upsert([]SearchResultEntry{
+		{Href: "https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025"},
+		{Href: "https://impo.com.uy/bases/notificaciones-transito-colonia/13-2025"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// already stored by another database
+	n, err = NewFileStore(root, caminera).Upsert([]SearchResultEntry{{Href: href + "/"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	collisions, err := FindCollisions(root)
+	require.NoError(t, err)
+	assert.Empty(t, collisions)
+}
+
+func TestFindCollisions(t *testing.T) {
+	root := t.TempDir()
+
+	// stored before the deduplication
+	write := func(db *DbReference, hrefs ...string) {
+		s := NewFileStore(root, db)
+		require.NoError(t, s.dbDirMustExists())
+
+		entries := make(map[string]SearchResultEntry)
+		for _, href := range hrefs {
+			entries[href] = SearchResultEntry{Href: href}
+		}
+
+		data, err := json.Marshal(entries)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(s.dbpath(), data, 0o600))
+	}
+
+	write(mustFind(t, "48"), "https://www.impo.com.uy/bases/notificaciones-transito-colonia/12-2025")
+	write(mustFind(t, "65"),
+		"https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025",
+		"https://impo.com.uy/bases/notificaciones-policia-caminera/1-2025",
+	)
+
+	collisions, err := FindCollisions(root)
+	require.NoError(t, err)
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025", collisions[0].URL)
+	assert.Equal(t, []int{48, 65}, collisions[0].DbIDs)
+	assert.Equal(t, []string{
+		"https://www.impo.com.uy/bases/notificaciones-transito-colonia/12-2025",
+		"https://impo.com.uy/bases/notificaciones-transito-colonia/12-2025",
+	}, collisions[0].Stored)
+}
+
+func mustFind(t *testing.T, q string) *DbReference {
+	t.Helper()
+
+	db, err := Find(q)
+	require.NoError(t, err)
+
+	return db
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// DocumentMonth is what a database published in a month, by the date of
+// publication of its documents.
+type DocumentMonth struct {
+	Month     string `json:"month"`     // 2025-06
+	Published int    `json:"published"` // documents of the month found by the search
+	Extracted int    `json:"extracted"`
+	// Gap marks a month without documents after months with some: the
+	// database may just have published nothing, or the crawl broke silently.
+	// The current month is never one, it may still get documents.
+	Gap bool `json:"gap,omitempty"`
+}
+
+// DocumentStats is the number of documents of a database published by IMPO
+// and extracted by us, to detect the crawls that stop finding documents.
+type DocumentStats struct {
+	DbID      int    `json:"db_id"`
+	Name      string `json:"name"`
+	Found     int    `json:"found"` // by the search, with or without a date
+	Extracted int    `json:"extracted"`
+	// Undated are the documents found without a date of publication, mostly
+	// the ones not downloaded yet: the date is in the document itself.
+	Undated       int             `json:"undated"`
+	LastPublished *time.Time      `json:"last_published,omitempty"`
+	Gaps          int             `json:"gaps"`
+	Months        []DocumentMonth `json:"months"`
+}
+
+// ListDocumentStats returns the document stats of the database of store,
+// by month up to the one of now. The month of a document is the one of its
+// "Fecha de Publicación", whether it was extracted or not, and the date of
+// its records when the file is gone.
+func ListDocumentStats(store *FileStore, repo OffenseRepository, now time.Time) (*DocumentStats, error) {
+	docs, err := ListDocuments(store, repo, DocumentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range docs {
+		if d.Status == DocumentMissing {
+			continue
+		}
+
+		published, err := documentPublished(store, d.DocSource)
+		if err != nil {
+			return nil, err
+		}
+
+		if published != nil {
+			docs[i].DocDate = published
+		}
+	}
+
+	return documentStats(store.dbRef, docs, now), nil
+}
+
+// documentPublished reads the date of publication of a stored document, nil
+// when it has none or the file is gone. Only the beginning of the document is
+// read: the date heading comes before the offenses.
+func documentPublished(store *FileStore, source string) (*time.Time, error) {
+	exists, err := store.exists(source)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	r, err := store.GetDocument(source)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	z := html.NewTokenizer(r)
+
+	var (
+		heading   strings.Builder
+		inHeading bool
+	)
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return nil, nil
+			}
+
+			return nil, z.Err()
+		case html.StartTagToken:
+			name, _ := z.TagName()
+
+			switch strings.ToLower(string(name)) {
+			case "h5":
+				heading.Reset()
+
+				inHeading = true
+			case "table":
+				return nil, nil
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if !inHeading || !strings.EqualFold(string(name), "h5") {
+				continue
+			}
+
+			inHeading = false
+
+			// a malformed date is left to the extraction to report
+			if date, ok, err := parsePublicationDate(heading.String()); ok && err == nil {
+				return &date, nil
+			}
+		case html.TextToken:
+			if inHeading {
+				heading.Write(z.Text())
+			}
+		}
+	}
+}
+
+func documentStats(dbRef *DbReference, docs []DocumentInfo, now time.Time) *DocumentStats {
+	ret := &DocumentStats{DbID: dbRef.ID, Name: dbRef.Name, Found: len(docs), Months: []DocumentMonth{}}
+
+	type counts struct{ published, extracted int }
+
+	months := make(map[string]*counts)
+
+	var first time.Time
+
+	for _, d := range docs {
+		if d.Status == DocumentExtracted {
+			ret.Extracted++
+		}
+
+		if d.DocDate == nil {
+			ret.Undated++
+
+			continue
+		}
+
+		if ret.LastPublished == nil || d.DocDate.After(*ret.LastPublished) {
+			ret.LastPublished = d.DocDate
+		}
+
+		month := time.Date(d.DocDate.Year(), d.DocDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+
+		c := months[month.Format("2006-01")]
+		if c == nil {
+			c = &counts{}
+			months[month.Format("2006-01")] = c
+		}
+
+		c.published++
+
+		if d.Status == DocumentExtracted {
+			c.extracted++
+		}
+	}
+
+	if first.IsZero() {
+		return ret
+	}
+
+	last := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if p := ret.LastPublished; p.After(last) {
+		last = time.Date(p.Year(), p.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		m := DocumentMonth{Month: month.Format("2006-01")}
+		if c := months[m.Month]; c != nil {
+			m.Published, m.Extracted = c.published, c.extracted
+		} else if month.Before(current) {
+			m.Gap = true
+			ret.Gaps++
+		}
+
+		ret.Months = append(ret.Months, m)
+	}
+
+	return ret
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentStats(t *testing.T) {
+	date := func(month time.Month, day int) *time.Time {
+		d := time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+
+		return &d
+	}
+
+	docs := []DocumentInfo{
+		{Status: DocumentExtracted, DocDate: date(time.February, 3)},
+		{Status: DocumentExtracted, DocDate: date(time.February, 17)},
+		{Status: DocumentFailed, DocDate: date(time.February, 20)},
+		{Status: DocumentExtracted, DocDate: date(time.April, 1)},
+		{Status: DocumentMissing},
+		{Status: DocumentFailed},
+	}
+
+	stats := documentStats(&DbReference{ID: 45, Name: "Maldonado"}, docs, time.Date(2025, time.June, 10, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, 6, stats.Found)
+	assert.Equal(t, 3, stats.Extracted)
+	assert.Equal(t, 2, stats.Undated)
+	assert.Equal(t, date(time.April, 1), stats.LastPublished)
+	// June may still get documents
+	assert.Equal(t, 2, stats.Gaps)
+	assert.Equal(t, []DocumentMonth{
+		{Month: "2025-02", Published: 3, Extracted: 2},
+		{Month: "2025-03", Gap: true},
+		{Month: "2025-04", Published: 1, Extracted: 1},
+		{Month: "2025-05", Gap: true},
+		{Month: "2025-06"},
+	}, stats.Months)
+
+	// without dated documents there are no months to compare
+	stats = documentStats(&DbReference{ID: 45}, docs[4:], time.Now())
+	assert.Empty(t, stats.Months)
+	assert.Equal(t, 0, stats.Gaps)
+}
+
+func TestListDocumentStats(t *testing.T) {
+	dbRef, err := Find("canelones")
+	require.NoError(t, err)
+
+	const base = "https://www.impo.com.uy/bases/notificaciones-transito-canelones/"
+
+	store := NewFileStore(t.TempDir(), dbRef)
+	_, err = store.Upsert([]SearchResultEntry{
+		{Href: base + "1-2025", Title: "1/025"},
+		{Href: base + "2-2025", Title: "2/025"},
+		{Href: base + "3-2025", Title: "3/025"},
+		{Href: base + "4-2025", Title: "4/025"},
+	}, false)
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveDocument(base+"1-2025", strings.NewReader(
+		"<html><body><h5>Fecha de Publicaci&oacute;n: 03/03/2025</h5><table></table></body></html>",
+	)))
+	require.NoError(t, store.SaveDocument(base+"2-2025", strings.NewReader(
+		"<html><body><h5>Fecha de <b>Publicación</b>:  10/05/2025 </h5></body></html>",
+	)))
+
+	extracted := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	records := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
+	repo := &staleRepository{summaries: map[string]DocumentSummary{
+		// extracted without records
+		base + "1-2025": {ExtractedAt: &extracted},
+		// extracted, and the file is gone
+		base + "3-2025": {Offenses: 2, ExtractedAt: &extracted, DocDate: &records},
+	}}
+
+	stats, err := ListDocumentStats(store, repo, time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, stats.Found)
+	assert.Equal(t, 2, stats.Extracted)
+	assert.Equal(t, 1, stats.Undated)
+	assert.Equal(t, 0, stats.Gaps)
+	assert.Equal(t, []DocumentMonth{
+		{Month: "2025-03", Published: 1, Extracted: 1},
+		{Month: "2025-04", Published: 1, Extracted: 1},
+		{Month: "2025-05", Published: 1},
+		{Month: "2025-06"},
+	}, stats.Months)
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DocumentStatus is how far a document got in the pipeline.
+type DocumentStatus string
+
+// Statuses of the documents.
+const (
+	DocumentMissing    DocumentStatus = "missing"    // found by the search, not downloaded yet
+	DocumentDownloaded DocumentStatus = "downloaded" // not extracted yet
+	DocumentExtracted  DocumentStatus = "extracted"
+	DocumentFailed     DocumentStatus = "failed" // the last extraction failed
+)
+
+var documentStatuses = []DocumentStatus{DocumentMissing, DocumentDownloaded, DocumentExtracted, DocumentFailed}
+
+// ParseDocumentStatus returns the status with the given name.
+func ParseDocumentStatus(s string) (DocumentStatus, error) {
+	if status := DocumentStatus(s); slices.Contains(documentStatuses, status) {
+		return status, nil
+	}
+
+	return "", fmt.Errorf("unknown document status %q, expected one of %v", s, documentStatuses)
+}
+
+// DocumentSummary is what the database knows of a document.
+type DocumentSummary struct {
+	Offenses         int
+	Errors           int
+	ExtractorVersion int
+	ExtractedAt      *time.Time
+	FailedAt         *time.Time
+	Failure          string
+	DocDate          *time.Time // of publication, when it has records
+}
+
+// DocumentInfo is a document of the inventory of a database.
+type DocumentInfo struct {
+	DbID             int            `json:"db_id"`
+	DocSource        string         `json:"doc_source"`
+	Title            string         `json:"title,omitempty"`
+	Year             int            `json:"year,omitempty"`
+	Status           DocumentStatus `json:"status"`
+	Offenses         int            `json:"offenses"`
+	Errors           int            `json:"errors"`
+	ExtractorVersion int            `json:"extractor_version,omitempty"`
+	ExtractedAt      *time.Time     `json:"extracted_at,omitempty"`
+	FailedAt         *time.Time     `json:"failed_at,omitempty"`
+	Failure          string         `json:"failure,omitempty"`
+	DocDate          *time.Time     `json:"doc_date,omitempty"`
+}
+
+// DocumentFilter selects the documents of the inventory. The zero values
+// match every document.
+type DocumentFilter struct {
+	Status DocumentStatus
+	Year   int
+}
+
+func (f DocumentFilter) matches(d *DocumentInfo) bool {
+	return (f.Status == "" || f.Status == d.Status) && (f.Year == 0 || f.Year == d.Year)
+}
+
+// ListDocuments returns the inventory of the documents of a database: the
+// ones found by the search, downloaded to the store or extracted to the
+// repository, sorted by source.
+func ListDocuments(store *FileStore, repo OffenseRepository, filter DocumentFilter) ([]DocumentInfo, error) {
+	entries, err := store.load(store.dbpath())
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := repo.ListDocumentSummaries(store.dbRef.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, 0, len(entries))
+	for source := range entries {
+		sources = append(sources, source)
+	}
+
+	for source := range summaries {
+		if _, ok := entries[source]; !ok {
+			sources = append(sources, source)
+		}
+	}
+
+	slices.Sort(sources)
+
+	var ret []DocumentInfo
+
+	for _, source := range sources {
+		d := DocumentInfo{DbID: store.dbRef.ID, DocSource: source, Title: entries[source].Title}
+		if _, year, err := store.dbRef.docIDFromURL(source); err == nil {
+			d.Year = year
+		}
+
+		summary, ok := summaries[source]
+
+		switch {
+		case ok && summary.FailedAt != nil:
+			d.Status = DocumentFailed
+		case ok && (summary.Offenses > 0 || summary.ExtractedAt != nil):
+			d.Status = DocumentExtracted
+		default:
+			exists, err := store.exists(source)
+			if err != nil {
+				return nil, err
+			}
+
+			d.Status = DocumentMissing
+			if exists {
+				d.Status = DocumentDownloaded
+			}
+		}
+
+		if ok {
+			d.Offenses, d.Errors, d.ExtractorVersion = summary.Offenses, summary.Errors, summary.ExtractorVersion
+			d.ExtractedAt, d.FailedAt, d.Failure = summary.ExtractedAt, summary.FailedAt, summary.Failure
+			d.DocDate = summary.DocDate
+		}
+
+		if filter.matches(&d) {
+			ret = append(ret, d)
+		}
+	}
+
+	return ret, nil
+}
+
+func (r *sqlOffenseRepository) ListDocumentSummaries(dbID int) (map[string]DocumentSummary, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			doc_source, CAST(SUM(offenses) AS BIGINT), CAST(SUM(errors) AS BIGINT), MAX(extractor_version),
+			MAX(extracted_at), MAX(failed_at), MAX(failure), MIN(doc_date)
+		FROM (
+			SELECT
+				doc_source, COUNT(*) AS offenses, COUNT(error) AS errors, NULL::INTEGER AS extractor_version,
+				NULL::TIMESTAMPTZ AS extracted_at, NULL::TIMESTAMPTZ AS failed_at, NULL::VARCHAR AS failure,
+				MIN(doc_date) AS doc_date
+			FROM offenses
+			WHERE db_id = ?
+			GROUP BY doc_source
+			UNION ALL
+			SELECT doc_source, 0, 0, extractor_version, extracted_at, NULL, NULL, NULL
+			FROM document_extractions
+			WHERE db_id = ?
+			UNION ALL
+			SELECT doc_source, 0, 0, NULL, NULL, failed_at, error, NULL
+			FROM document_failures
+			WHERE db_id = ?
+		)
+		GROUP BY doc_source
+	`, dbID, dbID, dbID)
+	if err != nil {
+		return nil, fmt.Errorf("querying documents: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[string]DocumentSummary)
+
+	for rows.Next() {
+		var (
+			source  string
+			s       DocumentSummary
+			version sql.NullInt64
+			failure sql.NullString
+		)
+
+		if err := rows.Scan(
+			&source, &s.Offenses, &s.Errors, &version, &s.ExtractedAt, &s.FailedAt, &failure, &s.DocDate,
+		); err != nil {
+			return nil, fmt.Errorf("scanning document: %w", err)
+		}
+
+		s.ExtractorVersion, s.Failure = int(version.Int64), failure.String
+		ret[source] = s
+	}
+
+	return ret, rows.Err()
+}
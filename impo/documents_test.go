@@ -0,0 +1,95 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDocumentStatus(t *testing.T) {
+	status, err := ParseDocumentStatus("failed")
+	require.NoError(t, err)
+	assert.Equal(t, DocumentFailed, status)
+
+	_, err = ParseDocumentStatus("pending")
+	assert.Error(t, err)
+}
+
+func TestSQLRepository_ListDocuments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	dbRef, err := Find("canelones")
+	require.NoError(t, err)
+
+	const base = "https://www.impo.com.uy/bases/notificaciones-transito-canelones/"
+
+	store := NewFileStore(t.TempDir(), dbRef)
+	_, err = store.Upsert([]SearchResultEntry{
+		{Href: base + "1-2024", Title: "1/024"},
+		{Href: base + "2-2025", Title: "2/025"},
+		{Href: base + "3-2025", Title: "3/025"},
+		{Href: base + "4-2025", Title: "4/025"},
+	}, false)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1-2024", "2-2025", "3-2025"} {
+		require.NoError(t, store.SaveDocument(base+id, strings.NewReader("<html></html>")))
+	}
+
+	doc := &Document{DocSource: base + "1-2024", DocID: "1/024", DocDate: time.Date(2024, 3, 1, 0, 0, 0, 0, UruguayTimezone)}
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{
+		{Document: doc, DbID: dbRef.ID, RecordID: 1, Vehicle: "ABC1234", Description: "Exceso"},
+		{Document: doc, DbID: dbRef.ID, RecordID: 2, Error: "falta horario", ErrorCode: CodeMissingTime},
+	}))
+	require.NoError(t, repo.SaveExtractionFailure(dbRef.ID, base+"3-2025", errors.New("parsing document: boom")))
+
+	docs, err := ListDocuments(store, repo, DocumentFilter{})
+	require.NoError(t, err)
+	require.Len(t, docs, 4)
+
+	statuses := map[string]DocumentStatus{}
+	for _, d := range docs {
+		statuses[strings.TrimPrefix(d.DocSource, base)] = d.Status
+	}
+
+	assert.Equal(t, map[string]DocumentStatus{
+		"1-2024": DocumentExtracted,
+		"2-2025": DocumentDownloaded,
+		"3-2025": DocumentFailed,
+		"4-2025": DocumentMissing,
+	}, statuses)
+
+	assert.Equal(t, 2, docs[0].Offenses)
+	assert.Equal(t, 1, docs[0].Errors)
+	assert.Equal(t, ExtractorVersion, docs[0].ExtractorVersion)
+	assert.Equal(t, 2024, docs[0].Year)
+	require.NotNil(t, docs[0].DocDate)
+	assert.Equal(t, "2024-03-01", docs[0].DocDate.Format(time.DateOnly))
+	assert.Nil(t, docs[1].DocDate)
+	assert.Equal(t, "parsing document: boom", docs[2].Failure)
+
+	docs, err = ListDocuments(store, repo, DocumentFilter{Status: DocumentDownloaded, Year: 2025})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, base+"2-2025", docs[0].DocSource)
+
+	// a successful extraction clears the failure
+	doc = &Document{DocSource: base + "3-2025", DocID: "3/025", DocDate: time.Date(2025, 3, 1, 0, 0, 0, 0, UruguayTimezone)}
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{
+		{Document: doc, DbID: dbRef.ID, RecordID: 1, Vehicle: "ABC1234", Description: "Exceso"},
+	}))
+
+	docs, err = ListDocuments(store, repo, DocumentFilter{Status: DocumentFailed})
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+}
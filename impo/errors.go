@@ -0,0 +1,145 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode classifies the error of an offense, stored in offenses.error_code
+// next to the message to aggregate them.
+type ErrorCode string
+
+// Codes of the offense errors.
+const (
+	CodeInvalidVehicle     ErrorCode = "invalid_vehicle"
+	CodeMissingTime        ErrorCode = "missing_time"
+	CodeDateTimeParse      ErrorCode = "datetime_parse"
+	CodeDateTooOld         ErrorCode = "date_too_old"
+	CodeDateFuture         ErrorCode = "date_future"
+	CodeMissingDescription ErrorCode = "missing_description"
+	CodeURParse            ErrorCode = "ur_parse"
+	CodeUnsupportedUnit    ErrorCode = "unsupported_unit"
+	CodeHeaderUnknown      ErrorCode = "header_unknown"
+	CodeUnknownCountry     ErrorCode = "unknown_country"
+	CodeUnknown            ErrorCode = "unknown"
+)
+
+// Errors of the extraction and validation of the offenses. The messages are
+// the ones found in offenses.error.
+var (
+	ErrInvalidVehicle     = errors.New("matrícula inválida")
+	ErrMissingTime        = errors.New("falta horario")
+	ErrDateTimeParse      = errors.New("couldn't parse datetime")
+	ErrDateTooOld         = errors.New("es anterior a 2015-01-01")
+	ErrDateFuture         = errors.New("es más nueva que la fecha de publicación")
+	ErrMissingDescription = errors.New("falta descripción")
+	ErrURParse            = errors.New("can't convert")
+	ErrUnsupportedUnit    = errors.New("unidad no soportada")
+	ErrHeaderUnknown      = errors.New("unknown property for header")
+	ErrUnknownCountry     = errors.New("país desconocido")
+	errParseInt           = errors.New("parsing integer part")
+)
+
+// errorCodes maps the errors to their codes, in the order they are checked.
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrInvalidVehicle, CodeInvalidVehicle},
+	{ErrMissingTime, CodeMissingTime},
+	{ErrDateTimeParse, CodeDateTimeParse},
+	{ErrDateTooOld, CodeDateTooOld},
+	{ErrDateFuture, CodeDateFuture},
+	{ErrMissingDescription, CodeMissingDescription},
+	{ErrUnsupportedUnit, CodeUnsupportedUnit},
+	{ErrURParse, CodeURParse},
+	{ErrHeaderUnknown, CodeHeaderUnknown},
+	{ErrUnknownCountry, CodeUnknownCountry},
+}
+
+// ErrorCodeOf returns the code of an error, CodeUnknown when it isn't part of
+// the taxonomy and "" for nil.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	return CodeUnknown
+}
+
+// ClassifyErrorMessage guesses the code of a message stored before the codes
+// existed. The older messages were free-form, so this is best-effort: it looks
+// for the message of every error of the taxonomy.
+func ClassifyErrorMessage(msg string) ErrorCode {
+	if msg == "" {
+		return ""
+	}
+
+	for _, e := range errorCodes {
+		if strings.Contains(msg, e.err.Error()) {
+			return e.code
+		}
+	}
+
+	// the unit errors of fineFromUnitQuantity are prefixed with the cell
+	if strings.HasPrefix(msg, "cantidad ") || strings.HasPrefix(msg, "unidad ") {
+		return CodeURParse
+	}
+
+	return CodeUnknown
+}
+
+func (r *sqlOffenseRepository) BackfillErrorCodes() (int64, error) {
+	rows, err := r.db.Query(
+		"SELECT DISTINCT error FROM offenses WHERE error IS NOT NULL AND error_code IS NULL",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("querying errors without code: %w", err)
+	}
+
+	var messages []string
+
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning error: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating errors: %w", err)
+	}
+
+	var total int64
+
+	for _, msg := range messages {
+		res, err := r.db.Exec(
+			"UPDATE offenses SET error_code = ? WHERE error = ? AND error_code IS NULL",
+			string(ClassifyErrorMessage(msg)), msg,
+		)
+		if err != nil {
+			return total, fmt.Errorf("classifying error %q: %w", msg, err)
+		}
+
+		n, _ := res.RowsAffected()
+		total += n
+	}
+
+	return total, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want ErrorCode
+	}{
+		{nil, ""},
+		{ErrInvalidVehicle, CodeInvalidVehicle},
+		{fmt.Errorf("la fecha `%v' %w `%v'", time.Now(), ErrDateFuture, time.Now()), CodeDateFuture},
+		{fmt.Errorf("%w: %q", ErrUnsupportedUnit, "$"), CodeUnsupportedUnit},
+		{fmt.Errorf("%w %q to UR: %w", ErrURParse, "x", errParseInt), CodeURParse},
+		{errors.New("no property for index 3"), CodeUnknown},
+	} {
+		assert.Equal(t, tc.want, ErrorCodeOf(tc.err), "%v", tc.err)
+	}
+}
+
+func TestClassifyErrorMessage(t *testing.T) {
+	for msg, want := range map[string]ErrorCode{
+		"":                   "",
+		"matrícula inválida": CodeInvalidVehicle,
+		"falta horario":      CodeMissingTime,
+		"la fecha `2014-12-31 00:00:00 -0300 -03' es anterior a 2015-01-01":                        CodeDateTooOld,
+		"la fecha `2024-05-02 00:00:00 -0300 -03' es más nueva que la fecha de publicación `2024'": CodeDateFuture,
+		`can't convert "abc" to UR: parsing integer part "abc": invalid syntax`:                    CodeURParse,
+		`cantidad "x": parsing integer part "x": invalid syntax`:                                   CodeURParse,
+		`unidad no soportada: "$"`: CodeUnsupportedUnit,
+		"no property for index 7":  CodeUnknown,
+	} {
+		assert.Equal(t, want, ClassifyErrorMessage(msg), msg)
+	}
+}
+
+func TestValidateErrorCodes(t *testing.T) {
+	record := &TrafficOffense{Vehicle: "AB"}
+	assert.Equal(t, CodeInvalidVehicle, ErrorCodeOf(record.Validate()))
+
+	record = &TrafficOffense{
+		Vehicle: "ABC1234",
+		Time:    time.Date(2014, 1, 1, 0, 0, 0, 0, UruguayTimezone),
+	}
+	err := record.Validate()
+	assert.Equal(t, CodeDateTooOld, ErrorCodeOf(err))
+	assert.Contains(t, err.Error(), "es anterior a 2015-01-01")
+}
+
+func TestSQLRepository_BackfillErrorCodes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		INSERT INTO offenses (db_id, doc_source, record_id, error, error_code) VALUES
+			(45, 'a', 1, 'matrícula inválida', NULL),
+			(45, 'a', 2, 'no property for index 7', NULL),
+			(45, 'a', 3, 'falta horario', 'missing_time'),
+			(45, 'a', 4, NULL, NULL)
+	`)
+	require.NoError(t, err)
+
+	repo, _ := NewSQLOffenseRepository(db)
+	n, err := repo.BackfillErrorCodes()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	codes := map[int]string{}
+	rows, err := db.Query("SELECT record_id, COALESCE(error_code, '') FROM offenses")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int
+			code string
+		)
+		require.NoError(t, rows.Scan(&id, &code))
+		codes[id] = code
+	}
+
+	assert.Equal(t, map[int]string{
+		1: string(CodeInvalidVehicle),
+		2: string(CodeUnknown),
+		3: string(CodeMissingTime),
+		4: "",
+	}, codes)
+}
@@ -5,40 +5,52 @@
 package impo
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"regexp"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/jcodagnone/chapauy/spatial"
+	"github.com/jcodagnone/chapauy/utils/concurrency"
 	"github.com/jcodagnone/chapauy/utils/htmlutils"
-	"github.com/mattn/go-isatty"
-	"github.com/schollz/progressbar/v3"
 	"golang.org/x/net/html"
 )
 
+// ExtractorVersion identifies the behavior of the parser and is recorded for
+// every extracted document. Bump it when a change to the extraction should
+// reach the documents already extracted, see `chapa impo reextract`.
+const ExtractorVersion = 5
+
 // UR represents Unidad Reajustable.
-// We encode as an integer to avoid losing precision with 0.5 values.
-// The value is stored as 10× the actual value (e.g., 5.5 UR is stored as 55).
+// We encode as an integer to avoid losing precision with fractional values.
+// The value is stored as URResolution× the actual value (e.g., 2.375 UR is
+// stored as 2375).
 type UR int
 
-const urResolution = 100
+// URResolution is the number of UR units in one UR: fines are published with
+// up to three decimals. Databases built with another resolution are migrated
+// by CreateSchema, see URResolutionMetaKey.
+const URResolution = 1000
+
+// urDecimals is the number of decimals of URResolution.
+const urDecimals = 3
 
 // String formats the UR value back to a printable string.
 func (ur UR) String() string {
-	a, b := int(ur)/urResolution, int(ur)%urResolution
+	a, b := int(ur)/URResolution, int(ur)%URResolution
 	if b == 0 {
 		return strconv.Itoa(a)
 	}
 
-	return strconv.Itoa(a) + "." + strings.TrimRight(strconv.Itoa(b), "0")
+	return strconv.Itoa(a) + "." + strings.TrimRight(fmt.Sprintf("%0*d", urDecimals, b), "0")
 }
 
 // Converts from string representation `0,5' in the of UR to its internal representation.
@@ -72,23 +84,17 @@ func parseUR(s string) (UR, error) {
 		return 0, fmt.Errorf("%w %q: %w", errParseInt, parts[0], err)
 	}
 
-	ret := a * urResolution
+	ret := a * URResolution
 
 	// If there is a fractional part, process it.
 	if len(parts) == 2 {
 		b := parts[1]
-		// Pad the fractional part with a trailing zero if needed.
-		n := len(b)
-		if n > 2 {
-			return 0, errors.New("only one decimal is supported")
+		// Pad the fractional part with trailing zeros if needed.
+		if len(b) > urDecimals {
+			return 0, fmt.Errorf("at most %d decimals are supported", urDecimals)
 		}
 
-		switch n {
-		case 1:
-			b += "0"
-		case 0:
-			b = "00"
-		}
+		b += strings.Repeat("0", urDecimals-len(b))
 		// Parse the fractional part
 		fraction, err := strconv.ParseInt(b, 10, 64)
 		if err != nil || fraction < 0 {
@@ -101,11 +107,47 @@ func parseUR(s string) (UR, error) {
 	return UR(ret), nil
 }
 
-// UruguayTimezone is the time location for Uruguay.
+var numericUnit = regexp.MustCompile(`^\d+(?:[.,]\d+)?$`)
+
+// fineFromUnitQuantity computes the fine of the documents that split it in
+// "Unidad" and "Cantidad" columns. The unit is either the name of the unit
+// ("UR", in which case a "Valor" column, if any, is the value of each one) or
+// the value of the unit in UR; the fine is the quantity times the unit.
+// Amounts in pesos can't be expressed in UR and are reported as errors.
+func fineFromUnitQuantity(valor UR, unit, quantity string) (UR, error) {
+	q, err := parseUR(strings.TrimSpace(quantity))
+	if err != nil {
+		return 0, fmt.Errorf("%w cantidad %q: %w", ErrURParse, quantity, err)
+	}
+
+	unit = strings.TrimSpace(unit)
+
+	var perUnit UR
+
+	switch {
+	case numericUnit.MatchString(unit):
+		if perUnit, err = parseUR(unit); err != nil {
+			return 0, fmt.Errorf("%w unidad %q: %w", ErrURParse, unit, err)
+		}
+	case normalize(unit) == "ur" || unit == "":
+		perUnit = URResolution
+		if valor != 0 {
+			perUnit = valor
+		}
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedUnit, unit)
+	}
+
+	return UR(int(perUnit) * int(q) / URResolution), nil
+}
+
+// UruguayTimezone is the time location for Uruguay. The timezone database is
+// embedded (see tzdata.go): a fixed UTC-3 would misread the documents from
+// before 2015, when Uruguay still had daylight saving time.
 var UruguayTimezone = func() *time.Location {
 	tz, err := time.LoadLocation("America/Montevideo")
 	if err != nil {
-		panic(err)
+		panic(fmt.Sprintf("loading America/Montevideo: %v", err))
 	}
 
 	return tz
@@ -155,6 +197,13 @@ type Document struct {
 	DocSource string    `json:"doc_src,omitempty"`
 	DocID     string    `json:"doc_id,omitempty"`
 	DocDate   time.Time `json:"doc_date"`
+	title     string    // as found in the HTML, to report unknown issuers
+	// headers ignored because no property is known for them, see
+	// ClientOptions.LearnHeaders
+	unknownHeaders []string
+	// spellings of the countries that aren't known, to be mapped by the
+	// curators
+	unknownCountries []string
 }
 
 // TrafficOffense represents a single traffic violation.
@@ -171,6 +220,8 @@ type TrafficOffense struct {
 	Description     string         `json:"description"`     // Offense description, e.g. 'Exceso de velocidad hasta 20 km/h'
 	UR              UR             `json:"ur"`              // Fine amount in UR
 	Error           string         `json:"error,omitempty"` // The error that occurred
+	ErrorCode       ErrorCode      `json:"error_code,omitempty"`
+	Raw             []string       `json:"raw,omitempty"`   // Cells of the row as found in the document, see ClientOptions.KeepRaw
 	Point           *spatial.Point `json:"point,omitempty"` // Geocoded point
 	ArticleIDs      []string       `json:"article_id"`
 	ArticleCodes    []int8         `json:"article_codes"`
@@ -182,6 +233,11 @@ type TrafficOffense struct {
 	H3Res6          uint64         `json:"h3_res6"`
 	H3Res7          uint64         `json:"h3_res7"`
 	H3Res8          uint64         `json:"h3_res8"`
+	H3Res9          uint64         `json:"h3_res9"`
+	H3Res10         uint64         `json:"h3_res10"`
+	// ProcessedAt is when the authority processed the offense, the "Fecha
+	// Ingreso" of Vialidad
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
 }
 
 // OffenseProperty represents a property of a traffic offense.
@@ -198,98 +254,331 @@ const (
 	propLocalidad
 	propHora
 	propCountry
+	propUnit
+	propQuantity
+	propProcessedAt
 	// used to ignore columns.
 	propIgnore
 )
 
-// phrases. This function maps these phrases to the concepts.
-func documentPropertyFromString(s string) (OffenseProperty, error) {
-	ns := normalize(s)
+// headerSynonyms are the headers each property is found under: the documents
+// use different phrases for the same concept, and their typos.
+var headerSynonyms = map[OffenseProperty][]string{
+	propVehicle: {
+		"Matrícula",
+		"Matrícula y padrón",
+		"ATRICULA", // https://www.impo.com.uy/bases/resoluciones-transito-rionegro/116-2023
+		"MATRICLA", // https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/38-2024
+		"MAT.",     // https://www.impo.com.uy/bases/notificaciones-transito-colonia/78-2025
+	},
+	propTime: {
+		"Fecha y Hora",
+		"Fecha-Hora",
+		"Fecha-Hola", // https://www.impo.com.uy/bases/notificaciones-transito-movilidad-maldonado/172-2025
+		"Fecha",
+	},
+	propLocation: {
+		"Intersección",
+		"ntersección", // https://www.impo.com.uy/bases/notificaciones-cgm/57-2017
+		"Lugar",
+		"Ubicación",
+	},
+	propID: {
+		"Intervenido",
+		"Serie-Boleta",
+		"ID_BOLETA",
+		"ID",
+	},
+	propDescription: {
+		"Artículo",
+		"INFRACCION",
+		"Nom. Tributo",
+		"Detalle",
+		"Detalles",
+		"Multa",
+		"CONDUCTOR", // https://www.impo.com.uy/bases/notificaciones-transito-colonia/76-2025
+	},
+	propUR: {
+		"Valor en UR",
+		"Valor UR",
+		"Valor Total",
+		"Valor",
+		"UR",
+		"Monto",
+	},
+	// Lavalleja provee informacion adicional de localidad
+	propLocalidad: {
+		"Localidad",
+	},
+	// Lavalleja separa la hora del día https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/25-2025
+	propHora: {
+		"Hora",
+	},
+	// Caminera arrancó a exponerlo desde https://impo.com.uy/bases/resoluciones-policia-caminera/1000-2025
+	// Esto viene de https://www.gub.uy/congreso-intendentes/comunicacion/noticias/multas-transito-vehiculos-matricula-extranjera
+	// Esta instrucción se imparte porque el sistema informático no distingue matrículas nacionales de extranjeras. Por ese motivo
+	// el dato de la procedencia debe ser preciso por constituir un factor central para su correcta visualización.
+	// A título informativo, por ejemplo, las motos de Uruguay y los autos de origen argentino –con matrículas anteriores
+	// a la del Mercosur-, comparten la misma estructura de “3 letras + 3 números”, por lo que, si al anotarse la infracción se
+	//  la marca como “vehículo nacional”, la misma irá directamente al Sucive, y si lo marcan como “vehículo extranjero”
+	//  irá al nuevo departamento “extranjeros”. De la forma en que se haga esta anotación en el sistema, dependerá
+	//  la correcta visualización como vehículo extranjero desde las plataformas del Sucive.
+	propCountry: {
+		"Pais",
+		"País",
+	},
+	// Caminera desde https://impo.com.uy/bases/resoluciones-policia-caminera/1000-2025 expresa la
+	// multa como una cantidad de unidades. Ver fineFromUnitQuantity.
+	propUnit: {
+		"Unidad",
+	},
+	propQuantity: {
+		"Cantidad",
+	},
+	// Vialidad publica la fecha en que se procesó la infracción, no la de la
+	// infracción: ver TimeRule.
+	propProcessedAt: {
+		"Fecha Ingreso",
+		"Fecha de Ingreso",
+	},
+	propIgnore: {
+		"CI.",                   // Colonia desde https://www.impo.com.uy/bases/notificaciones-transito-colonia/76-2025 reporta cedula
+		"Documento",             // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
+		"N° Documento",          // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
+		"Nombre o razón social", // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
+		"Deuda (11/11)",         // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
+	},
+}
 
-	for prop, names := range map[OffenseProperty][]string{
-		propVehicle: {
-			"Matrícula",
-			"Matrícula y padrón",
-			"ATRICULA", // https://www.impo.com.uy/bases/resoluciones-transito-rionegro/116-2023
-			"MATRICLA", // https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/38-2024
-			"MAT.",     // https://www.impo.com.uy/bases/notificaciones-transito-colonia/78-2025
-		},
-		propTime: {
-			"Fecha y Hora",
-			"Fecha-Hora",
-			"Fecha-Hola", // https://www.impo.com.uy/bases/notificaciones-transito-movilidad-maldonado/172-2025
-			"Fecha",
-			"Fecha Ingreso",
-		},
-		propLocation: {
-			"Intersección",
-			"ntersección", // https://www.impo.com.uy/bases/notificaciones-cgm/57-2017
-			"Lugar",
-			"Ubicación",
-		},
-		propID: {
-			"Intervenido",
-			"Serie-Boleta",
-			"ID_BOLETA",
-			"ID",
-		},
-		propDescription: {
-			"Artículo",
-			"INFRACCION",
-			"Nom. Tributo",
-			"Detalle",
-			"Detalles",
-			"Multa",
-			"CONDUCTOR", // https://www.impo.com.uy/bases/notificaciones-transito-colonia/76-2025
-		},
-		propUR: {
-			"Valor en UR",
-			"Valor UR",
-			"Valor Total",
-			"Valor",
-			"UR",
-			"Monto",
-		},
-		// Lavalleja provee informacion adicional de localidad
-		propLocalidad: {
-			"Localidad",
-		},
-		// Lavalleja separa la hora del día https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/25-2025
-		propHora: {
-			"Hora",
-		},
-		// Caminera arrancó a exponerlo desde https://impo.com.uy/bases/resoluciones-policia-caminera/1000-2025
-		// Esto viene de https://www.gub.uy/congreso-intendentes/comunicacion/noticias/multas-transito-vehiculos-matricula-extranjera
-		// Esta instrucción se imparte porque el sistema informático no distingue matrículas nacionales de extranjeras. Por ese motivo
-		// el dato de la procedencia debe ser preciso por constituir un factor central para su correcta visualización.
-		// A título informativo, por ejemplo, las motos de Uruguay y los autos de origen argentino –con matrículas anteriores
-		// a la del Mercosur-, comparten la misma estructura de “3 letras + 3 números”, por lo que, si al anotarse la infracción se
-		//  la marca como “vehículo nacional”, la misma irá directamente al Sucive, y si lo marcan como “vehículo extranjero”
-		//  irá al nuevo departamento “extranjeros”. De la forma en que se haga esta anotación en el sistema, dependerá
-		//  la correcta visualización como vehículo extranjero desde las plataformas del Sucive.
-		propCountry: {
-			"Pais",
-			"País",
-		},
-		propIgnore: {
-			"Unidad",                // TODO(juan) caminera tengo que volver a esto
-			"Cantidad",              // TODO(juan) caminera tengo que volver a esto
-			"CI.",                   // Colonia desde https://www.impo.com.uy/bases/notificaciones-transito-colonia/76-2025 reporta cedula
-			"Documento",             // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
-			"N° Documento",          // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
-			"Nombre o razón social", // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
-			"Deuda (11/11)",         // https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025
-		},
-	} {
+// fuzzyHeaderMinLen is the length of the shortest normalized synonym matched
+// with a typo: shorter ones, like "ur" or "id", are one edit away from too
+// many words.
+const fuzzyHeaderMinLen = 5
+
+// normalizedHeaderSynonyms are the headerSynonyms by normalized name, built
+// once instead of normalizing every synonym for each header cell.
+var normalizedHeaderSynonyms = func() map[string]OffenseProperty {
+	ret := make(map[string]OffenseProperty)
+
+	for prop, names := range headerSynonyms {
 		for _, name := range names {
-			normalizedName := normalize(name)
-			if ns == normalizedName {
-				return prop, nil
+			ns := normalize(name)
+			if other, ok := ret[ns]; ok && other != prop {
+				panic(fmt.Sprintf("header synonym %q is both %v and %v", name, other, prop))
 			}
+
+			ret[ns] = prop
+		}
+	}
+
+	return ret
+}()
+
+// documentPropertyFromString maps a header to its property. Headers that
+// aren't a synonym are matched with the synonyms one edit away, like the
+// typos of "MATRICLA", as long as all of them agree on the property.
+func documentPropertyFromString(s string) (OffenseProperty, error) {
+	ns := normalize(s)
+
+	if prop, ok := normalizedHeaderSynonyms[ns]; ok {
+		return prop, nil
+	}
+
+	var (
+		match OffenseProperty
+		found bool
+	)
+
+	for name, prop := range normalizedHeaderSynonyms {
+		if len(name) < fuzzyHeaderMinLen || !withinOneEdit(ns, name) {
+			continue
+		}
+
+		if found && prop != match {
+			return 0, fmt.Errorf("%w %q: ambiguous between %v and %v", ErrHeaderUnknown, s, match, prop)
+		}
+
+		match, found = prop, true
+	}
+
+	if found {
+		return match, nil
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrHeaderUnknown, s)
+}
+
+// withinOneEdit tells whether a and b differ by at most one insertion,
+// deletion or substitution.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	i := 0
+	for i < len(ra) && ra[i] == rb[i] {
+		i++
+	}
+
+	if len(ra) == len(rb) {
+		// substitution
+		return i == len(ra) || string(ra[i+1:]) == string(rb[i+1:])
+	}
+
+	// insertion in the longer one
+	return string(ra[i:]) == string(rb[i+1:])
+}
+
+// offensePropertyNames name the properties a header can be mapped to by the
+// curators.
+var offensePropertyNames = map[OffenseProperty]string{
+	propVehicle:     "vehicle",
+	propTime:        "time",
+	propLocation:    "location",
+	propID:          "id",
+	propDescription: "description",
+	propUR:          "ur",
+	propLocalidad:   "localidad",
+	propHora:        "hora",
+	propCountry:     "country",
+	propUnit:        "unit",
+	propQuantity:    "quantity",
+	propProcessedAt: "processed_at",
+	propIgnore:      "ignore",
+}
+
+func (p OffenseProperty) String() string {
+	if name, ok := offensePropertyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OffenseProperty(%d)", int(p))
+}
+
+// ParseOffenseProperty returns the property with the given name.
+func ParseOffenseProperty(name string) (OffenseProperty, error) {
+	for p, n := range offensePropertyNames {
+		if n == name {
+			return p, nil
 		}
 	}
 
-	return 0, fmt.Errorf("unknown property for header %q", s)
+	return 0, fmt.Errorf("unknown offense property %q", name)
+}
+
+// OffensePropertyNames returns the names of every property, sorted.
+func OffensePropertyNames() []string {
+	ret := make([]string, 0, len(offensePropertyNames))
+	for _, n := range offensePropertyNames {
+		ret = append(ret, n)
+	}
+
+	slices.Sort(ret)
+
+	return ret
+}
+
+// headerMapper maps the headers of a table to properties, falling back to
+// the synonyms learned from the curators (see ListHeaderSynonyms). When learn
+// is set, headers that are still unknown are ignored and collected instead of
+// aborting the document.
+//
+// It also maps the countries of the rows, falling back to the spellings
+// learned from the curators (see ListCountrySynonyms). The unknown ones are
+// always collected: the row gets ErrUnknownCountry.
+//
+// And it knows the TimeRule of the database, for the tables without the date
+// of the offense.
+type headerMapper struct {
+	synonyms map[string]OffenseProperty // by normalized header
+	learn    bool
+	unknown  []string
+
+	countries        map[string]string // ISO codes by normalized spelling
+	unknownCountries []string
+
+	timeRule TimeRule
+
+	done <-chan struct{} // closed when the extraction is given up
+}
+
+// abandoned tells whether the extraction was given up, to stop walking rows
+// nobody will read.
+func (m *headerMapper) abandoned() bool {
+	if m == nil {
+		return false
+	}
+
+	select {
+	case <-m.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *headerMapper) property(s string) (OffenseProperty, error) {
+	prop, err := documentPropertyFromString(s)
+	if err == nil || m == nil {
+		return prop, err
+	}
+
+	if p, ok := m.synonyms[normalize(s)]; ok {
+		return p, nil
+	}
+
+	if m.learn {
+		m.unknown = append(m.unknown, strings.TrimSpace(s))
+
+		return propIgnore, nil
+	}
+
+	return prop, err
+}
+
+// rule returns the TimeRule of the database, TimeFromDocument without one.
+func (m *headerMapper) rule() TimeRule {
+	if m == nil || m.timeRule == "" {
+		return TimeFromDocument
+	}
+
+	return m.timeRule
+}
+
+func (m *headerMapper) country(s string) (string, error) {
+	iso, err := normalizeCountryName(s)
+	if err == nil || m == nil {
+		return iso, err
+	}
+
+	if iso, ok := m.countries[normalize(s)]; ok {
+		return iso, nil
+	}
+
+	if s = strings.TrimSpace(s); !slices.Contains(m.unknownCountries, s) {
+		m.unknownCountries = append(m.unknownCountries, s)
+	}
+
+	return "", err
+}
+
+// setCountry sets the ISO code of the country of the vehicle, "" when the
+// document says it's another one.
+func (record *TrafficOffense) setCountry(iso string) {
+	if iso == "" {
+		return
+	}
+
+	if record.VehicleInfo == nil {
+		record.VehicleInfo = &VehicleInfo{}
+	}
+
+	record.VehicleInfo.Country = iso
 }
 
 // Assigns a value to the appropriate field based on the index.
@@ -297,13 +586,30 @@ func (record *TrafficOffense) set(i OffenseProperty, s string) error {
 	switch i {
 	case propVehicle:
 		record.Vehicle = NormalizeVehicleID(s)
+
+		if HasForeignMarker(s) {
+			if record.VehicleInfo == nil {
+				record.VehicleInfo = &VehicleInfo{}
+			}
+
+			record.VehicleInfo.Foreign = true
+		}
 	case propTime:
 		if s != "" {
 			record.Time = parseDateTime(s)
 			if record.Time.IsZero() {
-				return fmt.Errorf("%w: %q", errParseDateTime, s)
+				return fmt.Errorf("%w: %q", ErrDateTimeParse, s)
 			}
 		}
+	case propProcessedAt:
+		if s != "" {
+			when := parseDateTime(s)
+			if when.IsZero() {
+				return fmt.Errorf("%w: %q", ErrDateTimeParse, s)
+			}
+
+			record.ProcessedAt = &when
+		}
 	case propLocation:
 		record.Location = s
 	case propID:
@@ -314,24 +620,18 @@ func (record *TrafficOffense) set(i OffenseProperty, s string) error {
 	case propUR:
 		ur, err := parseUR(s)
 		if err != nil {
-			return fmt.Errorf("can't convert %q to UR: %w", s, err)
+			return fmt.Errorf("%w %q to UR: %w", ErrURParse, s, err)
 		}
 
 		record.UR = ur
 	case propCountry:
-		country, err := normalizeCountryName(s)
-		if err != nil {
-			// preferimos cortar ejecución para poder cargar el País, en vez
-			// de almacenarlo con error o ignorarlo
-			panic(err)
-		}
-
-		if country != "" {
-			if record.VehicleInfo == nil {
-				record.VehicleInfo = &VehicleInfo{}
+		if s != "" {
+			country, err := normalizeCountryName(s)
+			if err != nil {
+				return err
 			}
 
-			record.VehicleInfo.Country = country
+			record.setCountry(country)
 		}
 	case propIgnore:
 		// skip
@@ -343,12 +643,6 @@ func (record *TrafficOffense) set(i OffenseProperty, s string) error {
 }
 
 var vehiclePattern = regexp.MustCompile("(?i)^[A-Z0-9]{4,10}$")
-var (
-	errInvalidVehicle = errors.New("matrícula inválida")
-	errMissingTime    = errors.New("falta horario")
-	errParseInt       = errors.New("parsing integer part")
-	errParseDateTime  = errors.New("couldn't parse datetime")
-)
 
 const suciveArt9Descr = "Cobros por acciones, trámites o gestiones"
 
@@ -359,19 +653,19 @@ func (record *TrafficOffense) Validate() error {
 	}
 
 	if record.Vehicle == "" || !vehiclePattern.MatchString(record.Vehicle) {
-		return errInvalidVehicle
+		return ErrInvalidVehicle
 	}
 
 	if record.Time.IsZero() {
-		return errMissingTime
+		return ErrMissingTime
 	}
 
 	if record.Time.Before(time.Date(2015, 1, 1, 0, 0, 0, 0, UruguayTimezone)) {
-		return fmt.Errorf("la fecha `%v' es anterior a 2015-01-01", record.Time)
+		return fmt.Errorf("la fecha `%v' %w", record.Time, ErrDateTooOld)
 	}
 
 	if record.Description == "" {
-		return errors.New("falta descripción")
+		return ErrMissingDescription
 	}
 
 	return nil
@@ -383,18 +677,105 @@ type ExtractMetrics struct {
 	NewErrors      int
 	SuccessfulDocs int
 	FailedDocs     int
+	OversizedDocs  int // failed documents larger than ClientOptions.ExtractMaxBytes
+	TimedOutDocs   int // failed documents that took longer than ClientOptions.ExtractTimeout
+	PanickedDocs   int // failed documents whose extraction panicked
+	// ExtractTime is the time spent extracting the documents, adding the
+	// workers, and SlowestDoc the document that took the longest.
+	ExtractTime    time.Duration
+	SlowestDoc     string
+	SlowestDocTime time.Duration
+	// UnmatchedIssuers are the documents whose title doesn't mention any of
+	// the issuer aliases of the database. Their ID comes from the URL.
+	UnmatchedIssuers []UnmatchedIssuer
+	// UnknownHeaders are the headers ignored by ClientOptions.LearnHeaders.
+	UnknownHeaders []UnknownHeader
+	// UnknownValues are the values that couldn't be interpreted, like the
+	// spellings of the countries, left for the curators to map.
+	UnknownValues []UnknownValue
+	// ErrorRateAlarms are the databases whose error rate jumped over their
+	// ErrorBudget.
+	ErrorRateAlarms []ErrorRateAlarm
+}
+
+// UnknownHeader is a table header no property is known for, ignored while
+// extracting a document.
+type UnknownHeader struct {
+	DocSource string
+	Header    string
 }
 
+// UnmatchedIssuer is a document whose issuer wasn't recognized, usually
+// because IMPO changed the wording of the title. Adding an alias to
+// issuers.json fixes it.
+type UnmatchedIssuer struct {
+	DocSource string
+	Title     string
+}
+
+// Errors recorded for the documents the extraction gave up on.
+var (
+	ErrDocumentTooLarge  = errors.New("document too large")
+	ErrExtractionTimeout = errors.New("extraction timed out")
+)
+
 // Merge combines two ParseMetrics.
 func (m *ExtractMetrics) Merge(o *ExtractMetrics) *ExtractMetrics {
 	m.NewRecords += o.NewRecords
 	m.NewErrors += o.NewErrors
 	m.SuccessfulDocs += o.SuccessfulDocs
 	m.FailedDocs += o.FailedDocs
+	m.OversizedDocs += o.OversizedDocs
+	m.TimedOutDocs += o.TimedOutDocs
+	m.PanickedDocs += o.PanickedDocs
+	m.ExtractTime += o.ExtractTime
+
+	if o.SlowestDocTime > m.SlowestDocTime {
+		m.SlowestDoc, m.SlowestDocTime = o.SlowestDoc, o.SlowestDocTime
+	}
+
+	m.UnmatchedIssuers = append(m.UnmatchedIssuers, o.UnmatchedIssuers...)
+	m.UnknownHeaders = append(m.UnknownHeaders, o.UnknownHeaders...)
+	m.UnknownValues = append(m.UnknownValues, o.UnknownValues...)
+	m.ErrorRateAlarms = append(m.ErrorRateAlarms, o.ErrorRateAlarms...)
 
 	return m
 }
 
+// offensesTable extracts the offenses of a table one row at a time, for both
+// the DOM and the streaming extraction. The first row maps the columns to the
+// properties, unless the document is known to lack the headers.
+type offensesTable struct {
+	offenses           *[]*TrafficOffense
+	defaultDate        *time.Time
+	defaultDescription string
+	defaultHeaderProps map[int]OffenseProperty
+	keepRaw            bool
+	headers            *headerMapper
+	nr                 int
+	columnMap          map[int]OffenseProperty
+}
+
+func newOffensesTable(
+	offenses *[]*TrafficOffense,
+	defaultDate *time.Time,
+	defaultDescription string,
+	defaultHeaderProps map[int]OffenseProperty,
+	keepRaw bool,
+	headers *headerMapper,
+) *offensesTable {
+	return &offensesTable{
+		offenses:           offenses,
+		defaultDate:        defaultDate,
+		defaultDescription: defaultDescription,
+		defaultHeaderProps: defaultHeaderProps,
+		keepRaw:            keepRaw,
+		headers:            headers,
+		// Map to store the column index to property mapping
+		columnMap: make(map[int]OffenseProperty),
+	}
+}
+
 // Extracts offenses from the HTML table.
 func visitOffensesTable(
 	child *html.Node,
@@ -402,10 +783,10 @@ func visitOffensesTable(
 	defaultDate *time.Time,
 	defaultDescription string,
 	defaultHeaderProps map[int]OffenseProperty,
+	keepRaw bool,
+	headers *headerMapper,
 ) error {
-	nr := 0
-	// Map to store the column index to property mapping
-	columnMap := make(map[int]OffenseProperty)
+	table := newOffensesTable(offenses, defaultDate, defaultDescription, defaultHeaderProps, keepRaw, headers)
 
 	for child := child.FirstChild; child != nil; child = child.NextSibling {
 		// We're interested in <tr> elements
@@ -413,259 +794,353 @@ func visitOffensesTable(
 			continue
 		}
 
-		sb := strings.Builder{}
-
-		if nr == 0 {
-			// Process header row to determine column mapping
-			i := 0
+		if err := table.visitRow(child); err != nil {
+			return err
+		}
+	}
 
-			if len(defaultHeaderProps) > 0 {
-				columnMap = defaultHeaderProps
-				nr++
-				// we have to process the first row as data
-			} else {
-				for child := child.FirstChild; child != nil; child = child.NextSibling {
-					if child.Type != html.ElementNode || !strings.EqualFold("td", child.Data) {
-						continue
-					}
+	return nil
+}
 
-					sb.Reset()
+// visitRow extracts the offense of a <tr>, or the column mapping if it's the
+// header.
+func (t *offensesTable) visitRow(row *html.Node) error {
+	if t.headers.abandoned() {
+		return ErrExtractionTimeout
+	}
 
-					err := htmlutils.Node2string(child, &sb)
-					if err != nil {
-						continue
-					}
+	sb := strings.Builder{}
 
-					columnMap[i], err = documentPropertyFromString(sb.String())
-					if err != nil {
-						return err
-					}
+	if t.nr == 0 {
+		// Process header row to determine column mapping
+		i := 0
 
-					i++
+		if len(t.defaultHeaderProps) > 0 {
+			t.columnMap = t.defaultHeaderProps
+			t.nr++
+			// we have to process the first row as data
+		} else {
+			for child := row.FirstChild; child != nil; child = child.NextSibling {
+				if child.Type != html.ElementNode || !strings.EqualFold("td", child.Data) {
+					continue
 				}
 
-				hasDescriptionCol := false
-
-				for _, prop := range columnMap {
-					if prop == propDescription {
-						hasDescriptionCol = true
+				sb.Reset()
 
-						break
-					}
+				err := htmlutils.Node2string(child, &sb)
+				if err != nil {
+					continue
 				}
 
-				if !hasDescriptionCol && defaultDescription == "" {
-					return errors.New("tabla sin columna descripción")
+				t.columnMap[i], err = t.headers.property(sb.String())
+				if err != nil {
+					return err
 				}
 
-				nr++
-
-				continue
+				i++
 			}
-		}
 
-		hasDateCol := false
+			hasDescriptionCol := false
 
-		for _, prop := range columnMap {
-			if propTime == prop {
-				hasDateCol = true
+			for _, prop := range t.columnMap {
+				if prop == propDescription {
+					hasDescriptionCol = true
 
-				break
+					break
+				}
+			}
+
+			if !hasDescriptionCol && t.defaultDescription == "" {
+				return errors.New("tabla sin columna descripción")
 			}
-		}
 
-		record := TrafficOffense{}
-		record.RecordID = nr
+			t.nr++
 
-		if !hasDateCol {
-			// some documents like https://www.impo.com.uy/bases/notificaciones-transito-colonia/1-2023 don't
-			// have an infraction date available. To avoid discarting the records, we assume that the record
-			// is the one of the document
-			record.Time = *defaultDate
+			return nil
 		}
+	}
+
+	hasDateCol := false
+
+	for _, prop := range t.columnMap {
+		if propTime == prop {
+			hasDateCol = true
 
-		if defaultDescription != "" {
-			record.Description = defaultDescription
+			break
 		}
+	}
 
-		var lastErr error // Track the first error for each record
+	record := TrafficOffense{}
+	record.RecordID = t.nr
 
-		i := 0
+	if !hasDateCol {
+		// some documents like https://www.impo.com.uy/bases/notificaciones-transito-colonia/1-2023 don't
+		// have an infraction date available. To avoid discarting the records, we assume that the record
+		// is the one of the document
+		record.Time = *t.defaultDate
+	}
 
-		// casos especiales de Lavalleja que envia la fecha y el lugar separado
-		// recolectamos los valores parciales mientras recorremos las columnas
-		// para luega intentar usarlos
-		var hora, fecha, localidad string
+	if t.defaultDescription != "" {
+		record.Description = t.defaultDescription
+	}
 
-		for child := child.FirstChild; child != nil; child = child.NextSibling {
-			if child.Type != html.ElementNode || !strings.EqualFold("td", child.Data) {
-				continue
-			}
+	var lastErr error // Track the first error for each record
 
-			sb.Reset()
-
-			err := htmlutils.Node2string(child, &sb)
-			if err == nil {
-				s := sb.String()
-				// Get the property for this column index
-				if prop, exists := columnMap[i]; exists {
-					switch prop {
-					case propHora:
-						hora = s
-					case propLocalidad:
-						localidad = s
-					case propTime:
-						fecha = s
-						err = record.set(prop, s)
-					default:
-						err = record.set(prop, s)
-					}
-				} else {
-					err = fmt.Errorf("no property for index %d", i)
-				}
-			}
+	i := 0
 
-			if err != nil && lastErr == nil {
-				lastErr = err
-			}
+	// casos especiales de Lavalleja que envia la fecha y el lugar separado
+	// recolectamos los valores parciales mientras recorremos las columnas
+	// para luega intentar usarlos
+	var hora, fecha, localidad, unidad, cantidad string
 
-			i++
+	for child := row.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || !strings.EqualFold("td", child.Data) {
+			continue
 		}
 
-		// merge special split columns
-		if localidad != "" && record.Location != "" {
-			record.Location = fmt.Sprintf("%s, %s", record.Location, localidad)
+		sb.Reset()
+
+		err := htmlutils.Node2string(child, &sb)
+		if t.keepRaw {
+			record.Raw = append(record.Raw, sb.String())
 		}
 
-		if !record.Time.IsZero() && fecha != "" {
-			if t := parseDateTime(fmt.Sprintf("%s %s", fecha, hora)); !t.IsZero() {
-				record.Time = t
+		if err == nil {
+			s := sb.String()
+			// Get the property for this column index
+			if prop, exists := t.columnMap[i]; exists {
+				switch prop {
+				case propHora:
+					hora = s
+				case propLocalidad:
+					localidad = s
+				case propUnit:
+					unidad = s
+				case propQuantity:
+					cantidad = s
+				case propTime:
+					fecha = s
+					err = record.set(prop, s)
+				case propCountry:
+					if s != "" {
+						var country string
+						if country, err = t.headers.country(s); err == nil {
+							record.setCountry(country)
+						}
+					}
+				default:
+					err = record.set(prop, s)
+				}
+			} else {
+				err = fmt.Errorf("no property for index %d", i)
 			}
 		}
 
-		if lastErr == nil {
-			lastErr = record.Validate()
+		if err != nil && lastErr == nil {
+			lastErr = err
 		}
 
-		if lastErr == nil && !record.Time.IsZero() && record.Time.After(*defaultDate) {
-			// ver PAV1450 en https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/16-2024
-			lastErr = fmt.Errorf("la fecha `%v' es más nueva que la fecha de publicación `%v'", record.Time, *defaultDate)
-		}
+		i++
+	}
+
+	// merge special split columns
+	if localidad != "" && record.Location != "" {
+		record.Location = fmt.Sprintf("%s, %s", record.Location, localidad)
+	}
 
-		if lastErr != nil {
-			record.Error = lastErr.Error()
+	if !record.Time.IsZero() && fecha != "" {
+		if when := parseDateTime(fmt.Sprintf("%s %s", fecha, hora)); !when.IsZero() {
+			record.Time = when
 		}
+	}
+
+	if !hasDateCol && record.ProcessedAt != nil && t.headers.rule() == TimeFromProcessedAt {
+		record.Time = *record.ProcessedAt
+	}
+
+	if cantidad != "" && lastErr == nil {
+		record.UR, lastErr = fineFromUnitQuantity(record.UR, unidad, cantidad)
+	}
+
+	if lastErr == nil {
+		lastErr = record.Validate()
+	}
+
+	if lastErr == nil && !record.Time.IsZero() && record.Time.After(*t.defaultDate) {
+		// ver PAV1450 en https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/16-2024
+		lastErr = fmt.Errorf("la fecha `%v' %w `%v'", record.Time, ErrDateFuture, *t.defaultDate)
+	}
+
+	if lastErr != nil {
+		record.Error = lastErr.Error()
+		record.ErrorCode = ErrorCodeOf(lastErr)
+	}
+
+	*t.offenses = append(*t.offenses, &record)
+
+	t.nr++
+
+	return nil
+}
+
+// visitTitle detects the issuer and the ID of the document from its title.
+func (doc *Document) visitTitle(issuers []string, n *html.Node) error {
+	sb := strings.Builder{}
+
+	err := htmlutils.Node2string(n, &sb)
+	if err != nil {
+		return err
+	}
+
+	// Title: 'Notificación Dirección General de Tránsito y Transporte Intendencia de Maldonado N° 1/025'
+	doc.title = strings.TrimSpace(sb.String())
 
-		*offenses = append(*offenses, &record)
+	// Detect the issuer
+	if rest, ok := matchIssuer(doc.title, issuers); ok {
+		// Extract notification ID (e.g., "N° 1/025" -> "1/025")
+		idx := strings.LastIndex(rest, " ")
 
-		nr++
+		if idx >= 0 && idx < len(rest)-1 {
+			doc.DocID = rest[idx+1:]
+		} else if rest == "s/n" {
+			doc.DocID = rest
+		}
 	}
 
 	return nil
 }
 
+// visitPublicationDate extracts the date of an <h5> like "Fecha de
+// Publicación: 08/04/2025", if it's the one.
+func (doc *Document) visitPublicationDate(n *html.Node) error {
+	sb := strings.Builder{}
+
+	err := htmlutils.Node2string(n, &sb)
+	if err != nil {
+		return err
+	}
+
+	date, ok, err := parsePublicationDate(sb.String())
+	if ok {
+		doc.DocDate = date
+	}
+
+	return err
+}
+
+// parsePublicationDate reads the date of a heading like "Fecha de
+// Publicación: 08/04/2025", telling whether it's the one.
+func parsePublicationDate(heading string) (time.Time, bool, error) {
+	heading = strings.ToLower(heading)
+
+	const expected = "fecha de publicación:"
+
+	idx := strings.LastIndex(heading, expected)
+	if idx < 0 {
+		return time.Time{}, false, nil
+	}
+
+	date, err := time.ParseInLocation("02/01/2006", strings.TrimSpace(heading[idx+len(expected):]), UruguayTimezone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return date, true, nil
+}
+
+// art9Phrases are the phrases of the documents whose table lacks the
+// description because all the offenses are of the art. 9 of the SUCIVE.
+var art9Phrases = []string{
+	"que se constató la contravención a lo dispuesto en el art. 9 del texto ordenado del sucive",
+	"que el cuerpo inspectivo constató la contravención a lo dispuesto en el art 9 del texto ordenado del sucive",
+	"que la intendencia de montevideo, constató la contravención a lo dispuesto en el artículo 9 del texto ordenado del sucive",
+}
+
+// descriptionFromText returns the description implied by the text of the
+// document, if any.
+func descriptionFromText(s string) string {
+	// squash multiple spaces into one and lowercase
+	text := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+
+	for _, phrase := range art9Phrases {
+		if strings.Contains(text, phrase) {
+			return suciveArt9Descr
+		}
+	}
+
+	return ""
+}
+
+// descriptionScope tracks the default description of the tables of a
+// document. Some notifications have several tables, e.g. the speeding ones
+// followed by the ones of the art. 9, so the description of a table is the
+// one implied by the text of the <p>, <pre> and <div> read since the previous
+// table, or the one of the previous table when there's none.
+type descriptionScope struct {
+	description string
+	text        strings.Builder // since the previous table
+	depth       int             // of the open <p>, <pre> and <div>
+}
+
+// write adds the text of the document read outside the tables.
+func (s *descriptionScope) write(text string) {
+	if s.depth > 0 {
+		s.text.WriteString(text)
+		s.text.WriteByte(' ')
+	}
+}
+
+// table returns the default description of the table that starts.
+func (s *descriptionScope) table() string {
+	if strings.TrimSpace(s.text.String()) != "" {
+		s.description = descriptionFromText(s.text.String())
+		s.text.Reset()
+	}
+
+	return s.description
+}
+
 // Traverses the HTML document searching for offenses and metadata.
 func visitDocument(
 	issuers []string,
 	doc *Document,
 	offenses *[]*TrafficOffense,
-	defaultDescription *string,
+	scope *descriptionScope,
 	defaultHeaderProps map[int]OffenseProperty,
+	keepRaw bool,
+	headers *headerMapper,
 	n *html.Node,
 ) error {
 	// Look for a table with class="tabla_en_texto"
 	var isTable bool
 
-	if n.Type == html.ElementNode {
+	switch n.Type {
+	case html.TextNode:
+		scope.write(n.Data)
+	case html.ElementNode:
 		switch strings.ToLower(n.Data) {
 		case "table":
 			for _, attr := range n.Attr {
 				isTable = isTable || (strings.EqualFold("class", attr.Key) && attr.Val == "tabla_en_texto")
 			}
 		case "title":
-			sb := strings.Builder{}
-
-			err := htmlutils.Node2string(n, &sb)
-			if err != nil {
+			if err := doc.visitTitle(issuers, n); err != nil {
 				return err
 			}
-
-			// Title: 'Notificación Dirección General de Tránsito y Transporte Intendencia de Maldonado N° 1/025'
-			title := strings.ToLower(sb.String())
-
-			// Detect the issuer
-			for _, issuer := range issuers {
-				if len(issuer) > 0 {
-					if idx := strings.Index(title, strings.ToLower(issuer)); idx > -1 {
-						title = strings.TrimSpace(title[idx+len(issuer):])
-						// Extract notification ID (e.g., "N° 1/025" -> "1/025")
-						idx := strings.LastIndex(title, " ")
-
-						if idx >= 0 && idx < len(title)-1 {
-							title = title[idx+1:]
-							doc.DocID = title
-
-							break
-						} else if title == "s/n" {
-							doc.DocID = title
-
-							break
-						}
-					}
-				}
-			}
 		case "h5":
-			// Extract publication date: "Fecha de Publicación: 08/04/2025"
-			sb := strings.Builder{}
-
-			err := htmlutils.Node2string(n, &sb)
-			if err != nil {
+			if err := doc.visitPublicationDate(n); err != nil {
 				return err
 			}
-
-			title := strings.ToLower(sb.String())
-
-			const expected = "fecha de publicación:"
-
-			if idx := strings.LastIndex(title, expected); idx > -1 {
-				title = strings.TrimSpace(title[idx+len(expected):])
-
-				var err error
-
-				doc.DocDate, err = time.ParseInLocation("02/01/2006", title, UruguayTimezone)
-				if err != nil {
-					return err
-				}
-			}
 		case "p", "pre", "div":
-			if *defaultDescription != "" {
-				break
-			}
-
-			// normalizeText normalizes the text by squashing multiple spaces into one and lowercasing.
-			normalizeText := func(s string) string {
-				return strings.Join(strings.Fields(strings.ToLower(s)), " ")
-			}
-
-			sb := strings.Builder{}
-			if err := htmlutils.Node2string(n, &sb); err == nil {
-				text := normalizeText(sb.String())
-				// Phrases to search for.
-				phrases := []string{
-					"que se constató la contravención a lo dispuesto en el art. 9 del texto ordenado del sucive",
-					"que el cuerpo inspectivo constató la contravención a lo dispuesto en el art 9 del texto ordenado del sucive",
-					"que la intendencia de montevideo, constató la contravención a lo dispuesto en el artículo 9 del texto ordenado del sucive",
-				}
-
-				for _, phrase := range phrases {
-					if strings.Contains(text, phrase) {
-						*defaultDescription = suciveArt9Descr
-
-						break
-					}
-				}
-			}
+			scope.depth++
+			defer func() { scope.depth-- }()
 		}
+	default:
+	}
+
+	var defaultDescription string
+	if isTable {
+		defaultDescription = scope.table()
 	}
 
 	for child := n.FirstChild; child != nil; child = child.NextSibling {
@@ -675,11 +1150,13 @@ func visitDocument(
 				child,
 				offenses,
 				&doc.DocDate,
-				*defaultDescription,
+				defaultDescription,
 				defaultHeaderProps,
+				keepRaw,
+				headers,
 			)
 		} else {
-			err = visitDocument(issuers, doc, offenses, defaultDescription, defaultHeaderProps, child)
+			err = visitDocument(issuers, doc, offenses, scope, defaultHeaderProps, keepRaw, headers, child)
 		}
 
 		if err != nil {
@@ -692,13 +1169,12 @@ func visitDocument(
 
 // ExtractDocument extracts traffic offense information from HTML.
 func ExtractDocument(issuers []string, source string, n *html.Node) ([]*TrafficOffense, error) {
-	doc := &Document{}
-	offenses := make([]*TrafficOffense, 0, 800)
-
-	var defaultDescription string
-
-	var defaultHeaderProps map[int]OffenseProperty
+	return extractOffenses(issuers, source, n, false, nil)
+}
 
+// knownHeaderProps are the columns of the documents whose tables lack the
+// header row.
+func knownHeaderProps(source string) map[int]OffenseProperty {
 	switch source {
 	case
 		"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/SN20210707001-2021",
@@ -708,17 +1184,35 @@ func ExtractDocument(issuers []string, source string, n *html.Node) ([]*TrafficO
 		"https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/13-2024",
 		"https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/11-2024",
 		"https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/17-2024":
-		defaultHeaderProps = map[int]OffenseProperty{
+		return map[int]OffenseProperty{
 			0: propVehicle,
 			1: propDescription,
 			2: propUR,
 		}
 	}
 
-	if err := visitDocument(issuers, doc, &offenses, &defaultDescription, defaultHeaderProps, n); err != nil {
+	return nil
+}
+
+// extractOffenses is ExtractDocument, optionally keeping the raw cells of
+// every row and mapping the headers with the learned synonyms.
+func extractOffenses(
+	issuers []string, source string, n *html.Node, keepRaw bool, headers *headerMapper,
+) ([]*TrafficOffense, error) {
+	doc := &Document{}
+	offenses := make([]*TrafficOffense, 0, 800)
+
+	var scope descriptionScope
+
+	if err := visitDocument(issuers, doc, &offenses, &scope, knownHeaderProps(source), keepRaw, headers, n); err != nil {
 		return nil, err
 	}
 
+	if headers != nil {
+		doc.unknownHeaders = headers.unknown
+		doc.unknownCountries = headers.unknownCountries
+	}
+
 	// Assign the document to each offense
 	for _, offense := range offenses {
 		offense.Document = doc
@@ -732,25 +1226,17 @@ func (c *Client) extractDocument(id string) (*ExtractMetrics, error) {
 	failedMetrics := &ExtractMetrics{
 		FailedDocs: 1,
 	}
-	r, err := c.store.GetDocument(id)
-
-	if err != nil {
-		return failedMetrics, fmt.Errorf("opening document %s: %w", id, err)
-	}
-
-	node, err := htmlutils.AsNode(r)
-
-	if closeErr := r.Close(); closeErr != nil {
-		return failedMetrics, fmt.Errorf("closing document: %w", closeErr)
-	}
 
+	offenses, err := c.parseDocument(id)
 	if err != nil {
-		return failedMetrics, fmt.Errorf("parsing document: %w", err)
-	}
+		switch {
+		case errors.Is(err, ErrDocumentTooLarge):
+			failedMetrics.OversizedDocs = 1
+		case errors.Is(err, ErrExtractionTimeout):
+			failedMetrics.TimedOutDocs = 1
+		}
 
-	offenses, err := ExtractDocument(c.dbRef.Issuers, id, node)
-	if err != nil {
-		return failedMetrics, fmt.Errorf("parsing document: %w", err)
+		return failedMetrics, err
 	}
 
 	if len(offenses) > 0 {
@@ -782,65 +1268,74 @@ func (c *Client) extractDocument(id string) (*ExtractMetrics, error) {
 		NewErrors:  errorsCount,
 		FailedDocs: 1,
 	}
+
+	var unmatched []UnmatchedIssuer
+
 	if len(offenses) > 0 && offenses[0].DocID == "" {
-		return failedMetrics, errors.New("document ID not found")
+		doc := offenses[0].Document
+		if _, ok := matchIssuer(doc.title, c.dbRef.Issuers); !ok {
+			// not an extraction error: it is reported at the end of the run
+			unmatched = []UnmatchedIssuer{{DocSource: id, Title: doc.title}}
+			failedMetrics.UnmatchedIssuers = unmatched
+		}
+
+		if err := c.identifyDocument(id, doc); err != nil {
+			if unmatched != nil {
+				return failedMetrics, nil
+			}
+
+			return failedMetrics, err
+		}
 	}
 
-	if n := float64(successCount); n > 0 {
-		// we have a failsafe that fail to save documents with more than 5% of errors
-		// this allows us to catch extraction errors
-		if pct := float64(errorsCount) / n * 100.0; pct > 5.0 {
-			switch id {
-			// and all these case are cases with more than 5% but that had been reviewed as
-			// ok. usually they have low number of total records
-			case
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/6-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-colonia/18-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-colonia/19-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-colonia/104-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/2211-2023",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/7-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/14-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/31-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/17-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/11-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/12-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/13-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/15-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/20-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/22-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/25-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/33-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/34-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/37-2025",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/52-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/93-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/231-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/244-2025",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/257-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/425-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/551-2024",
-				"https://www.impo.com.uy/bases/resoluciones-transito-lavalleja/334-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-soriano/204-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/7-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/9-2024",
-				"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025_A",
-				"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/41-2025",
-				"https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/14-2024",
-				"https://www.impo.com.uy/bases/notificaciones-cgm/1709-2022",
-				"https://www.impo.com.uy/bases/notificaciones-cgm/3183-2024",
-				"https://www.impo.com.uy/bases/notificaciones-cgm/3458-2025",
-				"https://www.impo.com.uy/bases/resoluciones-transito-mtop/207-2025":
-				// these documents where reviewed, and aren't issues related to the scrapper
-			default:
-				return failedMetrics, fmt.Errorf("parsing document - too many errors - %2.f%%: for example: %w", pct, firstError)
+	var unknownHeaders []UnknownHeader
+
+	if len(offenses) > 0 && len(offenses[0].unknownHeaders) > 0 {
+		headers := offenses[0].unknownHeaders
+		for _, h := range headers {
+			unknownHeaders = append(unknownHeaders, UnknownHeader{DocSource: id, Header: h})
+		}
+
+		failedMetrics.UnknownHeaders = unknownHeaders
+
+		if !c.options.DryRun {
+			if err := c.repo.SavePendingHeaders(c.dbRef.ID, id, headers); err != nil {
+				return failedMetrics, transientError{fmt.Errorf("storing pending headers: %w", err)}
+			}
+		}
+	}
+
+	var unknownValues []UnknownValue
+
+	if len(offenses) > 0 && len(offenses[0].unknownCountries) > 0 {
+		countries := offenses[0].unknownCountries
+		for _, v := range countries {
+			unknownValues = append(unknownValues, UnknownValue{DocSource: id, Kind: ValueKindCountry, Value: v})
+		}
+
+		failedMetrics.UnknownValues = unknownValues
+
+		if !c.options.DryRun {
+			if err := c.repo.SavePendingValues(c.dbRef.ID, id, ValueKindCountry, countries); err != nil {
+				return failedMetrics, transientError{fmt.Errorf("storing pending countries: %w", err)}
 			}
 		}
 	}
 
+	if n := float64(successCount); n > 0 {
+		// we have a failsafe that fail to save documents with more errors than
+		// the budget of the database, this allows us to catch extraction errors
+		if pct := float64(errorsCount) / n * 100.0; !c.dbRef.Budget.Allows(id, pct) {
+			return failedMetrics, fmt.Errorf(
+				"parsing document - too many errors - %2.f%% (budget %.1f%%): for example: %w",
+				pct, c.dbRef.Budget.MaxErrorPct, firstError,
+			)
+		}
+	}
+
 	if !c.options.DryRun && (errorsCount == 0 || !c.options.SkipErrDocs) {
 		if err := c.repo.SaveTrafficOffenses(offenses); err != nil {
-			return failedMetrics, fmt.Errorf("storing document: %w", err)
+			return failedMetrics, transientError{fmt.Errorf("storing document: %w", err)}
 		}
 	}
 
@@ -859,21 +1354,155 @@ func (c *Client) extractDocument(id string) (*ExtractMetrics, error) {
 	}
 
 	return &ExtractMetrics{
-		NewRecords:     successCount,
-		NewErrors:      errorsCount,
-		SuccessfulDocs: 1,
+		NewRecords:       successCount,
+		NewErrors:        errorsCount,
+		SuccessfulDocs:   1,
+		UnmatchedIssuers: unmatched,
+		UnknownHeaders:   unknownHeaders,
+		UnknownValues:    unknownValues,
 	}, nil
 }
 
+// identifyDocument takes the ID of a document whose title doesn't have it
+// from its URL.
+func (c *Client) identifyDocument(id string, doc *Document) error {
+	docID, year, err := c.dbRef.docIDFromURL(id)
+	if err != nil {
+		return fmt.Errorf("document ID not found: %w", err)
+	}
+
+	doc.DocID = docID
+	if doc.DocDate.IsZero() {
+		// the best we know is the year of the URL
+		doc.DocDate = time.Date(year, 1, 1, 0, 0, 0, 0, UruguayTimezone)
+	}
+
+	return nil
+}
+
+// parseDocument reads a stored document and extracts its offenses, enforcing
+// the size and time limits of the options.
+func (c *Client) parseDocument(id string) ([]*TrafficOffense, error) {
+	r, err := c.store.GetDocument(id)
+	if err != nil {
+		return nil, transientError{fmt.Errorf("opening document %s: %w", id, err)}
+	}
+
+	var src io.Reader = r
+	if c.options.ExtractMaxBytes > 0 {
+		src = io.LimitReader(r, c.options.ExtractMaxBytes+1)
+	}
+
+	content, err := io.ReadAll(src)
+
+	if closeErr := r.Close(); closeErr != nil {
+		return nil, transientError{fmt.Errorf("closing document: %w", closeErr)}
+	}
+
+	if err != nil {
+		return nil, transientError{fmt.Errorf("reading document: %w", err)}
+	}
+
+	if c.options.ExtractMaxBytes > 0 && int64(len(content)) > c.options.ExtractMaxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrDocumentTooLarge, c.options.ExtractMaxBytes)
+	}
+
+	return withTimeout(c.options.ExtractTimeout, func(ctx context.Context) ([]*TrafficOffense, error) {
+		headers := &headerMapper{
+			synonyms:  c.headerSynonyms,
+			learn:     c.options.LearnHeaders,
+			countries: c.countrySynonyms,
+			timeRule:  c.dbRef.TimeRules.Rule,
+			done:      ctx.Done(),
+		}
+		r := &doneReader{r: bytes.NewReader(content), done: ctx.Done()}
+
+		// the DOM of the largest documents takes a lot of memory with several workers
+		if c.options.ExtractStreamBytes > 0 && int64(len(content)) > c.options.ExtractStreamBytes {
+			offenses, err := streamOffenses(c.dbRef.Issuers, id, r, c.options.KeepRaw, headers)
+			if err != nil {
+				return nil, fmt.Errorf("parsing document: %w", err)
+			}
+
+			return offenses, nil
+		}
+
+		node, err := htmlutils.AsNode(r)
+		if err != nil {
+			return nil, fmt.Errorf("parsing document: %w", err)
+		}
+
+		offenses, err := extractOffenses(c.dbRef.Issuers, id, node, c.options.KeepRaw, headers)
+		if err != nil {
+			return nil, fmt.Errorf("parsing document: %w", err)
+		}
+
+		return offenses, nil
+	})
+}
+
+// withTimeout runs fn and gives up waiting for it after d. The parser can't be
+// interrupted at any point, so on timeout ctx is canceled and fn stops at its
+// next read or row, while the worker is released to process the next
+// document. A zero duration waits forever.
+func withTimeout[T any](d time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	if d <= 0 {
+		return fn(context.Background())
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	done := make(chan result, 1)
+
+	go func() {
+		// this goroutine outlives the task of the pool, recover here too
+		v, err := concurrency.Safe(func() (T, error) { return fn(ctx) })
+		done <- result{v, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+
+		return zero, fmt.Errorf("%w: after %s", ErrExtractionTimeout, d)
+	}
+}
+
+// doneReader fails the reads once done is closed, which stops the tokenizer.
+type doneReader struct {
+	r    io.Reader
+	done <-chan struct{}
+}
+
+func (r *doneReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.done:
+		return 0, ErrExtractionTimeout
+	default:
+		return r.r.Read(p)
+	}
+}
+
 // Extracts JSON from downloaded HTML documents.
 func (c *Client) extractDocuments() error {
 	var docs []string
 
 	var err error
 
-	if c.options.ExtractFull {
+	switch {
+	case c.options.ReextractBelow > 0:
+		docs, err = c.staleDocuments()
+	case c.options.ExtractFull:
 		docs, err = c.store.ExistingDocuments()
-	} else {
+	default:
 		// get all local HTML documents
 		allDocs, err := c.store.ExistingDocuments()
 		if err != nil {
@@ -886,9 +1515,36 @@ func (c *Client) extractDocuments() error {
 			return fmt.Errorf("getting extracted documents: %w", err)
 		}
 
+		// the failures that can't be retried wait for the curators
+		failedDocs, err := c.repo.GetFailedDocuments(c.dbRef)
+		if err != nil {
+			return fmt.Errorf("getting failed documents: %w", err)
+		}
+
+		var skipped int
+
 		// find the documents that have not been extracted yet
 		for _, doc := range allDocs {
-			if _, ok := extractedDocs[doc]; !ok {
+			if _, ok := extractedDocs[doc]; ok {
+				continue
+			}
+
+			if f, ok := failedDocs[doc]; ok && f.Status != FailureRetrying && !slices.Contains(c.changed, doc) {
+				skipped++
+
+				continue
+			}
+
+			docs = append(docs, doc)
+		}
+
+		if skipped > 0 {
+			log.Printf("Skipping %d documents of %s whose extraction failed, pending review", skipped, c.dbRef.Name)
+		}
+
+		// and the ones that changed since they were extracted
+		for _, doc := range c.changed {
+			if extractedDocs[doc] {
 				docs = append(docs, doc)
 			}
 		}
@@ -898,68 +1554,80 @@ func (c *Client) extractDocuments() error {
 		return fmt.Errorf("getting documents to extract: %w", err)
 	}
 
+	if c.headerSynonyms, err = c.repo.ListHeaderSynonyms(); err != nil {
+		return fmt.Errorf("loading header synonyms: %w", err)
+	}
+
+	if c.countrySynonyms, err = c.repo.ListCountrySynonyms(); err != nil {
+		return fmt.Errorf("loading country synonyms: %w", err)
+	}
+
 	slices.Sort(docs)
 	n := len(docs)
+	started := time.Now()
 
 	maxProcs := c.options.ExtractMaxProcs
 	if maxProcs == 0 {
 		maxProcs = runtime.NumCPU()
 	}
 
-	var bar *progressbar.ProgressBar
-	if isatty.IsTerminal(os.Stderr.Fd()) {
-		bar = progressbar.NewOptions(n,
-			progressbar.OptionSetDescription("Extracting "+c.dbRef.Name),
-			progressbar.OptionSetWriter(os.Stderr),
-			progressbar.OptionShowCount(),
-			progressbar.OptionClearOnFinish(),
-		)
-	}
-
-	var wg sync.WaitGroup
+	phase := c.options.Progress.Start("Extraction "+c.dbRef.Name, n)
+	defer phase.Finish()
 
-	semaphore := make(chan struct{}, maxProcs)
-	errChan := make(chan error, n)
-	metricsChan := make(chan *ExtractMetrics, n)
+	run := ExtractionRun{DbID: c.dbRef.ID, StartedAt: started, Documents: n}
 
-	for _, id := range docs {
-		wg.Add(1)
+	diff, err := c.newReextractDiff()
+	if err != nil {
+		return err
+	}
 
-		go func(id string) {
-			defer wg.Done()
-			semaphore <- struct{}{}
+	_, err = concurrency.Run(context.Background(), maxProcs, docs,
+		func(_ context.Context, id string) (*ExtractMetrics, error) {
+			return c.extractDocument(id)
+		},
+		func(r concurrency.Result[string, *ExtractMetrics]) {
+			metrics := r.Value
+			if r.Panicked() {
+				// extractDocument didn't get to return its metrics
+				metrics = &ExtractMetrics{FailedDocs: 1, PanickedDocs: 1}
+
+				var panicErr *concurrency.PanicError
+				if errors.As(r.Err, &panicErr) {
+					phase.Logf("Extracting %s panicked: %v\n%s", r.Task, panicErr.Value, panicErr.Stack)
+				}
+			}
 
-			defer func() { <-semaphore }()
+			if r.Err != nil {
+				phase.Logf("Extraction failed - extracting %s - %s", r.Task, r.Err)
 
-			metrics, err := c.extractDocument(id)
-			if err != nil {
-				errChan <- fmt.Errorf("extracting %s - %w", id, err)
+				if !c.options.DryRun {
+					if saveErr := c.repo.SaveExtractionFailure(c.dbRef.ID, r.Task, r.Err); saveErr != nil {
+						phase.Logf("Error recording the failure of %s: %v", r.Task, saveErr)
+					}
+				}
 			}
 
 			if metrics != nil {
-				metricsChan <- metrics
-			}
+				metrics.ExtractTime = r.Duration
+				metrics.SlowestDoc, metrics.SlowestDocTime = r.Task, r.Duration
 
-			if bar == nil {
-				log.Printf("Extracting %s", id)
-			} else {
-				if err := bar.Add(1); err != nil {
-					errChan <- fmt.Errorf("updating progress bar for %s: %w", id, err)
-				}
+				c.Metrics.ExtractMetrics.Merge(metrics)
+				run.Records += metrics.NewRecords
+				run.Errors += metrics.NewErrors
 			}
-		}(id)
-	}
-
-	wg.Wait()
-	close(errChan)
-	close(metricsChan)
 
-	for err := range errChan {
-		log.Printf("Extraction failed - %s", err)
+			diff.add(r.Task, metrics, r.Err)
+			phase.Step("Extracting %s", r.Task)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("extracting documents: %w", err)
 	}
 
-	for metrics := range metricsChan {
-		c.Metrics.ExtractMetrics.Merge(metrics)
+	diff.finish(c.dbRef.Name)
+
+	if err := c.trackErrorRate(run); err != nil {
+		return err
 	}
 
 	log.Printf(
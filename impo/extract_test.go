@@ -22,19 +22,22 @@ func TestUnidadReajustables(t *testing.T) {
 		wantErr  bool
 	}{
 		{"", 0, "0", false},
-		{"1", 100, "1", false},
+		{"1", URResolution, "1", false},
 		{"0", 0, "0", false},
 		{"0,0", 0, "0", false},
 		{"0.0", 0, "0", false},
-		{"0,25", 0.25 * urResolution, "0.25", false},
-		{"0,5", 0.5 * urResolution, "0.5", false},
-		{"0.5", 0.5 * urResolution, "0.5", false},
-		{"0.50", 0.5 * urResolution, "0.5", false},
+		{"0,25", 0.25 * URResolution, "0.25", false},
+		{"0,5", 0.5 * URResolution, "0.5", false},
+		{"0.5", 0.5 * URResolution, "0.5", false},
+		{"0.50", 0.5 * URResolution, "0.5", false},
 		{"0,-1", 0, "", true},
-		{"0,9", 0.9 * urResolution, "0.9", false},
-		{"0,123", 0, "", true},
-		{"5", 5 * urResolution, "5", false},
-		{"2XPERS", 2 * urResolution, "2", false},
+		{"0,9", 0.9 * URResolution, "0.9", false},
+		{"0,05", 0.05 * URResolution, "0.05", false},
+		{"2,375", 2.375 * URResolution, "2.375", false},
+		{"0,123", 0.123 * URResolution, "0.123", false},
+		{"0,1234", 0, "", true},
+		{"5", 5 * URResolution, "5", false},
+		{"2XPERS", 2 * URResolution, "2", false},
 	}
 
 	for _, tc := range tests {
@@ -58,7 +61,7 @@ func TestUnidadReajustables(t *testing.T) {
 		})
 	}
 
-	if expected, got := "12.3", UR(12.3*urResolution).String(); expected != got {
+	if expected, got := "12.3", UR(12.3*URResolution).String(); expected != got {
 		t.Fatalf("want %v, got %v", expected, got)
 	}
 }
@@ -293,7 +296,33 @@ func TestTrafficOffensePropertyFrom(t *testing.T) {
 			want:        propHora,
 			expectedErr: false,
 		},
+		// typos one edit away
+		{
+			input:       "Matriula",
+			want:        propVehicle,
+			expectedErr: false,
+		},
+		{
+			input:       "Intersecciónn",
+			want:        propLocation,
+			expectedErr: false,
+		},
+		{
+			input:       "Detale",
+			want:        propDescription,
+			expectedErr: false,
+		},
 		// Error cases
+		{
+			input:       "UF", // too short to be a typo of UR
+			want:        0,
+			expectedErr: true,
+		},
+		{
+			input:       "Matriculaxx",
+			want:        0,
+			expectedErr: true,
+		},
 		{
 			input:       "SomethingUnknown",
 			want:        0, // Default value
@@ -340,7 +369,7 @@ func TestVisitHTML(t *testing.T) {
 				Time:        time.Date(2025, 1, 1, 0, 0, 0, 0, UruguayTimezone),
 				ID:          "IDM 0000000000",
 				Description: "Exceso de velocidad hasta 20 km/h",
-				UR:          UR(5 * urResolution),
+				UR:          UR(5 * URResolution),
 			},
 			`
 			<html>
@@ -377,7 +406,7 @@ func TestVisitHTML(t *testing.T) {
 				Time:        time.Date(2024, 12, 18, 20, 5, 0, 0, UruguayTimezone),
 				ID:          "FM14 1144",
 				Description: "15.4 No respetar señales luminosas",
-				UR:          6 * urResolution,
+				UR:          6 * URResolution,
 			},
 			`
 			<html>
@@ -411,7 +440,7 @@ func TestVisitHTML(t *testing.T) {
 				RecordID:    1,
 				Vehicle:     "ZME2015",
 				Description: "No respetar señales luminosas",
-				UR:          4 * urResolution,
+				UR:          4 * URResolution,
 				Time:        time.Date(2022, 0o5, 0o2, 0, 0, 0, 0, UruguayTimezone),
 			},
 			`
@@ -442,7 +471,7 @@ func TestVisitHTML(t *testing.T) {
 				Time:        time.Date(2025, 11, 5, 11, 48, 0, 0, UruguayTimezone),
 				ID:          "DPC 9999000604",
 				Description: "Exceso de velocidad de entre 21 km/h y 30 km/h",
-				UR:          UR(8 * urResolution),
+				UR:          UR(8 * URResolution),
 				VehicleInfo: &VehicleInfo{
 					Country: ISOUruguay,
 				},
@@ -727,7 +756,7 @@ func TestVisitOffensesTable_WithLocalidadAndHora(t *testing.T) {
 		t.Fatal("could not find tbody node")
 	}
 
-	err = visitOffensesTable(tbodyNode, &offenses, &defaultDate, "", nil)
+	err = visitOffensesTable(tbodyNode, &offenses, &defaultDate, "", nil, false, nil)
 	if err != nil {
 		t.Fatalf("visitOffensesTable returned an error: %v", err)
 	}
@@ -744,7 +773,7 @@ func TestVisitOffensesTable_WithLocalidadAndHora(t *testing.T) {
 		RecordID:    1,
 		Vehicle:     "ABE8658",
 		Description: "ADELANTAR POR LA DERECHA",
-		UR:          UR(3 * urResolution),
+		UR:          UR(3 * URResolution),
 		Time:        time.Date(2024, time.March, 31, 17, 27, 0, 0, UruguayTimezone),
 		Location:    "L.A. DE HERRERA Y LAVALLEJA, MINAS",
 	}
@@ -792,6 +821,42 @@ func TestVisitHTMLWithArt9(t *testing.T) {
 	}
 }
 
+func TestVisitHTMLWithMixedTables(t *testing.T) {
+	// the speeding table doesn't have the description, and the art. 9 of
+	// the previous one must not be taken for it
+	htmlInput := `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Montevideo N° 3907/025</title>
+		<h5>Fecha de Publicación: 10/12/2025</h5>
+		<pre>Notifícase a los propietarios de los vehículos cuyas matrículas se determinan, que se constató la contravención a lo dispuesto en el art. 9 del Texto Ordenado del Sucive.</pre>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+			<TR><TD><pre>SBF1234</pre></TD><TD><pre>10/12/2025 10:00</pre></TD></TR>
+		</table>
+		<pre>Notifícase a los propietarios de los vehículos cuyas matrículas se determinan, que los equipos de fiscalización constataron el exceso de velocidad.</pre>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD><TD><pre>Lugar</pre></TD></TR>
+			<TR><TD><pre>SBG5678</pre></TD><TD><pre>10/12/2025 11:00</pre></TD><TD><pre>AV ITALIA Y AV BOLIVIA</pre></TD></TR>
+		</table>
+	</html>
+	`
+
+	doc, err := html.Parse(strings.NewReader(htmlInput))
+	if err != nil {
+		t.Fatalf("failed to parse html: %v", err)
+	}
+
+	if _, err := ExtractDocument([]string{"intendencia de montevideo"}, "", doc); err == nil ||
+		!strings.Contains(err.Error(), "tabla sin columna descripción") {
+		t.Errorf("expected the speeding table to lack the description, got %v", err)
+	}
+
+	if _, err := streamOffenses([]string{"intendencia de montevideo"}, "", strings.NewReader(htmlInput), false, nil); err == nil ||
+		!strings.Contains(err.Error(), "tabla sin columna descripción") {
+		t.Errorf("expected the speeding table to lack the description when streaming, got %v", err)
+	}
+}
+
 func TestVisitHTMLWithMissingHeaders(t *testing.T) {
 	htmlInput := `
 	<html>
@@ -833,7 +898,415 @@ func TestVisitHTMLWithMissingHeaders(t *testing.T) {
 		t.Errorf("expected description 'Exceso de velocidad', got '%s'", offenses[0].Description)
 	}
 
-	if offenses[0].UR != 5*urResolution {
+	if offenses[0].UR != 5*URResolution {
 		t.Errorf("expected UR 5, got %v", offenses[0].UR)
 	}
 }
+
+// TestExtractDocument_RochaSalto checks the header variants these databases
+// are expected to use. The documents are made up, not samples of IMPO: the
+// layouts, plates and IDs are unverified until the first crawl, see the note
+// in dbrefs.go.
+func TestExtractDocument_RochaSalto(t *testing.T) {
+	tests := []struct {
+		db       string
+		input    string
+		docID    string
+		expected TrafficOffense
+	}{
+		{
+			db: "Rocha",
+			input: `
+			<html>
+				<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Rocha N° 12/025</title>
+				<h5>Fecha de Publicación: 03/11/2025 </h5>
+				<TABLE class="tabla_en_texto" style="width:100%;">
+				 <TR>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Matrícula</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Fecha y Hora</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Lugar</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Intervenido</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Artículo</pre></TD>
+				  <TD style="text-align:center;vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Valor en UR</pre></TD>
+				 </TR>
+				 <TR>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>CAD1234</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>25/10/2025 16:10</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Ruta 9 y Calle 25 de Agosto</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>IDR 0000001234</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Exceso de velocidad hasta 20 km/h</pre></TD>
+				  <TD style="text-align:center;vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>5</pre></TD>
+				 </TR>
+				</TABLE>
+			</html>`,
+			docID: "12/025",
+			expected: TrafficOffense{
+				RecordID:    1,
+				Vehicle:     "CAD1234",
+				Time:        time.Date(2025, 10, 25, 16, 10, 0, 0, UruguayTimezone),
+				Location:    "Ruta 9 y Calle 25 de Agosto",
+				ID:          "IDR 0000001234",
+				Description: "Exceso de velocidad hasta 20 km/h",
+				UR:          UR(5 * URResolution),
+			},
+		},
+		{
+			db: "Salto",
+			input: `
+			<html>
+				<title>Notificación Departamento de Tránsito Intendencia de Salto N° 7/025</title>
+				<h5>Fecha de Publicación: 14/10/2025 </h5>
+				<TABLE class="tabla_en_texto" style="width:100%;">
+				 <TR>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Matrícula</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Fecha</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Ubicación</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>ID</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Detalle</pre></TD>
+				  <TD style="text-align:center;vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>UR</pre></TD>
+				 </TR>
+				 <TR>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>HAB 5678</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>02/10/2025 09:05</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>Av. Batlle y Uruguay</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>5566</pre></TD>
+				  <TD style="vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>No respetar luz roja</pre></TD>
+				  <TD style="text-align:center;vertical-align:top;border-width:1px 1px 1px 1px;" ><pre>6</pre></TD>
+				 </TR>
+				</TABLE>
+			</html>`,
+			docID: "7/025",
+			expected: TrafficOffense{
+				RecordID:    1,
+				Vehicle:     "HAB5678",
+				Time:        time.Date(2025, 10, 2, 9, 5, 0, 0, UruguayTimezone),
+				Location:    "Av. Batlle y Uruguay",
+				ID:          "5566",
+				Description: "No respetar luz roja",
+				UR:          UR(6 * URResolution),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.db, func(t *testing.T) {
+			db, err := Find(tc.db)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			node, err := html.Parse(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			offenses, err := ExtractDocument(db.Issuers, "", node)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(offenses) != 1 {
+				t.Fatalf("expected 1 offense, got %d", len(offenses))
+			}
+
+			if expected, actual := tc.docID, offenses[0].DocID; expected != actual {
+				t.Errorf("docId - %q != %q", expected, actual)
+			}
+
+			if diff := cmp.Diff(&tc.expected, offenses[0], cmpopts.IgnoreFields(TrafficOffense{}, "Document")); diff != "" {
+				t.Errorf("parse output mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFineFromUnitQuantity(t *testing.T) {
+	tests := []struct {
+		valor          UR
+		unit, quantity string
+		want           UR
+		wantErr        bool
+	}{
+		{0, "UR", "5", 5 * URResolution, false},
+		{0, "U.R.", "2,5", 2.5 * URResolution, false},
+		{0, "", "3", 3 * URResolution, false},
+		{4 * URResolution, "UR", "2", 8 * URResolution, false},
+		{0, "1,5", "2", 3 * URResolution, false},
+		{0, "$", "2000", 0, true},
+		{0, "UR", "muchas", 0, true},
+	}
+
+	for _, tc := range tests {
+		got, err := fineFromUnitQuantity(tc.valor, tc.unit, tc.quantity)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("fineFromUnitQuantity(%v, %q, %q) = %v, %v", tc.valor, tc.unit, tc.quantity, got, err)
+		}
+	}
+}
+
+// Layout of https://impo.com.uy/bases/resoluciones-policia-caminera/1000-2025
+func TestExtractDocument_CamineraUnidadCantidad(t *testing.T) {
+	input := `
+	<html>
+		<title>Resolución Policía Caminera N° 1000/025</title>
+		<h5>Fecha de Publicación: 20/11/2025 </h5>
+		<TABLE class="tabla_en_texto" style="width:100%;">
+		 <TR>
+		  <TD><pre>Matrícula</pre></TD>
+		  <TD><pre>País</pre></TD>
+		  <TD><pre>Fecha y Hora</pre></TD>
+		  <TD><pre>Lugar</pre></TD>
+		  <TD><pre>Artículo</pre></TD>
+		  <TD><pre>Unidad</pre></TD>
+		  <TD><pre>Cantidad</pre></TD>
+		 </TR>
+		 <TR>
+		  <TD><pre>SBC1234</pre></TD>
+		  <TD><pre>Uruguay</pre></TD>
+		  <TD><pre>02/11/2025 10:15</pre></TD>
+		  <TD><pre>Ruta 1 km 45</pre></TD>
+		  <TD><pre>Exceso de velocidad</pre></TD>
+		  <TD><pre>UR</pre></TD>
+		  <TD><pre>8</pre></TD>
+		 </TR>
+		 <TR>
+		  <TD><pre>SBD5678</pre></TD>
+		  <TD><pre>Uruguay</pre></TD>
+		  <TD><pre>03/11/2025 11:20</pre></TD>
+		  <TD><pre>Ruta 5 km 30</pre></TD>
+		  <TD><pre>Sin cinturón</pre></TD>
+		  <TD><pre>$</pre></TD>
+		  <TD><pre>1500</pre></TD>
+		 </TR>
+		</TABLE>
+	</html>`
+
+	db, err := Find("Caminera")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offenses, err := ExtractDocument(db.Issuers, "", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(offenses) != 2 {
+		t.Fatalf("expected 2 offenses, got %d", len(offenses))
+	}
+
+	if offenses[0].Error != "" || offenses[0].UR != 8*URResolution {
+		t.Errorf("unexpected offense %+v", offenses[0])
+	}
+
+	if !strings.Contains(offenses[1].Error, ErrUnsupportedUnit.Error()) {
+		t.Errorf("expected an unsupported unit error, got %q", offenses[1].Error)
+	}
+}
+
+func TestTrafficOffenseSet_ForeignMarker(t *testing.T) {
+	var record TrafficOffense
+	if err := record.set(propVehicle, "ABC 123 (E)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Vehicle != "ABC123" || record.VehicleInfo == nil || !record.VehicleInfo.Foreign {
+		t.Errorf("expected a foreign ABC123, got %q %+v", record.Vehicle, record.VehicleInfo)
+	}
+}
+
+func TestExtractDocument_KeepRaw(t *testing.T) {
+	node, err := html.Parse(strings.NewReader(`<html>
+		<title>Notificación Centro de Gestión de Movilidad N° 1/025</title>
+		<h5>Fecha de Publicación: 17/06/2025 </h5>
+		<table class="tabla_en_texto">
+		<tr><td>Matrícula</td><td>Fecha y Hora</td><td>Lugar</td><td>Detalle</td><td>Valor UR</td></tr>
+		<tr><td>sab 5624</td><td>2/4/2025 8:37</td><td>AV ITALIA y AV BOLIVIA</td><td>Exceso</td><td>5,5</td></tr>
+		</table></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offenses, err := extractOffenses([]string{"centro de gestión de movilidad"}, "", node, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"sab 5624", "2/4/2025 8:37", "AV ITALIA y AV BOLIVIA", "Exceso", "5,5"}
+	if diff := cmp.Diff(expected, offenses[0].Raw); diff != "" {
+		t.Errorf("raw mismatch (-expected +got):\n%s", diff)
+	}
+
+	if offenses[0].Vehicle != "SAB5624" {
+		t.Errorf("expected the vehicle to be normalized, got %q", offenses[0].Vehicle)
+	}
+
+	offenses, err = ExtractDocument([]string{"centro de gestión de movilidad"}, "", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if offenses[0].Raw != nil {
+		t.Errorf("expected no raw cells by default, got %q", offenses[0].Raw)
+	}
+}
+
+func TestExtractDocument_LearnHeaders(t *testing.T) {
+	node, err := html.Parse(strings.NewReader(`<html>
+		<title>Notificación Centro de Gestión de Movilidad N° 1/025</title>
+		<h5>Fecha de Publicación: 17/06/2025 </h5>
+		<table class="tabla_en_texto">
+		<tr><td>Patente</td><td>Fecha y Hora</td><td>Lugar</td><td>Detalle</td><td>Valor UR</td><td>Observaciones</td></tr>
+		<tr><td>sab 5624</td><td>2/4/2025 8:37</td><td>AV ITALIA y AV BOLIVIA</td><td>Exceso</td><td>5,5</td><td>-</td></tr>
+		</table></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuers := []string{"centro de gestión de movilidad"}
+
+	if _, err := ExtractDocument(issuers, "", node); err == nil {
+		t.Fatal("expected unknown headers to abort the document")
+	}
+
+	headers := &headerMapper{
+		synonyms: map[string]OffenseProperty{normalize("PATENTE"): propVehicle},
+		learn:    true,
+	}
+
+	offenses, err := extractOffenses(issuers, "", node, false, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if offenses[0].Error != "" || offenses[0].Vehicle != "SAB5624" {
+		t.Errorf("expected the synonym to map the vehicle, got %q (%s)", offenses[0].Vehicle, offenses[0].Error)
+	}
+
+	if diff := cmp.Diff([]string{"Observaciones"}, offenses[0].unknownHeaders); diff != "" {
+		t.Errorf("unknown headers mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestExtractDocument_UnknownCountry(t *testing.T) {
+	node, err := html.Parse(strings.NewReader(`<html>
+		<title>Notificación Centro de Gestión de Movilidad N° 1/025</title>
+		<h5>Fecha de Publicación: 17/06/2025 </h5>
+		<table class="tabla_en_texto">
+		<tr><td>Matrícula</td><td>Fecha y Hora</td><td>Lugar</td><td>Detalle</td><td>Valor UR</td><td>País</td></tr>
+		<tr><td>sab 5624</td><td>2/4/2025 8:37</td><td>AV ITALIA y AV BOLIVIA</td><td>Exceso</td><td>5,5</td><td>Bolivia</td></tr>
+		<tr><td>sab 5625</td><td>2/4/2025 8:37</td><td>AV ITALIA y AV BOLIVIA</td><td>Exceso</td><td>5,5</td><td>Rep. Argentina</td></tr>
+		<tr><td>sab 5626</td><td>2/4/2025 8:37</td><td>AV ITALIA y AV BOLIVIA</td><td>Exceso</td><td>5,5</td><td>Bolivia</td></tr>
+		</table></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := &headerMapper{countries: map[string]string{normalize("Rep. Argentina"): ISOArgentina}}
+
+	offenses, err := extractOffenses([]string{"centro de gestión de movilidad"}, "", node, false, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(offenses) != 3 {
+		t.Fatalf("expected 3 offenses, got %d", len(offenses))
+	}
+
+	if offenses[0].ErrorCode != CodeUnknownCountry || !strings.Contains(offenses[0].Error, "Bolivia") {
+		t.Errorf("expected an unknown country error, got %q (%s)", offenses[0].Error, offenses[0].ErrorCode)
+	}
+
+	if offenses[1].Error != "" || offenses[1].VehicleInfo == nil || offenses[1].Country != ISOArgentina {
+		t.Errorf("expected the synonym to map the country, got %+v (%s)", offenses[1].VehicleInfo, offenses[1].Error)
+	}
+
+	if diff := cmp.Diff([]string{"Bolivia"}, offenses[0].unknownCountries); diff != "" {
+		t.Errorf("unknown countries mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestExtractDocument_ProcessedAt(t *testing.T) {
+	node, err := html.Parse(strings.NewReader(`<html>
+		<title>Resolución Dirección Nacional de Vialidad N° 1/025</title>
+		<h5>Fecha de Publicación: 17/06/2025 </h5>
+		<table class="tabla_en_texto">
+		<tr><td>Matrícula</td><td>Fecha Ingreso</td><td>Detalle</td><td>Valor UR</td></tr>
+		<tr><td>sab 5624</td><td>2/4/2025</td><td>Exceso</td><td>5,5</td></tr>
+		<tr><td>sab 5625</td><td>2/7/2025</td><td>Exceso</td><td>5,5</td></tr>
+		</table></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := time.Date(2025, time.June, 17, 0, 0, 0, 0, UruguayTimezone)
+	processed := time.Date(2025, time.April, 2, 0, 0, 0, 0, UruguayTimezone)
+
+	// by default the time is the date of the document, as without any date
+	offenses, err := ExtractDocument(nil, "", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o := offenses[0]; o.Error != "" || !o.Time.Equal(published) || o.ProcessedAt == nil || !o.ProcessedAt.Equal(processed) {
+		t.Errorf("got time %v and processed at %v (%s), want %v and %v", o.Time, o.ProcessedAt, o.Error, published, processed)
+	}
+
+	offenses, err = extractOffenses(nil, "", node, false, &headerMapper{timeRule: TimeFromProcessedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o := offenses[0]; o.Error != "" || !o.Time.Equal(processed) {
+		t.Errorf("got time %v (%s), want the processing date %v", o.Time, o.Error, processed)
+	}
+
+	// processed after the publication
+	if o := offenses[1]; o.ErrorCode != CodeDateFuture {
+		t.Errorf("got error %q (%s), want %s", o.Error, o.ErrorCode, CodeDateFuture)
+	}
+}
+
+func TestParseOffenseProperty(t *testing.T) {
+	for p, name := range offensePropertyNames {
+		got, err := ParseOffenseProperty(name)
+		if err != nil || got != p {
+			t.Errorf("ParseOffenseProperty(%q) = %v, %v; want %v", name, got, err, p)
+		}
+
+		if p.String() != name {
+			t.Errorf("%d.String() = %q, want %q", int(p), p.String(), name)
+		}
+	}
+
+	if _, err := ParseOffenseProperty("plate"); err == nil {
+		t.Error("expected an error for an unknown property")
+	}
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"matricula", "matricula", true},
+		{"matricla", "matricula", true},
+		{"matricula", "matricla", true},
+		{"matriculo", "matricula", true},
+		{"mtricla", "matricula", false},
+		{"fechahola", "fechayhora", false},
+		{"", "a", true},
+		{"", "ab", false},
+	}
+
+	for _, tt := range tests {
+		if got := withinOneEdit(tt.a, tt.b); got != tt.want {
+			t.Errorf("withinOneEdit(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
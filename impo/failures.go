@@ -0,0 +1,129 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FailuresSchema creates the table of the failed extractions. It's shared
+// with the curation server, that reviews the ones that can't be retried.
+const FailuresSchema = `
+	-- last failed extraction of the documents, cleared when one succeeds
+	CREATE TABLE IF NOT EXISTS document_failures (
+		doc_source VARCHAR PRIMARY KEY,
+		db_id INTEGER NOT NULL,
+		error VARCHAR NOT NULL,
+		failed_at TIMESTAMPTZ NOT NULL
+	);
+	ALTER TABLE document_failures ADD COLUMN IF NOT EXISTS error_code VARCHAR;
+	ALTER TABLE document_failures ADD COLUMN IF NOT EXISTS kind VARCHAR DEFAULT 'parse';
+	ALTER TABLE document_failures ADD COLUMN IF NOT EXISTS attempts INTEGER DEFAULT 1;
+	ALTER TABLE document_failures ADD COLUMN IF NOT EXISTS status VARCHAR DEFAULT 'pending';
+`
+
+// Kinds of extraction failures.
+const (
+	FailureTransient = "transient" // IO errors and timeouts, that the next runs retry
+	FailureParse     = "parse"     // the document can't be extracted as it is
+)
+
+// Statuses of the extraction failures.
+const (
+	FailureRetrying  = "retrying"  // retried by the next update
+	FailurePending   = "pending"   // queued for the review of the curators
+	FailureDismissed = "dismissed" // reviewed, not retried anymore
+)
+
+// MaxExtractionAttempts is how many times the extraction of a document with
+// transient failures is attempted before queueing it for review.
+const MaxExtractionAttempts = 5
+
+// ExtractionFailure is the last failed extraction of a document.
+type ExtractionFailure struct {
+	DbID      int       `json:"db_id"`
+	DocSource string    `json:"doc_source"`
+	Error     string    `json:"error"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	Kind      string    `json:"kind"`
+	Attempts  int       `json:"attempts"`
+	Status    string    `json:"status"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// transientError marks the failures that have nothing to do with the content
+// of the document, like IO or database errors.
+type transientError struct {
+	error
+}
+
+func (e transientError) Unwrap() error {
+	return e.error
+}
+
+// FailureKind tells whether an extraction error is worth retrying.
+func FailureKind(err error) string {
+	var transient transientError
+	if errors.As(err, &transient) || errors.Is(err, ErrExtractionTimeout) {
+		return FailureTransient
+	}
+
+	return FailureParse
+}
+
+func (r *sqlOffenseRepository) SaveExtractionFailure(dbID int, docSource string, cause error) error {
+	kind, status := FailureKind(cause), FailurePending
+	if kind == FailureTransient {
+		status = FailureRetrying
+	}
+
+	// the status of the failures already known depends on their attempts
+	if _, err := r.db.Exec(`
+		INSERT INTO document_failures (doc_source, db_id, error, error_code, kind, attempts, status, failed_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (doc_source) DO UPDATE SET
+			error = excluded.error,
+			error_code = excluded.error_code,
+			kind = excluded.kind,
+			attempts = document_failures.attempts + 1,
+			status = CASE
+				WHEN document_failures.status = 'dismissed' THEN 'dismissed'
+				WHEN excluded.kind = 'transient' AND document_failures.attempts + 1 < ? THEN 'retrying'
+				ELSE 'pending'
+			END,
+			failed_at = excluded.failed_at
+	`, docSource, dbID, cause.Error(), nve(string(ErrorCodeOf(cause))), kind, status, time.Now(),
+		MaxExtractionAttempts); err != nil {
+		return fmt.Errorf("saving failure of %s: %w", docSource, err)
+	}
+
+	return nil
+}
+
+func (r *sqlOffenseRepository) GetFailedDocuments(db *DbReference) (map[string]ExtractionFailure, error) {
+	rows, err := r.db.Query(`
+		SELECT doc_source, error, COALESCE(error_code, ''), kind, attempts, status, failed_at
+		FROM document_failures
+		WHERE db_id = ?
+	`, db.ID)
+	if err != nil {
+		return nil, fmt.Errorf("querying failed documents: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[string]ExtractionFailure)
+
+	for rows.Next() {
+		f := ExtractionFailure{DbID: db.ID}
+		if err := rows.Scan(&f.DocSource, &f.Error, &f.ErrorCode, &f.Kind, &f.Attempts, &f.Status, &f.FailedAt); err != nil {
+			return nil, fmt.Errorf("scanning failed document: %w", err)
+		}
+
+		ret[f.DocSource] = f
+	}
+
+	return ret, rows.Err()
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureKind(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("%w: after 1s", ErrExtractionTimeout), FailureTransient},
+		{transientError{fmt.Errorf("opening document x: %w", fs.ErrNotExist)}, FailureTransient},
+		{fmt.Errorf("wrapped: %w", transientError{errors.New("storing document: conflict")}), FailureTransient},
+		{fmt.Errorf("%w: more than 10 bytes", ErrDocumentTooLarge), FailureParse},
+		{errors.New("parsing document: boom"), FailureParse},
+	} {
+		assert.Equal(t, tc.want, FailureKind(tc.err), "%v", tc.err)
+	}
+
+	// the message is kept as is
+	assert.Equal(t, "storing document: conflict", transientError{errors.New("storing document: conflict")}.Error())
+}
+
+func TestSQLRepository_GetFailedDocuments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	dbRef, err := Find("canelones")
+	require.NoError(t, err)
+
+	timeout := fmt.Errorf("%w: after 1s", ErrExtractionTimeout)
+	for range MaxExtractionAttempts - 1 {
+		require.NoError(t, repo.SaveExtractionFailure(dbRef.ID, "a", timeout))
+	}
+
+	require.NoError(t, repo.SaveExtractionFailure(dbRef.ID, "b", errors.New("parsing document: boom")))
+
+	failed, err := repo.GetFailedDocuments(dbRef)
+	require.NoError(t, err)
+	require.Len(t, failed, 2)
+	assert.Equal(t, FailureTransient, failed["a"].Kind)
+	assert.Equal(t, MaxExtractionAttempts-1, failed["a"].Attempts)
+	assert.Equal(t, FailureRetrying, failed["a"].Status)
+	assert.Equal(t, FailureParse, failed["b"].Kind)
+	assert.Equal(t, FailurePending, failed["b"].Status)
+	assert.Equal(t, "parsing document: boom", failed["b"].Error)
+
+	// out of attempts, it's queued for review
+	require.NoError(t, repo.SaveExtractionFailure(dbRef.ID, "a", timeout))
+
+	failed, err = repo.GetFailedDocuments(dbRef)
+	require.NoError(t, err)
+	assert.Equal(t, MaxExtractionAttempts, failed["a"].Attempts)
+	assert.Equal(t, FailurePending, failed["a"].Status)
+
+	// the dismissed ones stay dismissed
+	_, err = db.Exec("UPDATE document_failures SET status = ? WHERE doc_source = 'b'", FailureDismissed)
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveExtractionFailure(dbRef.ID, "b", errors.New("parsing document: boom")))
+
+	failed, err = repo.GetFailedDocuments(dbRef)
+	require.NoError(t, err)
+	assert.Equal(t, FailureDismissed, failed["b"].Status)
+	assert.Equal(t, 2, failed["b"].Attempts)
+}
@@ -4,20 +4,59 @@
 package impo
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 )
 
 const (
 	// filename where SearchResultEntry objects are stored.
 	notificationsFile = "documents.json"
+	// filename where the time of the last completed search is stored.
+	lastSearchFile = "last-search"
+	// filename where the HTTP validators of the downloaded documents are stored.
+	validatorsFile = "validators.json"
 )
 
+// Re-published documents keep the original URL plus a letter suffix, e.g.
+// https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025_A
+// supersedes .../37-2025 (and a _B would supersede both).
+var republicationSuffix = regexp.MustCompile(`^(.+)_([A-Z])$`)
+
+// documentVersion splits a document id into the id of the original
+// publication and the re-publication suffix ("" for originals).
+func documentVersion(id string) (string, string) {
+	if m := republicationSuffix.FindStringSubmatch(id); m != nil {
+		return m[1], m[2]
+	}
+
+	return id, ""
+}
+
+// latestVersion returns which of the versions of the same document is the
+// current one.
+func latestVersion(ids []string) string {
+	var latest, latestSuffix string
+
+	for _, id := range ids {
+		if _, suffix := documentVersion(id); latest == "" || suffix > latestSuffix {
+			latest, latestSuffix = id, suffix
+		}
+	}
+
+	return latest
+}
+
 // Combines multiple closers to ensure all resources are released.
 type multiReadCloser struct {
 	io.ReadCloser
@@ -34,6 +73,7 @@ func (r *multiReadCloser) Close() error {
 
 type FileStore struct {
 	root  string
+	base  string       // root of the stores of every database
 	dbRef *DbReference // Reference to use id2file conversion
 }
 
@@ -42,6 +82,7 @@ type FileStore struct {
 func NewFileStore(root string, dbRef *DbReference) *FileStore {
 	return &FileStore{
 		root:  filepath.Join(root, fmt.Sprintf("%02d", dbRef.ID)),
+		base:  root,
 		dbRef: dbRef,
 	}
 }
@@ -83,6 +124,9 @@ func (s *FileStore) load(dbpath string) (map[string]SearchResultEntry, error) {
 
 // Upsert loads the existing map of SearchResultEntry objects from notifications.json,
 // inserts only the new entries, and returns the number of entries inserted.
+// Entries are compared by their CanonicalURL, and the ones already stored by
+// another database are skipped: databases with overlapping BaseURLs would
+// otherwise download and extract the same document twice.
 func (s *FileStore) Upsert(entries []SearchResultEntry, dryRun bool) (int, error) {
 	if err := s.dbDirMustExists(); err != nil {
 		return 0, err
@@ -96,14 +140,35 @@ func (s *FileStore) Upsert(entries []SearchResultEntry, dryRun bool) (int, error
 		return 0, err
 	}
 
+	others, err := s.otherDatabases()
+	if err != nil {
+		return 0, err
+	}
+
+	stored := make(map[string]bool, len(db))
+	for href := range db {
+		stored[CanonicalURL(href)] = true
+	}
+
 	var n int
 
 	for _, entry := range entries {
+		canonical := CanonicalURL(entry.Href)
+
 		// If the entry's key already exists, do nothing.
-		if _, ok := db[entry.Href]; !ok {
-			db[entry.Href] = entry
-			n++
+		if stored[canonical] {
+			continue
+		}
+
+		if dbID, ok := others[canonical]; ok {
+			log.Printf("Search - Skipping %s, already stored by database %d", entry.Href, dbID)
+
+			continue
 		}
+
+		db[entry.Href] = entry
+		stored[canonical] = true
+		n++
 	}
 
 	if !dryRun {
@@ -122,6 +187,121 @@ func (s *FileStore) Upsert(entries []SearchResultEntry, dryRun bool) (int, error
 	return n, nil
 }
 
+// LastSearch returns when the search phase last completed for the database,
+// or the zero time if it never did.
+func (s *FileStore) LastSearch() (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, lastSearchFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("reading last search file: %w", err)
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing last search file: %w", err)
+	}
+
+	return t, nil
+}
+
+// SetLastSearch records when the search phase completed.
+func (s *FileStore) SetLastSearch(t time.Time) error {
+	if err := s.dbDirMustExists(); err != nil {
+		return err
+	}
+
+	data := []byte(t.UTC().Format(time.RFC3339) + "\n")
+	if err := os.WriteFile(filepath.Join(s.root, lastSearchFile), data, 0o600); err != nil {
+		return fmt.Errorf("writing last search file: %w", err)
+	}
+
+	return nil
+}
+
+// Validators are the HTTP cache validators IMPO sent with a document, used to
+// revalidate it with a conditional request.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func validatorsFrom(resp *http.Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// apply adds the conditional headers to the request.
+func (v Validators) apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// LoadValidators returns the validators of the downloaded documents, keyed by
+// document ID.
+func (s *FileStore) LoadValidators() (map[string]Validators, error) {
+	ret := make(map[string]Validators)
+
+	data, err := os.ReadFile(filepath.Join(s.root, validatorsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading validators file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal validators: %w", err)
+	}
+
+	return ret, nil
+}
+
+// SaveValidators stores the validators of the downloaded documents.
+func (s *FileStore) SaveValidators(validators map[string]Validators) error {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	if err := s.dbDirMustExists(); err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(validators, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal validators: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.root, validatorsFile), output, 0o600); err != nil {
+		return fmt.Errorf("failed to write validators file: %w", err)
+	}
+
+	return nil
+}
+
+// sameDocument reports whether the stored copy of the document has the given
+// content.
+func (s *FileStore) sameDocument(id string, content []byte) (bool, error) {
+	r, err := s.GetDocument(id)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	stored, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("reading stored document %s: %w", id, err)
+	}
+
+	return bytes.Equal(stored, content), nil
+}
+
 // Converts a document ID to a filesystem path.
 func (s *FileStore) pathFor(id string, createParent bool) (string, error) {
 	if len(s.dbRef.id2file) == 0 {
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 )
 
 // for testing purposes, if your SearchResultEntry is defined differently,
@@ -144,3 +145,56 @@ func TestFileStore_Upsert(t *testing.T) {
 		}
 	})
 }
+
+func TestDocumentVersion(t *testing.T) {
+	tests := []struct {
+		id, base, suffix string
+	}{
+		{
+			"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025",
+			"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025", "",
+		},
+		{
+			"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025_A",
+			"https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025", "A",
+		},
+		{
+			"https://www.impo.com.uy/bases/resoluciones-transito-maldonado/31-2023_B",
+			"https://www.impo.com.uy/bases/resoluciones-transito-maldonado/31-2023", "B",
+		},
+		{
+			"https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025",
+			"https://www.impo.com.uy/bases/resoluciones-transito-mtop/SN20251204001-2025", "",
+		},
+	}
+
+	for _, tc := range tests {
+		base, suffix := documentVersion(tc.id)
+		if base != tc.base || suffix != tc.suffix {
+			t.Errorf("documentVersion(%q) = %q, %q; want %q, %q", tc.id, base, suffix, tc.base, tc.suffix)
+		}
+	}
+
+	if got := latestVersion([]string{"doc/1-2025_A", "doc/1-2025", "doc/1-2025_B"}); got != "doc/1-2025_B" {
+		t.Errorf("latestVersion() = %q", got)
+	}
+}
+
+func TestFileStore_LastSearch(t *testing.T) {
+	fs := NewFileStore(t.TempDir(), &DbReference{ID: 45})
+
+	last, err := fs.LastSearch()
+	if err != nil || !last.IsZero() {
+		t.Fatalf("expected no last search, got %v, %v", last, err)
+	}
+
+	when := time.Date(2025, time.March, 10, 4, 30, 0, 0, time.UTC)
+	if err := fs.SetLastSearch(when); err != nil {
+		t.Fatalf("SetLastSearch failed: %v", err)
+	}
+
+	last, err = fs.LastSearch()
+	if err != nil || !last.Equal(when) {
+		t.Errorf("expected %v, got %v, %v", when, last, err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// HeadersSchema creates the tables of the headers learned from the curators.
+// It's shared with the curation server, that maps the pending ones.
+const HeadersSchema = `
+	-- headers of the documents extracted with --learn-headers that no
+	-- property is known for
+	CREATE TABLE IF NOT EXISTS pending_headers (
+		header VARCHAR NOT NULL,
+		db_id INTEGER NOT NULL,
+		doc_source VARCHAR NOT NULL,
+		seen_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (header, doc_source)
+	);
+
+	-- headers mapped by the curators, used on top of the built-in ones
+	CREATE TABLE IF NOT EXISTS header_synonyms (
+		header VARCHAR PRIMARY KEY,
+		property VARCHAR NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+`
+
+func (r *sqlOffenseRepository) ListHeaderSynonyms() (map[string]OffenseProperty, error) {
+	rows, err := r.db.Query("SELECT header, property FROM header_synonyms")
+	if err != nil {
+		return nil, fmt.Errorf("querying header synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[string]OffenseProperty)
+
+	for rows.Next() {
+		var header, name string
+		if err := rows.Scan(&header, &name); err != nil {
+			return nil, fmt.Errorf("scanning header synonym: %w", err)
+		}
+
+		prop, err := ParseOffenseProperty(name)
+		if err != nil {
+			return nil, fmt.Errorf("header synonym %q: %w", header, err)
+		}
+
+		ret[normalize(header)] = prop
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlOffenseRepository) SavePendingHeaders(dbID int, docSource string, headers []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	now := time.Now()
+
+	for _, h := range headers {
+		if _, err := tx.Exec(`
+			INSERT INTO pending_headers (header, db_id, doc_source, seen_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (header, doc_source) DO UPDATE SET seen_at = excluded.seen_at
+		`, h, dbID, docSource, now); err != nil {
+			return fmt.Errorf("recording pending header %q: %w", h, err)
+		}
+	}
+
+	return tx.Commit()
+}
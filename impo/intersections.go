@@ -0,0 +1,159 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var intersectionSeparator = regexp.MustCompile(`(?i)\s+(?:y|esq\.?|esquina)\s+`)
+
+// ParseIntersection splits a location of the form "CALLE A Y CALLE B" into its
+// two streets.
+func ParseIntersection(location string) (string, string, bool) {
+	parts := intersectionSeparator.Split(strings.TrimSpace(location), -1)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// IntersectionKey returns the same key for the locations that name an
+// intersection with its streets in any order, e.g. "AV ITALIA Y AV BOLIVIA"
+// and "AV BOLIVIA esq. AV ITALIA" are "AV BOLIVIA Y AV ITALIA". It reports
+// false for the locations that aren't an intersection.
+func IntersectionKey(location string) (string, bool) {
+	a, b, ok := ParseIntersection(location)
+	if !ok {
+		return "", false
+	}
+
+	a = strings.ToUpper(strings.Join(strings.Fields(a), " "))
+	b = strings.ToUpper(strings.Join(strings.Fields(b), " "))
+
+	if a > b {
+		a, b = b, a
+	}
+
+	return a + " Y " + b, true
+}
+
+// mirroredKey is the key of the judgments of an intersection of a database.
+func mirroredKey(dbID int, location string) (locationKey, bool) {
+	key, ok := IntersectionKey(CleanLocation(dbID, location))
+
+	return locationKey{DbID: dbID, Location: key}, ok
+}
+
+// backfillMirroredIntersections moves the offenses of the intersections
+// without a judgment to the judged location with the same streets in another
+// order, keeping the location as written in display_location.
+func (r *sqlOffenseRepository) backfillMirroredIntersections() (int64, error) {
+	return r.moveUnjudgedLocations(mirroredKey)
+}
+
+// moveUnjudgedLocations moves the offenses of the locations without a judgment
+// to the judged location with the same key, keeping the location as written
+// in display_location. key reports false for the locations it doesn't apply to.
+func (r *sqlOffenseRepository) moveUnjudgedLocations(key func(dbID int, location string) (locationKey, bool)) (int64, error) {
+	judged := make(map[locationKey]string)
+
+	// the merged judgments first, so their canonical location wins
+	rows, err := r.db.Query(`
+		SELECT db_id, location
+		FROM locations
+		ORDER BY canonical_location IS NULL, location
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("querying judgments: %w", err)
+	}
+
+	if err := scanLocations(rows, func(dbID int, location string) {
+		if k, ok := key(dbID, location); ok {
+			if _, seen := judged[k]; !seen {
+				judged[k] = location
+			}
+		}
+	}); err != nil {
+		return 0, err
+	}
+
+	if len(judged) == 0 {
+		return 0, nil
+	}
+
+	rows, err = r.db.Query(`
+		SELECT DISTINCT o.db_id, o.location
+		FROM offenses o
+		LEFT JOIN locations lj ON o.db_id = lj.db_id AND o.location = lj.location
+		WHERE o.location IS NOT NULL AND o.location != '' AND lj.id IS NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("querying pending locations: %w", err)
+	}
+
+	moves := make(map[locationKey]string)
+
+	if err := scanLocations(rows, func(dbID int, location string) {
+		if k, ok := key(dbID, location); ok {
+			if to, ok := judged[k]; ok {
+				moves[locationKey{DbID: dbID, Location: location}] = to
+			}
+		}
+	}); err != nil {
+		return 0, err
+	}
+
+	var n int64
+
+	for from, to := range moves {
+		res, err := r.db.Exec(`
+			UPDATE offenses
+			SET
+				display_location = COALESCE(display_location, location),
+				location = ?
+			WHERE db_id = ? AND location = ?
+		`, to, from.DbID, from.Location)
+		if err != nil {
+			return n, fmt.Errorf("moving location %s: %w", from.Location, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return n, fmt.Errorf("getting rows affected: %w", err)
+		}
+
+		n += affected
+	}
+
+	return n, nil
+}
+
+// scanLocations calls fn with each db_id and location of rows, closing them.
+func scanLocations(rows *sql.Rows, fn func(dbID int, location string)) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			dbID     int
+			location string
+		)
+
+		if err := rows.Scan(&dbID, &location); err != nil {
+			return fmt.Errorf("scanning location: %w", err)
+		}
+
+		fn(dbID, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating locations: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+func TestIntersectionKey(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+		ok       bool
+	}{
+		{"AV ITALIA Y AV BOLIVIA", "AV BOLIVIA Y AV ITALIA", true},
+		{"AV BOLIVIA Y AV ITALIA", "AV BOLIVIA Y AV ITALIA", true},
+		{"av bolivia  esq. av italia", "AV BOLIVIA Y AV ITALIA", true},
+		{"18 DE JULIO 1234", "", false},
+		{"RUTA 1 Y KM 25 Y PEAJE", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := IntersectionKey(tt.location)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("IntersectionKey(%q) = %q, %v, want %q, %v", tt.location, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEnrichMirroredIntersection(t *testing.T) {
+	p := spatial.Point{Lat: -34.89, Lng: -56.07}
+	repo := &sqlOffenseRepository{
+		locationCache: map[locationKey]locationData{
+			{DbID: 45, Location: "AV BOLIVIA Y AV ITALIA"}: {Point: p},
+		},
+		intersectionCache: map[locationKey]locationData{
+			{DbID: 45, Location: "AV BOLIVIA Y AV ITALIA"}: {Point: p, CanonicalLocation: "AV BOLIVIA Y AV ITALIA"},
+		},
+	}
+
+	o := &TrafficOffense{DbID: 45, Location: "AV ITALIA Y AV BOLIVIA"}
+	repo.enrichOffense(o)
+
+	if o.Point == nil || o.Location != "AV BOLIVIA Y AV ITALIA" || o.DisplayLocation != "AV ITALIA Y AV BOLIVIA" {
+		t.Errorf("unexpected %+v", o)
+	}
+
+	// the judged one keeps its location
+	o = &TrafficOffense{DbID: 45, Location: "AV BOLIVIA Y AV ITALIA"}
+	repo.enrichOffense(o)
+
+	if o.Point == nil || o.DisplayLocation != "" {
+		t.Errorf("unexpected %+v", o)
+	}
+
+	// another database
+	o = &TrafficOffense{DbID: 46, Location: "AV ITALIA Y AV BOLIVIA"}
+	repo.enrichOffense(o)
+
+	if o.Point != nil || o.Location != "AV ITALIA Y AV BOLIVIA" {
+		t.Errorf("unexpected %+v", o)
+	}
+}
+
+func TestBackfillMirroredIntersections(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE locations (id INTEGER, db_id INTEGER, location VARCHAR, canonical_location VARCHAR);
+		INSERT INTO locations VALUES
+			(1, 45, 'AV BOLIVIA Y AV ITALIA', NULL),
+			(2, 45, 'CAPURRO Y AV AGRACIADA', 'AGRACIADA Y CAPURRO'),
+			(3, 45, 'AGRACIADA Y CAPURRO', NULL);
+		CREATE TABLE offenses (db_id INTEGER, location VARCHAR, display_location VARCHAR);
+		INSERT INTO offenses VALUES
+			(45, 'AV ITALIA Y AV BOLIVIA', NULL),
+			(45, 'AV ITALIA esq AV BOLIVIA', 'AV ITALIA ESQ. AV BOLIVIA'),
+			(45, 'AV BOLIVIA Y AV ITALIA', NULL),
+			(45, 'AV AGRACIADA Y CAPURRO', NULL),
+			(46, 'AV ITALIA Y AV BOLIVIA', NULL);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &sqlOffenseRepository{db: db}
+
+	n, err := repo.backfillMirroredIntersections()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Errorf("expected 3 offenses moved, got %d", n)
+	}
+
+	rows, err := db.Query(`
+		SELECT db_id, location, COALESCE(display_location, '')
+		FROM offenses
+		ORDER BY ALL
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+
+	for rows.Next() {
+		var (
+			dbID              int
+			location, display string
+		)
+
+		if err := rows.Scan(&dbID, &location, &display); err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, strings.Join([]string{location, display}, " | "))
+	}
+
+	want := []string{
+		"AV BOLIVIA Y AV ITALIA | ",
+		"AV BOLIVIA Y AV ITALIA | AV ITALIA ESQ. AV BOLIVIA",
+		"AV BOLIVIA Y AV ITALIA | AV ITALIA Y AV BOLIVIA",
+		// the merged judgment, BackfillGeocodingData then applies its
+		// canonical location
+		"CAPURRO Y AV AGRACIADA | AV AGRACIADA Y CAPURRO",
+		// another database
+		"AV ITALIA Y AV BOLIVIA | ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// defaultIssuerAliases are the names each database's issuer has been seen
+// with in the document titles.
+//
+//go:embed issuers.json
+var defaultIssuerAliases []byte
+
+// IssuerAliases are the names the issuer of a database uses in the titles of
+// its documents, e.g. "Dirección General de Tránsito y Transporte Intendencia
+// de Maldonado" and later "Departamento de Movilidad Intendencia de Maldonado".
+type IssuerAliases struct {
+	DbID    int      `json:"db_id"`
+	Name    string   `json:"name,omitempty"` // only for humans reading the file
+	Aliases []string `json:"aliases"`
+}
+
+// foldIssuerText lowercases s, removes its diacritics and squashes its
+// spaces, so that titles match aliases despite minor edits.
+func foldIssuerText(s string) string {
+	s, _, _ = transform.String(
+		transform.Chain(
+			norm.NFD,
+			runes.Remove(runes.In(unicode.Mn)),
+			norm.NFC,
+		),
+		s,
+	)
+
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// matchIssuer looks for any of the aliases in the title, both folded,
+// returning what follows the first one found.
+func matchIssuer(title string, aliases []string) (string, bool) {
+	title = foldIssuerText(title)
+
+	for _, alias := range aliases {
+		if alias = foldIssuerText(alias); alias == "" {
+			continue
+		}
+
+		if idx := strings.Index(title, alias); idx > -1 {
+			return strings.TrimSpace(title[idx+len(alias):]), true
+		}
+	}
+
+	return "", false
+}
+
+func addIssuerAliases(dbs []DbReference, r io.Reader) error {
+	var entries []IssuerAliases
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("decoding issuer aliases: %w", err)
+	}
+
+	for _, e := range entries {
+		i := dbIndex(dbs, e.DbID)
+		if i < 0 {
+			return fmt.Errorf("issuer aliases: %w: %d", errDatabaseNotFound, e.DbID)
+		}
+
+		for _, alias := range e.Aliases {
+			if alias = foldIssuerText(alias); alias != "" && !slices.Contains(dbs[i].Issuers, alias) {
+				dbs[i].Issuers = append(dbs[i].Issuers, alias)
+			}
+		}
+	}
+
+	return nil
+}
+
+func dbIndex(dbs []DbReference, id int) int {
+	for i := range dbs {
+		if dbs[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// LoadIssuerAliases adds the aliases of a JSON file, with the format of
+// issuers.json, to the ones built in. It must be called before creating the
+// clients.
+func LoadIssuerAliases(path string) error {
+	f, err := os.Open(path) // #nosec G304 - path comes from the command line
+	if err != nil {
+		return fmt.Errorf("opening issuer aliases: %w", err)
+	}
+	defer f.Close()
+
+	return addIssuerAliases(databases, f)
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMatchIssuer(t *testing.T) {
+	aliases := []string{"departamento de movilidad intendencia de maldonado"}
+
+	rest, ok := matchIssuer("Notificación  Departamento de MOVILIDAD Intendencia de Maldonado N° 12/025", aliases)
+	if !ok || rest != "n° 12/025" {
+		t.Errorf("expected a match, got %q %v", rest, ok)
+	}
+
+	// accents are ignored on both sides
+	rest, ok = matchIssuer("Notificación Dirección de Transito Intendencia de Rio Negro N° 3/024",
+		[]string{"Dirección de Tránsito Intendencia de Río Negro"})
+	if !ok || rest != "n° 3/024" {
+		t.Errorf("expected a match, got %q %v", rest, ok)
+	}
+
+	if _, ok := matchIssuer("Notificación Tránsito Intendencia de Maldonado N° 1/025", aliases); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestAddIssuerAliases(t *testing.T) {
+	dbs := []DbReference{{ID: 45, Issuers: []string{"departamento de movilidad intendencia de maldonado"}}}
+
+	err := addIssuerAliases(dbs, strings.NewReader(`[
+		{"db_id": 45, "aliases": ["Departamento de Movilidad Intendencia de Maldonado", "Unidad de Tránsito"]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"departamento de movilidad intendencia de maldonado", "unidad de transito"}
+	if strings.Join(dbs[0].Issuers, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, dbs[0].Issuers)
+	}
+
+	err = addIssuerAliases(dbs, strings.NewReader(`[{"db_id": 1, "aliases": ["x"]}]`))
+	if !errors.Is(err, errDatabaseNotFound) {
+		t.Errorf("expected errDatabaseNotFound, got %v", err)
+	}
+}
+
+func TestDefaultIssuerAliases(t *testing.T) {
+	if err := Each(func(db DbReference) error {
+		if len(db.Issuers) == 0 {
+			t.Errorf("database %s has no issuer aliases", db.Name)
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractDocument_UnmatchedIssuer(t *testing.T) {
+	dbRef, err := Find("maldonado")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "https://www.impo.com.uy/bases/notificaciones-transito-maldonado/1-2025"
+	c := NewImpoClient(&ClientOptions{DbPath: t.TempDir(), DryRun: true}, dbRef, nil)
+
+	html := `<html><title>Notificación Unidad Nueva Intendencia de Maldonado N° 1/025</title>
+	<table class="tabla_en_texto">
+	<tr><td>Matrícula</td><td>Fecha</td><td>Lugar</td><td>Detalle</td><td>UR</td></tr>
+	<tr><td>BAA1234</td><td>01/01/2025</td><td>Ruta 10</td><td>Exceso de velocidad</td><td>5</td></tr>
+	</table></html>`
+	if err := c.store.SaveDocument(id, strings.NewReader(html)); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics, err := c.extractDocument(id)
+	if err != nil {
+		t.Fatalf("expected the ID to come from the URL, got %v", err)
+	}
+
+	expected := UnmatchedIssuer{DocSource: id, Title: "Notificación Unidad Nueva Intendencia de Maldonado N° 1/025"}
+	if metrics.SuccessfulDocs != 1 || len(metrics.UnmatchedIssuers) != 1 || metrics.UnmatchedIssuers[0] != expected {
+		t.Errorf("expected a successful document with an unmatched issuer, got %+v", metrics)
+	}
+}
+
+func TestDocIDFromURL(t *testing.T) {
+	tests := []struct {
+		db, url, docID string
+		year           int
+	}{
+		{"montevideo", "https://www.impo.com.uy/bases/notificaciones-cgm/2933-2024", "2933/024", 2024},
+		{"tacuar", "https://www.impo.com.uy/bases/notificaciones-transito-tacuarembo/37-2025_A", "37/025", 2025},
+		{"lavalleja", "https://www.impo.com.uy/bases/notificaciones-transito-lavalleja/SN20210707001-2021", "sn20210707001/021", 2021},
+	}
+
+	for _, test := range tests {
+		dbRef, err := Find(test.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		docID, year, err := dbRef.docIDFromURL(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if docID != test.docID || year != test.year {
+			t.Errorf("%s: expected %q %d, got %q %d", test.url, test.docID, test.year, docID, year)
+		}
+	}
+
+	dbRef, err := Find("montevideo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := dbRef.docIDFromURL("https://www.impo.com.uy/bases/otra-cosa/1-2024"); err == nil {
+		t.Error("expected an error for an unknown URL")
+	}
+}
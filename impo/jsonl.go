@@ -0,0 +1,139 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// jsonLinesRepository is an OffenseRepository that writes each offense as a
+// JSON line (JSONL) instead of storing it. It keeps no state, so every
+// document is considered pending and no enrichment takes place.
+type jsonLinesRepository struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// NewJSONLinesRepository returns a repository that streams offenses to w, one
+// JSON object per line. Documents are written atomically: lines from different
+// documents are never interleaved.
+func NewJSONLinesRepository(w io.Writer) OffenseRepository {
+	return &jsonLinesRepository{w: bufio.NewWriter(w)}
+}
+
+func (r *jsonLinesRepository) LoadCaches() error {
+	return nil
+}
+
+func (r *jsonLinesRepository) CreateSchema() error {
+	return nil
+}
+
+func (r *jsonLinesRepository) SaveTrafficOffenses(offenses []*TrafficOffense) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enc := json.NewEncoder(r.w)
+	for _, o := range offenses {
+		if err := enc.Encode(o); err != nil {
+			return fmt.Errorf("encoding offense %d: %w", o.RecordID, err)
+		}
+	}
+
+	// flush per document so consumers see progress while extracting
+	return r.w.Flush()
+}
+
+func (r *jsonLinesRepository) GetExtractedDocuments(_ *DbReference) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (r *jsonLinesRepository) GetStaleDocuments(_ *DbReference, _ int) ([]string, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) ListHeaderSynonyms() (map[string]OffenseProperty, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) SavePendingHeaders(_ int, _ string, _ []string) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) ListCountrySynonyms() (map[string]string, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) SavePendingValues(_ int, _ string, _ string, _ []string) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) SaveExtractionRun(_ ExtractionRun) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) ListExtractionRuns(_ int, _ int) ([]ExtractionRun, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) SaveLinkChecks(_ []LinkCheck) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) ListMissingDocuments(_ int) ([]LinkCheck, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) SaveMeta(_ map[string]string) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) LinkRepublishedDocuments() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) BackfillAppealDeadlines() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) BackfillErrorCodes() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) SaveExtractionFailure(_ int, _ string, _ error) error {
+	return nil
+}
+
+func (r *jsonLinesRepository) GetFailedDocuments(_ *DbReference) (map[string]ExtractionFailure, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) ListPublicationDates(_ int, _ int) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) ListDocumentSummaries(_ int) (map[string]DocumentSummary, error) {
+	return nil, nil
+}
+
+func (r *jsonLinesRepository) BackfillGeocodingData() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) BackfillVehicleOverrides() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) BackfillStreetAliases() (int64, error) {
+	return 0, nil
+}
+
+func (r *jsonLinesRepository) BackportDescriptionArticles() (int64, error) {
+	return 0, nil
+}
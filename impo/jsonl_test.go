@@ -0,0 +1,45 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLinesRepository_SaveTrafficOffenses(t *testing.T) {
+	var buf bytes.Buffer
+
+	repo := NewJSONLinesRepository(&buf)
+	doc := &Document{DocSource: "doc1", DocID: "1/025"}
+
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{
+		{Document: doc, DbID: 45, RecordID: 1, Vehicle: "ABC1234", UR: 500},
+		{Document: doc, DbID: 45, RecordID: 2, Vehicle: "BCD2345", Error: "boom"},
+	}))
+
+	docs, err := repo.GetExtractedDocuments(&DbReference{ID: 45})
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+
+	var lines []map[string]any
+
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+
+		lines = append(lines, m)
+	}
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, "ABC1234", lines[0]["vehicle"])
+	assert.Equal(t, "1/025", lines[0]["doc_id"])
+	assert.Equal(t, "boom", lines[1]["error"])
+}
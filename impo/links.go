@@ -0,0 +1,247 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// DocStatusSchema creates the table with the last check of the links of the
+// documents.
+const DocStatusSchema = `
+	-- last time the link of every document was checked against IMPO
+	CREATE TABLE IF NOT EXISTS doc_status (
+		doc_source VARCHAR PRIMARY KEY,
+		db_id INTEGER NOT NULL,
+		status VARCHAR NOT NULL,
+		http_status INTEGER,
+		location VARCHAR,
+		error VARCHAR,
+		checked_at TIMESTAMPTZ NOT NULL,
+		missing_since TIMESTAMPTZ
+	);
+`
+
+// Statuses of the links of the documents.
+const (
+	LinkOK    = "ok"
+	LinkMoved = "moved" // IMPO redirects it somewhere else
+	LinkGone  = "gone"  // 404 or 410: the document was removed
+	LinkError = "error" // the check failed, nothing is known
+)
+
+// LinkCheck is the result of checking that a document is still published.
+type LinkCheck struct {
+	DbID       int       `json:"db_id"`
+	DocSource  string    `json:"doc_source"`
+	Status     string    `json:"status"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	Location   string    `json:"location,omitempty"` // of the redirect
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+	// MissingSince is the first check that didn't find the document, kept
+	// while it stays missing.
+	MissingSince *time.Time `json:"missing_since,omitempty"`
+}
+
+// Missing tells whether the document is no longer where it was published.
+func (c *LinkCheck) Missing() bool {
+	return c.Status == LinkMoved || c.Status == LinkGone
+}
+
+// LinkMetrics counts the links checked by VerifyLinks.
+type LinkMetrics struct {
+	LinksOK    int
+	LinksMoved int
+	LinksGone  int
+	LinksErr   int
+}
+
+// Merge adds the counts of another run.
+func (m *LinkMetrics) Merge(o *LinkMetrics) *LinkMetrics {
+	m.LinksOK += o.LinksOK
+	m.LinksMoved += o.LinksMoved
+	m.LinksGone += o.LinksGone
+	m.LinksErr += o.LinksErr
+
+	return m
+}
+
+// linkStatus classifies the response to the HEAD of a document.
+func linkStatus(code int) string {
+	switch {
+	case code >= 200 && code < 300:
+		return LinkOK
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return LinkGone
+	case code >= 300 && code < 400:
+		return LinkMoved
+	default:
+		return LinkError
+	}
+}
+
+// checkLink sends a HEAD for the document, without following redirects.
+func (c *Client) checkLink(id string) LinkCheck {
+	check := LinkCheck{DbID: c.dbRef.ID, DocSource: id, CheckedAt: time.Now()}
+
+	resp, err := c.client.Head(id)
+	if err != nil {
+		check.Status, check.Error = LinkError, err.Error()
+
+		return check
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("closing request %s: %v", id, err)
+	}
+
+	check.HTTPStatus = resp.StatusCode
+	check.Status = linkStatus(resp.StatusCode)
+
+	if check.Status == LinkMoved {
+		check.Location = resp.Header.Get("Location")
+	} else if check.Status == LinkError {
+		check.Error = resp.Status
+	}
+
+	return check
+}
+
+// VerifyLinks checks that every document found by the search is still
+// published, recording the result in doc_status, and returns the checks of
+// the documents that are missing from IMPO.
+func (c *Client) VerifyLinks() ([]LinkCheck, error) {
+	log.Printf("Verifying links of database %d - %s", c.dbRef.ID, c.dbRef.Name)
+
+	if !c.options.CrawlWindow.Contains(time.Now()) {
+		log.Printf("Outside the crawl window %s, skipping", c.options.CrawlWindow)
+
+		return nil, nil
+	}
+
+	entries, err := c.store.load(c.store.dbpath())
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	checks := make([]LinkCheck, 0, len(ids))
+
+	for i, id := range ids {
+		check := c.checkLink(id)
+
+		switch check.Status {
+		case LinkOK:
+			c.Metrics.LinksOK++
+		case LinkMoved:
+			c.Metrics.LinksMoved++
+			log.Printf("[%d/%d] %s moved to %s", i+1, len(ids), id, check.Location)
+		case LinkGone:
+			c.Metrics.LinksGone++
+			log.Printf("[%d/%d] %s is gone (%d)", i+1, len(ids), id, check.HTTPStatus)
+		default:
+			c.Metrics.LinksErr++
+			log.Printf("[%d/%d] checking %s failed: %s", i+1, len(ids), id, check.Error)
+		}
+
+		checks = append(checks, check)
+	}
+
+	if c.options.DryRun {
+		return slices.DeleteFunc(checks, func(c LinkCheck) bool { return !c.Missing() }), nil
+	}
+
+	if err := c.repo.SaveLinkChecks(checks); err != nil {
+		return nil, err
+	}
+
+	return c.repo.ListMissingDocuments(c.dbRef.ID)
+}
+
+func (r *sqlOffenseRepository) SaveLinkChecks(checks []LinkCheck) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+			err = errors.Join(err, rerr)
+		}
+	}()
+
+	// a failed check says nothing of the document: it keeps its status
+	for _, c := range checks {
+		var httpStatus, missingSince any
+		if c.HTTPStatus != 0 {
+			httpStatus = c.HTTPStatus
+		}
+
+		if c.Missing() {
+			missingSince = c.CheckedAt
+		}
+
+		if _, err := tx.Exec(`
+			INSERT INTO doc_status (doc_source, db_id, status, http_status, location, error, checked_at, missing_since)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (doc_source) DO UPDATE SET
+				status = CASE WHEN excluded.status = 'error' THEN doc_status.status ELSE excluded.status END,
+				http_status = excluded.http_status,
+				location = excluded.location,
+				error = excluded.error,
+				checked_at = excluded.checked_at,
+				missing_since = CASE
+					WHEN excluded.status = 'error' THEN doc_status.missing_since
+					WHEN excluded.status = 'ok' THEN NULL
+					ELSE COALESCE(doc_status.missing_since, excluded.missing_since)
+				END
+		`, c.DocSource, c.DbID, c.Status, httpStatus, nve(c.Location), nve(c.Error), c.CheckedAt,
+			missingSince); err != nil {
+			return fmt.Errorf("saving status of %s: %w", c.DocSource, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlOffenseRepository) ListMissingDocuments(dbID int) ([]LinkCheck, error) {
+	rows, err := r.db.Query(`
+		SELECT doc_source, status, COALESCE(http_status, 0), COALESCE(location, ''), COALESCE(error, ''),
+			checked_at, missing_since
+		FROM doc_status
+		WHERE db_id = ? AND status IN ('moved', 'gone')
+		ORDER BY missing_since, doc_source
+	`, dbID)
+	if err != nil {
+		return nil, fmt.Errorf("querying missing documents: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []LinkCheck
+
+	for rows.Next() {
+		c := LinkCheck{DbID: dbID}
+		if err := rows.Scan(&c.DocSource, &c.Status, &c.HTTPStatus, &c.Location, &c.Error,
+			&c.CheckedAt, &c.MissingSince); err != nil {
+			return nil, fmt.Errorf("scanning missing document: %w", err)
+		}
+
+		ret = append(ret, c)
+	}
+
+	return ret, rows.Err()
+}
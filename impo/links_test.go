@@ -0,0 +1,100 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkStatus(t *testing.T) {
+	for code, want := range map[int]string{
+		http.StatusOK:                  LinkOK,
+		http.StatusMovedPermanently:    LinkMoved,
+		http.StatusFound:               LinkMoved,
+		http.StatusNotFound:            LinkGone,
+		http.StatusGone:                LinkGone,
+		http.StatusInternalServerError: LinkError,
+		http.StatusForbidden:           LinkError,
+	} {
+		assert.Equal(t, want, linkStatus(code), "%d", code)
+	}
+}
+
+func TestVerifyLinks(t *testing.T) {
+	gone := false
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+
+		switch r.URL.Path {
+		case "/bases/notificaciones-transito-canelones/1-2025":
+			if gone {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+		case "/bases/notificaciones-transito-canelones/2-2025":
+			http.Redirect(w, r, "/bases/otro/2-2025", http.StatusMovedPermanently)
+
+			return
+		case "/bases/notificaciones-transito-canelones/3-2025":
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, err := NewSQLOffenseRepository(db)
+	require.NoError(t, err)
+
+	dbRef, err := Find("canelones")
+	require.NoError(t, err)
+
+	base := srv.URL + "/bases/notificaciones-transito-canelones/"
+	c := NewImpoClient(&ClientOptions{DbPath: t.TempDir()}, dbRef, repo)
+	_, err = c.store.Upsert([]SearchResultEntry{
+		{Href: base + "1-2025", Title: "1/025"},
+		{Href: base + "2-2025", Title: "2/025"},
+		{Href: base + "3-2025", Title: "3/025"},
+	}, false)
+	require.NoError(t, err)
+
+	missing, err := c.VerifyLinks()
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	assert.Equal(t, LinkMoved, missing[0].Status)
+	assert.Equal(t, "/bases/otro/2-2025", missing[0].Location)
+	require.NotNil(t, missing[0].MissingSince)
+	assert.Equal(t, LinkMetrics{LinksOK: 1, LinksMoved: 1, LinksErr: 1}, c.Metrics.LinkMetrics)
+
+	firstMissing := *missing[0].MissingSince
+
+	// the first document disappears, the moved one keeps when it went missing
+	gone = true
+
+	missing, err = c.VerifyLinks()
+	require.NoError(t, err)
+	require.Len(t, missing, 2)
+	assert.Equal(t, base+"2-2025", missing[0].DocSource)
+	assert.True(t, firstMissing.Equal(*missing[0].MissingSince))
+	assert.Equal(t, base+"1-2025", missing[1].DocSource)
+	assert.Equal(t, LinkGone, missing[1].Status)
+	assert.Equal(t, http.StatusNotFound, missing[1].HTTPStatus)
+
+	// a failed check keeps the known status
+	var status string
+	require.NoError(t, db.QueryRow("SELECT status FROM doc_status WHERE doc_source = ?", base+"3-2025").Scan(&status))
+	assert.Equal(t, LinkError, status)
+}
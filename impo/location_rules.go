@@ -0,0 +1,114 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// defaultLocationRules are the cleanups of the locations each database writes
+// in its own way.
+//
+//go:embed location_rules.json
+var defaultLocationRules []byte
+
+// LocationRule replaces the matches of a regular expression in the locations
+// of a database before geocoding them.
+type LocationRule struct {
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace"`
+	Note    string `json:"note,omitempty"` // only for humans reading the file
+	re      *regexp.Regexp
+}
+
+// LocationRules are the cleanup rules of a database, applied in order.
+type LocationRules struct {
+	DbID  int            `json:"db_id"`
+	Name  string         `json:"name,omitempty"` // only for humans reading the file
+	Rules []LocationRule `json:"rules"`
+}
+
+func addLocationRules(dbs []DbReference, r io.Reader) error {
+	var entries []LocationRules
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("decoding location rules: %w", err)
+	}
+
+	for _, e := range entries {
+		i := dbIndex(dbs, e.DbID)
+		if i < 0 {
+			return fmt.Errorf("location rules: %w: %d", errDatabaseNotFound, e.DbID)
+		}
+
+		for _, rule := range e.Rules {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("location rules of %s: %w", dbs[i].Name, err)
+			}
+
+			rule.re = re
+			dbs[i].LocationRules = append(dbs[i].LocationRules, rule)
+		}
+	}
+
+	return nil
+}
+
+// LoadLocationRules adds the rules of a JSON file, with the format of
+// location_rules.json, after the ones built in. It must be called before
+// geocoding or enriching the offenses.
+func LoadLocationRules(path string) error {
+	f, err := os.Open(path) // #nosec G304 - path comes from the command line
+	if err != nil {
+		return fmt.Errorf("opening location rules: %w", err)
+	}
+	defer f.Close()
+
+	return addLocationRules(databases, f)
+}
+
+// CleanLocation applies the location rules of the database to a location as
+// written in the documents, e.g. Tacuarembó's "18 DE JULIO FRENTE AL N° 250"
+// becomes "18 DE JULIO 250". Locations without rules are returned unchanged.
+func (d *DbReference) CleanLocation(location string) string {
+	if len(d.LocationRules) == 0 {
+		return location
+	}
+
+	for _, rule := range d.LocationRules {
+		location = rule.re.ReplaceAllString(location, rule.Replace)
+	}
+
+	return strings.Join(strings.Fields(location), " ")
+}
+
+// CleanLocation applies the location rules of the database with the given ID,
+// for the callers that only know the ID of the database.
+func CleanLocation(dbID int, location string) string {
+	if i := dbIndex(databases, dbID); i >= 0 {
+		return databases[i].CleanLocation(location)
+	}
+
+	return location
+}
+
+// cleanedKey is the key of the judgments of a location of a database as the
+// location rules leave it.
+func cleanedKey(dbID int, location string) (locationKey, bool) {
+	return locationKey{DbID: dbID, Location: CleanLocation(dbID, location)}, true
+}
+
+// backfillCleanedLocations moves the offenses of the locations without a
+// judgment to the judged location they become after the location rules, as
+// enrichOffense finds them, keeping the location as written in
+// display_location.
+func (r *sqlOffenseRepository) backfillCleanedLocations() (int64, error) {
+	return r.moveUnjudgedLocations(cleanedKey)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCleanLocation(t *testing.T) {
+	tests := []struct {
+		db       string
+		location string
+		want     string
+	}{
+		{"Tacuarembó", "18 DE JULIO FRENTE AL N° 250", "18 DE JULIO 250"},
+		{"Tacuarembó", "18 de Julio frente al n° 250", "18 de Julio 250"},
+		{"Tacuarembó", "18 DE JULIO Y SARANDI", "18 DE JULIO Y SARANDI"},
+		{"Montevideo", "18 DE JULIO FRENTE AL N° 250", "18 DE JULIO FRENTE AL N° 250"},
+		{"Maldonado", "GORLERO  Y 20", "GORLERO  Y 20"}, // without rules it's untouched
+	}
+
+	for _, tt := range tests {
+		db, err := Find(tt.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := db.CleanLocation(tt.location); got != tt.want {
+			t.Errorf("%s: CleanLocation(%q) = %q, want %q", tt.db, tt.location, got, tt.want)
+		}
+
+		if got := CleanLocation(db.ID, tt.location); got != tt.want {
+			t.Errorf("%s: CleanLocation(%d, %q) = %q, want %q", tt.db, db.ID, tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestAddLocationRules(t *testing.T) {
+	dbs := []DbReference{{ID: 26, Name: "Lavalleja"}}
+
+	err := addLocationRules(dbs, strings.NewReader(`[{"db_id": 26, "rules": [
+		{"pattern": "(?i)^RUTA\\s+N[°º]?\\s*", "replace": "RUTA "},
+		{"pattern": "(?i)\\s+KM\\.?\\s*", "replace": " KM "}
+	]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := dbs[0].CleanLocation("RUTA Nº8 KM.112"); got != "RUTA 8 KM 112" {
+		t.Errorf("unexpected %q", got)
+	}
+
+	err = addLocationRules(dbs, strings.NewReader(`[{"db_id": 26, "rules": [{"pattern": "("}]}]`))
+	if err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+
+	err = addLocationRules(dbs, strings.NewReader(`[{"db_id": 1, "rules": []}]`))
+	if !errors.Is(err, errDatabaseNotFound) {
+		t.Errorf("expected errDatabaseNotFound, got %v", err)
+	}
+}
+
+func TestBackfillCleanedLocations(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE locations (id INTEGER, db_id INTEGER, location VARCHAR, canonical_location VARCHAR);
+		INSERT INTO locations VALUES
+			(1, 56, '18 DE JULIO 250', NULL),
+			(2, 56, 'SARANDI FRENTE AL N° 10', NULL);
+		CREATE TABLE offenses (db_id INTEGER, location VARCHAR, display_location VARCHAR);
+		INSERT INTO offenses VALUES
+			(56, '18 DE JULIO FRENTE AL N° 250', NULL),
+			(56, '18 DE JULIO 250', NULL),
+			(56, 'SARANDI FRENTE AL N° 10', NULL),
+			(56, 'SARANDI 10', NULL),
+			(45, '18 DE JULIO FRENTE AL N° 250', NULL);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &sqlOffenseRepository{db: db}
+
+	n, err := repo.backfillCleanedLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Errorf("expected 2 offenses moved, got %d", n)
+	}
+
+	rows, err := db.Query(`
+		SELECT db_id, location || ' | ' || COALESCE(display_location, '')
+		FROM offenses
+		ORDER BY ALL
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+
+	if err := scanLocations(rows, func(_ int, location string) {
+		got = append(got, location)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		// Maldonado has no location rules
+		"18 DE JULIO FRENTE AL N° 250 | ",
+		"18 DE JULIO 250 | ",
+		"18 DE JULIO 250 | 18 DE JULIO FRENTE AL N° 250",
+		// judged as written, before the location rules
+		"SARANDI FRENTE AL N° 10 | ",
+		"SARANDI FRENTE AL N° 10 | SARANDI 10",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected offenses:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"crypto/x509"
+	"net/url"
+	"time"
+
+	"github.com/jcodagnone/chapauy/utils/progress"
+)
+
+// Option configures a Client created with NewClient.
+type Option func(*ClientOptions)
+
+// NewClient creates a client for a database of IMPO, storing the records in
+// repo, configured by opts. It lets other Go programs embed the scraper:
+//
+//	dbRef, err := impo.Find("montevideo")
+//	...
+//	repo, err := impo.NewSQLOffenseRepository(db)
+//	...
+//	c := impo.NewClient(dbRef, repo,
+//		impo.WithDbPath("db"),
+//		impo.WithUserAgent("example/1.0 (+https://example.com)"),
+//		impo.WithSearchDepth(5),
+//	)
+//	if err := c.Update(); err != nil {
+//		...
+//	}
+//
+// Without options the client searches, downloads and extracts every document,
+// keeping them in the current directory, with the limits of
+// DefaultClientOptions.
+func NewClient(dbRef *DbReference, repo OffenseRepository, opts ...Option) *Client {
+	options := DefaultClientOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return NewImpoClient(&options, dbRef, repo)
+}
+
+// The defaults of the client, the same as the ones of the flags of chapa.
+const (
+	DefaultSearchDepth     = 25
+	DefaultRequestDelay    = time.Second
+	DefaultExtractTimeout  = 2 * time.Minute
+	DefaultExtractMaxBytes = 64 << 20
+)
+
+// DefaultClientOptions returns the options NewClient starts from: a polite
+// delay between requests and bounded searches and extractions.
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		SearchDepth:        DefaultSearchDepth,
+		RequestDelay:       DefaultRequestDelay,
+		ExtractTimeout:     DefaultExtractTimeout,
+		ExtractMaxBytes:    DefaultExtractMaxBytes,
+		ExtractStreamBytes: DefaultStreamBytes,
+	}
+}
+
+// WithOptions starts from a copy of options, to combine a ClientOptions
+// filled elsewhere (e.g. by flags) with other options. The options given
+// before it are lost.
+func WithOptions(options ClientOptions) Option {
+	return func(o *ClientOptions) {
+		*o = options
+	}
+}
+
+// WithDbPath sets the root path of the database, where the documents are
+// stored unless WithArchivePath is given.
+func WithDbPath(path string) Option {
+	return func(o *ClientOptions) {
+		o.DbPath = path
+	}
+}
+
+// WithArchivePath sets the root path of the downloaded documents.
+func WithArchivePath(path string) Option {
+	return func(o *ClientOptions) {
+		o.ArchivePath = path
+	}
+}
+
+// WithUserAgent sets the User-Agent of the requests to IMPO. Please identify
+// your program and a way to reach you.
+func WithUserAgent(userAgent string) Option {
+	return func(o *ClientOptions) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithProxy sends the requests to IMPO through proxy, trusting rootCAs when
+// it isn't nil. See httputils.ParseProxy and httputils.LoadCABundle.
+func WithProxy(proxy *url.URL, rootCAs *x509.CertPool) Option {
+	return func(o *ClientOptions) {
+		o.Proxy = proxy
+		o.RootCAs = rootCAs
+	}
+}
+
+// WithRequestDelay sets the minimum time between two requests to IMPO.
+func WithRequestDelay(delay time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.RequestDelay = delay
+	}
+}
+
+// WithCrawlWindow restricts the search and download phases to a time of the
+// day, see ParseCrawlWindow.
+func WithCrawlWindow(window CrawlWindow) Option {
+	return func(o *ClientOptions) {
+		o.CrawlWindow = window
+	}
+}
+
+// WithSchedule skips the search and download phases when the database is
+// unlikely to have new documents, see Schedule.
+func WithSchedule(schedule *Schedule) Option {
+	return func(o *ClientOptions) {
+		o.Schedule = schedule
+	}
+}
+
+// WithPhases chooses the phases Update runs: the search of new documents,
+// their download and the extraction of their records.
+func WithPhases(search, download, extract bool) Option {
+	return func(o *ClientOptions) {
+		o.SkipSearch = !search
+		o.SkipDownload = !download
+		o.SkipExtract = !extract
+	}
+}
+
+// WithSearchDepth limits the search to the first pages of results. Zero
+// traverses all of them.
+func WithSearchDepth(pages int) Option {
+	return func(o *ClientOptions) {
+		o.SearchDepth = pages
+	}
+}
+
+// WithSince searches only the documents published on or after since,
+// instead of the ones published since a week before the last search.
+func WithSince(since time.Time) Option {
+	return func(o *ClientOptions) {
+		o.Since = since
+	}
+}
+
+// WithConcurrency sets the number of concurrent downloads and extractions.
+// Zero means one download and as many extractions as CPUs.
+func WithConcurrency(downloads, extractions int) Option {
+	return func(o *ClientOptions) {
+		o.DownloadMaxProcs = downloads
+		o.ExtractMaxProcs = extractions
+	}
+}
+
+// WithDryRun runs without persisting any change.
+func WithDryRun() Option {
+	return func(o *ClientOptions) {
+		o.DryRun = true
+	}
+}
+
+// WithProgress reports the progress of the phases to r instead of logging
+// every page and document.
+func WithProgress(r *progress.Reporter) Option {
+	return func(o *ClientOptions) {
+		o.Progress = r
+	}
+}
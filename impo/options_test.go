@@ -0,0 +1,65 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	dbRef, err := Find("montevideo")
+	require.NoError(t, err)
+
+	c := NewClient(dbRef, nil)
+
+	assert.Equal(t, 25, c.options.SearchDepth)
+	assert.Equal(t, time.Second, c.options.RequestDelay)
+	assert.Equal(t, 2*time.Minute, c.options.ExtractTimeout)
+	assert.Equal(t, int64(64<<20), c.options.ExtractMaxBytes)
+	assert.Equal(t, int64(DefaultStreamBytes), c.options.ExtractStreamBytes)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	dbRef, err := Find("montevideo")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewClient(dbRef, nil,
+		WithDbPath(dir),
+		WithSearchDepth(3),
+		WithSince(since),
+		WithPhases(true, false, true),
+		WithConcurrency(2, 4),
+		WithDryRun(),
+	)
+
+	assert.Equal(t, &ClientOptions{
+		DbPath:             dir,
+		RequestDelay:       DefaultRequestDelay,
+		SearchDepth:        3,
+		Since:              since,
+		SkipDownload:       true,
+		DownloadMaxProcs:   2,
+		ExtractMaxProcs:    4,
+		ExtractTimeout:     DefaultExtractTimeout,
+		ExtractMaxBytes:    DefaultExtractMaxBytes,
+		ExtractStreamBytes: DefaultStreamBytes,
+		DryRun:             true,
+	}, c.options)
+	assert.Equal(t, dir, c.store.base)
+
+	// WithOptions copies the struct and the later options change the copy
+	flags := ClientOptions{DbPath: dir, UserAgent: "test"}
+	c = NewClient(dbRef, nil, WithSearchDepth(3), WithOptions(flags), WithArchivePath("archive"))
+
+	assert.Equal(t, &ClientOptions{DbPath: dir, ArchivePath: "archive", UserAgent: "test"}, c.options)
+	assert.Empty(t, flags.ArchivePath)
+	assert.Equal(t, "archive", c.options.DocumentsPath())
+}
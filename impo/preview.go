@@ -0,0 +1,164 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDocumentNotFound  = errors.New("document not found")
+	ErrAmbiguousDocument = errors.New("document found in several databases")
+)
+
+// FindDocument returns the source of the document of the database whose URL
+// ends with id, e.g. "2933-2024" for
+// https://www.impo.com.uy/bases/notificaciones-cgm/2933-2024. The full URL is
+// an id too.
+func (s *FileStore) FindDocument(id string) (string, error) {
+	entries, err := s.load(s.dbpath())
+	if err != nil {
+		return "", err
+	}
+
+	for source := range entries {
+		if source == id || strings.HasSuffix(source, "/"+id) {
+			return source, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+}
+
+// FindStoredDocument looks for a document in the stores under root of every
+// database, or only in the one of dbID when it isn't zero.
+func FindStoredDocument(root string, dbID int, id string) (*DbReference, string, error) {
+	var (
+		found  *DbReference
+		source string
+	)
+
+	for i := range databases {
+		db := &databases[i]
+		if dbID != 0 && db.ID != dbID {
+			continue
+		}
+
+		s, err := NewFileStore(root, db).FindDocument(id)
+		if errors.Is(err, ErrDocumentNotFound) {
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+
+		if found != nil {
+			return nil, "", fmt.Errorf("%w: %s in %s and %s", ErrAmbiguousDocument, id, found.Name, db.Name)
+		}
+
+		found, source = db, s
+	}
+
+	if found == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+	}
+
+	return found, source, nil
+}
+
+// DocumentPreview is what the extraction produces now for a stored document,
+// without saving it.
+type DocumentPreview struct {
+	DbID             int               `json:"db_id"`
+	DocSource        string            `json:"doc_source"`
+	DocID            string            `json:"doc_id,omitempty"`
+	DocDate          *time.Time        `json:"doc_date,omitempty"`
+	Title            string            `json:"title,omitempty"`
+	ExtractorVersion int               `json:"extractor_version"`
+	Offenses         []*TrafficOffense `json:"offenses"`
+	Errors           int               `json:"errors"`
+	// ErrorPct is the percentage of rows with errors, and WithinBudget
+	// whether the extraction would save the document with them
+	ErrorPct         float64  `json:"error_pct"`
+	WithinBudget     bool     `json:"within_budget"`
+	UnknownHeaders   []string `json:"unknown_headers,omitempty"`
+	UnknownCountries []string `json:"unknown_countries,omitempty"`
+	// Error is why the whole document fails, e.g. a table without the
+	// description
+	Error     string    `json:"error,omitempty"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+}
+
+// PreviewDocument extracts a stored document as `impo update` would, with the
+// headers and countries mapped by the curators so far, without saving the
+// offenses nor the unknown headers and values. The errors of the extraction
+// are part of the preview; reading the document or the repository fails.
+func (c *Client) PreviewDocument(id string) (*DocumentPreview, error) {
+	var err error
+
+	if c.headerSynonyms, err = c.repo.ListHeaderSynonyms(); err != nil {
+		return nil, fmt.Errorf("loading header synonyms: %w", err)
+	}
+
+	if c.countrySynonyms, err = c.repo.ListCountrySynonyms(); err != nil {
+		return nil, fmt.Errorf("loading country synonyms: %w", err)
+	}
+
+	p := &DocumentPreview{
+		DbID:             c.dbRef.ID,
+		DocSource:        id,
+		ExtractorVersion: ExtractorVersion,
+		Offenses:         []*TrafficOffense{},
+	}
+
+	offenses, err := c.parseDocument(id)
+
+	var transient transientError
+	if errors.As(err, &transient) {
+		return nil, err
+	}
+
+	if err == nil && len(offenses) > 0 && offenses[0].DocID == "" {
+		err = c.identifyDocument(id, offenses[0].Document)
+	}
+
+	if err != nil {
+		p.Error, p.ErrorCode = err.Error(), ErrorCodeOf(err)
+	}
+
+	if len(offenses) == 0 {
+		return p, nil
+	}
+
+	doc := offenses[0].Document
+	doc.DocSource = id
+
+	p.DocID, p.Title = doc.DocID, doc.title
+	p.UnknownHeaders, p.UnknownCountries = doc.unknownHeaders, doc.unknownCountries
+
+	if !doc.DocDate.IsZero() {
+		p.DocDate = &doc.DocDate
+	}
+
+	for _, o := range offenses {
+		o.DbID = c.dbRef.ID
+
+		if o.Error != "" {
+			p.Errors++
+		}
+	}
+
+	p.Offenses = offenses
+
+	// as the extraction does, over the rows without errors
+	p.WithinBudget = true
+	if n := len(offenses) - p.Errors; n > 0 {
+		p.ErrorPct = float64(p.Errors) / float64(n) * 100.0
+		p.WithinBudget = c.dbRef.Budget.Allows(id, p.ErrorPct)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+const previewDoc = `
+<html>
+	<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Montevideo N° 3907/025</title>
+	<h5>Fecha de Publicación: 10/12/2025</h5>
+	<pre>Notifícase a los propietarios de los vehículos cuyas matrículas se determinan, que se constató la contravención a lo dispuesto en el art. 9 del Texto Ordenado del Sucive.</pre>
+	<table class="tabla_en_texto">
+		<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+		<TR><TD><pre>SBF1234</pre></TD><TD><pre>09/12/2025 10:00</pre></TD></TR>
+		<TR><TD><pre>SBF4321</pre></TD><TD><pre>32/12/2025 10:30</pre></TD></TR>
+	</table>
+</html>`
+
+func newPreviewClient(t *testing.T, id, content string) *Client {
+	t.Helper()
+
+	dbRef, err := Find("montevideo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewImpoClient(&ClientOptions{DbPath: t.TempDir()}, dbRef, NewJSONLinesRepository(io.Discard))
+	if _, err := c.store.Upsert([]SearchResultEntry{{Href: id, Title: "3907/025"}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if content != "" {
+		if err := c.store.SaveDocument(id, strings.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return c
+}
+
+func TestFindStoredDocument(t *testing.T) {
+	id := "https://www.impo.com.uy/bases/notificaciones-cgm/3907-2025"
+	c := newPreviewClient(t, id, previewDoc)
+	root := c.options.DocumentsPath()
+
+	for _, q := range []string{"3907-2025", id} {
+		dbRef, source, err := FindStoredDocument(root, 0, q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+
+		if dbRef.ID != 6 || source != id {
+			t.Errorf("%s: got %d %s", q, dbRef.ID, source)
+		}
+	}
+
+	// only the end of the path, not any suffix
+	if _, _, err := FindStoredDocument(root, 0, "07-2025"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("expected ErrDocumentNotFound, got %v", err)
+	}
+
+	if _, _, err := FindStoredDocument(root, 40, "3907-2025"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Errorf("expected ErrDocumentNotFound in another database, got %v", err)
+	}
+}
+
+func TestPreviewDocument(t *testing.T) {
+	id := "https://www.impo.com.uy/bases/notificaciones-cgm/3907-2025"
+	c := newPreviewClient(t, id, previewDoc)
+
+	p, err := c.PreviewDocument(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Error != "" {
+		t.Fatalf("unexpected error %s", p.Error)
+	}
+
+	if p.DbID != 6 || p.DocSource != id || p.DocID == "" || p.DocDate == nil {
+		t.Errorf("unexpected document %+v", p)
+	}
+
+	if len(p.Offenses) != 2 || p.Errors != 1 {
+		t.Fatalf("expected 2 offenses with an error, got %d with %d", len(p.Offenses), p.Errors)
+	}
+
+	if p.ErrorPct != 100 || p.WithinBudget {
+		t.Errorf("expected the error out of budget, got %.2f %v", p.ErrorPct, p.WithinBudget)
+	}
+
+	// nothing is saved
+	if exists, _ := c.store.exists(id); !exists {
+		t.Error("expected the document to remain stored")
+	}
+}
+
+func TestPreviewDocument_Failures(t *testing.T) {
+	id := "https://www.impo.com.uy/bases/notificaciones-cgm/3907-2025"
+
+	c := newPreviewClient(t, id, `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Montevideo N° 3907/025</title>
+		<h5>Fecha de Publicación: 10/12/2025</h5>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+			<TR><TD><pre>SBF1234</pre></TD><TD><pre>10/12/2025 10:00</pre></TD></TR>
+		</table>
+	</html>`)
+
+	p, err := c.PreviewDocument(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(p.Error, "tabla sin columna descripción") || p.ErrorCode == "" {
+		t.Errorf("expected the failure of the document, got %q %q", p.Error, p.ErrorCode)
+	}
+
+	// a document not downloaded yet isn't a preview
+	c = newPreviewClient(t, id, "")
+	if _, err := c.PreviewDocument(id); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// staleDocuments returns the documents to re-extract: the ones extracted by
+// an extractor older than ReextractBelow, only the ones of ReextractYear when
+// set. The year comes from the URL of the document, as in the file store.
+func (c *Client) staleDocuments() ([]string, error) {
+	docs, err := c.repo.GetStaleDocuments(c.dbRef, c.options.ReextractBelow)
+	if err != nil || c.options.ReextractYear == 0 {
+		return docs, err
+	}
+
+	return slices.DeleteFunc(docs, func(id string) bool {
+		_, year, err := c.dbRef.docIDFromURL(id)
+
+		return err != nil || year != c.options.ReextractYear
+	}), nil
+}
+
+// reextractDiff compares, on a dry run, what a re-extraction gets from each
+// document with what the database has of it, writing the documents that
+// would change. It compares the number of records and errors: the records
+// themselves aren't read back from the database.
+type reextractDiff struct {
+	w       io.Writer
+	before  map[string]DocumentSummary
+	docs    int
+	changed int
+}
+
+// newReextractDiff returns the diff of the re-extraction, nil when it wasn't
+// asked for.
+func (c *Client) newReextractDiff() (*reextractDiff, error) {
+	if c.options.ReextractDiff == nil || !c.options.DryRun || c.options.ReextractBelow == 0 {
+		return nil, nil
+	}
+
+	before, err := c.repo.ListDocumentSummaries(c.dbRef.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing the extracted documents: %w", err)
+	}
+
+	return &reextractDiff{w: c.options.ReextractDiff, before: before}, nil
+}
+
+// add compares the extraction of a document, failed when err isn't nil.
+func (d *reextractDiff) add(id string, m *ExtractMetrics, err error) {
+	if d == nil {
+		return
+	}
+
+	d.docs++
+
+	b := d.before[id]
+	records, errs := b.Offenses-b.Errors, b.Errors
+
+	switch {
+	case err != nil:
+		fmt.Fprintf(d.w, "- %s: %d records, %d errors -> failed: %v\n", id, records, errs, err)
+	case m != nil && (m.NewRecords != records || m.NewErrors != errs):
+		fmt.Fprintf(d.w, "~ %s: %d records, %d errors -> %d records, %d errors\n",
+			id, records, errs, m.NewRecords, m.NewErrors)
+	default:
+		return
+	}
+
+	d.changed++
+}
+
+// finish writes the totals of the diff.
+func (d *reextractDiff) finish(dbName string) {
+	if d == nil {
+		return
+	}
+
+	fmt.Fprintf(d.w, "%s: %d of %d documents would change\n", dbName, d.changed, d.docs)
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// staleRepository is a repository whose documents were all extracted by an
+// older extractor.
+type staleRepository struct {
+	OffenseRepository
+	stale     []string
+	summaries map[string]DocumentSummary
+}
+
+func (r *staleRepository) GetStaleDocuments(_ *DbReference, _ int) ([]string, error) {
+	return r.stale, nil
+}
+
+func (r *staleRepository) ListDocumentSummaries(_ int) (map[string]DocumentSummary, error) {
+	return r.summaries, nil
+}
+
+func TestReextractDryRun(t *testing.T) {
+	id := "https://www.impo.com.uy/bases/notificaciones-cgm/3907-2025"
+	other := "https://www.impo.com.uy/bases/notificaciones-cgm/100-2024"
+
+	dbRef, err := Find("montevideo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diff bytes.Buffer
+
+	repo := &staleRepository{
+		OffenseRepository: NewJSONLinesRepository(io.Discard),
+		stale:             []string{other, id},
+		summaries: map[string]DocumentSummary{
+			id:    {Offenses: 5, Errors: 1},
+			other: {Offenses: 3},
+		},
+	}
+	options := &ClientOptions{
+		DbPath:         t.TempDir(),
+		DryRun:         true,
+		ReextractBelow: ExtractorVersion,
+		ReextractYear:  2025,
+		ReextractDiff:  &diff,
+	}
+
+	c := NewImpoClient(options, dbRef, repo)
+	if err := c.store.SaveDocument(id, strings.NewReader(strings.Replace(previewDoc, "32/12/2025", "08/12/2025", 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := c.staleDocuments()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docs) != 1 || docs[0] != id {
+		t.Errorf("got %v, want only the document of 2025", docs)
+	}
+
+	if err := c.extractDocuments(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "~ " + id + ": 4 records, 1 errors -> 2 records, 0 errors\n" +
+		"Montevideo: 1 of 1 documents would change\n"
+	if diff.String() != want {
+		t.Errorf("got diff\n%s\nwant\n%s", diff.String(), want)
+	}
+
+	// without changes, only the totals
+	diff.Reset()
+
+	repo.summaries[id] = DocumentSummary{Offenses: 2}
+
+	if err := c.extractDocuments(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Montevideo: 0 of 1 documents would change\n"; diff.String() != want {
+		t.Errorf("got diff %q, want %q", diff.String(), want)
+	}
+}
@@ -4,13 +4,18 @@
 package impo
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"strconv"
-	"strings"
+	"time"
 
+	"github.com/duckdb/duckdb-go/v2"
 	"github.com/jcodagnone/chapauy/curation/utils"
 	"github.com/jcodagnone/chapauy/spatial"
 )
@@ -26,12 +31,66 @@ type OffenseRepository interface {
 	SaveTrafficOffenses(offenses []*TrafficOffense) error
 	// GetExtractedDocuments returns a list of all the documents that have been extracted.
 	GetExtractedDocuments(db *DbReference) (map[string]bool, error)
+	// GetStaleDocuments returns the extracted documents of the database whose
+	// extractor version is older than version.
+	GetStaleDocuments(db *DbReference, version int) ([]string, error)
+	// SaveMeta records key/values (e.g. build information) in the meta table.
+	SaveMeta(meta map[string]string) error
+	// LinkRepublishedDocuments fills superseded_by for every re-published
+	// document, returning the number of offenses updated.
+	LinkRepublishedDocuments() (int64, error)
+	// BackfillAppealDeadlines computes appeal_deadline for offenses that lack it.
+	BackfillAppealDeadlines() (int64, error)
+	// BackfillErrorCodes classifies the errors stored before the error codes.
+	BackfillErrorCodes() (int64, error)
+	// SaveExtractionFailure records why the extraction of a document failed.
+	SaveExtractionFailure(dbID int, docSource string, cause error) error
+	// GetFailedDocuments returns the last failed extraction of the documents
+	// of the database that didn't succeed since, by document.
+	GetFailedDocuments(db *DbReference) (map[string]ExtractionFailure, error)
+	// ListDocumentSummaries returns what is known of the extracted or failed
+	// documents of a database, by document.
+	ListDocumentSummaries(dbID int) (map[string]DocumentSummary, error)
+	// ListHeaderSynonyms returns the headers mapped by the curators to a
+	// property, by normalized header.
+	ListHeaderSynonyms() (map[string]OffenseProperty, error)
+	// SavePendingHeaders records the headers of a document no property is
+	// known for, so that a curator maps them.
+	SavePendingHeaders(dbID int, docSource string, headers []string) error
+	// ListCountrySynonyms returns the ISO codes the curators mapped the
+	// spellings of the countries to, by normalized spelling.
+	ListCountrySynonyms() (map[string]string, error)
+	// SavePendingValues records the values of a kind of a document that
+	// couldn't be interpreted, so that a curator maps them.
+	SavePendingValues(dbID int, docSource string, kind string, values []string) error
+	// SaveExtractionRun records the outcome of the extraction phase.
+	SaveExtractionRun(run ExtractionRun) error
+	// ListExtractionRuns returns the last runs of the database, the most
+	// recent first.
+	ListExtractionRuns(dbID int, limit int) ([]ExtractionRun, error)
+	// ListPublicationDates returns the last distinct dates of publication of
+	// the documents of the database, the most recent first.
+	ListPublicationDates(dbID int, limit int) ([]time.Time, error)
+	// SaveLinkChecks records the status of the links of the documents.
+	SaveLinkChecks(checks []LinkCheck) error
+	// ListMissingDocuments returns the last check of the documents of the
+	// database that moved or disappeared from IMPO.
+	ListMissingDocuments(dbID int) ([]LinkCheck, error)
 
 	//////// Geocoding Integration
-	// BackfillGeocodingData updates offenses with geocoding data from location_judgments table
+	// BackfillGeocodingData updates offenses with geocoding data from the
+	// locations table. As the judgments are applied at insert time, it only
+	// has work after judging or merging locations of offenses already stored.
 	BackfillGeocodingData() (int64, error)
 	// BackportDescriptionArticles updates offenses with curated article and section data
 	BackportDescriptionArticles() (int64, error)
+	// BackfillVehicleOverrides applies the categories and vehicle types of the
+	// reviewed and curated overrides to the offenses, restoring the ones of the
+	// removed overrides, and returns the number updated.
+	BackfillVehicleOverrides() (int64, error)
+	// BackfillStreetAliases rewrites the old names of the renamed streets in
+	// the locations of the offenses, returning the number updated.
+	BackfillStreetAliases() (int64, error)
 }
 
 // ArticleLabel represents a label for an article.
@@ -41,8 +100,7 @@ type ArticleLabel struct {
 }
 
 type locationData struct {
-	CanonicalLocation string
-	DisplayLocation   string
+	CanonicalLocation string // empty when the judgment wasn't merged
 	Point             spatial.Point
 	H3Res1            uint64
 	H3Res2            uint64
@@ -52,6 +110,8 @@ type locationData struct {
 	H3Res6            uint64
 	H3Res7            uint64
 	H3Res8            uint64
+	H3Res9            uint64
+	H3Res10           uint64
 }
 
 type descriptionData struct {
@@ -72,8 +132,14 @@ type sqlOffenseRepository struct {
 	articleCodeCache map[string]ArticleLabel
 	// Cache for location data
 	locationCache map[locationKey]locationData
+	// Cache for location data by IntersectionKey
+	intersectionCache map[locationKey]locationData
 	// Cache for description data
 	descriptionCache map[string]descriptionData
+	// Overrides of the vehicle types of the fleets
+	vehicleOverrides *VehicleOverrides
+	// Old names of the renamed streets
+	streetAliases *StreetAliases
 }
 
 func NewSQLOffenseRepository(db *sql.DB) (OffenseRepository, error) {
@@ -135,9 +201,20 @@ func (r *sqlOffenseRepository) LoadCaches() error {
 		return err
 	}
 
+	if err := r.loadVehicleOverrides(); err != nil {
+		return err
+	}
+
+	if err := r.loadStreetAliases(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// loadLocationCache loads every judgment, merged into a canonical location or
+// not, so new offenses get their point at insert time and the backfill only
+// has to deal with the judgments made after the import.
 func (r *sqlOffenseRepository) loadLocationCache() error {
 	r.locationCache = make(map[locationKey]locationData)
 
@@ -145,32 +222,75 @@ func (r *sqlOffenseRepository) loadLocationCache() error {
 		SELECT
 			db_id, location, canonical_location, point,
 			h3_res1, h3_res2, h3_res3, h3_res4,
-			h3_res5, h3_res6, h3_res7, h3_res8
+			h3_res5, h3_res6, h3_res7, h3_res8,
+			h3_res9, h3_res10
 		FROM locations
-		WHERE canonical_location IS NOT NULL
 	`)
 	if err != nil {
 		return fmt.Errorf("querying locations: %w", err)
 	}
 	defer rows.Close()
 
+	byCleaned := make(map[locationKey]locationData)
+	byIntersection := make(map[locationKey]locationData)
+	mergedIntersection := make(map[locationKey]bool)
+
 	for rows.Next() {
 		var k locationKey
 
 		var d locationData
 
+		var canonical sql.NullString
+
 		if err := rows.Scan(
-			&k.DbID, &k.Location, &d.CanonicalLocation, &d.Point,
+			&k.DbID, &k.Location, &canonical, &d.Point,
 			&d.H3Res1, &d.H3Res2, &d.H3Res3, &d.H3Res4,
 			&d.H3Res5, &d.H3Res6, &d.H3Res7, &d.H3Res8,
+			&d.H3Res9, &d.H3Res10,
 		); err != nil {
 			return fmt.Errorf("scanning location: %w", err)
 		}
 
-		d.DisplayLocation = k.Location
+		d.CanonicalLocation = canonical.String
 		r.locationCache[k] = d
+
+		if cleaned := CleanLocation(k.DbID, k.Location); cleaned != k.Location {
+			ck := locationKey{DbID: k.DbID, Location: cleaned}
+			// when several judgments clean up to the same text, the merged
+			// one wins
+			if prev, ok := byCleaned[ck]; !ok || prev.CanonicalLocation == "" {
+				byCleaned[ck] = d
+			}
+		}
+
+		if ik, ok := mirroredKey(k.DbID, k.Location); ok {
+			if _, seen := byIntersection[ik]; !seen || !mergedIntersection[ik] && canonical.Valid {
+				// the offenses of the same streets in another order take
+				// the judged location
+				if !canonical.Valid {
+					d.CanonicalLocation = k.Location
+				}
+
+				byIntersection[ik] = d
+				mergedIntersection[ik] = canonical.Valid
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating locations: %w", err)
 	}
 
+	// offenses whose location only differs by what the location rules clean
+	// up share the judgment, unless they have their own
+	for k, d := range byCleaned {
+		if _, ok := r.locationCache[k]; !ok {
+			r.locationCache[k] = d
+		}
+	}
+
+	r.intersectionCache = byIntersection
+
 	return nil
 }
 
@@ -208,6 +328,12 @@ func (r *sqlOffenseRepository) loadDescriptionCache() error {
 	return nil
 }
 
+// ActiveOffensesView defines active_offenses. DuckDB binds the view to the
+// columns offenses had when it was created, so it must be recreated after
+// adding columns to offenses.
+const ActiveOffensesView = `CREATE OR REPLACE VIEW active_offenses AS
+			SELECT * FROM offenses WHERE superseded_by IS NULL;`
+
 func (r *sqlOffenseRepository) CreateSchema() error {
 	_, err := r.db.Exec(`
 		CREATE TABLE IF NOT EXISTS offenses (
@@ -240,10 +366,63 @@ func (r *sqlOffenseRepository) CreateSchema() error {
 
 		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS article_ids VARCHAR[];
 		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS article_codes TINYINT[];
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS row_hash BIGINT;
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS superseded_by VARCHAR;
+		-- city-block cells, see curation.ReindexCells for the existing rows
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS h3_res9 UBIGINT;
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS h3_res10 UBIGINT;
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS appeal_deadline DATE;
+		-- plates marked as foreign by the document, e.g. "(E)" in Rio Negro
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS vehicle_foreign BOOLEAN;
+		-- category of the plate, and whether a VehicleOverride set it or the type
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS vehicle_category VARCHAR;
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS vehicle_overridden BOOLEAN;
+		-- original cells of the row, only extracted with --keep-raw
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS raw JSON;
+		-- ExtractorVersion that produced the row
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS extractor_version INTEGER;
+		-- ErrorCode of the error, to aggregate them
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS error_code VARCHAR;
+		-- meters to the nearest school zone and fixed radar, see chapa spatial proximity
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS nearest_school_m DOUBLE;
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS nearest_radar_m DOUBLE;
+		-- ur of each of the articles, what the aggregations by article sum, see URRule
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS ur_article INTEGER;
+		-- when the authority processed the offense, "Fecha Ingreso", see TimeRule
+		ALTER TABLE offenses ADD COLUMN IF NOT EXISTS processed_at TIMESTAMPTZ;
+
+		-- offenses of documents that were not re-published, what analytics should count
+		` + ActiveOffensesView + `
+
+		-- extractor version of every document, to re-extract the ones that
+		-- predate a parser change
+		CREATE TABLE IF NOT EXISTS document_extractions (
+			doc_source VARCHAR PRIMARY KEY,
+			db_id INTEGER NOT NULL,
+			extractor_version INTEGER NOT NULL,
+			extracted_at TIMESTAMPTZ NOT NULL
+		);
 
-	`)
+		-- error rate of every extraction run, to alarm when it jumps
+		CREATE TABLE IF NOT EXISTS extraction_runs (
+			db_id INTEGER NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			documents INTEGER NOT NULL,
+			records INTEGER NOT NULL,
+			errors INTEGER NOT NULL
+		);
 
-	return err
+		CREATE TABLE IF NOT EXISTS meta (
+			key VARCHAR PRIMARY KEY,
+			value VARCHAR,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+		);
+	` + HeadersSchema + ValuesSchema + VehicleOverridesSchema + StreetAliasesSchema + FailuresSchema + DocStatusSchema)
+	if err != nil {
+		return err
+	}
+
+	return r.migrateURResolution()
 }
 
 func (r *sqlOffenseRepository) GetExtractedDocuments(db *DbReference) (map[string]bool, error) {
@@ -267,6 +446,153 @@ func (r *sqlOffenseRepository) GetExtractedDocuments(db *DbReference) (map[strin
 	return existingDocs, nil
 }
 
+// GetStaleDocuments returns the documents with a row extracted before the
+// version. Rows saved before the version was stamped on them take the one of
+// their document, if any, and 0 otherwise.
+func (r *sqlOffenseRepository) GetStaleDocuments(db *DbReference, version int) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT o.doc_source
+		FROM offenses o
+		LEFT JOIN document_extractions e ON e.doc_source = o.doc_source
+		WHERE o.db_id = ?
+		GROUP BY o.doc_source
+		HAVING MIN(COALESCE(o.extractor_version, e.extractor_version, 0)) < ?
+		ORDER BY o.doc_source
+	`, db.ID, version)
+	if err != nil {
+		return nil, fmt.Errorf("querying stale documents: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []string
+
+	for rows.Next() {
+		var docSource string
+		if err := rows.Scan(&docSource); err != nil {
+			return nil, fmt.Errorf("scanning stale document: %w", err)
+		}
+
+		ret = append(ret, docSource)
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlOffenseRepository) SaveMeta(meta map[string]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO meta (key, value, updated_at)
+		VALUES (?, ?, current_timestamp)
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing meta statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for k, v := range meta {
+		if _, err := stmt.Exec(k, v); err != nil {
+			return fmt.Errorf("saving meta %s: %w", k, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// URResolutionMetaKey records in the meta table the URResolution of the ur
+// columns. Databases without it predate the key and have a resolution of 100.
+const URResolutionMetaKey = "ur_resolution"
+
+const legacyURResolution = 100
+
+// migrateURResolution scales the stored UR values when URResolution changes.
+func (r *sqlOffenseRepository) migrateURResolution() error {
+	from := legacyURResolution
+
+	var value string
+
+	err := r.db.QueryRow("SELECT value FROM meta WHERE key = ?", URResolutionMetaKey).Scan(&value)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("querying UR resolution: %w", err)
+	default:
+		if from, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid UR resolution %q: %w", value, err)
+		}
+	}
+
+	if from == URResolution {
+		return nil
+	}
+
+	if from <= 0 || URResolution%from != 0 {
+		return fmt.Errorf("can't migrate UR resolution %d to %d", from, URResolution)
+	}
+
+	factor := URResolution / from
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	res, err := tx.Exec("UPDATE offenses SET ur = ur * ? WHERE ur IS NOT NULL", factor)
+	if err != nil {
+		return fmt.Errorf("migrating offenses UR: %w", err)
+	}
+
+	// the review queue of the curators keeps a copy of the UR
+	var outliers int
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) FROM duckdb_tables() WHERE table_name = 'ur_outliers'",
+	).Scan(&outliers); err != nil {
+		return fmt.Errorf("looking for UR outliers: %w", err)
+	}
+
+	if outliers > 0 {
+		if _, err := tx.Exec(
+			"UPDATE ur_outliers SET ur = ur * ?, median = median * ?, p05 = p05 * ?, p95 = p95 * ?",
+			factor, factor, factor, factor,
+		); err != nil {
+			return fmt.Errorf("migrating UR outliers: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO meta (key, value, updated_at)
+		VALUES (?, ?, current_timestamp)
+	`, URResolutionMetaKey, strconv.Itoa(URResolution)); err != nil {
+		return fmt.Errorf("saving UR resolution: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing UR resolution: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("Migrated %d UR values from a resolution of %d to %d", n, from, URResolution)
+	}
+
+	return nil
+}
+
 func nve(v string) any {
 	var ret any
 	if len(v) == 0 {
@@ -281,8 +607,36 @@ func nve(v string) any {
 func (r *sqlOffenseRepository) enrichOffense(o *TrafficOffense) {
 	// 1. Geocoding
 	if o.Location != "" {
-		key := locationKey{DbID: o.DbID, Location: o.Location}
-		if locData, ok := r.locationCache[key]; ok {
+		cleaned := CleanLocation(o.DbID, o.Location)
+		renamed, isRenamed := r.streetAliases.Apply(o.DbID, cleaned, o.when())
+
+		// the judgment of the current name wins over one of the old name,
+		// so the corner keeps a single point
+		candidates := []string{o.Location, cleaned}
+		if isRenamed {
+			candidates = append([]string{renamed}, candidates...)
+		}
+
+		var (
+			locData locationData
+			ok      bool
+		)
+
+		for _, location := range candidates {
+			if locData, ok = r.locationCache[locationKey{DbID: o.DbID, Location: location}]; ok {
+				break
+			}
+		}
+
+		if !ok {
+			// the same streets in another order, "AV ITALIA Y AV BOLIVIA"
+			// for "AV BOLIVIA Y AV ITALIA"
+			if ik, isIntersection := IntersectionKey(renamed); isIntersection {
+				locData, ok = r.intersectionCache[locationKey{DbID: o.DbID, Location: ik}]
+			}
+		}
+
+		if ok {
 			o.Point = &locData.Point
 			o.H3Res1 = locData.H3Res1
 			o.H3Res2 = locData.H3Res2
@@ -292,12 +646,23 @@ func (r *sqlOffenseRepository) enrichOffense(o *TrafficOffense) {
 			o.H3Res6 = locData.H3Res6
 			o.H3Res7 = locData.H3Res7
 			o.H3Res8 = locData.H3Res8
+			o.H3Res9 = locData.H3Res9
+			o.H3Res10 = locData.H3Res10
 
 			if locData.CanonicalLocation != "" {
+				// as written in the offense, it may differ from the judged
+				// one by what the location rules clean up
+				o.DisplayLocation = o.Location
 				o.Location = locData.CanonicalLocation
-				o.DisplayLocation = locData.DisplayLocation
 			}
 		}
+
+		// under the current names of the streets, so the corner keeps its
+		// offenses across renames
+		if isRenamed && o.DisplayLocation == "" {
+			o.DisplayLocation = o.Location
+			o.Location = renamed
+		}
 	}
 
 	// 2. Description / Articles
@@ -306,7 +671,7 @@ func (r *sqlOffenseRepository) enrichOffense(o *TrafficOffense) {
 		if data, ok := r.descriptionCache[normDesc]; ok {
 			o.ArticleIDs = data.ArticleIDs
 			o.ArticleCodes = data.ArticleCodes
-		} else if strings.Contains(o.Description, ",") {
+		} else if utils.DefaultTokenizer.HasSeparator(o.Description) {
 			classify := func(part string) (utils.Classification, bool, error) {
 				normPart := utils.LowerASCIIFolding(part)
 				if info, ok := r.descriptionCache[normPart]; ok {
@@ -336,6 +701,237 @@ func nz(v uint64) any {
 	return v
 }
 
+// offenseValues returns the column values of an offense, in the order used by
+// the insert and update statements of SaveTrafficOffenses.
+func offenseValues(record *TrafficOffense, overrides *VehicleOverrides) []any {
+	var countryHint string
+	if record.VehicleInfo != nil {
+		countryHint = record.VehicleInfo.Country
+		if countryHint == "" && record.VehicleInfo.Foreign {
+			countryHint = HintForeign
+		}
+	}
+
+	info, _ := AnalyzeVehicleID(record.Vehicle, countryHint)
+	overridden := overrides.Apply(record.Vehicle, info)
+
+	var vehicleType sql.NullString
+	if info.VehicleType != "" {
+		vehicleType.String = info.VehicleType
+		vehicleType.Valid = true
+	}
+
+	var offenseError sql.NullString
+	if record.Error != "" {
+		offenseError.String = record.Error
+		offenseError.Valid = true
+	}
+
+	var raw any
+	if len(record.Raw) > 0 {
+		// a list of strings can't fail to encode
+		b, _ := json.Marshal(record.Raw)
+		raw = string(b)
+	}
+
+	var processedAt any
+	if record.ProcessedAt != nil {
+		processedAt = *record.ProcessedAt
+	}
+
+	var lng, lat any
+	if record.Point != nil {
+		lng = record.Point.Lng
+		lat = record.Point.Lat
+	}
+
+	return []any{
+		record.DbID,
+		record.DocID,
+		record.DocDate,
+		record.DocSource,
+		record.RecordID,
+		record.ID,
+		record.Vehicle,
+		nve(info.Country),
+		vehicleType,
+		record.Time,
+		record.Time, // For time_year extraction
+		nve(record.Location),
+		nve(record.DisplayLocation),
+		nve(record.Description),
+		record.UR,
+		offenseError,
+		nve(string(record.ErrorCode)),
+		lng,
+		lat,
+		nz(record.H3Res1),
+		nz(record.H3Res2),
+		nz(record.H3Res3),
+		nz(record.H3Res4),
+		nz(record.H3Res5),
+		nz(record.H3Res6),
+		nz(record.H3Res7),
+		nz(record.H3Res8),
+		nz(record.H3Res9),
+		nz(record.H3Res10),
+		record.ArticleIDs,
+		record.ArticleCodes,
+		info.Foreign,
+		raw,
+		ExtractorVersion,
+		articleUR(record),
+		processedAt,
+		nve(info.Category),
+		overridden,
+	}
+}
+
+// rowHash fingerprints the values written for a row so unchanged rows can be
+// skipped when a document is extracted again.
+func rowHash(values []any) int64 {
+	h := fnv.New64a()
+	for _, v := range values {
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC().Format(time.RFC3339Nano)
+		}
+
+		fmt.Fprintf(h, "%v\x00", v)
+	}
+
+	return int64(h.Sum64()) // #nosec G115 - a fingerprint, overflow is irrelevant
+}
+
+// offenseAppenderTypes are the types of offenseValues plus the row hash, the
+// columns of the rows batched by insertOffenses.
+var offenseAppenderTypes = func() []duckdb.TypeInfo {
+	t := func(typ duckdb.Type) duckdb.TypeInfo {
+		info, err := duckdb.NewTypeInfo(typ)
+		if err != nil {
+			panic(err)
+		}
+
+		return info
+	}
+	list := func(typ duckdb.Type) duckdb.TypeInfo {
+		info, err := duckdb.NewListInfo(t(typ))
+		if err != nil {
+			panic(err)
+		}
+
+		return info
+	}
+	h3 := t(duckdb.TYPE_UBIGINT)
+
+	return []duckdb.TypeInfo{
+		t(duckdb.TYPE_INTEGER),      // db_id
+		t(duckdb.TYPE_VARCHAR),      // doc_id
+		t(duckdb.TYPE_DATE),         // doc_date
+		t(duckdb.TYPE_VARCHAR),      // doc_source
+		t(duckdb.TYPE_INTEGER),      // record_id
+		t(duckdb.TYPE_VARCHAR),      // offense_id
+		t(duckdb.TYPE_VARCHAR),      // vehicle
+		t(duckdb.TYPE_VARCHAR),      // vehicle_country
+		t(duckdb.TYPE_VARCHAR),      // vehicle_type
+		t(duckdb.TYPE_TIMESTAMP_TZ), // time
+		t(duckdb.TYPE_TIMESTAMP_TZ), // time, for time_year
+		t(duckdb.TYPE_VARCHAR),      // location
+		t(duckdb.TYPE_VARCHAR),      // display_location
+		t(duckdb.TYPE_VARCHAR),      // description
+		t(duckdb.TYPE_INTEGER),      // ur
+		t(duckdb.TYPE_VARCHAR),      // error
+		t(duckdb.TYPE_VARCHAR),      // error_code
+		t(duckdb.TYPE_DOUBLE),       // point longitude
+		t(duckdb.TYPE_DOUBLE),       // point latitude
+		h3, h3, h3, h3, h3, h3, h3, h3, h3, h3,
+		list(duckdb.TYPE_VARCHAR),   // article_ids
+		list(duckdb.TYPE_TINYINT),   // article_codes
+		t(duckdb.TYPE_BOOLEAN),      // vehicle_foreign
+		t(duckdb.TYPE_VARCHAR),      // raw
+		t(duckdb.TYPE_INTEGER),      // extractor_version
+		t(duckdb.TYPE_INTEGER),      // ur_article
+		t(duckdb.TYPE_TIMESTAMP_TZ), // processed_at
+		t(duckdb.TYPE_VARCHAR),      // vehicle_category
+		t(duckdb.TYPE_BOOLEAN),      // vehicle_overridden
+		t(duckdb.TYPE_BIGINT),       // row_hash
+	}
+}()
+
+// appenderValue converts a value of offenseValues to one the appender takes:
+// unlike the statements, it doesn't know about driver.Valuer nor named types.
+func appenderValue(v any) (driver.Value, error) {
+	switch v := v.(type) {
+	case driver.Valuer:
+		return v.Value()
+	case UR:
+		return int(v), nil
+	default:
+		return v, nil
+	}
+}
+
+// insertOffenses inserts the rows, offenseValues plus the row hash, with an
+// appender on the connection of the transaction: a statement per row makes
+// the documents with thousands of rows take seconds to store.
+func insertOffenses(conn *sql.Conn, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return conn.Raw(func(driverConn any) error {
+		dc, ok := driverConn.(driver.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+
+		appender, err := duckdb.NewQueryAppender(dc, `
+			INSERT INTO offenses (
+				db_id, doc_id, doc_date, doc_source, record_id, offense_id,
+				vehicle, vehicle_country, vehicle_type, time, time_year, location, display_location, description, ur, error,
+				error_code, point,
+				h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8, h3_res9, h3_res10,
+				article_ids, article_codes, vehicle_foreign, raw, extractor_version, ur_article,
+				processed_at, vehicle_category, vehicle_overridden, row_hash
+			)
+			SELECT
+				col1, col2, col3, col4, col5, col6,
+				col7, col8, col9, col10, EXTRACT(YEAR FROM col11), col12, col13, col14, col15, col16,
+				col17, ST_Point(col18, col19),
+				col20, col21, col22, col23, col24, col25, col26, col27, col28, col29,
+				col30, col31, col32, col33, col34, col35,
+				col36, col37, col38, col39
+			FROM appended_data
+		`, "", offenseAppenderTypes, nil)
+		if err != nil {
+			return fmt.Errorf("creating appender: %w", err)
+		}
+
+		row := make([]driver.Value, len(offenseAppenderTypes))
+
+		for _, values := range rows {
+			for i, v := range values {
+				if row[i], err = appenderValue(v); err != nil {
+					appender.Close()
+
+					return fmt.Errorf("converting column %d: %w", i+1, err)
+				}
+			}
+
+			if err := appender.AppendRow(row...); err != nil {
+				appender.Close()
+
+				return fmt.Errorf("appending row: %w", err)
+			}
+		}
+
+		return appender.Close()
+	})
+}
+
+// SaveTrafficOffenses stores the offenses of a document. Rows are keyed by
+// (doc_source, record_id): new rows are inserted, changed rows are updated and
+// rows that no longer appear in the document are deleted. Unchanged rows are
+// left untouched.
 func (r *sqlOffenseRepository) SaveTrafficOffenses(offenses []*TrafficOffense) error {
 	if len(offenses) == 0 {
 		return nil
@@ -349,8 +945,17 @@ func (r *sqlOffenseRepository) SaveTrafficOffenses(offenses []*TrafficOffense) e
 	}
 
 	docSource := offenses[0].DocSource
-	tx, err := r.db.Begin()
+	ctx := context.Background()
 
+	// the inserts go through an appender, which works on a connection: the
+	// transaction has to be on the same one
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("getting connection for %s: %w", docSource, err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("starting transaction for %s: %w", docSource, err)
 	}
@@ -361,90 +966,239 @@ func (r *sqlOffenseRepository) SaveTrafficOffenses(offenses []*TrafficOffense) e
 		}
 	}()
 
-	if _, err := tx.Exec("DELETE FROM offenses WHERE doc_source = ?", docSource); err != nil {
-		return fmt.Errorf("deleting records for %s: %w", docSource, err)
+	existing, err := existingRowHashes(tx, docSource)
+	if err != nil {
+		return err
 	}
 
-	stmt, err := tx.Prepare(`
-		INSERT INTO offenses (
-			db_id, doc_id, doc_date, doc_source, record_id, offense_id,
-			vehicle, vehicle_country, vehicle_type, time, time_year, location, display_location, description, ur, error,
-			point,
-			h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8,
-			article_ids, article_codes
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, EXTRACT(YEAR FROM ?::TIMESTAMPTZ), ?, ?, ?, ?, ?, ST_Point(?, ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	updateStmt, err := tx.Prepare(`
+		UPDATE offenses SET
+			db_id = ?, doc_id = ?, doc_date = ?, doc_source = ?, record_id = ?, offense_id = ?,
+			vehicle = ?, vehicle_country = ?, vehicle_type = ?, time = ?, time_year = EXTRACT(YEAR FROM ?::TIMESTAMPTZ),
+			location = ?, display_location = ?, description = ?, ur = ?, error = ?,
+			error_code = ?, point = ST_Point(?, ?),
+			h3_res1 = ?, h3_res2 = ?, h3_res3 = ?, h3_res4 = ?, h3_res5 = ?, h3_res6 = ?, h3_res7 = ?, h3_res8 = ?,
+			h3_res9 = ?, h3_res10 = ?,
+			article_ids = ?, article_codes = ?, vehicle_foreign = ?, raw = ?, extractor_version = ?, ur_article = ?,
+			processed_at = ?, vehicle_category = ?, vehicle_overridden = ?, row_hash = ?
+		WHERE doc_source = ? AND record_id = ?
 	`)
 	if err != nil {
 		return fmt.Errorf("preparing statement: %w", err)
 	}
-	defer stmt.Close()
+	defer updateStmt.Close()
+
+	seen := make(map[int]bool, len(offenses))
+
+	var inserts [][]any
 
 	for _, record := range offenses {
-		var countryHint string
-		if record.VehicleInfo != nil {
-			countryHint = record.VehicleInfo.Country
-		}
-
-		info, _ := AnalyzeVehicleID(record.Vehicle, countryHint)
-
-		var vehicleType sql.NullString
-		if info.VehicleType != "" {
-			vehicleType.String = info.VehicleType
-			vehicleType.Valid = true
-		}
-
-		var offenseError sql.NullString
-		if record.Error != "" {
-			offenseError.String = record.Error
-			offenseError.Valid = true
-		}
-
-		var lng, lat any
-		if record.Point != nil {
-			lng = record.Point.Lng
-			lat = record.Point.Lat
-		}
-
-		_, err := stmt.Exec(
-			record.DbID,
-			record.DocID,
-			record.DocDate,
-			record.DocSource,
-			record.RecordID,
-			record.ID,
-			record.Vehicle,
-			nve(info.Country),
-			vehicleType,
-			record.Time,
-			record.Time, // For time_year extraction
-			nve(record.Location),
-			nve(record.DisplayLocation),
-			nve(record.Description),
-			record.UR,
-			offenseError,
-			lng,
-			lat,
-			nz(record.H3Res1),
-			nz(record.H3Res2),
-			nz(record.H3Res3),
-			nz(record.H3Res4),
-			nz(record.H3Res5),
-			nz(record.H3Res6),
-			nz(record.H3Res7),
-			nz(record.H3Res8),
-			record.ArticleIDs,
-			record.ArticleCodes,
+		values := offenseValues(record, r.vehicleOverrides)
+		hash := rowHash(values)
+		seen[record.RecordID] = true
+
+		old, ok := existing[record.RecordID]
+
+		switch {
+		case !ok:
+			inserts = append(inserts, append(values, hash))
+		case !old.Valid || old.Int64 != hash:
+			values = append(values, hash, docSource, record.RecordID)
+			if _, err := updateStmt.Exec(values...); err != nil {
+				return fmt.Errorf("updating record %d for %s: %w", record.RecordID, docSource, err)
+			}
+		}
+	}
+
+	if err := insertOffenses(conn, inserts); err != nil {
+		return fmt.Errorf("inserting records for %s: %w", docSource, err)
+	}
+
+	for recordID := range existing {
+		if seen[recordID] {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			"DELETE FROM offenses WHERE doc_source = ? AND record_id = ?", docSource, recordID,
+		); err != nil {
+			return fmt.Errorf("deleting record %d for %s: %w", recordID, docSource, err)
+		}
+	}
+
+	if err := setAppealDeadline(tx, docSource, offenses[0].DocDate); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO document_extractions (doc_source, db_id, extractor_version, extracted_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (doc_source) DO UPDATE SET
+			extractor_version = excluded.extractor_version,
+			extracted_at = excluded.extracted_at
+	`, docSource, offenses[0].DbID, ExtractorVersion, time.Now()); err != nil {
+		return fmt.Errorf("recording extractor version of %s: %w", docSource, err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM document_failures WHERE doc_source = ?", docSource); err != nil {
+		return fmt.Errorf("clearing failure of %s: %w", docSource, err)
+	}
+
+	if _, err := linkVersions(tx, docSource); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// linkVersions marks the offenses of every version of the document except the
+// latest as superseded by the latest one.
+func linkVersions(tx *sql.Tx, docSource string) (int64, error) {
+	base, _ := documentVersion(docSource)
+
+	rows, err := tx.Query(
+		"SELECT DISTINCT doc_source FROM offenses WHERE doc_source = ? OR starts_with(doc_source, ?)",
+		base, base+"_",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("querying versions of %s: %w", base, err)
+	}
+
+	var versions []string
+
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning version of %s: %w", base, err)
+		}
+
+		if b, _ := documentVersion(v); b == base {
+			versions = append(versions, v)
+		}
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating versions of %s: %w", base, err)
+	}
+
+	latest := latestVersion(versions)
+
+	var total int64
+
+	for _, v := range versions {
+		var supersededBy any
+		if v != latest {
+			supersededBy = latest
+		}
+
+		res, err := tx.Exec(
+			"UPDATE offenses SET superseded_by = ? WHERE doc_source = ? AND superseded_by IS DISTINCT FROM ?",
+			supersededBy, v, supersededBy,
 		)
 		if err != nil {
-			return fmt.Errorf("inserting record for %s: %w", docSource, err)
+			return total, fmt.Errorf("linking %s to %s: %w", v, latest, err)
 		}
+
+		n, _ := res.RowsAffected()
+		total += n
 	}
 
-	return tx.Commit()
+	return total, nil
+}
+
+func (r *sqlOffenseRepository) LinkRepublishedDocuments() (int64, error) {
+	rows, err := r.db.Query("SELECT DISTINCT doc_source FROM offenses WHERE regexp_matches(doc_source, '_[A-Z]$')")
+	if err != nil {
+		return 0, fmt.Errorf("querying re-published documents: %w", err)
+	}
+
+	var republished []string
+
+	for rows.Next() {
+		var docSource string
+		if err := rows.Scan(&docSource); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning re-published document: %w", err)
+		}
+
+		republished = append(republished, docSource)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating re-published documents: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	var total int64
+
+	for _, docSource := range republished {
+		n, err := linkVersions(tx, docSource)
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+	}
+
+	return total, tx.Commit()
+}
+
+// existingRowHashes returns the row hash of every stored record of a document.
+// Rows written before hashes existed have a NULL hash and are always updated.
+func existingRowHashes(tx *sql.Tx, docSource string) (map[int]sql.NullInt64, error) {
+	rows, err := tx.Query("SELECT record_id, row_hash FROM offenses WHERE doc_source = ?", docSource)
+	if err != nil {
+		return nil, fmt.Errorf("querying records for %s: %w", docSource, err)
+	}
+	defer rows.Close()
+
+	ret := make(map[int]sql.NullInt64)
+
+	for rows.Next() {
+		var recordID int
+
+		var hash sql.NullInt64
+		if err := rows.Scan(&recordID, &hash); err != nil {
+			return nil, fmt.Errorf("scanning record for %s: %w", docSource, err)
+		}
+
+		ret[recordID] = hash
+	}
+
+	return ret, rows.Err()
 }
 
 func (r *sqlOffenseRepository) BackfillGeocodingData() (int64, error) {
-	var n int64
+	// the locations judged as the location rules leave them
+	n, err := r.backfillCleanedLocations()
+	if err != nil {
+		return n, err
+	}
+
+	// the intersections judged with their streets in another order
+	mirrored, err := r.backfillMirroredIntersections()
+	n += mirrored
+
+	if err != nil {
+		return n, err
+	}
 
 	for _, q := range []string{
 		// first we apply the canonical names
@@ -452,14 +1206,15 @@ func (r *sqlOffenseRepository) BackfillGeocodingData() (int64, error) {
 		UPDATE offenses
 		SET
 			location = lj.canonical_location,
-			display_location = lj.location
+			display_location = COALESCE(offenses.display_location, lj.location)
 		FROM
 			locations lj
 		WHERE
 		        lj.canonical_location IS NOT NULL
 			AND offenses.db_id = lj.db_id
 			AND offenses.location = lj.location
-			AND offenses.display_location IS NULL
+			-- renamed streets keep the location as written
+			AND offenses.location != lj.canonical_location
 		`,
 		// then we apply the geocoding information
 		`
@@ -473,7 +1228,9 @@ func (r *sqlOffenseRepository) BackfillGeocodingData() (int64, error) {
 				h3_res5 = lj.h3_res5,
 				h3_res6 = lj.h3_res6,
 				h3_res7 = lj.h3_res7,
-				h3_res8 = lj.h3_res8
+				h3_res8 = lj.h3_res8,
+				h3_res9 = lj.h3_res9,
+				h3_res10 = lj.h3_res10
 			FROM
 				locations lj
 			WHERE
@@ -508,12 +1265,14 @@ func (r *sqlOffenseRepository) BackportDescriptionArticles() (int64, error) {
 		UPDATE offenses
 		SET
 			article_ids = d.article_ids,
-			article_codes = d.article_codes
+			article_codes = d.article_codes,
+			-- split by applyURRules below
+			ur_article = NULL
 		FROM descriptions d
 		WHERE
 			offenses.article_ids IS NULL
 			AND offenses.description IS NOT NULL
-			AND offenses.description = d.description
+			AND ` + utils.DescriptionKeyExpr("offenses.description") + ` = d.description_key
 		`,
 	}
 
@@ -539,6 +1298,22 @@ func (r *sqlOffenseRepository) BackportDescriptionArticles() (int64, error) {
 
 	totalRowsAffected += multiAffected
 
+	// 3. Pick the version of the renumbered articles in force at each offense
+	vigencyAffected, err := r.applyArticleVigency()
+	if err != nil {
+		return totalRowsAffected, fmt.Errorf("applying article vigency: %w", err)
+	}
+
+	totalRowsAffected += vigencyAffected
+
+	// 4. Split the UR of the rows among the articles just resolved
+	urAffected, err := r.applyURRules()
+	if err != nil {
+		return totalRowsAffected, err
+	}
+
+	totalRowsAffected += urAffected
+
 	return totalRowsAffected, nil
 }
 
@@ -581,12 +1356,13 @@ func (r *sqlOffenseRepository) backportMultiArticleDescriptions() (int64, error)
 	}
 
 	// 2. Get pending multi-article descriptions
-	// We fetch descriptions that contain a comma and are not yet backported.
+	// We fetch descriptions that are not yet backported and keep the ones
+	// with separators.
 	pendingQuery := `
 		SELECT DISTINCT description
 		FROM offenses
 		WHERE article_ids IS NULL
-		AND description LIKE '%,%'
+		AND description IS NOT NULL
 	`
 
 	pendingRows, err := r.db.Query(pendingQuery)
@@ -604,7 +1380,9 @@ func (r *sqlOffenseRepository) backportMultiArticleDescriptions() (int64, error)
 			return 0, fmt.Errorf("scanning pending description: %w", err)
 		}
 
-		pending = append(pending, desc)
+		if utils.DefaultTokenizer.HasSeparator(desc) {
+			pending = append(pending, desc)
+		}
 	}
 
 	// 3. Process each pending description
@@ -612,7 +1390,7 @@ func (r *sqlOffenseRepository) backportMultiArticleDescriptions() (int64, error)
 
 	updateQuery := `
 		UPDATE offenses
-		SET article_ids = ?, article_codes = ?
+		SET article_ids = ?, article_codes = ?, ur_article = NULL
 		WHERE description = ?
 	`
 
@@ -5,15 +5,17 @@ package impo
 
 import (
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
 	_ "github.com/duckdb/duckdb-go/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
 )
 
-func setupTestDB(t *testing.T) *sql.DB {
+func setupTestDB(t testing.TB) *sql.DB {
 	db, err := sql.Open("duckdb", "")
 	require.NoError(t, err)
 
@@ -112,6 +114,49 @@ func TestSQLRepository_GetExtractedDocuments(t *testing.T) {
 	assert.True(t, docs["doc3"])
 }
 
+func TestSQLRepository_GetStaleDocuments(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	now := time.Now().UTC()
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{{
+		DbID:     45,
+		Document: &Document{DocSource: "current", DocID: "1/025", DocDate: now},
+		RecordID: 1,
+		Vehicle:  "BAA1234",
+		Time:     now,
+	}}))
+
+	// extracted before versions were tracked, and by an older extractor
+	_, err := db.Exec(`
+		INSERT INTO offenses (db_id, doc_source, record_id) VALUES (45, 'untracked', 1), (45, 'old', 1), (46, 'other', 1);
+		INSERT INTO document_extractions VALUES ('old', 45, 0, now());
+	`)
+	require.NoError(t, err)
+
+	docs, err := repo.GetStaleDocuments(&DbReference{ID: 45}, ExtractorVersion)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old", "untracked"}, docs)
+
+	docs, err = repo.GetStaleDocuments(&DbReference{ID: 45}, ExtractorVersion+1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"current", "old", "untracked"}, docs)
+
+	var version int
+	require.NoError(t, db.QueryRow("SELECT extractor_version FROM offenses WHERE doc_source = 'current'").Scan(&version))
+	assert.Equal(t, ExtractorVersion, version)
+
+	// a single row of an older extractor is enough to re-extract the document
+	_, err = db.Exec("INSERT INTO offenses (db_id, doc_source, record_id, extractor_version) VALUES (45, 'current', 2, 0)")
+	require.NoError(t, err)
+
+	docs, err = repo.GetStaleDocuments(&DbReference{ID: 45}, ExtractorVersion)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"current", "old", "untracked"}, docs)
+}
+
 func TestSQLRepository_SaveTrafficOffenses_H3Nulls(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -142,3 +187,342 @@ func TestSQLRepository_SaveTrafficOffenses_H3Nulls(t *testing.T) {
 
 	assert.False(t, h3Res1.Valid, "h3_res1 should be NULL")
 }
+
+func TestSQLRepository_SaveMeta(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	require.NoError(t, repo.SaveMeta(map[string]string{"build_version": "v1", "build_commit": "abc"}))
+	require.NoError(t, repo.SaveMeta(map[string]string{"build_version": "v2"}))
+
+	var version, commit string
+	require.NoError(t, db.QueryRow("SELECT value FROM meta WHERE key = 'build_version'").Scan(&version))
+	require.NoError(t, db.QueryRow("SELECT value FROM meta WHERE key = 'build_commit'").Scan(&commit))
+	assert.Equal(t, "v2", version)
+	assert.Equal(t, "abc", commit)
+}
+
+func TestSQLRepository_MigrateURResolution(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// a database of before the UR resolution was recorded
+	_, err := db.Exec(`
+		DELETE FROM meta WHERE key = 'ur_resolution';
+		INSERT INTO offenses (db_id, doc_source, record_id, ur) VALUES (45, 'a', 1, 250), (45, 'a', 2, NULL);
+		CREATE TABLE ur_outliers (ur INTEGER, median DOUBLE, p05 DOUBLE, p95 DOUBLE);
+		INSERT INTO ur_outliers VALUES (5000, 500, 100, 1000);
+	`)
+	require.NoError(t, err)
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	// migrated once
+	for range 2 {
+		require.NoError(t, repo.CreateSchema())
+	}
+
+	var ur sql.NullInt64
+	require.NoError(t, db.QueryRow("SELECT ur FROM offenses WHERE record_id = 1").Scan(&ur))
+	assert.Equal(t, "2.5", UR(ur.Int64).String())
+	require.NoError(t, db.QueryRow("SELECT ur FROM offenses WHERE record_id = 2").Scan(&ur))
+	assert.False(t, ur.Valid)
+
+	var median float64
+	require.NoError(t, db.QueryRow("SELECT ur, median FROM ur_outliers").Scan(&ur, &median))
+	assert.Equal(t, int64(50000), ur.Int64)
+	assert.InDelta(t, 5000.0, median, 0.001)
+
+	var resolution string
+	require.NoError(t, db.QueryRow("SELECT value FROM meta WHERE key = ?", URResolutionMetaKey).Scan(&resolution))
+	assert.Equal(t, "1000", resolution)
+}
+
+func TestSQLRepository_SaveTrafficOffenses_Upsert(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	now := time.Now().UTC()
+	doc := &Document{DocSource: "doc_upsert", DocID: "1/025", DocDate: now}
+	offense := func(recordID int, vehicle string) *TrafficOffense {
+		return &TrafficOffense{
+			DbID:     45,
+			Document: doc,
+			RecordID: recordID,
+			Vehicle:  vehicle,
+			Time:     now,
+		}
+	}
+
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{
+		offense(1, "AAA1111"),
+		offense(2, "BBB2222"),
+		offense(3, "CCC3333"),
+	}))
+
+	var hash1 int64
+	require.NoError(t, db.QueryRow("SELECT row_hash FROM offenses WHERE record_id = 1").Scan(&hash1))
+
+	// record 2 changes, record 3 disappears from the document
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{
+		offense(1, "AAA1111"),
+		offense(2, "BBB2223"),
+	}))
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM offenses WHERE doc_source = 'doc_upsert'").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	var vehicle string
+	require.NoError(t, db.QueryRow("SELECT vehicle FROM offenses WHERE record_id = 2").Scan(&vehicle))
+	assert.Equal(t, "BBB2223", vehicle)
+
+	var hash1After int64
+	require.NoError(t, db.QueryRow("SELECT row_hash FROM offenses WHERE record_id = 1").Scan(&hash1After))
+	assert.Equal(t, hash1, hash1After)
+}
+
+func TestSQLRepository_SaveTrafficOffenses_Republished(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	now := time.Now().UTC()
+	save := func(docSource string) {
+		require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{{
+			DbID:     45,
+			Document: &Document{DocSource: docSource, DocID: "37/025", DocDate: now},
+			RecordID: 1,
+			Vehicle:  "AAA1111",
+			Time:     now,
+		}}))
+	}
+
+	save("37-2025")
+	save("37-2025_B")
+	save("37-2025_A")
+	save("137-2025")
+
+	supersededBy := func(docSource string) sql.NullString {
+		var s sql.NullString
+		require.NoError(t, db.QueryRow(
+			"SELECT superseded_by FROM offenses WHERE doc_source = ?", docSource).Scan(&s))
+
+		return s
+	}
+
+	assert.Equal(t, "37-2025_B", supersededBy("37-2025").String)
+	assert.Equal(t, "37-2025_B", supersededBy("37-2025_A").String)
+	assert.False(t, supersededBy("37-2025_B").Valid)
+	assert.False(t, supersededBy("137-2025").Valid)
+
+	var active int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM active_offenses").Scan(&active))
+	assert.Equal(t, 2, active)
+
+	_, err := db.Exec("UPDATE offenses SET superseded_by = NULL")
+	require.NoError(t, err)
+
+	n, err := repo.LinkRepublishedDocuments()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, "37-2025_B", supersededBy("37-2025").String)
+}
+
+func TestSQLRepository_AppealDeadlines(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo, _ := NewSQLOffenseRepository(db)
+
+	published := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SaveTrafficOffenses([]*TrafficOffense{{
+		DbID:     45,
+		Document: &Document{DocSource: "doc_appeal", DocID: "1/025", DocDate: published},
+		RecordID: 1,
+		Vehicle:  "AAA1111",
+		Time:     published,
+	}}))
+
+	var deadline time.Time
+	require.NoError(t, db.QueryRow(
+		"SELECT appeal_deadline FROM offenses WHERE doc_source = 'doc_appeal'").Scan(&deadline))
+	assert.Equal(t, "2025-06-16", deadline.Format(time.DateOnly))
+
+	open, err := ListOpenAppeals(db, time.Date(2025, time.June, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, open, 1)
+
+	open, err = ListOpenAppeals(db, time.Date(2025, time.June, 17, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestSQLRepository_EnrichLocationRules(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE locations (
+			db_id INTEGER, location VARCHAR, canonical_location VARCHAR, point POINT_2D,
+			h3_res1 UBIGINT, h3_res2 UBIGINT, h3_res3 UBIGINT, h3_res4 UBIGINT,
+			h3_res5 UBIGINT, h3_res6 UBIGINT, h3_res7 UBIGINT, h3_res8 UBIGINT,
+			h3_res9 UBIGINT, h3_res10 UBIGINT
+		);
+		INSERT INTO locations VALUES
+			(56, '18 DE JULIO 250', '18 DE JULIO 250', ST_Point(-55.98, -31.71), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+			(6, '18 DE JULIO 250', '18 DE JULIO 250', ST_Point(-56.19, -34.90), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10);
+	`)
+	require.NoError(t, err)
+
+	repo := &sqlOffenseRepository{db: db}
+	require.NoError(t, repo.loadLocationCache())
+
+	// Tacuarembó's rules clean up the wording, so the judgment applies
+	o := &TrafficOffense{DbID: 56, Location: "18 DE JULIO FRENTE AL N° 250"}
+	repo.enrichOffense(o)
+	require.NotNil(t, o.Point)
+	assert.InDelta(t, -31.71, o.Point.Lat, 1e-9)
+	assert.Equal(t, uint64(10), o.H3Res10)
+	assert.Equal(t, "18 DE JULIO 250", o.Location)
+	assert.Equal(t, "18 DE JULIO FRENTE AL N° 250", o.DisplayLocation)
+
+	// Montevideo has no such rule
+	o = &TrafficOffense{DbID: 6, Location: "18 DE JULIO FRENTE AL N° 250"}
+	repo.enrichOffense(o)
+	assert.Nil(t, o.Point)
+}
+
+func TestSQLRepository_EnrichUnmergedLocations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE locations (
+			db_id INTEGER, location VARCHAR, canonical_location VARCHAR, point POINT_2D,
+			h3_res1 UBIGINT, h3_res2 UBIGINT, h3_res3 UBIGINT, h3_res4 UBIGINT,
+			h3_res5 UBIGINT, h3_res6 UBIGINT, h3_res7 UBIGINT, h3_res8 UBIGINT,
+			h3_res9 UBIGINT, h3_res10 UBIGINT
+		);
+		INSERT INTO locations VALUES
+			(6, 'RIVERA Y SOCA', NULL, ST_Point(-56.16, -34.90), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10),
+			(6, 'AV RIVERA Y SOCA', 'RIVERA Y SOCA', ST_Point(-56.16, -34.90), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10);
+	`)
+	require.NoError(t, err)
+
+	repo := &sqlOffenseRepository{db: db}
+	require.NoError(t, repo.loadLocationCache())
+
+	// judged but never merged: gets the point, keeps its text
+	o := &TrafficOffense{DbID: 6, Location: "RIVERA Y SOCA"}
+	repo.enrichOffense(o)
+	require.NotNil(t, o.Point)
+	assert.InDelta(t, -34.90, o.Point.Lat, 1e-9)
+	assert.Equal(t, "RIVERA Y SOCA", o.Location)
+	assert.Empty(t, o.DisplayLocation)
+
+	o = &TrafficOffense{DbID: 6, Location: "AV RIVERA Y SOCA"}
+	repo.enrichOffense(o)
+	require.NotNil(t, o.Point)
+	assert.Equal(t, "RIVERA Y SOCA", o.Location)
+	assert.Equal(t, "AV RIVERA Y SOCA", o.DisplayLocation)
+}
+
+// insertOffensesByRow is how insertOffenses used to work, a statement per row,
+// kept to compare with.
+func insertOffensesByRow(tx *sql.Tx, rows [][]any) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO offenses (
+			db_id, doc_id, doc_date, doc_source, record_id, offense_id,
+			vehicle, vehicle_country, vehicle_type, time, time_year, location, display_location, description, ur, error,
+			error_code, point,
+			h3_res1, h3_res2, h3_res3, h3_res4, h3_res5, h3_res6, h3_res7, h3_res8, h3_res9, h3_res10,
+			article_ids, article_codes, vehicle_foreign, raw, extractor_version, ur_article,
+			processed_at, vehicle_category, vehicle_overridden, row_hash
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, EXTRACT(YEAR FROM ?::TIMESTAMPTZ), ?, ?, ?, ?, ?, ?, ST_Point(?, ?),
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, values := range rows {
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BenchmarkInsertOffenses inserts the rows of a bulk document, with a
+// statement per row and with the appender.
+func BenchmarkInsertOffenses(b *testing.B) {
+	n, err := html.Parse(strings.NewReader(SyntheticDocument(BenchRows)))
+	require.NoError(b, err)
+
+	offenses, err := ExtractDocument(nil, "", n)
+	require.NoError(b, err)
+
+	rows := make([][]any, 0, len(offenses))
+
+	for _, o := range offenses {
+		o.DbID = 45
+		o.DocSource = "bench"
+		values := offenseValues(o, nil)
+		rows = append(rows, append(values, rowHash(values)))
+	}
+
+	db := setupTestDB(b)
+	defer db.Close()
+
+	for name, insert := range map[string]func(*sql.Conn, *sql.Tx) error{
+		"statements": func(_ *sql.Conn, tx *sql.Tx) error { return insertOffensesByRow(tx, rows) },
+		"appender":   func(conn *sql.Conn, _ *sql.Tx) error { return insertOffenses(conn, rows) },
+	} {
+		b.Run(name, func(b *testing.B) {
+			conn, err := db.Conn(b.Context())
+			require.NoError(b, err)
+
+			defer conn.Close()
+
+			for b.Loop() {
+				tx, err := conn.BeginTx(b.Context(), nil)
+				require.NoError(b, err)
+				require.NoError(b, insert(conn, tx))
+				require.NoError(b, tx.Rollback())
+			}
+		})
+	}
+}
+
+func TestInsertOffenses_Rollback(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	o := &TrafficOffense{Document: &Document{DocSource: "doc_rollback"}, DbID: 45, RecordID: 1, UR: 100}
+	values := offenseValues(o, nil)
+
+	conn, err := db.Conn(t.Context())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	// the appender writes within the transaction of the connection
+	tx, err := conn.BeginTx(t.Context(), nil)
+	require.NoError(t, err)
+	require.NoError(t, insertOffenses(conn, [][]any{append(values, rowHash(values))}))
+	require.NoError(t, tx.Rollback())
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM offenses").Scan(&count))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+)
+
+// RunReportFile is the name of the report of the last `impo update` written
+// next to the database, read by the pipeline to build its manifest.
+const RunReportFile = "run_report.json"
+
+// RunMetrics are the totals of the phases of a run.
+type RunMetrics struct {
+	SearchPages          int `json:"search_pages"`
+	SearchRecords        int `json:"search_records"`
+	SearchStored         int `json:"search_stored"`
+	DownloadsOk          int `json:"downloads_ok"`
+	DownloadsErr         int `json:"downloads_err"`
+	DownloadsNotModified int `json:"downloads_not_modified"`
+	DownloadsChanged     int `json:"downloads_changed"`
+	NewRecords           int `json:"new_records"`
+	NewErrors            int `json:"new_errors"`
+	SuccessfulDocs       int `json:"successful_docs"`
+	FailedDocs           int `json:"failed_docs"`
+	UnknownHeaders       int `json:"unknown_headers"`
+	UnknownValues        int `json:"unknown_values"`
+	ErrorRateAlarms      int `json:"error_rate_alarms"`
+}
+
+// NewRunMetrics summarizes the metrics of the clients of a run.
+func NewRunMetrics(m *ClientMetrics) RunMetrics {
+	return RunMetrics{
+		SearchPages:          m.SearchPages,
+		SearchRecords:        m.SearchTotalRecords,
+		SearchStored:         m.SearchTotalStored,
+		DownloadsOk:          m.DownloadsOk,
+		DownloadsErr:         m.DownloadsErr,
+		DownloadsNotModified: m.DownloadsNotModified,
+		DownloadsChanged:     m.DownloadsChanged,
+		NewRecords:           m.NewRecords,
+		NewErrors:            m.NewErrors,
+		SuccessfulDocs:       m.SuccessfulDocs,
+		FailedDocs:           m.FailedDocs,
+		UnknownHeaders:       len(m.UnknownHeaders),
+		UnknownValues:        len(m.UnknownValues),
+		ErrorRateAlarms:      len(m.ErrorRateAlarms),
+	}
+}
+
+// RunReport is what an `impo update` did and the database it left.
+type RunReport struct {
+	StartedAt       time.Time        `json:"started_at"`
+	FinishedAt      time.Time        `json:"finished_at"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	Metrics         RunMetrics       `json:"metrics"`
+	Rows            map[string]int64 `json:"rows"` // by table
+}
+
+// WriteRunReport writes the report of a run that started at started to path,
+// counting the rows of the tables of the database.
+func WriteRunReport(db *sql.DB, path string, m *ClientMetrics, started, now time.Time) error {
+	rows, err := dbutils.RowCounts(db)
+	if err != nil {
+		return err
+	}
+
+	r := RunReport{
+		StartedAt:       started,
+		FinishedAt:      now,
+		DurationSeconds: now.Sub(started).Seconds(),
+		Metrics:         NewRunMetrics(m),
+		Rows:            rows,
+	}
+
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding run report: %w", err)
+	}
+
+	// #nosec G306 - public data, shipped with the database
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("writing run report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRunReport(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE offenses AS SELECT range AS id FROM range(3)")
+	require.NoError(t, err)
+
+	var m ClientMetrics
+	m.NewRecords, m.DownloadsOk = 3, 2
+	m.UnknownValues = []UnknownValue{{DocSource: "doc", Kind: ValueKindCountry, Value: "Narnia"}}
+
+	started := time.Date(2025, 12, 18, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), RunReportFile)
+	require.NoError(t, WriteRunReport(db, path, &m, started, started.Add(90*time.Second)))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var r RunReport
+	require.NoError(t, json.Unmarshal(b, &r))
+	assert.InDelta(t, 90.0, r.DurationSeconds, 0.001)
+	assert.Equal(t, RunMetrics{DownloadsOk: 2, NewRecords: 3, UnknownValues: 1}, r.Metrics)
+	assert.Equal(t, map[string]int64{"offenses": 3}, r.Rows)
+}
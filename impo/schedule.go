@@ -0,0 +1,141 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cadenceSamples is the number of the last dates of publication the cadence
+// of a database is computed from.
+const cadenceSamples = 10
+
+// Schedule lets Update skip the search phase of the databases unlikely to
+// have published new documents since their last search: not every
+// department publishes daily. The documents pending from earlier runs are
+// downloaded anyway. A database is searched when its last
+// search is older than half of its cadence (see PublicationCadence), when
+// its cadence is unknown and, to catch the ones that changed their pace, on
+// FullPassDay, a day of Uruguay.
+type Schedule struct {
+	FullPassDay time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"domingo": time.Sunday, "lunes": time.Monday, "martes": time.Tuesday, "miercoles": time.Wednesday,
+	"miércoles": time.Wednesday, "jueves": time.Thursday, "viernes": time.Friday, "sabado": time.Saturday,
+	"sábado": time.Saturday,
+}
+
+// ParseWeekday parses the name of a day of the week, in English or Spanish.
+func ParseWeekday(s string) (time.Weekday, error) {
+	if d, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return d, nil
+	}
+
+	return 0, fmt.Errorf("unknown day of the week %q", s)
+}
+
+// PublicationCadence returns the median time between the dates of
+// publication, or zero with fewer than three of them.
+func PublicationCadence(dates []time.Time) time.Duration {
+	if len(dates) < 3 {
+		return 0
+	}
+
+	gaps := make([]time.Duration, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		gap := dates[i-1].Sub(dates[i])
+		if gap < 0 {
+			gap = -gap
+		}
+
+		gaps = append(gaps, gap)
+	}
+
+	slices.Sort(gaps)
+
+	return gaps[len(gaps)/2]
+}
+
+// due tells whether a database whose last search was at lastSearch, and that
+// publishes every cadence, has to be searched at now, and why.
+func (s *Schedule) due(now, lastSearch time.Time, cadence time.Duration) (bool, string) {
+	switch {
+	case s == nil:
+		return true, "no schedule"
+	case now.In(UruguayTimezone).Weekday() == s.FullPassDay:
+		return true, "full pass"
+	case lastSearch.IsZero():
+		return true, "never searched"
+	case cadence == 0:
+		return true, "unknown cadence"
+	case now.Sub(lastSearch) >= cadence/2:
+		return true, fmt.Sprintf("searched %s ago, publishes every %s", roundDays(now.Sub(lastSearch)), roundDays(cadence))
+	default:
+		return false, fmt.Sprintf("searched %s ago, publishes every %s", roundDays(now.Sub(lastSearch)), roundDays(cadence))
+	}
+}
+
+// roundDays formats a duration in days, or in hours when shorter.
+func roundDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return d.Round(time.Hour).String()
+	}
+
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+// searchDue tells whether the schedule lets the database be searched now.
+func (c *Client) searchDue(now time.Time) (bool, error) {
+	lastSearch, err := c.store.LastSearch()
+	if err != nil {
+		return false, err
+	}
+
+	dates, err := c.repo.ListPublicationDates(c.dbRef.ID, cadenceSamples)
+	if err != nil {
+		return false, err
+	}
+
+	due, reason := c.options.Schedule.due(now, lastSearch, PublicationCadence(dates))
+	if !due {
+		log.Printf("Skipping search phase by schedule, %s", reason)
+	}
+
+	return due, nil
+}
+
+func (r *sqlOffenseRepository) ListPublicationDates(dbID int, limit int) ([]time.Time, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT doc_date
+		FROM offenses
+		WHERE db_id = ? AND doc_date IS NOT NULL
+		ORDER BY doc_date DESC
+		LIMIT ?
+	`, dbID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying publication dates: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []time.Time
+
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			return nil, fmt.Errorf("scanning publication date: %w", err)
+		}
+
+		ret = append(ret, d)
+	}
+
+	return ret, rows.Err()
+}
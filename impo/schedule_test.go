@@ -0,0 +1,125 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWeekday(t *testing.T) {
+	for s, want := range map[string]time.Weekday{"sunday": time.Sunday, "Miércoles": time.Wednesday, " sabado ": time.Saturday} {
+		got, err := ParseWeekday(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	_, err := ParseWeekday("weekend")
+	assert.Error(t, err)
+}
+
+func TestPublicationCadence(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, time.June, d, 0, 0, 0, 0, time.UTC) }
+
+	assert.Equal(t, time.Duration(0), PublicationCadence([]time.Time{day(20), day(13)}))
+	assert.Equal(t, 7*24*time.Hour, PublicationCadence([]time.Time{day(29), day(22), day(15), day(8)}))
+	// an odd long gap doesn't change the pace
+	assert.Equal(t, 7*24*time.Hour, PublicationCadence([]time.Time{day(29), day(22), day(15), day(1)}))
+}
+
+func TestScheduleDue(t *testing.T) {
+	s := &Schedule{FullPassDay: time.Sunday}
+	week := 7 * 24 * time.Hour
+
+	// a Tuesday
+	now := time.Date(2025, time.June, 10, 3, 0, 0, 0, UruguayTimezone)
+
+	for _, tt := range []struct {
+		name       string
+		now        time.Time
+		lastSearch time.Time
+		cadence    time.Duration
+		want       bool
+	}{
+		{"searched yesterday, weekly", now, now.Add(-24 * time.Hour), week, false},
+		{"searched four days ago, weekly", now, now.Add(-4 * 24 * time.Hour), week, true},
+		{"daily", now, now.Add(-24 * time.Hour), 24 * time.Hour, true},
+		{"unknown cadence", now, now.Add(-24 * time.Hour), 0, true},
+		{"never searched", now, time.Time{}, week, true},
+		{"full pass", now.AddDate(0, 0, 5), now.AddDate(0, 0, 4), week, true},
+		// Sunday 22:00 in Uruguay, Monday in UTC
+		{"full pass in UTC", time.Date(2025, time.June, 16, 1, 0, 0, 0, time.UTC), now.AddDate(0, 0, 4), week, true},
+		// Saturday 22:00 in Uruguay, Sunday in UTC
+		{"Sunday in UTC", time.Date(2025, time.June, 15, 1, 0, 0, 0, time.UTC), now.AddDate(0, 0, 4), week, false},
+	} {
+		got, _ := s.due(tt.now, tt.lastSearch, tt.cadence)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+
+	got, _ := (*Schedule)(nil).due(now, now, week)
+	assert.True(t, got)
+}
+
+type datesRepository struct {
+	OffenseRepository
+	dates []time.Time
+}
+
+func (r *datesRepository) ListPublicationDates(_ int, _ int) ([]time.Time, error) {
+	return r.dates, nil
+}
+
+func TestClientSearchDue(t *testing.T) {
+	dbRef := &DbReference{ID: 45, Name: "Maldonado"}
+	now := time.Date(2025, time.June, 10, 3, 0, 0, 0, UruguayTimezone)
+	week := func(n int) time.Time { return now.AddDate(0, 0, -7*n) }
+
+	c := NewImpoClient(&ClientOptions{DbPath: t.TempDir(), Schedule: &Schedule{FullPassDay: time.Sunday}},
+		dbRef, &datesRepository{dates: []time.Time{week(1), week(2), week(3)}})
+
+	due, err := c.searchDue(now)
+	require.NoError(t, err)
+	assert.True(t, due, "never searched")
+
+	require.NoError(t, c.store.SetLastSearch(now.Add(-24*time.Hour)))
+
+	due, err = c.searchDue(now)
+	require.NoError(t, err)
+	assert.False(t, due, "searched yesterday")
+}
+
+func TestUpdate_ScheduleKeepsDownloads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer srv.Close()
+
+	dbRef, err := Find("canelones")
+	require.NoError(t, err)
+
+	now := time.Now()
+	week := func(n int) time.Time { return now.AddDate(0, 0, -7*n) }
+
+	c := NewImpoClient(&ClientOptions{
+		DbPath:      t.TempDir(),
+		Schedule:    &Schedule{FullPassDay: (now.In(UruguayTimezone).Weekday() + 1) % 7},
+		SkipExtract: true,
+	}, dbRef, &datesRepository{dates: []time.Time{week(1), week(2), week(3)}})
+
+	require.NoError(t, c.store.SetLastSearch(now.Add(-time.Hour)))
+
+	// pending from an earlier run
+	_, err = c.store.Upsert([]SearchResultEntry{{Href: srv.URL + "/bases/notificaciones-transito-canelones/1-2025"}}, false)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Update())
+	assert.Equal(t, 1, c.Metrics.DownloadsOk)
+	assert.Zero(t, c.Metrics.SearchPages)
+}
@@ -0,0 +1,264 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SchemaFile is the name of the schema description written next to the
+// database.
+const SchemaFile = "schema.json"
+
+// Provenance of the columns: where their values come from.
+const (
+	FromDocument = "document" // read from the IMPO document
+	FromDerived  = "derived"  // computed by chapa from other columns
+	FromCuration = "curation" // assigned by the curators
+	FromPipeline = "pipeline" // bookkeeping of the pipeline
+)
+
+// ColumnDescription describes a column of the published database.
+type ColumnDescription struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Provenance  string `json:"provenance,omitempty"`
+}
+
+// TableDescription describes a table or view of the published database.
+type TableDescription struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Columns     []ColumnDescription `json:"columns"`
+}
+
+// SchemaDescription is the machine-readable description of the published
+// database, so that its users don't have to guess what the columns mean.
+type SchemaDescription struct {
+	GeneratedAt      time.Time          `json:"generated_at"`
+	ExtractorVersion int                `json:"extractor_version"`
+	URResolution     int                `json:"ur_resolution"`
+	Tables           []TableDescription `json:"tables"`
+}
+
+type columnDoc struct {
+	description string
+	provenance  string
+}
+
+type tableDoc struct {
+	name        string
+	description string
+	like        string // table whose columns it shares
+	columns     map[string]columnDoc
+}
+
+// h3Doc is the documentation of the h3_resN columns.
+var h3Doc = columnDoc{"celda H3 del punto en la resolución N, para agregar en el mapa", FromDerived}
+
+// schemaDocs documents the tables of the published database, in the order
+// they are described. The types are taken from the database itself.
+var schemaDocs = []tableDoc{
+	{
+		name:        "offenses",
+		description: "Infracciones extraídas de los documentos publicados en el Diario Oficial, una por fila de sus tablas.",
+		columns: map[string]columnDoc{
+			"db_id":            {"base de IMPO (departamento o Policía Caminera) que publicó el documento", FromPipeline},
+			"doc_id":           {"número del documento, como 1234/025", FromDocument},
+			"doc_date":         {"fecha de publicación del documento", FromDocument},
+			"doc_source":       {"URL del documento en IMPO", FromDocument},
+			"record_id":        {"número de fila dentro del documento", FromPipeline},
+			"offense_id":       {"identificador de la infracción según la intendencia", FromDocument},
+			"vehicle":          {"matrícula normalizada, sin espacios y en mayúsculas", FromDocument},
+			"vehicle_country":  {"país de la matrícula según su formato (ISO 3166-1 alfa-2)", FromDerived},
+			"vehicle_type":     {"tipo de vehículo inferido de la matrícula o de vehicle_overrides.json", FromDerived},
+			"time":             {"fecha y hora de la infracción, hora de Uruguay", FromDocument},
+			"time_year":        {"año de time", FromDerived},
+			"location":         {"ubicación para agregar: la canónica de la curación o con el nombre actual de las calles si existe; si no, tal como figura en el documento", FromCuration},
+			"display_location": {"ubicación tal como figura en el documento cuando location la reemplazó; vacía si no", FromDocument},
+			"description":      {"descripción de la infracción tal como figura en el documento", FromDocument},
+			"ur": {
+				fmt.Sprintf("multa en Unidades Reajustables, multiplicada por %d (ver ur_resolution)", URResolution),
+				FromDocument,
+			},
+			"ur_article": {
+				"multa de cada uno de los artículos de la fila, lo que suman las agregaciones por artículo (ver ur_rules.json)",
+				FromDerived,
+			},
+			"error":           {"motivo por el que la fila no se pudo extraer correctamente", FromPipeline},
+			"error_code":      {"código del error, para agregarlos", FromPipeline},
+			"point":           {"punto geocodificado de la ubicación (x longitud, y latitud)", FromCuration},
+			"h3_res1":         h3Doc,
+			"h3_res2":         h3Doc,
+			"h3_res3":         h3Doc,
+			"h3_res4":         h3Doc,
+			"h3_res5":         h3Doc,
+			"h3_res6":         h3Doc,
+			"h3_res7":         h3Doc,
+			"h3_res8":         h3Doc,
+			"h3_res9":         h3Doc,
+			"h3_res10":        h3Doc,
+			"article_ids":     {"artículos del Texto Ordenado del SUCIVE asignados a la descripción", FromCuration},
+			"article_codes":   {"códigos numéricos de article_ids, en el mismo orden", FromCuration},
+			"row_hash":        {"hash del contenido de la fila, para reconocer las republicadas", FromDerived},
+			"superseded_by":   {"documento que republicó la fila; vacío si sigue vigente", FromDerived},
+			"appeal_deadline": {"último día para presentar descargos", FromDerived},
+			"vehicle_foreign": {"el documento marca la matrícula como extranjera", FromDocument},
+			"vehicle_category": {
+				"categoría de la matrícula (oficial, taxi, ómnibus de turismo...), de sus patrones o de vehicle_overrides.json",
+				FromDerived,
+			},
+			"vehicle_overridden": {"una excepción de vehicle_overrides.json fijó la categoría o el tipo de vehículo", FromCuration},
+			"raw":                {"celdas originales de la fila, solo con --keep-raw", FromDocument},
+			"extractor_version":  {"versión del extractor que generó la fila", FromPipeline},
+			"nearest_school_m":   {"metros a la zona escolar más cercana al punto, NULL mientras no haya una capa de zonas escolares (la incluida está vacía)", FromDerived},
+			"nearest_radar_m":    {"metros al radar fijo más cercano al punto", FromDerived},
+			"processed_at":       {"fecha en que la autoridad procesó la infracción (\"Fecha Ingreso\"), ver time_rules.json", FromDocument},
+		},
+	},
+	{
+		name:        "active_offenses",
+		description: "Las infracciones de offenses que no fueron republicadas; la que deben usar los análisis.",
+		like:        "offenses",
+	},
+	{
+		name:        "articles",
+		description: "Artículos del Texto Ordenado del SUCIVE con los que se clasifican las infracciones.",
+		columns: map[string]columnDoc{
+			"id":             {"número del artículo, como 18.3", FromCuration},
+			"text":           {"texto del artículo", FromCuration},
+			"code":           {"código numérico del artículo", FromCuration},
+			"title":          {"título corto del artículo", FromCuration},
+			"effective_from": {"primer día de vigencia de esta versión del artículo", FromCuration},
+			"effective_to":   {"último día de vigencia de esta versión del artículo", FromCuration},
+			"replaced_by":    {"artículo que lo reemplazó", FromCuration},
+		},
+	},
+	{
+		name:        "locations",
+		description: "Ubicaciones de las infracciones geocodificadas por base.",
+		columns: map[string]columnDoc{
+			"id":                 {"identificador de la ubicación", FromPipeline},
+			"db_id":              {"base de IMPO de las infracciones", FromPipeline},
+			"location":           {"ubicación tal como figura en los documentos", FromDocument},
+			"canonical_location": {"nombre curado de la ubicación", FromCuration},
+			"point":              {"punto geocodificado (x longitud, y latitud)", FromCuration},
+			"is_electronic":      {"la ubicación es un radar o cámara", FromCuration},
+			"geocoding_method":   {"origen del punto: radares_rutas, google_maps, manual, etc.", FromCuration},
+			"confidence":         {"confianza en el punto: high, medium o low", FromCuration},
+			"notes":              {"notas de los curadores", FromCuration},
+			"created_at":         {"alta de la ubicación", FromPipeline},
+			"updated_at":         {"última modificación de la ubicación", FromPipeline},
+			"h3_res1":            h3Doc,
+			"h3_res2":            h3Doc,
+			"h3_res3":            h3Doc,
+			"h3_res4":            h3Doc,
+			"h3_res5":            h3Doc,
+			"h3_res6":            h3Doc,
+			"h3_res7":            h3Doc,
+			"h3_res8":            h3Doc,
+			"h3_res9":            h3Doc,
+			"h3_res10":           h3Doc,
+		},
+	},
+	{
+		name:        "document_extractions",
+		description: "Versión del extractor que procesó cada documento.",
+		columns: map[string]columnDoc{
+			"doc_source":        {"URL del documento en IMPO", FromDocument},
+			"db_id":             {"base de IMPO del documento", FromPipeline},
+			"extractor_version": {"versión del extractor", FromPipeline},
+			"extracted_at":      {"momento de la extracción", FromPipeline},
+		},
+	},
+	{
+		name:        "meta",
+		description: "Datos de la construcción de la base, como la versión de chapa y ur_resolution.",
+		columns: map[string]columnDoc{
+			"key":        {"nombre del dato", FromPipeline},
+			"value":      {"valor del dato", FromPipeline},
+			"updated_at": {"última actualización", FromPipeline},
+		},
+	},
+}
+
+// DescribeSchema documents the tables of schemaDocs found in the database,
+// with the types of their columns.
+func DescribeSchema(db *sql.DB, now time.Time) (*SchemaDescription, error) {
+	s := &SchemaDescription{GeneratedAt: now, ExtractorVersion: ExtractorVersion, URResolution: URResolution}
+
+	docs := make(map[string]tableDoc, len(schemaDocs))
+	for _, t := range schemaDocs {
+		docs[t.name] = t
+	}
+
+	for _, t := range schemaDocs {
+		columns := t.columns
+		if t.like != "" {
+			columns = docs[t.like].columns
+		}
+
+		rows, err := db.Query(`
+			SELECT column_name, data_type
+			FROM information_schema.columns
+			WHERE table_schema = 'main' AND table_name = ?
+			ORDER BY ordinal_position
+		`, t.name)
+		if err != nil {
+			return nil, fmt.Errorf("querying columns of %s: %w", t.name, err)
+		}
+
+		table := TableDescription{Name: t.name, Description: t.description}
+
+		for rows.Next() {
+			var c ColumnDescription
+			if err := rows.Scan(&c.Name, &c.Type); err != nil {
+				rows.Close()
+
+				return nil, fmt.Errorf("scanning columns of %s: %w", t.name, err)
+			}
+
+			c.Description, c.Provenance = columns[c.Name].description, columns[c.Name].provenance
+			table.Columns = append(table.Columns, c)
+		}
+
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		// the tables of other phases may be missing, e.g. without curation
+		if len(table.Columns) > 0 {
+			s.Tables = append(s.Tables, table)
+		}
+	}
+
+	return s, nil
+}
+
+// WriteSchemaDescription describes the schema and writes it as JSON to path.
+func WriteSchemaDescription(db *sql.DB, path string, now time.Time) error {
+	s, err := DescribeSchema(db, now)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding schema: %w", err)
+	}
+
+	// #nosec G306 - public data, shipped with the database
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("writing schema: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRepository_DescribeSchema(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), SchemaFile)
+	require.NoError(t, WriteSchemaDescription(db, path, time.Now()))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var s SchemaDescription
+	require.NoError(t, json.Unmarshal(b, &s))
+	assert.Equal(t, URResolution, s.URResolution)
+
+	tables := make(map[string]TableDescription)
+	for _, table := range s.Tables {
+		tables[table.Name] = table
+	}
+
+	// curation tables aren't created by the extraction
+	assert.NotContains(t, tables, "locations")
+
+	// every column of the offenses must be documented
+	for _, name := range []string{"offenses", "active_offenses", "document_extractions", "meta"} {
+		require.Contains(t, tables, name)
+
+		for _, c := range tables[name].Columns {
+			assert.NotEmpty(t, c.Type, "%s.%s", name, c.Name)
+			assert.NotEmpty(t, c.Description, "%s.%s", name, c.Name)
+			assert.NotEmpty(t, c.Provenance, "%s.%s", name, c.Name)
+		}
+	}
+
+	assert.Equal(t, len(tables["offenses"].Columns), len(tables["active_offenses"].Columns))
+}
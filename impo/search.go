@@ -7,13 +7,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jcodagnone/chapauy/utils/htmlutils"
+	"github.com/jcodagnone/chapauy/utils/progress"
 	"golang.org/x/net/html"
 )
 
@@ -126,8 +127,49 @@ func (c *Client) signIn() error {
 	return err
 }
 
-// fetches a single page of search results from the IMPO database.
-func (c *Client) retrieveSearchPage(page string) (*SearchResults, error) {
+// searchDateLayout is the date format of the IMPO search form.
+const searchDateLayout = "02/01/2006"
+
+// searchForm returns the form of the first search page. When since is not
+// zero only documents published on or after that date are requested.
+func (c *Client) searchForm(since time.Time) url.Values {
+	var fechadiar1 string
+	if !since.IsZero() {
+		fechadiar1 = since.Format(searchDateLayout)
+	}
+
+	return url.Values{
+		"realizarconsulta":       {"SI"},
+		"nuevaconsulta":          {"SI"},
+		"parlistabases":          {""},
+		"tipoServicio":           {strconv.Itoa(c.dbRef.ID)},
+		"combo1":                 {strconv.Itoa(c.dbRef.TodosID)},
+		"numeros":                {""},
+		"articulos":              {""},
+		"textolibre":             {""},
+		"texto1":                 {""},
+		"campotexto1":            {"TODOS"},
+		"optexto1":               {"Y"},
+		"texto2":                 {""},
+		"campotexto2":            {"TODOS"},
+		"optexto2":               {"Y"},
+		"texto3":                 {""},
+		"campotexto3":            {"TODOS"},
+		"fechadiar1":             {fechadiar1},
+		"fechadiar2":             {""},
+		"fechapro1":              {""},
+		"fechapro2":              {""},
+		"indexcombobasetematica": {"-1"},
+		"tema":                   {""},
+		"ntema":                  {""},
+		"refinar":                {""},
+	}
+}
+
+// fetches a single page of search results from the IMPO database. since only
+// applies to the first page, the following ones carry the query id. The
+// pages retrieved are logged through phase.
+func (c *Client) retrieveSearchPage(page string, since time.Time, phase *progress.Phase) (*SearchResults, error) {
 	if c.dbRef.SeedURL == "" {
 		return nil, errors.New("db entry - seed url is missing")
 	}
@@ -153,36 +195,13 @@ func (c *Client) retrieveSearchPage(page string) (*SearchResults, error) {
 
 	if page == "" {
 		// First page request
-		log.Printf("Search - Retrieving first page <%s>", c.dbRef.QueryURL)
-		resp, err = c.client.PostForm(
-			c.dbRef.QueryURL,
-			url.Values{
-				"realizarconsulta":       {"SI"},
-				"nuevaconsulta":          {"SI"},
-				"parlistabases":          {""},
-				"tipoServicio":           {strconv.Itoa(c.dbRef.ID)},
-				"combo1":                 {strconv.Itoa(c.dbRef.TodosID)},
-				"numeros":                {""},
-				"articulos":              {""},
-				"textolibre":             {""},
-				"texto1":                 {""},
-				"campotexto1":            {"TODOS"},
-				"optexto1":               {"Y"},
-				"texto2":                 {""},
-				"campotexto2":            {"TODOS"},
-				"optexto2":               {"Y"},
-				"texto3":                 {""},
-				"campotexto3":            {"TODOS"},
-				"fechadiar1":             {""},
-				"fechadiar2":             {""},
-				"fechapro1":              {""},
-				"fechapro2":              {""},
-				"indexcombobasetematica": {"-1"},
-				"tema":                   {""},
-				"ntema":                  {""},
-				"refinar":                {""},
-			},
-		)
+		if since.IsZero() {
+			phase.Printf("Search - Retrieving first page <%s>", c.dbRef.QueryURL)
+		} else {
+			phase.Printf("Search - Retrieving first page <%s> since %s", c.dbRef.QueryURL, since.Format(time.DateOnly))
+		}
+
+		resp, err = c.client.PostForm(c.dbRef.QueryURL, c.searchForm(since))
 	} else {
 		// Subsequent page request
 		var parsedURL *url.URL
@@ -192,7 +211,7 @@ func (c *Client) retrieveSearchPage(page string) (*SearchResults, error) {
 			return nil, fmt.Errorf("parsing QueryUrl <%s>: %w", c.dbRef.QueryURL, err)
 		}
 
-		log.Printf("Search - Retrieving next page %s", page)
+		phase.Printf("Search - Retrieving next page %s", page)
 		parsedURL.RawQuery = page
 		resp, err = c.client.Get(parsedURL.String())
 	}
@@ -235,15 +254,44 @@ func (c *Client) retrieveSearchPage(page string) (*SearchResults, error) {
 	return response, err
 }
 
+// searchLookback is subtracted from the date of the last search, so documents
+// published late (or indexed late by IMPO) are still found.
+const searchLookback = 7 * 24 * time.Hour
+
+// searchSince returns the publication date the search starts from: the one
+// given in the options or, unless a full search was requested, the date of
+// the last search minus searchLookback.
+func (c *Client) searchSince() (time.Time, error) {
+	if !c.options.Since.IsZero() || c.options.SearchFull {
+		return c.options.Since, nil
+	}
+
+	last, err := c.store.LastSearch()
+	if err != nil || last.IsZero() {
+		return time.Time{}, err
+	}
+
+	return last.Add(-searchLookback), nil
+}
+
 // searchForNewDocuments performs the search phase by traversing pages and finding new documents.
 func (c *Client) searchForNewDocuments() error {
 	page := ""
+	startedAt := time.Now()
+
+	since, err := c.searchSince()
+	if err != nil {
+		return fmt.Errorf("reading last search date: %w", err)
+	}
+
+	phase := c.options.Progress.Start("Search "+c.dbRef.Name, -1)
+	defer phase.Finish()
 
 	for range c.options.SearchDepth {
 		metrics := SearchMetrics{}
 		metrics.SearchPages++
 
-		r, err := c.retrieveSearchPage(page)
+		r, err := c.retrieveSearchPage(page, since, phase)
 		if err != nil {
 			return fmt.Errorf("retrieving search page: %w", err)
 		}
@@ -257,7 +305,7 @@ func (c *Client) searchForNewDocuments() error {
 
 		metrics.SearchTotalStored = storedCount
 
-		log.Printf(
+		phase.Step(
 			"Search - Page %d stats - %d new records from a total of %d records",
 			metrics.SearchPages,
 			metrics.SearchTotalStored,
@@ -274,6 +322,12 @@ func (c *Client) searchForNewDocuments() error {
 		}
 	}
 
+	if !c.options.DryRun {
+		if err := c.store.SetLastSearch(startedAt); err != nil {
+			return fmt.Errorf("saving last search date: %w", err)
+		}
+	}
+
 	return nil
 }
 
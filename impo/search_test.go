@@ -6,6 +6,7 @@ package impo
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/net/html"
@@ -90,3 +91,52 @@ func TestVisitSearchTable(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchSince(t *testing.T) {
+	last := time.Date(2025, time.March, 10, 4, 30, 0, 0, time.UTC)
+	since := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		options  ClientOptions
+		previous bool
+		expected time.Time
+	}{
+		{"first run", ClientOptions{}, false, time.Time{}},
+		{"after a run", ClientOptions{}, true, last.Add(-searchLookback)},
+		{"full", ClientOptions{SearchFull: true}, true, time.Time{}},
+		{"explicit", ClientOptions{Since: since}, true, since},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbRef := &DbReference{ID: 45, TodosID: 100}
+			tt.options.DbPath = t.TempDir()
+			c := &Client{dbRef: dbRef, options: &tt.options, store: NewFileStore(tt.options.DbPath, dbRef)}
+
+			if tt.previous {
+				if err := c.store.SetLastSearch(last); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := c.searchSince()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+
+			form := c.searchForm(got)
+			want := ""
+			if !tt.expected.IsZero() {
+				want = tt.expected.Format("02/01/2006")
+			}
+
+			if form.Get("fechadiar1") != want {
+				t.Errorf("expected fechadiar1 %q, got %q", want, form.Get("fechadiar1"))
+			}
+		})
+	}
+}
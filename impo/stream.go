@@ -0,0 +1,225 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jcodagnone/chapauy/utils/htmlutils"
+	"golang.org/x/net/html"
+)
+
+// DefaultStreamBytes is the size above which the documents are extracted
+// with the tokenizer instead of the DOM, see ClientOptions.ExtractStreamBytes.
+const DefaultStreamBytes = 4 << 20
+
+// loginTitle is the title of the page IMPO returns when the session expired.
+const loginTitle = "Ingreso - IMPO"
+
+// voidElements can't have children, so the tokenizer never closes them.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// subtree builds the DOM of a single element from the tokens, to reuse the
+// code that walks the DOM for the rows, without the DOM of the whole
+// document.
+type subtree struct {
+	root  *html.Node
+	stack []*html.Node
+}
+
+func newSubtree(t html.Token) *subtree {
+	root := &html.Node{Type: html.ElementNode, Data: t.Data, Attr: t.Attr}
+
+	return &subtree{root: root, stack: []*html.Node{root}}
+}
+
+// add appends the token to the subtree, returning true when the root element
+// is closed.
+func (st *subtree) add(t html.Token) bool {
+	top := st.stack[len(st.stack)-1]
+
+	switch t.Type {
+	case html.TextToken:
+		top.AppendChild(&html.Node{Type: html.TextNode, Data: t.Data})
+	case html.StartTagToken, html.SelfClosingTagToken:
+		n := &html.Node{Type: html.ElementNode, Data: t.Data, Attr: t.Attr}
+		top.AppendChild(n)
+
+		if t.Type == html.StartTagToken && !voidElements[t.Data] {
+			st.stack = append(st.stack, n)
+		}
+	case html.EndTagToken:
+		// closes the nearest open element, ignoring stray end tags
+		for i := len(st.stack) - 1; i >= 0; i-- {
+			if st.stack[i].Data == t.Data {
+				st.stack = st.stack[:i]
+
+				break
+			}
+		}
+	default:
+		// comments and doctypes
+	}
+
+	return len(st.stack) == 0
+}
+
+// opens tells whether the subtree has an open element with the given name.
+func (st *subtree) opens(name string) bool {
+	for _, n := range st.stack {
+		if n.Data == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// streamOffenses is extractOffenses for large documents: instead of parsing
+// the whole DOM it builds one for each row of the tables, the title and the
+// <h5> of the publication date. The default description of each table is
+// found in the text read before it, see descriptionScope.
+func streamOffenses(
+	issuers []string, source string, r io.Reader, keepRaw bool, headers *headerMapper,
+) ([]*TrafficOffense, error) {
+	doc := &Document{}
+	offenses := make([]*TrafficOffense, 0, 800)
+	defaultHeaderProps := knownHeaderProps(source)
+
+	var (
+		scope              descriptionScope
+		defaultDescription string         // of the table being read
+		table              *offensesTable // the table of offenses being read
+		capture            *subtree       // the element being built
+	)
+
+	// done handles an element once it's complete.
+	done := func(n *html.Node) error {
+		switch n.Data {
+		case "title":
+			if err := doc.visitTitle(issuers, n); err != nil {
+				return err
+			}
+
+			if doc.title == loginTitle {
+				return htmlutils.ErrSessionExpired
+			}
+		case "h5":
+			return doc.visitPublicationDate(n)
+		case "tr":
+			return table.visitRow(n)
+		}
+
+		return nil
+	}
+
+	z := html.NewTokenizer(r)
+
+	for {
+		if z.Next() == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("tokenizing document: %w", err)
+			}
+
+			break
+		}
+
+		t := z.Token()
+
+		if capture != nil {
+			// rows that aren't closed end with the next one, or with the table
+			if capture.root.Data == "tr" && !capture.opens("table") &&
+				((t.Type == html.StartTagToken && t.Data == "tr") ||
+					(t.Type == html.EndTagToken && (t.Data == "table" || t.Data == "tbody"))) {
+				if err := done(capture.root); err != nil {
+					return nil, err
+				}
+
+				capture = nil
+			} else {
+				if capture.add(t) {
+					if err := done(capture.root); err != nil {
+						return nil, err
+					}
+
+					capture = nil
+				}
+
+				continue
+			}
+		}
+
+		switch t.Type {
+		case html.TextToken:
+			if table == nil {
+				scope.write(t.Data)
+			}
+		case html.StartTagToken:
+			switch t.Data {
+			case "title", "h5":
+				capture = newSubtree(t)
+			case "table":
+				if table == nil && hasClass(t, "tabla_en_texto") {
+					defaultDescription = scope.table()
+					table = newOffensesTable(&offenses, &doc.DocDate, defaultDescription, defaultHeaderProps, keepRaw, headers)
+				}
+			case "thead", "tbody", "tfoot":
+				// like the DOM, every section maps its own columns
+				if table != nil {
+					table = newOffensesTable(&offenses, &doc.DocDate, defaultDescription, defaultHeaderProps, keepRaw, headers)
+				}
+			case "tr":
+				if table != nil {
+					capture = newSubtree(t)
+				}
+			case "p", "pre", "div":
+				if table == nil {
+					scope.depth++
+				}
+			}
+		case html.EndTagToken:
+			switch t.Data {
+			case "table":
+				table = nil
+			case "p", "pre", "div":
+				if table == nil && scope.depth > 0 {
+					scope.depth--
+				}
+			}
+		default:
+		}
+	}
+
+	if capture != nil && capture.root.Data == "tr" {
+		if err := done(capture.root); err != nil {
+			return nil, err
+		}
+	}
+
+	if headers != nil {
+		doc.unknownHeaders = headers.unknown
+		doc.unknownCountries = headers.unknownCountries
+	}
+
+	for _, offense := range offenses {
+		offense.Document = doc
+	}
+
+	return offenses, nil
+}
+
+func hasClass(t html.Token, class string) bool {
+	for _, attr := range t.Attr {
+		if attr.Key == "class" && attr.Val == class {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/utils/htmlutils"
+	"golang.org/x/net/html"
+)
+
+// assertSameExtraction checks that the streaming extraction of a document
+// matches the one of the DOM.
+func assertSameExtraction(t *testing.T, source, input string, keepRaw bool) []*TrafficOffense {
+	t.Helper()
+
+	issuers := []string{"intendencia de montevideo", "intendencia de maldonado", "intendencia de treinta y tres"}
+
+	node, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := extractOffenses(issuers, source, node, keepRaw, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := streamOffenses(issuers, source, strings.NewReader(input), keepRaw, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+
+	if string(wantJSON) != string(gotJSON) {
+		t.Fatalf("streaming extraction differs:\nwant %s\n got %s", wantJSON, gotJSON)
+	}
+
+	return got
+}
+
+func TestStreamOffenses_Synthetic(t *testing.T) {
+	offenses := assertSameExtraction(t, "", SyntheticDocument(200), true)
+	if len(offenses) != 200 {
+		t.Fatalf("expected 200 offenses, got %d", len(offenses))
+	}
+
+	if offenses[0].DocID != "1/025" {
+		t.Errorf("expected doc ID 1/025, got %q", offenses[0].DocID)
+	}
+}
+
+func TestStreamOffenses_Art9(t *testing.T) {
+	offenses := assertSameExtraction(t, "", `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Montevideo N° 3906/025</title>
+		<h5>Fecha de Publicación: 10/12/2025</h5>
+		<div><p>... que se constató la contravención a lo dispuesto en el <b>art. 9</b> del Texto Ordenado del Sucive.</p></div>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+			<TR><TD><pre>SBF1234</pre></TD><TD><pre>10/12/2025 10:00</pre></TD></TR>
+		</table>
+	</html>`, false)
+
+	if len(offenses) != 1 || offenses[0].Description != suciveArt9Descr {
+		t.Fatalf("expected one offense of the art. 9, got %+v", offenses)
+	}
+}
+
+func TestStreamOffenses_MixedTables(t *testing.T) {
+	offenses := assertSameExtraction(t, "", `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Montevideo N° 3907/025</title>
+		<h5>Fecha de Publicación: 10/12/2025</h5>
+		<div>
+			<pre>Notifícase a los propietarios de los vehículos cuyas matrículas se determinan, que se constató la contravención a lo dispuesto en el art. 9 del Texto Ordenado del Sucive.</pre>
+			<table class="tabla_en_texto">
+				<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+				<TR><TD><pre>SBF1234</pre></TD><TD><pre>10/12/2025 10:00</pre></TD></TR>
+			</table>
+			<table class="tabla_en_texto">
+				<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD></TR>
+				<TR><TD><pre>SBF4321</pre></TD><TD><pre>10/12/2025 10:30</pre></TD></TR>
+			</table>
+			<pre>Notifícase a los propietarios de los vehículos cuyas matrículas se determinan, que los equipos de fiscalización constataron el exceso de velocidad.</pre>
+			<table class="tabla_en_texto">
+				<TR><TD><pre>Matricula</pre></TD><TD><pre>Fecha y Hora</pre></TD><TD><pre>Artículo</pre></TD></TR>
+				<TR><TD><pre>SBG5678</pre></TD><TD><pre>10/12/2025 11:00</pre></TD><TD><pre>Exceso de velocidad de entre 21 km/h y 30 km/h</pre></TD></TR>
+				<TR><TD><pre>SBG8765</pre></TD><TD><pre>10/12/2025 11:30</pre></TD><TD><pre></pre></TD></TR>
+			</table>
+		</div>
+	</html>`, false)
+
+	var got []string
+	for _, o := range offenses {
+		got = append(got, o.Vehicle+": "+o.Description)
+	}
+
+	want := []string{
+		"SBF1234: " + suciveArt9Descr,
+		// without text since the previous table
+		"SBF4321: " + suciveArt9Descr,
+		"SBG5678: Exceso de velocidad de entre 21 km/h y 30 km/h",
+		"SBG8765: ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestStreamOffenses_KnownHeaders(t *testing.T) {
+	offenses := assertSameExtraction(t, "https://www.impo.com.uy/bases/notificaciones-transito-treintaytres/14-2024", `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Treinta y Tres N° 14/024</title>
+		<h5>Fecha de Publicación: 10/12/2024</h5>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>SBF1234</pre></TD><TD><pre>Exceso de velocidad</pre></TD><TD><pre>5</pre></TD></TR>
+			<TR><TD><pre>SBF1235</pre></TD><TD><pre>Mal estacionado</pre></TD><TD><pre>x</pre></TD></TR>
+		</table>
+	</html>`, false)
+
+	if len(offenses) != 2 || offenses[1].ErrorCode != CodeURParse {
+		t.Fatalf("expected two offenses, the second with an UR error, got %+v", offenses)
+	}
+}
+
+func TestStreamOffenses_UnclosedRows(t *testing.T) {
+	input := `
+	<html>
+		<title>Notificación Dirección General de Tránsito y Transporte Intendencia de Maldonado N° 1/025</title>
+		<h5>Fecha de Publicación: 01/02/2025</h5>
+		<table class="tabla_en_texto">
+			<TR><TD><pre>Matricula</pre></TD><TD><pre>Articulo</pre></TD><TD><pre>Valor en UR</pre></TD>
+			<TR><TD><pre>SBF1234</pre></TD><TD><pre>Exceso<br>de velocidad</pre></TD><TD><pre>5</pre></TD>
+			<TR><TD><pre>SBF1235</pre></TD><TD><pre>Mal estacionado</pre></TD><TD><pre>3</pre></TD>
+		</table>
+	</html>`
+
+	if offenses := assertSameExtraction(t, "", input, false); len(offenses) != 2 {
+		t.Fatalf("expected 2 offenses, got %d", len(offenses))
+	}
+}
+
+func TestStreamOffenses_SessionExpired(t *testing.T) {
+	_, err := streamOffenses(nil, "", strings.NewReader("<html><title>Ingreso - IMPO</title></html>"), false, nil)
+	if !errors.Is(err, htmlutils.ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired, got %v", err)
+	}
+}
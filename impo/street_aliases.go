@@ -0,0 +1,303 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultStreetAliases are the reviewed renames of the streets.
+//
+//go:embed street_aliases.json
+var defaultStreetAliases []byte
+
+// ErrInvalidStreetAlias is returned for aliases without both names or with an
+// unreadable date.
+var ErrInvalidStreetAlias = errors.New("invalid street alias")
+
+// StreetAlias is the old name of a street officially renamed, e.g. an avenue
+// of Montevideo, to rewrite the locations of the older documents with the
+// new name so the offenses of a corner stay together across the rename.
+type StreetAlias struct {
+	DbID int    `json:"db_id"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+	// Since is the date of the rename, YYYY-MM-DD. The offenses after it
+	// keep the old name, which may be another street by then. Every offense
+	// is rewritten when empty.
+	Since string `json:"since,omitempty"`
+	Note  string `json:"note,omitempty"` // only for humans reading the file
+
+	since time.Time
+	re    *regexp.Regexp
+}
+
+// Normalize normalizes the names of the alias and checks its date.
+func (a *StreetAlias) Normalize() error {
+	a.Old = strings.ToUpper(strings.Join(strings.Fields(a.Old), " "))
+	a.New = strings.ToUpper(strings.Join(strings.Fields(a.New), " "))
+
+	switch {
+	case a.DbID == 0:
+		return fmt.Errorf("%w: db_id is required", ErrInvalidStreetAlias)
+	case a.Old == "" || a.New == "":
+		return fmt.Errorf("%w: old and new are required", ErrInvalidStreetAlias)
+	case a.Old == a.New:
+		return fmt.Errorf("%w: %s is renamed to itself", ErrInvalidStreetAlias, a.Old)
+	}
+
+	a.since = time.Time{}
+
+	if a.Since != "" {
+		since, err := time.ParseInLocation(time.DateOnly, a.Since, UruguayTimezone)
+		if err != nil {
+			return fmt.Errorf("%w: since %q", ErrInvalidStreetAlias, a.Since)
+		}
+
+		a.since = since
+	}
+
+	a.re = regexp.MustCompile(a.Pattern())
+
+	// the rewritten locations would match again, see location_rules.json
+	// for the cleanups of the names
+	if a.re.MatchString(a.New) {
+		return fmt.Errorf("%w: %s contains the old name %s", ErrInvalidStreetAlias, a.New, a.Old)
+	}
+
+	return nil
+}
+
+// Pattern is the regular expression of the old name as a whole word in a
+// location, in the syntax shared by Go and DuckDB. The separators around it
+// are the groups 1 and 3.
+func (a *StreetAlias) Pattern() string {
+	words := strings.Fields(a.Old)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+
+	return `(?i)(^|[^\pL\pN])(` + strings.Join(words, `\s+`) + `)($|[^\pL\pN])`
+}
+
+// appliesAt tells whether the alias rewrites the location of an offense at
+// t, the zero time when unknown.
+func (a *StreetAlias) appliesAt(t time.Time) bool {
+	return a.since.IsZero() || t.IsZero() || t.Before(a.since)
+}
+
+// when returns the time of the offense, the date of its document when
+// unknown. BackfillStreetAliases follows the same rule in SQL.
+func (record *TrafficOffense) when() time.Time {
+	if record.Time.IsZero() && record.Document != nil {
+		return record.DocDate
+	}
+
+	return record.Time
+}
+
+// ParseStreetAliases reads a JSON list of aliases, with the format of
+// street_aliases.json.
+func ParseStreetAliases(r io.Reader) ([]StreetAlias, error) {
+	var ret []StreetAlias
+	if err := json.NewDecoder(r).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("decoding street aliases: %w", err)
+	}
+
+	for i := range ret {
+		if err := ret[i].Normalize(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// DefaultStreetAliases returns the reviewed aliases of street_aliases.json.
+func DefaultStreetAliases() ([]StreetAlias, error) {
+	return ParseStreetAliases(bytes.NewReader(defaultStreetAliases))
+}
+
+// StreetAliases are the aliases applied to the locations.
+type StreetAliases struct {
+	aliases []StreetAlias // longest old name first
+}
+
+// NewStreetAliases merges lists of normalized aliases, the later ones
+// replacing the aliases of the earlier ones with the same database and old
+// name.
+func NewStreetAliases(lists ...[]StreetAlias) *StreetAliases {
+	byKey := make(map[string]int)
+
+	var aliases []StreetAlias
+
+	for _, list := range lists {
+		for _, a := range list {
+			key := fmt.Sprintf("%d/%s", a.DbID, a.Old)
+			if i, ok := byKey[key]; ok {
+				aliases[i] = a
+
+				continue
+			}
+
+			byKey[key] = len(aliases)
+			aliases = append(aliases, a)
+		}
+	}
+
+	// "AGRACIADA" must not rewrite "AV AGRACIADA" before its own alias
+	slices.SortStableFunc(aliases, func(a, b StreetAlias) int {
+		return cmp.Compare(len(b.Old), len(a.Old))
+	})
+
+	return &StreetAliases{aliases: aliases}
+}
+
+// Len returns the number of aliases.
+func (s *StreetAliases) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	return len(s.aliases)
+}
+
+// Apply rewrites the old names of the streets in the location of an offense
+// of the database at t, reporting whether there was any.
+func (s *StreetAliases) Apply(dbID int, location string, t time.Time) (string, bool) {
+	if s == nil || location == "" {
+		return location, false
+	}
+
+	var found bool
+
+	for i := range s.aliases {
+		a := &s.aliases[i]
+		if a.DbID != dbID || !a.appliesAt(t) || !a.re.MatchString(location) {
+			continue
+		}
+
+		// the separators are kept, so a street can follow another
+		location = a.re.ReplaceAllString(location, "${1}"+a.New+"${3}")
+		found = true
+	}
+
+	return location, found
+}
+
+// StreetAliasesSchema creates the table of the aliases added by the curators
+// on top of the reviewed ones. It's shared with the curation server, that
+// maintains them.
+const StreetAliasesSchema = `
+	CREATE TABLE IF NOT EXISTS street_aliases (
+		db_id INTEGER NOT NULL,
+		old_name VARCHAR NOT NULL,
+		new_name VARCHAR NOT NULL,
+		since DATE,
+		note VARCHAR,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (db_id, old_name)
+	);
+`
+
+// ListStreetAliases returns the aliases of the curators.
+func ListStreetAliases(db *sql.DB) ([]StreetAlias, error) {
+	rows, err := db.Query(`
+		SELECT db_id, old_name, new_name, COALESCE(strftime(since, '%Y-%m-%d'), ''), COALESCE(note, '')
+		FROM street_aliases
+		ORDER BY db_id, old_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying street aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []StreetAlias
+
+	for rows.Next() {
+		var a StreetAlias
+		if err := rows.Scan(&a.DbID, &a.Old, &a.New, &a.Since, &a.Note); err != nil {
+			return nil, fmt.Errorf("scanning street alias: %w", err)
+		}
+
+		if err := a.Normalize(); err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, a)
+	}
+
+	return ret, rows.Err()
+}
+
+// loadStreetAliases loads the reviewed aliases and the ones of the curators,
+// which win.
+func (r *sqlOffenseRepository) loadStreetAliases() error {
+	reviewed, err := DefaultStreetAliases()
+	if err != nil {
+		return err
+	}
+
+	curated, err := ListStreetAliases(r.db)
+	if err != nil {
+		return err
+	}
+
+	r.streetAliases = NewStreetAliases(reviewed, curated)
+
+	return nil
+}
+
+// BackfillStreetAliases rewrites the old names of the streets in the
+// locations of the offenses extracted before the aliases, keeping the
+// location as written in display_location. It goes before
+// BackfillGeocodingData, so the offenses get the judgments of the new names.
+func (r *sqlOffenseRepository) BackfillStreetAliases() (int64, error) {
+	if err := r.loadStreetAliases(); err != nil {
+		return 0, err
+	}
+
+	var n int64
+
+	for _, a := range r.streetAliases.aliases {
+		var since, sinceDate any
+		if !a.since.IsZero() {
+			since, sinceDate = a.since, a.Since
+		}
+
+		res, err := r.db.Exec(`
+			UPDATE offenses
+			SET
+				display_location = COALESCE(display_location, location),
+				location = regexp_replace(location, ?, '\1'||?||'\3', 'g')
+			WHERE db_id = ?
+				AND regexp_matches(location, ?)
+				AND (CAST(? AS TIMESTAMPTZ) IS NULL
+					OR COALESCE("time" < ?, doc_date < CAST(? AS DATE), true))
+		`, a.Pattern(), a.New, a.DbID, a.Pattern(), since, since, sinceDate)
+		if err != nil {
+			return n, fmt.Errorf("backfilling street alias %s: %w", a.Old, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return n, fmt.Errorf("getting rows affected: %w", err)
+		}
+
+		n += affected
+	}
+
+	return n, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+func TestParseStreetAliases(t *testing.T) {
+	aliases, err := ParseStreetAliases(strings.NewReader(`[
+		{"db_id": 45, "old": " av  agraciada ", "new": "Av Libertador", "since": "2020-05-01"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := aliases[0]; a.Old != "AV AGRACIADA" || a.New != "AV LIBERTADOR" {
+		t.Errorf("expected the names normalized, got %+v", a)
+	}
+
+	for _, bad := range []string{
+		`[{"old": "AGRACIADA", "new": "LIBERTADOR"}]`,
+		`[{"db_id": 45, "old": "AGRACIADA"}]`,
+		`[{"db_id": 45, "old": "AGRACIADA", "new": "agraciada"}]`,
+		`[{"db_id": 45, "old": "ITALIA", "new": "AV ITALIA"}]`,
+		`[{"db_id": 45, "old": "AGRACIADA", "new": "LIBERTADOR", "since": "01/05/2020"}]`,
+	} {
+		if _, err := ParseStreetAliases(strings.NewReader(bad)); !errors.Is(err, ErrInvalidStreetAlias) {
+			t.Errorf("ParseStreetAliases(%s) error = %v, want ErrInvalidStreetAlias", bad, err)
+		}
+	}
+
+	if _, err := DefaultStreetAliases(); err != nil {
+		t.Errorf("street_aliases.json: %v", err)
+	}
+}
+
+func testStreetAliases(t *testing.T, curated ...StreetAlias) *StreetAliases {
+	t.Helper()
+
+	reviewed := []StreetAlias{
+		{DbID: 45, Old: "AGRACIADA", New: "AV DEL LIBERTADOR", Since: "2020-05-01"},
+		{DbID: 45, Old: "AV AGRACIADA", New: "AV DEL LIBERTADOR", Since: "2020-05-01"},
+		{DbID: 45, Old: "PROPIOS", New: "BATLLE Y ORDOÑEZ"},
+	}
+
+	for _, list := range [][]StreetAlias{reviewed, curated} {
+		for i := range list {
+			if err := list[i].Normalize(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return NewStreetAliases(reviewed, curated)
+}
+
+func TestStreetAliases_Apply(t *testing.T) {
+	aliases := testStreetAliases(t, StreetAlias{DbID: 45, Old: "PROPIOS", New: "BV BATLLE Y ORDOÑEZ"})
+	if aliases.Len() != 3 {
+		t.Fatalf("expected the curated PROPIOS to replace the reviewed one, got %d aliases", aliases.Len())
+	}
+
+	before := time.Date(2019, 1, 1, 10, 0, 0, 0, UruguayTimezone)
+	after := time.Date(2021, 1, 1, 10, 0, 0, 0, UruguayTimezone)
+
+	tests := []struct {
+		dbID     int
+		location string
+		at       time.Time
+		want     string
+	}{
+		{45, "AV AGRACIADA Y CAPURRO", before, "AV DEL LIBERTADOR Y CAPURRO"},
+		{45, "agraciada esq. propios", before, "AV DEL LIBERTADOR esq. BV BATLLE Y ORDOÑEZ"},
+		{45, "AGRACIADA", time.Time{}, "AV DEL LIBERTADOR"},
+		// after the rename the old name is kept
+		{45, "AV AGRACIADA Y CAPURRO", after, "AV AGRACIADA Y CAPURRO"},
+		{45, "PROPIOS Y 8 DE OCTUBRE", after, "BV BATLLE Y ORDOÑEZ Y 8 DE OCTUBRE"},
+		// only whole words
+		{45, "AGRACIADAS 1234", before, "AGRACIADAS 1234"},
+		{46, "AGRACIADA 1234", before, "AGRACIADA 1234"},
+	}
+
+	for _, tt := range tests {
+		got, found := aliases.Apply(tt.dbID, tt.location, tt.at)
+		if got != tt.want || found != (tt.want != tt.location) {
+			t.Errorf("Apply(%d, %q, %v) = %q, %v, want %q", tt.dbID, tt.location, tt.at, got, found, tt.want)
+		}
+	}
+
+	if _, found := (*StreetAliases)(nil).Apply(45, "AGRACIADA", before); found {
+		t.Error("nil aliases applied")
+	}
+}
+
+func TestEnrichStreetAliases(t *testing.T) {
+	repo := &sqlOffenseRepository{
+		locationCache: map[locationKey]locationData{
+			{DbID: 45, Location: "AV DEL LIBERTADOR Y CAPURRO"}: {Point: spatial.Point{Lat: -34.88, Lng: -56.2}},
+			{DbID: 45, Location: "AV AGRACIADA Y CAPURRO"}:      {Point: spatial.Point{Lat: -34.87, Lng: -56.2}},
+		},
+		streetAliases: testStreetAliases(t),
+	}
+
+	before := time.Date(2019, 1, 1, 10, 0, 0, 0, UruguayTimezone)
+
+	// judged under both names, the new one wins
+	o := &TrafficOffense{DbID: 45, Location: "AV AGRACIADA Y CAPURRO", Time: before}
+	repo.enrichOffense(o)
+
+	if o.Point == nil || o.Point.Lat != -34.88 ||
+		o.Location != "AV DEL LIBERTADOR Y CAPURRO" || o.DisplayLocation != "AV AGRACIADA Y CAPURRO" {
+		t.Errorf("unexpected %+v", o)
+	}
+
+	// not judged, renamed anyway so the corner keeps its offenses
+	o = &TrafficOffense{DbID: 45, Location: "PROPIOS Y 8 DE OCTUBRE", Time: before}
+	repo.enrichOffense(o)
+
+	if o.Point != nil || o.Location != "BATLLE Y ORDOÑEZ Y 8 DE OCTUBRE" || o.DisplayLocation != "PROPIOS Y 8 DE OCTUBRE" {
+		t.Errorf("unexpected %+v", o)
+	}
+
+	// after the rename, by its time or by the date of its document
+	for _, o := range []*TrafficOffense{
+		{DbID: 45, Location: "AV AGRACIADA Y CAPURRO", Time: before.AddDate(3, 0, 0)},
+		{DbID: 45, Location: "AV AGRACIADA Y CAPURRO", Document: &Document{DocDate: before.AddDate(3, 0, 0)}},
+	} {
+		repo.enrichOffense(o)
+
+		if o.Point == nil || o.Point.Lat != -34.87 || o.Location != "AV AGRACIADA Y CAPURRO" || o.DisplayLocation != "" {
+			t.Errorf("unexpected %+v", o)
+		}
+	}
+}
+
+func TestBackfillStreetAliases(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(StreetAliasesSchema + `
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_date DATE, "time" TIMESTAMPTZ, location VARCHAR, display_location VARCHAR
+		);
+		INSERT INTO offenses VALUES
+			(45, '2019-01-05', '2019-01-01 10:00:00-03', 'AV AGRACIADA Y CAPURRO', NULL),
+			(45, '2019-12-20', '2021-01-01 10:00:00-03', 'AV AGRACIADA Y CAPURRO', NULL),
+			(45, '2019-01-05', '2019-01-01 10:00:00-03', 'PROPIOS Y 8 DE OCTUBRE', 'PROPIOS Y 8 OCT'),
+			(45, '2019-01-05', NULL, 'AV AGRACIADA Y CUFRE', NULL),
+			(45, '2021-01-05', NULL, 'AV AGRACIADA Y CAPURRO', NULL),
+			(46, '2019-01-05', '2019-01-01 10:00:00-03', 'AV AGRACIADA Y CAPURRO', NULL);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO street_aliases (db_id, old_name, new_name, since, note, updated_at) VALUES
+			(45, 'AV AGRACIADA', 'AV DEL LIBERTADOR', '2020-05-01', NULL, ?),
+			(45, 'PROPIOS', 'BV BATLLE Y ORDOÑEZ', NULL, NULL, ?)
+	`, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &sqlOffenseRepository{db: db}
+
+	n, err := repo.BackfillStreetAliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Errorf("expected 3 offenses renamed, got %d", n)
+	}
+
+	// a second run has nothing left to do
+	if n, err := repo.BackfillStreetAliases(); err != nil || n != 0 {
+		t.Errorf("second backfill = %d, %v", n, err)
+	}
+
+	rows, err := db.Query(`
+		SELECT db_id, COALESCE(year("time"), 0), location, COALESCE(display_location, '')
+		FROM offenses
+		ORDER BY ALL
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+
+	for rows.Next() {
+		var (
+			dbID, year        int
+			location, display string
+		)
+
+		if err := rows.Scan(&dbID, &year, &location, &display); err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, strings.Join([]string{location, display}, " | "))
+	}
+
+	want := []string{
+		// without a time, by the date of the document
+		"AV AGRACIADA Y CAPURRO | ",
+		"AV DEL LIBERTADOR Y CUFRE | AV AGRACIADA Y CUFRE",
+		"AV DEL LIBERTADOR Y CAPURRO | AV AGRACIADA Y CAPURRO",
+		"BV BATLLE Y ORDOÑEZ Y 8 DE OCTUBRE | PROPIOS Y 8 OCT",
+		// after the rename
+		"AV AGRACIADA Y CAPURRO | ",
+		// another database
+		"AV AGRACIADA Y CAPURRO | ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TimeRule tells which date is the time of the offenses of a table without
+// the date of the offense.
+type TimeRule string
+
+// The rules of the time of the offenses.
+const (
+	// TimeFromDocument takes the date of the document, as the tables of
+	// Colonia that have no date at all.
+	TimeFromDocument TimeRule = "document"
+	// TimeFromProcessedAt takes the date the authority processed the
+	// offense, the "Fecha Ingreso" column, when the row has it: for the
+	// databases where it's after the offense, but closer to it than the
+	// publication.
+	TimeFromProcessedAt TimeRule = "processed_at"
+)
+
+// defaultTimeRules are the time rules reviewed for each database.
+//
+//go:embed time_rules.json
+var defaultTimeRules []byte
+
+// TimeRules is the rule of the time of the offenses of a database.
+type TimeRules struct {
+	DbID int      `json:"db_id"`
+	Name string   `json:"name,omitempty"` // only for humans reading the file
+	Rule TimeRule `json:"rule,omitempty"` // TimeFromDocument when empty
+	Note string   `json:"note,omitempty"` // only for humans reading the file
+}
+
+func validTimeRule(rule TimeRule) bool {
+	return rule == TimeFromDocument || rule == TimeFromProcessedAt
+}
+
+func addTimeRules(dbs []DbReference, r io.Reader) error {
+	var entries []TimeRules
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("decoding time rules: %w", err)
+	}
+
+	for _, e := range entries {
+		i := dbIndex(dbs, e.DbID)
+		if i < 0 {
+			return fmt.Errorf("time rules: %w: %d", errDatabaseNotFound, e.DbID)
+		}
+
+		if e.Rule != "" && !validTimeRule(e.Rule) {
+			return fmt.Errorf("time rules of %s: unknown rule %q", dbs[i].Name, e.Rule)
+		}
+
+		e.DbID, e.Name = dbs[i].ID, dbs[i].Name
+		dbs[i].TimeRules = e
+	}
+
+	return nil
+}
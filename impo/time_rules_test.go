@@ -0,0 +1,43 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddTimeRules(t *testing.T) {
+	dbs := []DbReference{{ID: 48, Name: "Colonia"}, {ID: 68, Name: "Vialidad"}}
+
+	if err := addTimeRules(dbs, strings.NewReader(`[{"db_id": 68, "rule": "processed_at"}]`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := dbs[1].TimeRules.Rule; got != TimeFromProcessedAt {
+		t.Errorf("rule of %s = %s, want %s", dbs[1].Name, got, TimeFromProcessedAt)
+	}
+
+	if got := (&headerMapper{timeRule: dbs[0].TimeRules.Rule}).rule(); got != TimeFromDocument {
+		t.Errorf("default rule = %s, want %s", got, TimeFromDocument)
+	}
+
+	if err := addTimeRules(dbs, strings.NewReader(`[{"db_id": 48, "rule": "ingreso"}]`)); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+
+	if err := addTimeRules(dbs, strings.NewReader(`[{"db_id": 1, "rule": "document"}]`)); err == nil {
+		t.Error("expected an error for an unknown database")
+	}
+
+	// the processing date of Vialidad isn't the time of the offense
+	vialidad, err := Find("vialidad")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := vialidad.TimeRules.Rule; got != TimeFromDocument {
+		t.Errorf("rule of %s = %s, want %s", vialidad.Name, got, TimeFromDocument)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+// Embeds the timezone database, so UruguayTimezone doesn't depend on the image
+// having one (scratch, distroless).
+import _ "time/tzdata"
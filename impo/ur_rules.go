@@ -0,0 +1,187 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// URRule tells what the UR of a row with several articles is.
+type URRule string
+
+// The rules of the UR of the rows.
+const (
+	// URPerRow is the fine of the whole row, as in the "Valor Total" of
+	// Colonia: an aggregation by article has to split it among them.
+	URPerRow URRule = "total"
+	// URPerArticle is the fine of each of the articles of the row.
+	URPerArticle URRule = "unit"
+)
+
+// defaultURRules are the UR rules reviewed for each database.
+//
+//go:embed ur_rules.json
+var defaultURRules []byte
+
+// URRules are the rules of the UR of a database, with the documents written
+// the other way as exceptions.
+type URRules struct {
+	DbID      int               `json:"db_id"`
+	Name      string            `json:"name,omitempty"` // only for humans reading the file
+	Rule      URRule            `json:"rule,omitempty"` // URPerRow when empty
+	Documents map[string]URRule `json:"documents,omitempty"`
+	Note      string            `json:"note,omitempty"` // only for humans reading the file
+}
+
+// RuleOf returns the rule of a document.
+func (r URRules) RuleOf(docSource string) URRule {
+	if rule, ok := r.Documents[docSource]; ok {
+		return rule
+	}
+
+	if r.Rule == "" {
+		return URPerRow
+	}
+
+	return r.Rule
+}
+
+func validURRule(rule URRule) bool {
+	return rule == URPerRow || rule == URPerArticle
+}
+
+func addURRules(dbs []DbReference, r io.Reader) error {
+	var entries []URRules
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("decoding UR rules: %w", err)
+	}
+
+	for _, e := range entries {
+		i := dbIndex(dbs, e.DbID)
+		if i < 0 {
+			return fmt.Errorf("UR rules: %w: %d", errDatabaseNotFound, e.DbID)
+		}
+
+		if e.Rule != "" && !validURRule(e.Rule) {
+			return fmt.Errorf("UR rules of %s: unknown rule %q", dbs[i].Name, e.Rule)
+		}
+
+		for doc, rule := range e.Documents {
+			if !validURRule(rule) {
+				return fmt.Errorf("UR rules of %s: unknown rule %q for %s", dbs[i].Name, rule, doc)
+			}
+		}
+
+		// the documents add up, the rule replaces the previous one
+		docs := maps.Clone(dbs[i].URRules.Documents)
+		if docs == nil {
+			docs = make(map[string]URRule)
+		}
+
+		maps.Copy(docs, e.Documents)
+
+		if e.Rule == "" {
+			e.Rule = dbs[i].URRules.Rule
+		}
+
+		e.DbID, e.Name, e.Documents = dbs[i].ID, dbs[i].Name, docs
+		dbs[i].URRules = e
+	}
+
+	return nil
+}
+
+// ArticleUR returns the UR of each of the n articles of a row with the rule.
+func (rule URRule) ArticleUR(ur UR, n int) UR {
+	if n <= 1 || rule == URPerArticle {
+		return ur
+	}
+
+	return UR(math.Round(float64(ur) / float64(n)))
+}
+
+// articleUR is ArticleUR with the rule of the document of the offense, nil
+// while its articles are unknown.
+func articleUR(o *TrafficOffense) any {
+	if o.ArticleIDs == nil {
+		return nil
+	}
+
+	rule := URPerRow
+
+	if o.Document != nil {
+		if i := dbIndex(databases, o.DbID); i >= 0 {
+			rule = databases[i].URRules.RuleOf(o.DocSource)
+		}
+	}
+
+	return rule.ArticleUR(o.UR, len(o.ArticleIDs))
+}
+
+// applyURRules sets ur_article of the offenses to what the rules of their
+// documents give, so the rows whose articles were resolved after they were
+// stored, or whose rule changed, add up the same as the freshly extracted ones.
+func (r *sqlOffenseRepository) applyURRules() (int64, error) {
+	// the exceptions first, the rest of the documents follow the rule of
+	// their database
+	var (
+		perArticle []string
+		args       []any
+	)
+
+	for _, db := range databases {
+		for _, doc := range slices.Sorted(maps.Keys(db.URRules.Documents)) {
+			rule := "false"
+			if db.URRules.Documents[doc] == URPerArticle {
+				rule = "true"
+			}
+
+			perArticle = append(perArticle, "WHEN doc_source = ? THEN "+rule)
+			args = append(args, doc)
+		}
+	}
+
+	for _, db := range databases {
+		if db.URRules.Rule == URPerArticle {
+			perArticle = append(perArticle, "WHEN db_id = ? THEN true")
+			args = append(args, db.ID)
+		}
+	}
+
+	// URPerRow when nothing matches
+	isPerArticle := "false"
+	if len(perArticle) > 0 {
+		isPerArticle = "CASE " + strings.Join(perArticle, " ") + " ELSE false END"
+	}
+
+	urArticle := `CASE
+		WHEN article_ids IS NULL OR ur IS NULL THEN NULL
+		WHEN ` + isPerArticle + ` THEN ur
+		ELSE CAST(round(ur / greatest(len(article_ids), 1)) AS INTEGER)
+	END`
+
+	// #nosec G202 - the expressions are constants
+	res, err := r.db.Exec(`
+		UPDATE offenses
+		SET ur_article = `+urArticle+`
+		WHERE ur_article IS DISTINCT FROM (`+urArticle+`)`,
+		append(slices.Clone(args), args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("applying UR rules: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAddURRules(t *testing.T) {
+	dbs := []DbReference{{ID: 48, Name: "Colonia"}, {ID: 45, Name: "Maldonado"}}
+
+	if err := addURRules(dbs, strings.NewReader(`[
+		{"db_id": 48, "rule": "total"},
+		{"db_id": 45, "rule": "unit", "documents": {"a": "total"}},
+		{"db_id": 45, "documents": {"b": "total"}}
+	]`)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		db   int
+		doc  string
+		want URRule
+	}{
+		{0, "x", URPerRow},
+		{1, "x", URPerArticle},
+		{1, "a", URPerRow},
+		{1, "b", URPerRow},
+	}
+
+	for _, tt := range tests {
+		if got := dbs[tt.db].URRules.RuleOf(tt.doc); got != tt.want {
+			t.Errorf("RuleOf(%s, %s) = %s, want %s", dbs[tt.db].Name, tt.doc, got, tt.want)
+		}
+	}
+
+	if err := addURRules(dbs, strings.NewReader(`[{"db_id": 48, "rule": "per-article"}]`)); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+
+	if got := (URRules{}).RuleOf("x"); got != URPerRow {
+		t.Errorf("default rule = %s, want %s", got, URPerRow)
+	}
+}
+
+func TestURRule_ArticleUR(t *testing.T) {
+	if got := URPerRow.ArticleUR(1000, 3); got != 333 {
+		t.Errorf("URPerRow.ArticleUR(1000, 3) = %d, want 333", got)
+	}
+
+	if got := URPerArticle.ArticleUR(1000, 3); got != 1000 {
+		t.Errorf("URPerArticle.ArticleUR(1000, 3) = %d, want 1000", got)
+	}
+
+	if got := URPerRow.ArticleUR(1000, 0); got != 1000 {
+		t.Errorf("URPerRow.ArticleUR(1000, 0) = %d, want 1000", got)
+	}
+
+	o := &TrafficOffense{Document: &Document{DocSource: "x"}, DbID: 48, UR: 600}
+	if got := articleUR(o); got != nil {
+		t.Errorf("articleUR() = %v before resolving the articles, want nil", got)
+	}
+
+	o.ArticleIDs = []string{"1", "2"}
+	if got := articleUR(o); got != UR(300) {
+		t.Errorf("articleUR() = %v, want 300", got)
+	}
+}
+
+func TestApplyURRules(t *testing.T) {
+	// document b of Colonia lists the fine of each article
+	saved := databases
+	databases = slices.Clone(databases)
+	t.Cleanup(func() { databases = saved })
+
+	i := dbIndex(databases, 48)
+	databases[i].URRules.Documents = map[string]URRule{"b": URPerArticle}
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE offenses (db_id INTEGER, doc_source VARCHAR, record_id INTEGER, ur INTEGER, article_ids VARCHAR[], ur_article INTEGER);
+		INSERT INTO offenses VALUES
+			(48, 'a', 1, 900, ['1', '2', '3'], NULL),
+			(48, 'a', 2, 900, ['1'], NULL),
+			(48, 'a', 3, 900, NULL, NULL),
+			(48, 'a', 4, 900, ['1', '2'], 100),
+			(48, 'a', 5, 900, NULL, 300),
+			(48, 'b', 6, 900, ['1', '2'], 450);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := (&sqlOffenseRepository{db: db}).applyURRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 5 {
+		t.Errorf("applyURRules() = %d, want 5", n)
+	}
+
+	// nothing changes the second time
+	n, err = (&sqlOffenseRepository{db: db}).applyURRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Errorf("applyURRules() again = %d, want 0", n)
+	}
+
+	rows, err := db.Query("SELECT record_id, ur_article FROM offenses ORDER BY record_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	want := map[int]sql.NullInt64{
+		1: {Int64: 300, Valid: true},
+		2: {Int64: 900, Valid: true},
+		3: {},
+		4: {Int64: 450, Valid: true},
+		5: {},
+		6: {Int64: 900, Valid: true},
+	}
+
+	for rows.Next() {
+		var (
+			id int
+			ur sql.NullInt64
+		)
+
+		if err := rows.Scan(&id, &ur); err != nil {
+			t.Fatal(err)
+		}
+
+		if ur != want[id] {
+			t.Errorf("ur_article of %d = %v, want %v", id, ur, want[id])
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
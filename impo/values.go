@@ -0,0 +1,96 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Kinds of the values the extraction doesn't know how to interpret.
+const (
+	ValueKindCountry = "country"
+)
+
+// ValuesSchema creates the tables of the values learned from the curators.
+// It's shared with the curation server, that maps the pending ones.
+const ValuesSchema = `
+	-- values of the cells that couldn't be interpreted, by kind, like the
+	-- spellings of the countries
+	CREATE TABLE IF NOT EXISTS pending_values (
+		kind VARCHAR NOT NULL,
+		value VARCHAR NOT NULL,
+		db_id INTEGER NOT NULL,
+		doc_source VARCHAR NOT NULL,
+		seen_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (kind, value, doc_source)
+	);
+
+	-- spellings of the countries mapped by the curators to an ISO code, used
+	-- on top of the built-in ones. An empty code means another country.
+	CREATE TABLE IF NOT EXISTS country_synonyms (
+		spelling VARCHAR PRIMARY KEY,
+		iso VARCHAR NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	);
+`
+
+// UnknownValue is a value of a document the extraction couldn't interpret.
+// The rows with it were stored with an error.
+type UnknownValue struct {
+	DocSource string
+	Kind      string
+	Value     string
+}
+
+func (r *sqlOffenseRepository) ListCountrySynonyms() (map[string]string, error) {
+	rows, err := r.db.Query("SELECT spelling, iso FROM country_synonyms")
+	if err != nil {
+		return nil, fmt.Errorf("querying country synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[string]string)
+
+	for rows.Next() {
+		var spelling, iso string
+		if err := rows.Scan(&spelling, &iso); err != nil {
+			return nil, fmt.Errorf("scanning country synonym: %w", err)
+		}
+
+		ret[normalize(spelling)] = iso
+	}
+
+	return ret, rows.Err()
+}
+
+func (r *sqlOffenseRepository) SavePendingValues(dbID int, docSource string, kind string, values []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	now := time.Now()
+
+	for _, v := range values {
+		if _, err := tx.Exec(`
+			INSERT INTO pending_values (kind, value, db_id, doc_source, seen_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (kind, value, doc_source) DO UPDATE SET seen_at = excluded.seen_at
+		`, kind, v, dbID, docSource, now); err != nil {
+			return fmt.Errorf("recording pending %s %q: %w", kind, v, err)
+		}
+	}
+
+	return tx.Commit()
+}
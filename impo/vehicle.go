@@ -59,11 +59,20 @@ func NormalizeVehicleID(s string) string {
 	ret := strings.ToUpper(s)
 
 	// Rio Negro usually mark foreign VehicleID with (E) - Extrajero
-	ret = strings.TrimRight(ret, "(E)")
+	ret = strings.TrimSuffix(ret, foreignMarker)
 
 	return ret
 }
 
+// foreignMarker is appended by Rio Negro to foreign plates.
+const foreignMarker = "(E)"
+
+// HasForeignMarker reports whether the plate, as written in the document, is
+// marked as foreign. NormalizeVehicleID removes the mark.
+func HasForeignMarker(s string) bool {
+	return strings.HasSuffix(strings.ToUpper(strings.Join(strings.Fields(s), "")), foreignMarker)
+}
+
 // VehicleSpecialType represents a special plate combination and its category.
 type VehicleSpecialType struct {
 	Value    string
@@ -156,6 +165,12 @@ var uruguayDepartments = map[string]bool{
 	DeptTacuarembo:  true,
 }
 
+// IsUruguayDepartment reports whether the letter is the first letter of the
+// plates issued by an Uruguayan department.
+func IsUruguayDepartment(letter string) bool {
+	return uruguayDepartments[letter]
+}
+
 // VehicleInfo contains the information extracted from a vehicle's license plate.
 type VehicleInfo struct {
 	Country        string `json:"country,omitempty"`      // ISO country code
@@ -163,6 +178,7 @@ type VehicleInfo struct {
 	VehicleType    string `json:"vehicle_type,omitempty"` // Vehicle type (Car, Motorcycle, etc.)
 	Category       string `json:"category,omitempty"`     // Official, Private, etc.
 	MercosurFormat bool   `json:"mercosur_format"`        // License plate format (Mercosur)
+	Foreign        bool   `json:"foreign,omitempty"`      // Known not to be Uruguayan, even if Country is unset
 }
 
 // PlatePattern defines a license plate pattern for a specific type/category.
@@ -181,6 +197,10 @@ const (
 	TypeAutoOrMoto = ""
 )
 
+// HintForeign is the country hint of plates known not to be Uruguayan whose
+// country is unknown.
+const HintForeign = "foreign"
+
 // Country code constants.
 const (
 	ISOUruguay   = "UY"
@@ -395,13 +415,20 @@ func normalizeCountryName(name string) (string, error) {
 		return "", nil
 	}
 
-	return "", fmt.Errorf("unknown country: %q", name)
+	return "", fmt.Errorf("%w: %q", ErrUnknownCountry, name)
 }
 
 // AnalyzeVehicleID infers information from a license plate. On error returns blank + error.
+//
+// With HintForeign only the patterns of other countries are tried: the
+// country is set only when a single one matches.
 func AnalyzeVehicleID(plate string, countryHint string) (*VehicleInfo, error) {
 	plate = NormalizeVehicleID(plate)
 
+	if countryHint == HintForeign {
+		return analyzeForeign(plate), nil
+	}
+
 	for _, countryCheck := range countryPatterns {
 		if countryHint != "" && countryCheck.ISO != countryHint {
 			continue
@@ -424,3 +451,30 @@ func AnalyzeVehicleID(plate string, countryHint string) (*VehicleInfo, error) {
 
 	return &VehicleInfo{}, errors.New("no info available")
 }
+
+func analyzeForeign(plate string) *VehicleInfo {
+	var ret *VehicleInfo
+
+	for _, countryCheck := range countryPatterns {
+		if countryCheck.ISO == ISOUruguay {
+			continue
+		}
+
+		if info, matched := analyzeCountry(plate, countryCheck.ISO, countryCheck.Patterns); matched {
+			if ret != nil {
+				// ambiguous
+				return &VehicleInfo{Foreign: true}
+			}
+
+			ret = info
+		}
+	}
+
+	if ret == nil {
+		return &VehicleInfo{Foreign: true}
+	}
+
+	ret.Foreign = true
+
+	return ret
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TypeOmnibus is the vehicle type of the buses, only known from the overrides.
+const TypeOmnibus = "Ómnibus"
+
+// defaultVehicleOverrides are the reviewed overrides of the fleets whose
+// plates share a prefix.
+//
+//go:embed vehicle_overrides.json
+var defaultVehicleOverrides []byte
+
+// ErrInvalidVehicleOverride is returned for overrides without a usable prefix
+// or with nothing to override.
+var ErrInvalidVehicleOverride = errors.New("invalid vehicle override")
+
+var (
+	vehicleOverridePrefixRe  = regexp.MustCompile(`^[A-Z0-9]+$`)
+	vehicleOverrideCountryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// VehicleOverride sets the category and the type of the plates of a country
+// that start with a prefix, for the fleets (taxis, buses, official vehicles)
+// the departments number in blocks that the plate patterns don't encode.
+type VehicleOverride struct {
+	Country     string `json:"country,omitempty"` // ISO code, Uruguay when empty
+	Prefix      string `json:"prefix"`
+	Category    string `json:"category,omitempty"`
+	VehicleType string `json:"vehicle_type,omitempty"`
+	Note        string `json:"note,omitempty"` // only for humans reading the file
+}
+
+// Normalize normalizes the prefix and the country of the override and checks
+// it has something to override.
+func (o *VehicleOverride) Normalize() error {
+	o.Prefix = NormalizeVehicleID(o.Prefix)
+	if o.Country == "" {
+		o.Country = ISOUruguay
+	}
+
+	switch {
+	case !vehicleOverridePrefixRe.MatchString(o.Prefix):
+		return fmt.Errorf("%w: prefix %q", ErrInvalidVehicleOverride, o.Prefix)
+	case !vehicleOverrideCountryRe.MatchString(o.Country):
+		return fmt.Errorf("%w: country %q", ErrInvalidVehicleOverride, o.Country)
+	case o.Category == "" && o.VehicleType == "":
+		return fmt.Errorf("%w: %s has neither category nor vehicle_type", ErrInvalidVehicleOverride, o.Prefix)
+	case o.VehicleType != "" && o.VehicleType != TypeAuto && o.VehicleType != TypeMoto && o.VehicleType != TypeOmnibus:
+		return fmt.Errorf("%w: vehicle_type %q", ErrInvalidVehicleOverride, o.VehicleType)
+	}
+
+	return nil
+}
+
+// ParseVehicleOverrides reads a JSON list of overrides, with the format of
+// vehicle_overrides.json.
+func ParseVehicleOverrides(r io.Reader) ([]VehicleOverride, error) {
+	var ret []VehicleOverride
+	if err := json.NewDecoder(r).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("decoding vehicle overrides: %w", err)
+	}
+
+	for i := range ret {
+		if err := ret[i].Normalize(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// DefaultVehicleOverrides returns the reviewed overrides of
+// vehicle_overrides.json.
+func DefaultVehicleOverrides() ([]VehicleOverride, error) {
+	return ParseVehicleOverrides(bytes.NewReader(defaultVehicleOverrides))
+}
+
+// VehicleOverrides are the overrides applied to the plates, the one with the
+// longest prefix winning.
+type VehicleOverrides struct {
+	overrides []VehicleOverride // longest prefix first
+}
+
+// NewVehicleOverrides merges lists of overrides, the later ones replacing the
+// overrides of the earlier ones with the same country and prefix.
+func NewVehicleOverrides(lists ...[]VehicleOverride) *VehicleOverrides {
+	byKey := make(map[string]int)
+
+	var overrides []VehicleOverride
+
+	for _, list := range lists {
+		for _, o := range list {
+			key := o.Country + "/" + o.Prefix
+			if i, ok := byKey[key]; ok {
+				overrides[i] = o
+
+				continue
+			}
+
+			byKey[key] = len(overrides)
+			overrides = append(overrides, o)
+		}
+	}
+
+	slices.SortStableFunc(overrides, func(a, b VehicleOverride) int {
+		return cmp.Compare(len(b.Prefix), len(a.Prefix))
+	})
+
+	return &VehicleOverrides{overrides: overrides}
+}
+
+// Len returns the number of overrides.
+func (v *VehicleOverrides) Len() int {
+	if v == nil {
+		return 0
+	}
+
+	return len(v.overrides)
+}
+
+// Apply sets the category and the type of the overrides of the plate, each
+// from the one with the longest prefix that has it, reporting whether there
+// was any.
+func (v *VehicleOverrides) Apply(plate string, info *VehicleInfo) bool {
+	if v == nil || info == nil {
+		return false
+	}
+
+	var category, vehicleType, found bool
+
+	for _, o := range v.overrides {
+		if o.Country != info.Country || !strings.HasPrefix(plate, o.Prefix) {
+			continue
+		}
+
+		found = true
+
+		if o.Category != "" && !category {
+			info.Category, category = o.Category, true
+		}
+
+		if o.VehicleType != "" && !vehicleType {
+			info.VehicleType, vehicleType = o.VehicleType, true
+		}
+	}
+
+	return found
+}
+
+// VehicleOverridesSchema creates the table of the overrides added by the
+// curators on top of the reviewed ones. It's shared with the curation server,
+// that maintains them.
+const VehicleOverridesSchema = `
+	CREATE TABLE IF NOT EXISTS vehicle_overrides (
+		country CHAR(2) NOT NULL,
+		prefix VARCHAR NOT NULL,
+		category VARCHAR,
+		vehicle_type VARCHAR,
+		note VARCHAR,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (country, prefix)
+	);
+`
+
+// ListVehicleOverrides returns the overrides of the curators.
+func ListVehicleOverrides(db *sql.DB) ([]VehicleOverride, error) {
+	rows, err := db.Query(`
+		SELECT country, prefix, COALESCE(category, ''), COALESCE(vehicle_type, ''), COALESCE(note, '')
+		FROM vehicle_overrides
+		ORDER BY country, prefix
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying vehicle overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []VehicleOverride
+
+	for rows.Next() {
+		var o VehicleOverride
+		if err := rows.Scan(&o.Country, &o.Prefix, &o.Category, &o.VehicleType, &o.Note); err != nil {
+			return nil, fmt.Errorf("scanning vehicle override: %w", err)
+		}
+
+		ret = append(ret, o)
+	}
+
+	return ret, rows.Err()
+}
+
+// loadVehicleOverrides loads the reviewed overrides and the ones of the
+// curators, which win.
+func (r *sqlOffenseRepository) loadVehicleOverrides() error {
+	reviewed, err := DefaultVehicleOverrides()
+	if err != nil {
+		return err
+	}
+
+	curated, err := ListVehicleOverrides(r.db)
+	if err != nil {
+		return err
+	}
+
+	r.vehicleOverrides = NewVehicleOverrides(reviewed, curated)
+
+	return nil
+}
+
+// BackfillVehicleOverrides applies the category and the type of the
+// overrides to the offenses extracted before them, and restores what the
+// plate says for the ones whose override was removed. Only the plates of the
+// current overrides and the ones overridden before are analyzed again.
+func (r *sqlOffenseRepository) BackfillVehicleOverrides() (int64, error) {
+	if err := r.loadVehicleOverrides(); err != nil {
+		return 0, err
+	}
+
+	cond := []string{"vehicle_overridden"}
+	args := make([]any, 0, 2*r.vehicleOverrides.Len())
+
+	for _, o := range r.vehicleOverrides.overrides {
+		cond = append(cond, "(vehicle_country = ? AND starts_with(vehicle, ?))")
+		args = append(args, o.Country, o.Prefix)
+	}
+
+	// #nosec G202 - the conditions are constants, the overrides are arguments
+	rows, err := r.db.Query(`
+		SELECT DISTINCT
+			vehicle, COALESCE(vehicle_country, ''), COALESCE(vehicle_foreign, false),
+			COALESCE(vehicle_type, ''), COALESCE(vehicle_category, ''), COALESCE(vehicle_overridden, false)
+		FROM offenses
+		WHERE vehicle IS NOT NULL AND (`+strings.Join(cond, " OR ")+`)
+	`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("querying overridden vehicles: %w", err)
+	}
+
+	type plate struct {
+		vehicle, country      string
+		foreign               bool
+		vehicleType, category string
+		overridden            bool
+	}
+
+	var changed []plate
+
+	for rows.Next() {
+		var p plate
+		if err := rows.Scan(&p.vehicle, &p.country, &p.foreign, &p.vehicleType, &p.category, &p.overridden); err != nil {
+			rows.Close()
+
+			return 0, fmt.Errorf("scanning overridden vehicle: %w", err)
+		}
+
+		// the country was analyzed from the hint of the document, so it
+		// gives the same analysis back
+		hint := p.country
+		if hint == "" && p.foreign {
+			hint = HintForeign
+		}
+
+		info, _ := AnalyzeVehicleID(p.vehicle, hint)
+		overridden := r.vehicleOverrides.Apply(p.vehicle, info)
+
+		if info.VehicleType != p.vehicleType || info.Category != p.category || overridden != p.overridden {
+			p.vehicleType, p.category, p.overridden = info.VehicleType, info.Category, overridden
+			changed = append(changed, p)
+		}
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return 0, fmt.Errorf("reading overridden vehicles: %w", err)
+	}
+
+	var n int64
+
+	for _, p := range changed {
+		res, err := r.db.Exec(`
+			UPDATE offenses
+			SET vehicle_type = ?, vehicle_category = ?, vehicle_overridden = ?
+			WHERE vehicle = ?
+				AND COALESCE(vehicle_country, '') = ?
+				AND COALESCE(vehicle_foreign, false) = ?
+		`, nve(p.vehicleType), nve(p.category), p.overridden, p.vehicle, p.country, p.foreign)
+		if err != nil {
+			return n, fmt.Errorf("backfilling vehicle override of %s: %w", p.vehicle, err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return n, fmt.Errorf("getting rows affected: %w", err)
+		}
+
+		n += affected
+	}
+
+	return n, nil
+}
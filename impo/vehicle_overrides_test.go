@@ -0,0 +1,163 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVehicleOverrides(t *testing.T) {
+	overrides, err := ParseVehicleOverrides(strings.NewReader(`[
+		{"prefix": "stx", "category": "Taxi", "vehicle_type": "Auto"},
+		{"country": "AR", "prefix": "AB", "category": "Oficial"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o := overrides[0]; o.Country != ISOUruguay || o.Prefix != "STX" {
+		t.Errorf("expected the prefix normalized in Uruguay, got %+v", o)
+	}
+
+	for _, bad := range []string{
+		`[{"prefix": "", "category": "Taxi"}]`,
+		`[{"prefix": "STX"}]`,
+		`[{"prefix": "STX", "vehicle_type": "Camión"}]`,
+		`[{"country": "Uruguay", "prefix": "STX", "category": "Taxi"}]`,
+	} {
+		if _, err := ParseVehicleOverrides(strings.NewReader(bad)); !errors.Is(err, ErrInvalidVehicleOverride) {
+			t.Errorf("ParseVehicleOverrides(%s) error = %v, want ErrInvalidVehicleOverride", bad, err)
+		}
+	}
+
+	if _, err := DefaultVehicleOverrides(); err != nil {
+		t.Errorf("vehicle_overrides.json: %v", err)
+	}
+}
+
+func TestVehicleOverrides_Apply(t *testing.T) {
+	overrides := NewVehicleOverrides(
+		[]VehicleOverride{
+			{Country: ISOUruguay, Prefix: "SB", VehicleType: TypeOmnibus, Category: "Transporte colectivo"},
+			{Country: ISOUruguay, Prefix: "SBA", Category: "Transporte colectivo"},
+		},
+		[]VehicleOverride{
+			{Country: ISOUruguay, Prefix: "SBB", Category: "Escolar", VehicleType: TypeOmnibus},
+			{Country: ISOUruguay, Prefix: "SBA", Category: "Turismo"},
+		},
+	)
+
+	if overrides.Len() != 3 {
+		t.Fatalf("expected the curated SBA to replace the reviewed one, got %d overrides", overrides.Len())
+	}
+
+	tests := []struct {
+		plate, country       string
+		wantCategory, wantVT string
+		wantFound            bool
+	}{
+		{"SBA1234", ISOUruguay, "Turismo", TypeOmnibus, true},
+		{"SBB1234", ISOUruguay, "Escolar", TypeOmnibus, true},
+		{"SBC1234", ISOUruguay, "Transporte colectivo", TypeOmnibus, true},
+		{"SAA1234", ISOUruguay, "", "", false},
+		{"SBA1234", ISOArgentina, "", "", false},
+	}
+
+	for _, tt := range tests {
+		info := &VehicleInfo{Country: tt.country}
+		found := overrides.Apply(tt.plate, info)
+
+		if found != tt.wantFound || info.Category != tt.wantCategory || info.VehicleType != tt.wantVT {
+			t.Errorf("Apply(%s, %s) = %v, %+v", tt.plate, tt.country, found, info)
+		}
+	}
+
+	if (*VehicleOverrides)(nil).Apply("SBA1234", &VehicleInfo{Country: ISOUruguay}) {
+		t.Error("nil overrides applied")
+	}
+}
+
+func TestBackfillVehicleOverrides(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(VehicleOverridesSchema + `
+		CREATE TABLE offenses (
+			vehicle VARCHAR, vehicle_country CHAR(2), vehicle_foreign BOOLEAN, vehicle_type VARCHAR,
+			vehicle_category VARCHAR, vehicle_overridden BOOLEAN
+		);
+		INSERT INTO offenses VALUES
+			('SBA1234', 'UY', false, NULL, NULL, NULL),
+			('SBB1234', 'UY', false, 'Auto', NULL, false),
+			('SAA1234', 'UY', false, NULL, NULL, NULL),
+			('SBA123', 'AR', false, 'Auto', NULL, false),
+			-- overridden by an override that was removed since
+			('SAB1234', 'UY', false, 'Ómnibus', 'Turismo', true);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO vehicle_overrides (country, prefix, category, vehicle_type, updated_at) VALUES
+			('UY', 'SB', NULL, 'Ómnibus', ?), ('UY', 'SBB', 'Escolar', 'Moto', ?)
+	`, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &sqlOffenseRepository{db: db}
+
+	n, err := repo.BackfillVehicleOverrides()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Errorf("expected 3 offenses updated, got %d", n)
+	}
+
+	got := make(map[string]string)
+
+	rows, err := db.Query(`
+		SELECT vehicle, COALESCE(vehicle_type, '') || '/' || COALESCE(vehicle_category, '') || '/' || COALESCE(vehicle_overridden, false)
+		FROM offenses
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vehicle, vehicleType string
+		if err := rows.Scan(&vehicle, &vehicleType); err != nil {
+			t.Fatal(err)
+		}
+
+		got[vehicle] = vehicleType
+	}
+
+	want := map[string]string{
+		"SBA1234": TypeOmnibus + "//true",
+		"SBB1234": TypeMoto + "/Escolar/true",
+		"SAA1234": "//false",
+		"SBA123":  TypeAuto + "//false",
+		"SAB1234": "//false",
+	}
+	for vehicle, vehicleType := range want {
+		if got[vehicle] != vehicleType {
+			t.Errorf("%s = %q, want %q", vehicle, got[vehicle], vehicleType)
+		}
+	}
+
+	// nothing left to do
+	if n, err := repo.BackfillVehicleOverrides(); err != nil || n != 0 {
+		t.Errorf("second backfill = %d, %v", n, err)
+	}
+}
@@ -14,6 +14,9 @@ func TestNormalize(t *testing.T) {
 	}{
 		{"AAA 0000", "AAA0000"},
 		{"AAA-000", "AAA000"},
+		{"ABC 123 (E)", "ABC123"},
+		// only the whole marker is removed
+		{"AB123CE", "AB123CE"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.id, func(t *testing.T) {
@@ -306,3 +309,35 @@ func TestAnalyzeVehicleID_WithHint(t *testing.T) {
 		t.Errorf("expected empty vehicle type, got %v", info.VehicleType)
 	}
 }
+
+func TestHasForeignMarker(t *testing.T) {
+	if !HasForeignMarker("ABC 123 (e)") {
+		t.Error("expected the plate to be marked as foreign")
+	}
+
+	if HasForeignMarker("AB123CE") {
+		t.Error("expected the plate not to be marked as foreign")
+	}
+}
+
+func TestAnalyzeVehicleID_Foreign(t *testing.T) {
+	// matches the Uruguayan Mercosur pattern, but it was marked as foreign
+	info, err := AnalyzeVehicleID("ABC1234", HintForeign)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !info.Foreign || info.Country != "" {
+		t.Errorf("expected foreign without country, got %+v", info)
+	}
+
+	// only Brazil uses this format
+	info, err = AnalyzeVehicleID("ABC1D23", HintForeign)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !info.Foreign || info.Country != ISOBrasil {
+		t.Errorf("expected a foreign Brazilian plate, got %+v", info)
+	}
+}
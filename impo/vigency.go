@@ -0,0 +1,217 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jcodagnone/chapauy/curation/utils"
+)
+
+type articleConflictKey struct {
+	description string
+	articleID   string
+}
+
+type articleConflict struct {
+	offenses    int
+	first, last time.Time
+}
+
+type vigencyUpdate struct {
+	docSource string
+	recordID  int
+	ids       []string
+	codes     []int8
+}
+
+// applyArticleVigency replaces the articles of the classified offenses by
+// their version in force at the date of the offense, for the articles that
+// were renumbered. Offenses with an article that had no version in force are
+// left as they are and recorded in article_conflicts for review.
+func (r *sqlOffenseRepository) applyArticleVigency() (int64, error) {
+	versions, codes, err := r.loadArticleVersions()
+	if err != nil {
+		return 0, err
+	}
+
+	var versioned []string
+
+	for id := range codes {
+		if versions.Versioned(id) {
+			versioned = append(versioned, id)
+		}
+	}
+
+	var (
+		updates   []vigencyUpdate
+		conflicts = make(map[articleConflictKey]*articleConflict)
+	)
+
+	if len(versioned) > 0 {
+		if updates, err = r.pendingVigencyUpdates(versions, codes, versioned, conflicts); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	for _, u := range updates {
+		if _, err := tx.Exec(`
+			UPDATE offenses SET article_ids = ?, article_codes = ?
+			WHERE doc_source = ? AND record_id = ?
+		`, u.ids, u.codes, u.docSource, u.recordID); err != nil {
+			return 0, fmt.Errorf("updating articles of %s#%d: %w", u.docSource, u.recordID, err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM article_conflicts"); err != nil {
+		return 0, fmt.Errorf("clearing article conflicts: %w", err)
+	}
+
+	for k, c := range conflicts {
+		if _, err := tx.Exec(`
+			INSERT INTO article_conflicts (description, article_id, offenses, first_time, last_time)
+			VALUES (?, ?, ?, ?, ?)
+		`, k.description, k.articleID, c.offenses, c.first, c.last); err != nil {
+			return 0, fmt.Errorf("recording article conflict of %s: %w", k.description, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if len(conflicts) > 0 {
+		log.Printf("⚠️  %d descriptions have offenses with no version of an article in force, see article_conflicts", len(conflicts))
+	}
+
+	return int64(len(updates)), nil
+}
+
+func (r *sqlOffenseRepository) loadArticleVersions() (*utils.ArticleVersions, map[string]int8, error) {
+	rows, err := r.db.Query(`
+		SELECT id, code, effective_from, effective_to, COALESCE(replaced_by, '')
+		FROM articles
+	`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading articles: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []utils.ArticleVersion
+
+	codes := make(map[string]int8)
+
+	for rows.Next() {
+		var (
+			v        utils.ArticleVersion
+			code     int8
+			from, to sql.NullTime
+		)
+
+		if err := rows.Scan(&v.ID, &code, &from, &to, &v.ReplacedBy); err != nil {
+			return nil, nil, fmt.Errorf("scanning article: %w", err)
+		}
+
+		v.From, v.To = from.Time, to.Time
+		codes[v.ID] = code
+		versions = append(versions, v)
+	}
+
+	return utils.NewArticleVersions(versions), codes, rows.Err()
+}
+
+func (r *sqlOffenseRepository) pendingVigencyUpdates(
+	versions *utils.ArticleVersions,
+	codes map[string]int8,
+	versioned []string,
+	conflicts map[articleConflictKey]*articleConflict,
+) ([]vigencyUpdate, error) {
+	rows, err := r.db.Query(`
+		SELECT doc_source, record_id, COALESCE(description, ''), "time", article_ids
+		FROM offenses
+		WHERE "time" IS NOT NULL AND list_has_any(article_ids, ?)
+	`, versioned)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses with versioned articles: %w", err)
+	}
+	defer rows.Close()
+
+	var updates []vigencyUpdate
+
+	for rows.Next() {
+		var (
+			u           vigencyUpdate
+			description string
+			t           time.Time
+			idsVal      any
+		)
+
+		if err := rows.Scan(&u.docSource, &u.recordID, &description, &t, &idsVal); err != nil {
+			return nil, fmt.Errorf("scanning offense: %w", err)
+		}
+
+		ids, ok := utils.AnyToStringSlice(idsVal)
+		if !ok {
+			continue
+		}
+
+		// vigency dates are days in Uruguay
+		local := t.In(UruguayTimezone)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+		var missing []string
+
+		u.ids, missing = versions.ResolveAll(ids, day)
+
+		for _, id := range missing {
+			k := articleConflictKey{description, id}
+
+			c, ok := conflicts[k]
+			if !ok {
+				c = &articleConflict{first: t, last: t}
+				conflicts[k] = c
+			}
+
+			c.offenses++
+
+			if t.Before(c.first) {
+				c.first = t
+			}
+
+			if t.After(c.last) {
+				c.last = t
+			}
+		}
+
+		if slices.Equal(u.ids, ids) {
+			continue
+		}
+
+		for _, id := range u.ids {
+			if code, ok := codes[id]; ok && !slices.Contains(u.codes, code) {
+				u.codes = append(u.codes, code)
+			}
+		}
+
+		updates = append(updates, u)
+	}
+
+	return updates, rows.Err()
+}
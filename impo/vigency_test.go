@@ -0,0 +1,101 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package impo
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/curation/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyArticleVigency(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			doc_source VARCHAR, record_id INTEGER, description VARCHAR,
+			"time" TIMESTAMPTZ, article_ids VARCHAR[], article_codes TINYINT[]
+		);
+		CREATE TABLE articles (
+			id VARCHAR PRIMARY KEY, text VARCHAR, code TINYINT, title VARCHAR,
+			effective_from DATE, effective_to DATE, replaced_by VARCHAR
+		);
+		CREATE TABLE article_conflicts (
+			description VARCHAR, article_id VARCHAR, offenses INTEGER,
+			first_time TIMESTAMPTZ, last_time TIMESTAMPTZ
+		);
+
+		INSERT INTO articles VALUES
+			('13.3.A', '', 13, '', NULL, '2020-01-01', '13.4.A'),
+			('13.4.A', '', 14, '', '2020-01-01', NULL, NULL),
+			('18.1', '', 18, '', '2021-01-01', NULL, NULL),
+			('21.3.1', '', 21, '', NULL, NULL, NULL);
+
+		INSERT INTO offenses VALUES
+			-- before the renumbering
+			('a', 1, 'EXCESO', '2019-06-01 10:00:00-03', ['13.3.A'], [13]),
+			-- after it, even if classified with the old ID
+			('a', 2, 'EXCESO', '2020-06-01 10:00:00-03', ['13.3.A'], [13]),
+			-- the night of 2019-12-31 in Uruguay is already 2020 in UTC
+			('a', 3, 'EXCESO', '2019-12-31 23:00:00-03', ['13.4.A'], [14]),
+			('b', 1, 'SIN CASCO, ESTACIONAR', '2020-06-01 10:00:00-03', ['21.3.1', '18.1'], [21, 18]);
+	`)
+	require.NoError(t, err)
+
+	repo := &sqlOffenseRepository{db: db}
+
+	n, err := repo.applyArticleVigency()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	articles := func(source string, record int) ([]string, []int8) {
+		var ids, codes any
+		require.NoError(t, db.QueryRow(
+			"SELECT article_ids, article_codes FROM offenses WHERE doc_source = ? AND record_id = ?", source, record,
+		).Scan(&ids, &codes))
+
+		i, _ := utils.AnyToStringSlice(ids)
+		c, _ := utils.AnyToInt8Slice(codes)
+
+		return i, c
+	}
+
+	ids, codes := articles("a", 1)
+	assert.Equal(t, []string{"13.3.A"}, ids)
+	assert.Equal(t, []int8{13}, codes)
+
+	ids, codes = articles("a", 2)
+	assert.Equal(t, []string{"13.4.A"}, ids)
+	assert.Equal(t, []int8{14}, codes)
+
+	ids, _ = articles("a", 3)
+	assert.Equal(t, []string{"13.3.A"}, ids)
+
+	// 18.1 wasn't in force yet: flagged and left alone
+	ids, _ = articles("b", 1)
+	assert.Equal(t, []string{"21.3.1", "18.1"}, ids)
+
+	var (
+		description, articleID string
+		offenses               int
+	)
+
+	require.NoError(t, db.QueryRow("SELECT description, article_id, offenses FROM article_conflicts").
+		Scan(&description, &articleID, &offenses))
+	assert.Equal(t, "SIN CASCO, ESTACIONAR", description)
+	assert.Equal(t, "18.1", articleID)
+	assert.Equal(t, 1, offenses)
+
+	// a second run has nothing left to change
+	n, err = repo.applyArticleVigency()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
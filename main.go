@@ -7,8 +7,17 @@ import (
 	"github.com/jcodagnone/chapauy/cmd"
 )
 
-var Version = "development"
+// Populated at build time via -ldflags (see Makefile).
+var (
+	Version = "development"
+	Commit  = ""
+	Date    = ""
+)
 
 func main() {
-	cmd.Execute(Version)
+	cmd.Execute(cmd.BuildInfo{
+		Version: Version,
+		Commit:  Commit,
+		Date:    Date,
+	})
 }
@@ -0,0 +1,171 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opendata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CKAN pushes the exported resources to a CKAN instance (the software behind
+// datos.gub.uy) using its action API.
+type CKAN struct {
+	BaseURL string // e.g. https://catalogodatos.gub.uy
+	APIKey  string
+	Client  *http.Client
+}
+
+type ckanResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ckanResponse struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+func (c *CKAN) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+
+	return http.DefaultClient
+}
+
+func (c *CKAN) do(req *http.Request, result any) error {
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", c.APIKey)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("calling %s: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	var r ckanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decoding %s response (status %d): %w", req.URL.Path, resp.StatusCode, err)
+	}
+
+	if !r.Success {
+		return fmt.Errorf("%s failed (status %d): %s", req.URL.Path, resp.StatusCode, r.Error)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(r.Result, result); err != nil {
+			return fmt.Errorf("decoding %s result: %w", req.URL.Path, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *CKAN) action(name string) string {
+	return strings.TrimSuffix(c.BaseURL, "/") + "/api/3/action/" + name
+}
+
+// resources returns the resources of the dataset, keyed by name.
+func (c *CKAN) resources(dataset string) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.action("package_show")+"?id="+url.QueryEscape(dataset), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating package_show request: %w", err)
+	}
+
+	var pkg struct {
+		Resources []ckanResource `json:"resources"`
+	}
+	if err := c.do(req, &pkg); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]string, len(pkg.Resources))
+	for _, r := range pkg.Resources {
+		ret[r.Name] = r.ID
+	}
+
+	return ret, nil
+}
+
+// upload creates the resource, or replaces its file when id is not empty.
+func (c *CKAN) upload(dataset, id string, r Resource) error {
+	f, err := os.Open(filepath.Clean(r.Path))
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", r.Path, err)
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	fields := map[string]string{"name": r.Name(), "format": "CSV", "mimetype": "text/csv"}
+
+	action := "resource_create"
+	if id == "" {
+		fields["package_id"] = dataset
+	} else {
+		fields["id"] = id
+		action = "resource_update"
+	}
+
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return fmt.Errorf("writing field %s: %w", k, err)
+		}
+	}
+
+	part, err := mw.CreateFormFile("upload", filepath.Base(r.Path))
+	if err != nil {
+		return fmt.Errorf("creating upload part: %w", err)
+	}
+
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("copying %s: %w", r.Path, err)
+	}
+
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("closing multipart body: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.action(action), &body)
+	if err != nil {
+		return fmt.Errorf("creating %s request: %w", action, err)
+	}
+
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return c.do(req, nil)
+}
+
+// Publish uploads the resources to the dataset, replacing the ones with the
+// same name.
+func (c *CKAN) Publish(dataset string, resources []Resource) error {
+	if c.BaseURL == "" || dataset == "" {
+		return errors.New("ckan: base url and dataset are required")
+	}
+
+	existing, err := c.resources(dataset)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resources {
+		if err := c.upload(dataset, existing[r.Name()], r); err != nil {
+			return fmt.Errorf("publishing %s: %w", r.Name(), err)
+		}
+	}
+
+	return nil
+}
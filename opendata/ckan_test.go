@@ -0,0 +1,62 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opendata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCKANPublish(t *testing.T) {
+	var calls []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		calls = append(calls, r.URL.Path)
+
+		var result any
+
+		switch r.URL.Path {
+		case "/api/3/action/package_show":
+			assert.Equal(t, "infracciones", r.URL.Query().Get("id"))
+			result = map[string]any{"resources": []map[string]string{{"id": "r2024", "name": "Infracciones 2024"}}}
+		case "/api/3/action/resource_update":
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			assert.Equal(t, "r2024", r.FormValue("id"))
+		case "/api/3/action/resource_create":
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			assert.Equal(t, "infracciones", r.FormValue("package_id"))
+			assert.Equal(t, "Infracciones 2025", r.FormValue("name"))
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "result": result})
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	var resources []Resource
+
+	for _, year := range []int{2024, 2025} {
+		r := Resource{Year: year, Path: filepath.Join(dir, "r.csv")}
+		require.NoError(t, os.WriteFile(r.Path, []byte("a,b\n"), 0o600))
+		resources = append(resources, r)
+	}
+
+	c := &CKAN{BaseURL: srv.URL, APIKey: "secret"}
+	require.NoError(t, c.Publish("infracciones", resources))
+	assert.Equal(t, []string{
+		"/api/3/action/package_show",
+		"/api/3/action/resource_update",
+		"/api/3/action/resource_create",
+	}, calls)
+}
@@ -0,0 +1,424 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package opendata exports the offenses dataset in the structure expected by
+// the national open data portal (datos.gub.uy): one CSV resource per year and
+// a DCAT metadata document describing them.
+package opendata
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/spatial"
+)
+
+// Options describe the published dataset.
+type Options struct {
+	Title       string
+	Description string
+	Publisher   string
+	License     string
+	Landing     string // landing page of the dataset
+	BaseURL     string // where the resources will be downloadable, used for downloadURL
+	Profile     string // ProfileFull (default) or ProfileAnonymized
+}
+
+// Export profiles.
+const (
+	// ProfileFull exports the offenses as published in the Diario Oficial.
+	ProfileFull = "full"
+	// ProfileAnonymized masks the plates (keeping the department letter of
+	// the Uruguayan ones), drops the intervention IDs and the references to
+	// the published document and truncates the time to the hour, for a
+	// dataset that can be distributed widely.
+	ProfileAnonymized = "anonymized"
+)
+
+// ErrUnknownProfile is returned by Export for an unsupported profile.
+var ErrUnknownProfile = errors.New("unknown export profile")
+
+// DefaultOptions returns the metadata used for the ChapaUY dataset.
+func DefaultOptions() Options {
+	return Options{
+		Title: "Infracciones de tránsito publicadas en el Diario Oficial",
+		Description: "Infracciones de tránsito notificadas por las intendencias y la Policía Caminera " +
+			"en el Diario Oficial (IMPO), normalizadas y geolocalizadas por ChapaUY.",
+		Publisher: "ChapaUY",
+		License:   "https://creativecommons.org/licenses/by/4.0/",
+		Landing:   "https://github.com/jcodagnone/chapauy",
+	}
+}
+
+// columns of the CSV resources, in order.
+var columns = []string{
+	"departamento",
+	"db_id",
+	"doc_id",
+	"doc_date",
+	"doc_source",
+	"record_id",
+	"offense_id",
+	"vehicle",
+	"vehicle_country",
+	"vehicle_type",
+	"time",
+	"location",
+	"description",
+	"ur",
+	"article_ids",
+	"lat",
+	"lng",
+}
+
+// droppedColumns are left out of the CSV resources of a profile. The
+// document, its date and the row point back to the publication in IMPO,
+// which has the plate.
+var droppedColumns = map[string][]string{
+	ProfileAnonymized: {"doc_id", "doc_date", "doc_source", "record_id", "offense_id"},
+}
+
+// profileColumns returns the indexes in columns exported by the profile.
+func profileColumns(profile string) []int {
+	var ret []int
+
+	for i, c := range columns {
+		if !slices.Contains(droppedColumns[profile], c) {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// MaskVehicle hides the plate keeping its length and, for Uruguayan plates,
+// the leading letter of the department that issued it.
+func MaskVehicle(plate, country string) string {
+	if plate == "" {
+		return ""
+	}
+
+	plate = impo.NormalizeVehicleID(plate)
+	keep := 0
+
+	if info, err := impo.AnalyzeVehicleID(plate, country); err == nil && info.Country == "UY" &&
+		info.AdmDivision != "" && strings.HasPrefix(plate, info.AdmDivision) {
+		keep = len(info.AdmDivision)
+	}
+
+	return plate[:keep] + strings.Repeat("*", len(plate)-keep)
+}
+
+// Resource is a file of the exported dataset.
+type Resource struct {
+	Year    int
+	Path    string
+	Records int
+}
+
+// Name is the title of the resource in the portal.
+func (r Resource) Name() string {
+	return fmt.Sprintf("Infracciones %d", r.Year)
+}
+
+// Export writes one CSV file per year (infracciones-YYYY.csv) and the DCAT
+// metadata (dcat.json) to dir. Only active offenses without extraction errors
+// are exported.
+func Export(db *sql.DB, dir string, opts Options) ([]Resource, error) {
+	if opts.Profile == "" {
+		opts.Profile = ProfileFull
+	}
+
+	if opts.Profile != ProfileFull && opts.Profile != ProfileAnonymized {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, opts.Profile)
+	}
+
+	anonymized := opts.Profile == ProfileAnonymized
+	exported := profileColumns(opts.Profile)
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	rows, err := db.Query(`
+		SELECT db_id, COALESCE(doc_id, ''), doc_date, doc_source, record_id,
+			COALESCE(offense_id, ''), COALESCE(vehicle, ''), COALESCE(vehicle_country, ''),
+			COALESCE(vehicle_type, ''), "time", COALESCE(display_location, location, ''),
+			COALESCE(description, ''), COALESCE(ur, 0), COALESCE(array_to_string(article_ids, ';'), ''),
+			point
+		FROM active_offenses
+		WHERE error IS NULL AND "time" IS NOT NULL
+		ORDER BY "time", db_id, doc_source, record_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses: %w", err)
+	}
+	defer rows.Close()
+
+	writers := make(map[int]*yearWriter)
+
+	defer func() {
+		for _, w := range writers {
+			_ = w.f.Close()
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			dbID, recordID, ur                   int
+			docID, docSource, offenseID, vehicle string
+			country, vehicleType, location       string
+			description, articles                string
+			docDate                              sql.NullTime
+			when                                 time.Time
+			point                                sql.Null[spatial.Point]
+		)
+
+		if err := rows.Scan(
+			&dbID, &docID, &docDate, &docSource, &recordID, &offenseID, &vehicle, &country,
+			&vehicleType, &when, &location, &description, &ur, &articles, &point,
+		); err != nil {
+			return nil, fmt.Errorf("scanning offense: %w", err)
+		}
+
+		// the year of time_year, not the one of the UTC time
+		year := when.In(impo.UruguayTimezone).Year()
+
+		w, ok := writers[year]
+		if !ok {
+			if w, err = newYearWriter(dir, year, exported); err != nil {
+				return nil, err
+			}
+
+			writers[year] = w
+		}
+
+		department, _ := impo.GetDBName(dbID)
+
+		var lat, lng string
+		if point.Valid {
+			lat = strconv.FormatFloat(point.V.Lat, 'f', -1, 64)
+			lng = strconv.FormatFloat(point.V.Lng, 'f', -1, 64)
+		}
+
+		var date string
+		if docDate.Valid {
+			date = docDate.Time.Format(time.DateOnly)
+		}
+
+		if anonymized {
+			vehicle = MaskVehicle(vehicle, country)
+			when = when.Truncate(time.Hour)
+		}
+
+		if err := w.write(pick([]string{
+			department,
+			strconv.Itoa(dbID),
+			docID,
+			date,
+			docSource,
+			strconv.Itoa(recordID),
+			offenseID,
+			vehicle,
+			country,
+			vehicleType,
+			when.Format(time.RFC3339),
+			location,
+			description,
+			impo.UR(ur).String(), // in UR, not in thousandths
+			articles,
+			lat,
+			lng,
+		}, exported)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating offenses: %w", err)
+	}
+
+	resources := make([]Resource, 0, len(writers))
+
+	var errs []error
+
+	for year, w := range writers {
+		errs = append(errs, w.close())
+		resources = append(resources, Resource{Year: year, Path: w.f.Name(), Records: w.n})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(resources, func(a, b Resource) int { return a.Year - b.Year })
+
+	if err := writeDCAT(filepath.Join(dir, "dcat.json"), opts, resources, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+func pick(record []string, indexes []int) []string {
+	ret := make([]string, len(indexes))
+	for i, j := range indexes {
+		ret[i] = record[j]
+	}
+
+	return ret
+}
+
+type yearWriter struct {
+	f *os.File
+	w *csv.Writer
+	n int
+}
+
+func newYearWriter(dir string, year int, exported []int) (*yearWriter, error) {
+	path := filepath.Join(dir, fmt.Sprintf("infracciones-%d.csv", year))
+
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", path, err)
+	}
+
+	w := &yearWriter{f: f, w: csv.NewWriter(f)}
+	if err := w.w.Write(pick(columns, exported)); err != nil {
+		return nil, fmt.Errorf("writing header of %s: %w", path, err)
+	}
+
+	return w, nil
+}
+
+func (w *yearWriter) write(record []string) error {
+	w.n++
+
+	if err := w.w.Write(record); err != nil {
+		return fmt.Errorf("writing %s: %w", w.f.Name(), err)
+	}
+
+	return nil
+}
+
+func (w *yearWriter) close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return fmt.Errorf("writing %s: %w", w.f.Name(), err)
+	}
+
+	return w.f.Close()
+}
+
+// DCAT vocabulary, serialized as JSON-LD.
+type dcatCatalog struct {
+	Context string      `json:"@context"`
+	Type    string      `json:"@type"`
+	Dataset dcatDataset `json:"dcat:dataset"`
+}
+
+type dcatDataset struct {
+	Type         string             `json:"@type"`
+	Title        string             `json:"dct:title"`
+	Description  string             `json:"dct:description"`
+	Publisher    dcatAgent          `json:"dct:publisher"`
+	License      string             `json:"dct:license"`
+	LandingPage  string             `json:"dcat:landingPage,omitempty"`
+	Language     string             `json:"dct:language"`
+	Spatial      string             `json:"dct:spatial"`
+	Keywords     []string           `json:"dcat:keyword"`
+	Modified     string             `json:"dct:modified"`
+	Temporal     dcatPeriod         `json:"dct:temporal"`
+	Distribution []dcatDistribution `json:"dcat:distribution"`
+}
+
+type dcatAgent struct {
+	Type string `json:"@type"`
+	Name string `json:"foaf:name"`
+}
+
+type dcatPeriod struct {
+	Type      string `json:"@type"`
+	StartDate string `json:"dcat:startDate"`
+	EndDate   string `json:"dcat:endDate"`
+}
+
+type dcatDistribution struct {
+	Type        string `json:"@type"`
+	Title       string `json:"dct:title"`
+	Format      string `json:"dct:format"`
+	MediaType   string `json:"dcat:mediaType"`
+	DownloadURL string `json:"dcat:downloadURL,omitempty"`
+	Modified    string `json:"dct:modified"`
+}
+
+func writeDCAT(path string, opts Options, resources []Resource, now time.Time) error {
+	ds := dcatDataset{
+		Type:        "dcat:Dataset",
+		Title:       opts.Title,
+		Description: opts.Description,
+		Publisher:   dcatAgent{Type: "foaf:Organization", Name: opts.Publisher},
+		License:     opts.License,
+		LandingPage: opts.Landing,
+		Language:    "es",
+		Spatial:     "Uruguay",
+		Keywords:    []string{"tránsito", "infracciones", "multas", "IMPO"},
+		Modified:    now.UTC().Format(time.RFC3339),
+	}
+
+	if opts.Profile == ProfileAnonymized {
+		ds.Description += " Versión anonimizada: matrículas enmascaradas (se conserva la letra del departamento), " +
+			"sin identificadores de intervención ni referencias al documento publicado y con la hora truncada."
+	}
+
+	if len(resources) > 0 {
+		ds.Temporal = dcatPeriod{
+			Type:      "dct:PeriodOfTime",
+			StartDate: strconv.Itoa(resources[0].Year),
+			EndDate:   strconv.Itoa(resources[len(resources)-1].Year),
+		}
+	}
+
+	for _, r := range resources {
+		var downloadURL string
+		if opts.BaseURL != "" {
+			downloadURL = strings.TrimSuffix(opts.BaseURL, "/") + "/" + filepath.Base(r.Path)
+		}
+
+		ds.Distribution = append(ds.Distribution, dcatDistribution{
+			Type:        "dcat:Distribution",
+			Title:       r.Name(),
+			Format:      "CSV",
+			MediaType:   "text/csv",
+			DownloadURL: downloadURL,
+			Modified:    ds.Modified,
+		})
+	}
+
+	catalog := dcatCatalog{
+		Context: "https://www.w3.org/ns/dcat.jsonld",
+		Type:    "dcat:Catalog",
+		Dataset: ds,
+	}
+
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling DCAT metadata: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opendata
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// a minimal stand-in of the offenses schema, without the spatial extension
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_date DATE, doc_source VARCHAR, record_id INTEGER,
+			offense_id VARCHAR, vehicle VARCHAR, vehicle_country VARCHAR, vehicle_type VARCHAR,
+			"time" TIMESTAMPTZ, location VARCHAR, display_location VARCHAR, description VARCHAR,
+			ur INTEGER, error VARCHAR, article_ids VARCHAR[], point STRUCT(x DOUBLE, y DOUBLE),
+			superseded_by VARCHAR
+		);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO offenses VALUES
+			(45, '1/024', '2024-03-01', 'doc1', 1, 'A1', 'AAA1111', 'UY', 'AUTO', '2024-02-10 10:00:00+00',
+			 'Ruta 10', NULL, 'Exceso de velocidad', 2500, NULL, ['18.1'], {'x': -54.9, 'y': -34.9}, NULL),
+			(45, '1/025', '2025-03-01', 'doc2', 1, 'A2', 'BBB2222', 'UY', 'AUTO', '2025-02-10 10:00:00+00',
+			 'Ruta 10', NULL, 'Mal estacionado', 10, NULL, NULL, NULL, NULL),
+			(45, '1/025', '2025-03-01', 'doc2', 2, NULL, NULL, NULL, NULL, NULL,
+			 NULL, NULL, NULL, NULL, 'parse error', NULL, NULL, NULL),
+			(45, '1/025', '2025-03-01', 'doc3', 1, 'A2', 'BBB2222', 'UY', 'AUTO', '2025-02-10 10:00:00+00',
+			 'Ruta 10', NULL, 'Mal estacionado', 10, NULL, NULL, NULL, 'doc2');
+	`)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.BaseURL = "https://example.com/data/"
+
+	resources, err := Export(db, dir, opts)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, 2024, resources[0].Year)
+	assert.Equal(t, 1, resources[0].Records)
+	assert.Equal(t, 1, resources[1].Records)
+
+	f, err := os.Open(filepath.Join(dir, "infracciones-2024.csv"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, columns, records[0])
+	assert.Equal(t, "Maldonado", records[1][0])
+	assert.Equal(t, "2.5", records[1][13])
+	assert.Equal(t, "18.1", records[1][14])
+	assert.Equal(t, "-34.9", records[1][15])
+
+	data, err := os.ReadFile(filepath.Join(dir, "dcat.json"))
+	require.NoError(t, err)
+
+	var catalog dcatCatalog
+	require.NoError(t, json.Unmarshal(data, &catalog))
+	require.Len(t, catalog.Dataset.Distribution, 2)
+	assert.Equal(t, "https://example.com/data/infracciones-2025.csv", catalog.Dataset.Distribution[1].DownloadURL)
+	assert.Equal(t, "2024", catalog.Dataset.Temporal.StartDate)
+}
+
+func TestExport_NewYearsEve(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// 2024-12-31 22:00 in Uruguay, already 2025 in UTC
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_date DATE, doc_source VARCHAR, record_id INTEGER,
+			offense_id VARCHAR, vehicle VARCHAR, vehicle_country VARCHAR, vehicle_type VARCHAR,
+			"time" TIMESTAMPTZ, location VARCHAR, display_location VARCHAR, description VARCHAR,
+			ur INTEGER, error VARCHAR, article_ids VARCHAR[], point STRUCT(x DOUBLE, y DOUBLE),
+			superseded_by VARCHAR
+		);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO offenses VALUES
+			(45, '1/025', '2025-01-10', 'doc1', 1, 'A1', 'AAA1111', 'UY', 'AUTO', '2024-12-31 22:00:00-03',
+			 'Ruta 10', NULL, 'Exceso de velocidad', 2500, NULL, NULL, NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	resources, err := Export(db, dir, DefaultOptions())
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, 2024, resources[0].Year)
+	assert.FileExists(t, filepath.Join(dir, "infracciones-2024.csv"))
+	assert.NoFileExists(t, filepath.Join(dir, "infracciones-2025.csv"))
+}
+
+func TestExport_Anonymized(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_date DATE, doc_source VARCHAR, record_id INTEGER,
+			offense_id VARCHAR, vehicle VARCHAR, vehicle_country VARCHAR, vehicle_type VARCHAR,
+			"time" TIMESTAMPTZ, location VARCHAR, display_location VARCHAR, description VARCHAR,
+			ur INTEGER, error VARCHAR, article_ids VARCHAR[], point STRUCT(x DOUBLE, y DOUBLE),
+			superseded_by VARCHAR
+		);
+		CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
+		INSERT INTO offenses VALUES
+			(45, '1/024', '2024-03-01', 'doc1', 1, 'A1', 'SBC1234', 'UY', 'AUTO', '2024-02-10 10:42:17+00',
+			 'Ruta 10', NULL, 'Exceso de velocidad', 2500, NULL, ['18.1'], NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.Profile = ProfileAnonymized
+
+	_, err = Export(db, dir, opts)
+	require.NoError(t, err)
+
+	f, err := os.Open(filepath.Join(dir, "infracciones-2024.csv"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	// nothing points back to the publication, which has the plate
+	for _, c := range []string{"offense_id", "doc_id", "doc_date", "doc_source", "record_id"} {
+		assert.NotContains(t, records[0], c)
+	}
+
+	row := make(map[string]string)
+	for i, c := range records[0] {
+		row[c] = records[1][i]
+	}
+
+	assert.Equal(t, "S******", row["vehicle"])
+	assert.Equal(t, "UY", row["vehicle_country"])
+	assert.Equal(t, "AUTO", row["vehicle_type"])
+	assert.Equal(t, time.Date(2024, 2, 10, 10, 0, 0, 0, time.UTC), mustParseTime(t, row["time"]).UTC())
+
+	opts.Profile = "bogus"
+	_, err = Export(db, dir, opts)
+	assert.ErrorIs(t, err, ErrUnknownProfile)
+}
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	when, err := time.Parse(time.RFC3339, s)
+	require.NoError(t, err)
+
+	return when
+}
+
+func TestMaskVehicle(t *testing.T) {
+	assert.Equal(t, "S******", MaskVehicle("SBC1234", "UY"))
+	assert.Equal(t, "A*****", MaskVehicle("ABC123", ""))
+	assert.Equal(t, "*******", MaskVehicle("AB123CD", "AR"))
+	assert.Empty(t, MaskVehicle("", "UY"))
+}
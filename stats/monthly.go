@@ -0,0 +1,343 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// Formats of the monthly report.
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+//go:embed templates/monthly.md templates/monthly.html
+var monthlyTemplates embed.FS
+
+var monthlyFuncs = map[string]any{
+	"change": Change,
+	"inc":    func(i int) int { return i + 1 },
+	// escapes the pipes of the Markdown tables
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+}
+
+var (
+	monthlyMarkdownTemplate = template.Must(
+		template.New("monthly.md").Funcs(monthlyFuncs).ParseFS(monthlyTemplates, "templates/monthly.md"),
+	)
+	monthlyHTMLTemplate = htmltemplate.Must(
+		htmltemplate.New("monthly.html").Funcs(monthlyFuncs).ParseFS(monthlyTemplates, "templates/monthly.html"),
+	)
+)
+
+var spanishMonths = [...]string{
+	"enero", "febrero", "marzo", "abril", "mayo", "junio",
+	"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+}
+
+// MonthlyDepartment are the offenses a database published in the month and in
+// the previous one.
+type MonthlyDepartment struct {
+	DbID         int
+	Name         string
+	Offenses     int
+	PrevOffenses int
+	UR           impo.UR
+}
+
+// LocationCount are the offenses of a location, usually a speed camera.
+type LocationCount struct {
+	DbID     int
+	Name     string // of the database
+	Location string
+	Offenses int
+	UR       impo.UR
+}
+
+// Fine is one of the largest fines of the month. The plate is left out on
+// purpose: the report is meant to be published.
+type Fine struct {
+	DbID        int
+	Name        string // of the database
+	DocID       string
+	DocSource   string
+	Description string
+	Location    string
+	UR          impo.UR
+}
+
+// ArticleTrend is the change of the offenses of an article between the month
+// and the previous one.
+type ArticleTrend struct {
+	ArticleID    string
+	Offenses     int
+	PrevOffenses int
+}
+
+// MonthlyReport is the digest of the offenses published in a month, compared
+// with the previous one, for newsletters.
+type MonthlyReport struct {
+	GeneratedAt  time.Time
+	Month        time.Time // first day of the month
+	Offenses     int
+	PrevOffenses int
+	UR           impo.UR
+	PrevUR       impo.UR
+	Departments  []MonthlyDepartment // the ranking, most offenses first
+	TopLocations []LocationCount
+	BiggestFines []Fine
+	Trends       []ArticleTrend // the articles that changed the most
+}
+
+// Title is the month of the report, in Spanish.
+func (r *MonthlyReport) Title() string {
+	return fmt.Sprintf("%s %d", spanishMonths[r.Month.Month()-1], r.Month.Year())
+}
+
+// PrevTitle is the month the report is compared with, in Spanish.
+func (r *MonthlyReport) PrevTitle() string {
+	prev := r.Month.AddDate(0, -1, 0)
+
+	return fmt.Sprintf("%s %d", spanishMonths[prev.Month()-1], prev.Year())
+}
+
+// Change formats the relative change from prev to cur as a signed
+// percentage.
+func Change(cur, prev int) string {
+	if prev == 0 {
+		if cur == 0 {
+			return "="
+		}
+
+		return "nuevo"
+	}
+
+	return fmt.Sprintf("%+.1f%%", pct(cur-prev, prev))
+}
+
+// ParseMonth parses a month written as YYYY-MM.
+func ParseMonth(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", s, impo.UruguayTimezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected YYYY-MM: %w", s, err)
+	}
+
+	return t, nil
+}
+
+// ComputeMonthlyReport aggregates the active offenses published (by document
+// date) in the month of the given date, reporting the topN of every ranking.
+// Offenses are counted by publication because the recent ones are published
+// months later, so the month they were committed is never complete.
+func ComputeMonthlyReport(db *sql.DB, month time.Time, topN int) (*MonthlyReport, error) {
+	month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, impo.UruguayTimezone)
+	r := &MonthlyReport{Month: month}
+
+	from := month.Format(time.DateOnly)
+	to := month.AddDate(0, 1, 0).Format(time.DateOnly)
+	prevFrom := month.AddDate(0, -1, 0).Format(time.DateOnly)
+
+	if err := r.computeDepartments(db, prevFrom, from, to); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if r.TopLocations, err = topLocations(db, from, to, topN); err != nil {
+		return nil, err
+	}
+
+	if r.BiggestFines, err = biggestFines(db, from, to, topN); err != nil {
+		return nil, err
+	}
+
+	if r.Trends, err = articleTrends(db, prevFrom, from, to, topN); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *MonthlyReport) computeDepartments(db *sql.DB, prevFrom, from, to string) error {
+	rows, err := db.Query(`
+		SELECT
+			db_id,
+			COUNT(*) FILTER (WHERE doc_date >= CAST(? AS DATE)),
+			COUNT(*) FILTER (WHERE doc_date < CAST(? AS DATE)),
+			CAST(COALESCE(SUM(ur) FILTER (WHERE doc_date >= CAST(? AS DATE)), 0) AS BIGINT),
+			CAST(COALESCE(SUM(ur) FILTER (WHERE doc_date < CAST(? AS DATE)), 0) AS BIGINT)
+		FROM active_offenses
+		WHERE doc_date >= CAST(? AS DATE) AND doc_date < CAST(? AS DATE)
+		GROUP BY db_id
+	`, from, from, from, from, prevFrom, to)
+	if err != nil {
+		return fmt.Errorf("querying departments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d      MonthlyDepartment
+			prevUR impo.UR
+		)
+
+		if err := rows.Scan(&d.DbID, &d.Offenses, &d.PrevOffenses, &d.UR, &prevUR); err != nil {
+			return fmt.Errorf("scanning departments: %w", err)
+		}
+
+		d.Name = dbName(d.DbID)
+		r.Offenses += d.Offenses
+		r.PrevOffenses += d.PrevOffenses
+		r.UR += d.UR
+		r.PrevUR += prevUR
+		r.Departments = append(r.Departments, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	slices.SortFunc(r.Departments, func(a, b MonthlyDepartment) int {
+		if a.Offenses != b.Offenses {
+			return b.Offenses - a.Offenses
+		}
+
+		return a.DbID - b.DbID
+	})
+
+	return nil
+}
+
+func topLocations(db *sql.DB, from, to string, n int) ([]LocationCount, error) {
+	rows, err := db.Query(`
+		SELECT db_id, COALESCE(display_location, location) AS loc, COUNT(*) AS n,
+			CAST(COALESCE(SUM(ur), 0) AS BIGINT)
+		FROM active_offenses
+		WHERE doc_date >= CAST(? AS DATE) AND doc_date < CAST(? AS DATE)
+			AND COALESCE(display_location, location) IS NOT NULL
+		GROUP BY ALL
+		ORDER BY n DESC, db_id, loc
+		LIMIT ?
+	`, from, to, n)
+	if err != nil {
+		return nil, fmt.Errorf("querying top locations: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []LocationCount
+
+	for rows.Next() {
+		var l LocationCount
+		if err := rows.Scan(&l.DbID, &l.Location, &l.Offenses, &l.UR); err != nil {
+			return nil, fmt.Errorf("scanning top locations: %w", err)
+		}
+
+		l.Name = dbName(l.DbID)
+		ret = append(ret, l)
+	}
+
+	return ret, rows.Err()
+}
+
+func biggestFines(db *sql.DB, from, to string, n int) ([]Fine, error) {
+	rows, err := db.Query(`
+		SELECT db_id, COALESCE(doc_id, ''), doc_source, COALESCE(description, ''),
+			COALESCE(display_location, location, ''), ur
+		FROM active_offenses
+		WHERE doc_date >= CAST(? AS DATE) AND doc_date < CAST(? AS DATE) AND ur IS NOT NULL
+		ORDER BY ur DESC, doc_source, record_id
+		LIMIT ?
+	`, from, to, n)
+	if err != nil {
+		return nil, fmt.Errorf("querying biggest fines: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []Fine
+
+	for rows.Next() {
+		var f Fine
+		if err := rows.Scan(&f.DbID, &f.DocID, &f.DocSource, &f.Description, &f.Location, &f.UR); err != nil {
+			return nil, fmt.Errorf("scanning biggest fines: %w", err)
+		}
+
+		f.Name = dbName(f.DbID)
+		ret = append(ret, f)
+	}
+
+	return ret, rows.Err()
+}
+
+func articleTrends(db *sql.DB, prevFrom, from, to string, n int) ([]ArticleTrend, error) {
+	rows, err := db.Query(`
+		SELECT
+			article_id,
+			COUNT(*) FILTER (WHERE doc_date >= CAST(? AS DATE)) AS cur,
+			COUNT(*) FILTER (WHERE doc_date < CAST(? AS DATE)) AS prev
+		FROM (
+			SELECT unnest(article_ids) AS article_id, doc_date
+			FROM active_offenses
+			WHERE doc_date >= CAST(? AS DATE) AND doc_date < CAST(? AS DATE)
+		)
+		GROUP BY article_id
+		ORDER BY abs(cur - prev) DESC, article_id
+		LIMIT ?
+	`, from, from, prevFrom, to, n)
+	if err != nil {
+		return nil, fmt.Errorf("querying article trends: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []ArticleTrend
+
+	for rows.Next() {
+		var a ArticleTrend
+		if err := rows.Scan(&a.ArticleID, &a.Offenses, &a.PrevOffenses); err != nil {
+			return nil, fmt.Errorf("scanning article trends: %w", err)
+		}
+
+		ret = append(ret, a)
+	}
+
+	return ret, rows.Err()
+}
+
+func dbName(dbID int) string {
+	if name, err := impo.GetDBName(dbID); err == nil {
+		return name
+	}
+
+	return fmt.Sprintf("DB %d", dbID)
+}
+
+// RenderMonthlyReport writes the report as Markdown or HTML.
+func RenderMonthlyReport(w io.Writer, r *MonthlyReport, format string) error {
+	var err error
+
+	switch format {
+	case FormatMarkdown:
+		err = monthlyMarkdownTemplate.Execute(w, r)
+	case FormatHTML:
+		err = monthlyHTMLTemplate.Execute(w, r)
+	default:
+		return fmt.Errorf("unknown format %q, expected %s or %s", format, FormatMarkdown, FormatHTML)
+	}
+
+	if err != nil {
+		return fmt.Errorf("rendering report: %w", err)
+	}
+
+	return nil
+}
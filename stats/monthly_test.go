@@ -0,0 +1,89 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyReport(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_date DATE, doc_source VARCHAR, record_id INTEGER,
+			vehicle VARCHAR, location VARCHAR, display_location VARCHAR, description VARCHAR, ur INTEGER,
+			article_ids VARCHAR[]
+		);
+		INSERT INTO active_offenses
+			SELECT 45, '1/025', '2025-06-10', 'https://impo/1-2025', i, 'AAA' || i, 'GORLERO Y 20', 'Gorlero y 20',
+				'EXCESO DE VELOCIDAD', 5000, ['18.3']
+			FROM range(4) t(i);
+		INSERT INTO active_offenses VALUES
+			(6, '2/025', '2025-06-02', 'https://impo/2-2025', 0, 'SBA1234', 'RAMBLA | PARQUE', NULL,
+				'CRUZAR CON LUZ ROJA', 20000, ['18.5']),
+			(6, '1/025', '2025-05-20', 'https://impo/0-2025', 0, 'SBA1235', 'RAMBLA', NULL, 'EXCESO DE VELOCIDAD', 5000, ['18.3']),
+			(6, '1/025', '2025-05-20', 'https://impo/0-2025', 1, 'SBA1236', 'RAMBLA', NULL, 'EXCESO DE VELOCIDAD', 5000, ['18.3']),
+			(6, '0/025', '2025-04-20', 'https://impo/9-2025', 0, 'SBA1237', 'RAMBLA', NULL, 'EXCESO DE VELOCIDAD', 5000, ['18.3']);
+	`)
+	require.NoError(t, err)
+
+	month, err := ParseMonth("2025-06")
+	require.NoError(t, err)
+
+	r, err := ComputeMonthlyReport(db, month, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, "junio 2025", r.Title())
+	assert.Equal(t, "mayo 2025", r.PrevTitle())
+	assert.Equal(t, 5, r.Offenses)
+	assert.Equal(t, 2, r.PrevOffenses)
+	assert.Equal(t, impo.UR(40000), r.UR)
+	assert.Equal(t, []MonthlyDepartment{
+		{DbID: 45, Name: "Maldonado", Offenses: 4, UR: 20000},
+		{DbID: 6, Name: "Montevideo", Offenses: 1, PrevOffenses: 2, UR: 20000},
+	}, r.Departments)
+
+	require.Len(t, r.TopLocations, 2)
+	assert.Equal(t, "Gorlero y 20", r.TopLocations[0].Location)
+	assert.Equal(t, 4, r.TopLocations[0].Offenses)
+
+	require.Len(t, r.BiggestFines, 3)
+	assert.Equal(t, "CRUZAR CON LUZ ROJA", r.BiggestFines[0].Description)
+
+	assert.Equal(t, []ArticleTrend{
+		{ArticleID: "18.3", Offenses: 4, PrevOffenses: 2},
+		{ArticleID: "18.5", Offenses: 1},
+	}, r.Trends)
+
+	var md strings.Builder
+	require.NoError(t, RenderMonthlyReport(&md, r, FormatMarkdown))
+	assert.Contains(t, md.String(), "# Infracciones de tránsito publicadas en junio 2025")
+	assert.Contains(t, md.String(), "| 1 | Maldonado | 4 | 20 | nuevo |")
+	assert.Contains(t, md.String(), "| 2 | Montevideo | 1 | 20 | -50.0% |")
+	assert.Contains(t, md.String(), `RAMBLA \| PARQUE`)
+	assert.NotContains(t, md.String(), "SBA1234")
+
+	var html strings.Builder
+	require.NoError(t, RenderMonthlyReport(&html, r, FormatHTML))
+	assert.Contains(t, html.String(), `<a href="https://impo/2-2025"`)
+	assert.NotContains(t, html.String(), "SBA1234")
+
+	assert.Error(t, RenderMonthlyReport(&html, r, "pdf"))
+}
+
+func TestChange(t *testing.T) {
+	assert.Equal(t, "+50.0%", Change(3, 2))
+	assert.Equal(t, "-100.0%", Change(0, 2))
+	assert.Equal(t, "nuevo", Change(1, 0))
+	assert.Equal(t, "=", Change(0, 0))
+}
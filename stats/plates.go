@@ -0,0 +1,117 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// PlateLetters are the Uruguayan plates of a database by their first letter.
+type PlateLetters struct {
+	DbID     int
+	Total    int
+	ByLetter map[string]int
+}
+
+// PlatePrefix is a first letter of Uruguayan plates that isn't mapped to a
+// department, usually a new series.
+type PlatePrefix struct {
+	Letter   string
+	Count    int
+	Mercosur int   // how many of them are in Mercosur format
+	DbIDs    []int // databases where it was seen, ascending
+}
+
+// PlateReport cross-tabulates the first letter of the Uruguayan plates against
+// the database that issued the offense, to validate the department mapping.
+type PlateReport struct {
+	Letters   []string // every letter seen, ascending
+	Databases []PlateLetters
+	Unknown   []PlatePrefix // letters without department, most seen first
+}
+
+// ComputePlateReport aggregates the plates of the active offenses whose
+// country is Uruguay and start with a letter.
+func ComputePlateReport(db *sql.DB) (*PlateReport, error) {
+	rows, err := db.Query(`
+		SELECT
+			db_id,
+			upper(left(vehicle, 1)) AS letter,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE regexp_matches(upper(vehicle), '^[A-Z]{3}[0-9]{4}$'))
+		FROM active_offenses
+		WHERE vehicle_country = ? AND regexp_matches(upper(vehicle), '^[A-Z]')
+		GROUP BY ALL
+		ORDER BY db_id, letter
+	`, impo.ISOUruguay)
+	if err != nil {
+		return nil, fmt.Errorf("querying plate letters: %w", err)
+	}
+	defer rows.Close()
+
+	r := &PlateReport{}
+	letters := make(map[string]bool)
+	unknown := make(map[string]*PlatePrefix)
+
+	for rows.Next() {
+		var (
+			dbID, count, mercosur int
+			letter                string
+		)
+
+		if err := rows.Scan(&dbID, &letter, &count, &mercosur); err != nil {
+			return nil, fmt.Errorf("scanning plate letters: %w", err)
+		}
+
+		if n := len(r.Databases); n == 0 || r.Databases[n-1].DbID != dbID {
+			r.Databases = append(r.Databases, PlateLetters{DbID: dbID, ByLetter: make(map[string]int)})
+		}
+
+		d := &r.Databases[len(r.Databases)-1]
+		d.ByLetter[letter] = count
+		d.Total += count
+
+		if !letters[letter] {
+			letters[letter] = true
+			r.Letters = append(r.Letters, letter)
+		}
+
+		if !impo.IsUruguayDepartment(letter) {
+			p, ok := unknown[letter]
+			if !ok {
+				p = &PlatePrefix{Letter: letter}
+				unknown[letter] = p
+			}
+
+			p.Count += count
+			p.Mercosur += mercosur
+			p.DbIDs = append(p.DbIDs, dbID)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(r.Letters)
+
+	for _, p := range unknown {
+		r.Unknown = append(r.Unknown, *p)
+	}
+
+	slices.SortFunc(r.Unknown, func(a, b PlatePrefix) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+
+		return strings.Compare(a.Letter, b.Letter)
+	})
+
+	return r, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputePlateReport(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (db_id INTEGER, vehicle VARCHAR, vehicle_country CHAR(2));
+		INSERT INTO active_offenses VALUES
+			(45, 'SBC1234', 'UY'),
+			(45, 'SAA123', 'UY'),
+			(45, 'AAB1234', 'UY'),
+			(45, '123456', 'UY'),
+			(45, 'ABC123', 'AR'),
+			(45, 'ZXA1234', 'UY'),
+			(57, 'zxb1234', 'UY'),
+			(57, 'T1234', 'UY'),
+			(57, 'BAA1234', 'UY');
+	`)
+	require.NoError(t, err)
+
+	r, err := ComputePlateReport(db)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B", "S", "T", "Z"}, r.Letters)
+	assert.Equal(t, []PlateLetters{
+		{DbID: 45, Total: 4, ByLetter: map[string]int{"A": 1, "S": 2, "Z": 1}},
+		{DbID: 57, Total: 3, ByLetter: map[string]int{"B": 1, "T": 1, "Z": 1}},
+	}, r.Databases)
+	assert.Equal(t, []PlatePrefix{
+		{Letter: "Z", Count: 2, Mercosur: 2, DbIDs: []int{45, 57}},
+		{Letter: "T", Count: 1, DbIDs: []int{57}},
+	}, r.Unknown)
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// RepeatOffendersFile is the name of the distribution written next to the
+// database.
+const RepeatOffendersFile = "repeat_offenders.json"
+
+// RepeatBuckets are the ranges of offenses per plate of the distribution.
+var RepeatBuckets = []string{"1", "2-5", "6-10", ">10"}
+
+// PlateBucket are the plates with a number of offenses in the range of Label,
+// and the offenses they add up to.
+type PlateBucket struct {
+	Label    string `json:"label"`
+	Plates   int    `json:"plates"`
+	Offenses int    `json:"offenses"`
+}
+
+// RepeatOffenders is how concentrated the offenses of a database are among
+// the plates in a year. Only counts are kept, never the plates.
+type RepeatOffenders struct {
+	DbID     int           `json:"db_id"`
+	Name     string        `json:"name"`
+	Year     int           `json:"year"`
+	Plates   int           `json:"plates"`
+	Offenses int           `json:"offenses"`
+	Buckets  []PlateBucket `json:"buckets"` // in the order of RepeatBuckets
+}
+
+// RepeatShare is the share of the offenses committed by plates with more
+// than one, as a percentage.
+func (r *RepeatOffenders) RepeatShare() float64 {
+	return pct(r.Offenses-r.Buckets[0].Offenses, r.Offenses)
+}
+
+// RepeatOffendersReport is the distribution of offenses per plate of every
+// database and year.
+type RepeatOffendersReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Rows        []RepeatOffenders `json:"rows"`
+}
+
+// ComputeRepeatOffenders aggregates the active offenses per plate, database
+// and year, and counts the plates of every bucket.
+func ComputeRepeatOffenders(db *sql.DB, now time.Time) (*RepeatOffendersReport, error) {
+	rows, err := db.Query(`
+		SELECT
+			db_id,
+			time_year,
+			CASE WHEN n = 1 THEN 0 WHEN n <= 5 THEN 1 WHEN n <= 10 THEN 2 ELSE 3 END AS bucket,
+			COUNT(*),
+			CAST(SUM(n) AS BIGINT)
+		FROM (
+			SELECT db_id, time_year, upper(vehicle), COUNT(*) AS n
+			FROM active_offenses
+			WHERE vehicle IS NOT NULL AND vehicle <> '' AND time_year IS NOT NULL
+			GROUP BY ALL
+		)
+		GROUP BY ALL
+		ORDER BY db_id, time_year, bucket
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses per plate: %w", err)
+	}
+	defer rows.Close()
+
+	r := &RepeatOffendersReport{GeneratedAt: now}
+
+	for rows.Next() {
+		var dbID, year, bucket, plates, offenses int
+		if err := rows.Scan(&dbID, &year, &bucket, &plates, &offenses); err != nil {
+			return nil, fmt.Errorf("scanning offenses per plate: %w", err)
+		}
+
+		if n := len(r.Rows); n == 0 || r.Rows[n-1].DbID != dbID || r.Rows[n-1].Year != year {
+			row := RepeatOffenders{DbID: dbID, Name: dbName(dbID), Year: year}
+			for _, label := range RepeatBuckets {
+				row.Buckets = append(row.Buckets, PlateBucket{Label: label})
+			}
+
+			r.Rows = append(r.Rows, row)
+		}
+
+		row := &r.Rows[len(r.Rows)-1]
+		row.Buckets[bucket].Plates = plates
+		row.Buckets[bucket].Offenses = offenses
+		row.Plates += plates
+		row.Offenses += offenses
+	}
+
+	return r, rows.Err()
+}
+
+// WriteRepeatOffenders computes the distribution and writes it as JSON to
+// path.
+func WriteRepeatOffenders(db *sql.DB, path string, now time.Time) error {
+	r, err := ComputeRepeatOffenders(db, now)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding repeat offenders: %w", err)
+	}
+
+	// #nosec G306 - public data, served by the web
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("writing repeat offenders: %w", err)
+	}
+
+	return nil
+}
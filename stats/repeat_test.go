@@ -0,0 +1,60 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRepeatOffenders(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (db_id INTEGER, time_year USMALLINT, vehicle VARCHAR);
+		INSERT INTO active_offenses SELECT 45, 2025, 'AAA0001' FROM range(12);
+		INSERT INTO active_offenses SELECT 45, 2025, 'AAA0002' FROM range(3);
+		INSERT INTO active_offenses VALUES
+			(45, 2025, 'aaa0002'),
+			(45, 2025, 'AAA0003'),
+			(45, 2025, ''),
+			(45, 2024, 'AAA0001'),
+			(6, 2025, 'SBA1234'),
+			(6, NULL, 'SBA1235');
+	`)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), RepeatOffendersFile)
+	require.NoError(t, WriteRepeatOffenders(db, path, now))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "AAA0001")
+
+	var r RepeatOffendersReport
+	require.NoError(t, json.Unmarshal(b, &r))
+	require.Len(t, r.Rows, 3)
+
+	assert.Equal(t, RepeatOffenders{
+		DbID: 6, Name: "Montevideo", Year: 2025, Plates: 1, Offenses: 1,
+		Buckets: []PlateBucket{{"1", 1, 1}, {"2-5", 0, 0}, {"6-10", 0, 0}, {">10", 0, 0}},
+	}, r.Rows[0])
+	assert.Equal(t, 2024, r.Rows[1].Year)
+
+	maldonado := r.Rows[2]
+	assert.Equal(t, 3, maldonado.Plates)
+	assert.Equal(t, 17, maldonado.Offenses)
+	assert.Equal(t, []PlateBucket{{"1", 1, 1}, {"2-5", 1, 4}, {"6-10", 0, 0}, {">10", 1, 12}}, maldonado.Buckets)
+	assert.InDelta(t, 94.1, maldonado.RepeatShare(), 0.1)
+}
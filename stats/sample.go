@@ -0,0 +1,137 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"bufio"
+	"database/sql"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// QASampleFile is the name of the review sheet written next to the database.
+const QASampleFile = "qa_sample.html"
+
+//go:embed templates/qa_sample.html
+var qaTemplates embed.FS
+
+var qaSampleTemplate = template.Must(template.ParseFS(qaTemplates, "templates/qa_sample.html"))
+
+// SampledOffense is an offense picked for review, with what the extraction
+// read from its row.
+type SampledOffense struct {
+	DocSource   string
+	DocID       string
+	RecordID    int
+	Vehicle     string
+	Time        time.Time
+	Location    string
+	Description string
+	UR          impo.UR
+	Error       string
+}
+
+// DepartmentSample are the offenses picked from a database.
+type DepartmentSample struct {
+	DbID      int
+	Name      string
+	Extracted int // offenses extracted in the run
+	Offenses  []SampledOffense
+}
+
+// QASample is a random sample of the offenses extracted by a run, for a human
+// to check them against the source documents.
+type QASample struct {
+	GeneratedAt time.Time
+	Since       time.Time
+	PerDB       int
+	Departments []DepartmentSample
+}
+
+// ComputeQASample picks up to perDB random offenses of every database among
+// the documents extracted since the given time.
+func ComputeQASample(db *sql.DB, since time.Time, perDB int) (*QASample, error) {
+	rows, err := db.Query(`
+		SELECT o.db_id, COUNT(*) OVER (PARTITION BY o.db_id), o.doc_source, COALESCE(o.doc_id, ''),
+			o.record_id, COALESCE(o.vehicle, ''), o."time", COALESCE(o.location, ''),
+			COALESCE(o.description, ''), COALESCE(o.ur, 0), COALESCE(o.error, '')
+		FROM offenses o
+		JOIN document_extractions e ON e.doc_source = o.doc_source
+		WHERE e.extracted_at >= ?
+		QUALIFY row_number() OVER (PARTITION BY o.db_id ORDER BY random()) <= ?
+		ORDER BY o.db_id, o.doc_source, o.record_id
+	`, since, perDB)
+	if err != nil {
+		return nil, fmt.Errorf("querying sample: %w", err)
+	}
+	defer rows.Close()
+
+	s := &QASample{Since: since, PerDB: perDB}
+
+	for rows.Next() {
+		var (
+			dbID, extracted int
+			o               SampledOffense
+			when            sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&dbID, &extracted, &o.DocSource, &o.DocID, &o.RecordID, &o.Vehicle, &when,
+			&o.Location, &o.Description, &o.UR, &o.Error,
+		); err != nil {
+			return nil, fmt.Errorf("scanning sample: %w", err)
+		}
+
+		if when.Valid {
+			o.Time = when.Time.In(impo.UruguayTimezone)
+		}
+
+		if n := len(s.Departments); n == 0 || s.Departments[n-1].DbID != dbID {
+			d := DepartmentSample{DbID: dbID, Extracted: extracted, Name: fmt.Sprintf("DB %d", dbID)}
+			if name, err := impo.GetDBName(dbID); err == nil {
+				d.Name = name
+			}
+
+			s.Departments = append(s.Departments, d)
+		}
+
+		d := &s.Departments[len(s.Departments)-1]
+		d.Offenses = append(d.Offenses, o)
+	}
+
+	return s, rows.Err()
+}
+
+// WriteQASample computes the sample and writes it as an HTML review sheet to
+// path.
+func WriteQASample(db *sql.DB, path string, since time.Time, perDB int, now time.Time) error {
+	s, err := ComputeQASample(db, since, perDB)
+	if err != nil {
+		return err
+	}
+
+	s.GeneratedAt = now
+
+	f, err := os.Create(path) // #nosec G304 - path is built from the command line
+	if err != nil {
+		return fmt.Errorf("creating sample: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := qaSampleTemplate.Execute(w, s); err != nil {
+		return fmt.Errorf("rendering sample: %w", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("writing sample: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteQASample(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE offenses (
+			db_id INTEGER, doc_id VARCHAR, doc_source VARCHAR, record_id INTEGER, vehicle VARCHAR,
+			"time" TIMESTAMPTZ, location VARCHAR, description VARCHAR, ur INTEGER, error VARCHAR
+		);
+		CREATE TABLE document_extractions (doc_source VARCHAR, extracted_at TIMESTAMPTZ);
+		INSERT INTO document_extractions VALUES
+			('https://impo/new-45', '2025-06-30 10:00:00-03'),
+			('https://impo/new-6', '2025-06-30 10:00:00-03'),
+			('https://impo/old-45', '2025-06-01 10:00:00-03');
+		INSERT INTO offenses
+			SELECT 45, '1/025', 'https://impo/new-45', i, 'AAA' || i, '2025-06-10 10:00:00-03', 'GORLERO Y 20',
+				'EXCESO DE VELOCIDAD', 500, NULL
+			FROM range(5) t(i);
+		INSERT INTO offenses VALUES
+			(6, '2/025', 'https://impo/new-6', 0, NULL, NULL, NULL, NULL, NULL, 'falta matrícula'),
+			(45, '0/025', 'https://impo/old-45', 0, 'OLD0000', NULL, NULL, NULL, NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	since := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	s, err := ComputeQASample(db, since, 3)
+	require.NoError(t, err)
+	require.Len(t, s.Departments, 2)
+	assert.Equal(t, "Montevideo", s.Departments[0].Name)
+	assert.Equal(t, "falta matrícula", s.Departments[0].Offenses[0].Error)
+	assert.Equal(t, 5, s.Departments[1].Extracted)
+	assert.Len(t, s.Departments[1].Offenses, 3)
+
+	for _, o := range s.Departments[1].Offenses {
+		assert.Equal(t, "https://impo/new-45", o.DocSource)
+	}
+
+	path := filepath.Join(t.TempDir(), QASampleFile)
+	require.NoError(t, WriteQASample(db, path, since, 3, since))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `<a href="https://impo/new-45"`)
+	assert.Contains(t, string(b), "Maldonado (3 de 5)")
+	assert.NotContains(t, string(b), "OLD0000")
+}
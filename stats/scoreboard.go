@@ -0,0 +1,155 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// ScoreboardFile is the name of the scoreboard written next to the database.
+const ScoreboardFile = "scoreboard.json"
+
+// ScoreboardDepartment are the headline figures of a database.
+type ScoreboardDepartment struct {
+	DbID         int     `json:"db_id"`
+	Name         string  `json:"name"`
+	Offenses30d  int     `json:"offenses_30d"`
+	Offenses365d int     `json:"offenses_365d"`
+	TopArticle   string  `json:"top_article,omitempty"` // of the last 365 days
+	UR365d       float64 `json:"ur_365d"`               // in UR, not in the resolution of the ur column
+	TotalUR      float64 `json:"ur_total"`
+	LastDocument string  `json:"last_document,omitempty"` // publication date, YYYY-MM-DD
+}
+
+// Scoreboard summarizes every database for the landing page, so it renders
+// without querying DuckDB.
+type Scoreboard struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Departments []ScoreboardDepartment `json:"departments"`
+}
+
+// ComputeScoreboard aggregates the active offenses of every database, counting
+// the recent ones from now.
+func ComputeScoreboard(db *sql.DB, now time.Time) (*Scoreboard, error) {
+	since30d, since365d := now.AddDate(0, 0, -30), now.AddDate(-1, 0, 0)
+
+	rows, err := db.Query(`
+		SELECT
+			db_id,
+			COUNT(*) FILTER (WHERE "time" >= ?),
+			COUNT(*) FILTER (WHERE "time" >= ?),
+			CAST(COALESCE(SUM(ur) FILTER (WHERE "time" >= ?), 0) AS BIGINT),
+			CAST(COALESCE(SUM(ur), 0) AS BIGINT),
+			MAX(doc_date)
+		FROM active_offenses
+		GROUP BY db_id
+		ORDER BY db_id
+	`, since30d, since365d, since365d)
+	if err != nil {
+		return nil, fmt.Errorf("querying scoreboard: %w", err)
+	}
+	defer rows.Close()
+
+	s := &Scoreboard{GeneratedAt: now}
+
+	for rows.Next() {
+		var (
+			d               ScoreboardDepartment
+			ur365d, urTotal impo.UR
+			lastDoc         sql.NullTime
+		)
+
+		if err := rows.Scan(&d.DbID, &d.Offenses30d, &d.Offenses365d, &ur365d, &urTotal, &lastDoc); err != nil {
+			return nil, fmt.Errorf("scanning scoreboard: %w", err)
+		}
+
+		d.UR365d, d.TotalUR = urValue(ur365d), urValue(urTotal)
+
+		if lastDoc.Valid {
+			d.LastDocument = lastDoc.Time.Format(time.DateOnly)
+		}
+
+		if name, err := impo.GetDBName(d.DbID); err == nil {
+			d.Name = name
+		}
+
+		s.Departments = append(s.Departments, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	top, err := topArticlePerDB(db, since365d)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range s.Departments {
+		s.Departments[i].TopArticle = top[s.Departments[i].DbID]
+	}
+
+	return s, nil
+}
+
+// urValue converts from the resolution of the ur column to UR.
+func urValue(ur impo.UR) float64 {
+	return float64(ur) / impo.URResolution
+}
+
+func topArticlePerDB(db *sql.DB, since time.Time) (map[int]string, error) {
+	rows, err := db.Query(`
+		SELECT db_id, article_id
+		FROM (SELECT db_id, unnest(article_ids) AS article_id FROM active_offenses WHERE "time" >= ?)
+		GROUP BY db_id, article_id
+		QUALIFY row_number() OVER (PARTITION BY db_id ORDER BY COUNT(*) DESC, article_id) = 1
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying top article: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[int]string)
+
+	for rows.Next() {
+		var (
+			dbID    int
+			article string
+		)
+
+		if err := rows.Scan(&dbID, &article); err != nil {
+			return nil, fmt.Errorf("scanning top article: %w", err)
+		}
+
+		ret[dbID] = article
+	}
+
+	return ret, rows.Err()
+}
+
+// WriteScoreboard computes the scoreboard and writes it as JSON to path.
+func WriteScoreboard(db *sql.DB, path string, now time.Time) error {
+	s, err := ComputeScoreboard(db, now)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encoding scoreboard: %w", err)
+	}
+
+	// #nosec G306 - public data, served by the web
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("writing scoreboard: %w", err)
+	}
+
+	return nil
+}
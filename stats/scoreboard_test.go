@@ -0,0 +1,54 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteScoreboard(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (
+			db_id INTEGER, doc_date DATE, "time" TIMESTAMPTZ, ur INTEGER, article_ids VARCHAR[]
+		);
+		INSERT INTO active_offenses VALUES
+			(45, '2025-06-20', '2025-06-10 10:00:00-03', 500, ['18.1', '13.3']),
+			(45, '2025-06-20', '2025-06-11 10:00:00-03', 500, ['13.3']),
+			(45, '2025-01-10', '2025-01-05 10:00:00-03', 1000, ['18.1']),
+			(45, '2024-01-10', '2024-01-05 10:00:00-03', 2000, ['18.1']),
+			(6, '2024-02-10', '2024-02-05 10:00:00-03', 100, []);
+	`)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), ScoreboardFile)
+	require.NoError(t, WriteScoreboard(db, path, now))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var s Scoreboard
+	require.NoError(t, json.Unmarshal(b, &s))
+
+	assert.True(t, now.Equal(s.GeneratedAt))
+	assert.Equal(t, []ScoreboardDepartment{
+		{DbID: 6, Name: "Montevideo", TotalUR: 0.1, LastDocument: "2024-02-10"},
+		{
+			DbID: 45, Name: "Maldonado", Offenses30d: 2, Offenses365d: 3, TopArticle: "13.3",
+			UR365d: 2, TotalUR: 4, LastDocument: "2025-06-20",
+		},
+	}, s.Departments)
+}
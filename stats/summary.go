@@ -0,0 +1,172 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stats computes aggregated figures of the ChapaUY database, to sanity
+// check it without opening the web UI.
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/jcodagnone/chapauy/impo"
+)
+
+// DepartmentYears are the offenses of a database per year.
+type DepartmentYears struct {
+	DbID   int
+	Total  int
+	ByYear map[int]int
+}
+
+// ArticleCount is the number of offenses classified under an article.
+type ArticleCount struct {
+	ArticleID string
+	Count     int
+}
+
+// Summary is an overview of the active offenses of the database.
+type Summary struct {
+	Offenses    int
+	Geocoded    int // offenses with a point
+	Classified  int // offenses with at least an article
+	TotalUR     impo.UR
+	Years       []int // every year with offenses, ascending
+	Departments []DepartmentYears
+	TopArticles []ArticleCount
+}
+
+// GeocodedPct is the share of the offenses with a point, as a percentage.
+func (s *Summary) GeocodedPct() float64 {
+	return pct(s.Geocoded, s.Offenses)
+}
+
+// ClassifiedPct is the share of the offenses with an article, as a percentage.
+func (s *Summary) ClassifiedPct() float64 {
+	return pct(s.Classified, s.Offenses)
+}
+
+func pct(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+
+	return float64(a) * 100 / float64(b)
+}
+
+// ComputeSummary aggregates the active offenses, reporting the topN articles
+// with more offenses.
+func ComputeSummary(db *sql.DB, topN int) (*Summary, error) {
+	s := &Summary{}
+
+	if err := db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COUNT(point),
+			COUNT(*) FILTER (WHERE len(article_ids) > 0),
+			CAST(COALESCE(SUM(ur), 0) AS BIGINT)
+		FROM active_offenses
+	`).Scan(&s.Offenses, &s.Geocoded, &s.Classified, &s.TotalUR); err != nil {
+		return nil, fmt.Errorf("querying totals: %w", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT db_id, time_year, COUNT(*)
+		FROM active_offenses
+		WHERE time_year IS NOT NULL
+		GROUP BY ALL
+		ORDER BY db_id, time_year
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying offenses per year: %w", err)
+	}
+	defer rows.Close()
+
+	years := make(map[int]bool)
+
+	for rows.Next() {
+		var dbID, year, count int
+		if err := rows.Scan(&dbID, &year, &count); err != nil {
+			return nil, fmt.Errorf("scanning offenses per year: %w", err)
+		}
+
+		if n := len(s.Departments); n == 0 || s.Departments[n-1].DbID != dbID {
+			s.Departments = append(s.Departments, DepartmentYears{DbID: dbID, ByYear: make(map[int]int)})
+		}
+
+		d := &s.Departments[len(s.Departments)-1]
+		d.ByYear[year] = count
+		d.Total += count
+
+		if !years[year] {
+			years[year] = true
+			s.Years = append(s.Years, year)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// rows come sorted by department first
+	slices.Sort(s.Years)
+
+	if s.TopArticles, err = topArticles(db, topN); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func topArticles(db *sql.DB, n int) ([]ArticleCount, error) {
+	rows, err := db.Query(`
+		SELECT article_id, COUNT(*) AS n
+		FROM (SELECT unnest(article_ids) AS article_id FROM active_offenses)
+		GROUP BY article_id
+		ORDER BY n DESC, article_id
+		LIMIT ?
+	`, n)
+	if err != nil {
+		return nil, fmt.Errorf("querying top articles: %w", err)
+	}
+	defer rows.Close()
+
+	var ret []ArticleCount
+
+	for rows.Next() {
+		var a ArticleCount
+		if err := rows.Scan(&a.ArticleID, &a.Count); err != nil {
+			return nil, fmt.Errorf("scanning top articles: %w", err)
+		}
+
+		ret = append(ret, a)
+	}
+
+	return ret, rows.Err()
+}
+
+// Sparkline draws the values as a line of block characters scaled to the
+// largest one.
+func Sparkline(values []int) string {
+	const ticks = "▁▂▃▄▅▆▇█"
+
+	blocks := []rune(ticks)
+	maxValue := 0
+
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+
+	ret := make([]rune, len(values))
+
+	for i, v := range values {
+		if v == 0 {
+			ret[i] = ' '
+		} else {
+			ret[i] = blocks[v*(len(blocks)-1)/maxValue]
+		}
+	}
+
+	return string(ret)
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package stats
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeSummary(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// only the columns the summary reads; point is a list to avoid the
+	// spatial extension
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (
+			db_id INTEGER, time_year USMALLINT, ur INTEGER, point DOUBLE[], article_ids VARCHAR[]
+		);
+		INSERT INTO active_offenses VALUES
+			(45, 2024, 500, [1, 2], ['18.1']),
+			(45, 2025, 550, NULL, ['18.1', '13.3']),
+			(45, 2025, 550, [1, 2], []),
+			(57, 2025, 1000, [1, 2], ['13.3']),
+			(57, NULL, 100, NULL, NULL);
+	`)
+	require.NoError(t, err)
+
+	s, err := ComputeSummary(db, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, s.Offenses)
+	assert.Equal(t, 3, s.Geocoded)
+	assert.Equal(t, 3, s.Classified)
+	assert.Equal(t, impo.UR(2700), s.TotalUR)
+	assert.InDelta(t, 60.0, s.GeocodedPct(), 1e-9)
+	assert.Equal(t, []int{2024, 2025}, s.Years)
+	assert.Equal(t, []DepartmentYears{
+		{DbID: 45, Total: 3, ByYear: map[int]int{2024: 1, 2025: 2}},
+		{DbID: 57, Total: 1, ByYear: map[int]int{2025: 1}},
+	}, s.Departments)
+	assert.Equal(t, []ArticleCount{{ArticleID: "13.3", Count: 2}}, s.TopArticles)
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁ ▄█", Sparkline([]int{1, 0, 50, 100}))
+	assert.Empty(t, Sparkline(nil))
+}
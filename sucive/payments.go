@@ -0,0 +1,236 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sucive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/dbutils"
+)
+
+// The payment status of the offenses of a plate is personal data: it is only
+// looked up for a plate whose owner agreed to it, and kept in its own DuckDB
+// file, outside of the state directory of the database, which gets published
+// whole.
+
+// DefaultPaymentsPath is the DuckDB file with the payment status of the plates
+// looked up, relative to the working directory and not to the state one.
+const DefaultPaymentsPath = "payments.duckdb"
+
+// ErrPaymentsInState is returned for a payments file inside the state
+// directory.
+var ErrPaymentsInState = errors.New("the payments file can't be in the state directory, which gets published")
+
+// PaymentsTable is the table of PaymentsFile.
+const PaymentsTable = "payment_status"
+
+// Status is the payment status of an offense.
+type Status string
+
+// The status of an offense once its plate is looked up.
+const (
+	StatusPending   Status = "pending"    // listed as a fine to pay
+	StatusPaid      Status = "paid"       // listed as a paid fine
+	StatusListed    Status = "listed"     // listed, without a status we can read
+	StatusNotListed Status = "not_listed" // no fine of its day
+)
+
+var (
+	pendingRegex = regexp.MustCompile(`(?i)\b(pendientes?|impag[ao]s?|adeudad[ao]s?|a pagar|vencid[ao]s?)\b`)
+	paidRegex    = regexp.MustCompile(`(?i)\b(paga|pagad[ao]s?|abonad[ao]s?|cancelad[ao]s?)\b`)
+)
+
+// statusFromText reads the status of a fine from the text of its row, empty
+// when it says none.
+func statusFromText(text string) Status {
+	switch {
+	case pendingRegex.MatchString(text):
+		return StatusPending
+	case paidRegex.MatchString(text):
+		return StatusPaid
+	default:
+		return ""
+	}
+}
+
+// Payment is the payment status of an offense.
+type Payment struct {
+	Offense
+	Status Status
+	Fine   string // description of the matching fine, if any
+}
+
+// PlateOffenses returns the active offenses of a plate with a time, the ones
+// that can be matched with a fine.
+func PlateOffenses(db *sql.DB, plate string) ([]Offense, error) {
+	rows, err := db.Query(`
+		SELECT db_id, doc_source, record_id, vehicle, "time"
+		FROM active_offenses
+		WHERE vehicle = ? AND "time" IS NOT NULL
+		ORDER BY "time", db_id, doc_source, record_id
+	`, impo.NormalizeVehicleID(plate))
+	if err != nil {
+		return nil, fmt.Errorf("querying the offenses of %s: %w", plate, err)
+	}
+	defer rows.Close()
+
+	var ret []Offense
+
+	for rows.Next() {
+		var o Offense
+		if err := rows.Scan(&o.DbID, &o.DocSource, &o.RecordID, &o.Vehicle, &o.Time); err != nil {
+			return nil, fmt.Errorf("scanning offense: %w", err)
+		}
+
+		ret = append(ret, o)
+	}
+
+	return ret, rows.Err()
+}
+
+// LinkPayments looks up the fines of plate and returns the payment status of
+// each of its offenses: the one of the fine of the same day, as in Reconcile,
+// or StatusNotListed.
+func LinkPayments(ctx context.Context, lookup Lookup, plate string, offenses []Offense) ([]Payment, error) {
+	fines, err := lookup.Fines(ctx, plate)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make([]bool, len(fines))
+	ret := make([]Payment, len(offenses))
+
+	for i, o := range offenses {
+		ret[i] = Payment{Offense: o, Status: StatusNotListed}
+
+		if j := findFine(fines, used, o.Time); j >= 0 {
+			used[j] = true
+			ret[i].Status = fines[j].Status
+			ret[i].Fine = fines[j].Description
+
+			if ret[i].Status == "" {
+				ret[i].Status = StatusListed
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// CheckPaymentsPath fails with ErrPaymentsInState when the payments file at
+// path is inside the state directory stateDir.
+func CheckPaymentsPath(path, stateDir string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("payments path: %w", err)
+	}
+
+	absState, err := filepath.Abs(stateDir)
+	if err != nil {
+		return fmt.Errorf("state path: %w", err)
+	}
+
+	if rel, err := filepath.Rel(absState, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrPaymentsInState, path)
+	}
+
+	return nil
+}
+
+// OpenPayments opens, creating it when missing, the payments file at path.
+func OpenPayments(path string) (*sql.DB, error) {
+	db, err := dbutils.Open(path, dbutils.ReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + PaymentsTable + ` (
+			db_id INTEGER NOT NULL,
+			doc_source VARCHAR NOT NULL,
+			record_id INTEGER NOT NULL,
+			plate VARCHAR NOT NULL,
+			status VARCHAR NOT NULL,
+			fine VARCHAR,
+			checked_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (db_id, doc_source, record_id)
+		)
+	`); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("creating %s: %w", PaymentsTable, err)
+	}
+
+	return db, nil
+}
+
+// SavePayments replaces the payment status of the offenses of plate, in a
+// database opened by OpenPayments.
+func SavePayments(db *sql.DB, plate string, payments []Payment, checkedAt time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+	}()
+
+	plate = impo.NormalizeVehicleID(plate)
+	if _, err := tx.Exec(`DELETE FROM `+PaymentsTable+` WHERE plate = ?`, plate); err != nil {
+		return fmt.Errorf("deleting the payments of %s: %w", plate, err)
+	}
+
+	for _, p := range payments {
+		if _, err := tx.Exec(`INSERT INTO `+PaymentsTable+` VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			p.DbID, p.DocSource, p.RecordID, plate, string(p.Status), nullable(p.Fine), checkedAt,
+		); err != nil {
+			return fmt.Errorf("saving the payment of %s: %w", p.DocSource, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing the payments of %s: %w", plate, err)
+	}
+
+	return nil
+}
+
+// AttachPayments attaches, read only, the payments file at path to db, where
+// browse finds it to show the payment status of the offenses.
+func AttachPayments(db *sql.DB, path string) error {
+	if strings.ContainsRune(path, '\'') {
+		return fmt.Errorf("invalid payments path %q", path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("payments: %w", err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ATTACH '%s' AS payments (READ_ONLY)", path)); err != nil {
+		return fmt.Errorf("attaching %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func nullable(s string) any {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+
+	return s
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sucive
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusFromText(t *testing.T) {
+	for text, want := range map[string]Status{
+		"Exceso de velocidad Pendiente":  StatusPending,
+		"Semáforo en rojo IMPAGA":        StatusPending,
+		"Semáforo en rojo Monto a pagar": StatusPending,
+		"Estacionamiento Paga":           StatusPaid,
+		"Estacionamiento Pagada":         StatusPaid,
+		"Cancelada por convenio":         StatusPaid,
+		"Exceso de velocidad":            "",
+		"Pagaré":                         "",
+	} {
+		assert.Equal(t, want, statusFromText(text), text)
+	}
+}
+
+func TestLinkPayments(t *testing.T) {
+	offenses := []Offense{
+		{DbID: 45, DocSource: "doc1", RecordID: 1, Vehicle: "SBC1234", Time: day(1)},
+		{DbID: 45, DocSource: "doc1", RecordID: 2, Vehicle: "SBC1234", Time: day(2)},
+		{DbID: 6, DocSource: "doc2", RecordID: 1, Vehicle: "SBC1234", Time: day(3)},
+		{DbID: 6, DocSource: "doc3", RecordID: 7, Vehicle: "SBC1234", Time: day(4)},
+	}
+	lookup := fakeLookup{"SBC1234": {
+		{Date: day(1), Description: "Velocidad Pendiente", Status: StatusPending},
+		{Date: day(2), Description: "Semáforo Paga", Status: StatusPaid},
+		{Date: day(3), Description: "Estacionamiento"},
+	}}
+
+	payments, err := LinkPayments(context.Background(), lookup, "SBC1234", offenses)
+	require.NoError(t, err)
+	require.Len(t, payments, 4)
+
+	assert.Equal(t, StatusPending, payments[0].Status)
+	assert.Equal(t, "Velocidad Pendiente", payments[0].Fine)
+	assert.Equal(t, StatusPaid, payments[1].Status)
+	assert.Equal(t, StatusListed, payments[2].Status)
+	assert.Equal(t, StatusNotListed, payments[3].Status)
+	assert.Empty(t, payments[3].Fine)
+
+	_, err = LinkPayments(context.Background(), lookup, "AAA1234", offenses)
+	assert.Error(t, err)
+}
+
+func TestSavePayments(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (db_id INTEGER, doc_source VARCHAR, record_id INTEGER, vehicle VARCHAR, "time" TIMESTAMPTZ);
+		INSERT INTO active_offenses VALUES
+			(45, 'doc1', 2, 'SBC1234', '2025-03-02 10:30:00-03'),
+			(45, 'doc1', 1, 'SBC1234', '2025-03-01 10:30:00-03'),
+			(45, 'doc1', 3, 'SBC1234', NULL),
+			(45, 'doc1', 4, 'BAA1234', '2025-03-01 10:30:00-03');
+	`)
+	require.NoError(t, err)
+
+	offenses, err := PlateOffenses(db, "sbc 1234")
+	require.NoError(t, err)
+	require.Len(t, offenses, 2)
+	assert.Equal(t, 1, offenses[0].RecordID)
+
+	path := filepath.Join(t.TempDir(), DefaultPaymentsPath)
+
+	pdb, err := OpenPayments(path)
+	require.NoError(t, err)
+
+	payments := []Payment{{Offense: offenses[0], Status: StatusPaid, Fine: "Velocidad Paga"}, {Offense: offenses[1], Status: StatusNotListed}}
+	require.NoError(t, SavePayments(pdb, "SBC1234", payments, time.Now()))
+	// a new lookup replaces the previous one
+	require.NoError(t, SavePayments(pdb, "SBC1234", payments[:1], time.Now()))
+	require.NoError(t, pdb.Close())
+
+	require.NoError(t, AttachPayments(db, path))
+
+	var status string
+	require.NoError(t, db.QueryRow(`
+		SELECT string_agg(status, ',' ORDER BY record_id)
+		FROM active_offenses JOIN payments.payment_status USING (db_id, doc_source, record_id)
+	`).Scan(&status))
+	assert.Equal(t, "paid", status)
+
+	assert.ErrorIs(t, AttachPayments(db, filepath.Join(t.TempDir(), DefaultPaymentsPath)), os.ErrNotExist)
+}
+
+func TestCheckPaymentsPath(t *testing.T) {
+	assert.NoError(t, CheckPaymentsPath("payments.duckdb", "db"))
+	assert.NoError(t, CheckPaymentsPath("private/payments.duckdb", "db"))
+	assert.NoError(t, CheckPaymentsPath("db-private/payments.duckdb", "db"))
+	assert.NoError(t, CheckPaymentsPath("../payments.duckdb", "."))
+
+	assert.ErrorIs(t, CheckPaymentsPath("db/payments.duckdb", "db"), ErrPaymentsInState)
+	assert.ErrorIs(t, CheckPaymentsPath("db/private/payments.duckdb", "./db"), ErrPaymentsInState)
+	assert.ErrorIs(t, CheckPaymentsPath("payments.duckdb", "."), ErrPaymentsInState)
+}
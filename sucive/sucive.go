@@ -0,0 +1,265 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sucive reconciles the extracted offenses against the public fines
+// lookup of SUCIVE, the vehicle registry shared by the departments, to
+// estimate how complete the dataset is and, for the plates whose owner asks
+// for it, which of their offenses are pending or paid.
+package sucive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/jcodagnone/chapauy/utils/htmlutils"
+	"golang.org/x/net/html"
+)
+
+// Fine is a fine of a plate as listed by the lookup.
+type Fine struct {
+	Date        time.Time
+	Description string
+	Status      Status // empty when the lookup doesn't tell it
+}
+
+// Lookup lists the fines of a plate.
+type Lookup interface {
+	Fines(ctx context.Context, plate string) ([]Fine, error)
+}
+
+// Offense is an extracted offense of a sampled plate.
+type Offense struct {
+	DbID      int
+	DocSource string
+	RecordID  int
+	Vehicle   string
+	Time      time.Time
+}
+
+// SampleOffenses picks up to n random Uruguayan plates and returns all their
+// active offenses, grouped by plate.
+func SampleOffenses(db *sql.DB, n int) (map[string][]Offense, error) {
+	rows, err := db.Query(`
+		WITH plates AS (
+			SELECT vehicle
+			FROM (SELECT DISTINCT vehicle FROM active_offenses WHERE vehicle_country = ? AND "time" IS NOT NULL)
+			ORDER BY random()
+			LIMIT ?
+		)
+		SELECT o.db_id, o.vehicle, o."time"
+		FROM active_offenses o JOIN plates p ON p.vehicle = o.vehicle
+		WHERE o."time" IS NOT NULL
+		ORDER BY o.vehicle, o."time"
+	`, impo.ISOUruguay, n)
+	if err != nil {
+		return nil, fmt.Errorf("sampling offenses: %w", err)
+	}
+	defer rows.Close()
+
+	ret := make(map[string][]Offense)
+
+	for rows.Next() {
+		var o Offense
+		if err := rows.Scan(&o.DbID, &o.Vehicle, &o.Time); err != nil {
+			return nil, fmt.Errorf("scanning sampled offense: %w", err)
+		}
+
+		ret[o.Vehicle] = append(ret[o.Vehicle], o)
+	}
+
+	return ret, rows.Err()
+}
+
+// Match are the offenses found in the lookup out of the ones checked.
+type Match struct {
+	Offenses int
+	Matched  int
+}
+
+// Rate is the share of the offenses found, as a percentage.
+func (m Match) Rate() float64 {
+	if m.Offenses == 0 {
+		return 0
+	}
+
+	return float64(m.Matched) * 100 / float64(m.Offenses)
+}
+
+// Report is the outcome of a reconciliation.
+type Report struct {
+	Match
+	Plates       int
+	LookupErrors int // plates whose lookup failed, not counted in Match
+	ByDb         map[int]*Match
+}
+
+// Reconcile looks up every plate and counts how many of its offenses appear
+// as a fine of the same day. Each fine matches a single offense.
+func Reconcile(ctx context.Context, lookup Lookup, sample map[string][]Offense) *Report {
+	r := &Report{ByDb: make(map[int]*Match)}
+
+	plates := make([]string, 0, len(sample))
+	for plate := range sample {
+		plates = append(plates, plate)
+	}
+
+	sort.Strings(plates)
+
+	for _, plate := range plates {
+		fines, err := lookup.Fines(ctx, plate)
+		if err != nil {
+			r.LookupErrors++
+
+			continue
+		}
+
+		r.Plates++
+		used := make([]bool, len(fines))
+
+		for _, o := range sample[plate] {
+			m := r.ByDb[o.DbID]
+			if m == nil {
+				m = &Match{}
+				r.ByDb[o.DbID] = m
+			}
+
+			m.Offenses++
+			r.Offenses++
+
+			if i := findFine(fines, used, o.Time); i >= 0 {
+				used[i] = true
+				m.Matched++
+				r.Matched++
+			}
+		}
+	}
+
+	return r
+}
+
+func findFine(fines []Fine, used []bool, t time.Time) int {
+	y, m, d := t.In(impo.UruguayTimezone).Date()
+
+	for i, f := range fines {
+		if used[i] {
+			continue
+		}
+
+		if fy, fm, fd := f.Date.In(impo.UruguayTimezone).Date(); fy == y && fm == m && fd == d {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// HTTPLookup queries a public lookup page and takes as fines the rows of its
+// tables that have a date.
+type HTTPLookup struct {
+	// URL of the lookup, with %s where the plate goes.
+	URL    string
+	Client *http.Client
+}
+
+var dateRegex = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`)
+
+// Fines implements Lookup.
+func (l *HTTPLookup) Fines(ctx context.Context, plate string) ([]Fine, error) {
+	u := fmt.Sprintf(l.URL, url.QueryEscape(plate))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", plate, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("looking up %s: status %d", plate, resp.StatusCode)
+	}
+
+	node, err := htmlutils.AsNode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing lookup of %s: %w", plate, err)
+	}
+
+	return finesFromHTML(node), nil
+}
+
+// finesFromHTML returns a fine for every table row with a date in one of its
+// cells; the rest of the cells make up the description.
+func finesFromHTML(n *html.Node) []Fine {
+	var ret []Fine
+
+	var visit func(n *html.Node)
+
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && strings.EqualFold(n.Data, "tr") {
+			if f, ok := fineFromRow(n); ok {
+				ret = append(ret, f)
+			}
+
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+
+	visit(n)
+
+	return ret
+}
+
+func fineFromRow(tr *html.Node) (Fine, bool) {
+	var (
+		f     Fine
+		found bool
+		descr []string
+	)
+
+	for td := tr.FirstChild; td != nil; td = td.NextSibling {
+		if td.Type != html.ElementNode || !strings.EqualFold(td.Data, "td") {
+			continue
+		}
+
+		var sb strings.Builder
+		if err := htmlutils.Node2string(td, &sb); err != nil {
+			continue
+		}
+
+		text := strings.TrimSpace(sb.String())
+
+		if !found {
+			if m := dateRegex.FindString(text); m != "" {
+				if t, err := time.ParseInLocation("2/1/2006", m, impo.UruguayTimezone); err == nil {
+					f.Date, found = t, true
+
+					continue
+				}
+			}
+		}
+
+		if text != "" {
+			descr = append(descr, text)
+		}
+	}
+
+	f.Description = strings.Join(descr, " ")
+	f.Status = statusFromText(f.Description)
+
+	return f, found
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sucive
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jcodagnone/chapauy/impo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLookup map[string][]Fine
+
+func (l fakeLookup) Fines(_ context.Context, plate string) ([]Fine, error) {
+	fines, ok := l[plate]
+	if !ok {
+		return nil, errors.New("lookup failed")
+	}
+
+	return fines, nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 3, d, 10, 30, 0, 0, impo.UruguayTimezone)
+}
+
+func TestReconcile(t *testing.T) {
+	sample := map[string][]Offense{
+		"SBC1234": {{DbID: 6, Vehicle: "SBC1234", Time: day(1)}, {DbID: 6, Vehicle: "SBC1234", Time: day(1)}},
+		"BAA1234": {{DbID: 45, Vehicle: "BAA1234", Time: day(2)}, {DbID: 45, Vehicle: "BAA1234", Time: day(3)}},
+		"AAA1234": {{DbID: 40, Vehicle: "AAA1234", Time: day(4)}},
+	}
+	lookup := fakeLookup{
+		// a single fine that day, so only one of the two offenses matches
+		"SBC1234": {{Date: time.Date(2025, 3, 1, 0, 0, 0, 0, impo.UruguayTimezone)}},
+		"BAA1234": {{Date: day(2)}, {Date: day(3)}, {Date: day(9)}},
+	}
+
+	r := Reconcile(context.Background(), lookup, sample)
+
+	assert.Equal(t, 2, r.Plates)
+	assert.Equal(t, 1, r.LookupErrors)
+	assert.Equal(t, Match{Offenses: 4, Matched: 3}, r.Match)
+	assert.InDelta(t, 75.0, r.Rate(), 1e-9)
+	assert.Equal(t, map[int]*Match{6: {Offenses: 2, Matched: 1}, 45: {Offenses: 2, Matched: 2}}, r.ByDb)
+}
+
+func TestHTTPLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SBC1234", r.URL.Query().Get("matricula"))
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><table>
+			<tr><td>Fecha</td><td>Infracción</td></tr>
+			<tr><td>01/03/2025 10:30</td><td>Exceso de velocidad</td></tr>
+			<tr><td>5/3/2025</td><td>Semáforo</td><td>en rojo</td></tr>
+		</table></html>`)
+	}))
+	defer server.Close()
+
+	lookup := &HTTPLookup{URL: server.URL + "/?matricula=%s", Client: server.Client()}
+
+	fines, err := lookup.Fines(context.Background(), "SBC1234")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Fine{
+		{Date: time.Date(2025, 3, 1, 0, 0, 0, 0, impo.UruguayTimezone), Description: "Exceso de velocidad"},
+		{Date: time.Date(2025, 3, 5, 0, 0, 0, 0, impo.UruguayTimezone), Description: "Semáforo en rojo"},
+	}, fines)
+}
+
+func TestSampleOffenses(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE active_offenses (db_id INTEGER, vehicle VARCHAR, vehicle_country CHAR(2), "time" TIMESTAMPTZ);
+		INSERT INTO active_offenses VALUES
+			(6, 'SBC1234', 'UY', '2025-03-01 10:30:00-03'),
+			(45, 'SBC1234', 'UY', '2025-03-02 10:30:00-03'),
+			(45, 'BAA1234', 'UY', '2025-03-02 10:30:00-03'),
+			(45, 'BAA1234', 'UY', NULL),
+			(45, 'ABC123', 'AR', '2025-03-02 10:30:00-03');
+	`)
+	require.NoError(t, err)
+
+	sample, err := SampleOffenses(db, 10)
+	require.NoError(t, err)
+	assert.Len(t, sample, 2)
+	assert.Len(t, sample["SBC1234"], 2)
+	assert.Len(t, sample["BAA1234"], 1)
+
+	sample, err = SampleOffenses(db, 1)
+	require.NoError(t, err)
+	assert.Len(t, sample, 1)
+}
@@ -0,0 +1,48 @@
+/**
+ * Copyright 2025 The ChapaUY Authors
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Sends the curator token in the Authorization header of the API calls. It's
+// asked for the first time the server requires it (chapa curation serve
+// --require-tokens) and kept in the browser's local storage.
+(() => {
+    const storageKey = 'chapa_curator_token';
+    const originalFetch = window.fetch.bind(window);
+
+    const isAPI = (input) => {
+        const url = new URL(typeof input === 'string' ? input : input.url, window.location.href);
+
+        return url.origin === window.location.origin && url.pathname.startsWith('/api/');
+    };
+
+    const fetchWithToken = (input, init) => {
+        const headers = new Headers(init.headers || {});
+        const token = window.localStorage.getItem(storageKey);
+        if (token) {
+            headers.set('Authorization', `Bearer ${token}`);
+        }
+
+        return originalFetch(input, { ...init, headers });
+    };
+
+    window.fetch = async (input, init = {}) => {
+        if (!isAPI(input)) {
+            return originalFetch(input, init);
+        }
+
+        const response = await fetchWithToken(input, init);
+        if (response.status !== 401) {
+            return response;
+        }
+
+        const token = window.prompt('Curator token (see chapa curation token issue):');
+        if (!token) {
+            return response;
+        }
+
+        window.localStorage.setItem(storageKey, token.trim());
+
+        return fetchWithToken(input, init);
+    };
+})();
@@ -0,0 +1,130 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package calendar knows which days the Uruguayan public administration
+// works, so deadlines expressed in días hábiles can be computed.
+package calendar
+
+import "time"
+
+// fixedHolidays are the holidays that always fall on the same date.
+var fixedHolidays = []struct {
+	month time.Month
+	day   int
+}{
+	{time.January, 1},   // Año Nuevo
+	{time.January, 6},   // Día de Reyes
+	{time.May, 1},       // Día de los Trabajadores
+	{time.June, 19},     // Natalicio de Artigas
+	{time.July, 18},     // Jura de la Constitución
+	{time.August, 25},   // Declaratoria de la Independencia
+	{time.November, 2},  // Día de los Difuntos
+	{time.December, 25}, // Navidad
+}
+
+// movableHolidays are moved to a Monday by Ley 16.805 when they fall
+// between Tuesday and Friday.
+var movableHolidays = []struct {
+	month time.Month
+	day   int
+}{
+	{time.April, 19},   // Desembarco de los 33 Orientales
+	{time.May, 18},     // Batalla de las Piedras
+	{time.October, 12}, // Día de la Diversidad Cultural
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Easter returns the date of Easter Sunday for the given year (Gregorian
+// calendar, anonymous algorithm).
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return date(year, time.Month(month), day)
+}
+
+// Holidays returns the days of the year in which public offices are closed,
+// besides weekends: national holidays, Carnaval and Semana de Turismo.
+func Holidays(year int) []time.Time {
+	var days []time.Time
+
+	for _, h := range fixedHolidays {
+		days = append(days, date(year, h.month, h.day))
+	}
+
+	for _, h := range movableHolidays {
+		days = append(days, moveToMonday(date(year, h.month, h.day)))
+	}
+
+	easter := Easter(year)
+	// Carnaval: Monday and Tuesday, 48 and 47 days before Easter
+	days = append(days, easter.AddDate(0, 0, -48), easter.AddDate(0, 0, -47))
+	// Semana de Turismo: Monday to Friday before Easter
+	for d := -6; d <= -2; d++ {
+		days = append(days, easter.AddDate(0, 0, d))
+	}
+
+	return days
+}
+
+// moveToMonday applies Ley 16.805: holidays on Tuesday or Wednesday are
+// observed the previous Monday, on Thursday or Friday the following Monday.
+func moveToMonday(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Tuesday:
+		return t.AddDate(0, 0, -1)
+	case time.Wednesday:
+		return t.AddDate(0, 0, -2)
+	case time.Thursday:
+		return t.AddDate(0, 0, 4)
+	case time.Friday:
+		return t.AddDate(0, 0, 3)
+	default:
+		return t
+	}
+}
+
+// IsBusinessDay reports whether t (its calendar date) is a día hábil.
+func IsBusinessDay(t time.Time) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+
+	day := date(t.Year(), t.Month(), t.Day())
+	for _, h := range Holidays(t.Year()) {
+		if h.Equal(day) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AddBusinessDays returns the date n business days after t, not counting t
+// itself. The result is a date at midnight UTC.
+func AddBusinessDays(t time.Time, n int) time.Time {
+	day := date(t.Year(), t.Month(), t.Day())
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if IsBusinessDay(day) {
+			n--
+		}
+	}
+
+	return day
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEaster(t *testing.T) {
+	assert.Equal(t, date(2024, time.March, 31), Easter(2024))
+	assert.Equal(t, date(2025, time.April, 20), Easter(2025))
+	assert.Equal(t, date(2026, time.April, 5), Easter(2026))
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	tests := []struct {
+		name string
+		day  time.Time
+		want bool
+	}{
+		{"weekday", date(2025, time.June, 3), true},
+		{"saturday", date(2025, time.June, 7), false},
+		{"independencia", date(2025, time.August, 25), false},
+		{"carnaval", date(2025, time.March, 4), false},
+		{"turismo", date(2025, time.April, 16), false},
+		// 18 de mayo 2022 was a Wednesday, observed on Monday 16
+		{"moved holiday", date(2022, time.May, 16), false},
+		{"moved from", date(2022, time.May, 18), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsBusinessDay(tt.day))
+		})
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	// published Friday 2025-04-11: Semana de Turismo is 14-18 and May 1 a holiday
+	got := AddBusinessDays(time.Date(2025, time.April, 11, 15, 0, 0, 0, time.UTC), 10)
+	assert.Equal(t, date(2025, time.May, 5), got)
+
+	got = AddBusinessDays(date(2025, time.June, 2), 10)
+	assert.Equal(t, date(2025, time.June, 16), got)
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package concurrency runs tasks on a bounded pool of workers.
+//
+// A panic in a task is reported as the error of that task instead of taking
+// down the process: a single malformed document must not abort a nightly
+// update.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrPanic is matched by the errors of the tasks that panicked.
+var ErrPanic = errors.New("task panicked")
+
+// PanicError is the error of a task that panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrPanic, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrPanic
+}
+
+// Safe calls fn, returning a PanicError if it panics. It is what the pool
+// does for each task, for the goroutines the tasks start themselves.
+func Safe[R any](fn func() (R, error)) (v R, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero R
+
+			v, err = zero, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
+
+// Result is the outcome of a task.
+type Result[T, R any] struct {
+	Task     T
+	Index    int // of the task in the slice given to Run
+	Value    R
+	Err      error
+	Duration time.Duration
+}
+
+// Panicked reports whether the task panicked.
+func (r Result[T, R]) Panicked() bool {
+	return errors.Is(r.Err, ErrPanic)
+}
+
+// Stats summarizes the tasks a Run completed.
+type Stats struct {
+	Tasks    int
+	Failed   int // including the panicked ones
+	Panicked int
+	Busy     time.Duration // sum of the durations of the tasks
+	Max      time.Duration // of the slowest task
+}
+
+// Mean returns the mean duration of a task.
+func (s Stats) Mean() time.Duration {
+	if s.Tasks == 0 {
+		return 0
+	}
+
+	return s.Busy / time.Duration(s.Tasks)
+}
+
+// Run calls fn for every task on at most workers goroutines, the number of
+// CPUs when workers isn't positive. done is called with the result of each
+// task as it completes, always from the calling goroutine, so it can update
+// state without locking.
+//
+// Cancelling ctx stops handing tasks to the workers: Run waits for the
+// running ones, which get ctx to give up early, and returns ctx.Err(). The
+// tasks that didn't start are not reported to done.
+func Run[T, R any](
+	ctx context.Context, workers int, tasks []T,
+	fn func(context.Context, T) (R, error),
+	done func(Result[T, R]),
+) (Stats, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	indexes := make(chan int)
+	results := make(chan Result[T, R])
+
+	var wg sync.WaitGroup
+
+	for range min(workers, len(tasks)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				started := time.Now()
+				v, err := Safe(func() (R, error) { return fn(ctx, tasks[i]) })
+
+				results <- Result[T, R]{
+					Task:     tasks[i],
+					Index:    i,
+					Value:    v,
+					Err:      err,
+					Duration: time.Since(started),
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indexes)
+
+		for i := range tasks {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var stats Stats
+
+	for r := range results {
+		stats.Tasks++
+		stats.Busy += r.Duration
+		stats.Max = max(stats.Max, r.Duration)
+
+		if r.Err != nil {
+			stats.Failed++
+		}
+
+		if r.Panicked() {
+			stats.Panicked++
+		}
+
+		if done != nil {
+			done(r)
+		}
+	}
+
+	return stats, ctx.Err()
+}
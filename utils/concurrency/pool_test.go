@@ -0,0 +1,126 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tasks := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	var running, peak atomic.Int32
+
+	got := make(map[int]int)
+
+	stats, err := Run(context.Background(), 3, tasks, func(_ context.Context, n int) (int, error) {
+		peak.Store(max(peak.Load(), running.Add(1)))
+		defer running.Add(-1)
+
+		time.Sleep(time.Millisecond)
+
+		if n == 4 {
+			return 0, errors.New("four")
+		}
+
+		return n * n, nil
+	}, func(r Result[int, int]) {
+		if r.Err == nil {
+			got[r.Task] = r.Value
+		}
+
+		if tasks[r.Index] != r.Task {
+			t.Errorf("index %d is task %d, not %d", r.Index, tasks[r.Index], r.Task)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(got) != 7 || got[3] != 9 {
+		t.Errorf("results = %v", got)
+	}
+
+	if p := peak.Load(); p > 3 {
+		t.Errorf("%d tasks ran at once, want at most 3", p)
+	}
+
+	if stats.Tasks != 8 || stats.Failed != 1 || stats.Panicked != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if stats.Max < time.Millisecond || stats.Mean() > stats.Max {
+		t.Errorf("durations = max %s, mean %s", stats.Max, stats.Mean())
+	}
+}
+
+func TestRun_Panic(t *testing.T) {
+	var failed []Result[string, int]
+
+	stats, err := Run(context.Background(), 2, []string{"ok", "boom", "ok"}, func(_ context.Context, s string) (int, error) {
+		if s == "boom" {
+			var m map[string]int
+			m[s]++ // assignment to entry in nil map
+		}
+
+		return len(s), nil
+	}, func(r Result[string, int]) {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if stats.Tasks != 3 || stats.Panicked != 1 || len(failed) != 1 {
+		t.Fatalf("stats = %+v, failed = %v", stats, failed)
+	}
+
+	r := failed[0]
+	if r.Task != "boom" || !r.Panicked() {
+		t.Errorf("failed = %+v", r)
+	}
+
+	var panicErr *PanicError
+	if !errors.As(r.Err, &panicErr) || !strings.Contains(string(panicErr.Stack), "pool_test.go") {
+		t.Errorf("error = %v, want a PanicError with the stack of the task", r.Err)
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := make([]int, 100)
+
+	stats, err := Run(ctx, 1, tasks, func(ctx context.Context, _ int) (int, error) {
+		cancel()
+		<-ctx.Done()
+
+		return 0, ctx.Err()
+	}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	if stats.Tasks >= len(tasks) {
+		t.Errorf("ran %d tasks after cancelling", stats.Tasks)
+	}
+}
+
+func TestSafe(t *testing.T) {
+	v, err := Safe(func() (int, error) { return 1, nil })
+	if v != 1 || err != nil {
+		t.Errorf("Safe() = %d, %v", v, err)
+	}
+
+	_, err = Safe(func() (int, error) { panic("unknown country") })
+	if !errors.Is(err, ErrPanic) || !strings.Contains(err.Error(), "unknown country") {
+		t.Errorf("Safe() error = %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dbutils centralizes how the DuckDB database file is opened.
+//
+// DuckDB allows either a single read-write process or many read-only
+// processes on the same file. Commands that only read should open the database
+// with ReadOnly so they can run side by side, and every command gets a retry
+// and a readable error when the file is locked by another process.
+package dbutils
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/duckdb/duckdb-go/v2" // also registers the duckdb driver
+)
+
+// AccessMode selects how the database file is opened.
+type AccessMode int
+
+const (
+	ReadWrite AccessMode = iota
+	ReadOnly
+)
+
+// ErrLocked is returned when another process holds a conflicting lock.
+var ErrLocked = errors.New("database is locked by another process")
+
+// Options tunes Open.
+type Options struct {
+	Mode AccessMode
+	// Retries is the number of additional attempts when the file is locked.
+	Retries int
+	// RetryDelay is the delay before the first retry; it doubles on each one.
+	RetryDelay time.Duration
+}
+
+// DefaultOptions waits around 15 seconds for a lock to be released.
+func DefaultOptions(mode AccessMode) Options {
+	return Options{
+		Mode:       mode,
+		Retries:    4,
+		RetryDelay: time.Second,
+	}
+}
+
+// DSN returns the data source name for path in the given mode.
+func DSN(path string, mode AccessMode) string {
+	if mode == ReadOnly {
+		return path + "?access_mode=read_only"
+	}
+
+	return path
+}
+
+// IsLocked reports whether err is a DuckDB file lock conflict.
+func IsLocked(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrLocked) {
+		return true
+	}
+
+	msg := err.Error()
+
+	return strings.Contains(msg, "Could not set lock on file") ||
+		strings.Contains(msg, "Conflicting lock is held")
+}
+
+// Open opens the DuckDB database at path with the default options for mode.
+func Open(path string, mode AccessMode) (*sql.DB, error) {
+	return OpenWithOptions(path, DefaultOptions(mode))
+}
+
+// OpenWithOptions opens the DuckDB database at path, retrying while the file is
+// locked by another process.
+func OpenWithOptions(path string, opts Options) (*sql.DB, error) {
+	return retryLocked(path, opts, func() (*sql.DB, error) { return open(path, opts.Mode) })
+}
+
+// AttachedName is the name of the database attached by OpenAttached.
+const AttachedName = "chapauy"
+
+// OpenAttached opens an in-memory database with the DuckDB file at path
+// attached read-only, as the default database of every connection. Unlike
+// one opened ReadOnly, it can attach new files to write them, as the exports
+// do, while other processes read the file.
+func OpenAttached(path string) (*sql.DB, error) {
+	if strings.ContainsRune(path, '\'') {
+		return nil, fmt.Errorf("invalid database path %q", path)
+	}
+
+	return retryLocked(path, DefaultOptions(ReadOnly), func() (*sql.DB, error) {
+		connector, err := duckdb.NewConnector("", func(execer driver.ExecerContext) error {
+			// ATTACH doesn't accept parameters; the path was checked above
+			_, err := execer.ExecContext(context.Background(), fmt.Sprintf(
+				"ATTACH IF NOT EXISTS '%s' AS %s (READ_ONLY); USE %s", path, AttachedName, AttachedName,
+			), nil)
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		db := sql.OpenDB(connector)
+		if err := db.Ping(); err != nil {
+			db.Close()
+
+			return nil, err
+		}
+
+		return db, nil
+	})
+}
+
+// retryLocked opens a database with open, retrying while the file at path is
+// locked by another process.
+func retryLocked(path string, opts Options, open func() (*sql.DB, error)) (*sql.DB, error) {
+	delay := opts.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		db, err := open()
+		if err == nil {
+			return db, nil
+		}
+
+		if !IsLocked(err) {
+			return nil, err
+		}
+
+		if attempt >= opts.Retries {
+			return nil, fmt.Errorf(
+				"%w: %s (close the other chapa process or use a read-only command): %w",
+				ErrLocked, path, err,
+			)
+		}
+
+		log.Printf("⚠️ Database %s is locked, retrying in %s (%d/%d)", path, delay, attempt+1, opts.Retries)
+		time.Sleep(delay)
+
+		delay *= 2
+	}
+}
+
+func open(path string, mode AccessMode) (*sql.DB, error) {
+	db, err := sql.Open("duckdb", DSN(path, mode))
+	if err != nil {
+		return nil, err
+	}
+
+	// the driver may defer opening the file until the first connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsLocked(t *testing.T) {
+	lockErr := errors.New(`IO Error: Could not set lock on file "db/chapauy.duckdb": Conflicting lock is held in chapa (PID 1)`)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"other", errors.New("no such table"), false},
+		{"duckdb", lockErr, true},
+		{"wrapped", fmt.Errorf("opening database: %w", lockErr), true},
+		{"sentinel", ErrLocked, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLocked(tt.err); got != tt.want {
+				t.Errorf("IsLocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.duckdb")
+
+	rw, err := Open(path, ReadWrite)
+	if err != nil {
+		t.Fatalf("opening read-write: %v", err)
+	}
+
+	if _, err := rw.Exec("CREATE TABLE t (a INTEGER); INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("creating table: %v", err)
+	}
+
+	rw.Close()
+
+	ro, err := Open(path, ReadOnly)
+	if err != nil {
+		t.Fatalf("opening read-only: %v", err)
+	}
+	defer ro.Close()
+
+	var a int
+	if err := ro.QueryRow("SELECT a FROM t").Scan(&a); err != nil || a != 1 {
+		t.Fatalf("reading: %v (a=%d)", err, a)
+	}
+
+	_, err = ro.Exec("INSERT INTO t VALUES (2)")
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+}
+
+func TestOpenAttached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.duckdb")
+
+	rw, err := Open(path, ReadWrite)
+	if err != nil {
+		t.Fatalf("opening read-write: %v", err)
+	}
+
+	if _, err := rw.Exec("CREATE TABLE t (a INTEGER); INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("creating table: %v", err)
+	}
+
+	rw.Close()
+
+	// a read-only database can't attach a new file
+	ro, err := Open(path, ReadOnly)
+	if err != nil {
+		t.Fatalf("opening read-only: %v", err)
+	}
+
+	if _, err := ro.Exec(fmt.Sprintf("ATTACH '%s' AS out", filepath.Join(dir, "ro.duckdb"))); err == nil {
+		t.Fatalf("expected attaching to a read-only database to fail")
+	}
+
+	ro.Close()
+
+	db, err := OpenAttached(path)
+	if err != nil {
+		t.Fatalf("opening attached: %v", err)
+	}
+	defer db.Close()
+
+	// more connections than one, each with the database as its default
+	db.SetMaxIdleConns(0)
+
+	for range 3 {
+		var a int
+		if err := db.QueryRow("SELECT a FROM main.t").Scan(&a); err != nil || a != 1 {
+			t.Fatalf("reading: %v (a=%d)", err, a)
+		}
+	}
+
+	out := filepath.Join(dir, "out.duckdb")
+	if _, err := db.Exec(fmt.Sprintf("ATTACH '%s' AS out; CREATE TABLE out.t AS FROM t; DETACH out", out)); err != nil {
+		t.Fatalf("writing %s: %v", out, err)
+	}
+
+	if _, err := db.Exec("INSERT INTO t VALUES (2)"); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+
+	check, err := Open(out, ReadOnly)
+	if err != nil {
+		t.Fatalf("opening %s: %v", out, err)
+	}
+	defer check.Close()
+
+	var a int
+	if err := check.QueryRow("SELECT a FROM t").Scan(&a); err != nil || a != 1 {
+		t.Fatalf("reading %s: %v (a=%d)", out, err, a)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// LockFile is the file that keeps a single long-running process, like
+// `impo watch`, per database directory. DuckDB only locks the database while
+// it's open, and a daemon closes it between runs.
+const LockFile = "chapauy.lock"
+
+// Lock takes the lock of the database directory dir, an flock(2) on LockFile
+// where the pid of the process is written for humans. It fails with ErrLocked
+// while another process, or another Lock of this one, holds it. The kernel
+// releases the lock of a process that dies, so there are no stale locks. The
+// returned function releases it.
+func Lock(dir string) (func() error, error) {
+	path := filepath.Join(dir, LockFile)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600) // #nosec G304 - our own file
+	if err != nil {
+		return nil, fmt.Errorf("opening lock %s: %w", path, err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil { // #nosec G115 - a file descriptor
+		holder := lockHolder(f)
+		_ = f.Close()
+
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s is held by process %s", ErrLocked, path, holder)
+		}
+
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+
+	// the file stays: removing it would let another process lock a new one
+	// while a third still waits on this
+	if err := errors.Join(f.Truncate(0), writePid(f)); err != nil {
+		_ = f.Close()
+
+		return nil, fmt.Errorf("writing lock %s: %w", path, err)
+	}
+
+	return f.Close, nil
+}
+
+func writePid(f *os.File) error {
+	_, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+
+	return err
+}
+
+// lockHolder returns the pid written in the lock, or "?" when it has none yet.
+func lockHolder(f *os.File) string {
+	content, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if pid := strings.TrimSpace(string(content)); err == nil && pid != "" {
+		return pid
+	}
+
+	return "?"
+}
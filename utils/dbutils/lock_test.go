@@ -0,0 +1,68 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	unlock, err := Lock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Lock(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err = Lock(dir)
+	if err != nil {
+		t.Fatalf("expected the lock after releasing it, got %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LockFile)
+
+	// left by a process that died: nobody holds the flock
+	if err := os.WriteFile(path, []byte("1073741824\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := Lock(dir)
+	if err != nil {
+		t.Fatalf("expected to take over the stale lock, got %v", err)
+	}
+	defer func() { _ = unlock() }()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := strconv.Itoa(os.Getpid()) + "\n"; string(content) != want {
+		t.Errorf("expected the pid of this process, got %q", content)
+	}
+
+	if _, err := Lock(dir); err == nil || !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Errorf("expected ErrLocked naming this process, got %v", err)
+	}
+}
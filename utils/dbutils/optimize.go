@@ -0,0 +1,176 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ScratchTablePrefix marks the tables created for ad-hoc work, which Optimize
+// drops so they don't end up published.
+const ScratchTablePrefix = "tmp_"
+
+// OptimizeOptions tunes Optimize.
+type OptimizeOptions struct {
+	// Analyze refreshes the statistics the planner uses.
+	Analyze bool
+}
+
+// OptimizeReport is what Optimize did.
+type OptimizeReport struct {
+	DroppedTables []string
+	SizeBefore    int64 // of the database and its write-ahead log, in bytes
+	SizeAfter     int64
+}
+
+// Optimize leaves the database at path as small as it can before it is
+// published: drops the scratch tables, optionally analyzes it and checkpoints
+// it, so the write-ahead log is merged and the space of the deleted rows is
+// reused.
+func Optimize(path string, opts OptimizeOptions) (*OptimizeReport, error) {
+	report := &OptimizeReport{}
+
+	var err error
+	if report.SizeBefore, err = fileSize(path); err != nil {
+		return nil, err
+	}
+
+	db, err := Open(path, ReadWrite)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT database_name, schema_name, table_name
+		FROM duckdb_tables()
+		WHERE NOT internal AND (temporary OR starts_with(table_name, ?))
+		ORDER BY ALL
+	`, ScratchTablePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing scratch tables: %w", err)
+	}
+
+	var tables []string
+
+	for rows.Next() {
+		var database, schema, table string
+		if err := rows.Scan(&database, &schema, &table); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scanning scratch table: %w", err)
+		}
+
+		tables = append(tables, fmt.Sprintf("%q.%q.%q", database, schema, table))
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing scratch tables: %w", err)
+	}
+
+	for _, table := range tables {
+		if _, err := db.Exec("DROP TABLE " + table); err != nil {
+			return nil, fmt.Errorf("dropping %s: %w", table, err)
+		}
+
+		report.DroppedTables = append(report.DroppedTables, table)
+	}
+
+	if opts.Analyze {
+		if _, err := db.Exec("ANALYZE"); err != nil {
+			return nil, fmt.Errorf("analyzing: %w", err)
+		}
+	}
+
+	if _, err := db.Exec("FORCE CHECKPOINT"); err != nil {
+		return nil, fmt.Errorf("checkpointing: %w", err)
+	}
+
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("closing database: %w", err)
+	}
+
+	if report.SizeAfter, err = fileSize(path); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// fileSize returns the size of the database and its write-ahead log.
+func fileSize(path string) (int64, error) {
+	var size int64
+
+	for _, p := range []string{path, path + ".wal"} {
+		info, err := os.Stat(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("reading size of %s: %w", p, err)
+		}
+
+		size += info.Size()
+	}
+
+	return size, nil
+}
+
+// RowCounts returns the number of rows of each of the tables of the database,
+// leaving out the temporary and the scratch ones.
+func RowCounts(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.Query(`
+		SELECT schema_name, table_name
+		FROM duckdb_tables()
+		WHERE database_name = current_database()
+			AND NOT internal AND NOT temporary AND NOT starts_with(table_name, ?)
+		ORDER BY ALL
+	`, ScratchTablePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	var tables [][2]string
+
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			rows.Close()
+
+			return nil, fmt.Errorf("scanning table: %w", err)
+		}
+
+		tables = append(tables, [2]string{schema, table})
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	ret := make(map[string]int64, len(tables))
+
+	for _, t := range tables {
+		name := t[1]
+		if t[0] != "main" {
+			name = t[0] + "." + t[1]
+		}
+
+		var n int64
+		// #nosec G202 - the names come from the catalog
+		if err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %q.%q", t[0], t[1])).Scan(&n); err != nil {
+			return nil, fmt.Errorf("counting rows of %s: %w", name, err)
+		}
+
+		ret[name] = n
+	}
+
+	return ret, nil
+}
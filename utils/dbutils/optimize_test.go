@@ -0,0 +1,84 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.duckdb")
+
+	db, err := Open(path, ReadWrite)
+	if err != nil {
+		t.Fatalf("opening: %v", err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE offenses AS SELECT range AS id FROM range(100000);
+		CREATE TABLE tmp_outliers AS SELECT range AS id FROM range(1000);
+		DELETE FROM offenses WHERE id % 2 = 0;
+	`); err != nil {
+		t.Fatalf("creating tables: %v", err)
+	}
+
+	db.Close()
+
+	report, err := Optimize(path, OptimizeOptions{Analyze: true})
+	if err != nil {
+		t.Fatalf("optimizing: %v", err)
+	}
+
+	if want := []string{`"test"."main"."tmp_outliers"`}; !slices.Equal(report.DroppedTables, want) {
+		t.Errorf("dropped %v, want %v", report.DroppedTables, want)
+	}
+
+	if report.SizeAfter == 0 {
+		t.Errorf("size after = 0")
+	}
+
+	db, err = Open(path, ReadOnly)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM offenses").Scan(&n); err != nil || n != 50000 {
+		t.Fatalf("counting offenses: %v (n=%d)", err, n)
+	}
+
+	if err := db.QueryRow("SELECT COUNT(*) FROM duckdb_tables() WHERE table_name = 'tmp_outliers'").Scan(&n); err != nil || n != 0 {
+		t.Fatalf("tmp_outliers still there: %v (n=%d)", err, n)
+	}
+}
+
+func TestRowCounts(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.duckdb"), ReadWrite)
+	if err != nil {
+		t.Fatalf("opening: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE offenses AS SELECT range AS id FROM range(10);
+		CREATE TABLE meta (key VARCHAR, value VARCHAR);
+		CREATE TABLE tmp_outliers AS SELECT range AS id FROM range(5);
+		CREATE TEMP TABLE scratch AS SELECT range AS id FROM range(5);
+	`); err != nil {
+		t.Fatalf("creating tables: %v", err)
+	}
+
+	counts, err := RowCounts(db)
+	if err != nil {
+		t.Fatalf("counting rows: %v", err)
+	}
+
+	if want := map[string]int64{"offenses": 10, "meta": 0}; !maps.Equal(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SnapshotDir is the directory, next to the database, with its snapshots.
+const SnapshotDir = "snapshots"
+
+// snapshotTimeLayout prefixes the name of the snapshots, so that they sort by
+// the time they were taken.
+const snapshotTimeLayout = "20060102-150405"
+
+var labelRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.]*$`)
+
+// ErrSnapshotNotFound is returned when no snapshot matches the given name.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// Snapshot is a copy of the database, taken before an operation that may have
+// to be rolled back.
+type Snapshot struct {
+	Name      string // the file name, without the extension
+	Label     string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// snapshotPath returns the path of the snapshot name of the database at path.
+func snapshotPath(path, name string) string {
+	return filepath.Join(filepath.Dir(path), SnapshotDir, name+".duckdb")
+}
+
+// CreateSnapshot copies the database at path into SnapshotDir, labeled. The
+// database is checkpointed first, and kept open while copying so that no
+// other process writes it meanwhile.
+func CreateSnapshot(path, label string) (*Snapshot, error) {
+	if !labelRegex.MatchString(label) {
+		return nil, fmt.Errorf("invalid snapshot label %q: only letters, digits, '_' and '.'", label)
+	}
+
+	// Open would create an empty one
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("database: %w", err)
+	}
+
+	db, err := Open(path, ReadWrite)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("FORCE CHECKPOINT"); err != nil {
+		return nil, fmt.Errorf("checkpointing: %w", err)
+	}
+
+	s := &Snapshot{Label: label, CreatedAt: time.Now().Truncate(time.Second)}
+	s.Name = s.CreatedAt.Format(snapshotTimeLayout) + "-" + label
+
+	dst := snapshotPath(path, s.Name)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", SnapshotDir, err)
+	}
+
+	if s.Size, err = copyFile(path, dst); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ListSnapshots returns the snapshots of the database at path, the newest
+// first.
+func ListSnapshots(path string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(filepath.Dir(path), SnapshotDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("listing snapshots: %w", err)
+	}
+
+	var ret []Snapshot
+
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".duckdb")
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		n := len(snapshotTimeLayout)
+		if len(name) < n+2 || name[n] != '-' {
+			continue // not one of ours
+		}
+
+		createdAt, err := time.ParseInLocation(snapshotTimeLayout, name[:n], time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot %s: %w", name, err)
+		}
+
+		ret = append(ret, Snapshot{Name: name, Label: name[n+1:], CreatedAt: createdAt, Size: info.Size()})
+	}
+
+	slices.SortFunc(ret, func(a, b Snapshot) int { return strings.Compare(b.Name, a.Name) })
+
+	return ret, nil
+}
+
+// RestoreSnapshot replaces the database at path with a snapshot, given by its
+// name or by its label, the newest one with it. The database must not be open
+// by another process, nor locked by a daemon (see Lock).
+func RestoreSnapshot(path, name string) (*Snapshot, error) {
+	snapshots, err := ListSnapshots(path)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(snapshots, func(s Snapshot) bool { return s.Name == name || s.Label == name })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	s := &snapshots[i]
+
+	unlock, err := Lock(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := unlock(); err != nil {
+			log.Printf("Error releasing lock: %v", err)
+		}
+	}()
+
+	// fails while another process has it open
+	db, err := Open(path, ReadWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("closing database: %w", err)
+	}
+
+	// copied next to the database and renamed over it, so that an
+	// interrupted restore leaves it untouched
+	tmp := path + ".restore"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("removing %s: %w", tmp, err)
+	}
+
+	if _, err := copyFile(snapshotPath(path, s.Name), tmp); err != nil {
+		return nil, err
+	}
+
+	// closing checkpoints the database, but a log left behind would be
+	// replayed over the snapshot
+	if err := os.Remove(path + ".wal"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		_ = os.Remove(tmp)
+
+		return nil, fmt.Errorf("removing write-ahead log: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+
+		return nil, fmt.Errorf("replacing %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// copyFile copies src into dst, which must not exist, returning its size.
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src) // #nosec G304 - the database and its snapshots
+	if err != nil {
+		return 0, fmt.Errorf("opening %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 - the database and its snapshots
+	if err != nil {
+		return 0, fmt.Errorf("creating %s: %w", dst, err)
+	}
+
+	n, err := io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+
+	if err = errors.Join(err, out.Close()); err != nil {
+		_ = os.Remove(dst)
+
+		return 0, fmt.Errorf("copying %s to %s: %w", src, dst, err)
+	}
+
+	return n, nil
+}
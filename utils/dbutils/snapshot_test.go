@@ -0,0 +1,109 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbutils
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chapauy.duckdb")
+
+	db, err := Open(path, ReadWrite)
+	if err != nil {
+		t.Fatalf("opening: %v", err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE offenses AS SELECT range AS id FROM range(100)`); err != nil {
+		t.Fatalf("creating table: %v", err)
+	}
+
+	db.Close()
+
+	if _, err := CreateSnapshot(filepath.Join(t.TempDir(), "chapauy.duckdb"), "empty"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+
+	if _, err := CreateSnapshot(path, "before-dedupe"); err == nil {
+		t.Error("expected an error for an invalid label")
+	}
+
+	first, err := CreateSnapshot(path, "before_dedupe")
+	if err != nil {
+		t.Fatalf("creating snapshot: %v", err)
+	}
+
+	if first.Size == 0 || first.Label != "before_dedupe" {
+		t.Errorf("unexpected snapshot %+v", first)
+	}
+
+	// the names have a resolution of a second
+	time.Sleep(time.Second)
+
+	count := func() int {
+		t.Helper()
+
+		db, err := Open(path, ReadOnly)
+		if err != nil {
+			t.Fatalf("opening: %v", err)
+		}
+		defer db.Close()
+
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM offenses").Scan(&n); err != nil {
+			t.Fatalf("counting offenses: %v", err)
+		}
+
+		return n
+	}
+
+	db, err = Open(path, ReadWrite)
+	if err != nil {
+		t.Fatalf("opening: %v", err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM offenses WHERE id >= 10`); err != nil {
+		t.Fatalf("deleting: %v", err)
+	}
+
+	db.Close()
+
+	if _, err := CreateSnapshot(path, "after"); err != nil {
+		t.Fatalf("creating snapshot: %v", err)
+	}
+
+	snapshots, err := ListSnapshots(path)
+	if err != nil {
+		t.Fatalf("listing: %v", err)
+	}
+
+	if len(snapshots) != 2 || snapshots[0].Label != "after" || snapshots[1].Name != first.Name ||
+		!snapshots[1].CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("unexpected snapshots %+v", snapshots)
+	}
+
+	if _, err := RestoreSnapshot(path, "missing"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	if _, err := RestoreSnapshot(path, "before_dedupe"); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	if n := count(); n != 100 {
+		t.Errorf("got %d offenses after restoring, want 100", n)
+	}
+
+	if _, err := RestoreSnapshot(path, snapshots[0].Name); err != nil {
+		t.Fatalf("restoring: %v", err)
+	}
+
+	if n := count(); n != 10 {
+		t.Errorf("got %d offenses after restoring, want 10", n)
+	}
+}
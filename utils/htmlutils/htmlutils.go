@@ -5,9 +5,11 @@
 package htmlutils
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"regexp"
 	"strings"
@@ -15,6 +17,8 @@ import (
 
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
 )
 
 const htmlAllowedRunes = "(?i)[\u0020-\u007Fáéíóöúñº°ªq]*"
@@ -94,12 +98,61 @@ func AsReader(resp *http.Response) (io.Reader, error) {
 		return nil, fmt.Errorf("media type is %s", media)
 	}
 
-	rr, err := charset.NewReader(resp.Body, media)
+	return NewReader(resp.Body, media)
+}
+
+// NewReader converts an HTML document to UTF-8. The charset is the one
+// declared by contentType, which may be empty, or else by the meta tags of the
+// document, unless the bytes contradict it (see sniffEncoding).
+func NewReader(r io.Reader, contentType string) (io.Reader, error) {
+	body, err := io.ReadAll(r)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("reading body: %w", err)
 	}
 
-	return rr, nil
+	e, _ := sniffEncoding(body, contentType)
+
+	return e.NewDecoder().Reader(bytes.NewReader(body)), nil
+}
+
+// sniffEncoding returns the encoding of an HTML body and its name. A byte
+// order mark wins; otherwise the declared charset, from the Content-Type or
+// else from the meta tags, is trusted unless the bytes contradict it: some old
+// IMPO documents are ISO-8859-1 declared as UTF-8, and the other way around,
+// which mis-decodes the accented headers.
+func sniffEncoding(body []byte, contentType string) (encoding.Encoding, string) {
+	// without a Content-Type, the byte order mark, the meta tags or a guess
+	meta, metaName, bom := charset.DetermineEncoding(body, "")
+	if bom {
+		return meta, metaName
+	}
+
+	declared, declaredName := meta, metaName
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if e, name := charset.Lookup(params["charset"]); e != nil {
+			declared, declaredName = e, name
+		}
+	}
+
+	switch {
+	case declaredName == "utf-8" && !utf8.Valid(body):
+		if metaName != "utf-8" {
+			return meta, metaName
+		}
+
+		// what browsers assume for ISO-8859-1
+		return charmap.Windows1252, "windows-1252"
+	case declaredName != "utf-8" && isMultibyteUTF8(body):
+		return encoding.Nop, "utf-8"
+	}
+
+	return declared, declaredName
+}
+
+// isMultibyteUTF8 tells whether the body is valid UTF-8 with some non-ASCII
+// character, which is unlikely for text in a single byte charset.
+func isMultibyteUTF8(body []byte) bool {
+	return bytes.IndexFunc(body, func(r rune) bool { return r >= utf8.RuneSelf }) != -1 && utf8.Valid(body)
 }
 
 // AsNode parses an io.Reader as an HTML node.
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -110,6 +112,55 @@ func TestAsHTMLReader_HappyPathTranscoding(t *testing.T) {
 	}
 }
 
+func TestAsReader_Charsets(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		contentType string
+		encoding    string
+	}{
+		{"latin1.html", "text/html; charset=iso-8859-1", "windows-1252"},
+		{"latin1_meta.html", "text/html", "windows-1252"},
+		// the bytes contradict the header
+		{"latin1_meta.html", "text/html; charset=utf-8", "windows-1252"},
+		{"latin1.html", "text/html; charset=utf-8", "windows-1252"},
+		{"utf8.html", "text/html; charset=iso-8859-1", "utf-8"},
+		{"utf8.html", "text/html", "utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+" as "+tt.contentType, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, name := sniffEncoding(body, tt.contentType); name != tt.encoding {
+				t.Errorf("expected %s, got %s", tt.encoding, name)
+			}
+
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{tt.contentType}},
+				Body:       io.NopCloser(strings.NewReader(string(body))),
+			}
+
+			n, err := asHTMLNode(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sb := strings.Builder{}
+			if err = Node2string(n, &sb); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(sb.String(), "Matrícula Intersección Año") {
+				t.Errorf("accented headers mis-decoded: %q", sb.String())
+			}
+		})
+	}
+}
+
 func TestHasHtmlContentType(t *testing.T) {
 	tests := []struct {
 		expected bool
@@ -7,11 +7,14 @@ package httputils
 import (
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -125,6 +128,112 @@ func (t *AppendRequestHeadersRoundTripper) RoundTrip(req *http.Request) (*http.R
 	return resp, err
 }
 
+// PoliteRoundTripper spaces out requests to be gentle with the remote server
+// and honors the Retry-After header of 429 and 503 responses.
+type PoliteRoundTripper struct {
+	Transport http.RoundTripper
+	// Delay is the minimum time between the start of two requests.
+	Delay time.Duration
+	// MaxRetries is the number of times a request is retried after a
+	// Retry-After response.
+	MaxRetries int
+	// MaxRetryAfter caps how long a Retry-After may make us wait. Longer
+	// waits return the response as is.
+	MaxRetryAfter time.Duration
+
+	mu    sync.Mutex
+	last  time.Time
+	sleep func(time.Duration)
+}
+
+func (t *PoliteRoundTripper) wait(d time.Duration) {
+	if t.sleep != nil {
+		t.sleep(d)
+	} else {
+		time.Sleep(d)
+	}
+}
+
+// throttle blocks until Delay has passed since the previous request.
+func (t *PoliteRoundTripper) throttle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if d := t.Delay - time.Since(t.last); !t.last.IsZero() && d > 0 {
+		t.wait(d)
+	}
+
+	t.last = time.Now()
+}
+
+// RetryAfter parses a Retry-After header value, either delay-seconds or an
+// HTTP date. It returns false if the value is missing or invalid.
+func RetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(when.Sub(now), 0), true
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *PoliteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		t.throttle()
+
+		resp, err := t.Transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+
+		d, ok := RetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || attempt >= t.MaxRetries || (t.MaxRetryAfter > 0 && d > t.MaxRetryAfter) {
+			return resp, nil
+		}
+
+		// the body has to be replayed on the next attempt
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				_ = resp.Body.Close()
+
+				return nil, fmt.Errorf("replaying request body: %w", err)
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		log.Printf("⏳ %s answered %d, retrying in %v", req.URL.Host, resp.StatusCode, d)
+		t.wait(d)
+	}
+}
+
 ////////////////////////////////////////////////////
 
 // implementation, but enforce expirations dates if missing.
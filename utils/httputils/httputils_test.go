@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // dummyRoundTripper is useful to simulate a response.
@@ -133,3 +134,120 @@ func TestAppendRequestHeadersRoundTripper(t *testing.T) {
 		t.Errorf("expected header X-Test-Header to have value 'TestValue', but got '%s'", got)
 	}
 }
+
+//////////////////////////////////
+// Test PoliteRoundTripper
+
+// sequenceRoundTripper answers with the given responses in order.
+type sequenceRoundTripper struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (s *sequenceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		s.bodies = append(s.bodies, string(b))
+	}
+
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+
+	return resp, nil
+}
+
+func response(status int, retryAfter string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+
+	return resp
+}
+
+func TestPoliteRoundTripper_RetryAfter(t *testing.T) {
+	seq := &sequenceRoundTripper{responses: []*http.Response{
+		response(http.StatusTooManyRequests, "3"),
+		response(http.StatusServiceUnavailable, "1"),
+		response(http.StatusOK, ""),
+	}}
+
+	var waits []time.Duration
+
+	rt := &PoliteRoundTripper{
+		Transport:  seq,
+		MaxRetries: 3,
+		sleep:      func(d time.Duration) { waits = append(waits, d) },
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("a=b"))
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip returned error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	if len(waits) != 2 || waits[0] != 3*time.Second || waits[1] != time.Second {
+		t.Errorf("unexpected waits %v", waits)
+	}
+
+	for _, b := range seq.bodies {
+		if b != "a=b" {
+			t.Errorf("expected the body to be replayed, got %q", b)
+		}
+	}
+}
+
+func TestPoliteRoundTripper_GivesUp(t *testing.T) {
+	seq := &sequenceRoundTripper{responses: []*http.Response{
+		response(http.StatusTooManyRequests, "3600"),
+	}}
+
+	rt := &PoliteRoundTripper{
+		Transport:     seq,
+		MaxRetries:    3,
+		MaxRetryAfter: time.Minute,
+		sleep:         func(d time.Duration) { t.Errorf("unexpected wait %v", d) },
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip returned error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", resp.StatusCode)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"Mon, 10 Mar 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 10 Mar 2025 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := RetryAfter(tt.value, now)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("RetryAfter(%q) = %v, %v; expected %v, %v", tt.value, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package i18n provides a tiny message catalog for user-facing strings.
+//
+// Messages are identified by their source text (à la gettext), so call sites
+// stay readable and strings without a catalog entry are shown verbatim.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Lang is a supported language code.
+type Lang string
+
+const (
+	Spanish Lang = "es"
+	English Lang = "en"
+)
+
+// DefaultLang is the language used when nothing else is requested.
+const DefaultLang = Spanish
+
+var ErrUnsupportedLang = errors.New("unsupported language")
+
+// current is set once at startup (see SetLang) and only read afterwards.
+var current = DefaultLang
+
+// ParseLang validates a language code such as "es", "EN" or "es-UY".
+func ParseLang(s string) (Lang, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+
+	switch Lang(s) {
+	case Spanish, English:
+		return Lang(s), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedLang, s)
+}
+
+// SetLang sets the process-wide language.
+func SetLang(s string) error {
+	l, err := ParseLang(s)
+	if err != nil {
+		return err
+	}
+
+	current = l
+
+	return nil
+}
+
+// Current returns the process-wide language.
+func Current() Lang {
+	return current
+}
+
+// T translates msg to the current language. Unknown messages are returned as is.
+func T(msg string) string {
+	if entry, ok := catalog[msg]; ok {
+		if s, ok := entry[Current()]; ok {
+			return s
+		}
+	}
+
+	return msg
+}
+
+// Sprintf translates format and formats it with args.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf translates format and builds an error like fmt.Errorf (%w included).
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLang(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Lang
+		wantErr bool
+	}{
+		{"es", Spanish, false},
+		{"EN", English, false},
+		{"es-UY", Spanish, false},
+		{"en_US", English, false},
+		{"fr", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLang(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLang(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+
+		if got != tt.want {
+			t.Errorf("ParseLang(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer func() { current = DefaultLang }()
+
+	if got := T("invalid db_id"); got != "db_id inválido" {
+		t.Errorf("T() in es = %q", got)
+	}
+
+	if got := T("judgment no puede ser nil"); got != "judgment no puede ser nil" {
+		t.Errorf("T() in es for spanish source = %q", got)
+	}
+
+	if err := SetLang("en"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := T("judgment no puede ser nil"); got != "judgment cannot be nil" {
+		t.Errorf("T() in en = %q", got)
+	}
+
+	if got := T("not in the catalog"); got != "not in the catalog" {
+		t.Errorf("T() for unknown message = %q", got)
+	}
+}
+
+func TestErrorfWraps(t *testing.T) {
+	defer func() { current = DefaultLang }()
+
+	current = English
+	inner := errors.New("boom")
+
+	err := Errorf("coordenadas inválidas: %w", inner)
+	if !errors.Is(err, inner) {
+		t.Errorf("Errorf() does not wrap: %v", err)
+	}
+
+	if err.Error() != "invalid coordinates: boom" {
+		t.Errorf("Errorf() = %q", err.Error())
+	}
+}
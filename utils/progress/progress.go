@@ -0,0 +1,164 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package progress reports the progress of the phases of the long commands,
+// like the search, download and extraction of impo update.
+//
+// On a terminal each phase gets a bar with its ETA. Otherwise, as in the logs
+// of CI, a line with the progress and the ETA is logged every LogEvery, so the
+// logs don't get a line per document. A quiet reporter reports nothing.
+package progress
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/schollz/progressbar/v3"
+)
+
+// LogEvery is how often the progress of a phase is logged without a terminal.
+const LogEvery = 30 * time.Second
+
+// Reporter starts the phases of a command. A nil Reporter, like a quiet one,
+// reports nothing.
+type Reporter struct {
+	quiet bool
+	tty   bool
+	every time.Duration
+	logf  func(format string, args ...any)
+	now   func() time.Time
+}
+
+// New creates a reporter on stderr, with bars when it's a terminal.
+func New(quiet bool) *Reporter {
+	return &Reporter{
+		quiet: quiet,
+		tty:   isatty.IsTerminal(os.Stderr.Fd()),
+		every: LogEvery,
+		logf:  log.Printf,
+		now:   time.Now,
+	}
+}
+
+// Quiet tells whether the reporter reports nothing.
+func (r *Reporter) Quiet() bool {
+	return r == nil || r.quiet
+}
+
+// Start starts a phase of total steps, -1 when unknown, like the pages of a
+// search. It returns nil, a phase that reports nothing, when quiet.
+func (r *Reporter) Start(name string, total int) *Phase {
+	if r.Quiet() {
+		return nil
+	}
+
+	p := &Phase{r: r, name: name, total: total, started: r.now()}
+	p.logged = p.started
+
+	if r.tty {
+		p.bar = progressbar.NewOptions(total,
+			progressbar.OptionSetDescription(name),
+			progressbar.OptionSetWriter(os.Stderr),
+			progressbar.OptionShowCount(),
+			progressbar.OptionClearOnFinish(),
+		)
+	}
+
+	return p
+}
+
+// Phase is a phase in progress. Its methods aren't safe for concurrent use,
+// call them from the goroutine collecting the results of the workers.
+type Phase struct {
+	r       *Reporter
+	name    string
+	total   int
+	done    int
+	started time.Time
+	logged  time.Time
+	bar     *progressbar.ProgressBar
+}
+
+// Add advances the phase n steps.
+func (p *Phase) Add(n int) {
+	if p == nil {
+		return
+	}
+
+	p.done += n
+
+	if p.bar != nil {
+		_ = p.bar.Add(n)
+
+		return
+	}
+
+	if now := p.r.now(); now.Sub(p.logged) >= p.r.every {
+		p.logged = now
+		p.r.logf("%s", p.status(now))
+	}
+}
+
+// Step advances the phase one step, see Printf for the message about it.
+func (p *Phase) Step(format string, args ...any) {
+	p.Printf(format, args...)
+	p.Add(1)
+}
+
+// Printf logs a detail of the phase, like the page being searched, only
+// without a reporter: the bar or the periodic lines tell the progress
+// otherwise.
+func (p *Phase) Printf(format string, args ...any) {
+	if p == nil {
+		log.Printf(format, args...)
+	}
+}
+
+// Logf logs a message that must be seen, like a failure, above the bar when
+// there is one.
+func (p *Phase) Logf(format string, args ...any) {
+	if p == nil || p.bar == nil {
+		log.Printf(format, args...)
+
+		return
+	}
+
+	_ = p.bar.Clear()
+
+	log.Printf(format, args...)
+
+	_ = p.bar.RenderBlank()
+}
+
+// Finish ends the phase, clearing its bar.
+func (p *Phase) Finish() {
+	if p == nil || p.bar == nil {
+		return
+	}
+
+	_ = p.bar.Finish()
+}
+
+func (p *Phase) status(now time.Time) string {
+	elapsed := now.Sub(p.started)
+
+	if p.total <= 0 {
+		return fmt.Sprintf("%s: %d in %s", p.name, p.done, elapsed.Round(time.Second))
+	}
+
+	return fmt.Sprintf("%s: %d/%d (%d%%), ETA %s",
+		p.name, p.done, p.total, p.done*100/p.total, ETA(p.done, p.total, elapsed).Round(time.Second))
+}
+
+// ETA estimates the time left to complete total steps after done of them
+// took elapsed, at the same pace. It's zero when it can't tell.
+func ETA(done, total int, elapsed time.Duration) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+
+	return time.Duration(float64(elapsed) / float64(done) * float64(total-done))
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The ChapaUY Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package progress
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETA(t *testing.T) {
+	assert.Equal(t, 3*time.Minute, ETA(25, 100, time.Minute))
+	assert.Equal(t, time.Duration(0), ETA(0, 100, time.Minute))
+	assert.Equal(t, time.Duration(0), ETA(100, 100, time.Minute))
+	assert.Equal(t, time.Duration(0), ETA(5, -1, time.Minute))
+}
+
+// newTestReporter returns a reporter without a terminal whose clock advances
+// 10s every time it's read, and the lines it logs.
+func newTestReporter() (*Reporter, *[]string) {
+	var lines []string
+
+	now := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	return &Reporter{
+		every: LogEvery,
+		logf: func(format string, args ...any) {
+			lines = append(lines, fmt.Sprintf(format, args...))
+		},
+		now: func() time.Time {
+			now = now.Add(10 * time.Second)
+
+			return now
+		},
+	}, &lines
+}
+
+func TestPhaseLogsPeriodically(t *testing.T) {
+	r, lines := newTestReporter()
+
+	p := r.Start("Extraction montevideo", 10)
+	for range 6 {
+		p.Step("Extracting %s", "doc")
+	}
+
+	p.Finish()
+
+	// a line every 30s of the 60s, with the pace of the steps so far
+	assert.Equal(t, []string{
+		"Extraction montevideo: 3/10 (30%), ETA 1m10s",
+		"Extraction montevideo: 6/10 (60%), ETA 40s",
+	}, *lines)
+}
+
+func TestPhaseUnknownTotal(t *testing.T) {
+	r, lines := newTestReporter()
+
+	p := r.Start("Search montevideo", -1)
+	p.Add(1)
+	p.Add(1)
+	p.Add(1)
+
+	assert.Equal(t, []string{"Search montevideo: 3 in 30s"}, *lines)
+}
+
+func TestQuiet(t *testing.T) {
+	r, lines := newTestReporter()
+	r.quiet = true
+
+	assert.True(t, r.Quiet())
+	assert.True(t, (*Reporter)(nil).Quiet())
+
+	p := r.Start("Downloads montevideo", 10)
+	assert.Nil(t, p)
+
+	// a nil phase reports nothing through the reporter
+	p.Add(10)
+	p.Finish()
+
+	assert.Empty(t, *lines)
+}
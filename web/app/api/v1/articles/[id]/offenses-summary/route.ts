@@ -0,0 +1,40 @@
+/**
+ * Copyright 2025 The ChapaUY Authors
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { NextRequest, NextResponse } from "next/server"
+import { getArticleOffensesSummary } from "@/lib/repository"
+import { checkETag } from "@/lib/etag"
+
+export async function GET(
+  request: NextRequest,
+  props: { params: Promise<{ id: string }> }
+) {
+  try {
+    const etagCheck = await checkETag(request)
+    if (etagCheck.response) {
+      return etagCheck.response
+    }
+    const { headers } = etagCheck.options!
+
+    const { id } = await props.params
+    const summary = await getArticleOffensesSummary(id)
+    if (!summary) {
+      return NextResponse.json(
+        { error: `unknown article "${id}"` },
+        { status: 404 }
+      )
+    }
+
+    return NextResponse.json(summary, { headers })
+  } catch (error) {
+    console.error("[API] Error in /api/v1/articles/[id]/offenses-summary:", error)
+    return NextResponse.json(
+      {
+        error: `Internal Server Error: ${error instanceof Error ? error.message : String(error)}`,
+      },
+      { status: 500 }
+    )
+  }
+}
@@ -0,0 +1,30 @@
+/**
+ * Copyright 2025 The ChapaUY Authors
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { NextRequest, NextResponse } from "next/server"
+import { getArticleList } from "@/lib/repository"
+import { checkETag } from "@/lib/etag"
+
+export async function GET(request: NextRequest) {
+  try {
+    const etagCheck = await checkETag(request)
+    if (etagCheck.response) {
+      return etagCheck.response
+    }
+    const { headers } = etagCheck.options!
+
+    const articles = await getArticleList()
+
+    return NextResponse.json({ articles }, { headers })
+  } catch (error) {
+    console.error("[API] Error in /api/v1/articles:", error)
+    return NextResponse.json(
+      {
+        error: `Internal Server Error: ${error instanceof Error ? error.message : String(error)}`,
+      },
+      { status: 500 }
+    )
+  }
+}
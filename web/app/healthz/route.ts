@@ -0,0 +1,26 @@
+/**
+ * Copyright 2025 The ChapaUY Authors
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+import { NextResponse } from "next/server"
+
+// Liveness of the server, used by the smoke tests of the images. It doesn't
+// touch DuckDB: the base web image is published without the database. Dates
+// are shown in Uruguay's time, so formatting one fails when the image lacks
+// the timezone data.
+export const dynamic = "force-dynamic"
+
+export function GET() {
+  return NextResponse.json(
+    {
+      status: "ok",
+      commit: process.env.GIT_COMMIT_SHA || "dev",
+      timezone: Intl.DateTimeFormat("es-UY", {
+        timeZone: "America/Montevideo",
+        timeZoneName: "short",
+      }).format(new Date(0)),
+    },
+    { headers: { "Cache-Control": "no-store" } }
+  )
+}
@@ -29,6 +29,14 @@ interface OffenseCardProps {
   params: OffensesParams
 }
 
+// labels of the payment status of chapa payments
+const paymentLabels: Record<string, string> = {
+  pending: "Pendiente",
+  paid: "Paga",
+  listed: "En SUCIVE",
+  not_listed: "No figura",
+}
+
 const getVehicleIcon = (vehicleType: string) => {
   var ret
   if (vehicleType === "Moto") {
@@ -277,6 +285,18 @@ export function OffenseCard({
               <span title="ID de la infracción">{offense.id}</span>
             </>
           )}
+          {offense.payment_status && (
+            <>
+              <span>•</span>
+              <span
+                className={`rounded px-1.5 py-0.5 font-medium ${offense.payment_status === "pending" ? "bg-destructive/10 text-destructive" : "bg-primary/10 text-primary"}`}
+                title="Estado de pago en SUCIVE"
+              >
+                {paymentLabels[offense.payment_status] ||
+                  offense.payment_status}
+              </span>
+            </>
+          )}
         </div>
 
         {offense.error && (
@@ -17,6 +17,8 @@ export const databases: DbReference[] = [
   { id: 6, name: "Montevideo" },
   { id: 43, name: "Paysandu" },
   { id: 55, name: "Rio Negro" },
+  { id: 71, name: "Rocha" },
+  { id: 73, name: "Salto" },
   { id: 49, name: "Soriano" },
   { id: 56, name: "Tacuarembó" },
   { id: 52, name: "Treinta y Tres" },
@@ -11,14 +11,18 @@ import {
   getDimensionResults,
   getChartDataByDayOfYear,
   getMapClusters,
+  getArticleList,
+  getArticleOffensesSummary,
 } from "./repository"
 import { Dimension, InPredicate, SortBy } from "./types"
 
 // Mock the duckdb module to return our test instance
 let testDB: duckdb.Database
+let testPayments = false
 
 vi.mock("./duckdb", () => ({
   getDuckDB: () => testDB,
+  hasPayments: () => testPayments,
   waitForDB: () => Promise.resolve(),
 }))
 
@@ -90,8 +94,11 @@ const setupTestDB = async () => {
         h3_res7 UBIGINT,
         h3_res8 UBIGINT,
         article_ids VARCHAR[],
-        article_codes TINYINT[]
+        article_codes TINYINT[],
+        ur_article INTEGER,
+        superseded_by VARCHAR
     );
+    CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL;
     CREATE TABLE articles (
         id VARCHAR PRIMARY KEY,
         text VARCHAR NOT NULL,
@@ -163,6 +170,36 @@ describe("OffenseRepository", () => {
       expect(summaries[0].ur_total).toBe(600)
       expect(summaries[0].ur_avg).toBe(200)
     })
+
+    it("skips the offenses of superseded documents", async () => {
+      // doc1 was re-published as doc1_A
+      await runQuery(
+        testDB,
+        `
+        INSERT INTO offenses (db_id, doc_source, doc_id, doc_date, record_id, offense_id, vehicle, vehicle_country, vehicle_type, time, time_year, location, description, ur, error) VALUES
+          (45, 'doc1_A', 'doc1_id', '2023-01-02', 1, 'offense1', 'AAAA123', 'UY', 'AUTO', '2023-01-01 10:00:00', 2023, 'Some Location', 'Speeding', 100, NULL);
+        UPDATE offenses SET superseded_by = 'doc1_A' WHERE doc_source = 'doc1' AND record_id = 1;
+      `
+      )
+
+      const summaries = await getOffensesSummary([], null)
+      expect(summaries[0].count).toBe(5)
+      expect(summaries[0].ur_total).toBe(600)
+
+      const offenses = await getOffenses(
+        [{ dimension: Dimension.Vehicle, values: ["AAAA123"] }],
+        SortBy.Document,
+        1,
+        10
+      )
+      expect(offenses.map((o) => o.doc_source).sort()).toEqual([
+        "doc1",
+        "doc1_A",
+      ])
+
+      const [vehicles] = await getDimensionResults([], [Dimension.Vehicle])
+      expect(vehicles.values.find((v) => v.value === "AAAA123")?.count).toBe(2)
+    })
   })
 
   describe("getOffenses", () => {
@@ -192,6 +229,38 @@ describe("OffenseRepository", () => {
       const offenses = await getOffenses(filters, SortBy.Vehicle, 1, 10)
       expect(offenses).toHaveLength(0)
     })
+
+    it("payment status of a plate looked up", async () => {
+      // as attached with CHAPAUY_PAYMENTS_DB
+      await runQuery(
+        testDB,
+        `
+        ATTACH ':memory:' AS payments;
+        CREATE TABLE payments.payment_status (db_id INTEGER, doc_source VARCHAR, record_id INTEGER, plate VARCHAR, status VARCHAR);
+        INSERT INTO payments.payment_status VALUES (45, 'doc1', 1, 'AAAA123', 'pending');
+      `
+      )
+      testPayments = true
+
+      try {
+        const offenses = await getOffenses(
+          [{ dimension: Dimension.Vehicle, values: ["AAAA123"] }],
+          SortBy.Document,
+          1,
+          10
+        )
+        expect(offenses.map((o) => o.payment_status)).toEqual([
+          "pending",
+          undefined,
+        ])
+
+        // only shown when looking up a plate
+        const all = await getOffenses([], SortBy.Document, 1, 10)
+        expect(all.every((o) => o.payment_status === undefined)).toBe(true)
+      } finally {
+        testPayments = false
+      }
+    })
   })
 
   describe("getDimensionResults", () => {
@@ -360,6 +429,68 @@ describe("OffenseRepository", () => {
     })
   })
 
+  describe("articles", () => {
+    beforeEach(async () => {
+      await setupTestDB()
+      await runQuery(
+        testDB,
+        `
+        INSERT INTO articles (id, text, code, title) VALUES
+          ('18.9.1', 'Estacionar en lugar prohibido o regulado', 18, 'Del estacionamiento'),
+          ('13.3.A', 'Superar las velocidades máximas permitidas', 13, 'De las velocidades');
+        UPDATE offenses SET article_ids = ['13.3.A'], article_codes = [13], ur_article = ur
+          WHERE description = 'Speeding';
+        -- the fine of the row is split between its articles
+        UPDATE offenses SET article_ids = ['13.3.A', '13.4'], article_codes = [13, 13], ur_article = ur / 2
+          WHERE description = 'Parking';
+      `
+      )
+    })
+
+    it("lists the catalog by code", async () => {
+      const articles = await getArticleList()
+      expect(articles.map((a) => a.id)).toEqual(["13.3.A", "18.9.1"])
+      expect(articles[0].code).toBe(13)
+      expect(articles[0].title).toBe("De las velocidades")
+    })
+
+    it("summarizes the offenses of an article", async () => {
+      const summary = await getArticleOffensesSummary("13.3.A")
+      expect(summary).not.toBeNull()
+      expect(summary!.count).toBe(3)
+      // in UR, the rows are in thousandths
+      expect(summary!.ur_total).toBe(0.5)
+      expect(summary!.trend).toEqual([
+        { year: 2023, count: 1, ur_total: 0.1 },
+        { year: 2024, count: 2, ur_total: 0.4 },
+      ])
+    })
+
+    it("skips the offenses of superseded documents", async () => {
+      await runQuery(
+        testDB,
+        `
+        INSERT INTO offenses (db_id, doc_source, doc_id, doc_date, record_id, offense_id, vehicle, time, time_year, description, ur, article_ids, article_codes, ur_article, superseded_by) VALUES
+          (45, 'doc0', 'doc0_id', '2022-12-30', 1, 'offense1', 'AAAA123', '2023-01-01 10:00:00', 2023, 'Speeding', 100, ['13.3.A'], [13], 100, 'doc1');
+      `
+      )
+
+      const summary = await getArticleOffensesSummary("13.3.A")
+      expect(summary!.count).toBe(3)
+      expect(summary!.ur_total).toBe(0.5)
+    })
+
+    it("articles without offenses", async () => {
+      const summary = await getArticleOffensesSummary("18.9.1")
+      expect(summary!.count).toBe(0)
+      expect(summary!.trend).toEqual([])
+    })
+
+    it("unknown article", async () => {
+      expect(await getArticleOffensesSummary("99.9")).toBeNull()
+    })
+  })
+
   describe("getMapClusters", () => {
     beforeEach(async () => {
       testDB = new duckdb.Database(":memory:")
@@ -367,8 +498,10 @@ describe("OffenseRepository", () => {
         testDB,
         `INSTALL spatial; LOAD spatial; CREATE TABLE offenses (
             db_id INTEGER, location VARCHAR, point POINT_2D, 
-            h3_res6 UBIGINT, h3_res7 UBIGINT, h3_res8 UBIGINT
-          )`
+            h3_res6 UBIGINT, h3_res7 UBIGINT, h3_res8 UBIGINT,
+            superseded_by VARCHAR
+          );
+          CREATE VIEW active_offenses AS SELECT * FROM offenses WHERE superseded_by IS NULL`
       )
 
       const parentRes6 = BigInt("0x86c2a7a97ffffff").toString()
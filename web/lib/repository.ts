@@ -3,7 +3,7 @@
  * SPDX-License-Identifier: Apache-2.0
  */
 
-import { getDuckDB, waitForDB } from "./duckdb"
+import { getDuckDB, hasPayments, waitForDB } from "./duckdb"
 import { getDBName, databases, countryDisplay } from "./db-refs"
 import {
   OffensesParams,
@@ -15,6 +15,8 @@ import {
   Dimension,
   Facet,
   FacetValue,
+  Article,
+  ArticleOffensesSummary,
 } from "@/lib/types"
 import * as h3 from "h3-js"
 import { unstable_cache, cacheLife } from "next/cache"
@@ -190,7 +192,7 @@ export async function getOffensesSummary(
       MIN(h3_res7) as min_h3_7, MAX(h3_res7) as max_h3_7,
       MIN(h3_res8) as min_h3_8, MAX(h3_res8) as max_h3_8
     `
-  query += " FROM offenses"
+  query += " FROM active_offenses"
 
   if (where) {
     query += ` WHERE ${where}`
@@ -255,6 +257,87 @@ export const getArticles = unstable_cache(
   { revalidate: 3600 }
 )
 
+// The article catalog, ordered by code and id, for the article explorer.
+export async function getArticleList(): Promise<Article[]> {
+  "use cache"
+  cacheLife("days")
+
+  await waitForDB()
+  const db = getDuckDB()
+
+  const rows = await dbAll(
+    db,
+    "SELECT id, code, title, text FROM articles ORDER BY code, id",
+    []
+  )
+  return rows.map((r: any) => ({
+    id: r.id,
+    code: Number(r.code),
+    title: r.title,
+    text: r.text,
+  }))
+}
+
+// Count, UR total and yearly trend of the offenses classified with an article.
+// The totals are in UR, not in the thousandths offenses stores. Returns null
+// when the article doesn't exist.
+export async function getArticleOffensesSummary(
+  id: string
+): Promise<ArticleOffensesSummary | null> {
+  "use cache"
+  cacheLife("days")
+
+  await waitForDB()
+  const db = getDuckDB()
+
+  const articles = await dbAll(
+    db,
+    "SELECT id, code, title, text FROM articles WHERE id = ?",
+    [id]
+  )
+  if (articles.length === 0) {
+    return null
+  }
+
+  const rows = await dbAll(
+    db,
+    // ur_article splits the fine of the rows with several articles, see
+    // impo/ur_rules.json
+    `SELECT time_year AS year, COUNT(*) AS count, SUM(ur_article) AS ur_total
+     FROM active_offenses
+     WHERE list_contains(article_ids, ?)
+     GROUP BY time_year
+     ORDER BY time_year`,
+    [id]
+  )
+
+  const a = articles[0]
+  const summary: ArticleOffensesSummary = {
+    article: { id: a.id, code: Number(a.code), title: a.title, text: a.text },
+    count: 0,
+    ur_total: 0,
+    trend: [],
+  }
+  // summed in thousandths, to not add up rounding errors
+  let urTotal = 0
+  rows.forEach((r: any) => {
+    const count = Number(r.count)
+    const yearTotal = Number(r.ur_total ?? 0)
+    summary.count += count
+    urTotal += yearTotal
+    // offenses without a time still add to the totals
+    if (r.year !== null) {
+      summary.trend.push({
+        year: Number(r.year),
+        count,
+        ur_total: yearTotal / 1000,
+      })
+    }
+  })
+  summary.ur_total = urTotal / 1000
+  return summary
+}
+
 export async function getDimensionResults(
   predicates: InPredicate[],
   dimensions: Dimension[],
@@ -303,7 +386,7 @@ export async function getDimensionResults(
               '${Dimension.Features}' as dimension,
               '${part.label}' as value,
               COUNT(*) as count
-            FROM offenses
+            FROM active_offenses
             ${clause}
           `)
           valueArgs.push(...args)
@@ -321,10 +404,10 @@ export async function getDimensionResults(
       // Base query construction
       if (dim === Dimension.ArticleID || dim === Dimension.ArticleCode) {
         selectVal = "value"
-        fromClause = `(SELECT UNNEST(${column}) as value FROM offenses) sub`
+        fromClause = `(SELECT UNNEST(${column}) as value FROM active_offenses) sub`
       } else {
         selectVal = `${column}::VARCHAR` // Force varchar for union compatibility
-        fromClause = "offenses"
+        fromClause = "active_offenses"
       }
 
       // Apply filters
@@ -397,7 +480,7 @@ export async function getDimensionResults(
         // Article Logic
         const predWhere = where ? `WHERE ${where}` : ""
         const searchClause = searchQuery ? `WHERE value::VARCHAR ILIKE ?` : ""
-        let innerSql = `SELECT UNNEST(${column}) as value FROM offenses ${predWhere}`
+        let innerSql = `SELECT UNNEST(${column}) as value FROM active_offenses ${predWhere}`
 
 
         queryPart = `
@@ -429,7 +512,7 @@ export async function getDimensionResults(
               '${dim}' as dimension,
               ${selectVal} as value,
               COUNT(*) as count
-            FROM offenses
+            FROM active_offenses
             ${whereSql}
             GROUP BY value
             ORDER BY count DESC, value ASC
@@ -439,7 +522,7 @@ export async function getDimensionResults(
             SELECT 
               '${dim}' as dimension,
               COUNT(DISTINCT COALESCE(${selectVal}, '')) as total
-            FROM offenses
+            FROM active_offenses
             ${whereSql}
          `
         valueArgs.push(...finalArgs)
@@ -633,7 +716,7 @@ export async function getDocumentFacets(
             '${Dimension.Features}' as dimension,
             '${part.label}' as value,
             COUNT(DISTINCT CASE WHEN ${part.expr} THEN ${distinctDoc} END) as count
-          FROM offenses
+          FROM active_offenses
           ${clause}
         `)
         valueArgs.push(...args)
@@ -651,7 +734,7 @@ export async function getDocumentFacets(
         '${dim}' as dimension,
         ${column}::VARCHAR as value, 
         COUNT(DISTINCT ${distinctDocExpr}) as count 
-      FROM offenses 
+      FROM active_offenses 
       ${where ? `WHERE ${where}` : ""}
       GROUP BY value 
       ORDER BY count DESC, value ASC
@@ -662,7 +745,7 @@ export async function getDocumentFacets(
       SELECT 
         '${dim}' as dimension,
         COUNT(DISTINCT COALESCE(${column}::VARCHAR, '')) as total
-      FROM offenses
+      FROM active_offenses
       ${where ? `WHERE ${where}` : ""}
     `
 
@@ -788,6 +871,13 @@ export async function getOffenses(
   const db = getDuckDB()
   const { where, args } = buildWhereClause(predicates || [])
 
+  // the payment status is only shown when looking up a plate
+  const withPayments =
+    hasPayments() &&
+    (predicates || []).some(
+      (p) => p.dimension === Dimension.Vehicle && p.values?.length > 0
+    )
+
   let query = `
     SELECT
       db_id,
@@ -806,8 +896,8 @@ export async function getOffenses(
       ur,
       error,
       point,
-      article_ids
-    FROM offenses
+      article_ids${withPayments ? ", payment_status.status AS payment_status" : ""}
+    FROM active_offenses${withPayments ? " LEFT JOIN payments.payment_status USING (db_id, doc_source, record_id)" : ""}
   `
 
   if (where) {
@@ -846,6 +936,7 @@ export async function getOffenses(
       error: row.error,
       point: row.point,
       article_id: row.article_ids,
+      payment_status: row.payment_status ?? undefined,
       // Defaults for missing fields
       adm_division: "",
       mercosur_format: false,
@@ -874,7 +965,7 @@ export async function getDocuments(
       count(*) AS records,
       sum(ur) AS ur,
       sum(CASE WHEN error IS NOT NULL THEN 1 ELSE 0 END) AS errors
-    FROM offenses
+    FROM active_offenses
   `
 
   if (where) {
@@ -889,7 +980,7 @@ export async function getDocuments(
   const countQuery = `
     SELECT COUNT(*) as total FROM (
       SELECT 1
-      FROM offenses
+      FROM active_offenses
       ${where ? `WHERE ${where}` : ""}
       GROUP BY db_id, doc_id, doc_date, doc_source
     )
@@ -965,7 +1056,7 @@ export async function getChartDataByDayOfYear(
             ${selectGroup} as grp,
             CAST(strftime(time, '%j') AS INTEGER) as day_of_year,
             count(*) as count
-        FROM offenses
+        FROM active_offenses
         WHERE ${where}
         GROUP BY ${groupByClause}
         ORDER BY grp, day_of_year
@@ -1069,7 +1160,7 @@ async function getChartDataByDimension(
             ${selectGroup} as grp,
             strftime(time, '${dimExpr}') as dim,
             count(*) as count
-        FROM offenses
+        FROM active_offenses
         WHERE ${where}
         ${groupByClause}
         ORDER BY grp, dim
@@ -1178,7 +1269,7 @@ async function getMapLocations(
             ST_Y(point) as lat,
             location,
             COUNT(*) as offenses
-        FROM offenses
+        FROM active_offenses
         WHERE
             h3_res${resolution} IN (${inPlaceholders}) AND point IS NOT NULL
     `
@@ -1246,7 +1337,7 @@ export async function getMapClusters(
             COUNT(DISTINCT location) as locations,
             AVG(ST_X(point)) as lng,
             AVG(ST_Y(point)) as lat
-        FROM offenses
+        FROM active_offenses
         WHERE
             ${parentResCol} = CAST(? AS UBIGINT)
     `
@@ -33,6 +33,9 @@ export interface Offense {
     lng: number
   }
   error?: string
+  // status of the fine in SUCIVE, for the plates looked up with consent of
+  // their owner (see chapa payments): pending, paid, listed or not_listed
+  payment_status?: string
 }
 
 export interface OffenseDocument {
@@ -142,6 +145,26 @@ export interface OffensesResponse {
   }
 }
 
+export interface Article {
+  id: string
+  code: number
+  title: string
+  text: string
+}
+
+export interface ArticleTrendPoint {
+  year: number
+  count: number
+  ur_total: number // in UR
+}
+
+export interface ArticleOffensesSummary {
+  article: Article
+  count: number
+  ur_total: number // in UR, not in thousandths like Offense.ur
+  trend: ArticleTrendPoint[] // by year of the offense, oldest first
+}
+
 export interface OffensesParams {
   predicates: InPredicate[]
   page?: number
@@ -11,7 +11,7 @@ export function cn(...inputs: ClassValue[]) {
 }
 
 export function formatUR(urValue: number): string {
-  return (urValue / 100.0).toLocaleString().replace(/\.?0+$/, "")
+  return (urValue / 1000.0).toLocaleString().replace(/\.?0+$/, "")
 }
 
 export function normalizeVehicleId(s: string): string {